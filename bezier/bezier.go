@@ -74,3 +74,46 @@ func (c Curve) Curve(p []vg.Point) []vg.Point {
 	}
 	return p
 }
+
+// ControlPoints returns the control points of the curve.
+func (c Curve) ControlPoints() []vg.Point {
+	cp := make([]vg.Point, len(c))
+	var w vg.Length
+	for i, p := range c {
+		if i == 0 {
+			w = 1
+		} else if i == 1 {
+			w = vg.Length(len(c)) - 1
+		} else {
+			w *= vg.Length(len(c)-i) / vg.Length(i)
+		}
+		cp[i] = vg.Point{X: p.Control.X / w, Y: p.Control.Y / w}
+	}
+	return cp
+}
+
+// Split returns the two curves formed by dividing the curve at t, where 0 ≤ t ≤ 1,
+// using de Casteljau's algorithm. The first curve spans the curve from 0 to t and
+// the second spans the curve from t to 1.
+func (c Curve) Split(t float64) (Curve, Curve) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	p := c.ControlPoints()
+	left := make([]vg.Point, len(p))
+	right := make([]vg.Point, len(p))
+	for k := len(p) - 1; ; k-- {
+		left[len(p)-1-k] = p[0]
+		right[k] = p[k]
+		if k == 0 {
+			break
+		}
+		for i := 0; i < k; i++ {
+			p[i] = vg.Point{
+				X: p[i].X + (p[i+1].X-p[i].X)*vg.Length(t),
+				Y: p[i].Y + (p[i+1].Y-p[i].Y)*vg.Length(t),
+			}
+		}
+	}
+	return New(left...), New(right...)
+}
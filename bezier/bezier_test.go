@@ -227,3 +227,30 @@ func (s *S) TestCurve(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestSplit(c *check.C) {
+	for i, ctrls := range [][]vg.Point{
+		{{1, 2}, {3, 4}},
+		{{1, 2}, {3, 4}, {5, 6}, {7, 8}},
+		{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+		{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 3}},
+	} {
+		b := New(ctrls...)
+		for j, t := range b.ControlPoints() {
+			c.Check(t, approxEquals, ctrls[j], epsilon, check.Commentf("Test %d control %d", i, j))
+		}
+		for _, st := range []float64{0, 0.25, 0.5, 0.9, 1} {
+			l, r := b.Split(st)
+			c.Check(len(l), check.Equals, len(ctrls))
+			c.Check(len(r), check.Equals, len(ctrls))
+			for k := 0; k <= 10; k++ {
+				u := float64(k) / 10
+				c.Check(l.Point(u), approxEquals, b.Point(u*st), 1e-9, check.Commentf("Test %d split %v left %v", i, st, u))
+				c.Check(r.Point(u), approxEquals, b.Point(st+u*(1-st)), 1e-9, check.Commentf("Test %d split %v right %v", i, st, u))
+			}
+		}
+	}
+	l, r := Curve(nil).Split(0.5)
+	c.Check(l, check.IsNil)
+	c.Check(r, check.IsNil)
+}
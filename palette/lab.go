@@ -0,0 +1,95 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"image/color"
+	"math"
+)
+
+// lab is a color in the CIE L*a*b* color space relative to the D65 white point.
+type lab struct {
+	l, a, b float64
+}
+
+// D65 reference white point.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// labOf returns the L*a*b* representation of the sRGB color c, ignoring alpha.
+func labOf(c color.NRGBA) lab {
+	r := linear(float64(c.R) / 0xff)
+	g := linear(float64(c.G) / 0xff)
+	b := linear(float64(c.B) / 0xff)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / whiteX
+	y := (0.2126729*r + 0.7151522*g + 0.0721750*b) / whiteY
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / whiteZ
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return lab{l: 116*fy - 16, a: 500 * (fx - fy), b: 200 * (fy - fz)}
+}
+
+// nrgba returns the opaque sRGB color closest to c.
+func (c lab) nrgba() color.NRGBA {
+	fy := (c.l + 16) / 116
+	x := whiteX * labFInv(fy+c.a/500)
+	y := whiteY * labFInv(fy)
+	z := whiteZ * labFInv(fy-c.b/200)
+
+	return color.NRGBA{
+		R: channel(3.2404542*x - 1.5371385*y - 0.4985314*z),
+		G: channel(-0.9692660*x + 1.8760108*y + 0.0415560*z),
+		B: channel(0.0556434*x - 0.2040259*y + 1.0572252*z),
+		A: 0xff,
+	}
+}
+
+// lerp returns the color the fraction f of the way from c to d.
+func (c lab) lerp(d lab, f float64) lab {
+	return lab{
+		l: c.l + (d.l-c.l)*f,
+		a: c.a + (d.a-c.a)*f,
+		b: c.b + (d.b-c.b)*f,
+	}
+}
+
+const labDelta = 6.0 / 29
+
+func labF(t float64) float64 {
+	if t > labDelta*labDelta*labDelta {
+		return math.Cbrt(t)
+	}
+	return t/(3*labDelta*labDelta) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	if t > labDelta {
+		return t * t * t
+	}
+	return 3 * labDelta * labDelta * (t - 4.0/29)
+}
+
+// linear returns the linear intensity of the sRGB encoded channel value v.
+func linear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// channel returns the sRGB encoded channel value of the linear intensity v,
+// clamped to the sRGB gamut.
+func channel(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Floor(math.Min(math.Max(v, 0), 1)*0xff + 0.5))
+}
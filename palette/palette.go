@@ -0,0 +1,108 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package palette provides continuous color ramps and named palettes for coloring
+// values. Colors are interpolated in the CIE L*a*b* color space so that equal steps
+// in value give perceptually similar steps in color.
+package palette
+
+import (
+	"image/color"
+	"math"
+)
+
+// ColorFunc returns the color representing v, where 0 ≤ v ≤ 1. Values outside
+// [0, 1] are clamped to the interval.
+type ColorFunc func(v float64) color.Color
+
+// Ramp returns a ColorFunc interpolating between the given color stops, which are
+// evenly spaced across [0, 1] with the first stop at 0 and the last at 1. Colors
+// between the stops are interpolated in CIE L*a*b* and alpha is interpolated
+// linearly. Ramp panics if no stop is given.
+func Ramp(stops ...color.Color) ColorFunc {
+	if len(stops) == 0 {
+		panic("palette: no color stops")
+	}
+	ends := make([]color.NRGBA, len(stops))
+	labs := make([]lab, len(stops))
+	for i, c := range stops {
+		ends[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+		labs[i] = labOf(ends[i])
+	}
+	return func(v float64) color.Color {
+		if len(ends) == 1 || v <= 0 || math.IsNaN(v) {
+			return ends[0]
+		}
+		if v >= 1 {
+			return ends[len(ends)-1]
+		}
+		pos := v * float64(len(ends)-1)
+		i := int(pos)
+		f := pos - float64(i)
+		if f == 0 {
+			return ends[i]
+		}
+		c := labs[i].lerp(labs[i+1], f).nrgba()
+		c.A = uint8(math.Floor(float64(ends[i].A) + (float64(ends[i+1].A)-float64(ends[i].A))*f + 0.5))
+		return c
+	}
+}
+
+// Diverging returns a ColorFunc interpolating from neg at 0 through mid at 0.5 to
+// pos at 1.
+func Diverging(neg, mid, pos color.Color) ColorFunc {
+	return Ramp(neg, mid, pos)
+}
+
+// Discretize returns n colors sampled evenly from f, with the first color given by
+// f(0) and the last by f(1). If n is 1 the single color is f(0.5). The returned slice
+// may be used as the Palette of a Heat.
+func Discretize(f ColorFunc, n int) []color.Color {
+	if n <= 0 {
+		return nil
+	}
+	if n == 1 {
+		return []color.Color{f(0.5)}
+	}
+	p := make([]color.Color, n)
+	for i := range p {
+		p[i] = f(float64(i) / float64(n-1))
+	}
+	return p
+}
+
+// Viridis returns a ColorFunc approximating the perceptually uniform viridis palette,
+// ranging from dark blue at 0 through green to yellow at 1.
+func Viridis() ColorFunc {
+	return Ramp(
+		color.NRGBA{R: 0x44, G: 0x01, B: 0x54, A: 0xff},
+		color.NRGBA{R: 0x47, G: 0x2c, B: 0x7a, A: 0xff},
+		color.NRGBA{R: 0x3b, G: 0x51, B: 0x8b, A: 0xff},
+		color.NRGBA{R: 0x2c, G: 0x71, B: 0x8e, A: 0xff},
+		color.NRGBA{R: 0x21, G: 0x90, B: 0x8d, A: 0xff},
+		color.NRGBA{R: 0x27, G: 0xad, B: 0x81, A: 0xff},
+		color.NRGBA{R: 0x5c, G: 0xc8, B: 0x63, A: 0xff},
+		color.NRGBA{R: 0xaa, G: 0xdc, B: 0x32, A: 0xff},
+		color.NRGBA{R: 0xfd, G: 0xe7, B: 0x25, A: 0xff},
+	)
+}
+
+// RedBlue returns a diverging ColorFunc ranging from dark red at 0 through a light
+// neutral grey at 0.5 to dark blue at 1.
+func RedBlue() ColorFunc {
+	return Ramp(
+		color.NRGBA{R: 0xb2, G: 0x18, B: 0x2b, A: 0xff},
+		color.NRGBA{R: 0xef, G: 0x8a, B: 0x62, A: 0xff},
+		color.NRGBA{R: 0xfd, G: 0xdb, B: 0xc7, A: 0xff},
+		color.NRGBA{R: 0xf7, G: 0xf7, B: 0xf7, A: 0xff},
+		color.NRGBA{R: 0xd1, G: 0xe5, B: 0xf0, A: 0xff},
+		color.NRGBA{R: 0x67, G: 0xa9, B: 0xcf, A: 0xff},
+		color.NRGBA{R: 0x21, G: 0x66, B: 0xac, A: 0xff},
+	)
+}
+
+// Greys returns a sequential ColorFunc ranging from white at 0 to black at 1.
+func Greys() ColorFunc {
+	return Ramp(color.White, color.Black)
+}
@@ -0,0 +1,167 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func nrgba(c color.Color) color.NRGBA { return color.NRGBAModel.Convert(c).(color.NRGBA) }
+
+// near returns whether the channels of a and b differ by no more than tol.
+func near(a, b color.Color, tol int) bool {
+	ca, cb := nrgba(a), nrgba(b)
+	for _, d := range []int{
+		int(ca.R) - int(cb.R),
+		int(ca.G) - int(cb.G),
+		int(ca.B) - int(cb.B),
+		int(ca.A) - int(cb.A),
+	} {
+		if d < -tol || tol < d {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *S) TestLabRoundTrip(c *check.C) {
+	for _, col := range []color.NRGBA{
+		{A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		{R: 0xff, A: 0xff},
+		{G: 0xff, A: 0xff},
+		{B: 0xff, A: 0xff},
+		{R: 0x44, G: 0x01, B: 0x54, A: 0xff},
+		{R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+	} {
+		c.Check(labOf(col).nrgba(), check.Equals, col)
+	}
+	l := labOf(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	c.Check(math.Abs(l.l-100) < 1e-3, check.Equals, true, check.Commentf("white L* %v", l.l))
+	c.Check(math.Abs(l.a) < 1e-3 && math.Abs(l.b) < 1e-3, check.Equals, true, check.Commentf("white a* b* %v %v", l.a, l.b))
+}
+
+func (s *S) TestRamp(c *check.C) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	blue := color.NRGBA{B: 0xff, A: 0xff}
+	f := Ramp(color.Black, color.White)
+
+	// The ends of the ramp are the end stops and values outside [0, 1] are clamped.
+	for _, t := range []struct {
+		v    float64
+		want color.Color
+	}{
+		{v: 0, want: color.Black},
+		{v: -1, want: color.Black},
+		{v: math.NaN(), want: color.Black},
+		{v: 1, want: color.White},
+		{v: 2, want: color.White},
+	} {
+		c.Check(nrgba(f(t.v)), check.Equals, nrgba(t.want), check.Commentf("value %v", t.v))
+	}
+
+	// The midpoint of a black to white ramp is mid-grey in L*, not in sRGB.
+	c.Check(nrgba(f(0.5)), check.Equals, color.NRGBA{R: 0x77, G: 0x77, B: 0x77, A: 0xff})
+	c.Check(math.Abs(labOf(nrgba(f(0.5))).l-50) < 0.5, check.Equals, true)
+
+	// Intermediate stops are hit exactly.
+	f = Ramp(red, color.White, blue)
+	c.Check(nrgba(f(0.5)), check.Equals, nrgba(color.White))
+	c.Check(near(f(0.5-1e-9), f(0.5), 1), check.Equals, true)
+	c.Check(near(f(0.5+1e-9), f(0.5), 1), check.Equals, true)
+	c.Check(nrgba(f(0.25)).R > nrgba(f(0.25)).B, check.Equals, true)
+	c.Check(nrgba(f(0.75)).B > nrgba(f(0.75)).R, check.Equals, true)
+
+	// Lightness varies monotonically along a ramp between a dark and a light color.
+	f = Ramp(color.NRGBA{R: 0x20, G: 0x10, B: 0x40, A: 0xff}, color.NRGBA{R: 0xf0, G: 0xe0, B: 0x80, A: 0xff})
+	prev := math.Inf(-1)
+	for i := 0; i <= 20; i++ {
+		l := labOf(nrgba(f(float64(i) / 20))).l
+		c.Check(l >= prev, check.Equals, true, check.Commentf("step %d L* %v < %v", i, l, prev))
+		prev = l
+	}
+
+	// Alpha is interpolated linearly.
+	f = Ramp(color.NRGBA{A: 0}, color.NRGBA{A: 0xff})
+	c.Check(nrgba(f(0.5)).A, check.Equals, uint8(0x80))
+
+	// A single stop ramp is constant.
+	f = Ramp(red)
+	for _, v := range []float64{0, 0.5, 1} {
+		c.Check(nrgba(f(v)), check.Equals, red)
+	}
+
+	c.Check(func() { Ramp() }, check.Panics, "palette: no color stops")
+}
+
+func (s *S) TestDiverging(c *check.C) {
+	neg := color.NRGBA{R: 0xb2, G: 0x18, B: 0x2b, A: 0xff}
+	mid := color.NRGBA{R: 0xf7, G: 0xf7, B: 0xf7, A: 0xff}
+	pos := color.NRGBA{R: 0x21, G: 0x66, B: 0xac, A: 0xff}
+	f := Diverging(neg, mid, pos)
+	c.Check(nrgba(f(0)), check.Equals, neg)
+	c.Check(nrgba(f(0.5)), check.Equals, mid)
+	c.Check(nrgba(f(1)), check.Equals, pos)
+
+	// Values either side of the midpoint are lighter than the ends.
+	for _, v := range []float64{0.25, 0.75} {
+		l := labOf(nrgba(f(v))).l
+		c.Check(l > labOf(neg).l && l > labOf(pos).l && l < labOf(mid).l, check.Equals, true, check.Commentf("value %v L* %v", v, l))
+	}
+
+	f = RedBlue()
+	c.Check(nrgba(f(0)), check.Equals, neg)
+	c.Check(nrgba(f(0.5)), check.Equals, mid)
+	c.Check(nrgba(f(1)), check.Equals, pos)
+}
+
+func (s *S) TestNamed(c *check.C) {
+	for _, t := range []struct {
+		name       string
+		f          ColorFunc
+		start, end color.NRGBA
+	}{
+		{name: "viridis", f: Viridis(), start: color.NRGBA{R: 0x44, G: 0x01, B: 0x54, A: 0xff}, end: color.NRGBA{R: 0xfd, G: 0xe7, B: 0x25, A: 0xff}},
+		{name: "greys", f: Greys(), start: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, end: color.NRGBA{A: 0xff}},
+	} {
+		c.Check(nrgba(t.f(0)), check.Equals, t.start, check.Commentf("%s", t.name))
+		c.Check(nrgba(t.f(1)), check.Equals, t.end, check.Commentf("%s", t.name))
+	}
+
+	// Viridis increases in lightness.
+	f := Viridis()
+	prev := math.Inf(-1)
+	for i := 0; i <= 32; i++ {
+		l := labOf(nrgba(f(float64(i) / 32))).l
+		c.Check(l > prev, check.Equals, true, check.Commentf("step %d L* %v <= %v", i, l, prev))
+		prev = l
+	}
+}
+
+func (s *S) TestDiscretize(c *check.C) {
+	f := Ramp(color.Black, color.White)
+	c.Check(Discretize(f, 0), check.HasLen, 0)
+	c.Check(Discretize(f, 1), check.DeepEquals, []color.Color{f(0.5)})
+
+	p := Discretize(f, 5)
+	c.Assert(p, check.HasLen, 5)
+	for i, col := range p {
+		c.Check(col, check.Equals, f(float64(i)/4))
+	}
+	c.Check(nrgba(p[0]), check.Equals, nrgba(color.Black))
+	c.Check(nrgba(p[2]), check.Equals, color.NRGBA{R: 0x77, G: 0x77, B: 0x77, A: 0xff})
+	c.Check(nrgba(p[4]), check.Equals, nrgba(color.White))
+}
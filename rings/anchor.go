@@ -0,0 +1,75 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// ExternalAnchor is an end of a Links or Ribbons that lies at a point outside the rings
+// rather than on the arcs of features, for example on the edge of a panel drawn beside
+// the plot. Every feature at an ExternalAnchor end is joined to the anchor, and the
+// curves to the anchor are generated by the Bezier ControlPointsTo method. The other
+// end of a Links or Ribbons with an ExternalAnchor end must be held by the rings.
+type ExternalAnchor struct {
+	// Point is the position of the anchor relative to the center of the plot.
+	// Point must lie outside the radius of the other end of the Links or
+	// Ribbons.
+	Point vg.Point
+
+	// Width is the width of the ends of ribbons at the anchor. The end of
+	// a ribbon is a straight line of length Width centered on Point and
+	// perpendicular to the direction of Point from the center. Links end
+	// at Point whatever the Width.
+	Width vg.Length
+}
+
+// Arc returns the arc subtended at the center by the end of a ribbon at the anchor.
+func (a *ExternalAnchor) Arc() Arc {
+	theta, r := Polar(a.Point)
+	half := Angle(math.Atan2(float64(a.Width/2), float64(r)))
+	return Arc{Theta: theta - half, Phi: 2 * half}
+}
+
+// ArcOf returns the arc of the anchor for any loc and f. The returned error is always
+// nil.
+func (a *ExternalAnchor) ArcOf(loc, f feat.Feature) (Arc, error) { return a.Arc(), nil }
+
+// radius returns the distance from the center of the ends of the end of a ribbon at
+// the anchor.
+func (a *ExternalAnchor) radius() vg.Length {
+	_, r := Polar(a.Point)
+	return vg.Length(math.Hypot(float64(r), float64(a.Width/2)))
+}
+
+// anchorOf returns the ExternalAnchor of end and whether end is an ExternalAnchor.
+func anchorOf(end ArcOfer) (*ExternalAnchor, bool) {
+	a, ok := end.(*ExternalAnchor)
+	return a, ok && a != nil
+}
+
+// endControlPoints returns the Bézier control points of b for a curve from the end at
+// angles[0] and radii[0] to the end at angles[1] and radii[1], the ends being held by
+// the corresponding elements of ends. If either end is an ExternalAnchor, the points
+// are given by ControlPointsTo, otherwise by ControlPoints.
+func endControlPoints(b *Bezier, ends [2]ArcOfer, angles [2]Angle, radii [2]vg.Length) []vg.Point {
+	for j, e := range ends {
+		if _, ok := anchorOf(e); !ok {
+			continue
+		}
+		pts := b.ControlPointsTo(angles[1-j], radii[1-j], Rectangular(angles[j], radii[j]))
+		if j == 0 {
+			for i, k := 0, len(pts)-1; i < k; i, k = i+1, k-1 {
+				pts[i], pts[k] = pts[k], pts[i]
+			}
+		}
+		return pts
+	}
+	return b.ControlPoints(angles, radii)
+}
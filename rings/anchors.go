@@ -0,0 +1,124 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gonum/plot/vg"
+)
+
+// Anchor is a position computed by a ring when it is drawn, described by an angle and
+// a radius about the center of the ring.
+type Anchor struct {
+	// Center is the center of the ring providing the anchor.
+	Center vg.Point
+
+	// Angle is the angle of the anchor about Center.
+	Angle Angle
+
+	// Radius is the distance of the anchor from Center.
+	Radius vg.Length
+}
+
+// Point returns the position of the anchor.
+func (a Anchor) Point() vg.Point { return RectangularAt(a.Center, a.Angle, a.Radius) }
+
+// Anchors is a registry of named anchors shared between plotters, so that a plotter may
+// be drawn relative to positions computed by the rings drawn before it. Rings register
+// their anchors when they are drawn, so a plotter resolving an anchor must be drawn after
+// the ring providing it. Plotters added to a gonum plot are drawn in the order they are
+// added.
+//
+// Blocks with Anchors register, for the block of each feature, the anchors
+//
+//	<name>/<feature name>/start
+//	<name>/<feature name>/mid
+//	<name>/<feature name>/end
+//
+// at the radial centre of the block and the start, centre and end of its arc, and the
+// anchors <name>/inner and <name>/outer at the inner and outer radii of the ring at the
+// start of the base arc, where name is the AnchorName of the Blocks or "blocks" if it is
+// empty. An Axis with Anchors registers <name>/tick/<label> at the radius of each
+// labelled major tick, where name is the AnchorName of the Axis or "axis" if it is empty.
+type Anchors struct {
+	mu        sync.Mutex
+	resolvers map[string]func() Anchor
+}
+
+// NewAnchors returns a new empty Anchors.
+func NewAnchors() *Anchors { return &Anchors{} }
+
+// Register registers the anchor returned by resolver under name, replacing any anchor
+// previously registered under name. The resolver is called each time the anchor is
+// resolved.
+func (a *Anchors) Register(name string, resolver func() Anchor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.resolvers == nil {
+		a.resolvers = make(map[string]func() Anchor)
+	}
+	a.resolvers[name] = resolver
+}
+
+// Resolve returns the anchor registered under name. An error is returned if no anchor
+// has been registered under name, which is the case if the ring providing it has not
+// yet been drawn.
+func (a *Anchors) Resolve(name string) (Anchor, error) {
+	a.mu.Lock()
+	resolver, ok := a.resolvers[name]
+	a.mu.Unlock()
+	if !ok {
+		return Anchor{}, fmt.Errorf("rings: anchor %q not registered: its provider may not have been drawn", name)
+	}
+	return resolver(), nil
+}
+
+// Names returns the sorted names of the registered anchors.
+func (a *Anchors) Names() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	names := make([]string, 0, len(a.resolvers))
+	for name := range a.resolvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reset removes all registered anchors.
+func (a *Anchors) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.resolvers = nil
+}
+
+// clear removes all registered anchors with names starting with prefix.
+func (a *Anchors) clear(prefix string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name := range a.resolvers {
+		if strings.HasPrefix(name, prefix) {
+			delete(a.resolvers, name)
+		}
+	}
+}
+
+// register registers the fixed anchor at angle and radius about cen under name.
+func (a *Anchors) register(name string, cen vg.Point, angle Angle, radius vg.Length) {
+	anchor := Anchor{Center: cen, Angle: angle, Radius: radius}
+	a.Register(name, func() Anchor { return anchor })
+}
+
+// anchorName returns name, or def if name is empty.
+func anchorName(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
@@ -6,10 +6,12 @@ package rings
 
 import (
 	"errors"
+	"fmt"
 	"math"
 
 	"github.com/biogo/biogo/feat"
 	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
 )
 
 // Arcer is a type that describes an arc of circle.
@@ -24,9 +26,33 @@ type ArcOfer interface {
 	// ArcOf must return a non-nil error if the feat.Feature is not found by
 	// the receiver or the query is nil, unless the receiver is an Arc. When
 	// the receiver is an Arc the error returned is always nil.
+	//
+	// Rings obtain the angles of positions within features from ArcOf, so an
+	// ArcOfer may map positions to angles non-linearly, as Blocks does for its
+	// Zooms.
 	ArcOf(loc, f feat.Feature) (Arc, error)
 }
 
+// Offsetter is an ArcOfer that radially displaces the rendering of the features mapped
+// to its span. Blocks, Spokes, Scores, Labels, Links, Texts, Tiles and Connectors rings
+// rendering features located by an Offsetter displace each feature's rendering by the
+// feature's offset.
+type Offsetter interface {
+	ArcOfer
+
+	// OffsetOf returns the radial displacement of the rendering of f within loc.
+	OffsetOf(loc, f feat.Feature) vg.Length
+}
+
+// offsetOf returns the radial displacement of f within loc given by base, or zero if
+// base is not an Offsetter.
+func offsetOf(base ArcOfer, loc, f feat.Feature) vg.Length {
+	if o, ok := base.(Offsetter); ok {
+		return o.OffsetOf(loc, f)
+	}
+	return 0
+}
+
 // Point represents a 2-D point.
 type Point struct {
 	X, Y float64
@@ -44,6 +70,12 @@ func Rectangular(theta Angle, r vg.Length) vg.Point {
 	return vg.Point{X: vg.Length(math.Cos(float64(theta)) * float64(r)), Y: vg.Length(math.Sin(float64(theta)) * float64(r))}
 }
 
+// RectangularAt returns the rectangular coordinates for the location defined by theta and r
+// in polar coordinates around cen.
+func RectangularAt(cen vg.Point, theta Angle, r vg.Length) vg.Point {
+	return cen.Add(Rectangular(theta, r))
+}
+
 // Polar returns the polar coordinates of a point.
 func Polar(p vg.Point) (theta Angle, r vg.Length) {
 	if (p == vg.Point{0, 0}) {
@@ -53,6 +85,12 @@ func Polar(p vg.Point) (theta Angle, r vg.Length) {
 }
 
 // Angle represents an angle in radians. Angles increase in the counter clockwise direction.
+//
+// The winding of a ring is the direction of the sweep of its base arc: counter
+// clockwise for a positive Phi and clockwise for a negative Phi. Geometry that is
+// offset along the arc from a position, such as the ticks of an Axis, is offset in
+// the winding direction, so a ring drawn with a clockwise base is the mirror image
+// of the same ring drawn with a counter clockwise base.
 type Angle float64
 
 var (
@@ -61,12 +99,83 @@ var (
 )
 
 const (
+	// Clockwise and CounterClockwise are the signs of the sweeps of arcs
+	// in the clockwise and counter clockwise directions. Multiplying an
+	// angle by a direction gives the angle swept in that direction.
 	Clockwise        Angle = -1
 	CounterClockwise Angle = 1
 
-	Complete Angle = Angle(2 * math.Pi)
+	// Complete is the angle of a complete circle. AngleHalf, AngleQuarter
+	// and AngleEighth are the angles of fractions of a circle, and
+	// AngleDegree is the angle of one degree.
+	Complete     Angle = Angle(2 * math.Pi)
+	AngleHalf          = Complete / 2
+	AngleQuarter       = Complete / 4
+	AngleEighth        = Complete / 8
+	AngleDegree        = Complete / 360
 )
 
+// MinFeatureAngle is the default minimum angular width of the rendered mark of a
+// feature. Blocks and Tiles render features with narrower arcs, including zero length
+// features, as marks of the minimum width centred on the feature.
+var MinFeatureAngle = Angle(0.05 * math.Pi / 180)
+
+// minFeatureAngle returns the minimum angular width of a feature mark given the
+// per-type override min. A zero min specifies MinFeatureAngle and a negative min
+// specifies no minimum.
+func minFeatureAngle(min Angle) Angle {
+	switch {
+	case min == 0:
+		return MinFeatureAngle
+	case min < 0:
+		return 0
+	}
+	return min
+}
+
+// widen returns arc widened about its middle to sweep at least min, retaining the
+// direction of the arc.
+func widen(arc Arc, min Angle) Arc {
+	if math.Abs(float64(arc.Phi)) >= float64(min) {
+		return arc
+	}
+	if arc.Phi < 0 {
+		min = -min
+	}
+	return Arc{Theta: arc.Theta + arc.Phi/2 - min/2, Phi: min}
+}
+
+// padded returns arc, the arc of a feature held by loc in base, mapped linearly into
+// the arc of loc with pad removed from each of its ends. If pad is at least half the
+// sweep of the arc of loc, arc is mapped to the middle of the arc of loc. Arcs of
+// features without a location are returned unaltered.
+func padded(base ArcOfer, loc feat.Feature, arc Arc, pad Angle) (Arc, error) {
+	if pad <= 0 || loc == nil {
+		return arc, nil
+	}
+	la, err := base.ArcOf(nil, loc)
+	if err != nil || la.Phi == 0 {
+		return arc, err
+	}
+	if la.Phi < 0 {
+		pad = -pad
+	}
+	if math.Abs(float64(pad)) > math.Abs(float64(la.Phi/2)) {
+		pad = la.Phi / 2
+	}
+	scale := (la.Phi - 2*pad) / la.Phi
+	return Arc{Theta: la.Theta + pad + (arc.Theta-la.Theta)*scale, Phi: arc.Phi * scale}, nil
+}
+
+// windingOf returns the direction of the sweep of arc, Clockwise if its Phi is
+// negative and CounterClockwise otherwise.
+func windingOf(arc Arc) Angle {
+	if arc.Phi < 0 {
+		return Clockwise
+	}
+	return CounterClockwise
+}
+
 // Arc represents an arc of a circle.
 type Arc struct {
 	Theta Angle // Initial angle of an arc in radians.
@@ -93,6 +202,73 @@ func (a Arc) Contains(alpha Angle) bool {
 type Arcs struct {
 	Base Arc                  // Base represents the complete span of the Arcs.
 	Arcs map[feat.Feature]Arc // Arcs provides a lookup for features within the span.
+
+	// Boundary specifies how features extending beyond the end
+	// of their location are mapped to arcs.
+	Boundary BoundaryPolicy
+}
+
+// BoundaryPolicy specifies how the positions of a feature extending beyond the end
+// of its location are mapped to angles.
+type BoundaryPolicy int
+
+const (
+	Clamp  BoundaryPolicy = iota // Clamp pins positions beyond the end of a location to the end of its arc.
+	Strict                       // Strict rejects features extending beyond the end of their location.
+	Wrap                         // Wrap maps positions beyond the end of a location past the end of its arc.
+)
+
+// end returns the end position of f in the context of loc according to the policy.
+func (b BoundaryPolicy) end(loc, f feat.Feature) (int, error) {
+	end := f.End()
+	if end <= loc.End() {
+		return end, nil
+	}
+	switch b {
+	case Clamp:
+		return loc.End(), nil
+	case Strict:
+		return 0, &BoundaryError{Feature: f, Location: loc}
+	case Wrap:
+		return end, nil
+	default:
+		panic("rings: unknown boundary policy")
+	}
+}
+
+// boundaryOf returns the boundary policy of a, or Clamp if a does not have one.
+func boundaryOf(a ArcOfer) BoundaryPolicy {
+	switch a := a.(type) {
+	case Arcs:
+		return a.Boundary
+	case *Arcs:
+		return a.Boundary
+	case *Layout:
+		return a.Boundary
+	case Lens:
+		return boundaryOf(a.ArcOfer)
+	case *Blocks:
+		return boundaryOf(a.Base)
+	case *NestedArcs:
+		return a.Boundary
+	default:
+		return Clamp
+	}
+}
+
+// BoundaryError is the error describing a feature that is not contained within
+// its location.
+type BoundaryError struct {
+	Feature  feat.Feature
+	Location feat.Feature
+}
+
+func (e *BoundaryError) Error() string {
+	f, loc := e.Feature, e.Location
+	if f.Start() < loc.Start() || f.Start() > loc.End() {
+		return fmt.Sprintf("rings: feature %q out of range of location %q", f.Name(), loc.Name())
+	}
+	return fmt.Sprintf("rings: feature %q extends beyond end of location %q", f.Name(), loc.Name())
 }
 
 // NewGappedArcs returns an Arcs that maps the provided features to the base arc with
@@ -148,11 +324,15 @@ func (a Arcs) ArcOf(loc, f feat.Feature) (Arc, error) {
 		if f.Start() < loc.Start() || f.Start() > loc.End() {
 			return arcNaN, errors.New("rings: feature out of range")
 		}
+		fe, err := a.Boundary.end(loc, f)
+		if err != nil {
+			return arcNaN, err
+		}
 		if fa, ok := a.containingArcOf(loc); ok {
 			min, max := loc.Start(), loc.End()
 
 			scale := fa.Phi / Angle(max-min)
-			start, end := Angle(f.Start()-min)*scale, Angle(f.End()-min)*scale
+			start, end := Angle(f.Start()-min)*scale, Angle(fe-min)*scale
 
 			return Arc{start + fa.Theta, end - start}, nil
 		}
@@ -213,3 +393,185 @@ func (l Lens) ArcOf(loc, f feat.Feature) (Arc, error) {
 	}
 	return l.Optics(loc, f, l.ArcOfer.Arc(), arc)
 }
+
+// OffsetOf returns the offset returned by the embedded ArcOfer if it is an Offsetter,
+// otherwise zero.
+func (l Lens) OffsetOf(loc, f feat.Feature) vg.Length { return offsetOf(l.ArcOfer, loc, f) }
+
+// Sector represents an annular sector of a circle; the region between the Inner
+// and Outer radii around Center swept by the sector's Arc.
+type Sector struct {
+	Center       vg.Point
+	Inner, Outer vg.Length
+	Arc
+}
+
+// Path appends the outline of the sector to pa. If the sector describes a complete
+// circle and disjoint is true, the inner and outer arcs of the outline are not joined.
+func (s Sector) Path(pa *vg.Path, disjoint bool) {
+	pa.Move(RectangularAt(s.Center, s.Theta, s.Inner))
+	pa.Arc(s.Center, s.Inner, float64(s.Theta), float64(s.Phi))
+	if disjoint && (s.Phi == Clockwise*Complete || s.Phi == CounterClockwise*Complete) {
+		pa.Move(RectangularAt(s.Center, s.Theta+s.Phi, s.Outer))
+	}
+	pa.Arc(s.Center, s.Outer, float64(s.Theta+s.Phi), float64(-s.Phi))
+	pa.Close()
+}
+
+// AnnularWedge appends the outline of the annular sector between the inner and outer
+// radii around cen swept by arc to pa. The outline is built from arc path components,
+// rather than line segment approximations, so it renders exactly on vector canvases.
+// The outline runs along the inner radius in the direction of arc and returns along
+// the outer radius before it is closed.
+func AnnularWedge(pa *vg.Path, cen vg.Point, inner, outer vg.Length, arc Arc) {
+	Sector{Center: cen, Inner: inner, Outer: outer, Arc: arc}.Path(pa, false)
+}
+
+// Position returns the fractional position of p along the arc of the sector, with 0
+// at the sector's Theta and 1 at Theta+Phi, and whether p lies within the sector.
+func (s Sector) Position(p vg.Point) (frac float64, ok bool) {
+	theta, r := Polar(p.Sub(s.Center))
+	if r < s.Inner || r > s.Outer {
+		return 0, false
+	}
+	return s.Arc.position(theta)
+}
+
+// position returns the fractional position of theta along the arc, and whether the
+// angle lies within the arc.
+func (a Arc) position(theta Angle) (frac float64, ok bool) {
+	switch {
+	case a.Phi == 0:
+		return 0, Normalize(theta) == Normalize(a.Theta)
+	case math.Abs(float64(a.Phi)) >= float64(Complete):
+		frac = float64(Normalize(theta-a.Theta) / Complete)
+		if a.Phi < 0 {
+			frac = float64(Normalize(a.Theta-theta) / Complete)
+		}
+		return frac, true
+	case a.Phi > 0:
+		frac = float64(Normalize(theta-a.Theta) / a.Phi)
+	default:
+		frac = float64(Normalize(a.Theta-theta) / -a.Phi)
+	}
+	return frac, frac <= 1
+}
+
+// bounds returns the bounding box, relative to the arc's center, of the annular sector
+// described by the arc between the inner and outer radii.
+func (a Arc) bounds(inner, outer vg.Length) vg.Rectangle {
+	if math.Abs(float64(a.Phi)) >= float64(Complete) {
+		return vg.Rectangle{Min: vg.Point{-outer, -outer}, Max: vg.Point{outer, outer}}
+	}
+	pts := []vg.Point{
+		Rectangular(a.Theta, inner), Rectangular(a.Theta, outer),
+		Rectangular(a.Theta+a.Phi, inner), Rectangular(a.Theta+a.Phi, outer),
+	}
+	for q := Angle(0); q < Complete; q += Complete / 4 {
+		if _, ok := a.position(q); ok {
+			pts = append(pts, Rectangular(q, outer))
+		}
+	}
+	b := vg.Rectangle{Min: pts[0], Max: pts[0]}
+	for _, p := range pts[1:] {
+		b.Min.X, b.Min.Y = vg.Length(math.Min(float64(b.Min.X), float64(p.X))), vg.Length(math.Min(float64(b.Min.Y), float64(p.Y)))
+		b.Max.X, b.Max.Y = vg.Length(math.Max(float64(b.Max.X), float64(p.X))), vg.Length(math.Max(float64(b.Max.Y), float64(p.Y)))
+	}
+	return b
+}
+
+// ArcDashes specifies a dash pattern for stroking arcs in angular units. Arcs stroked
+// with an ArcDashes are rendered as a sequence of sub-arcs, so dashes subtend the same
+// angle at every radius.
+type ArcDashes struct {
+	// Angles holds the alternating sweeps of the dashes and gaps of the pattern.
+	// If Angles is empty or sums to zero, arcs are stroked as solid lines subject
+	// to the dashes of the line style.
+	Angles []Angle
+
+	// Offset is the angle at which the pattern starts. The pattern is anchored at
+	// Offset rather than at the start of each arc, so dashes of concentric arcs
+	// are radially aligned.
+	Offset Angle
+}
+
+// period returns the sweep of the dash pattern.
+func (d ArcDashes) period() Angle {
+	var p Angle
+	for _, a := range d.Angles {
+		p += Angle(math.Abs(float64(a)))
+	}
+	return p
+}
+
+// isSet returns whether the ArcDashes specifies a dash pattern.
+func (d ArcDashes) isSet() bool { return d.period() > 0 }
+
+// style returns the line style to stroke paths made by the ArcDashes with. If the
+// ArcDashes specifies a dash pattern the returned style has no dashes.
+func (d ArcDashes) style(sty draw.LineStyle) draw.LineStyle {
+	if d.isSet() {
+		sty.Dashes, sty.DashOffs = nil, 0
+	}
+	return sty
+}
+
+// arcPath appends the arc of radius rad around cen to pa, dashed according to the
+// dash pattern of d if it is set.
+func (d ArcDashes) arcPath(pa *vg.Path, cen vg.Point, rad vg.Length, arc Arc) {
+	period := d.period()
+	if period == 0 {
+		pa.Move(RectangularAt(cen, arc.Theta, rad))
+		pa.Arc(cen, rad, float64(arc.Theta), float64(arc.Phi))
+		return
+	}
+
+	// Dashes are emitted counter-clockwise from the start of the arc, independent
+	// of its winding.
+	if arc.Phi < 0 {
+		arc = Arc{arc.Theta + arc.Phi, -arc.Phi}
+	}
+	pos := Angle(math.Mod(float64(arc.Theta-d.Offset), float64(period)))
+	if pos < 0 {
+		pos += period
+	}
+	var i int
+	for ; ; i = (i + 1) % len(d.Angles) {
+		a := Angle(math.Abs(float64(d.Angles[i])))
+		if pos < a {
+			pos = a - pos
+			break
+		}
+		pos -= a
+	}
+	for theta, end := arc.Theta, arc.Theta+arc.Phi; theta < end; {
+		sweep := Angle(math.Min(float64(pos), float64(end-theta)))
+		if i%2 == 0 && sweep > 0 {
+			pa.Move(RectangularAt(cen, theta, rad))
+			pa.Arc(cen, rad, float64(theta), float64(sweep))
+		}
+		theta += sweep
+		i = (i + 1) % len(d.Angles)
+		pos = Angle(math.Abs(float64(d.Angles[i])))
+	}
+}
+
+// sectorPath appends the outline of the sector to pa with its arcs dashed according
+// to the dash pattern of d. If the dash pattern is not set, the outline is the path
+// given by the sector's Path method.
+func (d ArcDashes) sectorPath(pa *vg.Path, s Sector) {
+	if !d.isSet() {
+		s.Path(pa, true)
+		return
+	}
+	if s.Inner > 0 {
+		d.arcPath(pa, s.Center, s.Inner, s.Arc)
+	}
+	d.arcPath(pa, s.Center, s.Outer, s.Arc)
+	if math.Abs(float64(s.Phi)) < float64(Complete) {
+		for _, theta := range []Angle{s.Theta, s.Theta + s.Phi} {
+			pa.Move(RectangularAt(s.Center, theta, s.Inner))
+			pa.Line(RectangularAt(s.Center, theta, s.Outer))
+		}
+	}
+}
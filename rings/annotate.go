@@ -0,0 +1,264 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/bezier"
+)
+
+// DefaultAnnotationSegments is the number of segments used to render the connectors
+// of a PeakAnnotations returned by AnnotatePeaks.
+const DefaultAnnotationSegments = 20
+
+// PeakAnnotation is a score selected for annotation by a PeakAnnotations.
+type PeakAnnotation struct {
+	// Scorer is the Scorer holding the score and Index is the
+	// index of the score in the Scorer's Scores.
+	Scorer Scorer
+	Index  int
+
+	// Value is the annotated score.
+	Value float64
+
+	// Angle and Radius are the position of the score, at the
+	// angular midpoint of the Scorer's arc and the radius of the
+	// score given the score range of the Scores.
+	Angle  Angle
+	Radius vg.Length
+}
+
+// PeakAnnotations implements rendering of callouts annotating selected scores of a
+// Scores ring, each joined to the position of its score by a curved connector. The
+// annotated scores, their positions and the placement of the callouts are resolved
+// when the PeakAnnotations is drawn, so they follow changes to the Scores' Set and
+// score range after construction.
+type PeakAnnotations struct {
+	// Scores is the annotated Scores ring.
+	Scores *Scores
+
+	// Select returns whether the score v at index idx of the Scores of the
+	// Scorer f is annotated. NaN scores are not annotated.
+	Select func(f feat.Feature, idx int, v float64) bool
+
+	// Format returns the callout text of an annotated score. If Format is nil,
+	// the shortest representation of the score is used.
+	Format func(f feat.Feature, idx int, v float64) string
+
+	// Callouts holds the configuration of the callout boxes: their Radius, Box,
+	// TextStyle, Spread and Push. The Set, Base, Anchor and LeaderStyle of
+	// Callouts are ignored.
+	Callouts Callouts
+
+	// LineStyle determines the line style of the connectors.
+	LineStyle draw.LineStyle
+
+	// Fade, if not nil, is the color that connectors are graded towards from
+	// the color of the LineStyle at the score to Fade at the callout.
+	Fade color.Color
+
+	// Segments specifies the number of segments used to render each connector
+	// as a Bézier curve leaving the score radially and meeting its callout. If
+	// Segments is less than 2 the score is joined to its callout with a straight
+	// line.
+	Segments int
+
+	// Layer specifies the drawing layer of the PeakAnnotations when rendered
+	// by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// AnnotatePeaks returns a PeakAnnotations annotating the scores of s selected by sel
+// with callouts placed outside the outer radius of s. The provided options are then
+// applied in order. The returned PeakAnnotations uses the X and Y values of s. An error
+// is returned if s or sel is nil or an option fails.
+func AnnotatePeaks(s *Scores, sel func(f feat.Feature, idx int, v float64) bool, opts ...PeakOption) (*PeakAnnotations, error) {
+	if s == nil {
+		return nil, errors.New("rings: nil scores")
+	}
+	if sel == nil {
+		return nil, errors.New("rings: nil peak selector")
+	}
+	r := &PeakAnnotations{
+		Scores:   s,
+		Select:   sel,
+		Callouts: Callouts{Radius: s.Outer + (s.Outer-s.Inner)/2},
+		Segments: DefaultAnnotationSegments,
+		X:        s.X,
+		Y:        s.Y,
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Annotations returns the scores selected for annotation, in the order of the Scorers
+// rendered by the Scores, resolved against the current state of the Scores. An error
+// is returned if a Scorer has no arc in the Base of the Scores.
+func (r *PeakAnnotations) Annotations() ([]PeakAnnotation, error) {
+	s := r.Scores
+	var (
+		anns   []PeakAnnotation
+		ranges = make(map[feat.Feature][2]float64)
+	)
+	for _, f := range s.scorers() {
+		loc := f.Location()
+		if f.Start() < loc.Start() || f.End() > loc.End() {
+			continue
+		}
+		var (
+			arc    Arc
+			hasArc bool
+		)
+		for i, v := range f.Scores() {
+			if math.IsNaN(v) || !r.Select(f, i, v) {
+				continue
+			}
+			if !hasArc {
+				var err error
+				arc, err = s.arcOf(loc, f)
+				if err != nil {
+					return nil, err
+				}
+				hasArc = true
+			}
+			rng, ok := ranges[loc]
+			if !ok {
+				rng[0], rng[1] = s.LocationRange(loc)
+				ranges[loc] = rng
+			}
+			rad, _ := s.scoreRadius(f, v, rng[0], rng[1])
+			anns = append(anns, PeakAnnotation{
+				Scorer: f,
+				Index:  i,
+				Value:  v,
+				Angle:  arc.Theta + arc.Phi/2,
+				Radius: rad,
+			})
+		}
+	}
+	return anns, nil
+}
+
+// text returns the callout text of a.
+func (r *PeakAnnotations) text(a PeakAnnotation) string {
+	if r.Format == nil {
+		return strconv.FormatFloat(a.Value, 'g', -1, 64)
+	}
+	return r.Format(a.Scorer, a.Index, a.Value)
+}
+
+// boxes returns the placed callout boxes of anns relative to the center of the rendering.
+func (r *PeakAnnotations) boxes(anns []PeakAnnotation) []vg.Rectangle {
+	angles := make([]Angle, len(anns))
+	texts := make([]string, len(anns))
+	for i, a := range anns {
+		angles[i], texts[i] = a.Angle, r.text(a)
+	}
+	return r.Callouts.place(angles, texts)
+}
+
+// DrawAt renders the annotations of a PeakAnnotations at cen in the specified drawing
+// area, according to the PeakAnnotations configuration.
+func (r *PeakAnnotations) DrawAt(ca draw.Canvas, cen vg.Point) {
+	anns, err := r.Annotations()
+	if err != nil {
+		panic(fmt.Sprint("rings: no arc for feature location: ", err))
+	}
+	if len(anns) == 0 {
+		return
+	}
+
+	var pa vg.Path
+	for i, b := range r.boxes(anns) {
+		a := anns[i]
+		b = vg.Rectangle{Min: cen.Add(b.Min), Max: cen.Add(b.Max)}
+		rad := cornerRadius(b, r.Callouts.Box.Radius)
+		from := RectangularAt(cen, a.Angle, a.Radius)
+		to := nearestOnBox(b, rad, RectangularAt(cen, a.Angle, r.Callouts.Radius))
+		r.connect(ca, &pa, from, RectangularAt(cen, a.Angle, (a.Radius+r.Callouts.Radius)/2), to)
+		r.Callouts.drawBox(ca, &pa, b, rad, r.text(a))
+	}
+}
+
+// connect strokes the connector from the score at from to the callout at to, leaving
+// the score towards ctl, using pa as scratch space.
+func (r *PeakAnnotations) connect(ca draw.Canvas, pa *vg.Path, from, ctl, to vg.Point) {
+	sty := r.LineStyle
+	if sty.Color == nil || sty.Width == 0 {
+		return
+	}
+	pts := []vg.Point{from, to}
+	if r.Segments > 1 {
+		b := bezier.New(from, ctl, to)
+		pts = pts[:1]
+		for i := 1; i <= r.Segments; i++ {
+			pts = append(pts, b.Point(float64(i)/float64(r.Segments)))
+		}
+	}
+	if r.Fade == nil {
+		*pa = (*pa)[:0]
+		pa.Move(pts[0])
+		for _, p := range pts[1:] {
+			pa.Line(p)
+		}
+		ca.SetLineStyle(sty)
+		ca.Stroke(*pa)
+		return
+	}
+	n := len(pts) - 1
+	for i := 0; i < n; i++ {
+		sty.Color = lerpColor(r.LineStyle.Color, r.Fade, (float64(i)+0.5)/float64(n))
+		*pa = (*pa)[:0]
+		pa.Move(pts[i])
+		pa.Line(pts[i+1])
+		ca.SetLineStyle(sty)
+		ca.Stroke(*pa)
+	}
+}
+
+// XY returns the x and y coordinates of the PeakAnnotations.
+func (r *PeakAnnotations) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the PeakAnnotations.
+func (r *PeakAnnotations) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the PeakAnnotations' X and Y values as the drawing coordinates.
+func (r *PeakAnnotations) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a glyphbox for the annotation rendering including the placed
+// callout boxes.
+func (r *PeakAnnotations) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	bounds := r.Scores.Base.Arc().bounds(0, r.Scores.Outer)
+	if anns, err := r.Annotations(); err == nil {
+		for _, b := range r.boxes(anns) {
+			bounds = union(bounds, b)
+		}
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: bounds,
+	}}
+}
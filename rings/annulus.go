@@ -0,0 +1,118 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Annulus implements rendering a background for a ring. An Annulus with a zero
+// Inner radius is rendered as a disc or, if restricted to an arc, a circular sector.
+//
+// Plotters added to a gonum plot are drawn in the order they are added, so an Annulus
+// should be added before the rings it forms the background of, and will be occluded
+// by any rings added after it.
+type Annulus struct {
+	// Inner and Outer define the inner and outer radii of the annulus.
+	Inner, Outer vg.Length
+
+	// Arc restricts the annulus to the specified arc. If the Phi of Arc is zero the
+	// complete annulus is rendered.
+	Arc Arc
+
+	// Color determines the fill color of the annulus. If Color is nil no fill is performed.
+	Color color.Color
+
+	// LineStyle determines the line style of the border of the annulus.
+	LineStyle draw.LineStyle
+
+	// ArcDashes, if set, specifies the dash pattern of the arcs of the border in
+	// angular units in place of the dashes of the LineStyle. The radial edges of
+	// the border of an annulus restricted to an arc are rendered solid.
+	ArcDashes ArcDashes
+
+	// Layer specifies the drawing layer of the Annulus when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewAnnulus returns an Annulus filled with the specified color between the inner and
+// outer radii.
+func NewAnnulus(col color.Color, inner, outer vg.Length) *Annulus {
+	return &Annulus{
+		Inner: inner,
+		Outer: outer,
+		Color: col,
+	}
+}
+
+// DrawAt renders the Annulus at cen in the specified drawing area, according to the
+// Annulus configuration.
+func (r *Annulus) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if r.Color == nil && (r.LineStyle.Color == nil || r.LineStyle.Width == 0) {
+		return
+	}
+
+	var pa vg.Path
+	annulusPath(&pa, cen, r.Inner, r.Outer, r.Arc)
+
+	if r.Color != nil {
+		ca.SetColor(r.Color)
+		ca.Fill(pa)
+	}
+	if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
+		if r.ArcDashes.isSet() {
+			arc := r.Arc
+			if arc.Phi == 0 {
+				arc = Arc{0, Complete}
+			}
+			pa = pa[:0]
+			r.ArcDashes.sectorPath(&pa, Sector{Center: cen, Inner: r.Inner, Outer: r.Outer, Arc: arc})
+		}
+		ca.SetLineStyle(r.ArcDashes.style(r.LineStyle))
+		ca.Stroke(pa)
+	}
+}
+
+// annulusPath appends the outline of the annulus between the inner and outer radii
+// around cen to pa, restricted to arc if its Phi is not zero.
+func annulusPath(pa *vg.Path, cen vg.Point, inner, outer vg.Length, arc Arc) {
+	if arc.Phi == 0 {
+		arc = Arc{0, Complete}
+	}
+	Sector{Center: cen, Inner: inner, Outer: outer, Arc: arc}.Path(pa, true)
+}
+
+// XY returns the x and y coordinates of the Annulus.
+func (r *Annulus) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Annulus.
+func (r *Annulus) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Annulus' X and Y values as the drawing coordinates.
+func (r *Annulus) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the annulus rendering restricted
+// to its arc.
+func (r *Annulus) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	arc := r.Arc
+	if arc.Phi == 0 {
+		arc.Phi = Complete
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: arc.bounds(r.Inner, r.Outer),
+	}}
+}
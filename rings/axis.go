@@ -6,6 +6,9 @@ package rings
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
@@ -19,6 +22,10 @@ type Axis struct {
 	// Angle specifies the angular location of the axis.
 	Angle Angle
 
+	// Auto, if not nil, determines the angular location of the axis from
+	// the base of the ring when the axis is drawn, in place of Angle.
+	Auto AxisPlacement
+
 	// Label describes the axis label configuration.
 	Label AxisLabel
 
@@ -30,19 +37,223 @@ type Axis struct {
 
 	// Grid is the style of the grid lines.
 	Grid draw.LineStyle
+
+	// GridArcDashes, if set, specifies the dash pattern of the grid lines in
+	// angular units in place of the dashes of the Grid line style.
+	GridArcDashes ArcDashes
+
+	// Frame, if its Color and Width are set, is the style of a pair of caps
+	// marking the ends of the axis at the inner and outer radii of the ring.
+	// The caps extend from the axis line by the tick Length on the tick Side.
+	// The frame is drawn with or without tick marks and does not require a
+	// tick Marker.
+	Frame draw.LineStyle
+
+	// FrameLabels specifies that the caps of the Frame are labelled with the
+	// minimum and maximum of the axis range using the tick Label style and
+	// Format. A frame label is not drawn where a labelled major tick mark is
+	// drawn at the same value.
+	FrameLabels bool
+
+	// BreakLength is the length of the pair of slashes marking each break in
+	// the axis. If BreakLength is zero, the tick Length is used.
+	BreakLength vg.Length
+
+	// Range, if not nil, is a shared score range that the axis is drawn according
+	// to in place of the range of the renderer drawing the axis, unless the
+	// renderer is a Trace with an explicit range or rendering a Scores with an
+	// explicit range.
+	Range *Range
+
+	// MirrorTo, if not zero, is the radius to which the scale of the axis is
+	// mirrored about the radius of the minimum of its range, so that values
+	// increase in both directions from that radius, as for a track of scores
+	// rendered back to back with an inverted Scores. The axis line is extended
+	// to MirrorTo, and ticks and grid lines are also rendered at the mirrored
+	// radii of their values.
+	MirrorTo vg.Length
+
+	// Anchors, if not nil, is the registry the anchors of the labelled major
+	// ticks of the axis are registered with when the axis is drawn. The anchor
+	// names are prefixed with AnchorName, or "axis" if AnchorName is empty.
+	Anchors    *Anchors
+	AnchorName string
+
+	// from holds the Scores provided to RangeFrom.
+	from []*Scores
+}
+
+// RangeFrom specifies that the axis and the provided Scores are drawn according to
+// a common range. The Range of the axis and of each of the Scores is set to a new
+// Range including the Scores, and any explicit range of the Scores is discarded as
+// it is by the SharedRange option, so the Scores are rendered on the scale that the
+// axis is ticked according to. Calling RangeFrom with no Scores removes the Range of
+// the axis, leaving the Ranges of the Scores provided to earlier calls unchanged.
+func (r *Axis) RangeFrom(scores ...*Scores) {
+	r.from = append([]*Scores(nil), scores...)
+	if len(scores) == 0 {
+		r.Range = nil
+		return
+	}
+	rng := NewRange()
+	for _, s := range scores {
+		s.Range = rng
+		s.Min, s.Max = 0, 0
+	}
+	rng.Include(scores...)
+	r.Range = rng
+}
+
+// scale returns s with its range replaced by the range of the Axis' Range, if it is
+// set.
+func (r *Axis) scale(s radialScale) radialScale {
+	if r.Range == nil {
+		return s
+	}
+	min, max := r.Range.Values()
+	return newRadialScale(min, max, s.inner, s.outer, s.breaks)
+}
+
+// Break is an interval of values excluded from a radial scale. Values in the open
+// interval (Lo, Hi) are not rendered and Lo and Hi are rendered at the same radius.
+type Break struct {
+	Lo, Hi float64
+}
+
+// breaksOf returns the radial scale breaks of the score renderer rr.
+func breaksOf(rr ScoreRenderer) []Break {
+	switch rr := rr.(type) {
+	case *Trace:
+		return rr.Breaks
+	case *ErrorBand:
+		return rr.Breaks
+	case *Interval:
+		return rr.Breaks
+	case *Segments:
+		return rr.Breaks
+	}
+	return nil
+}
+
+// radialScale is a linear mapping of values in [min, max] to radii in [inner, outer]
+// that skips the intervals excluded by breaks.
+type radialScale struct {
+	min, max     float64
+	inner, outer vg.Length
+
+	// breaks holds the sorted, non-overlapping breaks within (min, max).
+	breaks []Break
+
+	// rs is the scaling from values to radial distance.
+	rs float64
+}
+
+// newRadialScale returns a radialScale mapping [min, max] to [inner, outer] excluding
+// the intervals in breaks.
+func newRadialScale(min, max float64, inner, outer vg.Length, breaks []Break) radialScale {
+	s := radialScale{min: min, max: max, inner: inner, outer: outer}
+	if len(breaks) != 0 {
+		bs := make([]Break, 0, len(breaks))
+		for _, b := range breaks {
+			b.Lo, b.Hi = math.Max(b.Lo, min), math.Min(b.Hi, max)
+			if b.Lo < b.Hi {
+				bs = append(bs, b)
+			}
+		}
+		sort.Sort(byLo(bs))
+		for _, b := range bs {
+			if n := len(s.breaks); n != 0 && b.Lo <= s.breaks[n-1].Hi {
+				s.breaks[n-1].Hi = math.Max(s.breaks[n-1].Hi, b.Hi)
+				continue
+			}
+			s.breaks = append(s.breaks, b)
+		}
+	}
+	span := max - min
+	for _, b := range s.breaks {
+		span -= b.Hi - b.Lo
+	}
+	s.rs = float64(outer-inner) / span
+	return s
+}
+
+type byLo []Break
+
+func (b byLo) Len() int           { return len(b) }
+func (b byLo) Less(i, j int) bool { return b[i].Lo < b[j].Lo }
+func (b byLo) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// excludes returns whether v lies outside the scale or within one of its breaks.
+func (s radialScale) excludes(v float64) bool {
+	if math.IsNaN(v) || v < s.min || s.max < v {
+		return true
+	}
+	for _, b := range s.breaks {
+		if b.Lo < v && v < b.Hi {
+			return true
+		}
+	}
+	return false
+}
+
+// shifted returns the scale displaced radially by off.
+func (s radialScale) shifted(off vg.Length) radialScale {
+	s.inner += off
+	s.outer += off
+	return s
+}
+
+// radius returns the radius of v and whether v is rendered by the scale. Values
+// outside the scale are clamped to the scale and values within a break are placed
+// at the radius of the break.
+func (s radialScale) radius(v float64) (rad vg.Length, ok bool) {
+	ok = !s.excludes(v)
+	v = math.Min(math.Max(v, s.min), s.max)
+	d := v - s.min
+	for _, b := range s.breaks {
+		if v <= b.Lo {
+			break
+		}
+		d -= math.Min(v, b.Hi) - b.Lo
+	}
+	return vg.Length(d*s.rs) + s.inner, ok
 }
 
 // AxisLabel describes an axis label format and text.
 type AxisLabel struct {
-	// Text is the axis label string.
+	// Text is the axis label string. Lines of a multi-line Text are stacked.
 	Text string
 
+	// Rich, if not nil, is rendered in place of Text as a sequence of styled runs
+	// on a single baseline. Runs with a zero font size are rendered with TextStyle.
+	Rich []StyledString
+
 	// TextStyle is the style of the axis label text.
 	draw.TextStyle
 
 	// Placement determines the text rotation and alignment.
 	// If Placement is nil, DefaultPlacement is used.
 	Placement TextPlacement
+
+	// ExactAnchor specifies that the label is placed according to the
+	// measured glyph bounds of its text rather than by the alignment returned
+	// by Placement. The bounds, rotated as returned by Placement, are placed
+	// beside the axis line on the side opposite its ticks, centered on the
+	// perpendicular to the line at its mid point with their nearest edge at
+	// the line.
+	ExactAnchor bool
+}
+
+// runs returns the styled runs of the axis label.
+func (l AxisLabel) runs() []StyledString {
+	runs := make([]StyledString, len(l.Rich))
+	for i, s := range l.Rich {
+		if s.Font.Size == 0 {
+			s.TextStyle = l.TextStyle
+		}
+		runs[i] = s
+	}
+	return runs
 }
 
 // TickConfig describes an axis tick configuration.
@@ -57,122 +268,289 @@ type TickConfig struct {
 	// If Placement is nil, DefaultPlacement is used.
 	Placement TextPlacement
 
+	// ExactAnchor specifies that tick labels are placed according
+	// to the measured glyph bounds of their text, from the ascent
+	// of the first line to the descent of the last, rather than by
+	// the alignment returned by Placement. The bounds, rotated as
+	// returned by Placement, are centered on the line from the tick
+	// through the label anchor with their nearest edge at the anchor,
+	// so that labels sit at the same distance from their ticks at
+	// every angle.
+	ExactAnchor bool
+
 	// Length is the length of a major tick mark.
 	// Minor tick marks are half of the length of major
 	// tick marks.
 	Length vg.Length
 
+	// Side specifies the side of the axis line to which
+	// tick marks and labels extend.
+	Side TickSide
+
+	// LabelOffset is the distance of the anchors of tick
+	// labels from the axis line. If LabelOffset is zero,
+	// the labels of an Axis are placed at twice the tick
+	// Length and those of a Scale at the tick Length plus
+	// the height of the label font.
+	LabelOffset vg.Length
+
 	// Marker returns the tick marks. Any tick marks
 	// returned by the Marker function that are not in
 	// range of the axis are not drawn.
 	Marker plot.Ticker
+
+	// Format, if not nil, returns the label text of each major tick mark
+	// given its value, over-riding the Label provided by the Marker.
+	// CommaFormat, SIFormat and GenomicFormat provide common formats.
+	Format func(v float64) string
+}
+
+// TickSide specifies the side of an axis line to which tick marks and labels extend.
+type TickSide int
+
+const (
+	TickOutside TickSide = iota // TickOutside extends ticks from an Axis in the winding direction of its base and away from the center from a Scale.
+	TickInside                  // TickInside extends ticks from an Axis against the winding direction of its base and towards the center from a Scale.
+)
+
+// direction returns the sign of the displacement of tick marks and labels from the
+// axis line.
+func (t TickConfig) direction() vg.Length {
+	switch t.Side {
+	case TickOutside:
+		return 1
+	case TickInside:
+		return -1
+	default:
+		panic("rings: unknown tick side")
+	}
+}
+
+// labelOffset returns the signed distance of tick label anchors from the axis line,
+// using def if the LabelOffset is zero.
+func (t TickConfig) labelOffset(def vg.Length) vg.Length {
+	if t.LabelOffset != 0 {
+		def = t.LabelOffset
+	}
+	return t.direction() * def
+}
+
+// fillLabel fills the tick label txt anchored at pt, displaced from its tick in the
+// direction dir, with the rotation and alignment returned by the Placement for the
+// angle a.
+func (t TickConfig) fillLabel(ca draw.Canvas, pt vg.Point, dir, a Angle, txt string) {
+	var (
+		rot            Angle
+		xalign, yalign float64
+	)
+	if t.Placement == nil {
+		rot, xalign, yalign = DefaultPlacement(a)
+	} else {
+		rot, xalign, yalign = t.Placement(a)
+	}
+	if t.ExactAnchor {
+		fillTextAnchored(ca, t.Label, pt, dir, rot, txt)
+		return
+	}
+	fillText(ca, t.Label, pt, rot, xalign, yalign, txt)
+}
+
+// text returns the label text of the tick mark.
+func (t TickConfig) text(mark plot.Tick) string {
+	if t.Format == nil {
+		return mark.Label
+	}
+	return t.Format(mark.Value)
+}
+
+// ResolvedTick is a tick mark of an Axis resolved to its rendered radius.
+type ResolvedTick struct {
+	// Value is the score value of the tick mark.
+	Value float64
+
+	// Label is the label text of the tick mark. Minor tick marks
+	// have an empty Label.
+	Label string
+
+	// Radius is the distance of the tick mark and its grid line
+	// from the center of the ring.
+	Radius vg.Length
+
+	// Minor is true for minor tick marks.
+	Minor bool
+
+	// Mirrored is true for tick marks at the mirrored radius of
+	// their value when the Axis has a MirrorTo radius.
+	Mirrored bool
+}
+
+// Ticks returns the tick marks of the axis that are rendered for a ring spanning
+// the inner and outer radii and scaling scores in [min, max], excluding the intervals
+// in breaks. If the Axis takes its range from Scores or has a Range, that range is
+// used in place of min and max. Ticks is the computation used when the axis is drawn, so a Trace
+// drawing the axis places its ticks and grid lines at the returned radii when given
+// its Inner, Outer, Min, Max and Breaks.
+func (r *Axis) Ticks(min, max float64, inner, outer vg.Length, breaks ...Break) []ResolvedTick {
+	return r.ticks(r.scale(newRadialScale(min, max, inner, outer, breaks)))
+}
+
+// ticks returns the tick marks of the axis within the radial scale s. Tick marks
+// outside s or within its breaks are not returned.
+func (r *Axis) ticks(s radialScale) []ResolvedTick {
+	var ticks []ResolvedTick
+	for _, mark := range r.Tick.Marker.Ticks(s.min, s.max) {
+		if s.excludes(mark.Value) {
+			continue
+		}
+		radius, _ := s.radius(mark.Value)
+		t := ResolvedTick{Value: mark.Value, Radius: radius, Minor: mark.IsMinor()}
+		if !t.Minor {
+			t.Label = r.Tick.text(mark)
+		}
+		ticks = append(ticks, t)
+	}
+
+	// Mirrored ticks are scaled into the extent between the
+	// minimum radius and MirrorTo. The tick at the minimum
+	// is not repeated.
+	if r.MirrorTo != 0 && r.MirrorTo != s.inner && s.outer != s.inner {
+		ratio := (s.inner - r.MirrorTo) / (s.outer - s.inner)
+		for _, t := range ticks {
+			if t.Radius == s.inner {
+				continue
+			}
+			t.Radius = s.inner - (t.Radius-s.inner)*ratio
+			t.Mirrored = true
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks
 }
 
 // drawAt renders the axis at cen in the specified drawing area, according to the
-// Axis configuration.
-func (r *Axis) drawAt(ca draw.Canvas, cen vg.Point, fs []Scorer, base ArcOfer, inner, outer vg.Length, min, max float64) {
-	locMap := make(map[feat.Feature]struct{})
+// Axis configuration and the radial scale s. Ticks and grid lines within the breaks
+// of s are not drawn.
+func (r *Axis) drawAt(ca draw.Canvas, cen vg.Point, fs []Scorer, base ArcOfer, s radialScale) {
+	if r.Auto != nil {
+		angle, err := r.Auto(base)
+		if err != nil {
+			panic(fmt.Sprintf("rings: failed to place axis: %v", err))
+		}
+		placed := *r
+		placed.Angle = angle
+		r = &placed
+	}
+	s = r.scale(s)
 
 	var (
 		pa vg.Path
 
-		marks []plot.Tick
+		ticks []ResolvedTick
 
-		scale = (outer - inner) / vg.Length(max-min)
+		inner, outer = s.inner, s.outer
 	)
+	// Locations are collected in order of first appearance so that
+	// grid lines are rendered in a deterministic order.
+	var locs []feat.Feature
+	seen := make(map[feat.Feature]bool)
 	for _, f := range fs {
-		locMap[f.Location()] = struct{}{}
+		if loc := f.Location(); !seen[loc] {
+			seen[loc] = true
+			locs = append(locs, loc)
+		}
 	}
 	if r.Grid.Color != nil && r.Grid.Width != 0 {
-		for loc := range locMap {
+		for _, loc := range locs {
 			arc, err := base.ArcOf(loc, nil)
 			if err != nil {
 				panic(fmt.Sprint("rings: no arc for feature location:", err))
 			}
 
-			ca.SetLineStyle(r.Grid)
-			marks = r.Tick.Marker.Ticks(min, max)
-			for _, mark := range marks {
-				if mark.Value < min || mark.Value > max {
-					continue
-				}
+			ca.SetLineStyle(r.GridArcDashes.style(r.Grid))
+			if ticks == nil {
+				ticks = r.ticks(s)
+			}
+			for _, t := range ticks {
 				pa = pa[:0]
-
-				radius := vg.Length(mark.Value-min)*scale + inner
-
-				pa.Move(cen.Add(Rectangular(arc.Theta, radius)))
-				pa.Arc(cen, radius, float64(arc.Theta), float64(arc.Phi))
-
+				r.GridArcDashes.arcPath(&pa, cen, t.Radius, arc)
 				ca.Stroke(pa)
 			}
 		}
 	}
 
 	if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
-		pa = pa[:0]
-
-		pa.Move(cen.Add(Rectangular(r.Angle, inner)))
-		pa.Line(cen.Add(Rectangular(r.Angle, outer)))
-
 		ca.SetLineStyle(r.LineStyle)
-		ca.Stroke(pa)
+		r.drawLine(ca, cen, s)
+		if r.MirrorTo != 0 && r.MirrorTo != s.inner {
+			pa = pa[:0]
+			pa.Move(RectangularAt(cen, r.Angle, r.MirrorTo))
+			pa.Line(RectangularAt(cen, r.Angle, s.inner))
+			ca.Stroke(pa)
+		}
 	}
 
+	// Ticks and their labels are offset perpendicular to the
+	// axis in the winding direction of the base.
+	perp := r.Angle + windingOf(base.Arc())*Complete/4
+
 	if r.Tick.LineStyle.Color != nil && r.Tick.LineStyle.Width != 0 && r.Tick.Length != 0 {
 		ca.SetLineStyle(r.Tick.LineStyle)
-		if marks == nil {
-			marks = r.Tick.Marker.Ticks(min, max)
+		if ticks == nil {
+			ticks = r.ticks(s)
 		}
-		for _, mark := range marks {
-			if mark.Value < min || mark.Value > max {
-				continue
-			}
+		for _, t := range ticks {
 			pa = pa[:0]
 
-			radius := vg.Length(mark.Value-min)*scale + inner
-
 			var length vg.Length
-			if mark.IsMinor() {
+			if t.Minor {
 				length = r.Tick.Length / 2
 			} else {
 				length = r.Tick.Length
 			}
-			off := Rectangular(r.Angle+Complete/4, length)
-			e := Rectangular(r.Angle, radius)
+			e := Rectangular(r.Angle, t.Radius)
 			pa.Move(cen.Add(e))
-			pa.Line(cen.Add(e.Add(off)))
+			pa.Line(cen.Add(RectangularAt(e, perp, r.Tick.direction()*length)))
 
 			ca.Stroke(pa)
 
-			if mark.IsMinor() || r.Tick.Label.Color == nil {
+			if t.Minor || r.Tick.Label.Color == nil {
 				continue
 			}
 
-			pt := cen.Add(Rectangular(r.Angle, radius).Add(vg.Point{off.X * 2, off.Y * 2}))
-			var (
-				rot            Angle
-				xalign, yalign float64
-			)
-			if r.Tick.Placement == nil {
-				rot, xalign, yalign = DefaultPlacement(r.Angle)
-			} else {
-				rot, xalign, yalign = r.Tick.Placement(r.Angle)
+			off := r.Tick.labelOffset(2 * r.Tick.Length)
+			r.Tick.fillLabel(ca, cen.Add(RectangularAt(e, perp, off)), sideOf(perp, off), r.Angle, t.Label)
+		}
+	}
+
+	if r.Frame.Color != nil && r.Frame.Width != 0 {
+		// Frame labels give way to labelled tick marks at the
+		// same value.
+		labelled := make(map[float64]bool)
+		if r.Tick.LineStyle.Color != nil && r.Tick.LineStyle.Width != 0 && r.Tick.Length != 0 && r.Tick.Label.Color != nil {
+			for _, t := range ticks {
+				if !t.Minor && !t.Mirrored {
+					labelled[t.Value] = true
+				}
 			}
-			if rot != 0 {
-				ca.Push()
-				ca.Translate(pt)
-				ca.Rotate(float64(rot))
-				ca.Translate(vg.Point{-pt.X, -pt.Y})
-				ca.FillText(r.Tick.Label, pt, xalign, yalign, mark.Label)
-				ca.Pop()
-			} else {
-				ca.FillText(r.Tick.Label, pt, xalign, yalign, mark.Label)
+		}
+		r.drawFrame(ca, cen, s, perp, labelled)
+	}
+
+	if r.Anchors != nil {
+		if ticks == nil {
+			ticks = r.ticks(s)
+		}
+		name := anchorName(r.AnchorName, "axis")
+		r.Anchors.clear(name + "/tick/")
+		for _, t := range ticks {
+			if !t.Minor && !t.Mirrored && t.Label != "" {
+				r.Anchors.register(name+"/tick/"+t.Label, cen, r.Angle, t.Radius)
 			}
 		}
 	}
 
-	if r.Label.Text != "" && r.Label.Color != nil {
-		pt := cen.Add(Rectangular(r.Angle, (inner+outer)/2))
+	if (r.Label.Text != "" && r.Label.Color != nil) || r.Label.Rich != nil {
+		pt := RectangularAt(cen, r.Angle, (inner+outer)/2)
 		var (
 			rot            Angle
 			xalign, yalign float64
@@ -182,15 +560,89 @@ func (r *Axis) drawAt(ca draw.Canvas, cen vg.Point, fs []Scorer, base ArcOfer, i
 		} else {
 			rot, xalign, yalign = r.Label.Placement(r.Angle)
 		}
-		if rot != 0 {
-			ca.Push()
-			ca.Translate(pt)
-			ca.Rotate(float64(rot))
-			ca.Translate(vg.Point{-pt.X, -pt.Y})
-			ca.FillText(r.Label.TextStyle, pt, xalign, yalign, r.Label.Text)
-			ca.Pop()
-		} else {
-			ca.FillText(r.Label.TextStyle, pt, xalign, yalign, r.Label.Text)
+		// Exactly anchored labels are placed on the side of the
+		// axis line opposite its ticks.
+		side := sideOf(perp, -r.Tick.direction())
+		switch {
+		case r.Label.ExactAnchor && r.Label.Rich != nil:
+			fillRunsAnchored(ca, pt, side, rot, r.Label.runs())
+		case r.Label.ExactAnchor:
+			fillTextAnchored(ca, r.Label.TextStyle, pt, side, rot, r.Label.Text)
+		case r.Label.Rich != nil:
+			fillRuns(ca, pt, rot, xalign, yalign, r.Label.runs())
+		default:
+			fillText(ca, r.Label.TextStyle, pt, rot, xalign, yalign, r.Label.Text)
+		}
+	}
+}
+
+// drawFrame renders the caps of the axis Frame at the inner and outer radii of s,
+// extending from the axis line in the direction perp, and labels them with the ends
+// of the range of s if FrameLabels is true and their values are not in labelled.
+func (r *Axis) drawFrame(ca draw.Canvas, cen vg.Point, s radialScale, perp Angle, labelled map[float64]bool) {
+	var pa vg.Path
+	for _, end := range []struct {
+		radius vg.Length
+		value  float64
+	}{
+		{radius: s.inner, value: s.min},
+		{radius: s.outer, value: s.max},
+	} {
+		e := RectangularAt(cen, r.Angle, end.radius)
+		pa = pa[:0]
+		pa.Move(e)
+		pa.Line(RectangularAt(e, perp, r.Tick.direction()*r.Tick.Length))
+		ca.SetLineStyle(r.Frame)
+		ca.Stroke(pa)
+
+		if !r.FrameLabels || r.Tick.Label.Color == nil || labelled[end.value] {
+			continue
+		}
+		off := r.Tick.labelOffset(2 * r.Tick.Length)
+		mark := plot.Tick{Value: end.value, Label: strconv.FormatFloat(end.value, 'g', -1, 64)}
+		r.Tick.fillLabel(ca, RectangularAt(e, perp, off), sideOf(perp, off), r.Angle, r.Tick.text(mark))
+	}
+}
+
+// sideOf returns the direction of a displacement of off in the direction a.
+func sideOf(a Angle, off vg.Length) Angle {
+	if off < 0 {
+		return a + AngleHalf
+	}
+	return a
+}
+
+// drawLine renders the axis line from the inner to the outer radius of s. The line is
+// interrupted at each break of s and the break is marked with a pair of slashes.
+func (r *Axis) drawLine(ca draw.Canvas, cen vg.Point, s radialScale) {
+	length := r.BreakLength
+	if length == 0 {
+		length = r.Tick.Length
+	}
+	length = vg.Length(math.Abs(float64(length)))
+	gap := length / 3
+
+	var pa vg.Path
+	from := s.inner
+	for _, b := range s.breaks {
+		rad, _ := s.radius(b.Lo)
+		pa = pa[:0]
+		pa.Move(RectangularAt(cen, r.Angle, from))
+		pa.Line(RectangularAt(cen, r.Angle, rad-gap/2))
+		ca.Stroke(pa)
+
+		slash := Rectangular(r.Angle+Complete/8, length/2)
+		for _, d := range []vg.Length{-gap / 2, gap / 2} {
+			mid := RectangularAt(cen, r.Angle, rad+d)
+			pa = pa[:0]
+			pa.Move(mid.Add(vg.Point{-slash.X, -slash.Y}))
+			pa.Line(mid.Add(slash))
+			ca.Stroke(pa)
 		}
+		from = rad + gap/2
 	}
+	pa = pa[:0]
+	pa.Move(RectangularAt(cen, r.Angle, from))
+	pa.Line(RectangularAt(cen, r.Angle, s.outer))
+	ca.Stroke(pa)
 }
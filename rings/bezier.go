@@ -7,6 +7,7 @@ package rings
 import (
 	"math"
 	"math/rand"
+	"sync"
 
 	"github.com/gonum/plot/vg"
 )
@@ -67,43 +68,219 @@ type Bezier struct {
 	//
 	// Radius specifies the Bézier radius of a curve generated by the Bezier.
 	Radius LengthDist
+	// RadiusFunc, if not nil, returns the Length of the Radius of a curve given the
+	// angular separation of its ends, in [0, π], and the radii of its ends, so
+	// that the depth of a curve may depend on the distance it spans. The returned
+	// length is perturbed by the Min and Max of the Radius. RadiusFunc is not used
+	// by ControlPointsAt for curves between different centers.
+	RadiusFunc func(sep Angle, rad [2]vg.Length) vg.Length
 	// Crest and Purity specify the crest and purity behaviour of a curve generated by the Bezier.
-	// If nil, these values are not used.
+	// If nil, these values are not used. The crest control point of each end lies on the ray
+	// from the center through the end, displaced from the end towards the Radius by the Crest
+	// factor of the difference between the Radius and the radius of the end. A Radius greater
+	// than the radius of an end displaces its crest control point outward.
 	Crest  *FactorDist
 	Purity *FactorDist
+
+	// Outward specifies that curves bow outside the radius of their ends rather
+	// than towards the center, as for the inverted links of circos. The Radius,
+	// after RadiusFunc and Purity are applied, is reflected about the mean radius
+	// of the ends, so that a curve reaches as far outside its ends as it would
+	// otherwise reach inside them. Outward is not used by ControlPointsAt for
+	// curves between different centers.
+	Outward bool
+
+	// Rand is the source of random values used to perturb the Radius, Crest and
+	// Purity of generated curves. Use of Rand is serialised, so Links sharing a
+	// Bezier may be drawn concurrently, though the values drawn by each are then
+	// not reproducible. If Rand is nil, Seed is used.
+	Rand *rand.Rand
+
+	// Seed, if not zero and Rand is nil, seeds the random values of each curve
+	// in combination with the end points of the curve, so curves are reproducible
+	// whatever the order in which they are generated, including when Links sharing
+	// the Bezier are drawn concurrently. If Seed is zero and Rand is nil, the
+	// math/rand package's default source is used.
+	Seed int64
+
+	// mu protects Rand.
+	mu sync.Mutex
+}
+
+// float64 returns a random value in [0, 1) from the Bezier's Rand, or from the
+// math/rand package's default source if Rand is nil.
+func (b *Bezier) float64() float64 {
+	if b.Rand == nil {
+		return rand.Float64()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Rand.Float64()
+}
+
+// random returns a function returning random values in [0, 1) for the curve between
+// the points p. If the Bezier's Rand is nil and its Seed is not zero, the values are
+// generated by a SplitMix64 sequence seeded by the Seed and p.
+func (b *Bezier) random(p [2]vg.Point) func() float64 {
+	if b.Rand != nil || b.Seed == 0 {
+		return b.float64
+	}
+	state := uint64(b.Seed)
+	for _, q := range p {
+		state = mix64(state ^ math.Float64bits(float64(q.X)))
+		state = mix64(state ^ math.Float64bits(float64(q.Y)))
+	}
+	return func() float64 {
+		state += 0x9e3779b97f4a7c15
+		return float64(mix64(state)>>11) / (1 << 53)
+	}
+}
+
+// mix64 is the SplitMix64 output function.
+func mix64(z uint64) uint64 {
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// ProportionalRadius returns a Bezier RadiusFunc that places the control point of a
+// curve at a fraction of the mean radius of its ends that falls linearly from maxFrac
+// for ends at the same angle to minFrac for ends separated by half a turn. Curves
+// between nearby ends then stay near the rim while those between distant ends pass
+// close to the center.
+func ProportionalRadius(minFrac, maxFrac float64) func(sep Angle, rad [2]vg.Length) vg.Length {
+	return func(sep Angle, rad [2]vg.Length) vg.Length {
+		f := math.Min(math.Abs(float64(sep))/math.Pi, 1)
+		return (rad[0] + rad[1]) / 2 * vg.Length(maxFrac+(minFrac-maxFrac)*f)
+	}
 }
 
 // ControlPoints returns a set of Bézier curve control points defining the path between the points defined
 // by the parameters and the Bezier's Radius, Crest and Purity fields.
 func (b *Bezier) ControlPoints(a [2]Angle, rad [2]vg.Length) []vg.Point {
+	// The bisector is taken across the smaller of the two
+	// angles between the ends, whatever the winding or the
+	// normalisation of the end angles.
+	d := math.Remainder(float64(a[1]-a[0]), 2*math.Pi)
+	return b.controlPoints(a, rad, a[0]+Angle(d/2))
+}
+
+// ControlPointsTo returns a set of Bézier curve control points defining the path from the
+// point at angle a and radius rad to the point ext, given relative to the center, as for a
+// link to an ExternalAnchor. The Bezier's Radius, Crest and Purity fields are interpreted
+// as for ControlPoints, except that the middle control point lies on the straight-line
+// bisector of the ends, the ray from the center through the midpoint of the straight line
+// between the ends, in place of the ray bisecting the angle between the ends. The crest
+// control point of ext lies on the ray from the center through ext.
+func (b *Bezier) ControlPointsTo(a Angle, rad vg.Length, ext vg.Point) []vg.Point {
+	theta, r := Polar(ext)
+	bisect, _ := Polar(Rectangular(a, rad).Add(ext).Scale(0.5))
+	return b.controlPoints([2]Angle{a, theta}, [2]vg.Length{rad, r}, bisect)
+}
+
+// controlPoints returns the control points of a curve between the points at the angles a
+// and radii rad with the middle control point on the ray at the angle bisect.
+func (b *Bezier) controlPoints(a [2]Angle, rad [2]vg.Length, bisect Angle) []vg.Point {
 	var p [2]vg.Point
 	for i := range a {
 		p[i] = Rectangular(a[i], rad[i])
 	}
+	random := b.random(p)
 
 	var radius = b.Radius
+	if b.RadiusFunc != nil {
+		d := math.Remainder(float64(a[1]-a[0]), 2*math.Pi)
+		radius.Length = b.RadiusFunc(Angle(math.Abs(d)), rad)
+	}
 	if b.Purity != nil {
 		bisectRadius := vg.Length(math.Hypot(float64(p[0].X+p[1].X)/2, float64(p[0].Y+p[1].Y)/2))
-		radius.Length += vg.Length(b.Purity.Perturb(rand.Float64())-1) * (radius.Length - bisectRadius)
+		radius.Length += vg.Length(b.Purity.Perturb(random())-1) * (radius.Length - bisectRadius)
 	}
-
-	var bisect Angle
-	if math.Abs(float64(a[1]-a[0])) > math.Pi {
-		bisect = (a[0]+a[1]+Angle(2*math.Pi))/2 - Angle(2*math.Pi)
-	} else {
-		bisect = (a[1] + a[0]) / 2
+	if b.Outward {
+		radius.Length = rad[0] + rad[1] - radius.Length
 	}
-	mid := Rectangular(bisect, radius.Perturb(rand.Float64()))
+
+	mid := Rectangular(bisect, radius.Perturb(random()))
 
 	if b.Crest != nil {
 		points := []vg.Point{0: p[0], 2: mid, 4: p[1]}
-		c := b.Crest.Perturb(rand.Float64())
+		c := b.Crest.Perturb(random())
 
 		for i, r := range rad {
-			points[2*i+1] = Rectangular(a[i], r-(r-radius.Length)*vg.Length(c))
+			points[2*i+1] = Rectangular(a[i], crestRadius(r, radius.Length, c))
 		}
 		return points
 	}
 
 	return []vg.Point{p[0], mid, p[1]}
 }
+
+// crestRadius returns the radius of the crest control point of an end at radius r for
+// a curve with the control radius rad and crest factor c. The crest control point is
+// displaced from r towards rad, inward or outward, and does not pass through the center
+// of the curve's ends, where it would otherwise pull the curve towards the opposite side.
+func crestRadius(r, rad vg.Length, c float64) vg.Length {
+	return vg.Length(math.Max(0, float64(r+(rad-r)*vg.Length(c))))
+}
+
+// ControlPointsAt returns a set of Bézier curve control points defining the path between the
+// points p, each rendered in the plot centered at the corresponding point in centers. The
+// returned control points are in the same coordinates as p and centers.
+//
+// The Bezier's Radius, Crest and Purity are interpreted relative to the segment between the
+// two centers: the middle control point lies on the segment, displaced from its midpoint by
+// the Radius toward the projection onto the segment of the midpoint of p, or toward the
+// second center if the projection is the midpoint of the segment, and the crest
+// control points lie on the rays from each center through its end point. If the centers
+// are the same, ControlPointsAt is equivalent to ControlPoints.
+func (b *Bezier) ControlPointsAt(p [2]vg.Point, centers [2]vg.Point) []vg.Point {
+	axis := centers[1].Sub(centers[0])
+	length := vg.Length(math.Hypot(float64(axis.X), float64(axis.Y)))
+	if length == 0 {
+		var (
+			a   [2]Angle
+			rad [2]vg.Length
+		)
+		for i := range p {
+			a[i], rad[i] = Polar(p[i].Sub(centers[0]))
+		}
+		pts := b.ControlPoints(a, rad)
+		for i := range pts {
+			pts[i] = centers[0].Add(pts[i])
+		}
+		return pts
+	}
+	u := axis.Scale(1 / length)
+	mid := centers[0].Add(axis.Scale(0.5))
+
+	// The bisect displacement is the signed distance along the segment from its
+	// midpoint to the projection of the midpoint of the end points.
+	bisect := p[0].Add(p[1]).Scale(0.5).Sub(mid)
+	disp := bisect.X*u.X + bisect.Y*u.Y
+	dir := vg.Length(1)
+	if disp < 0 {
+		dir = -1
+	}
+
+	random := b.random(p)
+	var radius = b.Radius
+	if b.Purity != nil {
+		radius.Length += vg.Length(b.Purity.Perturb(random())-1) * (radius.Length - disp*dir)
+	}
+	d := dir * radius.Perturb(random())
+	d = vg.Length(math.Max(-float64(length/2), math.Min(float64(d), float64(length/2))))
+	control := mid.Add(u.Scale(d))
+
+	if b.Crest != nil {
+		points := []vg.Point{0: p[0], 2: control, 4: p[1]}
+		c := b.Crest.Perturb(random())
+
+		for i, cen := range centers {
+			a, r := Polar(p[i].Sub(cen))
+			points[2*i+1] = RectangularAt(cen, a, crestRadius(r, radius.Length, c))
+		}
+		return points
+	}
+
+	return []vg.Point{p[0], control, p[1]}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
@@ -16,9 +17,13 @@ import (
 	"github.com/biogo/biogo/feat"
 )
 
-// Blocks implements rendering of feat.Features as radial blocks.
+// Blocks implements rendering of feat.Features as radial blocks. A Blocks may be
+// given a keep filter removing some of its locations. The keep filter can only be
+// set with the BlockKeep option when the Blocks is constructed, since the arcs of
+// the kept features are allocated then.
 type Blocks struct {
-	// Set holds a collection of features to render.
+	// Set holds a collection of features to render. Features that are SectorPathers
+	// define the outline of their block.
 	Set []feat.Feature
 
 	// Base defines the targets of the rendered blocks.
@@ -29,6 +34,17 @@ type Blocks struct {
 	// over-ridden if the feature describing the block is a FillColorer.
 	Color color.Color
 
+	// Colors, if not nil, holds the fill colors of the blocks of the features it
+	// holds as keys, over-riding Color and Gradient, for example as returned by
+	// ColorsFor. Colors is over-ridden if the feature describing a block is a
+	// FillColorer.
+	Colors map[feat.Feature]color.Color
+
+	// Gradient, if not nil, specifies a radial gradient used in place of Color
+	// to fill the blocks of features that are not FillColorers or held by Colors.
+	// Blocks that are not rendered with the Rect shape are filled with Color.
+	Gradient *GradientFill
+
 	// LineStyle determines the line style of each block. LineStyle behaviour
 	// is over-ridden if the feature describing a block is a LineStyler.
 	LineStyle draw.LineStyle
@@ -36,16 +52,125 @@ type Blocks struct {
 	// Inner and Outer define the inner and outer radii of the blocks.
 	Inner, Outer vg.Length
 
+	// Offset, if not nil, specifies the radial displacement of the block of each
+	// feature. The displacement of a block is the sum of Offset applied to the
+	// block's feature and each of its ancestor locations, and is reported to
+	// rings using the Blocks as their base by the OffsetOf method.
+	Offset func(f feat.Feature) vg.Length
+
+	// Weight, if not nil, returns the weight of a feature. When Weight is not
+	// nil, the features of the Set that are not held by another feature of the
+	// Set are allocated arcs in proportion to their weights rather than the arcs
+	// given by the Base, for example to size the blocks of a chord diagram by
+	// the total flow of each category. The weighted arcs are placed in the order
+	// of the features' arcs in the Base and share the total angle of those arcs,
+	// leaving the gaps between them unchanged. Positions within a feature are
+	// mapped linearly to its weighted arc by the ArcOf method, so all rings using
+	// the Blocks as their base, including the ends of Links and Ribbons, render
+	// positions with the same mapping. Weights must be positive. The weighted
+	// arcs are retained until the Set is reassigned or changes length or the
+	// Base is changed, so the weight of a feature must not otherwise change
+	// while the Blocks is in use.
+	Weight func(f feat.Feature) float64
+
+	// weights holds the weighted arcs of the Set.
+	weights weightCache
+
+	// Zooms specifies regions of locations that are allocated a scaled share of
+	// their location's arc. Zooms are applied by the ArcOf method, so all rings
+	// using the Blocks as their base render positions with the same mapping.
+	// Zooms within a location must not overlap.
+	Zooms []Zoom
+
+	// UseOrientation specifies that a chevron indicating the orientation of each
+	// feature is drawn within its block using the block's line style. Chevrons of
+	// forward features point in the direction of the base arc and those of reverse
+	// features point against it. The orientation of a feature is its orientation
+	// relative to the base; features that are not feat.Orienters are treated as
+	// forward.
+	UseOrientation bool
+
+	// Shape specifies the outline of the block of each feature that is a
+	// feat.Orienter. Blocks of features that are not feat.Orienters, or are not
+	// oriented, are rendered with the Rect shape. Shape is ignored for features
+	// that are SectorPathers.
+	Shape BlockShape
+
+	// HeadAngle is the maximum angular length of the head of an Arrow shaped
+	// block. If HeadAngle is zero, or the block is shorter than HeadAngle, the
+	// whole block forms the head.
+	HeadAngle Angle
+
+	// MinAngle is the minimum angular width of a rendered block. Features with
+	// narrower arcs, including zero length features, are rendered as blocks of
+	// MinAngle centred on the feature. If MinAngle is zero, MinFeatureAngle is
+	// used, and if it is negative no minimum angle is applied.
+	MinAngle Angle
+
+	// InnerLabel describes the labels rendered within the blocks. Labels are
+	// rendered after all the blocks.
+	InnerLabel InnerLabel
+
+	// Names describes the names of the features rendered along the outside
+	// of the arcs of their blocks. Names are rendered after all the blocks.
+	Names BlockNames
+
+	// Other, if not nil, is the stub location allocated in place of the locations
+	// removed by the keep filter. Other is held by the Set.
+	Other feat.Feature
+
+	// keep is the keep filter set by BlockKeep, and otherLength
+	// is the length of the Other stub it requested.
+	keep        func(loc feat.Feature) bool
+	otherLength int
+
+	// filtered holds the locations removed by keep.
+	filtered map[feat.Feature]bool
+
+	// HitTester, if not nil, records the geometry of each rendered block.
+	HitTester *HitTester
+
+	// Anchors, if not nil, is the registry the anchors of the blocks are registered
+	// with when the Blocks is drawn. The anchor names are prefixed with AnchorName,
+	// or "blocks" if AnchorName is empty.
+	Anchors    *Anchors
+	AnchorName string
+
+	// Title is the title of the ring.
+	Title Title
+
+	// Layer specifies the drawing layer of the Blocks when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
 
+// BlockShape specifies the outline of a block.
+type BlockShape int
+
+const (
+	Rect  BlockShape = iota // Rect renders a block as an annular sector.
+	Arrow                   // Arrow renders a block as an annular sector tapering to a point at its 3' end.
+)
+
 // NewBlocks returns a Blocks based on the parameters, first checking that the provided features
-// are able to be rendered. An error is returned if the features are not renderable.
-func NewBlocks(fs []feat.Feature, base ArcOfer, inner, outer vg.Length) (*Blocks, error) {
+// are able to be rendered. The provided options are then applied in order. An error is returned
+// if the features are not renderable, the base arc sweeps more than a complete circle or an
+// option fails.
+func NewBlocks(fs []feat.Feature, base ArcOfer, inner, outer vg.Length, opts ...BlockOption) (*Blocks, error) {
+	return newBlocks(fs, base, nil, inner, outer, opts)
+}
+
+// newBlocks returns a Blocks as described by NewBlocks. If alloc is not nil, it is used
+// to allocate the arcs of the features kept by a keep filter.
+func newBlocks(fs []feat.Feature, base ArcOfer, alloc func([]feat.Feature) ArcOfer, inner, outer vg.Length, opts []BlockOption) (*Blocks, error) {
 	if inner > outer {
 		return nil, errors.New("rings: inner radius greater than outer radius")
 	}
+	if math.Abs(float64(base.Arc().Phi)) > float64(Complete) {
+		return nil, errors.New("rings: base arc exceeds a complete circle")
+	}
 	for _, f := range fs {
 		if f.End() < f.Start() {
 			return nil, errors.New("rings: inverted feature")
@@ -59,68 +184,115 @@ func NewBlocks(fs []feat.Feature, base ArcOfer, inner, outer vg.Length) (*Blocks
 			return nil, err
 		}
 	}
-	return &Blocks{
+	r := &Blocks{
 		Set:   fs,
 		Inner: inner,
 		Outer: outer,
 		Base:  base,
-	}, nil
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.keep != nil {
+		if err := r.filter(alloc); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
 }
 
 // NewGappedBlocks is a convenience wrapper of NewBlocks that guarantees to provide a valid ArcOfer based
 // of the provided Arcer. If the provided Arcer is an ArcOfer it is tested for validity and a new ArcOfer is
-// created only if needed.
-func NewGappedBlocks(fs []feat.Feature, base Arcer, inner, outer vg.Length, gap float64) (*Blocks, error) {
+// created only if needed. A base Arc sweeping less than a complete circle produces a
+// fan; rings based on the returned Blocks are rendered only over the fan's arc. If a
+// new ArcOfer is created and the Blocks is given a keep filter by the BlockKeep option,
+// the arcs are allocated to the kept features only.
+func NewGappedBlocks(fs []feat.Feature, base Arcer, inner, outer vg.Length, gap float64, opts ...BlockOption) (*Blocks, error) {
 	if inner > outer {
 		return nil, errors.New("rings: inner radius greater than outer radius")
 	}
-	var b ArcOfer
+	var (
+		b     ArcOfer
+		alloc = func(fs []feat.Feature) ArcOfer { return NewGappedArcs(base, fs, gap) }
+	)
 	switch base := base.(type) {
 	case ArcOfer:
 		b = base
+		allocated := false
 		for _, f := range fs {
 			if _, err := base.ArcOf(f, nil); err != nil {
 				b = NewGappedArcs(base, fs, gap)
+				allocated = true
 				break
 			}
 		}
+		if !allocated {
+			alloc = nil
+		}
 	default:
 		b = NewGappedArcs(base, fs, gap)
 	}
-	return NewBlocks(fs, b, inner, outer)
+	return newBlocks(fs, b, alloc, inner, outer, opts)
 }
 
 // DrawAt renders the feature of a Blocks at cen in the specified drawing area,
 // according to the Blocks configuration.
 func (r *Blocks) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if r.Anchors != nil {
+		r.Anchors.clear(anchorName(r.AnchorName, "blocks") + "/")
+	}
 	if len(r.Set) == 0 {
 		return
 	}
 
-	var pa vg.Path
+	var (
+		pa   vg.Path
+		secs []Sector
+	)
 	for _, f := range r.Set {
 		pa = pa[:0]
 
-		arc, err := r.Base.ArcOf(f.Location(), f)
+		arc, err := r.ArcOf(f.Location(), f)
 		if err != nil {
 			panic(fmt.Sprintf("rings: no arc for feature location: %v", err))
 		}
+		arc = widen(arc, minFeatureAngle(r.MinAngle))
 
-		pa.Move(cen.Add(Rectangular(arc.Theta, r.Inner)))
-		pa.Arc(cen, r.Inner, float64(arc.Theta), float64(arc.Phi))
-		if arc.Phi == Clockwise*Complete || arc.Phi == CounterClockwise*Complete {
-			if c, ok := f.(feat.Conformationer); ok && c.Conformation() == feat.Circular {
-				pa.Move(cen.Add(Rectangular(arc.Theta+arc.Phi, r.Outer)))
-			}
+		off := r.OffsetOf(f.Location(), f)
+		sec := Sector{Center: cen, Inner: r.Inner + off, Outer: r.Outer + off, Arc: arc}
+		rect := false
+		if sp, ok := f.(SectorPather); ok {
+			sp.SectorPath(&pa, sec)
+		} else if r.Shape == Arrow && isOriented(f) {
+			arrowPath(&pa, sec, r.HeadAngle, r.direction(f))
+		} else {
+			rect = true
+			c, ok := f.(feat.Conformationer)
+			sec.Path(&pa, ok && c.Conformation() == feat.Circular)
+		}
+		if r.HitTester != nil {
+			r.HitTester.AddSector(r, f, sec)
+		}
+		if r.InnerLabel.Text != nil || r.Names.Color != nil {
+			secs = append(secs, sec)
+		}
+		if r.Anchors != nil {
+			name := anchorName(r.AnchorName, "blocks") + "/" + f.Name()
+			rad := (sec.Inner + sec.Outer) / 2
+			r.Anchors.register(name+"/start", cen, arc.Theta, rad)
+			r.Anchors.register(name+"/mid", cen, arc.Theta+arc.Phi/2, rad)
+			r.Anchors.register(name+"/end", cen, arc.Theta+arc.Phi, rad)
 		}
-		pa.Arc(cen, r.Outer, float64(arc.Theta+arc.Phi), float64(-arc.Phi))
-		pa.Close()
 
-		if c, ok := f.(FillColorer); ok {
-			ca.SetColor(c.FillColor())
-			ca.Fill(pa)
-		} else if r.Color != nil {
-			ca.SetColor(r.Color)
+		col, own := r.fillOf(f)
+		if !own && r.Gradient != nil && rect {
+			r.Gradient.fill(ca, sec, false)
+			col = nil
+		}
+		if col != nil {
+			ca.SetColor(col)
 			ca.Fill(pa)
 		}
 
@@ -133,19 +305,227 @@ func (r *Blocks) DrawAt(ca draw.Canvas, cen vg.Point) {
 		if sty.Color != nil && sty.Width != 0 {
 			ca.SetLineStyle(sty)
 			ca.Stroke(pa)
+
+			if r.UseOrientation {
+				pa = pa[:0]
+				chevronPath(&pa, sec, r.direction(f))
+				if len(pa) != 0 {
+					ca.Stroke(pa)
+				}
+			}
+		}
+	}
+	for i, sec := range secs {
+		r.InnerLabel.drawAt(ca, r.Set[i], sec)
+		r.Names.drawAt(ca, sec, r.Set[i].Name())
+	}
+	if r.Anchors != nil {
+		name := anchorName(r.AnchorName, "blocks")
+		theta := r.Base.Arc().Theta
+		r.Anchors.register(name+"/inner", cen, theta, r.Inner)
+		r.Anchors.register(name+"/outer", cen, theta, r.Outer)
+	}
+	r.Title.drawAt(ca, cen, r.Inner, r.Outer)
+}
+
+// direction returns the direction of transcription of f relative to the base arc.
+func (r *Blocks) direction(f feat.Feature) Angle {
+	dir := CounterClockwise
+	if r.Base.Arc().Phi < 0 {
+		dir = Clockwise
+	}
+	if orientationOf(f) == feat.Reverse {
+		dir = -dir
+	}
+	return dir
+}
+
+// isOriented returns whether f is a feat.Orienter with a defined orientation.
+func isOriented(f feat.Feature) bool {
+	o, ok := f.(feat.Orienter)
+	return ok && o.Orientation() != feat.NotOriented
+}
+
+// arrowPath appends to pa the outline of sec tapered to a point at its middle radius at
+// the end of the sector's arc in the direction dir. The head of the arrow spans at most
+// head of the sector's arc, or the whole arc if head is zero. The outline is wound in the
+// same sense as the outline returned by sec.Path.
+func arrowPath(pa *vg.Path, sec Sector, head, dir Angle) {
+	span := Angle(math.Abs(float64(sec.Phi)))
+	if head <= 0 || head > span {
+		head = span
+	}
+	if sec.Phi < 0 {
+		head = -head
+	}
+	body := sec.Phi - head
+	mid := (sec.Inner + sec.Outer) / 2
+
+	if dir*sec.Phi >= 0 {
+		// The tip is at the end of the arc.
+		pa.Move(RectangularAt(sec.Center, sec.Theta, sec.Inner))
+		if body != 0 {
+			pa.Arc(sec.Center, sec.Inner, float64(sec.Theta), float64(body))
+		}
+		pa.Line(RectangularAt(sec.Center, sec.Theta+sec.Phi, mid))
+		pa.Line(RectangularAt(sec.Center, sec.Theta+body, sec.Outer))
+		if body != 0 {
+			pa.Arc(sec.Center, sec.Outer, float64(sec.Theta+body), float64(-body))
 		}
+	} else {
+		// The tip is at the start of the arc.
+		pa.Move(RectangularAt(sec.Center, sec.Theta, mid))
+		pa.Line(RectangularAt(sec.Center, sec.Theta+head, sec.Inner))
+		if body != 0 {
+			pa.Arc(sec.Center, sec.Inner, float64(sec.Theta+head), float64(body))
+		}
+		pa.Line(RectangularAt(sec.Center, sec.Theta+sec.Phi, sec.Outer))
+		if body != 0 {
+			pa.Arc(sec.Center, sec.Outer, float64(sec.Theta+sec.Phi), float64(-body))
+		}
+		pa.Line(RectangularAt(sec.Center, sec.Theta+head, sec.Outer))
+	}
+	pa.Close()
+}
+
+// chevronPath appends to pa a chevron centred within sec and pointing along the sector's
+// arc in the direction dir. The chevron spans the middle half of the sector's radial
+// extent and at most the middle 80% of its angular extent, so arbitrarily thin sectors
+// hold a correspondingly narrow chevron.
+func chevronPath(pa *vg.Path, sec Sector, dir Angle) {
+	if sec.Phi == 0 || sec.Outer <= sec.Inner {
+		return
 	}
+	mid := sec.Theta + sec.Phi/2
+	rad := (sec.Inner + sec.Outer) / 2
+	h := (sec.Outer - sec.Inner) / 4
+
+	// The chevron is as wide as it is high unless that would not fit in the sector.
+	half := Angle(h / rad)
+	if max := Angle(math.Abs(float64(sec.Phi))) * 0.4; half > max {
+		half = max
+	}
+	back, tip := mid-dir*half, mid+dir*half
+	pa.Move(RectangularAt(sec.Center, back, rad+h))
+	pa.Line(RectangularAt(sec.Center, tip, rad))
+	pa.Line(RectangularAt(sec.Center, back, rad-h))
 }
 
 // XY returns the x and y coordinates of the Blocks.
 func (r *Blocks) XY() (x, y float64) { return r.X, r.Y }
 
+// RingTitle returns the title of the Blocks and the radii it is placed relative to.
+func (r *Blocks) RingTitle() (t *Title, inner, outer vg.Length) { return &r.Title, r.Inner, r.Outer }
+
 // Arc returns the base arc of the Blocks.
 func (r *Blocks) Arc() Arc { return r.Base.Arc() }
 
 // ArcOf returns the Arc location of the parameter. If the location is not found in
-// the Blocks, an error is returned.
-func (r *Blocks) ArcOf(loc, f feat.Feature) (Arc, error) { return r.Base.ArcOf(loc, f) }
+// the Blocks, an error is returned, which is a *FilteredError if the location was
+// removed by the Blocks' keep filter. If the Blocks has a Weight, the arc given by the
+// Base is mapped to the weighted arc of the feature of the Set holding it. If loc is
+// held by any of the Blocks' Zooms, the arc of f is then mapped through the zoomed
+// regions of loc.
+func (r *Blocks) ArcOf(loc, f feat.Feature) (Arc, error) {
+	if l := r.filteredLocation(f); l != nil {
+		return arcNaN, &FilteredError{Location: l}
+	}
+	if l := r.filteredLocation(loc); l != nil {
+		return arcNaN, &FilteredError{Location: l}
+	}
+	arc, err := r.baseArcOf(loc, f)
+	if err != nil || len(r.Zooms) == 0 || loc == nil || f == nil {
+		return arc, err
+	}
+	zs, err := zoomsOf(r.Zooms, loc)
+	if err != nil || len(zs) == 0 {
+		return arc, err
+	}
+	la, err := r.baseArcOf(nil, loc)
+	if err != nil {
+		return arc, err
+	}
+	end, err := boundaryOf(r.Base).end(loc, f)
+	if err != nil {
+		return arcNaN, err
+	}
+	return zoomed(la, loc, f.Start(), end, zs), nil
+}
+
+// ColorOf returns the fill color of the block rendering f or the nearest of its
+// ancestor locations held by the Blocks. A feature is rendered by the block of the
+// feature of the Set it is, or failing that by the block of the only feature of the
+// Set with its non-empty name. If no block renders f or its locations, or the block
+// is not filled, ColorOf returns nil. ColorOf indexes the Set on each call, so
+// ColorByLocation should be used to color many features.
+func (r *Blocks) ColorOf(f feat.Feature) color.Color {
+	return r.colorIndex()(f)
+}
+
+// colorIndex returns a function returning the fill colors of features as described
+// by ColorOf, using an index of the features of the Set built when colorIndex is
+// called.
+func (r *Blocks) colorIndex() func(feat.Feature) color.Color {
+	held := make(map[feat.Feature]bool, len(r.Set))
+	byName := make(map[string]feat.Feature, len(r.Set))
+	for _, b := range r.Set {
+		held[b] = true
+		name := b.Name()
+		if name == "" {
+			continue
+		}
+		if _, dup := byName[name]; dup {
+			// Shared names do not identify a block.
+			byName[name] = nil
+			continue
+		}
+		byName[name] = b
+	}
+	return func(f feat.Feature) color.Color {
+		for ; f != nil; f = f.Location() {
+			b := f
+			if !held[b] {
+				b = byName[f.Name()]
+			}
+			if b != nil {
+				col, _ := r.fillOf(b)
+				return col
+			}
+		}
+		return nil
+	}
+}
+
+// fillOf returns the fill color of the block of f and whether the color is specific
+// to f, given by f being a FillColorer or by the Colors of the Blocks.
+func (r *Blocks) fillOf(f feat.Feature) (col color.Color, own bool) {
+	if c, ok := f.(FillColorer); ok {
+		return c.FillColor(), true
+	}
+	if c, ok := r.Colors[f]; ok {
+		return c, true
+	}
+	return r.Color, false
+}
+
+// OffsetOf returns the radial displacement of the rendering of f within loc. The
+// offset is the sum of the Blocks' Offset applied to f, or loc if f is nil, and each
+// of its ancestor locations, and the offset given by the Blocks' Base if it is an
+// Offsetter.
+func (r *Blocks) OffsetOf(loc, f feat.Feature) vg.Length {
+	off := offsetOf(r.Base, loc, f)
+	if r.Offset == nil {
+		return off
+	}
+	q := f
+	if q == nil {
+		q = loc
+	}
+	for ; q != nil; q = q.Location() {
+		off += r.Offset(q)
+	}
+	return off
+}
 
 type featureOrienter interface {
 	feat.Feature
@@ -160,20 +540,44 @@ func globalOrientation(f featureOrienter) feat.Orientation {
 	return f.Orientation()
 }
 
+// orientationOf returns the orientation of f relative to its outermost location, taking
+// the orientations of f and each of its ancestor locations into account. Features that
+// are not feat.Orienters or are not oriented are treated as forward.
+func orientationOf(f feat.Feature) feat.Orientation {
+	o := feat.Forward
+	for ; f != nil; f = f.Location() {
+		if fo, ok := f.(feat.Orienter); ok && fo.Orientation() == feat.Reverse {
+			o = -o
+		}
+	}
+	return o
+}
+
+// DrawLayer returns the drawing layer of the Blocks.
+func (r *Blocks) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Blocks' X and Y values as the drawing coordinates.
 func (r *Blocks) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
-// GlyphBoxes returns a liberal glyphbox for the blocks rendering.
+// GlyphBoxes returns a liberal glyphbox for the blocks rendering restricted to
+// the base arc. If the Title has a Stack, the title is registered with it.
 func (r *Blocks) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if r.Title.Stack != nil {
+		r.Title.Stack.Include(r)
+	}
+	rad := r.Outer
+	for _, f := range r.Set {
+		if off := r.OffsetOf(f.Location(), f); r.Outer+off > rad {
+			rad = r.Outer + off
+		}
+	}
+	rad += r.Names.height()
 	return []plot.GlyphBox{{
-		X: plt.X.Norm(r.X),
-		Y: plt.Y.Norm(r.Y),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{-r.Outer, -r.Outer},
-			Max: vg.Point{r.Outer, r.Outer},
-		},
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(r.Inner, rad),
 	}}
 }
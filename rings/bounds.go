@@ -0,0 +1,104 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Extenter is a type that can report the extent of its rendering.
+type Extenter interface {
+	// Extent returns the bounding box of the rendering relative
+	// to the center of the ring.
+	Extent() vg.Rectangle
+}
+
+// drawAter is a ring that can be drawn about a center point.
+type drawAter interface {
+	DrawAt(ca draw.Canvas, cen vg.Point)
+}
+
+// Bounds returns the bounding box, relative to their common center, of the renderings
+// of the provided tracks. Tracks that are Extenters report their own extents. The
+// extents of other tracks are taken from their glyph boxes, which include the measured
+// extents of rendered text such as labels, names and titles. Plotters of a Layered are
+// included individually. Tracks that are neither Extenters nor plot.GlyphBoxers are
+// ignored. If no track has an extent, the zero rectangle is returned.
+func Bounds(tracks ...plot.Plotter) vg.Rectangle {
+	plt := &plot.Plot{}
+	plt.X.Scale, plt.Y.Scale = plot.LinearScale{}, plot.LinearScale{}
+
+	var (
+		box   vg.Rectangle
+		found bool
+	)
+	include := func(b vg.Rectangle) {
+		if !found {
+			box, found = b, true
+			return
+		}
+		box = union(box, b)
+	}
+	var extents func([]plot.Plotter)
+	extents = func(ps []plot.Plotter) {
+		for _, p := range ps {
+			switch p := p.(type) {
+			case Extenter:
+				include(p.Extent())
+			case *Layered:
+				extents(p.Plotters)
+			case plot.GlyphBoxer:
+				for _, gb := range p.GlyphBoxes(plt) {
+					include(gb.Rectangle)
+				}
+			}
+		}
+	}
+	extents(tracks)
+	return box
+}
+
+// Fit returns the width and height of a canvas that holds the renderings of the
+// provided tracks surrounded by margin, and the point on that canvas at which the
+// tracks should be centered, as calculated by Bounds. The returned values are
+// intended for use with DrawFitted. For example, a tightly cropped PNG may be
+// rendered with
+//
+//	w, h, cen := rings.Fit(10, tracks...)
+//	c := vgimg.New(w, h)
+//	rings.DrawFitted(draw.New(c), cen, tracks...)
+//	vgimg.PngCanvas{Canvas: c}.WriteTo(f)
+func Fit(margin vg.Length, tracks ...plot.Plotter) (w, h vg.Length, cen vg.Point) {
+	b := Bounds(tracks...)
+	w = vg.Length(math.Ceil(float64(b.Max.X - b.Min.X + 2*margin)))
+	h = vg.Length(math.Ceil(float64(b.Max.Y - b.Min.Y + 2*margin)))
+	cen = vg.Point{
+		X: (w-(b.Max.X-b.Min.X))/2 - b.Min.X,
+		Y: (h-(b.Max.Y-b.Min.Y))/2 - b.Min.Y,
+	}
+	return w, h, cen
+}
+
+// DrawFitted draws the provided tracks on ca centered at cen, relative to the minimum
+// point of ca, in the rendering order of a Layered holding the tracks. Tracks that
+// cannot be drawn about a center point are ignored.
+func DrawFitted(ca draw.Canvas, cen vg.Point, tracks ...plot.Plotter) {
+	drawFitted(ca, ca.Min.Add(cen), tracks)
+}
+
+func drawFitted(ca draw.Canvas, cen vg.Point, tracks []plot.Plotter) {
+	for _, p := range NewLayered(tracks...).Sorted() {
+		switch p := p.(type) {
+		case *Layered:
+			drawFitted(ca, cen, p.Plotters)
+		case drawAter:
+			p.DrawAt(ca, cen)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"image/color"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// RadialInterval is an interval of radii.
+type RadialInterval struct {
+	Inner, Outer vg.Length
+}
+
+// RingRadii returns the radial intervals of the provided rings that are Titlers, such
+// as Blocks, Scores and Tiles, so that a Brush may span all the tracks of a plot.
+// Other values are ignored.
+func RingRadii(rs ...interface{}) []RadialInterval {
+	var radii []RadialInterval
+	for _, r := range rs {
+		if t, ok := r.(Titler); ok {
+			_, inner, outer := t.RingTitle()
+			radii = append(radii, RadialInterval{Inner: inner, Outer: outer})
+		}
+	}
+	return radii
+}
+
+// byInner sorts radial intervals by inner radius.
+type byInner []RadialInterval
+
+func (r byInner) Len() int           { return len(r) }
+func (r byInner) Less(i, j int) bool { return r[i].Inner < r[j].Inner }
+func (r byInner) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// Brush implements rendering a selection of an interval of a location across several
+// radial intervals. The arc of the brushed interval is resolved through Base, so a
+// brush crossing a zoomed region of a Blocks base follows the zoomed mapping.
+type Brush struct {
+	// Location, Start and End specify the brushed interval of Location.
+	Location   feat.Feature
+	Start, End int
+
+	// Base maps the brushed interval to its arc.
+	Base ArcOfer
+
+	// Radii holds the radial intervals painted by the brush. Overlapping and
+	// abutting intervals are painted once as their union.
+	Radii []RadialInterval
+
+	// Color determines the fill color of the painted intervals, and is usually
+	// translucent so that the brushed tracks remain visible.
+	Color color.Color
+
+	// LineStyle determines the line style of the single border drawn around
+	// the extent of the painted intervals, from the smallest inner radius to
+	// the largest outer radius.
+	LineStyle draw.LineStyle
+
+	// Layer specifies the drawing layer of the Brush when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewBrush returns a Brush painting the interval [start, end) of loc, as mapped by base,
+// across the provided radial intervals with col. An error is returned if the interval is
+// inverted or empty or its arc cannot be resolved.
+func NewBrush(base ArcOfer, loc feat.Feature, start, end int, col color.Color, radii ...RadialInterval) (*Brush, error) {
+	r := &Brush{
+		Location: loc,
+		Start:    start,
+		End:      end,
+		Base:     base,
+		Radii:    radii,
+		Color:    col,
+	}
+	if _, err := r.arc(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// brushFeature is the interval of a location selected by a Brush.
+type brushFeature struct {
+	loc        feat.Feature
+	start, end int
+}
+
+func (f brushFeature) Start() int             { return f.start }
+func (f brushFeature) End() int               { return f.end }
+func (f brushFeature) Len() int               { return f.end - f.start }
+func (f brushFeature) Name() string           { return "brush" }
+func (f brushFeature) Description() string    { return "brush" }
+func (f brushFeature) Location() feat.Feature { return f.loc }
+
+// Arc returns the arc of the brushed interval. If the arc cannot be resolved, the
+// angles of the returned arc are NaN.
+func (r *Brush) Arc() Arc {
+	arc, err := r.arc()
+	if err != nil {
+		return arcNaN
+	}
+	return arc
+}
+
+// arc returns the arc of the brushed interval. An error is returned if the interval
+// is inverted or empty or its arc cannot be resolved.
+func (r *Brush) arc() (Arc, error) {
+	switch {
+	case r.Base == nil:
+		return arcNaN, errors.New("rings: nil brush base")
+	case r.Location == nil:
+		return arcNaN, errors.New("rings: nil brush location")
+	case r.End < r.Start:
+		return arcNaN, errors.New("rings: inverted brush")
+	case r.End == r.Start:
+		return arcNaN, errors.New("rings: zero length brush")
+	}
+	return r.Base.ArcOf(r.Location, brushFeature{loc: r.Location, start: r.Start, end: r.End})
+}
+
+// union returns the union of the radial intervals of the Brush sorted by inner radius.
+func (r *Brush) union() []RadialInterval {
+	if len(r.Radii) == 0 {
+		return nil
+	}
+	radii := append([]RadialInterval(nil), r.Radii...)
+	sort.Sort(byInner(radii))
+	union := radii[:1]
+	for _, ri := range radii[1:] {
+		last := &union[len(union)-1]
+		if ri.Inner <= last.Outer {
+			if ri.Outer > last.Outer {
+				last.Outer = ri.Outer
+			}
+			continue
+		}
+		union = append(union, ri)
+	}
+	return union
+}
+
+// DrawAt renders the Brush at cen in the specified drawing area, according to the
+// Brush configuration.
+func (r *Brush) DrawAt(ca draw.Canvas, cen vg.Point) {
+	union := r.union()
+	if len(union) == 0 {
+		return
+	}
+	arc, err := r.arc()
+	if err != nil {
+		panic(err)
+	}
+
+	var pa vg.Path
+	if r.Color != nil {
+		ca.SetColor(r.Color)
+		for _, ri := range union {
+			pa = pa[:0]
+			Sector{Center: cen, Inner: ri.Inner, Outer: ri.Outer, Arc: arc}.Path(&pa, true)
+			ca.Fill(pa)
+		}
+	}
+	if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
+		pa = pa[:0]
+		Sector{Center: cen, Inner: union[0].Inner, Outer: union[len(union)-1].Outer, Arc: arc}.Path(&pa, true)
+		ca.SetLineStyle(r.LineStyle)
+		ca.Stroke(pa)
+	}
+}
+
+// XY returns the x and y coordinates of the Brush.
+func (r *Brush) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Brush.
+func (r *Brush) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Brush's X and Y values as the drawing coordinates.
+func (r *Brush) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the brush rendering restricted to the
+// arc of the brush.
+func (r *Brush) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	union := r.union()
+	arc, err := r.arc()
+	if len(union) == 0 || err != nil {
+		return nil
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: arc.bounds(union[0].Inner, union[len(union)-1].Outer),
+	}}
+}
@@ -0,0 +1,367 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Callout is a boxed text annotation of a feature.
+type Callout struct {
+	// Feature is the annotated feature. The leader line of the callout
+	// is drawn to the midpoint of the feature.
+	Feature feat.Feature
+
+	// Text is the text of the callout. Text may hold multiple lines.
+	Text string
+}
+
+// BoxStyle describes the rendering of a rounded rectangle box.
+type BoxStyle struct {
+	// Color is the fill color of the box. If Color is nil the box is not filled.
+	Color color.Color
+
+	// LineStyle is the style of the box border.
+	LineStyle draw.LineStyle
+
+	// Radius is the radius of the box corners. Radius is limited to half
+	// the smaller dimension of the box.
+	Radius vg.Length
+
+	// Padding is the space between the box border and its text.
+	Padding vg.Length
+}
+
+// Callouts implements rendering of boxed text annotations placed outside a ring, each
+// joined to the midpoint of its feature by a leader line. Callouts are placed in order
+// of the angles of their features and a callout that would overlap a callout already
+// placed is spread angularly and pushed radially outward until it is clear.
+type Callouts struct {
+	// Set holds the callouts to render.
+	Set []Callout
+
+	// Base defines the targets of the callouts' leader lines.
+	Base ArcOfer
+
+	// Anchor is the radius of the leader line ends at the features.
+	Anchor vg.Length
+
+	// Radius is the distance from the center to the nearest point of the
+	// unmoved callout boxes. The centers of unmoved boxes lie on the radial
+	// line through the midpoint of their feature.
+	Radius vg.Length
+
+	// Box determines the style of the callout boxes.
+	Box BoxStyle
+
+	// TextStyle determines the text style of the callouts.
+	TextStyle draw.TextStyle
+
+	// LeaderStyle determines the line style of the leader lines.
+	LeaderStyle draw.LineStyle
+
+	// Spread is the largest angular displacement of a callout from its feature
+	// used to avoid overlaps. If Spread is zero, callouts are only pushed outward.
+	Spread Angle
+
+	// Push is the largest radial displacement of a callout used to avoid overlaps.
+	Push vg.Length
+
+	// Layer specifies the drawing layer of the Callouts when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewCallouts returns a Callouts rendering the provided callouts with leader lines
+// from anchor to boxes placed at the given radius. The callouts may be checked with
+// the Callouts' Validate method. If base is an XYer, the returned base XY values are
+// used to populate the Callouts' X and Y fields.
+func NewCallouts(cs []Callout, base ArcOfer, anchor, radius vg.Length) *Callouts {
+	var x, y float64
+	if xy, ok := base.(XYer); ok {
+		x, y = xy.XY()
+	}
+	return &Callouts{
+		Set:    cs,
+		Base:   base,
+		Anchor: anchor,
+		Radius: radius,
+		X:      x,
+		Y:      y,
+	}
+}
+
+// placementSteps is the number of angular and radial steps tried when
+// placing an overlapping callout.
+const placementSteps = 8
+
+// Boxes returns the boxes of the callouts in the Set relative to the center of the
+// rendering, after placement to avoid overlaps. Callouts that cannot be placed clear
+// of other callouts are placed at their unmoved position. An error is returned if
+// a callout's feature has no arc in the Base.
+func (r *Callouts) Boxes() ([]vg.Rectangle, error) {
+	angles := make([]Angle, len(r.Set))
+	for i, c := range r.Set {
+		a, err := r.angleOf(c)
+		if err != nil {
+			return nil, err
+		}
+		angles[i] = a
+	}
+	texts := make([]string, len(r.Set))
+	for i, c := range r.Set {
+		texts[i] = c.Text
+	}
+	return r.place(angles, texts), nil
+}
+
+// place returns the boxes of callouts holding texts for targets at the given angles,
+// placed in order of angle to avoid overlaps.
+func (r *Callouts) place(angles []Angle, texts []string) []vg.Rectangle {
+	order := make([]int, len(angles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Stable(byAngle{order: order, angles: angles})
+
+	boxes := make([]vg.Rectangle, len(angles))
+	placed := make([]vg.Rectangle, 0, len(angles))
+	for _, i := range order {
+		w, h := r.size(texts[i])
+		boxes[i] = boxAt(angles[i], r.Radius, w, h)
+	search:
+		for step := 0; step <= placementSteps; step++ {
+			rad := r.Radius + r.Push*vg.Length(step)/placementSteps
+			for _, off := range spreads(r.Spread) {
+				b := boxAt(angles[i]+off, rad, w, h)
+				if !overlapsAny(b, placed) {
+					boxes[i] = b
+					break search
+				}
+			}
+		}
+		placed = append(placed, boxes[i])
+	}
+	return boxes
+}
+
+type byAngle struct {
+	order  []int
+	angles []Angle
+}
+
+func (a byAngle) Len() int           { return len(a.order) }
+func (a byAngle) Less(i, j int) bool { return norm(a.angles[a.order[i]]) < norm(a.angles[a.order[j]]) }
+func (a byAngle) Swap(i, j int)      { a.order[i], a.order[j] = a.order[j], a.order[i] }
+
+// norm returns a in the range [0, 2π).
+func norm(a Angle) Angle {
+	a = Angle(math.Mod(float64(a), float64(Complete)))
+	if a < 0 {
+		a += Complete
+	}
+	return a
+}
+
+// spreads returns the angular displacements tried when placing a callout, in
+// order of increasing displacement.
+func spreads(spread Angle) []Angle {
+	if spread == 0 {
+		return []Angle{0}
+	}
+	offs := []Angle{0}
+	for i := 1; i <= placementSteps; i++ {
+		d := spread * Angle(i) / placementSteps
+		offs = append(offs, d, -d)
+	}
+	return offs
+}
+
+// boxAt returns a box of width w and height h with its center on the ray at the given
+// angle, placed as close to the center as possible while lying outside the circle of
+// radius rad.
+func boxAt(angle Angle, rad, w, h vg.Length) vg.Rectangle {
+	sin, cos := math.Sincos(float64(angle))
+	ux, uy := math.Abs(cos), math.Abs(sin)
+	a, b, r := float64(w)/2, float64(h)/2, float64(rad)
+
+	// Find the distance t of the box center from the center such that the
+	// nearest point of the box is at rad. The nearest point is either on the
+	// near vertical edge, the near horizontal edge or the near corner.
+	var t float64
+	switch {
+	case ux > 0 && (r+a)/ux*uy <= b:
+		t = (r + a) / ux
+	case uy > 0 && (r+b)/uy*ux <= a:
+		t = (r + b) / uy
+	default:
+		p := a*ux + b*uy
+		t = p + math.Sqrt(p*p-(a*a+b*b-r*r))
+	}
+
+	c := vg.Point{X: vg.Length(t * cos), Y: vg.Length(t * sin)}
+	return vg.Rectangle{
+		Min: vg.Point{X: c.X - w/2, Y: c.Y - h/2},
+		Max: vg.Point{X: c.X + w/2, Y: c.Y + h/2},
+	}
+}
+
+func overlapsAny(b vg.Rectangle, boxes []vg.Rectangle) bool {
+	for _, o := range boxes {
+		if b.Min.X < o.Max.X && o.Min.X < b.Max.X && b.Min.Y < o.Max.Y && o.Min.Y < b.Max.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// size returns the size of the box holding txt.
+func (r *Callouts) size(txt string) (w, h vg.Length) {
+	w, h = TextBounds(r.TextStyle, txt)
+	return w + 2*r.Box.Padding, h + 2*r.Box.Padding
+}
+
+// angleOf returns the angle of the midpoint of the feature of c.
+func (r *Callouts) angleOf(c Callout) (Angle, error) {
+	arc, err := r.Base.ArcOf(c.Feature.Location(), c.Feature)
+	if err != nil {
+		return 0, err
+	}
+	return arc.Theta + arc.Phi/2, nil
+}
+
+// DrawAt renders the callouts of a Callouts at cen in the specified drawing area,
+// according to the Callouts configuration.
+func (r *Callouts) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if len(r.Set) == 0 {
+		return
+	}
+
+	boxes, err := r.Boxes()
+	if err != nil {
+		panic(fmt.Sprint("rings: no arc for feature location: ", err))
+	}
+	var pa vg.Path
+	for i, c := range r.Set {
+		b := vg.Rectangle{Min: cen.Add(boxes[i].Min), Max: cen.Add(boxes[i].Max)}
+		rad := cornerRadius(b, r.Box.Radius)
+
+		if r.LeaderStyle.Color != nil && r.LeaderStyle.Width != 0 {
+			angle, _ := r.angleOf(c)
+			from := RectangularAt(cen, angle, r.Anchor+offsetOf(r.Base, c.Feature.Location(), c.Feature))
+			pa = pa[:0]
+			pa.Move(from)
+			pa.Line(nearestOnBox(b, rad, from))
+			ca.SetLineStyle(r.LeaderStyle)
+			ca.Stroke(pa)
+		}
+
+		r.drawBox(ca, &pa, b, rad, c.Text)
+	}
+}
+
+// drawBox renders the box b with corners of radius rad holding txt, using pa as
+// scratch space.
+func (r *Callouts) drawBox(ca draw.Canvas, pa *vg.Path, b vg.Rectangle, rad vg.Length, txt string) {
+	*pa = (*pa)[:0]
+	roundedRect(pa, b, rad)
+	if r.Box.Color != nil {
+		ca.SetColor(r.Box.Color)
+		ca.Fill(*pa)
+	}
+	if r.Box.LineStyle.Color != nil && r.Box.LineStyle.Width != 0 {
+		ca.SetLineStyle(r.Box.LineStyle)
+		ca.Stroke(*pa)
+	}
+
+	if r.TextStyle.Color != nil && r.TextStyle.Font.Size != 0 {
+		mid := vg.Point{X: (b.Min.X + b.Max.X) / 2, Y: (b.Min.Y + b.Max.Y) / 2}
+		fillText(ca, r.TextStyle, mid, 0, -0.5, -0.5, txt)
+	}
+}
+
+// cornerRadius returns rad limited to half the smaller dimension of b.
+func cornerRadius(b vg.Rectangle, rad vg.Length) vg.Length {
+	w, h := b.Max.X-b.Min.X, b.Max.Y-b.Min.Y
+	return vg.Length(math.Max(0, math.Min(float64(rad), math.Min(float64(w), float64(h))/2)))
+}
+
+// roundedRect appends the outline of b with corners of radius rad to pa.
+func roundedRect(pa *vg.Path, b vg.Rectangle, rad vg.Length) {
+	if rad == 0 {
+		pa.Move(b.Min)
+		pa.Line(vg.Point{X: b.Max.X, Y: b.Min.Y})
+		pa.Line(b.Max)
+		pa.Line(vg.Point{X: b.Min.X, Y: b.Max.Y})
+		pa.Close()
+		return
+	}
+	pa.Move(vg.Point{X: b.Min.X + rad, Y: b.Min.Y})
+	pa.Line(vg.Point{X: b.Max.X - rad, Y: b.Min.Y})
+	pa.Arc(vg.Point{X: b.Max.X - rad, Y: b.Min.Y + rad}, rad, -math.Pi/2, math.Pi/2)
+	pa.Line(vg.Point{X: b.Max.X, Y: b.Max.Y - rad})
+	pa.Arc(vg.Point{X: b.Max.X - rad, Y: b.Max.Y - rad}, rad, 0, math.Pi/2)
+	pa.Line(vg.Point{X: b.Min.X + rad, Y: b.Max.Y})
+	pa.Arc(vg.Point{X: b.Min.X + rad, Y: b.Max.Y - rad}, rad, math.Pi/2, math.Pi/2)
+	pa.Line(vg.Point{X: b.Min.X, Y: b.Min.Y + rad})
+	pa.Arc(vg.Point{X: b.Min.X + rad, Y: b.Min.Y + rad}, rad, math.Pi, math.Pi/2)
+	pa.Close()
+}
+
+// nearestOnBox returns the point on the border of b with corners of radius rad
+// nearest to p. If p is within b, the center of b is returned.
+func nearestOnBox(b vg.Rectangle, rad vg.Length, p vg.Point) vg.Point {
+	// The border is the set of points at distance rad from
+	// the box inset by rad.
+	q := vg.Point{
+		X: vg.Length(math.Max(float64(b.Min.X+rad), math.Min(float64(p.X), float64(b.Max.X-rad)))),
+		Y: vg.Length(math.Max(float64(b.Min.Y+rad), math.Min(float64(p.Y), float64(b.Max.Y-rad)))),
+	}
+	d := distance(p, q)
+	if d <= rad {
+		return vg.Point{X: (b.Min.X + b.Max.X) / 2, Y: (b.Min.Y + b.Max.Y) / 2}
+	}
+	f := rad / d
+	return vg.Point{X: q.X + (p.X-q.X)*f, Y: q.Y + (p.Y-q.Y)*f}
+}
+
+// XY returns the x and y coordinates of the Callouts.
+func (r *Callouts) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Callouts.
+func (r *Callouts) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Callouts' X and Y values as the drawing coordinates.
+func (r *Callouts) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a glyphbox for the callouts rendering including the placed
+// callout boxes.
+func (r *Callouts) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	bounds := r.Base.Arc().bounds(0, r.Anchor)
+	if boxes, err := r.Boxes(); err == nil {
+		for _, b := range boxes {
+			bounds = union(bounds, b)
+		}
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: bounds,
+	}}
+}
@@ -0,0 +1,199 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image"
+	"math"
+	"strings"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Center implements rendering of text and an image in the center of a ring plot.
+// The text and image are both centered on the rendering location shared with the
+// rings of the plot.
+type Center struct {
+	// Text is the text to render. Newlines in Text separate paragraphs, and
+	// paragraphs are wrapped at spaces to fit within the Width and Radius.
+	Text string
+
+	// TextStyle is the style of the text.
+	TextStyle draw.TextStyle
+
+	// LineSpacing is the additional distance between adjacent lines of text.
+	LineSpacing vg.Length
+
+	// Width is the maximum width of a line of text. If Width is zero, the width
+	// of lines is not limited by Width.
+	Width vg.Length
+
+	// Radius is the radius of the circle that lines of text are wrapped to fit
+	// within. If Radius is zero, the width of lines is not limited by Radius.
+	// Words wider than the available width are placed on a line alone.
+	Radius vg.Length
+
+	// Image, if not nil, is rendered under the text, scaled so that its corners
+	// lie on the circle of ImageRadius.
+	Image image.Image
+
+	// ImageRadius is the radius of the circle the Image is scaled to fit.
+	ImageRadius vg.Length
+
+	// Layer specifies the drawing layer of the Center when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewCenter returns a Center rendering the provided text with the given text style.
+func NewCenter(text string, sty draw.TextStyle) *Center {
+	return &Center{Text: text, TextStyle: sty}
+}
+
+// Lines returns the lines of text rendered by the Center after wrapping.
+func (r *Center) Lines() []string {
+	if r.Text == "" {
+		return nil
+	}
+
+	// The width available to each line within the Radius depends on the number
+	// of lines, so the text is wrapped until the number of lines is stable.
+	n := 1
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = r.wrap(n)
+		if len(lines) == n {
+			break
+		}
+		n = len(lines)
+	}
+	return lines
+}
+
+// wrap returns the lines of the Center's text wrapped for a layout of n lines.
+func (r *Center) wrap(n int) []string {
+	var lines []string
+	for _, para := range strings.Split(r.Text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, w := range words[1:] {
+			if lw, _ := TextBounds(r.TextStyle, line+" "+w); lw <= r.lineWidth(len(lines), n) {
+				line += " " + w
+				continue
+			}
+			lines = append(lines, line)
+			line = w
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// lineWidth returns the width available to line i of a layout of n lines.
+func (r *Center) lineWidth(i, n int) vg.Length {
+	w := vg.Length(math.Inf(1))
+	if r.Width > 0 {
+		w = r.Width
+	}
+	if r.Radius > 0 {
+		// The width is limited by the chord at the line edge furthest from the center.
+		top := r.lineCenter(i, n) + r.lineHeight()/2
+		y := math.Max(math.Abs(float64(top)), math.Abs(float64(top-r.lineHeight())))
+		var chord vg.Length
+		if y < float64(r.Radius) {
+			chord = 2 * vg.Length(math.Sqrt(float64(r.Radius*r.Radius)-y*y))
+		}
+		if chord < w {
+			w = chord
+		}
+	}
+	return w
+}
+
+// lineHeight returns the height of a line of text.
+func (r *Center) lineHeight() vg.Length { return lineHeight(r.TextStyle) }
+
+// lineCenter returns the vertical displacement of the center of line i of a layout
+// of n lines from the center of the text.
+func (r *Center) lineCenter(i, n int) vg.Length {
+	step := r.lineHeight() + r.LineSpacing
+	return (vg.Length(n-1)/2 - vg.Length(i)) * step
+}
+
+// DrawAt renders the Center at cen in the specified drawing area, according to the
+// Center configuration.
+func (r *Center) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if r.Image != nil && r.ImageRadius > 0 {
+		ca.DrawImage(imageRect(r.Image, cen, r.ImageRadius), r.Image)
+	}
+
+	if r.TextStyle.Color == nil || r.TextStyle.Font.Size == 0 {
+		return
+	}
+	lines := r.Lines()
+	for i, l := range lines {
+		if l == "" {
+			continue
+		}
+		pt := cen.Add(vg.Point{Y: r.lineCenter(i, len(lines))})
+		ca.FillText(r.TextStyle, pt, -0.5, -0.5, l)
+	}
+}
+
+// imageRect returns the rectangle centered on cen with the aspect ratio of img and
+// its corners on the circle of radius rad around cen.
+func imageRect(img image.Image, cen vg.Point, rad vg.Length) vg.Rectangle {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	s := 2 * float64(rad) / math.Hypot(w, h)
+	half := vg.Point{X: vg.Length(w * s / 2), Y: vg.Length(h * s / 2)}
+	return vg.Rectangle{Min: cen.Sub(half), Max: cen.Add(half)}
+}
+
+// XY returns the x and y coordinates of the Center.
+func (r *Center) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Center.
+func (r *Center) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Center's X and Y values as the drawing coordinates.
+func (r *Center) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the center rendering.
+func (r *Center) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	var w, h vg.Length
+	if r.Image != nil {
+		w, h = r.ImageRadius, r.ImageRadius
+	}
+	if lines := r.Lines(); len(lines) != 0 {
+		for _, l := range lines {
+			if lw, _ := TextBounds(r.TextStyle, l); lw/2 > w {
+				w = lw / 2
+			}
+		}
+		if th := r.lineCenter(0, len(lines)) + r.lineHeight()/2; th > h {
+			h = th
+		}
+	}
+	return []plot.GlyphBox{{
+		X: plt.X.Norm(r.X),
+		Y: plt.Y.Norm(r.Y),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{-w, -h},
+			Max: vg.Point{w, h},
+		},
+	}}
+}
@@ -0,0 +1,494 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image"
+	"image/color"
+	imgdraw "image/draw"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Clip is a plot.Plotter that renders a ring clipped to an annulus, or an annular sector,
+// about the center of the ring, so that no part of the ring's rendering falls outside the
+// region. Clipping is performed geometrically by the Clip rather than by the canvas, so
+// it is supported by all canvas backends, including vgimg, vgsvg, vgpdf and vgeps:
+//
+//   - filled paths are intersected with the region,
+//   - stroked paths are cut where they cross the boundary of the region, so the
+//     ink of a stroke reaching the boundary extends beyond it by up to half its
+//     line width,
+//   - text is drawn only if its bounding box lies within the region, and
+//   - images are masked to the region.
+//
+// Paths lying within the region are drawn unaltered. The arcs of paths crossing the
+// boundary are approximated by line segments.
+type Clip struct {
+	// Plotter is the clipped ring.
+	Plotter plot.Plotter
+
+	// Inner and Outer are the radii of the clipping region.
+	Inner, Outer vg.Length
+
+	// Arc, if not nil, restricts the clipping region to the annular
+	// sector swept by the Arc.
+	Arc *Arc
+
+	// FillRule determines the interior of clipped filled paths that
+	// overlap themselves. If FillRule is DefaultRule, the even-odd rule
+	// is used.
+	FillRule FillRule
+}
+
+// Clipped returns a Clip rendering p clipped to the annulus between the inner and outer
+// radii, restricted to the annular sector swept by arc if arc is not nil.
+func Clipped(p plot.Plotter, inner, outer vg.Length, arc *Arc) plot.Plotter {
+	return &Clip{Plotter: p, Inner: inner, Outer: outer, Arc: arc}
+}
+
+// DrawAt renders the clipped ring at cen in the specified drawing area. If the Plotter
+// cannot be drawn about a center point, nothing is rendered.
+func (r *Clip) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if p, ok := r.Plotter.(drawAter); ok {
+		p.DrawAt(r.canvas(ca, cen), cen)
+	}
+}
+
+// Plot calls Plot on the Plotter with a canvas clipping rendering to the region about
+// the center given by the Plotter's X and Y values, or the origin if the Plotter is not
+// an XYer.
+func (r *Clip) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	x, y := r.XY()
+	r.Plotter.Plot(r.canvas(ca, vg.Point{trX(x), trY(y)}), plt)
+}
+
+// XY returns the x and y coordinates of the Plotter, or zero if the Plotter is not an XYer.
+func (r *Clip) XY() (x, y float64) {
+	if xy, ok := r.Plotter.(XYer); ok {
+		return xy.XY()
+	}
+	return 0, 0
+}
+
+// DrawLayer returns the drawing layer of the Plotter, or the DataLayer if the Plotter is
+// not a Layerer.
+func (r *Clip) DrawLayer() int {
+	if l, ok := r.Plotter.(Layerer); ok {
+		return l.DrawLayer()
+	}
+	return DataLayer
+}
+
+// Extent returns the intersection of the extent of the Plotter, as calculated by Bounds,
+// and the bounds of the clipping region.
+func (r *Clip) Extent() vg.Rectangle {
+	b := Bounds(r.Plotter)
+	if b == (vg.Rectangle{}) {
+		return b
+	}
+	reg := r.arc().bounds(r.Inner, r.Outer)
+	b.Min.X, b.Min.Y = vg.Length(math.Max(float64(b.Min.X), float64(reg.Min.X))), vg.Length(math.Max(float64(b.Min.Y), float64(reg.Min.Y)))
+	b.Max.X, b.Max.Y = vg.Length(math.Min(float64(b.Max.X), float64(reg.Max.X))), vg.Length(math.Min(float64(b.Max.Y), float64(reg.Max.Y)))
+	if b.Min.X > b.Max.X || b.Min.Y > b.Max.Y {
+		return vg.Rectangle{}
+	}
+	return b
+}
+
+// GlyphBoxes returns a glyphbox for the clipped rendering.
+func (r *Clip) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	x, y := r.XY()
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(x),
+		Y:         plt.Y.Norm(y),
+		Rectangle: r.Extent(),
+	}}
+}
+
+// arc returns the arc of the clipping region.
+func (r *Clip) arc() Arc {
+	if r.Arc == nil {
+		return Arc{Theta: 0, Phi: Complete}
+	}
+	return *r.Arc
+}
+
+// canvas returns a canvas clipping rendering on ca to the region about cen.
+func (r *Clip) canvas(ca draw.Canvas, cen vg.Point) draw.Canvas {
+	rule := r.FillRule
+	if rule == DefaultRule {
+		rule = EvenOddRule
+	}
+	s := Sector{Center: cen, Inner: r.Inner, Outer: r.Outer, Arc: r.arc()}
+	ca.Canvas = &clipCanvas{
+		Canvas: ca.Canvas,
+		sector: s,
+		region: clipRegion(s),
+		rule:   rule,
+		ctm:    identity,
+	}
+	return ca
+}
+
+// clipRegion returns the closed polygons bounding the sector. Arcs along the outer radius
+// are inscribed in the outer circle and arcs along the inner radius circumscribe the inner
+// circle so that the polygons lie within the sector.
+func clipRegion(s Sector) [][]vg.Point {
+	arc := func(pts []vg.Point, r vg.Length, from, sweep Angle) []vg.Point {
+		n := int(math.Ceil(math.Abs(float64(sweep)) / flattenStep))
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i <= n; i++ {
+			pts = append(pts, RectangularAt(s.Center, from+sweep*Angle(i)/Angle(n), r))
+		}
+		return pts
+	}
+	in := s.Inner / vg.Length(math.Cos(flattenStep/2))
+	if math.Abs(float64(s.Phi)) >= float64(Complete) {
+		polys := [][]vg.Point{arc(nil, s.Outer, 0, Complete)}
+		if s.Inner > 0 {
+			polys = append(polys, arc(nil, in, 0, -Complete))
+		}
+		return polys
+	}
+	pts := arc(nil, s.Outer, s.Theta, s.Phi)
+	if s.Inner > 0 {
+		pts = arc(pts, in, s.Theta+s.Phi, -s.Phi)
+	} else {
+		pts = append(pts, s.Center)
+	}
+	return [][]vg.Point{pts}
+}
+
+// affine is a two dimensional affine transform mapping (x, y) to
+// (a*x + c*y + e, b*x + d*y + f).
+type affine struct{ a, b, c, d, e, f float64 }
+
+var identity = affine{a: 1, d: 1}
+
+// then returns the transform applying n and then m.
+func (m affine) then(n affine) affine {
+	return affine{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+// inverse returns the inverse of m.
+func (m affine) inverse() affine {
+	det := m.a*m.d - m.b*m.c
+	return affine{
+		a: m.d / det,
+		b: -m.b / det,
+		c: -m.c / det,
+		d: m.a / det,
+		e: (m.c*m.f - m.d*m.e) / det,
+		f: (m.b*m.e - m.a*m.f) / det,
+	}
+}
+
+// apply returns p transformed by m.
+func (m affine) apply(p vg.Point) vg.Point {
+	x, y := float64(p.X), float64(p.Y)
+	return vg.Point{X: vg.Length(m.a*x + m.c*y + m.e), Y: vg.Length(m.b*x + m.d*y + m.f)}
+}
+
+// clipCanvas is a vg.Canvas that clips rendering to a sector before passing it to the
+// underlying canvas. The transforms applied to the canvas are tracked so that the sector
+// may be expressed in the coordinates in which paths are drawn.
+type clipCanvas struct {
+	vg.Canvas
+
+	// sector and region are the clipping sector and its
+	// bounding polygons in the coordinates of the canvas
+	// before any transform was applied.
+	sector Sector
+	region [][]vg.Point
+
+	// rule is the fill rule of clipped fills.
+	rule FillRule
+
+	// ctm is the current transform and stack holds the
+	// transforms saved by Push.
+	ctm   affine
+	stack []affine
+
+	// user holds the region in the current coordinates
+	// and its edges, or nil if they must be recalculated.
+	user  [][]vg.Point
+	edges []edge
+}
+
+func (c *clipCanvas) Rotate(rad float64) {
+	sin, cos := math.Sincos(rad)
+	c.transform(affine{a: cos, b: sin, c: -sin, d: cos})
+	c.Canvas.Rotate(rad)
+}
+
+func (c *clipCanvas) Translate(pt vg.Point) {
+	c.transform(affine{a: 1, d: 1, e: float64(pt.X), f: float64(pt.Y)})
+	c.Canvas.Translate(pt)
+}
+
+func (c *clipCanvas) Scale(x, y float64) {
+	c.transform(affine{a: x, d: y})
+	c.Canvas.Scale(x, y)
+}
+
+func (c *clipCanvas) Push() {
+	c.stack = append(c.stack, c.ctm)
+	c.Canvas.Push()
+}
+
+func (c *clipCanvas) Pop() {
+	if n := len(c.stack); n != 0 {
+		c.ctm = c.stack[n-1]
+		c.stack = c.stack[:n-1]
+		c.user = nil
+	}
+	c.Canvas.Pop()
+}
+
+// transform applies m to the current transform.
+func (c *clipCanvas) transform(m affine) {
+	c.ctm = c.ctm.then(m)
+	c.user = nil
+}
+
+// userRegion returns the clipping region in the current coordinates and its edges.
+func (c *clipCanvas) userRegion() ([][]vg.Point, []edge) {
+	if c.user != nil {
+		return c.user, c.edges
+	}
+	if c.ctm == identity {
+		c.user = c.region
+	} else {
+		inv := c.ctm.inverse()
+		c.user = make([][]vg.Point, len(c.region))
+		for i, r := range c.region {
+			c.user[i] = make([]vg.Point, len(r))
+			for j, p := range r {
+				c.user[i][j] = inv.apply(p)
+			}
+		}
+	}
+	c.edges = polyEdges(c.user)
+	return c.user, c.edges
+}
+
+// near returns the edges of the region whose bounding boxes overlap the bounding box
+// of polys.
+func (c *clipCanvas) near(polys [][]vg.Point) []edge {
+	min, max, ok := bounds(polys)
+	if !ok {
+		return nil
+	}
+	_, edges := c.userRegion()
+	var near []edge
+	for _, e := range edges {
+		if math.Max(float64(e.a.X), float64(e.b.X)) < float64(min.X) || math.Min(float64(e.a.X), float64(e.b.X)) > float64(max.X) ||
+			math.Max(float64(e.a.Y), float64(e.b.Y)) < float64(min.Y) || math.Min(float64(e.a.Y), float64(e.b.Y)) > float64(max.Y) {
+			continue
+		}
+		near = append(near, edge{a: e.a, b: e.b})
+	}
+	return near
+}
+
+// in returns whether pt lies within the region.
+func (c *clipCanvas) in(pt vg.Point) bool {
+	region, _ := c.userRegion()
+	return winding(region, pt) != 0
+}
+
+// within returns whether the closed polygons in polys lie entirely within the region.
+func (c *clipCanvas) within(polys [][]vg.Point) bool {
+	if len(polys) == 0 || !c.in(polys[0][0]) {
+		return false
+	}
+	near := c.near(polys)
+	for _, e := range polyEdges(polys) {
+		for _, f := range near {
+			if _, _, _, ok := e.crossing(f); ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Fill fills the intersection of pa and the clipping region.
+func (c *clipCanvas) Fill(pa vg.Path) {
+	polys := flatten(pa)
+	if len(polys) == 0 {
+		return
+	}
+	near := c.near(polys)
+	if len(near) == 0 {
+		// The path lies entirely within or
+		// entirely outside the region.
+		if c.in(polys[0][0]) {
+			c.Canvas.Fill(pa)
+		}
+		return
+	}
+	if c.within(polys) {
+		c.Canvas.Fill(pa)
+		return
+	}
+	edges := append(polyEdges(polys), near...)
+	splitEdges(edges)
+	out := boundary(edges, func(pt vg.Point) bool {
+		return c.rule.inside(winding(polys, pt)) && c.in(pt)
+	})
+	if len(out) != 0 {
+		c.Canvas.Fill(out)
+	}
+}
+
+// Stroke strokes the parts of pa lying within the clipping region.
+func (c *clipCanvas) Stroke(pa vg.Path) {
+	lines := polylines(pa)
+	if len(lines) == 0 {
+		return
+	}
+	near := c.near(lines)
+
+	var (
+		out     vg.Path
+		clipped bool
+	)
+	for _, l := range lines {
+		joined := false
+		for i := 1; i < len(l); i++ {
+			seg := edge{a: l[i-1], b: l[i]}
+			for _, f := range near {
+				t, _, p, ok := seg.crossing(f)
+				if ok && 0 < t && t < 1 {
+					seg.splits = append(seg.splits, split{t: t, p: p})
+				}
+			}
+			sort.Sort(splits(seg.splits))
+			clipped = clipped || len(seg.splits) != 0
+			from := seg.a
+			for _, to := range append(seg.points(), seg.b) {
+				mid := vg.Point{X: (from.X + to.X) / 2, Y: (from.Y + to.Y) / 2}
+				if !c.in(mid) {
+					clipped = true
+					joined = false
+					from = to
+					continue
+				}
+				if !joined {
+					out.Move(from)
+					joined = true
+				}
+				out.Line(to)
+				from = to
+			}
+		}
+	}
+	switch {
+	case !clipped:
+		c.Canvas.Stroke(pa)
+	case len(out) != 0:
+		c.Canvas.Stroke(out)
+	}
+}
+
+// polylines returns the line segment approximations of the subpaths of pa. Closed
+// subpaths end at their starting point.
+func polylines(pa vg.Path) [][]vg.Point {
+	var (
+		lines [][]vg.Point
+		line  []vg.Point
+	)
+	for _, c := range pa {
+		switch c.Type {
+		case vg.MoveComp:
+			if len(line) > 1 {
+				lines = append(lines, line)
+			}
+			line = []vg.Point{c.Pos}
+		case vg.LineComp:
+			line = append(line, c.Pos)
+		case vg.ArcComp:
+			n := int(math.Ceil(math.Abs(c.Angle) / flattenStep))
+			for i := 0; i <= n; i++ {
+				var theta float64
+				if n != 0 {
+					theta = c.Start + c.Angle*float64(i)/float64(n)
+				}
+				line = append(line, RectangularAt(c.Pos, Angle(theta), c.Radius))
+			}
+		case vg.CloseComp:
+			if len(line) != 0 {
+				line = append(line, line[0])
+				lines = append(lines, line)
+				line = []vg.Point{line[0]}
+			}
+		}
+	}
+	if len(line) > 1 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// FillString draws the text if its bounding box lies within the clipping region.
+func (c *clipCanvas) FillString(f vg.Font, pt vg.Point, text string) {
+	e := f.Extents()
+	box := vg.Rectangle{
+		Min: vg.Point{X: pt.X, Y: pt.Y + e.Descent},
+		Max: vg.Point{X: pt.X + f.Width(text), Y: pt.Y + e.Ascent},
+	}
+	if c.within([][]vg.Point{rectPoly(box)}) {
+		c.Canvas.FillString(f, pt, text)
+	}
+}
+
+// DrawImage draws the image masked to the clipping region.
+func (c *clipCanvas) DrawImage(rect vg.Rectangle, img image.Image) {
+	if c.within([][]vg.Point{rectPoly(rect)}) {
+		c.Canvas.DrawImage(rect, img)
+		return
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return
+	}
+	masked := image.NewRGBA(b)
+	imgdraw.Draw(masked, b, img, b.Min, imgdraw.Src)
+	w, h := rect.Max.X-rect.Min.X, rect.Max.Y-rect.Min.Y
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// Image rows run downwards from the top of rect.
+			p := c.ctm.apply(vg.Point{
+				X: rect.Min.X + w*vg.Length((float64(x-b.Min.X)+0.5)/float64(b.Dx())),
+				Y: rect.Max.Y - h*vg.Length((float64(y-b.Min.Y)+0.5)/float64(b.Dy())),
+			})
+			if _, ok := c.sector.Position(p); !ok {
+				masked.SetRGBA(x, y, color.RGBA{})
+			}
+		}
+	}
+	c.Canvas.DrawImage(rect, masked)
+}
+
+// rectPoly returns the corners of r as a closed polygon.
+func rectPoly(r vg.Rectangle) []vg.Point {
+	return []vg.Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}}
+}
@@ -0,0 +1,108 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"hash/fnv"
+	"image/color"
+	"sort"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// slotOf returns the index of the palette slot of name in a palette of n colors. The
+// slot is given by the 64-bit FNV-1a hash of the bytes of name, so it depends only on
+// name and n.
+func slotOf(name string, n int) int {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int(h.Sum64() % uint64(n))
+}
+
+// ColorOf returns the color of p for the given name. The color is chosen by a stable
+// hash of name, so a name is given the same color of a palette in every figure and on
+// every run. If p is empty, ColorOf returns nil.
+func ColorOf(name string, p []color.Color) color.Color {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[slotOf(name, len(p))]
+}
+
+// ColorsFor returns colors of p for the features in fs, keyed by feature, suitable
+// for use as the Colors of a Blocks or with ColorByFeature. Each feature is given the
+// color returned by ColorOf for its name unless that color has been given to another
+// feature, in which case it is given the next unused color of p. Features are assigned
+// in order of name, so the assignment depends only on the names of the features and
+// p, and not on the order of fs. Distinct features are given distinct colors when p
+// holds enough colors; otherwise the features left without an unused color are given
+// the color returned by ColorOf. Nil features are ignored. If p is empty, the returned
+// map is empty.
+func ColorsFor(fs []feat.Feature, p []color.Color) map[feat.Feature]color.Color {
+	colors := make(map[feat.Feature]color.Color, len(fs))
+	if len(p) == 0 {
+		return colors
+	}
+
+	seen := make(map[feat.Feature]bool, len(fs))
+	var slots []featureSlot
+	for i, f := range fs {
+		if f == nil || seen[f] {
+			continue
+		}
+		seen[f] = true
+		slots = append(slots, featureSlot{f: f, name: f.Name(), index: i, slot: slotOf(f.Name(), len(p))})
+	}
+	sort.Stable(byFeatureName(slots))
+
+	// Features are given their hash slot if it is free and then
+	// the next free slot, in order of name.
+	used := make([]bool, len(p))
+	var displaced []featureSlot
+	for _, s := range slots {
+		if used[s.slot] {
+			displaced = append(displaced, s)
+			continue
+		}
+		used[s.slot] = true
+		colors[s.f] = p[s.slot]
+	}
+	free := len(p) - (len(slots) - len(displaced))
+	for _, s := range displaced {
+		if free == 0 {
+			colors[s.f] = p[s.slot]
+			continue
+		}
+		i := s.slot
+		for used[i] {
+			i = (i + 1) % len(p)
+		}
+		used[i] = true
+		free--
+		colors[s.f] = p[i]
+	}
+	return colors
+}
+
+// featureSlot is the hash slot of a feature's name in a palette.
+type featureSlot struct {
+	f     feat.Feature
+	name  string
+	index int
+	slot  int
+}
+
+// byFeatureName sorts featureSlots by name and then by position in the slice of
+// features they were taken from.
+type byFeatureName []featureSlot
+
+func (s byFeatureName) Len() int { return len(s) }
+func (s byFeatureName) Less(i, j int) bool {
+	if s[i].name != s[j].name {
+		return s[i].name < s[j].name
+	}
+	return s[i].index < s[j].index
+}
+func (s byFeatureName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
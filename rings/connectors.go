@@ -0,0 +1,183 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/bezier"
+)
+
+// Connector is a type that describes the ends of a connector.
+type Connector interface {
+	// Positions returns the locations of the connector ends and the positions
+	// of the ends within their locations.
+	Positions() (loc [2]feat.Feature, pos [2]int)
+}
+
+// Connection is a Connector joining fixed positions within features.
+type Connection struct {
+	Loc [2]feat.Feature
+	Pos [2]int
+}
+
+// Positions returns the Loc and Pos of the Connection.
+func (c Connection) Positions() (loc [2]feat.Feature, pos [2]int) { return c.Loc, c.Pos }
+
+// Connectors implements rendering of lines joining positions at two radii. Each connector
+// is drawn as a radial stub from each end joined by a straight or smoothly curved line.
+type Connectors struct {
+	// Set holds a collection of connectors to render.
+	Set []Connector
+
+	// Ends holds the elements that define the targets of the connector ends.
+	Ends [2]ArcOfer
+	// Radii indicates the distance of the connector end points from the center of the plot.
+	Radii [2]vg.Length
+
+	// Stubs holds the lengths of the radial stubs at each end of the connectors.
+	// Stubs extend from each end towards the radius of the other end.
+	Stubs [2]vg.Length
+
+	// Segments specifies the number of segments used to render each connector as a
+	// Bézier curve through the stub ends. If Segments is less than 2 the stub ends
+	// are joined with a straight line.
+	Segments int
+
+	// LineStyle determines the line style of each connector. LineStyle behaviour
+	// is over-ridden if the Connector is a LineStyler.
+	LineStyle draw.LineStyle
+
+	// Layer specifies the drawing layer of the Connectors when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewConnectors returns a Connectors based on the parameters, first checking that the provided
+// connectors are able to be rendered. An error is returned if the connectors are not renderable.
+func NewConnectors(cs []Connector, ends [2]ArcOfer, r [2]vg.Length) (*Connectors, error) {
+	for _, c := range cs {
+		loc, pos := c.Positions()
+		for i, l := range loc {
+			if l != nil && (pos[i] < l.Start() || pos[i] > l.End()) {
+				return nil, errors.New("rings: connector position out of range")
+			}
+			if _, err := ends[i].ArcOf(l, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Connectors{
+		Set:   cs,
+		Ends:  ends,
+		Radii: r,
+	}, nil
+}
+
+// DrawAt renders the connectors of a Connectors at cen in the specified drawing area,
+// according to the Connectors configuration.
+func (r *Connectors) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if len(r.Set) == 0 {
+		return
+	}
+
+	var pa vg.Path
+	for _, c := range r.Set {
+		var sty draw.LineStyle
+		if ls, ok := c.(LineStyler); ok {
+			sty = ls.LineStyle()
+		} else {
+			sty = r.LineStyle
+		}
+		if sty.Color == nil || sty.Width == 0 {
+			continue
+		}
+
+		pts, err := r.points(c)
+		if err != nil {
+			panic(fmt.Sprint("rings: no arc for feature location:", err))
+		}
+
+		pa = pa[:0]
+		pa.Move(cen.Add(pts[0]))
+		if r.Segments > 1 {
+			b := bezier.New(pts[:]...)
+			for i := 1; i <= r.Segments; i++ {
+				pa.Line(cen.Add(b.Point(float64(i) / float64(r.Segments))))
+			}
+		} else {
+			for _, p := range pts[1:] {
+				pa.Line(cen.Add(p))
+			}
+		}
+
+		ca.SetLineStyle(sty)
+		ca.Stroke(pa)
+	}
+}
+
+// points returns the end and stub points of the connector c relative to the center.
+func (r *Connectors) points(c Connector) ([4]vg.Point, error) {
+	var pts [4]vg.Point
+	loc, pos := c.Positions()
+	var angles [2]Angle
+	for i := range angles {
+		var err error
+		angles[i], err = angleAt(r.Ends[i], loc[i], pos[i])
+		if err != nil {
+			return pts, err
+		}
+	}
+
+	radii := r.Radii
+	for i := range radii {
+		radii[i] += offsetOf(r.Ends[i], loc[i], locus{loc: loc[i], pos: pos[i]})
+	}
+	dir := vg.Length(1)
+	if radii[1] < radii[0] {
+		dir = -1
+	}
+	pts[0] = Rectangular(angles[0], radii[0])
+	pts[1] = Rectangular(angles[0], radii[0]+dir*r.Stubs[0])
+	pts[2] = Rectangular(angles[1], radii[1]-dir*r.Stubs[1])
+	pts[3] = Rectangular(angles[1], radii[1])
+	return pts, nil
+}
+
+// XY returns the x and y coordinates of the Connectors.
+func (r *Connectors) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Connectors.
+func (r *Connectors) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Connectors' X and Y values as the drawing coordinates.
+func (r *Connectors) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the connectors rendering.
+func (r *Connectors) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	rad := r.Radii[0]
+	if r.Radii[1] > rad {
+		rad = r.Radii[1]
+	}
+	return []plot.GlyphBox{{
+		X: plt.X.Norm(r.X),
+		Y: plt.Y.Norm(r.Y),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{-rad, -rad},
+			Max: vg.Point{rad, rad},
+		},
+	}}
+}
@@ -0,0 +1,75 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	imgdraw "image/draw"
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// rasterCanvas is a canvas rendering to an image at a known resolution.
+type rasterCanvas interface {
+	DPI() float64
+	Image() imgdraw.Image
+}
+
+// snapper snaps positions and radii of strokes to the pixel grid of a raster canvas
+// so that circular and radial strokes render crisply. The zero snapper leaves
+// positions and radii unaltered.
+type snapper struct {
+	scale float64 // Pixels per point.
+	off   float64 // Offset from pixel boundaries of stroke centers in pixels.
+}
+
+// crisp returns a snapper for strokes of width w on ca. Strokes on vector canvases
+// are not snapped.
+func crisp(ca draw.Canvas, w vg.Length) snapper {
+	rc, ok := ca.Canvas.(rasterCanvas)
+	if !ok {
+		return snapper{}
+	}
+	scale := rc.DPI() / 72
+	width := math.Max(1, math.Floor(float64(w)*scale+0.5))
+	return snapper{scale: scale, off: math.Mod(width, 2) / 2}
+}
+
+// point returns p moved so that a stroke through p along a pixel row or column
+// covers whole pixels.
+func (s snapper) point(p vg.Point) vg.Point {
+	return vg.Point{X: s.position(p.X), Y: s.position(p.Y)}
+}
+
+func (s snapper) position(v vg.Length) vg.Length {
+	if s.scale == 0 {
+		return v
+	}
+	return vg.Length((math.Floor(float64(v)*s.scale-s.off+0.5) + s.off) / s.scale)
+}
+
+// length returns l rounded to a whole number of pixels.
+func (s snapper) length(l vg.Length) vg.Length {
+	if s.scale == 0 {
+		return l
+	}
+	return vg.Length(math.Floor(float64(l)*s.scale+0.5) / s.scale)
+}
+
+// clean returns p with coordinates within rounding error of a snapped position
+// placed at the snapped position. Points calculated from snapped centers and radii
+// may otherwise fall fractionally off the pixel grid.
+func (s snapper) clean(p vg.Point) vg.Point {
+	if s.scale == 0 {
+		return p
+	}
+	for _, v := range []*vg.Length{&p.X, &p.Y} {
+		if q := s.position(*v); math.Abs(float64(q-*v))*s.scale < 1e-6 {
+			*v = q
+		}
+	}
+	return p
+}
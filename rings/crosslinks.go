@@ -0,0 +1,168 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/graphics/bezier"
+)
+
+// CrossLinks implements rendering of feat.Feature associations as Bézier curves between
+// two ring plots drawn with different centers on the same canvas.
+type CrossLinks struct {
+	// Set holds a collection of feature pairs to render.
+	Set []Pair
+
+	// Ends holds the elements that define the end targets of the rendered links. The
+	// first end is rendered in the plot at the first center and the second in the plot
+	// at the second center.
+	Ends [2]ArcOfer
+	// Radii indicates the distance of the link end points from the center of their plot.
+	Radii [2]vg.Length
+
+	// Bezier describes the Bézier configuration for link rendering. The Bezier's
+	// Radius, Crest and Purity are interpreted as described by Bezier.ControlPointsAt.
+	Bezier *Bezier
+
+	// LineStyle determines the line style of each link Bézier curve. LineStyle behaviour
+	// is over-ridden if the Pair describing features is a LineStyler.
+	LineStyle draw.LineStyle
+
+	// LineStyleFunc, if not nil, is called once for each Pair that is not a LineStyler
+	// when the CrossLinks is drawn and the returned style is used in place of LineStyle.
+	LineStyleFunc func(Pair) draw.LineStyle
+
+	// Centered specifies that each link end is placed at the middle of the arc of
+	// its feature. Otherwise link ends are placed at the start of their features.
+	Centered bool
+
+	// Layer specifies the drawing layer of the CrossLinks when rendered by a Layered.
+	Layer int
+
+	// Centers specifies the rendering locations of the two plots when Plot is called.
+	Centers [2]Point
+}
+
+// NewCrossLinks returns a CrossLinks based on the parameters, first checking that the provided
+// features are able to be rendered. An error is returned if the features are not renderable.
+func NewCrossLinks(fp []Pair, ends [2]ArcOfer, r [2]vg.Length) (*CrossLinks, error) {
+	for _, p := range fp {
+		for i, f := range p.Features() {
+			if f.End() < f.Start() {
+				return nil, errors.New("rings: inverted feature")
+			}
+			if _, err := ends[i].ArcOf(nil, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &CrossLinks{
+		Set:   fp,
+		Ends:  ends,
+		Radii: r,
+	}, nil
+}
+
+// links returns a Links sharing the configuration of the CrossLinks.
+func (r *CrossLinks) links() *Links {
+	return &Links{
+		Set:           r.Set,
+		Ends:          r.Ends,
+		Radii:         r.Radii,
+		Bezier:        r.Bezier,
+		LineStyle:     r.LineStyle,
+		LineStyleFunc: r.LineStyleFunc,
+		Centered:      r.Centered,
+	}
+}
+
+// DrawAt renders the feature pairs of a CrossLinks between the plots at cens in the
+// specified drawing area, according to the CrossLinks configuration.
+func (r *CrossLinks) DrawAt(ca draw.Canvas, cens [2]vg.Point) {
+	if len(r.Set) == 0 {
+		return
+	}
+
+	// Check if we have a Bézier and we want more than one segment in the curve.
+	bez := r.Bezier != nil && r.Bezier.Segments > 1
+
+	l := r.links()
+	var pa vg.Path
+	for _, fp := range r.Set {
+		sty := l.styleOf(fp)
+		if sty.Color == nil || sty.Width == 0 {
+			continue
+		}
+
+		angles, ok := l.endAngles(fp)
+		if !ok {
+			continue
+		}
+		radii := l.endRadii(fp)
+		var p [2]vg.Point
+		for i := range p {
+			p[i] = RectangularAt(cens[i], angles[i], radii[i])
+		}
+
+		pa = pa[:0]
+		pa.Move(p[0])
+		if bez {
+			b := bezier.New(r.Bezier.ControlPointsAt(p, cens)...)
+			for i := 1; i <= r.Bezier.Segments; i++ {
+				pa.Line(b.Point(float64(i) / float64(r.Bezier.Segments)))
+			}
+		} else {
+			pa.Line(p[1])
+		}
+
+		ca.SetLineStyle(sty)
+		ca.Stroke(pa)
+	}
+}
+
+// DrawLayer returns the drawing layer of the CrossLinks.
+func (r *CrossLinks) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the CrossLinks' Centers values as the drawing coordinates.
+func (r *CrossLinks) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	var cens [2]vg.Point
+	for i, c := range r.Centers {
+		cens[i] = vg.Point{trX(c.X), trY(c.Y)}
+	}
+	r.DrawAt(ca, cens)
+}
+
+// GlyphBoxes returns a liberal glyphbox around each of the two plots joined by the
+// CrossLinks. The middle control points of the link curves lie between the centers,
+// so the curves are within the region spanned by the glyphboxes.
+func (r *CrossLinks) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if len(r.Set) == 0 {
+		return nil
+	}
+
+	rad := math.Max(float64(r.Radii[0]), float64(r.Radii[1]))
+	if r.Bezier != nil && r.Bezier.Crest != nil {
+		rad = math.Max(rad, float64(r.Bezier.Radius.Length))
+	}
+	boxes := make([]plot.GlyphBox, len(r.Centers))
+	for i, c := range r.Centers {
+		boxes[i] = plot.GlyphBox{
+			X: plt.X.Norm(c.X),
+			Y: plt.Y.Norm(c.Y),
+			Rectangle: vg.Rectangle{
+				Min: vg.Point{vg.Length(-rad), vg.Length(-rad)},
+				Max: vg.Point{vg.Length(rad), vg.Length(rad)},
+			},
+		}
+	}
+	return boxes
+}
@@ -0,0 +1,176 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image"
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/graphics/palette"
+)
+
+// DensityBlend specifies how the raster of a density rendering is combined with the
+// vector rendering of the links.
+type DensityBlend int
+
+const (
+	// DensityOnly renders only the density raster; links are not stroked.
+	DensityOnly DensityBlend = iota
+	// DensityUnder renders the density raster under the stroked links.
+	DensityUnder
+	// DensityOver renders the density raster over the stroked links.
+	DensityOver
+)
+
+// DensityOptions describes the rendering of links as a raster of link density. Each
+// link is flattened and stamped into an off-screen intensity buffer that counts the
+// number of links passing through each pixel. The counts are then mapped to colors
+// and the resulting image is drawn onto the canvas. Vector output formats embed the
+// raster.
+type DensityOptions struct {
+	// DPI is the resolution of the density raster. If DPI is zero, a resolution
+	// of 72 dots per inch is used.
+	DPI float64
+
+	// Radius is the radius in pixels of the stamp used to rasterise each link.
+	// A zero Radius stamps single pixels.
+	Radius float64
+
+	// Ramp maps the link count of each pixel relative to the greatest count in
+	// the raster, from 0 to 1, to a color. Pixels no link passes through are
+	// transparent. If Ramp is nil, palette.Greys is used.
+	Ramp palette.ColorFunc
+
+	// Blend specifies how the raster is combined with the stroked links.
+	Blend DensityBlend
+}
+
+// raster returns the density raster of the provided flattened paths and the
+// rectangle it covers on the canvas.
+func (d *DensityOptions) raster(paths []vg.Path) (*image.NRGBA, vg.Rectangle) {
+	dpi := d.DPI
+	if dpi == 0 {
+		dpi = 72
+	}
+	scale := dpi / 72 // Pixels per point.
+	rad := math.Max(d.Radius, 0)
+
+	// Find the bounds of the raster with room for the stamp.
+	min := vg.Point{X: vg.Length(math.Inf(1)), Y: vg.Length(math.Inf(1))}
+	max := vg.Point{X: vg.Length(math.Inf(-1)), Y: vg.Length(math.Inf(-1))}
+	for _, pa := range paths {
+		for _, c := range pa {
+			min.X = vg.Length(math.Min(float64(min.X), float64(c.Pos.X)))
+			min.Y = vg.Length(math.Min(float64(min.Y), float64(c.Pos.Y)))
+			max.X = vg.Length(math.Max(float64(max.X), float64(c.Pos.X)))
+			max.Y = vg.Length(math.Max(float64(max.Y), float64(c.Pos.Y)))
+		}
+	}
+	if min.X > max.X {
+		return nil, vg.Rectangle{}
+	}
+	pad := vg.Length((rad + 1) / scale)
+	min = vg.Point{X: min.X - pad, Y: min.Y - pad}
+	w := int(math.Ceil(float64(max.X+pad-min.X) * scale))
+	h := int(math.Ceil(float64(max.Y+pad-min.Y) * scale))
+	rect := vg.Rectangle{
+		Min: min,
+		Max: vg.Point{X: min.X + vg.Length(float64(w)/scale), Y: min.Y + vg.Length(float64(h)/scale)},
+	}
+
+	// Stamp each link into the count buffer, counting each link at most
+	// once per pixel.
+	var (
+		counts = make([]uint32, w*h)
+		last   = make([]int, w*h)
+	)
+	for i := range last {
+		last[i] = -1
+	}
+	stamp := func(link int, x, y float64) {
+		r := int(math.Ceil(rad))
+		cx, cy := int(math.Floor(x)), int(math.Floor(y))
+		for py := cy - r; py <= cy+r; py++ {
+			if py < 0 || py >= h {
+				continue
+			}
+			for px := cx - r; px <= cx+r; px++ {
+				if px < 0 || px >= w {
+					continue
+				}
+				if rad > 0 && math.Hypot(float64(px)+0.5-x, float64(py)+0.5-y) > rad {
+					continue
+				}
+				if i := py*w + px; last[i] != link {
+					last[i] = link
+					counts[i]++
+				}
+			}
+		}
+	}
+	// toPixel returns the raster coordinates of p. Raster rows run down the canvas.
+	toPixel := func(p vg.Point) (x, y float64) {
+		return float64(p.X-rect.Min.X) * scale, float64(rect.Max.Y-p.Y) * scale
+	}
+	for link, pa := range paths {
+		var px, py float64
+		for _, c := range pa {
+			x, y := toPixel(c.Pos)
+			if c.Type == vg.MoveComp {
+				stamp(link, x, y)
+				px, py = x, y
+				continue
+			}
+			// Step along the segment at most half a pixel at a time.
+			n := int(math.Ceil(2 * math.Hypot(x-px, y-py)))
+			for k := 1; k <= n; k++ {
+				f := float64(k) / float64(n)
+				stamp(link, px+(x-px)*f, py+(y-py)*f)
+			}
+			px, py = x, y
+		}
+	}
+
+	// Convert the counts to an intensity image and map the intensities to colors.
+	gray := image.NewGray16(image.Rect(0, 0, w, h))
+	var most uint16
+	for i, n := range counts {
+		if n > math.MaxUint16 {
+			n = math.MaxUint16
+		}
+		gray.Pix[2*i] = uint8(n >> 8)
+		gray.Pix[2*i+1] = uint8(n)
+		if uint16(n) > most {
+			most = uint16(n)
+		}
+	}
+	ramp := d.Ramp
+	if ramp == nil {
+		ramp = palette.Greys()
+	}
+	img := image.NewNRGBA(gray.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := gray.Gray16At(x, y).Y
+			if n == 0 {
+				continue
+			}
+			img.Set(x, y, ramp(float64(n)/float64(most)))
+		}
+	}
+	return img, rect
+}
+
+// draw renders the density raster of the provided flattened paths on ca.
+func (d *DensityOptions) draw(ca draw.Canvas, paths []vg.Path) {
+	img, rect := d.raster(paths)
+	if img == nil {
+		return
+	}
+	ca.DrawImage(rect, img)
+}
@@ -0,0 +1,353 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Geometry is the resolved geometry of a ring, suitable for encoding as JSON. All
+// angles are in radians, all lengths are in points and all coordinates are relative
+// to the centre of the ring. Colors are encoded as "#rrggbbaa" hexadecimal strings
+// of non-alpha-premultiplied color components.
+//
+// The JSON encoding of a Geometry has the form:
+//
+//	{
+//	 "type": "blocks"|"scores"|"links"|"labels",
+//	 "blocks": [{"name": string, "arc": {"theta": number, "phi": number},
+//	             "inner": number, "outer": number, "fill": string, "stroke": string}, ...],
+//	 "scores": [{"name": string, "arc": {"theta": number, "phi": number},
+//	             "radii": [number|null, ...], "cells": [<block>, ...]}, ...],
+//	 "links":  [{"names": [string, string], "angles": [number, number],
+//	             "radii": [number, number], "control": [{"x": number, "y": number}, ...],
+//	             "stroke": string}, ...],
+//	 "labels": [{"text": string, "angle": number, "anchor": {"x": number, "y": number},
+//	             "rotation": number, "xalign": number, "yalign": number, "color": string}, ...]
+//	}
+//
+// Only the element list corresponding to the type is present, and empty fill, stroke
+// and color strings are omitted.
+type Geometry struct {
+	Type   string          `json:"type"`
+	Blocks []BlockGeometry `json:"blocks,omitempty"`
+	Scores []ScoreGeometry `json:"scores,omitempty"`
+	Links  []LinkGeometry  `json:"links,omitempty"`
+	Labels []LabelGeometry `json:"labels,omitempty"`
+}
+
+// EncodeTo writes the JSON encoding of the Geometry to w.
+func (g *Geometry) EncodeTo(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g)
+}
+
+// ArcGeometry is the JSON representation of an Arc.
+type ArcGeometry struct {
+	Theta float64 `json:"theta"`
+	Phi   float64 `json:"phi"`
+}
+
+func arcGeometry(a Arc) ArcGeometry { return ArcGeometry{Theta: float64(a.Theta), Phi: float64(a.Phi)} }
+
+// PointGeometry is the JSON representation of a vg.Point.
+type PointGeometry struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func pointGeometry(p vg.Point) PointGeometry { return PointGeometry{X: float64(p.X), Y: float64(p.Y)} }
+
+// BlockGeometry is the resolved geometry of an annular sector.
+type BlockGeometry struct {
+	Name   string      `json:"name,omitempty"`
+	Arc    ArcGeometry `json:"arc"`
+	Inner  float64     `json:"inner"`
+	Outer  float64     `json:"outer"`
+	Fill   string      `json:"fill,omitempty"`
+	Stroke string      `json:"stroke,omitempty"`
+}
+
+// ScoreGeometry is the resolved geometry of a Scorer. Radii holds the radius of each
+// score value, with nil representing values that would not be rendered. Cells holds
+// the sectors rendered for each score value by renderers that render blocks.
+type ScoreGeometry struct {
+	Name  string          `json:"name"`
+	Arc   ArcGeometry     `json:"arc"`
+	Radii []*float64      `json:"radii"`
+	Cells []BlockGeometry `json:"cells,omitempty"`
+}
+
+// LinkGeometry is the resolved geometry of a link between a pair of features.
+// Control holds the Bézier control points of the link, or the two end points
+// if the link is drawn as a straight line.
+type LinkGeometry struct {
+	Names   [2]string       `json:"names"`
+	Angles  [2]float64      `json:"angles"`
+	Radii   [2]float64      `json:"radii"`
+	Control []PointGeometry `json:"control"`
+	Stroke  string          `json:"stroke,omitempty"`
+}
+
+// LabelGeometry is the resolved geometry of a label.
+type LabelGeometry struct {
+	Text     string        `json:"text"`
+	Angle    float64       `json:"angle"`
+	Anchor   PointGeometry `json:"anchor"`
+	Rotation float64       `json:"rotation"`
+	XAlign   float64       `json:"xalign"`
+	YAlign   float64       `json:"yalign"`
+	Color    string        `json:"color,omitempty"`
+}
+
+// hexColor returns the "#rrggbbaa" representation of c, or the empty string if c is nil.
+func hexColor(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
+
+// strokeColor returns the hexadecimal color of sty if the style would be rendered.
+func strokeColor(sty draw.LineStyle) string {
+	if sty.Color == nil || sty.Width == 0 {
+		return ""
+	}
+	return hexColor(sty.Color)
+}
+
+// Describe returns the resolved geometry of the Blocks.
+func (r *Blocks) Describe() (*Geometry, error) {
+	g := &Geometry{Type: "blocks"}
+	for _, f := range r.Set {
+		arc, err := r.ArcOf(f.Location(), f)
+		if err != nil {
+			return nil, fmt.Errorf("rings: no arc for feature location: %v", err)
+		}
+		off := r.OffsetOf(f.Location(), f)
+		b := BlockGeometry{
+			Name:  f.Name(),
+			Arc:   arcGeometry(arc),
+			Inner: float64(r.Inner + off),
+			Outer: float64(r.Outer + off),
+		}
+		col, _ := r.fillOf(f)
+		b.Fill = hexColor(col)
+		if ls, ok := f.(LineStyler); ok {
+			b.Stroke = strokeColor(ls.LineStyle())
+		} else {
+			b.Stroke = strokeColor(r.LineStyle)
+		}
+		g.Blocks = append(g.Blocks, b)
+	}
+	return g, nil
+}
+
+// ScoreDescriber is a ScoreRenderer that can describe the geometry it renders for a Scorer.
+type ScoreDescriber interface {
+	ScoreRenderer
+
+	// DescribeScores returns the geometry of the rendering of scorer across the
+	// specified arc, with the set-wide values inner, outer, min and max. The
+	// min and max parameters may be ignored by an implementation.
+	DescribeScores(arc Arc, scorer Scorer, inner, outer vg.Length, min, max float64) ScoreGeometry
+}
+
+// Describe returns the resolved geometry of the Scores. If the Scores' Renderer is a
+// ScoreDescriber, the description is obtained from the Renderer, otherwise score radii
+// are scaled linearly between Inner and Outer according to the score range returned by
+// ScoreRange, with scores outside that range described as nil.
+func (r *Scores) Describe() (*Geometry, error) {
+	g := &Geometry{Type: "scores"}
+	d, isDescriber := r.Renderer.(ScoreDescriber)
+	ranges := make(map[feat.Feature][2]float64)
+	for _, f := range r.scorers() {
+		loc := f.Location()
+		if f.Start() < loc.Start() || f.End() > loc.End() {
+			continue
+		}
+		rng, ok := ranges[loc]
+		if !ok {
+			rng[0], rng[1] = r.LocationRange(loc)
+			ranges[loc] = rng
+		}
+		min, max := rng[0], rng[1]
+		arc, err := r.arcOf(loc, f)
+		if err != nil {
+			return nil, fmt.Errorf("rings: no arc for feature location: %v", err)
+		}
+		off := offsetOf(r.Base, loc, f)
+		lo, hi := r.scaled(r.Inner+off, r.Outer+off)
+		if isDescriber {
+			g.Scores = append(g.Scores, d.DescribeScores(arc, f, lo, hi, min, max))
+			continue
+		}
+		g.Scores = append(g.Scores, ScoreGeometry{
+			Name:  f.Name(),
+			Arc:   arcGeometry(arc),
+			Radii: scoreRadii(f.Scores(), newRadialScale(min, max, lo, hi, nil)),
+		})
+	}
+	return g, nil
+}
+
+// scoreRadii returns the radii of the values in scores given by the radial scale s.
+// Values excluded by s and NaN values are returned as nil.
+func scoreRadii(scores []float64, s radialScale) []*float64 {
+	radii := make([]*float64, len(scores))
+	for i, v := range scores {
+		r, ok := s.radius(v)
+		if !ok {
+			continue
+		}
+		rad := float64(r)
+		radii[i] = &rad
+	}
+	return radii
+}
+
+// DescribeScores returns the geometry of the Trace rendering of scorer. The min and
+// max parameters are ignored if the Trace's Min and Max fields are not both zero and
+// were not taken from the range of an earlier rendering.
+// Scores within the Trace's Breaks are described as nil.
+func (t *Trace) DescribeScores(arc Arc, scorer Scorer, inner, outer vg.Length, min, max float64) ScoreGeometry {
+	if (t.Max != 0 || t.Min != 0) && !t.autoRange {
+		min, max = t.Min, t.Max
+	}
+	return ScoreGeometry{
+		Name:  scorer.Name(),
+		Arc:   arcGeometry(arc),
+		Radii: scoreRadii(scorer.Scores(), t.scale(inner, outer, min, max)),
+	}
+}
+
+// DescribeScores returns the geometry of the Heat rendering of scorer. The min and
+// max parameters are ignored if the Heat's Min and Max fields are not both zero and
+// were not taken from the range of an earlier rendering.
+func (h *Heat) DescribeScores(arc Arc, scorer Scorer, inner, outer vg.Length, min, max float64) ScoreGeometry {
+	if (h.Max != 0 || h.Min != 0) && !h.autoRange {
+		min, max = h.Min, h.Max
+	}
+	scores := scorer.Scores()
+	g := ScoreGeometry{
+		Name:  scorer.Name(),
+		Arc:   arcGeometry(arc),
+		Radii: make([]*float64, len(scores)),
+	}
+
+	ps := float64(len(h.Palette)-1) / (max - min)
+	d := (outer - inner) / vg.Length(len(scores))
+	rad := inner
+	for i, v := range scores {
+		if c := h.colorOf(v, min, max, ps); c != nil {
+			mid := float64(rad + d/2)
+			g.Radii[i] = &mid
+			g.Cells = append(g.Cells, BlockGeometry{
+				Arc:   arcGeometry(arc),
+				Inner: float64(rad),
+				Outer: float64(rad + d),
+				Fill:  hexColor(c),
+			})
+		}
+		rad += d
+	}
+	return g
+}
+
+// Describe returns the resolved geometry of the Links. Links with no line color or
+// zero line width, and links that are not rendered because an end starts outside its
+// location or is filtered, are not included. An error is returned if the arc of an end
+// cannot be found. Random perturbation of Bézier control points is resolved using the
+// Bezier's Rand field, consuming random values in the same order as DrawAt.
+func (r *Links) Describe() (*Geometry, error) {
+	g := &Geometry{Type: "links"}
+	bez := r.Bezier != nil && r.Bezier.Segments > 1
+	for _, fp := range r.Set {
+		sty := r.styleOf(fp)
+		if sty.Color == nil || sty.Width == 0 {
+			continue
+		}
+		angles, ok, err := r.arcAngles(fp)
+		if err != nil {
+			return nil, fmt.Errorf("rings: no arc for feature location: %v", err)
+		}
+		if !ok {
+			continue
+		}
+		p := fp.Features()
+		radii := r.endRadii(fp)
+		l := LinkGeometry{
+			Names:  [2]string{p[0].Name(), p[1].Name()},
+			Angles: [2]float64{float64(angles[0]), float64(angles[1])},
+			Radii:  [2]float64{float64(radii[0]), float64(radii[1])},
+		}
+		var cp []vg.Point
+		if bez {
+			cp = r.Bezier.ControlPoints(angles, radii)
+		} else {
+			cp = []vg.Point{Rectangular(angles[0], radii[0]), Rectangular(angles[1], radii[1])}
+		}
+		for _, c := range cp {
+			l.Control = append(l.Control, pointGeometry(c))
+		}
+		l.Stroke = strokeColor(sty)
+		g.Links = append(g.Links, l)
+	}
+	return g, nil
+}
+
+// Describe returns the resolved geometry of the Labels. Labels that would not be
+// rendered, including labels removed by priority pruning, are not included.
+func (r *Labels) Describe() (*Geometry, error) {
+	g := &Geometry{Type: "labels"}
+	var accepted map[int]bool
+	if r.prunes() {
+		lay, _ := r.layout()
+		accepted = make(map[int]bool, len(lay))
+		for _, p := range lay {
+			accepted[p.index] = true
+		}
+	}
+	for i, l := range r.Labels {
+		if accepted != nil && !accepted[i] {
+			continue
+		}
+		if r.SkipFiltered && isFiltered(r.Base, labelled(l)) {
+			continue
+		}
+		var sty draw.TextStyle
+		if ts, ok := l.(TextStyler); ok {
+			sty = ts.TextStyle()
+		} else {
+			sty = r.TextStyle
+		}
+		if sty.Color == nil || sty.Font.Size == 0 {
+			continue
+		}
+		angle, err := r.angleOf(l)
+		if err != nil {
+			return nil, fmt.Errorf("rings: no arc for feature location: %v", err)
+		}
+		rot, xalign, yalign := r.placement(l, angle)
+		g.Labels = append(g.Labels, LabelGeometry{
+			Text:     r.text(l),
+			Angle:    float64(angle),
+			Anchor:   pointGeometry(Rectangular(angle, r.Radius+r.offsetOf(l))),
+			Rotation: float64(rot),
+			XAlign:   xalign,
+			YAlign:   yalign,
+			Color:    hexColor(sty.Color),
+		})
+	}
+	return g, nil
+}
@@ -0,0 +1,418 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Diff is a track rendering the difference between two aligned score series, for
+// example the scores of two samples, as a ribbon between their radial profiles. The
+// profile of each series has a radius that varies linearly with angle between the
+// first scores of its intervals at the angular midpoints of the intervals' arcs, as
+// for a Trace with LinearInterpolation, and is held at the first and last scores of
+// a run of adjacent intervals to the ends of the run. Profiles are broken between
+// intervals that are not adjacent and at intervals where either series has no score
+// or a NaN score. The region between the profiles is filled with AFill where A
+// exceeds B and with BFill where B exceeds A, and is split at the interpolated
+// crossings of the profiles so the change of fill color is exact.
+type Diff struct {
+	// A and B hold the compared score series. The intervals of each
+	// location must have the same start and end coordinates in A and
+	// in B, although they may be held in a different order. The first
+	// score of each interval is rendered.
+	A, B ScoreSource
+
+	// Base defines the targets of the rendered scores.
+	Base ArcOfer
+
+	// AFill and BFill are the fill colors of the regions where the score
+	// of A exceeds that of B and where the score of B exceeds that of A.
+	// If a fill color is nil the corresponding regions are not filled.
+	AFill, BFill color.Color
+
+	// AStyle and BStyle, if their Color and Width are set, are the line
+	// styles of the profiles of A and B. The profiles are rendered over
+	// the fills.
+	AStyle, BStyle draw.LineStyle
+
+	// Min and Max hold the score range shared by A and B. If Min and Max
+	// are both zero, the range is determined from the finite scores of A
+	// and B when the Diff is rendered. Scores outside the range are
+	// clamped to the range.
+	Min, Max float64
+
+	// Inner and Outer define the inner and outer radii of the track.
+	Inner, Outer vg.Length
+
+	// Layer specifies the drawing layer of the Diff when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewDiff returns a Diff rendering the difference between the score series a and b
+// between the inner and outer radii, with the score range of the series. An error is
+// returned if either series is nil, the radii are not valid, or the bins of a and b
+// differ.
+func NewDiff(a, b ScoreSource, base ArcOfer, inner, outer vg.Length) (*Diff, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("rings: nil score source")
+	}
+	if inner > outer {
+		return nil, errors.New("rings: inner radius greater than outer radius")
+	}
+	for _, src := range []ScoreSource{a, b} {
+		if t, ok := src.(*ScoreTrack); ok {
+			if err := t.check(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r := &Diff{A: a, B: b, Base: base, Inner: inner, Outer: outer}
+	bins, err := r.bins()
+	if err != nil {
+		return nil, err
+	}
+	r.Min, r.Max = diffRange(bins)
+	return r, nil
+}
+
+// diffBin is an interval shared by the A and B series of a Diff.
+type diffBin struct {
+	Arc
+
+	// f is the view of the interval in A.
+	f Scorer
+
+	// a and b are the first scores of the interval in
+	// A and B, or NaN if the interval has no score.
+	a, b float64
+}
+
+// bins returns the intervals shared by the A and B series of the Diff, or an error
+// naming the first location, in order of appearance in A and then B, whose intervals
+// differ between the series.
+func (r *Diff) bins() ([]diffBin, error) {
+	locsA, binsA := locationBins(r.A)
+	locsB, binsB := locationBins(r.B)
+	for _, loc := range locsB {
+		if _, ok := binsA[loc]; !ok {
+			locsA = append(locsA, loc)
+		}
+	}
+	var bins []diffBin
+	for _, loc := range locsA {
+		a, b := binsA[loc], binsB[loc]
+		if len(a) != len(b) {
+			return nil, diffMismatch(loc)
+		}
+		for i := range a {
+			if a[i].Start() != b[i].Start() || a[i].End() != b[i].End() {
+				return nil, diffMismatch(loc)
+			}
+			bins = append(bins, diffBin{f: a[i], a: firstScore(a[i]), b: firstScore(b[i])})
+		}
+	}
+	return bins, nil
+}
+
+// diffMismatch returns the error reporting that the bins of loc differ between the
+// series of a Diff.
+func diffMismatch(loc feat.Feature) error {
+	var name string
+	if loc != nil {
+		name = loc.Name()
+	}
+	return fmt.Errorf("rings: diff bins of location %q differ between series", name)
+}
+
+// locationBins returns views of the intervals of src grouped by location and sorted
+// by start and end, and the locations in order of first appearance.
+func locationBins(src ScoreSource) ([]feat.Feature, map[feat.Feature][]Scorer) {
+	n := src.Len()
+	views := make([]sourceScorer, n)
+	var locs []feat.Feature
+	bins := make(map[feat.Feature][]Scorer)
+	for i := range views {
+		views[i] = sourceScorer{src: src, i: i}
+		loc := views[i].Location()
+		if _, ok := bins[loc]; !ok {
+			locs = append(locs, loc)
+		}
+		bins[loc] = append(bins[loc], &views[i])
+	}
+	for _, fs := range bins {
+		sort.Sort(byCoordinates(fs))
+	}
+	return locs, bins
+}
+
+// byCoordinates sorts Scorers by start and then end.
+type byCoordinates []Scorer
+
+func (s byCoordinates) Len() int { return len(s) }
+func (s byCoordinates) Less(i, j int) bool {
+	if s[i].Start() != s[j].Start() {
+		return s[i].Start() < s[j].Start()
+	}
+	return s[i].End() < s[j].End()
+}
+func (s byCoordinates) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// firstScore returns the first score of f, or NaN if f has no scores.
+func firstScore(f Scorer) float64 {
+	v, ok := segmentValue(f)
+	if !ok {
+		return math.NaN()
+	}
+	return v
+}
+
+// diffRange returns the range of the finite scores of bins, or [0, 0] if there are none.
+func diffRange(bins []diffBin) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, bin := range bins {
+		for _, v := range []float64{bin.a, bin.b} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+	if min > max {
+		return 0, 0
+	}
+	return min, max
+}
+
+// ScoreRange returns the score range used to render the Diff. If Min and Max are both
+// zero, the range is determined from the scores of A and B.
+func (r *Diff) ScoreRange() (min, max float64) {
+	if r.Min != 0 || r.Max != 0 {
+		return r.Min, r.Max
+	}
+	bins, err := r.bins()
+	if err != nil {
+		return 0, 0
+	}
+	return diffRange(bins)
+}
+
+// DrawAt renders the Diff at cen in the specified drawing area, according to the Diff
+// configuration. DrawAt panics if the bins of A and B differ.
+func (r *Diff) DrawAt(ca draw.Canvas, cen vg.Point) {
+	bins, err := r.bins()
+	if err != nil {
+		panic(err)
+	}
+	min, max := r.Min, r.Max
+	if min == 0 && max == 0 {
+		min, max = diffRange(bins)
+	}
+	if !(min < max) {
+		return
+	}
+	scale := newRadialScale(min, max, r.Inner, r.Outer, nil)
+
+	drawn := bins[:0]
+	for _, bin := range bins {
+		loc := bin.f.Location()
+		if loc != nil && (bin.f.Start() < loc.Start() || bin.f.End() > loc.End()) {
+			continue
+		}
+		arc, err := r.Base.ArcOf(loc, bin.f)
+		if err != nil {
+			panic(fmt.Sprint("rings: no arc for feature location:", err))
+		}
+		if arc.Phi < 0 {
+			arc.Theta, arc.Phi = arc.Theta+arc.Phi, -arc.Phi
+		}
+		bin.Arc = arc
+		drawn = append(drawn, bin)
+	}
+	sort.Stable(byTheta(drawn))
+
+	var run []diffBin
+	for i, bin := range drawn {
+		if math.IsNaN(bin.a) || math.IsNaN(bin.b) {
+			r.drawRun(ca, cen, run, scale)
+			run = run[:0]
+			continue
+		}
+		if len(run) != 0 && !adjacent(drawn[i-1].f, bin.f) {
+			r.drawRun(ca, cen, run, scale)
+			run = run[:0]
+		}
+		run = append(run, bin)
+	}
+	r.drawRun(ca, cen, run, scale)
+}
+
+// byTheta sorts diffBins by the start angle of their arcs.
+type byTheta []diffBin
+
+func (b byTheta) Len() int           { return len(b) }
+func (b byTheta) Less(i, j int) bool { return b[i].Theta < b[j].Theta }
+func (b byTheta) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// diffKnot is a point on the profiles of a Diff in polar coordinates, holding the
+// radii of the A and B profiles at the angle theta.
+type diffKnot struct {
+	theta Angle
+	a, b  vg.Length
+}
+
+// sign returns the sign of the difference between the profiles across the part of
+// the profiles between k and o, which must not cross.
+func (k diffKnot) sign(o diffKnot) int {
+	d := (k.a - k.b) + (o.a - o.b)
+	switch {
+	case d > 0:
+		return 1
+	case d < 0:
+		return -1
+	}
+	return 0
+}
+
+// drawRun renders the fills and profiles of a run of adjacent bins.
+func (r *Diff) drawRun(ca draw.Canvas, cen vg.Point, run []diffBin, scale radialScale) {
+	if len(run) == 0 {
+		return
+	}
+	pts := diffPoints(run, scale)
+
+	var pa vg.Path
+	for start, i := 0, 1; i < len(pts); i++ {
+		s := pts[i-1].sign(pts[i])
+		if i < len(pts)-1 && pts[i].sign(pts[i+1]) == s {
+			continue
+		}
+		fill := r.AFill
+		if s < 0 {
+			fill = r.BFill
+		}
+		if s != 0 && fill != nil {
+			piece := pts[start : i+1]
+			pa = pa[:0]
+			pa.Move(RectangularAt(cen, piece[0].theta, piece[0].a))
+			for _, p := range piece[1:] {
+				pa.Line(RectangularAt(cen, p.theta, p.a))
+			}
+			for j := len(piece) - 1; j >= 0; j-- {
+				pa.Line(RectangularAt(cen, piece[j].theta, piece[j].b))
+			}
+			pa.Close()
+			ca.SetColor(fill)
+			ca.Fill(pa)
+		}
+		start = i
+	}
+
+	for _, p := range []struct {
+		sty    draw.LineStyle
+		radius func(diffKnot) vg.Length
+	}{
+		{sty: r.AStyle, radius: func(k diffKnot) vg.Length { return k.a }},
+		{sty: r.BStyle, radius: func(k diffKnot) vg.Length { return k.b }},
+	} {
+		if p.sty.Color == nil || p.sty.Width == 0 {
+			continue
+		}
+		pa = pa[:0]
+		pa.Move(RectangularAt(cen, pts[0].theta, p.radius(pts[0])))
+		for _, k := range pts[1:] {
+			pa.Line(RectangularAt(cen, k.theta, p.radius(k)))
+		}
+		ca.SetLineStyle(p.sty)
+		ca.Stroke(pa)
+	}
+}
+
+// diffPoints returns the points of the profiles of a run of adjacent bins, spaced by
+// at most curveStep. The points include the crossings of the profiles, so the profiles
+// do not cross between consecutive points.
+func diffPoints(run []diffBin, scale radialScale) []diffKnot {
+	// The knots are the start of the run, the midpoint of each arc
+	// and the end of the run.
+	knots := make([]diffKnot, 0, len(run)+2)
+	for i, bin := range run {
+		a, _ := scale.radius(bin.a)
+		b, _ := scale.radius(bin.b)
+		if i == 0 {
+			knots = append(knots, diffKnot{theta: bin.Theta, a: a, b: b})
+		}
+		knots = append(knots, diffKnot{theta: bin.Theta + bin.Phi/2, a: a, b: b})
+		if i == len(run)-1 {
+			knots = append(knots, diffKnot{theta: bin.Theta + bin.Phi, a: a, b: b})
+		}
+	}
+
+	pts := []diffKnot{knots[0]}
+	for i, to := range knots[1:] {
+		from := knots[i]
+		ends := []diffKnot{to}
+		if d0, d1 := from.a-from.b, to.a-to.b; d0*d1 < 0 {
+			// The profiles vary linearly with angle between the knots,
+			// so their difference does too.
+			c := lerpKnot(from, to, float64(d0/(d0-d1)))
+			c.b = c.a
+			ends = []diffKnot{c, to}
+		}
+		for _, end := range ends {
+			start := pts[len(pts)-1]
+			n := int(math.Ceil(float64(end.theta-start.theta) / float64(curveStep)))
+			for s := 1; s < n; s++ {
+				pts = append(pts, lerpKnot(start, end, float64(s)/float64(n)))
+			}
+			pts = append(pts, end)
+		}
+	}
+	return pts
+}
+
+// lerpKnot returns the point at the fraction f of the way from a to b.
+func lerpKnot(a, b diffKnot, f float64) diffKnot {
+	return diffKnot{
+		theta: a.theta + (b.theta-a.theta)*Angle(f),
+		a:     a.a + (b.a-a.a)*vg.Length(f),
+		b:     a.b + (b.b-a.b)*vg.Length(f),
+	}
+}
+
+// XY returns the x and y coordinates of the Diff.
+func (r *Diff) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Diff.
+func (r *Diff) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Diff's X and Y values as the drawing coordinates.
+func (r *Diff) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the Diff rendering restricted to the
+// base arc.
+func (r *Diff) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(r.Inner, r.Outer),
+	}}
+}
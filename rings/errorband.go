@@ -0,0 +1,187 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// ErrorBand is a ScoreRenderer that represents a pair of values for each feature as a
+// filled band between the radial profiles of the values, for example the extent of an error
+// around a mean rendered by a Trace. Bands are broken where a Trace with the same Join
+// setting would break its trace: between features that are not adjacent or are not
+// joined, and at features with a NaN value.
+type ErrorBand struct {
+	// Upper and Lower return the values bounding the band for a Scorer.
+	Upper, Lower func(Scorer) float64
+
+	// Fill determines the fill color of the band. If Fill is nil no fill is performed.
+	// The filled region lies between the lesser and greater of the values of each
+	// feature, so the band is filled consistently where the Upper and Lower profiles
+	// cross.
+	Fill color.Color
+
+	// UpperStyle and LowerStyle determine the line styles of the Upper and Lower
+	// profiles.
+	UpperStyle, LowerStyle draw.LineStyle
+
+	// Join specifies whether adjacent features should be joined into a single band.
+	Join bool
+
+	DrawArea draw.Canvas
+
+	Center       vg.Point
+	Inner, Outer vg.Length
+
+	Min, Max float64
+
+	// Breaks specifies intervals of score values that are excluded from the radial
+	// scale of the band. Values within a break are placed at the radius of the break.
+	Breaks []Break
+
+	values arcScores
+
+	// offset is the displacement of each Scorer given
+	// by the configuring context.
+	offset func(Scorer) vg.Length
+}
+
+// Configure is called by Scores' DrawAt method. The score range of c is ignored if
+// the ErrorBand's Min and Max fields are not both zero.
+func (b *ErrorBand) Configure(c ScoreContext) {
+	b.values = b.values[:0]
+	b.DrawArea = c.Canvas
+	b.Center = c.Center
+	b.Inner = c.Inner
+	b.Outer = c.Outer
+	b.offset = c.Offset
+	if b.Max == 0 && b.Min == 0 {
+		b.Min = c.Min
+		b.Max = c.Max
+	}
+}
+
+// DataRange returns the range of the finite Upper and Lower values of scorer.
+func (b *ErrorBand) DataRange(scorer Scorer) (min, max float64, ok bool) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range []float64{b.Upper(scorer), b.Lower(scorer)} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return min, max, min <= max
+}
+
+// CopyRenderer returns an unconfigured copy of the ErrorBand.
+func (b *ErrorBand) CopyRenderer() ScoreRenderer {
+	c := *b
+	c.DrawArea = draw.Canvas{}
+	c.values = nil
+	return &c
+}
+
+// Render adds the scores at the specified arc for lazy rendering.
+func (b *ErrorBand) Render(arc Arc, scorer Scorer) {
+	b.values = append(b.values, arcScore{arc, scorer})
+}
+
+// bandBin is the rendered extent of a single feature of an ErrorBand.
+type bandBin struct {
+	Arc
+	upper, lower float64
+	offset       vg.Length
+}
+
+// Close renders the added scores.
+func (b *ErrorBand) Close() {
+	sort.Sort(b.values)
+
+	var run []bandBin
+	for i, v := range b.values {
+		bin := bandBin{Arc: v.Arc, upper: b.Upper(v.Scorer), lower: b.Lower(v.Scorer), offset: scorerOffset(b.offset, v.Scorer)}
+		if bin.Phi < 0 {
+			bin.Theta, bin.Phi = bin.Theta+bin.Phi, -bin.Phi
+		}
+		if math.IsNaN(bin.upper) || math.IsNaN(bin.lower) {
+			b.renderRun(run)
+			run = run[:0]
+			continue
+		}
+		if len(run) != 0 && !(b.Join && adjacent(b.values[i-1].Scorer, v.Scorer) && run[len(run)-1].offset == bin.offset) {
+			b.renderRun(run)
+			run = run[:0]
+		}
+		run = append(run, bin)
+	}
+	b.renderRun(run)
+}
+
+// renderRun renders the band for a run of joined bins.
+func (b *ErrorBand) renderRun(run []bandBin) {
+	if len(run) == 0 {
+		return
+	}
+
+	var pa vg.Path
+	if b.Fill != nil {
+		// The outer boundary follows the greater value of each bin and the inner
+		// boundary the lesser, so the boundaries never cross.
+		b.profile(&pa, run, func(bin bandBin) float64 { return math.Max(bin.upper, bin.lower) }, false)
+		b.profile(&pa, run, func(bin bandBin) float64 { return math.Min(bin.upper, bin.lower) }, true)
+		pa.Close()
+		b.DrawArea.SetColor(b.Fill)
+		b.DrawArea.Fill(pa)
+	}
+	for _, p := range []struct {
+		sty   draw.LineStyle
+		value func(bandBin) float64
+	}{
+		{sty: b.UpperStyle, value: func(bin bandBin) float64 { return bin.upper }},
+		{sty: b.LowerStyle, value: func(bin bandBin) float64 { return bin.lower }},
+	} {
+		if p.sty.Color == nil || p.sty.Width == 0 {
+			continue
+		}
+		pa = pa[:0]
+		b.profile(&pa, run, p.value, false)
+		b.DrawArea.SetLineStyle(p.sty)
+		b.DrawArea.Stroke(pa)
+	}
+}
+
+// profile appends the radial profile of the values of the bins of run to pa. If the
+// profile is reversed it is traced from the end of the last bin and joined to the end
+// of pa, otherwise it starts a new sub-path.
+func (b *ErrorBand) profile(pa *vg.Path, run []bandBin, value func(bandBin) float64, reverse bool) {
+	for i := range run {
+		bin := run[i]
+		theta, phi := bin.Theta, bin.Phi
+		if reverse {
+			bin = run[len(run)-1-i]
+			theta, phi = bin.Theta+bin.Phi, -bin.Phi
+		}
+		rad := b.radius(value(bin), bin.offset)
+		if i == 0 && !reverse {
+			pa.Move(RectangularAt(b.Center, theta, rad))
+		} else {
+			pa.Line(RectangularAt(b.Center, theta, rad))
+		}
+		pa.Arc(b.Center, rad, float64(theta), float64(phi))
+	}
+}
+
+// radius returns the radius of v, clamped to the range of the ErrorBand and skipping
+// its Breaks, displaced by off.
+func (b *ErrorBand) radius(v float64, off vg.Length) vg.Length {
+	rad, _ := newRadialScale(b.Min, b.Max, b.Inner+off, b.Outer+off, b.Breaks).radius(v)
+	return rad
+}
@@ -57,24 +57,26 @@ func main() {
 
 	g := byte(0)
 	for i := vg.Length(0); i < 3; i++ {
-		bs, err := rings.NewGappedBlocks(randomFeatures(rand.Intn(10), 1000, 1000000, false, sty), rings.Arc{0, rings.Complete * rings.Clockwise}, 50+i*8, 55+i*8, 0.005)
+		bs, err := rings.NewGappedBlocks(randomFeatures(rand.Intn(10), 1000, 1000000, false, sty), rings.Arc{0, rings.Complete * rings.Clockwise}, 50+i*8, 55+i*8, 0.005,
+			rings.BlockFill(color.RGBA{R: 196, G: g, B: 128, A: 255}),
+		)
 		if err != nil {
 			panic(err)
 		}
-		bs.Color = color.RGBA{R: 196, G: g, B: 128, A: 255}
 		g += 60
 		p.Add(bs)
 	}
 
-	bs, err := rings.NewGappedBlocks(randomFeatures(3, 100000, 1000000, false, sty), rings.Arc{0, rings.Complete * rings.Clockwise}, 80, 100, 0.01)
+	bs, err := rings.NewGappedBlocks(randomFeatures(3, 100000, 1000000, false, sty), rings.Arc{0, rings.Complete * rings.Clockwise}, 80, 100, 0.01,
+		rings.BlockLineStyle(sty),
+		rings.BlockFill(color.RGBA{R: 196, G: g + 24, B: 128, A: 255}),
+	)
 	if err != nil {
 		panic(err)
 	}
 	bs.Set[0].(*fs).orient = feat.Forward
 	bs.Set[1].(*fs).orient = feat.Forward
 	bs.Set[2].(*fs).orient = feat.Forward
-	bs.LineStyle = sty
-	bs.Color = color.RGBA{R: 196, G: g + 24, B: 128, A: 255}
 	g += 60
 	p.Add(bs)
 
@@ -82,11 +84,12 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	lb, err := rings.NewLabels(bs, 110, rings.NameLabels(bs.Set)...)
+	lb, err := rings.NewLabelsWith(bs, 110, rings.NameLabels(bs.Set),
+		rings.LabelTextStyle(draw.TextStyle{Color: color.Gray16{0}, Font: font}),
+	)
 	if err != nil {
 		panic(err)
 	}
-	lb.TextStyle = draw.TextStyle{Color: color.Gray16{0}, Font: font}
 	p.Add(lb)
 
 	m := randomFeatures(400, bs.Set[1].Start(), bs.Set[1].End(), true, sty)
@@ -165,15 +168,16 @@ func main() {
 	for i := range mp {
 		mp[i] = fp{feats: [2]*fs{m[i].(*fs), m[len(m)/2+i].(*fs)}, sty: sty}
 	}
-	ls, err := rings.NewLinks(mp, [2]rings.ArcOfer{bs, bs}, [2]vg.Length{47, 47})
+	ls, err := rings.NewLinks(mp, [2]rings.ArcOfer{bs, bs}, [2]vg.Length{47, 47},
+		rings.LinkBezier(&rings.Bezier{Segments: 20,
+			Radius: rings.LengthDist{Length: 2 * 47 / 3, Min: floatPtr(0.95), Max: floatPtr(1.05)},
+			Crest:  &rings.FactorDist{Factor: 2, Min: floatPtr(0.7), Max: floatPtr(1.4)},
+		}),
+		rings.LinkLineStyle(sty),
+	)
 	if err != nil {
 		panic(err)
 	}
-	ls.Bezier = &rings.Bezier{Segments: 20,
-		Radius: rings.LengthDist{Length: 2 * 47 / 3, Min: floatPtr(0.95), Max: floatPtr(1.05)},
-		Crest:  &rings.FactorDist{Factor: 2, Min: floatPtr(0.7), Max: floatPtr(1.4)},
-	}
-	ls.LineStyle = sty
 	p.Add(ls)
 
 	p.Add(plotter.NewGlyphBoxes())
@@ -0,0 +1,118 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Facet renders a grid of ring plots, one for each of a collection of samples, with
+// each plot titled with the name of its sample.
+//
+// Plotters of different samples may share a Range so that the samples are rendered
+// on a common radial scale. All the facets' plotters are asked for their glyph boxes
+// before any facet is drawn, so a shared Range holds the scores of every facet when
+// the facets are drawn.
+type Facet struct {
+	// Samples holds the names of the samples to render. Samples are placed in
+	// the grid in row-major order.
+	Samples []string
+
+	// Plotters returns the plotters that render the named sample.
+	Plotters func(sample string) ([]plot.Plotter, error)
+
+	// Rows and Cols specify the dimensions of the grid. If both are zero, the grid
+	// is made as close to square as possible, and if one is zero, it is chosen to
+	// hold all the samples.
+	Rows, Cols int
+
+	// Padding is the space around each facet.
+	Padding vg.Length
+
+	// TitleStyle determines the style of the sample titles drawn at the top of
+	// each facet. Titles are not drawn if TitleStyle has a nil Color or a Font
+	// with zero size.
+	TitleStyle draw.TextStyle
+}
+
+// grid returns the number of rows and columns of the Facet's grid.
+func (f *Facet) grid() (rows, cols int, err error) {
+	rows, cols = f.Rows, f.Cols
+	n := len(f.Samples)
+	switch {
+	case rows < 0 || cols < 0:
+		return 0, 0, fmt.Errorf("rings: negative facet grid dimension %dx%d", rows, cols)
+	case rows == 0 && cols == 0:
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+		if cols == 0 {
+			return 0, 0, nil
+		}
+		rows = (n + cols - 1) / cols
+	case rows == 0:
+		rows = (n + cols - 1) / cols
+	case cols == 0:
+		cols = (n + rows - 1) / rows
+	}
+	if rows*cols < n {
+		return 0, 0, fmt.Errorf("rings: %d samples do not fit in a %dx%d facet grid", n, rows, cols)
+	}
+	return rows, cols, nil
+}
+
+// Draw renders the facets in the drawing area c. An error is returned if the grid
+// cannot hold the samples or the plotters of a sample cannot be obtained.
+func (f *Facet) Draw(c draw.Canvas) error {
+	rows, cols, err := f.grid()
+	if err != nil || rows == 0 {
+		return err
+	}
+	if f.Plotters == nil {
+		return fmt.Errorf("rings: nil facet plotters function")
+	}
+
+	plots := make([]*plot.Plot, len(f.Samples))
+	for i, s := range f.Samples {
+		ps, err := f.Plotters(s)
+		if err != nil {
+			return err
+		}
+		p, err := plot.New()
+		if err != nil {
+			return err
+		}
+		p.Add(ps...)
+		p.HideAxes()
+		plots[i] = p
+
+		// Collect shared state, such as shared ranges, from
+		// the plotters before any facet is drawn.
+		for _, pl := range ps {
+			if gb, ok := pl.(plot.GlyphBoxer); ok {
+				gb.GlyphBoxes(p)
+			}
+		}
+	}
+
+	tiles := draw.Tiles{
+		Rows: rows, Cols: cols,
+		PadTop: f.Padding, PadBottom: f.Padding, PadLeft: f.Padding, PadRight: f.Padding,
+		PadX: f.Padding, PadY: f.Padding,
+	}
+	title := f.TitleStyle.Color != nil && f.TitleStyle.Font.Size != 0
+	for i, p := range plots {
+		cell := tiles.At(c, i%cols, i/cols)
+		if title {
+			cell.FillText(f.TitleStyle, vg.Point{X: cell.Center().X, Y: cell.Max.Y}, -0.5, -1, f.Samples[i])
+			cell.Max.Y -= lineHeight(f.TitleStyle)
+		}
+		p.Draw(cell)
+	}
+	return nil
+}
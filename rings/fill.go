@@ -0,0 +1,246 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+)
+
+// FillRule specifies how the interior of an outline that overlaps itself is determined.
+type FillRule int
+
+const (
+	// DefaultRule leaves the fill rule to the canvas. Canvases differ in their
+	// rules: vgimg uses the even-odd rule while vgpdf and vgsvg use the non-zero
+	// rule, so self-overlapping outlines may render differently between formats.
+	DefaultRule FillRule = iota
+	// NonZeroRule fills the regions enclosed by an outline a non-zero net number
+	// of times.
+	NonZeroRule
+	// EvenOddRule fills the regions enclosed by an outline an odd number of times.
+	EvenOddRule
+)
+
+// outline returns a path filling the interior of pa according to the rule. The
+// returned path does not overlap itself and so fills identically under the rules
+// of all canvases. Arcs in pa are approximated by line segments. If the rule is
+// DefaultRule, pa is returned unaltered.
+func (rule FillRule) outline(pa vg.Path) vg.Path {
+	if rule == DefaultRule {
+		return pa
+	}
+	polys := flatten(pa)
+	edges := polyEdges(polys)
+	splitEdges(edges)
+	return boundary(edges, func(pt vg.Point) bool { return rule.inside(winding(polys, pt)) })
+}
+
+// polyEdges returns the edges of the closed polygons in polys.
+func polyEdges(polys [][]vg.Point) []edge {
+	var edges []edge
+	for _, r := range polys {
+		for i, a := range r {
+			b := r[(i+1)%len(r)]
+			if a != b {
+				edges = append(edges, edge{a: a, b: b})
+			}
+		}
+	}
+	return edges
+}
+
+// splitEdges splits the edges where they cross so that the edges only meet at their
+// ends. Each crossing point is calculated once so that split edges share their ends
+// exactly.
+func splitEdges(edges []edge) {
+	for i := range edges {
+		for j := i + 1; j < len(edges); j++ {
+			t, u, p, ok := edges[i].crossing(edges[j])
+			if !ok {
+				continue
+			}
+			if 0 < t && t < 1 {
+				edges[i].splits = append(edges[i].splits, split{t: t, p: p})
+			}
+			if 0 < u && u < 1 {
+				edges[j].splits = append(edges[j].splits, split{t: u, p: p})
+			}
+		}
+	}
+}
+
+// boundary returns a path outlining the region of points for which in returns true,
+// which must be bounded by the split edges. The parts of the edges that separate the
+// region from its exterior are kept, directed with the region to their left, and
+// joined into closed loops.
+func boundary(edges []edge, in func(vg.Point) bool) vg.Path {
+	var (
+		kept []edge
+		seen = make(map[[2]vg.Point]bool)
+	)
+	for _, e := range edges {
+		sort.Sort(splits(e.splits))
+		from := e.a
+		for _, to := range append(e.points(), e.b) {
+			if from == to {
+				continue
+			}
+			a, b := from, to
+			from = to
+
+			left, right := sides(a, b)
+			inLeft, inRight := in(left), in(right)
+			switch {
+			case inLeft == inRight:
+				continue
+			case inRight:
+				a, b = b, a
+			}
+			if seen[[2]vg.Point{a, b}] {
+				// Drop coincident duplicate edges.
+				continue
+			}
+			seen[[2]vg.Point{a, b}] = true
+			kept = append(kept, edge{a: a, b: b})
+		}
+	}
+
+	// Join the kept edges into closed loops. The winding number of a point is
+	// the sum of the contributions of the edges, so the order in which edges
+	// meeting at a point are joined does not alter the filled region.
+	from := make(map[vg.Point][]int)
+	for i, e := range kept {
+		from[e.a] = append(from[e.a], i)
+	}
+	used := make([]bool, len(kept))
+	var out vg.Path
+	for i := range kept {
+		if used[i] {
+			continue
+		}
+		start := kept[i].a
+		out.Move(start)
+		for j := i; ; {
+			used[j] = true
+			p := kept[j].b
+			if p == start {
+				break
+			}
+			out.Line(p)
+			next := -1
+			for _, k := range from[p] {
+				if !used[k] {
+					next = k
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			j = next
+		}
+		out.Close()
+	}
+	return out
+}
+
+// inside returns whether a point with the given winding number is within
+// a filled region according to the rule.
+func (rule FillRule) inside(w int) bool {
+	if rule == EvenOddRule {
+		return w%2 != 0
+	}
+	return w != 0
+}
+
+// edge is a directed line segment of an outline.
+type edge struct {
+	a, b   vg.Point
+	splits []split
+}
+
+// split is a point at which an edge is split and its fractional position along
+// the edge.
+type split struct {
+	t float64
+	p vg.Point
+}
+
+type splits []split
+
+func (s splits) Len() int           { return len(s) }
+func (s splits) Less(i, j int) bool { return s[i].t < s[j].t }
+func (s splits) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// points returns the split points of e in order along e.
+func (e edge) points() []vg.Point {
+	pts := make([]vg.Point, len(e.splits))
+	for i, s := range e.splits {
+		pts[i] = s.p
+	}
+	return pts
+}
+
+// crossing returns the fractional positions along e and f of the point at which
+// they cross, and the point. Parallel edges do not cross.
+func (e edge) crossing(f edge) (t, u float64, p vg.Point, ok bool) {
+	d1x, d1y := float64(e.b.X-e.a.X), float64(e.b.Y-e.a.Y)
+	d2x, d2y := float64(f.b.X-f.a.X), float64(f.b.Y-f.a.Y)
+	den := d1x*d2y - d1y*d2x
+	if den == 0 {
+		return 0, 0, vg.Point{}, false
+	}
+	ox, oy := float64(f.a.X-e.a.X), float64(f.a.Y-e.a.Y)
+	t = (ox*d2y - oy*d2x) / den
+	u = (ox*d1y - oy*d1x) / den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, 0, vg.Point{}, false
+	}
+	switch {
+	case t == 0:
+		p = e.a
+	case t == 1:
+		p = e.b
+	case u == 0:
+		p = f.a
+	case u == 1:
+		p = f.b
+	default:
+		p = vg.Point{X: e.a.X + vg.Length(t*d1x), Y: e.a.Y + vg.Length(t*d1y)}
+	}
+	return t, u, p, true
+}
+
+// sides returns points just to the left and right of the midpoint of the segment
+// from a to b.
+func sides(a, b vg.Point) (left, right vg.Point) {
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	l := math.Hypot(dx, dy)
+	eps := math.Min(l/4, 1e-3)
+	nx, ny := vg.Length(-dy/l*eps), vg.Length(dx/l*eps)
+	mid := vg.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+	return vg.Point{X: mid.X + nx, Y: mid.Y + ny}, vg.Point{X: mid.X - nx, Y: mid.Y - ny}
+}
+
+// winding returns the winding number of the closed polygons in polys about pt.
+func winding(polys [][]vg.Point, pt vg.Point) int {
+	var w int
+	for _, r := range polys {
+		for i, a := range r {
+			b := r[(i+1)%len(r)]
+			side := (b.X-a.X)*(pt.Y-a.Y) - (pt.X-a.X)*(b.Y-a.Y)
+			switch {
+			case a.Y <= pt.Y && pt.Y < b.Y && side > 0:
+				w++
+			case b.Y <= pt.Y && pt.Y < a.Y && side < 0:
+				w--
+			}
+		}
+	}
+	return w
+}
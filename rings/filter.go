@@ -0,0 +1,114 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// FilteredError is the error returned by the ArcOf method of a Blocks for a feature
+// held by a location removed from the Blocks by its keep filter.
+type FilteredError struct {
+	Location feat.Feature
+}
+
+func (e *FilteredError) Error() string {
+	return fmt.Sprintf("rings: location %q filtered", e.Location.Name())
+}
+
+// Filtered returns whether f is, or is held by, a location removed from the Blocks by
+// its keep filter.
+func (r *Blocks) Filtered(f feat.Feature) bool { return r.filteredLocation(f) != nil }
+
+// filteredLocation returns f or the ancestor location of f that was removed from the
+// Blocks by its keep filter, or nil if there is no such location.
+func (r *Blocks) filteredLocation(f feat.Feature) feat.Feature {
+	if len(r.filtered) == 0 {
+		return nil
+	}
+	for ; f != nil; f = f.Location() {
+		if r.filtered[f] {
+			return f
+		}
+	}
+	return nil
+}
+
+// filter removes the features of the Set that are rejected by keep. If alloc is not
+// nil, the Base is replaced by the arcs allocated by alloc to the remaining features
+// and the Other stub, if one is requested. Otherwise the arcs of the removed features
+// are left empty. An error is returned if a stub is requested and alloc is nil.
+func (r *Blocks) filter(alloc func([]feat.Feature) ArcOfer) error {
+	r.filtered = make(map[feat.Feature]bool)
+	var kept []feat.Feature
+	for _, f := range r.Set {
+		if r.keep(f) {
+			kept = append(kept, f)
+		} else {
+			r.filtered[f] = true
+		}
+	}
+	if r.otherLength > 0 && len(r.filtered) != 0 {
+		if alloc == nil {
+			return errors.New("rings: other stub requires allocated arcs")
+		}
+		r.Other = &otherLocation{length: r.otherLength}
+		kept = append(kept, r.Other)
+	}
+	r.Set = kept
+	if alloc != nil {
+		r.Base = alloc(kept)
+	}
+	return nil
+}
+
+// filteringBlocks returns the Blocks with a keep filter that maps the arcs of base,
+// either as base itself or as the ArcOfer base maps arcs through, or nil if there is
+// no such Blocks.
+func filteringBlocks(base ArcOfer) *Blocks {
+	switch b := base.(type) {
+	case *Blocks:
+		if b.keep != nil {
+			return b
+		}
+		return filteringBlocks(b.Base)
+	case Lens:
+		return filteringBlocks(b.ArcOfer)
+	case *NestedArcs:
+		return filteringBlocks(b.Parent)
+	case *Spokes:
+		return filteringBlocks(b.Base)
+	case *Tiles:
+		return filteringBlocks(b.Base)
+	default:
+		return nil
+	}
+}
+
+// filtering returns whether base maps arcs through a Blocks with a keep filter.
+func filtering(base ArcOfer) bool { return filteringBlocks(base) != nil }
+
+// isFiltered returns whether f is held by a location removed by the keep filter
+// of the Blocks that base maps arcs through.
+func isFiltered(base ArcOfer, f feat.Feature) bool {
+	b := filteringBlocks(base)
+	return b != nil && b.Filtered(f)
+}
+
+// otherLocation is the stub location standing in for the locations removed from a
+// Blocks by its keep filter.
+type otherLocation struct {
+	length int
+}
+
+func (l *otherLocation) Start() int             { return 0 }
+func (l *otherLocation) End() int               { return l.length }
+func (l *otherLocation) Len() int               { return l.length }
+func (l *otherLocation) Name() string           { return "other" }
+func (l *otherLocation) Description() string    { return "filtered locations" }
+func (l *otherLocation) Location() feat.Feature { return nil }
@@ -0,0 +1,97 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CommaFormat returns v formatted with its integer digits grouped in threes separated
+// by commas, for example "12,345,678". The fractional part of v, if any, is retained.
+// CommaFormat is suitable for use as a TickConfig Format.
+func CommaFormat(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	var sign string
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	var frac string
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i:]
+	}
+	if len(s) <= 3 {
+		return sign + s + frac
+	}
+	b := make([]byte, 0, len(s)+len(s)/3)
+	for i := range s {
+		if i != 0 && (len(s)-i)%3 == 0 {
+			b = append(b, ',')
+		}
+		b = append(b, s[i])
+	}
+	return sign + string(b) + frac
+}
+
+// siPrefixes holds the SI prefixes for the powers of 1000 from 1000⁻⁴ to 1000⁴,
+// with the empty prefix at index siUnit.
+var siPrefixes = [...]string{"p", "n", "µ", "m", "", "k", "M", "G", "T"}
+
+const siUnit = len(siPrefixes) / 2
+
+// SIFormat returns a function that formats values with an SI prefix and the given unit,
+// for example "12.3 kHz" for 12345 with the unit "Hz". Values are given to at most three
+// significant figures. The returned function is suitable for use as a TickConfig Format.
+func SIFormat(unit string) func(v float64) string {
+	return func(v float64) string { return siFormat(v, unit, -4) }
+}
+
+// GenomicFormat returns v formatted as a sequence length in bases with the units b, kb,
+// Mb or Gb to at most three significant figures, for example "12.3 Mb" for 12345678.
+// GenomicFormat is suitable for use as a TickConfig Format.
+func GenomicFormat(v float64) string { return siFormat(v, "b", 0) }
+
+// siFormat returns v formatted to at most three significant figures with the SI prefix
+// of the power of 1000 that places its magnitude in [1, 1000) and the given unit. The
+// power is not less than 1000^min.
+func siFormat(v float64, unit string, min int) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return withUnit(strconv.FormatFloat(v, 'f', -1, 64), unit)
+	}
+	const max = len(siPrefixes) - 1 - siUnit
+	exp := int(math.Floor(math.Log10(math.Abs(v)) / 3))
+	if exp < min {
+		exp = min
+	}
+	if exp > max {
+		exp = max
+	}
+	x := round3(v / math.Pow(1000, float64(exp)))
+	// Rounding to three significant figures may carry into the next power.
+	if math.Abs(x) >= 1000 && exp < max {
+		exp++
+		x = round3(v / math.Pow(1000, float64(exp)))
+	}
+	return withUnit(strconv.FormatFloat(x, 'f', -1, 64), siPrefixes[siUnit+exp]+unit)
+}
+
+// round3 returns x rounded to three significant figures.
+func round3(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	p := math.Pow(10, 2-math.Floor(math.Log10(math.Abs(x))))
+	return math.Floor(x*p+0.5) / p
+}
+
+// withUnit returns the number and unit separated by a space, or the number alone if
+// unit is empty.
+func withUnit(num, unit string) string {
+	if unit == "" {
+		return num
+	}
+	return num + " " + unit
+}
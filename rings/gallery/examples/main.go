@@ -0,0 +1,69 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The examples command writes the figures of the rings gallery as PNG and SVG files.
+//
+// Usage:
+//
+//	examples [-out dir] [figure ...]
+//
+// If no figures are named, all the gallery figures are written.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/biogo/graphics/rings/gallery"
+)
+
+func main() {
+	out := flag.String("out", ".", "specifies the directory the figures are written to.")
+	flag.Parse()
+
+	figs := gallery.Figures
+	if flag.NArg() != 0 {
+		figs = nil
+		for _, name := range flag.Args() {
+			f, ok := figure(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "examples: no figure %q\n", name)
+				os.Exit(2)
+			}
+			figs = append(figs, f)
+		}
+	}
+
+	err := os.MkdirAll(*out, 0755)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, f := range figs {
+		ps, err := f.Build()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "examples: %s: %v\n", f.Name, err)
+			os.Exit(1)
+		}
+		for _, ext := range []string{"png", "svg"} {
+			err = gallery.Save(filepath.Join(*out, fmt.Sprintf("%s.%s", f.Name, ext)), ps)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "examples: %s: %v\n", f.Name, err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// figure returns the gallery figure with the given name.
+func figure(name string) (gallery.Figure, bool) {
+	for _, f := range gallery.Figures {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return gallery.Figure{}, false
+}
@@ -0,0 +1,457 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gallery provides a set of canonical rings figures built from synthetic data.
+// Each figure is built by a self-contained function returning the plotters of the
+// figure, so the functions serve both as starting points for new figures and as
+// integration tests of combinations of rings. The figures may be rendered with Save,
+// or written as PNG and SVG files by the examples command.
+package gallery
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/palette"
+	"github.com/biogo/graphics/rings"
+	"github.com/biogo/graphics/rings/io"
+)
+
+// Figure is a named gallery figure.
+type Figure struct {
+	// Name is the name of the figure, used as the base
+	// name of the files the figure is written to.
+	Name string
+
+	// Build returns the plotters of the figure.
+	Build func() ([]plot.Plotter, error)
+}
+
+// Figures holds the gallery figures in gallery order.
+var Figures = []Figure{
+	{Name: "ideogram", Build: Ideogram},
+	{Name: "trace", Build: Trace},
+	{Name: "heat", Build: Heat},
+	{Name: "hairball", Build: Hairball},
+	{Name: "chord", Build: Chord},
+	{Name: "karyotype", Build: Karyotype},
+}
+
+// Margin is the margin left around the figures by Save.
+const Margin vg.Length = 10
+
+// Save renders the plotters of a figure to the named file, cropped to the extent of the
+// rendering with a Margin about it. The format of the file is taken from its extension,
+// and may be any format supported by draw.NewFormattedCanvas.
+func Save(file string, ps []plot.Plotter) (err error) {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+	w, h, cen := rings.Fit(Margin, ps...)
+	c, err := draw.NewFormattedCanvas(w, h, format)
+	if err != nil {
+		return err
+	}
+	rings.DrawFitted(draw.New(c), cen, ps...)
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+	_, err = c.WriteTo(f)
+	return err
+}
+
+// Geometry shared by the figures.
+const (
+	// gap is the gap between chromosomes as a fraction of the base arc.
+	gap = 0.01
+
+	// inner and outer are the radii of the ideogram.
+	inner, outer vg.Length = 150, 165
+
+	// binLength is the length of the bins of the synthetic scores.
+	binLength = 2e6
+)
+
+// base is the arc shared by the figures, running clockwise from twelve o'clock.
+var base = rings.Arc{Theta: rings.Complete / 4, Phi: rings.Complete * rings.Clockwise}
+
+var (
+	black = color.Gray{0}
+	thin  = draw.LineStyle{Color: black, Width: 0.5}
+)
+
+// chromosomeLengths and centromeres describe the synthetic karyotype of the figures,
+// loosely following the six largest human chromosomes. Each centromere is given as
+// the fraction of its chromosome held by the p arm.
+var (
+	chromosomeLengths = []int{248956422, 242193529, 198295559, 190214555, 181538259, 170805979}
+	centromeres       = []float64{0.50, 0.38, 0.46, 0.26, 0.27, 0.35}
+)
+
+// stains is the cycle of stains given to the bands of each chromosome arm.
+var stains = []string{"gneg", "gpos50", "gneg", "gpos100", "gneg", "gpos25", "gneg", "gpos75"}
+
+// karyotype returns the chromosomes of the synthetic karyotype and their bands. Each arm
+// is divided into bands of about 12 Mb, with a pair of centromeric bands at the centromere.
+func karyotype() ([]feat.Feature, map[feat.Feature][]rings.Band) {
+	chrs := make([]feat.Feature, len(chromosomeLengths))
+	bands := make(map[feat.Feature][]rings.Band)
+	for i, l := range chromosomeLengths {
+		chr := &io.Feature{Chrom: fmt.Sprintf("chr%d", i+1), To: l, ID: fmt.Sprintf("chr%d", i+1)}
+		chrs[i] = chr
+
+		cen := int(centromeres[i] * float64(l))
+		acen := l / 100
+		bs := arm("p", 0, cen-acen, true)
+		bs = append(bs,
+			rings.Band{Name: "p11", Start: cen - acen, End: cen, Stain: "acen"},
+			rings.Band{Name: "q11", Start: cen, End: cen + acen, Stain: "acen"},
+		)
+		bands[chr] = append(bs, arm("q", cen+acen, l, false)...)
+	}
+	return chrs, bands
+}
+
+// arm returns the bands of a chromosome arm between from and to, numbered outwards from
+// the centromere, which is at to if toCentromere is true and at from otherwise.
+func arm(prefix string, from, to int, toCentromere bool) []rings.Band {
+	n := (to - from) / 12e6
+	if n < 1 {
+		n = 1
+	}
+	bs := make([]rings.Band, n)
+	for k := range bs {
+		idx := k
+		if toCentromere {
+			idx = n - 1 - k
+		}
+		bs[k] = rings.Band{
+			Name:  fmt.Sprintf("%s%d", prefix, 12+idx),
+			Start: from + k*(to-from)/n,
+			End:   from + (k+1)*(to-from)/n,
+			Stain: stains[idx%len(stains)],
+		}
+	}
+	return bs
+}
+
+// ideogram returns the chromosomes of the synthetic karyotype and their ideogram.
+func ideogram() ([]feat.Feature, *rings.Blocks, error) {
+	chrs, bands := karyotype()
+	id, err := rings.NewIdeogram(chrs, bands, nil, base, inner, outer, gap)
+	if err != nil {
+		return nil, nil, err
+	}
+	id.LineStyle = thin
+	return chrs, id, nil
+}
+
+// bins returns scorers holding n scores for each binLength bin of the chromosomes, with
+// each score given by fn from the corresponding score of the preceding bin of the
+// chromosome, or zero for the first bin.
+func bins(chrs []feat.Feature, n int, fn func(last float64) float64) []rings.Scorer {
+	var ss []rings.Scorer
+	for _, chr := range chrs {
+		last := make([]float64, n)
+		for from := chr.Start(); from < chr.End(); from += binLength {
+			to := from + binLength
+			if to > chr.End() {
+				to = chr.End()
+			}
+			v := make([]float64, n)
+			for i := range v {
+				v[i] = fn(last[i])
+			}
+			copy(last, v)
+			ss = append(ss, &io.ValueFeature{
+				Feature: io.Feature{Chrom: chr.Name(), From: from, To: to, Loc: chr},
+				Values:  v,
+			})
+		}
+	}
+	return ss
+}
+
+// font returns a Helvetica font of the given size.
+func font(size vg.Length) (vg.Font, error) {
+	return vg.MakeFont("Helvetica", size)
+}
+
+// Ideogram returns a figure of a banded ideogram of a karyotype.
+func Ideogram() ([]plot.Plotter, error) {
+	_, id, err := ideogram()
+	if err != nil {
+		return nil, err
+	}
+	return []plot.Plotter{id}, nil
+}
+
+// Trace returns a figure of a pair of score traces inside an ideogram, with a radial
+// axis placed in the largest gap between the chromosomes. The traces are random walks.
+func Trace() ([]plot.Plotter, error) {
+	chrs, id, err := ideogram()
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	ss := bins(chrs, 2, func(last float64) float64 {
+		return math.Max(-3, math.Min(3, last+rnd.NormFloat64()/3))
+	})
+
+	fnt, err := font(6)
+	if err != nil {
+		return nil, err
+	}
+	t := &rings.Trace{
+		LineStyles: []draw.LineStyle{
+			{Color: color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 0xff}, Width: 0.75},
+			{Color: color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff}, Width: 0.75},
+		},
+		Join: true,
+		Axis: &rings.Axis{
+			Auto:      rings.PlaceAtLargestGap,
+			LineStyle: thin,
+			Grid:      draw.LineStyle{Color: color.Gray{0xcc}, Width: 0.25},
+			Tick: rings.TickConfig{
+				Marker:    plot.DefaultTicks{},
+				LineStyle: thin,
+				Length:    2,
+				Label:     draw.TextStyle{Color: black, Font: fnt},
+			},
+		},
+	}
+	sc, err := rings.NewScores(ss, id, 90, 140, t, rings.ScoreMinMax(-3, 3))
+	if err != nil {
+		return nil, err
+	}
+	return []plot.Plotter{id, sc}, nil
+}
+
+// Heat returns a figure of a heat ring of five samples of scores inside an ideogram, colored
+// with a perceptually uniform ramp.
+func Heat() ([]plot.Plotter, error) {
+	chrs, id, err := ideogram()
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(2))
+	ss := bins(chrs, 5, func(last float64) float64 {
+		return math.Max(0, math.Min(1, last+rnd.NormFloat64()/8))
+	})
+
+	h := &rings.Heat{Ramp: palette.Viridis()}
+	sc, err := rings.NewScores(ss, id, 110, 145, h, rings.ScoreMinMax(0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return []plot.Plotter{id, sc}, nil
+}
+
+// Hairball returns a figure of a large number of links between random positions of an
+// ideogram, colored by the chromosome of their first end. The depth of each link depends
+// on the distance it spans, so that links between nearby positions stay near the rim.
+func Hairball() ([]plot.Plotter, error) {
+	chrs, id, err := ideogram()
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(3))
+	pos := func() *io.Feature {
+		chr := chrs[rnd.Intn(len(chrs))]
+		from := rnd.Intn(chr.Len() - 1e6)
+		return &io.Feature{Chrom: chr.Name(), From: from, To: from + 1e6, Loc: chr}
+	}
+	links := make([]rings.Pair, 400)
+	for i := range links {
+		links[i] = &io.Link{ID: fmt.Sprint(i), Ends: [2]*io.Feature{pos(), pos()}, Value: math.NaN()}
+	}
+
+	cols := chromosomeColors(chrs, 0x60)
+	l, err := rings.NewLinks(links, [2]rings.ArcOfer{id, id}, [2]vg.Length{inner, inner},
+		rings.LinkBezier(&rings.Bezier{
+			Segments:   20,
+			RadiusFunc: rings.ProportionalRadius(0.1, 0.8),
+			Rand:       rnd,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	l.LineStyleFunc = func(p rings.Pair) draw.LineStyle {
+		return draw.LineStyle{Color: cols[p.Features()[0].Location()], Width: 0.5}
+	}
+	return []plot.Plotter{id, l}, nil
+}
+
+// chromosomeColors returns a color for each of the chromosomes taken from a perceptually
+// uniform ramp with the given alpha.
+func chromosomeColors(chrs []feat.Feature, alpha uint8) map[feat.Feature]color.Color {
+	pal := palette.Discretize(palette.Viridis(), len(chrs))
+	cols := make(map[feat.Feature]color.Color, len(chrs))
+	for i, chr := range chrs {
+		c := color.NRGBAModel.Convert(pal[i]).(color.NRGBA)
+		c.A = alpha
+		cols[chr] = c
+	}
+	return cols
+}
+
+// group is a colored segment of a chord diagram.
+type group struct {
+	io.Feature
+	color color.Color
+}
+
+func (g *group) FillColor() color.Color { return g.color }
+
+// flow is a weighted association between groups of a chord diagram, rendered as a
+// ribbon whose ends span arcs of their groups in proportion to the weight of the flow.
+type flow struct {
+	ends  [2]feat.Feature
+	color color.Color
+}
+
+func (f flow) Features() [2]feat.Feature { return f.ends }
+func (f flow) FillColor() color.Color    { return f.color }
+
+// Chord returns a chord diagram of the weighted flows between five groups. Each group
+// is allocated an arc in proportion to the total weight of its flows and each flow is
+// rendered as a ribbon whose ends are in proportion to its weight, colored by its source
+// group.
+func Chord() ([]plot.Plotter, error) {
+	names := []string{"A", "B", "C", "D", "E"}
+	weights := [][]int{
+		{0, 40, 15, 30, 10},
+		{25, 0, 35, 5, 20},
+		{10, 30, 0, 25, 15},
+		{45, 10, 20, 0, 5},
+		{5, 15, 30, 20, 0},
+	}
+
+	// Allocate the groups with the outgoing flows of
+	// each group preceding its incoming flows.
+	pal := palette.Discretize(palette.Viridis(), len(names))
+	groups := make([]*group, len(names))
+	fs := make([]feat.Feature, len(names))
+	for i, n := range names {
+		var total int
+		for j := range names {
+			total += weights[i][j] + weights[j][i]
+		}
+		groups[i] = &group{Feature: io.Feature{Chrom: n, To: total, ID: n}, color: pal[i]}
+		fs[i] = groups[i]
+	}
+	out := make([]int, len(names))
+	in := make([]int, len(names))
+	for i := range names {
+		for j := range names {
+			in[i] += weights[i][j]
+		}
+	}
+	end := func(g, w int, next []int) *io.Feature {
+		f := &io.Feature{Chrom: names[g], From: next[g], To: next[g] + w, Loc: groups[g]}
+		next[g] += w
+		return f
+	}
+	var flows []rings.Pair
+	for i := range names {
+		c := color.NRGBAModel.Convert(pal[i]).(color.NRGBA)
+		c.A = 0xa0
+		for j, w := range weights[i] {
+			if w == 0 {
+				continue
+			}
+			flows = append(flows, flow{ends: [2]feat.Feature{end(i, w, out), end(j, w, in)}, color: c})
+		}
+	}
+
+	bs, err := rings.NewGappedBlocks(fs, base, inner, outer, 2*gap, rings.BlockLineStyle(thin))
+	if err != nil {
+		return nil, err
+	}
+	rs, err := rings.NewRibbons(flows, [2]rings.ArcOfer{bs, bs}, [2]vg.Length{inner, inner})
+	if err != nil {
+		return nil, err
+	}
+	rs.Bezier = &rings.Bezier{Segments: 30}
+	// Flat ribbons do not pinch at the center. Ribbons between nearby
+	// arcs still overlap themselves, so fill them with the same rule in
+	// all output formats.
+	rs.Twist = rings.Flat
+	rs.FillRule = rings.NonZeroRule
+	rs.LineStyle = draw.LineStyle{Color: color.Gray{0x60}, Width: 0.25}
+
+	fnt, err := font(10)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := rings.NewLabelsWith(bs, outer+8, rings.NameLabels(fs),
+		rings.LabelTextStyle(draw.TextStyle{Color: black, Font: fnt}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return []plot.Plotter{bs, rs, lb}, nil
+}
+
+// Karyotype returns a figure of a banded ideogram with a genomic scale along its outer
+// edge and the chromosome names outside the scale.
+func Karyotype() ([]plot.Plotter, error) {
+	chrs, id, err := ideogram()
+	if err != nil {
+		return nil, err
+	}
+
+	small, err := font(5)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := rings.NewScale(chrs, id, outer+2)
+	if err != nil {
+		return nil, err
+	}
+	sc.LineStyle = thin
+	sc.Tick.LineStyle = thin
+	sc.Tick.Length = 2
+	sc.Tick.Label = draw.TextStyle{Color: black, Font: small}
+	sc.Tick.Format = func(v float64) string {
+		// Leave the origin of each chromosome unlabelled so that
+		// it does not crowd the last label of the preceding one.
+		if v == 0 {
+			return ""
+		}
+		return rings.GenomicFormat(v)
+	}
+
+	fnt, err := font(10)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := rings.NewLabelsWith(id, outer+30, rings.NameLabels(chrs),
+		rings.LabelTextStyle(draw.TextStyle{Color: black, Font: fnt}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return []plot.Plotter{id, sc, lb}, nil
+}
@@ -0,0 +1,118 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gallery
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgimg"
+	"github.com/gonum/plot/vg/vgsvg"
+	"gopkg.in/check.v1"
+
+	"github.com/biogo/graphics/rings"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestFigures(c *check.C) {
+	dir := c.MkDir()
+	for _, f := range Figures {
+		ps, err := f.Build()
+		c.Assert(err, check.Equals, nil, check.Commentf("figure %s", f.Name))
+		c.Check(rings.ValidateAll(ps...), check.Equals, nil, check.Commentf("figure %s", f.Name))
+
+		for _, ext := range []string{"png", "svg"} {
+			file := filepath.Join(dir, f.Name+"."+ext)
+			c.Assert(Save(file, ps), check.Equals, nil, check.Commentf("figure %s", f.Name))
+			fi, err := os.Stat(file)
+			c.Assert(err, check.Equals, nil)
+			c.Check(fi.Size() > 0, check.Equals, true, check.Commentf("figure %s", file))
+		}
+
+		// The figures are cropped to the extent of their rendering.
+		r, err := os.Open(filepath.Join(dir, f.Name+".png"))
+		c.Assert(err, check.Equals, nil)
+		img, err := png.Decode(r)
+		r.Close()
+		c.Assert(err, check.Equals, nil)
+		w, h, _ := rings.Fit(Margin, ps...)
+		b := img.Bounds()
+		px := func(l vg.Length) float64 { return float64(l/vg.Inch) * vgimg.DefaultDPI }
+		c.Check(math.Abs(float64(b.Dx())-px(w)) <= 1, check.Equals, true, check.Commentf("figure %s width %d for %v", f.Name, b.Dx(), w))
+		c.Check(math.Abs(float64(b.Dy())-px(h)) <= 1, check.Equals, true, check.Commentf("figure %s height %d for %v", f.Name, b.Dy(), h))
+	}
+}
+
+func (s *S) TestDeterministic(c *check.C) {
+	dir := c.MkDir()
+	for _, f := range Figures {
+		var files [2][]byte
+		for i := range files {
+			ps, err := f.Build()
+			c.Assert(err, check.Equals, nil)
+			file := filepath.Join(dir, f.Name+".svg")
+			c.Assert(Save(file, ps), check.Equals, nil)
+			files[i], err = ioutil.ReadFile(file)
+			c.Assert(err, check.Equals, nil)
+		}
+		c.Check(string(files[0]) == string(files[1]), check.Equals, true, check.Commentf("figure %s", f.Name))
+	}
+}
+
+func (s *S) TestCompactSVG(c *check.C) {
+	// svg returns the size of the SVG rendering of the figure built by fn. If compact is
+	// true, the curves of the figure are simplified with a tolerance of 0.25pt and its
+	// coordinates are rounded to one decimal place.
+	svg := func(fn func() ([]plot.Plotter, error), compact bool) int {
+		ps, err := fn()
+		c.Assert(err, check.Equals, nil)
+		w, h, cen := rings.Fit(Margin, ps...)
+		sc := vgsvg.New(w, h)
+		ca := draw.New(sc)
+		if compact {
+			for _, p := range ps {
+				switch p := p.(type) {
+				case *rings.Ribbons:
+					p.Tolerance = 0.25
+				case *rings.Links:
+					p.Tolerance = 0.25
+				}
+			}
+			ca = draw.NewCanvas(rings.RoundingCanvas{Canvas: sc, Precision: 1, DPI: vgsvg.DPI}, w, h)
+		}
+		rings.DrawFitted(ca, cen, ps...)
+		var buf bytes.Buffer
+		_, err = sc.WriteTo(&buf)
+		c.Assert(err, check.Equals, nil)
+		return buf.Len()
+	}
+
+	for _, test := range []struct {
+		name string
+		fn   func() ([]plot.Plotter, error)
+	}{
+		{name: "hairball", fn: Hairball},
+		{name: "chord", fn: Chord},
+	} {
+		before, after := svg(test.fn, false), svg(test.fn, true)
+		c.Logf("%s: %d -> %d bytes", test.name, before, after)
+		c.Check(float64(after) < 0.8*float64(before), check.Equals, true,
+			check.Commentf("figure %s: %d bytes compacted from %d", test.name, after, before))
+	}
+}
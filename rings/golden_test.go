@@ -0,0 +1,241 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgimg"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/rings"
+
+	"gopkg.in/check.v1"
+)
+
+var update = flag.Bool("update", false, "Update the golden images of the golden image tests.")
+
+const (
+	// goldenDPI and goldenSize are the resolution and size of golden images.
+	goldenDPI  = 72
+	goldenSize = vg.Length(300)
+
+	// goldenTolerance is the largest difference in any channel of a pixel
+	// that is not counted as a differing pixel, and goldenMaxDiffs is the
+	// number of differing pixels allowed, to accommodate differences in font
+	// rasterisation.
+	goldenTolerance = 0x30
+	goldenMaxDiffs  = 200
+)
+
+// goldenPlots returns the canonical plots compared against golden images. Each plot
+// is built from a seeded random source so that it is identical on every run.
+func goldenPlots(c *check.C) map[string]func() *plot.Plot {
+	font, err := vg.MakeFont("Helvetica", 6)
+	c.Assert(err, check.Equals, nil)
+	textSty := draw.TextStyle{Color: color.Black, Font: font}
+	sty := plotter.DefaultLineStyle
+	sty.Width /= 2
+
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 800, name: "chr2"},
+		&fs{start: 0, end: 600, name: "chr3"},
+	}
+	ideogram := func() *rings.Blocks {
+		bands := make(map[feat.Feature][]rings.Band)
+		stains := []string{"gneg", "gpos25", "gpos50", "gpos75", "gpos100"}
+		for i, chr := range chrs {
+			for s := 0; s < chr.End(); s += 100 {
+				bands[chr] = append(bands[chr], rings.Band{
+					Name:  fmt.Sprintf("p%d", s/100),
+					Start: s, End: s + 100,
+					Stain: stains[(i+s/100)%len(stains)],
+				})
+			}
+		}
+		b, err := rings.NewIdeogram(chrs, bands, nil, rings.Arc{0, rings.Complete * rings.Clockwise}, 100, 110, 0.02)
+		c.Assert(err, check.Equals, nil)
+		b.LineStyle = sty
+		return b
+	}
+
+	return map[string]func() *plot.Plot{
+		"ideogram": func() *plot.Plot {
+			b := ideogram()
+			lb, err := rings.NewLabelsWith(b, 115, rings.NameLabels(chrs), rings.LabelTextStyle(textSty))
+			c.Assert(err, check.Equals, nil)
+			return goldenPlot(c, b, lb)
+		},
+		"trace": func() *plot.Plot {
+			rnd := rand.New(rand.NewSource(1))
+			b := ideogram()
+			var scorers []rings.Scorer
+			for _, chr := range chrs {
+				scorers = append(scorers, makeScorers(chr.(*fs), 20, 2, func(_, j int) float64 {
+					return float64(j) + rnd.Float64()
+				})...)
+			}
+			grid := sty
+			grid.Color = color.Gray{0xbf}
+			sc, err := rings.NewScores(scorers, b, 60, 95, &rings.Trace{
+				LineStyles: []draw.LineStyle{sty, sty},
+				Axis: &rings.Axis{
+					Angle:     rings.Complete / 4,
+					LineStyle: sty,
+					Grid:      grid,
+					Tick: rings.TickConfig{
+						Marker:    plot.DefaultTicks{},
+						LineStyle: sty,
+						Length:    2,
+						Label:     textSty,
+					},
+				},
+			}, rings.ScoreMinMax(0, 2))
+			c.Assert(err, check.Equals, nil)
+			return goldenPlot(c, b, sc)
+		},
+		"links": func() *plot.Plot {
+			rnd := rand.New(rand.NewSource(1))
+			b := ideogram()
+			var pairs []rings.Pair
+			for i := 0; i < 20; i++ {
+				var p fp
+				for j := range p.feats {
+					loc := chrs[rnd.Intn(len(chrs))]
+					start := rnd.Intn(loc.End() - 10)
+					p.feats[j] = &fs{start: start, end: start + 10, location: loc, style: sty}
+				}
+				p.sty = sty
+				pairs = append(pairs, p)
+			}
+			l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{95, 95},
+				rings.LinkBezier(&rings.Bezier{
+					Segments: 20,
+					Radius:   rings.LengthDist{Length: 50, Min: floatPtr(0.9), Max: floatPtr(1.1)},
+					Rand:     rnd,
+				}),
+			)
+			c.Assert(err, check.Equals, nil)
+			return goldenPlot(c, b, l)
+		},
+	}
+}
+
+// goldenPlot returns a plot holding the provided plotters with hidden axes.
+func goldenPlot(c *check.C, ps ...plot.Plotter) *plot.Plot {
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	p.Add(ps...)
+	p.HideAxes()
+	return p
+}
+
+// renderCanvas renders p to a vgimg.Canvas at the golden image resolution.
+func renderCanvas(p *plot.Plot) *vgimg.Canvas {
+	cv := vgimg.NewWith(vgimg.UseWH(goldenSize, goldenSize), vgimg.UseDPI(goldenDPI))
+	p.Draw(draw.New(cv))
+	return cv
+}
+
+// render renders p to an image at the golden image resolution.
+func render(p *plot.Plot) *image.RGBA {
+	img := renderCanvas(p).Image()
+	rgba := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba
+}
+
+// pixelHash returns a hash of the pixels of img.
+func pixelHash(img *image.RGBA) [sha256.Size]byte {
+	return sha256.Sum256(img.Pix)
+}
+
+// diffPixels returns the number of pixels of got and want differing by more than
+// goldenTolerance in any channel. Images of different sizes differ in every pixel.
+func diffPixels(got, want *image.RGBA) int {
+	if got.Bounds() != want.Bounds() {
+		return got.Bounds().Dx() * got.Bounds().Dy()
+	}
+	var n int
+	for i := 0; i < len(got.Pix); i += 4 {
+		for j := i; j < i+4; j++ {
+			d := int(got.Pix[j]) - int(want.Pix[j])
+			if d < -goldenTolerance || goldenTolerance < d {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// readGolden returns the golden image in the named file as an *image.RGBA.
+func readGolden(path string) (*image.RGBA, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba, nil
+}
+
+// TestGolden compares renderings of the canonical plots against the golden images
+// in testdata. Run the tests with -update to regenerate the golden images.
+func (s *S) TestGolden(c *check.C) {
+	for name, fn := range goldenPlots(c) {
+		path := filepath.Join("testdata", "golden_"+name+".png")
+		got := render(fn())
+
+		// Rendering must be deterministic within a run.
+		c.Check(pixelHash(render(fn())), check.Equals, pixelHash(got), check.Commentf("%s rendering is not deterministic", name))
+
+		if *update {
+			var buf bytes.Buffer
+			c.Assert(png.Encode(&buf, got), check.Equals, nil)
+			c.Assert(ioutil.WriteFile(path, buf.Bytes(), 0644), check.Equals, nil)
+			continue
+		}
+
+		want, err := readGolden(path)
+		c.Assert(err, check.Equals, nil, check.Commentf("run the tests with -update to create golden images"))
+		if pixelHash(got) == pixelHash(want) {
+			continue
+		}
+		n := diffPixels(got, want)
+		if n > goldenMaxDiffs && (*pics || *allPics) {
+			var buf bytes.Buffer
+			c.Assert(png.Encode(&buf, got), check.Equals, nil)
+			c.Assert(ioutil.WriteFile(fmt.Sprintf("golden_%s-fail.png", name), buf.Bytes(), 0644), check.Equals, nil)
+		}
+		c.Check(n <= goldenMaxDiffs, check.Equals, true, check.Commentf("%s: %d pixels differ from %s", name, n, path))
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// DefaultGradientSlices is the number of slices used to render a GradientFill
+// with no Slices specified.
+const DefaultGradientSlices = 32
+
+// gradientOverlap is the radial distance by which each slice of a gradient
+// extends under the following slice so that antialiasing seams are not
+// rendered between slices.
+const gradientOverlap = vg.Length(0.5)
+
+// GradientFill is a radial gradient fill. Since not all canvases support gradients,
+// the gradient is rendered as a series of concentric annular wedges with colors
+// interpolated from the inner radius to the outer radius.
+type GradientFill struct {
+	// Inner and Outer are the colors of the gradient at the inner
+	// and outer radii. A nil color is treated as transparent.
+	Inner, Outer color.Color
+
+	// Ease, if not nil, maps the fractional radial position within
+	// the gradient, from 0 at the inner radius to 1 at the outer
+	// radius, to the fraction of the interpolation from Inner to
+	// Outer. If Ease is nil the interpolation is linear.
+	Ease func(float64) float64
+
+	// Slices is the number of annular wedges used to render the
+	// gradient. If Slices is zero, DefaultGradientSlices is used.
+	Slices int
+}
+
+// At returns the color of the gradient at the fractional radial position t, from
+// 0 at the inner radius to 1 at the outer radius.
+func (g *GradientFill) At(t float64) color.Color {
+	if g.Ease != nil {
+		t = g.Ease(t)
+	}
+	return lerpColor(g.Inner, g.Outer, t)
+}
+
+// slices returns the number of slices used to render the gradient.
+func (g *GradientFill) slices() int {
+	if g.Slices == 0 {
+		return DefaultGradientSlices
+	}
+	return g.Slices
+}
+
+// fill renders the gradient within the annular wedge of sec. Each slice extends
+// under the next by gradientOverlap, or by the width of a slice if that is smaller.
+// The outermost slice is extended beyond the outer radius of sec only if open is
+// true, so that fills rendered after it cover the seam between them. If the outer
+// radius of sec is less than its inner radius, the gradient runs inwards from the
+// inner radius and slices extend under the next slice towards the center.
+func (g *GradientFill) fill(ca draw.Canvas, sec Sector, open bool) {
+	n := g.slices()
+	if n <= 0 || sec.Outer == sec.Inner {
+		return
+	}
+	w := (sec.Outer - sec.Inner) / vg.Length(n)
+	overlap := gradientOverlap
+	if overlap > vg.Length(math.Abs(float64(w))) {
+		overlap = vg.Length(math.Abs(float64(w)))
+	}
+	if w < 0 {
+		overlap = -overlap
+	}
+	var pa vg.Path
+	for i := 0; i < n; i++ {
+		c := g.At((float64(i) + 0.5) / float64(n))
+		if _, _, _, a := c.RGBA(); a == 0 {
+			continue
+		}
+		from := sec.Inner + vg.Length(i)*w
+		to := from + w
+		if i < n-1 || open {
+			to += overlap
+		} else {
+			to = sec.Outer
+		}
+		if to < from {
+			from, to = to, from
+		}
+		pa = pa[:0]
+		AnnularWedge(&pa, sec.Center, from, to, sec.Arc)
+		ca.SetColor(c)
+		ca.Fill(pa)
+	}
+}
+
+// lerpColor returns the color at the fraction t of the linear interpolation from a
+// to b in premultiplied RGBA space. The value of t is clamped to [0, 1] and nil colors
+// are treated as transparent.
+func lerpColor(a, b color.Color, t float64) color.Color {
+	if a == nil {
+		a = color.Transparent
+	}
+	if b == nil {
+		b = color.Transparent
+	}
+	t = math.Max(0, math.Min(1, t))
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	lerp := func(x, y uint32) uint16 {
+		return uint16(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA64{R: lerp(ar, br), G: lerp(ag, bg), B: lerp(ab, bb), A: lerp(aa, ba)}
+}
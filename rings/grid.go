@@ -0,0 +1,187 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// PolarGrid implements rendering a reference grid of concentric circles and radial
+// spokes that is independent of any ring.
+//
+// Plotters added to a gonum plot are drawn in the order they are added, so a PolarGrid
+// added first is rendered under all other plotters.
+type PolarGrid struct {
+	// Inner and Outer define the inner and outer radii of the grid. Spokes are
+	// rendered between Inner and Outer.
+	Inner, Outer vg.Length
+
+	// Radii holds the radii of the rendered circles. If Radii is nil, Circles
+	// circles are rendered evenly spaced from Inner to Outer inclusive, or at
+	// Outer if Circles is one.
+	Radii   []vg.Length
+	Circles int
+
+	// Angles holds the angles of the rendered spokes. If Angles is nil, Spokes
+	// spokes are rendered evenly spaced around the grid's arc, including both ends
+	// of the arc if it is not a complete circle. Angles outside the arc are not
+	// rendered.
+	Angles []Angle
+	Spokes int
+
+	// Arc restricts the grid to the specified arc. If the Phi of Arc is zero the
+	// complete grid is rendered.
+	Arc Arc
+
+	// CircleStyle and SpokeStyle determine the line styles of the circles and the
+	// spokes.
+	CircleStyle, SpokeStyle draw.LineStyle
+
+	// Crisp specifies that the grid's circles and spokes are snapped to the pixel
+	// grid when rendered on a raster canvas so that thin lines render sharply.
+	// Crisp has no effect on vector canvases.
+	Crisp bool
+
+	// Layer specifies the drawing layer of the PolarGrid when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewPolarGrid returns a PolarGrid rendering the specified number of circles and
+// spokes between the inner and outer radii using the provided line style.
+func NewPolarGrid(sty draw.LineStyle, inner, outer vg.Length, circles, spokes int) *PolarGrid {
+	return &PolarGrid{
+		Inner:       inner,
+		Outer:       outer,
+		Circles:     circles,
+		Spokes:      spokes,
+		CircleStyle: sty,
+		SpokeStyle:  sty,
+	}
+}
+
+// arc returns the arc of the grid.
+func (r *PolarGrid) arc() Arc {
+	if r.Arc.Phi == 0 {
+		return Arc{r.Arc.Theta, Complete}
+	}
+	return r.Arc
+}
+
+// radii returns the radii of the circles of the grid.
+func (r *PolarGrid) radii() []vg.Length {
+	if r.Radii != nil || r.Circles < 1 {
+		return r.Radii
+	}
+	if r.Circles == 1 {
+		return []vg.Length{r.Outer}
+	}
+	radii := make([]vg.Length, r.Circles)
+	step := (r.Outer - r.Inner) / vg.Length(r.Circles-1)
+	for i := range radii {
+		radii[i] = r.Inner + vg.Length(i)*step
+	}
+	return radii
+}
+
+// angles returns the angles of the spokes of the grid that lie within its arc.
+func (r *PolarGrid) angles() []Angle {
+	arc := r.arc()
+	if r.Angles != nil {
+		var angles []Angle
+		for _, a := range r.Angles {
+			if _, ok := arc.position(a); ok {
+				angles = append(angles, a)
+			}
+		}
+		return angles
+	}
+	if r.Spokes < 1 {
+		return nil
+	}
+	n := r.Spokes
+	if arc.Phi != Complete && arc.Phi != -Complete && n > 1 {
+		n--
+	}
+	angles := make([]Angle, r.Spokes)
+	for i := range angles {
+		angles[i] = arc.Theta + Angle(i)*arc.Phi/Angle(n)
+	}
+	return angles
+}
+
+// DrawAt renders the PolarGrid at cen in the specified drawing area, according to the
+// PolarGrid configuration.
+func (r *PolarGrid) DrawAt(ca draw.Canvas, cen vg.Point) {
+	arc := r.arc()
+
+	var pa vg.Path
+	if r.CircleStyle.Color != nil && r.CircleStyle.Width != 0 {
+		s := r.snapper(ca, r.CircleStyle.Width)
+		c := s.point(cen)
+		for _, rad := range r.radii() {
+			rad = s.length(rad)
+			pa.Move(s.clean(RectangularAt(c, arc.Theta, rad)))
+			pa.Arc(c, rad, float64(arc.Theta), float64(arc.Phi))
+		}
+		if len(pa) != 0 {
+			ca.SetLineStyle(r.CircleStyle)
+			ca.Stroke(pa)
+		}
+	}
+
+	pa = pa[:0]
+	if r.SpokeStyle.Color != nil && r.SpokeStyle.Width != 0 {
+		s := r.snapper(ca, r.SpokeStyle.Width)
+		c := s.point(cen)
+		for _, a := range r.angles() {
+			pa.Move(s.clean(RectangularAt(c, a, s.length(r.Inner))))
+			pa.Line(s.clean(RectangularAt(c, a, s.length(r.Outer))))
+		}
+		if len(pa) != 0 {
+			ca.SetLineStyle(r.SpokeStyle)
+			ca.Stroke(pa)
+		}
+	}
+}
+
+// snapper returns a snapper for lines of width w on ca if the PolarGrid is Crisp.
+func (r *PolarGrid) snapper(ca draw.Canvas, w vg.Length) snapper {
+	if !r.Crisp {
+		return snapper{}
+	}
+	return crisp(ca, w)
+}
+
+// XY returns the x and y coordinates of the PolarGrid.
+func (r *PolarGrid) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the PolarGrid.
+func (r *PolarGrid) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the PolarGrid's X and Y values as the drawing coordinates.
+func (r *PolarGrid) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the grid rendering restricted to its arc.
+func (r *PolarGrid) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	outer := r.Outer
+	for _, rad := range r.radii() {
+		if rad > outer {
+			outer = rad
+		}
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.arc().bounds(0, outer),
+	}}
+}
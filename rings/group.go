@@ -0,0 +1,115 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"github.com/biogo/biogo/feat"
+)
+
+// GroupFeature is a feat.Feature spanning the members of a feature group within
+// a single location.
+type GroupFeature struct {
+	// Group is the feature group.
+	Group feat.Feature
+
+	// Loc is the location of the members of the group spanned by the GroupFeature.
+	Loc feat.Feature
+
+	// From and To are the minimum start and maximum end of the spanned members.
+	From, To int
+}
+
+func (g *GroupFeature) Start() int             { return g.From }
+func (g *GroupFeature) End() int               { return g.To }
+func (g *GroupFeature) Len() int               { return g.To - g.From }
+func (g *GroupFeature) Location() feat.Feature { return g.Loc }
+
+// Name returns the name of the feature group.
+func (g *GroupFeature) Name() string {
+	if g.Group == nil {
+		return ""
+	}
+	return g.Group.Name()
+}
+
+// Description returns the description of the feature group.
+func (g *GroupFeature) Description() string {
+	if g.Group == nil {
+		return ""
+	}
+	return g.Group.Description()
+}
+
+// GroupPair is a Pair aggregating the feature pairs joining two feature groups.
+type GroupPair struct {
+	// Feats holds the group features at the ends of the aggregated pairs.
+	Feats [2]*GroupFeature
+
+	// Pairs holds the aggregated feature pairs.
+	Pairs []Pair
+}
+
+// Features returns the group features of the GroupPair.
+func (p *GroupPair) Features() [2]feat.Feature {
+	return [2]feat.Feature{p.Feats[0], p.Feats[1]}
+}
+
+// Count returns the number of aggregated feature pairs. Count can be used to scale the
+// rendering of the GroupPair, for example by a Links LineStyleFunc.
+func (p *GroupPair) Count() int { return len(p.Pairs) }
+
+// GroupPairs aggregates the provided pairs into GroupPairs according to the groups returned
+// by groupOf for the features of each pair. If groupOf returns nil for a feature, the feature
+// forms its own group. Pairs are aggregated when the features at each end are in the same
+// group and location, and each GroupFeature spans the extent of all the members of its group
+// within its location that are found in pairs. GroupPairs are returned in the order of their
+// first aggregated pair and all returned values are *GroupPair. The GroupFeatures share the
+// locations of the group members, so they may be rendered by Ribbons and Links using the
+// ArcOfer used for the original pairs.
+func GroupPairs(pairs []Pair, groupOf func(feat.Feature) feat.Feature) []Pair {
+	type member struct {
+		group, loc feat.Feature
+	}
+	memberOf := func(f feat.Feature) member {
+		g := groupOf(f)
+		if g == nil {
+			g = f
+		}
+		return member{group: g, loc: f.Location()}
+	}
+
+	groups := make(map[member]*GroupFeature)
+	for _, p := range pairs {
+		for _, f := range p.Features() {
+			m := memberOf(f)
+			g, ok := groups[m]
+			if !ok {
+				groups[m] = &GroupFeature{Group: m.group, Loc: m.loc, From: f.Start(), To: f.End()}
+				continue
+			}
+			if f.Start() < g.From {
+				g.From = f.Start()
+			}
+			if f.End() > g.To {
+				g.To = f.End()
+			}
+		}
+	}
+
+	var grouped []Pair
+	index := make(map[[2]*GroupFeature]*GroupPair)
+	for _, p := range pairs {
+		fp := p.Features()
+		key := [2]*GroupFeature{groups[memberOf(fp[0])], groups[memberOf(fp[1])]}
+		gp, ok := index[key]
+		if !ok {
+			gp = &GroupPair{Feats: key}
+			index[key] = gp
+			grouped = append(grouped, gp)
+		}
+		gp.Pairs = append(gp.Pairs, p)
+	}
+	return grouped
+}
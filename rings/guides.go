@@ -0,0 +1,169 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// SharedGuides implements rendering of guide arcs at key score values, such as zero or a
+// significance threshold, across a collection of Scores tracks that share a radial scale.
+// For each value, an arc is drawn across the arc of the Base of each track at the radius
+// of the value in the track, and adjacent tracks are joined by radial connectors across
+// the gap between them, so that the guides run continuously across the tracks. Values
+// outside the score range of a track, or within a break of a Trace renderer's scale, are
+// not drawn for that track, nor are the connectors to it.
+type SharedGuides struct {
+	// Range, if not nil, is the score range shared by the Tracks, each of which
+	// must be rendered according to it. Guides are placed according to the
+	// score range of each track given by its ScoreRange method.
+	Range *Range
+
+	// Values holds the score values at which guides are drawn.
+	Values []float64
+
+	// LineStyle determines the line style of the guide arcs and connectors.
+	LineStyle draw.LineStyle
+
+	// Tracks holds the participating Scores. Tracks adjacent in the slice are
+	// joined by connectors at the start of the arc of the Base of the first
+	// of the pair and, if the arc is not a complete circle, at its end.
+	// Tracks with per feature ranges are not supported.
+	Tracks []*Scores
+
+	// Layer specifies the drawing layer of the SharedGuides when rendered by a
+	// Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewSharedGuides returns a SharedGuides drawing guides at the provided values across the
+// tracks on the shared range rng with the line style sty. An error is returned if no track
+// is provided, a track is nil, or rng is not nil and a track is not rendered according to it.
+func NewSharedGuides(rng *Range, values []float64, sty draw.LineStyle, tracks ...*Scores) (*SharedGuides, error) {
+	if len(tracks) == 0 {
+		return nil, errors.New("rings: no guide tracks")
+	}
+	for _, t := range tracks {
+		if t == nil {
+			return nil, errors.New("rings: nil guide track")
+		}
+		if rng != nil && !t.usesRange(rng) {
+			return nil, errors.New("rings: guide track not using shared range")
+		}
+	}
+	return &SharedGuides{
+		Range:     rng,
+		Values:    values,
+		LineStyle: sty,
+		Tracks:    tracks,
+	}, nil
+}
+
+// Radius returns the radius of the guide at the score value v in track t and whether
+// the guide is drawn for t.
+func (r *SharedGuides) Radius(t *Scores, v float64) (rad vg.Length, ok bool) {
+	min, max := t.ScoreRange()
+	if !(min < max) {
+		return 0, false
+	}
+	lo, hi := t.scaled(t.Inner, t.Outer)
+	return newRadialScale(min, max, lo, hi, breaksOf(t.Renderer)).radius(v)
+}
+
+// usesRange returns whether t is rendered according to the shared range rng.
+func (t *Scores) usesRange(rng *Range) bool {
+	return t.Range == rng && !t.hasRange() && !t.PerFeatureRange
+}
+
+// DrawAt renders the guides of a SharedGuides at cen in the specified drawing area,
+// according to the SharedGuides configuration.
+func (r *SharedGuides) DrawAt(ca draw.Canvas, cen vg.Point) {
+	sty := r.LineStyle
+	if sty.Color == nil || sty.Width == 0 || len(r.Tracks) == 0 {
+		return
+	}
+	ca.SetLineStyle(sty)
+
+	var pa vg.Path
+	for _, v := range r.Values {
+		for i, t := range r.Tracks {
+			rad, ok := r.Radius(t, v)
+			if !ok {
+				continue
+			}
+			arc := t.Base.Arc()
+			pa = pa[:0]
+			pa.Move(RectangularAt(cen, arc.Theta, rad))
+			pa.Arc(cen, rad, float64(arc.Theta), float64(arc.Phi))
+			ca.Stroke(pa)
+
+			if i == len(r.Tracks)-1 {
+				continue
+			}
+			next, ok := r.Radius(r.Tracks[i+1], v)
+			if !ok {
+				continue
+			}
+			angles := []Angle{arc.Theta}
+			if math.Abs(float64(arc.Phi)) < float64(Complete) {
+				angles = append(angles, arc.Theta+arc.Phi)
+			}
+			for _, a := range angles {
+				pa = pa[:0]
+				pa.Move(RectangularAt(cen, a, rad))
+				pa.Line(RectangularAt(cen, a, next))
+				ca.Stroke(pa)
+			}
+		}
+	}
+}
+
+// XY returns the x and y coordinates of the SharedGuides.
+func (r *SharedGuides) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the SharedGuides.
+func (r *SharedGuides) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the SharedGuides' X and Y values as the drawing coordinates.
+func (r *SharedGuides) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the guides, covering the annuli of the
+// Tracks.
+func (r *SharedGuides) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	var (
+		bounds vg.Rectangle
+		found  bool
+	)
+	for _, t := range r.Tracks {
+		if t == nil || t.Base == nil {
+			continue
+		}
+		b := t.Base.Arc().bounds(t.Inner, t.Outer)
+		if !found {
+			bounds, found = b, true
+			continue
+		}
+		bounds = union(bounds, b)
+	}
+	if !found {
+		return nil
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: bounds,
+	}}
+}
@@ -5,27 +5,71 @@
 package rings
 
 import (
+	"errors"
+	"fmt"
 	"image/color"
+	"math"
+	"sort"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
 )
 
 // Highlight implements rendering a colored arc.
 type Highlight struct {
-	// Base describes the arc through which the highlight should be drawn.
+	// Base describes the arc through which the highlight should be drawn. If the
+	// Phi of Base is not zero, Base is used in preference to LocFeatures.
 	Base Arc
 
+	// LocFeatures and LocBase specify the highlighted arc as the union of the arcs
+	// of the features in LocFeatures within LocBase when the Phi of Base is zero.
+	LocFeatures []feat.Feature
+	LocBase     ArcOfer
+
+	// JoinGap is the largest angular gap between the arcs of LocFeatures that is
+	// spanned by the highlight. Arcs separated by a larger gap form separate wedges.
+	JoinGap Angle
+
+	// AllowSplit specifies that the highlight may be rendered as more than one
+	// wedge. If AllowSplit is false and the arcs of LocFeatures do not form a single
+	// wedge, DrawAt panics and Validate reports an error.
+	AllowSplit bool
+
 	// Color determines the fill color of the highlight.
 	Color color.Color
 
+	// Gradient, if not nil, specifies a radial gradient used in place of Color
+	// to fill the highlight.
+	Gradient *GradientFill
+
+	// Pattern determines the fill pattern of the highlight. If Pattern is not nil
+	// the highlight is filled with the pattern, over any fill color.
+	Pattern Patterner
+
 	// LineStyle determines the line style of the highlight.
 	LineStyle draw.LineStyle
 
+	// ArcDashes, if set, specifies the dash pattern of the arcs of the highlight
+	// outline in angular units in place of the dashes of the LineStyle. The radial
+	// edges of the outline are rendered solid.
+	ArcDashes ArcDashes
+
 	// Inner and Outer define the inner and outer radii of the blocks.
 	Inner, Outer vg.Length
 
+	// Offset, if not nil, returns the radial displacement of the wedge of the
+	// highlight holding f. The displacement of a wedge joining the arcs of more
+	// than one of LocFeatures is given by the first feature along the LocBase
+	// arc. Offset is called with a nil feature when the highlight is specified
+	// by Base.
+	Offset func(f feat.Feature) vg.Length
+
+	// Layer specifies the drawing layer of the Highlight when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
@@ -44,35 +88,154 @@ func NewHighlight(col color.Color, base Arc, inner, outer vg.Length) *Highlight
 // DrawAt renders the feature of a Highlight at cen in the specified drawing area,
 // according to the Highlight configuration.
 func (r *Highlight) DrawAt(ca draw.Canvas, cen vg.Point) {
-	if r.Color == nil && (r.LineStyle.Color == nil || r.LineStyle.Width == 0) {
+	if r.Color == nil && r.Gradient == nil && r.Pattern == nil && (r.LineStyle.Color == nil || r.LineStyle.Width == 0) {
 		return
 	}
 
+	arcs, feats, err := r.wedges()
+	if err != nil {
+		panic(err)
+	}
+
 	var pa vg.Path
+	for i, arc := range arcs {
+		pa = pa[:0]
+
+		off := r.offsetOf(feats[i])
+		sec := Sector{Center: cen, Inner: r.Inner + off, Outer: r.Outer + off, Arc: arc}
+		sec.Path(&pa, true)
 
-	pa.Move(cen.Add(Rectangular(r.Base.Theta, r.Inner)))
-	pa.Arc(cen, r.Inner, float64(r.Base.Theta), float64(r.Base.Phi))
-	if r.Base.Phi == Clockwise*Complete || r.Base.Phi == CounterClockwise*Complete {
-		pa.Move(cen.Add(Rectangular(r.Base.Theta+r.Base.Phi, r.Outer)))
+		switch {
+		case r.Gradient != nil:
+			r.Gradient.fill(ca, sec, false)
+		case r.Color != nil:
+			ca.SetColor(r.Color)
+			ca.Fill(pa)
+		}
+		if r.Pattern != nil {
+			r.Pattern.Fill(ca, pa)
+		}
+		if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
+			if r.ArcDashes.isSet() {
+				pa = pa[:0]
+				r.ArcDashes.sectorPath(&pa, sec)
+			}
+			ca.SetLineStyle(r.ArcDashes.style(r.LineStyle))
+			ca.Stroke(pa)
+		}
+	}
+}
+
+// Arcs returns the arcs of the wedges of the Highlight. If the Phi of Base is not zero
+// or LocFeatures is empty, the single arc is Base. Otherwise the arcs of LocFeatures
+// within LocBase are ordered along the LocBase arc and joined where they overlap or are
+// separated by no more than JoinGap. An error is returned if an arc of LocFeatures cannot
+// be found, a feature of LocFeatures has zero length, or more than one wedge is formed
+// and AllowSplit is false.
+func (r *Highlight) Arcs() ([]Arc, error) {
+	arcs, _, err := r.wedges()
+	return arcs, err
+}
+
+// wedges returns the arcs of the wedges of the Highlight as described by Arcs, and the
+// first feature of LocFeatures along the LocBase arc held by each wedge. The feature of
+// a wedge given by Base is nil.
+func (r *Highlight) wedges() ([]Arc, []feat.Feature, error) {
+	if r.Base.Phi != 0 || len(r.LocFeatures) == 0 {
+		return []Arc{r.Base}, []feat.Feature{nil}, nil
+	}
+	if r.LocBase == nil {
+		return nil, nil, errors.New("rings: nil highlight location base")
 	}
-	pa.Arc(cen, r.Outer, float64(r.Base.Theta+r.Base.Phi), float64(-r.Base.Phi))
-	pa.Close()
 
-	if r.Color != nil {
-		ca.SetColor(r.Color)
-		ca.Fill(pa)
+	// Arcs are joined as fractional extents along the base arc.
+	base := r.LocBase.Arc()
+	if base.Phi == 0 {
+		return nil, nil, errors.New("rings: highlight location base has zero arc")
 	}
-	if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
-		ca.SetLineStyle(r.LineStyle)
-		ca.Stroke(pa)
+	span := math.Abs(float64(base.Phi))
+	exts := make(extents, 0, len(r.LocFeatures))
+	for _, f := range r.LocFeatures {
+		if f == nil {
+			return nil, nil, errors.New("rings: nil highlight feature")
+		}
+		if f.Len() == 0 {
+			return nil, nil, fmt.Errorf("rings: zero length highlight feature %q", f.Name())
+		}
+		arc, err := r.LocBase.ArcOf(f.Location(), f)
+		if err != nil {
+			return nil, nil, err
+		}
+		theta := arc.Theta
+		if (arc.Phi < 0) != (base.Phi < 0) {
+			theta += arc.Phi
+		}
+		start, _ := base.position(theta)
+		exts = append(exts, extent{start: start, end: start + math.Abs(float64(arc.Phi))/span, feat: f})
 	}
+	sort.Sort(exts)
+
+	const tol = 1e-9
+	join := math.Abs(float64(r.JoinGap))/span + tol
+	joined := exts[:1]
+	for _, e := range exts[1:] {
+		last := &joined[len(joined)-1]
+		if e.start-last.end <= join {
+			last.end = math.Max(last.end, e.end)
+			continue
+		}
+		joined = append(joined, e)
+	}
+	if len(joined) > 1 && !r.AllowSplit {
+		return nil, nil, fmt.Errorf("rings: highlight features form %d separate wedges", len(joined))
+	}
+
+	arcs := make([]Arc, len(joined))
+	feats := make([]feat.Feature, len(joined))
+	for i, e := range joined {
+		arcs[i] = Arc{Theta: base.Theta + Angle(e.start)*base.Phi, Phi: Angle(e.end-e.start) * base.Phi}
+		feats[i] = e.feat
+	}
+	return arcs, feats, nil
+}
+
+// offsetOf returns the radial displacement of the wedge holding f.
+func (r *Highlight) offsetOf(f feat.Feature) vg.Length {
+	if r.Offset == nil {
+		return 0
+	}
+	return r.Offset(f)
 }
 
 // XY returns the x and y coordinates of the Highlight.
 func (r *Highlight) XY() (x, y float64) { return r.X, r.Y }
 
-// Arc returns the arc of the Highlight.
-func (r *Highlight) Arc() Arc { return r.Base }
+// extent is the fractional extent of the arc of feat along a base arc.
+type extent struct {
+	start, end float64
+	feat       feat.Feature
+}
+
+type extents []extent
+
+func (e extents) Len() int           { return len(e) }
+func (e extents) Less(i, j int) bool { return e[i].start < e[j].start }
+func (e extents) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// Arc returns the arc of the Highlight. If the Highlight is specified by LocFeatures,
+// the arc spans all its wedges. If the arcs of LocFeatures cannot be resolved, Base
+// is returned.
+func (r *Highlight) Arc() Arc {
+	arcs, err := r.Arcs()
+	if err != nil {
+		return r.Base
+	}
+	first, last := arcs[0], arcs[len(arcs)-1]
+	return Arc{Theta: first.Theta, Phi: last.Theta + last.Phi - first.Theta}
+}
+
+// DrawLayer returns the drawing layer of the Highlight.
+func (r *Highlight) DrawLayer() int { return r.Layer }
 
 // Plot calls DrawAt using the Highlight's X and Y values as the drawing coordinates.
 func (r *Highlight) Plot(ca draw.Canvas, plt *plot.Plot) {
@@ -80,14 +243,26 @@ func (r *Highlight) Plot(ca draw.Canvas, plt *plot.Plot) {
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
-// GlyphBoxes returns a liberal glyphbox for the highlight rendering.
+// GlyphBoxes returns a liberal glyphbox for the highlight rendering restricted
+// to the arcs of the wedges of the highlight.
 func (r *Highlight) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	arcs, feats, err := r.wedges()
+	if err != nil {
+		arcs, feats = []Arc{r.Base}, []feat.Feature{nil}
+	}
+	var box vg.Rectangle
+	for i, arc := range arcs {
+		off := r.offsetOf(feats[i])
+		b := arc.bounds(r.Inner+off, r.Outer+off)
+		if i == 0 {
+			box = b
+		} else {
+			box = union(box, b)
+		}
+	}
 	return []plot.GlyphBox{{
-		X: plt.X.Norm(r.X),
-		Y: plt.Y.Norm(r.Y),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{-r.Outer, -r.Outer},
-			Max: vg.Point{r.Outer, r.Outer},
-		},
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: box,
 	}}
 }
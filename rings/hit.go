@@ -0,0 +1,181 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// HitTester records the geometry of rendered ring elements so that points on the
+// drawing canvas can be mapped back to the features and tracks rendered at those
+// points. A HitTester is populated by the ring types that hold it during calls to
+// their DrawAt or Plot methods.
+type HitTester struct {
+	// Tolerance specifies the maximum distance between a queried point and a
+	// curve for the point to be considered a hit on the curve.
+	Tolerance vg.Length
+
+	// mu protects the recorded geometry so that rings
+	// sharing the HitTester may be drawn concurrently.
+	mu      sync.Mutex
+	sectors []sectorRecord
+	curves  []curveRecord
+}
+
+// HitResult describes an element found at a queried point.
+type HitResult struct {
+	// Track is the ring that rendered the element.
+	Track interface{}
+
+	// Feature is the feature rendered at the queried point. Feature
+	// is nil for hits on feature associations.
+	Feature feat.Feature
+
+	// Pair is the feature association rendered at the queried point.
+	// Pair is nil for hits on features.
+	Pair Pair
+
+	// Pos is the fractional position of the queried point within the
+	// element. For features, Pos is the position within the feature's
+	// arc from the feature's start to its end. For associations, Pos is
+	// the position along the rendered curve from the first feature of
+	// the Pair to the second.
+	Pos float64
+}
+
+type sectorRecord struct {
+	track interface{}
+	f     feat.Feature
+	Sector
+}
+
+type curveRecord struct {
+	track interface{}
+	p     Pair
+	pts   []vg.Point
+	outer vg.Length
+}
+
+// AddSector records that the track rendered the feature f within the sector s.
+func (h *HitTester) AddSector(track interface{}, f feat.Feature, s Sector) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sectors = append(h.sectors, sectorRecord{track: track, f: f, Sector: s})
+}
+
+// AddCurve records that the track rendered the feature association p as the line
+// segments of pa around cen. Components of pa that are not vg.MoveComp or vg.LineComp
+// are ignored.
+func (h *HitTester) AddCurve(track interface{}, p Pair, cen vg.Point, pa vg.Path) {
+	c := curveRecord{track: track, p: p}
+	for _, comp := range pa {
+		if comp.Type != vg.MoveComp && comp.Type != vg.LineComp {
+			continue
+		}
+		c.pts = append(c.pts, comp.Pos)
+		if _, r := Polar(comp.Pos.Sub(cen)); r > c.outer {
+			c.outer = r
+		}
+	}
+	if len(c.pts) != 0 {
+		h.mu.Lock()
+		h.curves = append(h.curves, c)
+		h.mu.Unlock()
+	}
+}
+
+// Reset clears all recorded geometry from the receiver.
+func (h *HitTester) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sectors = h.sectors[:0]
+	h.curves = h.curves[:0]
+}
+
+// Hit returns the elements rendered at p, sorted outermost first. Elements with equal
+// outer radii are returned in reverse rendering order, so the element rendered on top
+// is returned first.
+func (h *HitTester) Hit(p vg.Point) []HitResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var hits []hit
+	for i := len(h.sectors) - 1; i >= 0; i-- {
+		s := h.sectors[i]
+		if pos, ok := s.Position(p); ok {
+			hits = append(hits, hit{
+				HitResult: HitResult{Track: s.track, Feature: s.f, Pos: pos},
+				outer:     s.Outer,
+			})
+		}
+	}
+	for i := len(h.curves) - 1; i >= 0; i-- {
+		c := h.curves[i]
+		if pos, ok := c.position(p, h.Tolerance); ok {
+			hits = append(hits, hit{
+				HitResult: HitResult{Track: c.track, Pair: c.p, Pos: pos},
+				outer:     c.outer,
+			})
+		}
+	}
+	sort.Stable(byOuter(hits))
+
+	if len(hits) == 0 {
+		return nil
+	}
+	res := make([]HitResult, len(hits))
+	for i, h := range hits {
+		res[i] = h.HitResult
+	}
+	return res
+}
+
+// position returns the fractional position along the curve of the point on the curve
+// closest to p and whether that point is within tol of p.
+func (c curveRecord) position(p vg.Point, tol vg.Length) (frac float64, ok bool) {
+	if len(c.pts) == 1 {
+		return 0, distance(p, c.pts[0]) <= tol
+	}
+	best := vg.Length(math.Inf(1))
+	for i := 1; i < len(c.pts); i++ {
+		t, d := nearest(p, c.pts[i-1], c.pts[i])
+		if d < best {
+			best = d
+			frac = (float64(i-1) + t) / float64(len(c.pts)-1)
+		}
+	}
+	return frac, best <= tol
+}
+
+// nearest returns the parameter of the point on the segment from a to b that is
+// closest to p, and the distance between that point and p.
+func nearest(p, a, b vg.Point) (t float64, d vg.Length) {
+	ab := b.Sub(a)
+	l2 := ab.Dot(ab)
+	if l2 != 0 {
+		t = math.Min(math.Max(float64(p.Sub(a).Dot(ab)/l2), 0), 1)
+	}
+	return t, distance(p, a.Add(ab.Scale(vg.Length(t))))
+}
+
+func distance(p, q vg.Point) vg.Length {
+	return vg.Length(math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y)))
+}
+
+type hit struct {
+	HitResult
+	outer vg.Length
+}
+
+type byOuter []hit
+
+func (h byOuter) Len() int           { return len(h) }
+func (h byOuter) Less(i, j int) bool { return h[i].outer > h[j].outer }
+func (h byOuter) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
@@ -0,0 +1,151 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Band is a cytogenetic band of a chromosome.
+type Band struct {
+	// Name is the name of the band, for example "p36.33".
+	Name string
+
+	// Start and End are the positions of the band on its chromosome.
+	Start, End int
+
+	// Stain is the Giemsa stain of the band, for example "gneg", "gpos50" or "acen".
+	Stain string
+}
+
+// DefaultStains is the default mapping from Giemsa stain to band fill color used by
+// NewIdeogram.
+var DefaultStains = map[string]color.Color{
+	"gneg":    color.Gray{0xff},
+	"gpos25":  color.Gray{3 * math.MaxUint8 / 4},
+	"gpos33":  color.Gray{2 * math.MaxUint8 / 3},
+	"gpos50":  color.Gray{math.MaxUint8 / 2},
+	"gpos66":  color.Gray{math.MaxUint8 / 3},
+	"gpos75":  color.Gray{math.MaxUint8 / 4},
+	"gpos100": color.Gray{0x0},
+	"gpos":    color.Gray{0x0},
+	"acen":    color.RGBA{R: 0xff, A: 0xff},
+	"gvar":    color.Gray{0xdc},
+	"stalk":   color.Gray{0x7f},
+}
+
+// NewIdeogram returns a Blocks rendering the chromosomes as ideograms subdivided into the
+// bands held in bands for each chromosome. Bands are filled with the color mapped from their
+// stain in stains, or DefaultStains if stains is nil. Centromeric bands, with the stain "acen",
+// are rendered as triangles narrowing towards the centromere. Chromosomes without bands are
+// rendered as a single block. The returned Blocks has a Base holding the arcs of the chromosomes
+// within base, separated by the specified gap, and so may be used as the base of other rings.
+// An error is returned if a band lies outside its chromosome or has a stain without a color.
+func NewIdeogram(chromosomes []feat.Feature, bands map[feat.Feature][]Band, stains map[string]color.Color, base Arcer, inner, outer vg.Length, gap float64) (*Blocks, error) {
+	if stains == nil {
+		stains = DefaultStains
+	}
+	var set []feat.Feature
+	for _, chr := range chromosomes {
+		bs := append([]Band(nil), bands[chr]...)
+		if len(bs) == 0 {
+			set = append(set, chr)
+			continue
+		}
+		sort.Stable(byStart(bs))
+		for i, b := range bs {
+			if b.End < b.Start {
+				return nil, errors.New("rings: inverted band")
+			}
+			if b.Start < chr.Start() || b.End > chr.End() {
+				return nil, fmt.Errorf("rings: band %s out of range of %s", b.Name, chr.Name())
+			}
+			col, ok := stains[b.Stain]
+			if !ok {
+				return nil, fmt.Errorf("rings: no color for stain %q", b.Stain)
+			}
+			f := &band{Band: b, chr: chr, color: col}
+			if b.Stain == "acen" {
+				switch {
+				case i < len(bs)-1 && bs[i+1].Stain == "acen":
+					f.taper = taperEnd
+				case i > 0 && bs[i-1].Stain == "acen":
+					f.taper = taperStart
+				case strings.HasPrefix(b.Name, "q"):
+					f.taper = taperStart
+				default:
+					f.taper = taperEnd
+				}
+			}
+			set = append(set, f)
+		}
+	}
+
+	b, err := NewGappedBlocks(chromosomes, base, inner, outer, gap)
+	if err != nil {
+		return nil, err
+	}
+	b.Set = set
+	return b, nil
+}
+
+type byStart []Band
+
+func (b byStart) Len() int           { return len(b) }
+func (b byStart) Less(i, j int) bool { return b[i].Start < b[j].Start }
+func (b byStart) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// taper specifies the end of an arc at which a centromeric band narrows.
+type taper int
+
+const (
+	noTaper taper = iota
+	taperStart
+	taperEnd
+)
+
+// band is a feat.Feature representation of a Band within an ideogram.
+type band struct {
+	Band
+	chr   feat.Feature
+	color color.Color
+	taper taper
+}
+
+func (b *band) Start() int             { return b.Band.Start }
+func (b *band) End() int               { return b.Band.End }
+func (b *band) Len() int               { return b.Band.End - b.Band.Start }
+func (b *band) Name() string           { return b.Band.Name }
+func (b *band) Description() string    { return b.Stain }
+func (b *band) Location() feat.Feature { return b.chr }
+func (b *band) FillColor() color.Color { return b.color }
+
+// SectorPath appends the outline of the band to pa. Centromeric bands are outlined as a
+// triangle with its base at the arm end of the band and its apex at the centromere.
+func (b *band) SectorPath(pa *vg.Path, s Sector) {
+	var base, apex Angle
+	switch b.taper {
+	case taperStart:
+		base, apex = s.Theta+s.Phi, s.Theta
+	case taperEnd:
+		base, apex = s.Theta, s.Theta+s.Phi
+	default:
+		s.Path(pa, false)
+		return
+	}
+	pa.Move(RectangularAt(s.Center, base, s.Inner))
+	pa.Line(RectangularAt(s.Center, apex, (s.Inner+s.Outer)/2))
+	pa.Line(RectangularAt(s.Center, base, s.Outer))
+	pa.Close()
+}
@@ -0,0 +1,92 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// InnerLabel describes labels rendered within the blocks of a Blocks ring. Each label
+// is centred on the radial and angular centre of its block, and is not rendered if it
+// does not fit within the block.
+type InnerLabel struct {
+	// Text returns the label text of the block of f. If Text is nil or returns an
+	// empty string, the block is not labelled.
+	Text func(f feat.Feature) string
+
+	// TextStyle is the style of the label text.
+	draw.TextStyle
+
+	// Placement determines the text rotation given the angle of the centre of
+	// the block. The alignment returned by Placement is ignored. If Placement
+	// is nil, Tangential is used so that labels follow the arcs of their blocks.
+	Placement TextPlacement
+
+	// MinSize, if greater than zero, is the smallest font size to which a label
+	// that does not fit within its block is shrunk before it is suppressed.
+	MinSize vg.Length
+}
+
+// label returns the text style, rotation and text of the label of the block of f
+// rendered in sec, and whether the label fits within the block. A label fits if the
+// extent of its text box along the tangent at the centre of the block is no greater
+// than the chord of the block's arc at the block's central radius, and its extent
+// along the radius is no greater than the radial thickness of the block.
+func (l InnerLabel) label(f feat.Feature, sec Sector) (sty draw.TextStyle, rot Angle, txt string, ok bool) {
+	if l.Text == nil || l.Color == nil {
+		return sty, 0, "", false
+	}
+	txt = l.Text(f)
+	if txt == "" {
+		return sty, 0, "", false
+	}
+
+	mid := sec.Theta + sec.Phi/2
+	placement := l.Placement
+	if placement == nil {
+		placement = Tangential
+	}
+	rot, _, _ = placement(mid)
+
+	w, h := TextBounds(l.TextStyle, txt)
+	sin, cos := math.Sincos(float64(rot - (mid + Complete/4)))
+	tan := math.Abs(float64(w)*cos) + math.Abs(float64(h)*sin)
+	rad := math.Abs(float64(w)*sin) + math.Abs(float64(h)*cos)
+
+	phi := math.Min(math.Abs(float64(sec.Phi)), math.Pi)
+	chord := float64(sec.Inner+sec.Outer) * math.Sin(phi/2)
+	thick := math.Abs(float64(sec.Outer - sec.Inner))
+
+	// The extents of the text box scale with the font size,
+	// so a label that does not fit is shrunk by the ratio of
+	// the space available to the space required.
+	scale := math.Min(chord/tan, thick/rad)
+	sty = l.TextStyle
+	switch {
+	case scale >= 1:
+		return sty, rot, txt, true
+	case l.MinSize > 0 && sty.Font.Size*vg.Length(scale) >= l.MinSize:
+		sty.Font.Size *= vg.Length(scale)
+		return sty, rot, txt, true
+	default:
+		return sty, rot, txt, false
+	}
+}
+
+// drawAt renders the label of the block of f rendered in sec if it fits within the
+// block.
+func (l InnerLabel) drawAt(ca draw.Canvas, f feat.Feature, sec Sector) {
+	sty, rot, txt, ok := l.label(f, sec)
+	if !ok {
+		return
+	}
+	pt := RectangularAt(sec.Center, sec.Theta+sec.Phi/2, (sec.Inner+sec.Outer)/2)
+	fillText(ca, sty, pt, rot, -0.5, -0.5, txt)
+}
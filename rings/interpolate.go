@@ -0,0 +1,237 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/graphics/bezier"
+)
+
+// Interpolation specifies how a Trace renders a trace between the scores of joined
+// adjacent features.
+type Interpolation int
+
+const (
+	// StepInterpolation renders each score as an arc across its feature,
+	// joined to the scores of adjacent features by radial lines.
+	StepInterpolation Interpolation = iota
+	// LinearInterpolation renders a trace with a radius that varies linearly
+	// with angle between the scores at the angular midpoints of the features.
+	LinearInterpolation
+	// SmoothInterpolation renders a trace as a Catmull-Rom spline through the
+	// scores at the angular midpoints of the features. The spline is clamped
+	// to the radii of the score range, so it does not overshoot the range.
+	SmoothInterpolation
+)
+
+// curveStep is the largest angle between the points used to render an interpolated
+// trace.
+const curveStep = Complete / 720
+
+// curveKnot is a point on an interpolated trace in polar coordinates with the slope
+// of the trace at the point.
+type curveKnot struct {
+	theta  Angle
+	radius vg.Length
+	slope  float64
+}
+
+// drawCurves renders the traces of scores according to the Trace's Interpolation.
+// Each run of joined adjacent scores is rendered as a curve from the start of the arc
+// of its first score to the end of the arc of its last score, passing through the
+// score radii at the angular midpoints of the arcs. A score that is not joined to
+// either neighbour is rendered as an arc. When the Trace has a Segmenter, each part
+// of a curve within the arc of a score is rendered with the style of the score.
+func (t *Trace) drawCurves(scores [][]float64, scale radialScale) {
+	var n int
+	for _, s := range scores {
+		if len(s) > n {
+			n = len(s)
+		}
+	}
+	var run []int
+	for j := 0; j < n; j++ {
+		run = run[:0]
+		for i, v := range t.values {
+			if j >= len(scores[i]) || math.IsNaN(scores[i][j]) {
+				t.drawCurve(run, scores, j, scale)
+				run = run[:0]
+				continue
+			}
+			if len(run) != 0 && (run[len(run)-1] != i-1 || !t.joins(v.Scorer, j) || !adjacent(t.values[i-1].Scorer, v.Scorer) ||
+				scorerOffset(t.offset, t.values[i-1].Scorer) != scorerOffset(t.offset, v.Scorer)) {
+				t.drawCurve(run, scores, j, scale)
+				run = run[:0]
+			}
+			run = append(run, i)
+		}
+		t.drawCurve(run, scores, j, scale)
+	}
+}
+
+// joins returns whether the jth score of f is joined to the score of the preceding
+// adjacent feature.
+func (t *Trace) joins(f Scorer, j int) bool {
+	if tj, ok := f.(TraceJoiner); ok {
+		return tj.JoinTrace(j)
+	}
+	return t.Join
+}
+
+// style returns the line style of the trace of the jth score, v.
+func (t *Trace) style(j int, v float64) draw.LineStyle {
+	if t.Segmenter != nil {
+		return t.Segmenter(v)
+	}
+	return t.LineStyles[j]
+}
+
+// drawCurve renders the jth trace of the run of joined scores with the value indexes
+// held in run.
+func (t *Trace) drawCurve(run []int, scores [][]float64, j int, scale radialScale) {
+	if len(run) == 0 {
+		return
+	}
+	scale = scale.shifted(scorerOffset(t.offset, t.values[run[0]].Scorer))
+	arcs := make([]Arc, len(run))
+	for k, i := range run {
+		arc := t.values[i].Arc
+		if arc.Phi < 0 {
+			arc.Theta, arc.Phi = arc.Theta+arc.Phi, -arc.Phi
+		}
+		arcs[k] = arc
+	}
+
+	if len(run) == 1 {
+		v := scores[run[0]][j]
+		rad, ok := scale.radius(v)
+		if !ok {
+			return
+		}
+		var pa vg.Path
+		pa.Move(RectangularAt(t.Center, arcs[0].Theta, rad))
+		pa.Arc(t.Center, rad, float64(arcs[0].Theta), float64(arcs[0].Phi))
+		t.stroke(t.style(j, v), pa)
+		return
+	}
+
+	// The knots are the start of the run, the midpoint of each arc
+	// and the end of the run. Scores outside the range of the scale
+	// are clamped to the range.
+	knots := make([]curveKnot, 0, len(run)+2)
+	for k, i := range run {
+		rad, _ := scale.radius(scores[i][j])
+		if k == 0 {
+			knots = append(knots, curveKnot{theta: arcs[k].Theta, radius: rad})
+		}
+		knots = append(knots, curveKnot{theta: arcs[k].Theta + arcs[k].Phi/2, radius: rad})
+		if k == len(run)-1 {
+			knots = append(knots, curveKnot{theta: arcs[k].Theta + arcs[k].Phi, radius: rad})
+		}
+	}
+	for k := 1; k < len(knots)-1; k++ {
+		if d := knots[k+1].theta - knots[k-1].theta; d > 0 {
+			knots[k].slope = float64(knots[k+1].radius-knots[k-1].radius) / float64(d)
+		}
+	}
+	curves := t.interpolate(knots, scale)
+
+	// Parts of the curve within the arcs of scores sharing a style
+	// are rendered as a single path.
+	var (
+		pa   vg.Path
+		sty  draw.LineStyle
+		prev draw.LineStyle
+	)
+	for k, i := range run {
+		sty = t.style(j, scores[i][j])
+		if k != 0 && !sameLineStyle(sty, prev) {
+			t.stroke(prev, pa)
+			pa = pa[:0]
+		}
+		prev = sty
+
+		// The arc of the kth score spans the part of the curve from
+		// the boundary of the arc within the curve between knots k and
+		// k+1, through knot k+1, to its boundary within the curve
+		// between knots k+1 and k+2.
+		for h := k; h < k+2; h++ {
+			from, to := 0.0, 1.0
+			if h == k && k != 0 {
+				from = knotFraction(knots[h], knots[h+1], arcs[k].Theta)
+			}
+			if h == k+1 && k != len(run)-1 {
+				to = knotFraction(knots[h], knots[h+1], arcs[k].Theta+arcs[k].Phi)
+			}
+			span := float64(knots[h+1].theta-knots[h].theta) * (to - from)
+			steps := int(math.Ceil(span / float64(curveStep)))
+			if steps < 1 {
+				steps = 1
+			}
+			if len(pa) == 0 {
+				pa.Move(t.curvePoint(curves[h], from))
+			}
+			for s := 1; s <= steps; s++ {
+				pa.Line(t.curvePoint(curves[h], from+(to-from)*float64(s)/float64(steps)))
+			}
+		}
+	}
+	t.stroke(sty, pa)
+}
+
+// interpolate returns the curves between consecutive knots according to the Trace's
+// Interpolation. The curves are cubic Bézier curves in polar coordinates, with angles
+// as X and radii as Y, whose angular control points divide the span between the knots
+// evenly, so angle varies linearly along each curve. The radial control points are
+// clamped to the radii of scale so the curves do not leave the range of scale.
+func (t *Trace) interpolate(knots []curveKnot, scale radialScale) []bezier.Curve {
+	lo, hi := scale.inner, scale.outer
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	clamp := func(r vg.Length) vg.Length {
+		return vg.Length(math.Max(float64(lo), math.Min(float64(r), float64(hi))))
+	}
+	curves := make([]bezier.Curve, len(knots)-1)
+	for k := range curves {
+		a, b := knots[k], knots[k+1]
+		d := b.theta - a.theta
+		var c1, c2 vg.Length
+		switch t.Interpolation {
+		case LinearInterpolation:
+			c1, c2 = a.radius+(b.radius-a.radius)/3, b.radius-(b.radius-a.radius)/3
+		case SmoothInterpolation:
+			c1 = clamp(a.radius + vg.Length(a.slope*float64(d)/3))
+			c2 = clamp(b.radius - vg.Length(b.slope*float64(d)/3))
+		default:
+			panic("rings: unknown trace interpolation")
+		}
+		curves[k] = bezier.New(
+			vg.Point{X: vg.Length(a.theta), Y: a.radius},
+			vg.Point{X: vg.Length(a.theta + d/3), Y: c1},
+			vg.Point{X: vg.Length(b.theta - d/3), Y: c2},
+			vg.Point{X: vg.Length(b.theta), Y: b.radius},
+		)
+	}
+	return curves
+}
+
+// knotFraction returns the fraction of the angular span from a to b at theta.
+func knotFraction(a, b curveKnot, theta Angle) float64 {
+	if b.theta == a.theta {
+		return 0
+	}
+	return float64((theta - a.theta) / (b.theta - a.theta))
+}
+
+// curvePoint returns the point at u along the polar curve c.
+func (t *Trace) curvePoint(c bezier.Curve, u float64) vg.Point {
+	p := c.Point(u)
+	return RectangularAt(t.Center, Angle(p.X), p.Y)
+}
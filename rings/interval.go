@@ -0,0 +1,166 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Interval is a ScoreRenderer that represents feature scores as a radial candlestick.
+// The scores of each Scorer are interpreted as (lo, mid, hi) or, when there are five
+// scores, as (lo, q1, mid, q3, hi). A whisker line is drawn radially through the middle
+// of the feature's arc from lo to hi and a mark is drawn across the arc at mid. When
+// five scores are given, a box from q1 to q3 is drawn under the mark. Values outside
+// the range of the Interval are clamped to the range.
+type Interval struct {
+	// Values, if not nil, returns the values to render for a Scorer in place of
+	// the Scorer's scores.
+	Values func(Scorer) []float64
+
+	// Whisker and Mark determine the line styles of the whisker and the mark.
+	// The Mark line style is also used for the outline of the box.
+	Whisker, Mark draw.LineStyle
+
+	// Fill determines the fill color of the box. If Fill is nil no fill is performed.
+	Fill color.Color
+
+	// Width is the angular width of the mark and box, centered on the middle of
+	// the feature's arc. If Width is zero, the mark and box span the feature's arc.
+	Width Angle
+
+	DrawArea draw.Canvas
+
+	Center       vg.Point
+	Inner, Outer vg.Length
+
+	Min, Max float64
+
+	// Breaks specifies intervals of score values that are excluded from the radial
+	// scale of the intervals. Values within a break are placed at the radius of the
+	// break.
+	Breaks []Break
+
+	// offset is the displacement of each Scorer given
+	// by the configuring context.
+	offset func(Scorer) vg.Length
+}
+
+// Configure is called by Scores' DrawAt method. The score range of c is ignored if
+// the Interval's Min and Max fields are not both zero.
+func (iv *Interval) Configure(c ScoreContext) {
+	iv.DrawArea = c.Canvas
+	iv.Center = c.Center
+	iv.Inner = c.Inner
+	iv.Outer = c.Outer
+	iv.offset = c.Offset
+	if iv.Max == 0 && iv.Min == 0 {
+		iv.Min = c.Min
+		iv.Max = c.Max
+	}
+}
+
+// values returns the values rendered for scorer and whether they are renderable.
+func (iv *Interval) values(scorer Scorer) ([]float64, bool) {
+	var v []float64
+	if iv.Values != nil {
+		v = iv.Values(scorer)
+	} else {
+		v = scorer.Scores()
+	}
+	if len(v) != 3 && len(v) != 5 {
+		return nil, false
+	}
+	for _, s := range v {
+		if math.IsNaN(s) {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// DataRange returns the range of the lo and hi values of scorer.
+func (iv *Interval) DataRange(scorer Scorer) (min, max float64, ok bool) {
+	v, ok := iv.values(scorer)
+	if !ok {
+		return 0, 0, false
+	}
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, s := range []float64{v[0], v[len(v)-1]} {
+		if math.IsInf(s, 0) {
+			continue
+		}
+		min = math.Min(min, s)
+		max = math.Max(max, s)
+	}
+	return min, max, min <= max
+}
+
+// radius returns the radius of v, clamped to the range of the Interval and skipping
+// its Breaks, displaced by off.
+func (iv *Interval) radius(v float64, off vg.Length) vg.Length {
+	rad, _ := newRadialScale(iv.Min, iv.Max, iv.Inner+off, iv.Outer+off, iv.Breaks).radius(v)
+	return rad
+}
+
+// CopyRenderer returns an unconfigured copy of the Interval.
+func (iv *Interval) CopyRenderer() ScoreRenderer {
+	c := *iv
+	c.DrawArea = draw.Canvas{}
+	return &c
+}
+
+// Render renders the values of scorer across the specified arc. Rendering is performed
+// eagerly.
+func (iv *Interval) Render(arc Arc, scorer Scorer) {
+	v, ok := iv.values(scorer)
+	if !ok {
+		return
+	}
+
+	off := scorerOffset(iv.offset, scorer)
+	mid := arc.Theta + arc.Phi/2
+	span := arc
+	if iv.Width != 0 {
+		span = Arc{mid - iv.Width/2, iv.Width}
+	}
+
+	var pa vg.Path
+	if iv.Whisker.Color != nil && iv.Whisker.Width != 0 {
+		pa.Move(RectangularAt(iv.Center, mid, iv.radius(v[0], off)))
+		pa.Line(RectangularAt(iv.Center, mid, iv.radius(v[len(v)-1], off)))
+		iv.DrawArea.SetLineStyle(iv.Whisker)
+		iv.DrawArea.Stroke(pa)
+	}
+
+	mark := iv.Mark.Color != nil && iv.Mark.Width != 0
+	if len(v) == 5 {
+		pa = pa[:0]
+		AnnularWedge(&pa, iv.Center, iv.radius(v[1], off), iv.radius(v[3], off), span)
+		if iv.Fill != nil {
+			iv.DrawArea.SetColor(iv.Fill)
+			iv.DrawArea.Fill(pa)
+		}
+		if mark {
+			iv.DrawArea.SetLineStyle(iv.Mark)
+			iv.DrawArea.Stroke(pa)
+		}
+	}
+
+	if mark {
+		pa = pa[:0]
+		rad := iv.radius(v[len(v)/2], off)
+		pa.Move(RectangularAt(iv.Center, span.Theta, rad))
+		pa.Arc(iv.Center, rad, float64(span.Theta), float64(span.Phi))
+		iv.DrawArea.SetLineStyle(iv.Mark)
+		iv.DrawArea.Stroke(pa)
+	}
+}
+
+// Close is a no-op.
+func (iv *Interval) Close() {}
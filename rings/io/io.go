@@ -0,0 +1,379 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package io provides functions for reading features and scores for rings plots
+// from common genomic interval file formats.
+package io
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/rings"
+)
+
+// Feature is a genomic interval read from an annotation file. Feature
+// coordinates are zero-based and half-open.
+type Feature struct {
+	// Chrom is the name of the chromosome or contig holding the feature.
+	Chrom string
+
+	// From and To are the start and end of the feature.
+	From, To int
+
+	// ID is the name of the feature.
+	ID string
+
+	// Desc is the description of the feature.
+	Desc string
+
+	// Strand is the orientation of the feature.
+	Strand feat.Orientation
+
+	// Loc is the location of the feature. Loc may be nil.
+	Loc feat.Feature
+}
+
+func (f *Feature) Start() int                    { return f.From }
+func (f *Feature) End() int                      { return f.To }
+func (f *Feature) Len() int                      { return f.To - f.From }
+func (f *Feature) Name() string                  { return f.ID }
+func (f *Feature) Description() string           { return f.Desc }
+func (f *Feature) Location() feat.Feature        { return f.Loc }
+func (f *Feature) Orientation() feat.Orientation { return f.Strand }
+
+// ValueFeature is a Feature associated with a set of score values. ValueFeature
+// satisfies the rings.Scorer interface.
+type ValueFeature struct {
+	Feature
+
+	// Values holds the scores of the feature.
+	Values []float64
+}
+
+// Scores returns the Values of the feature.
+func (f *ValueFeature) Scores() []float64 { return f.Values }
+
+// ReadBED reads features in BED format from r. At least the chrom, chromStart and
+// chromEnd fields must be present. The name and strand fields are used if present,
+// and other fields are ignored. If a line has no name field the chrom field is used
+// as the feature name. Blank lines, comment lines and track and browser lines are
+// skipped.
+//
+// The returned features have nil locations, and so are suitable for use as the
+// chromosomes of a karyotype by a rings.Blocks.
+func ReadBED(r io.Reader) ([]feat.Feature, error) {
+	var fs []feat.Feature
+	err := readLines(r, func(fields []string) error {
+		if len(fields) < 3 {
+			return fmt.Errorf("too few fields: %d", len(fields))
+		}
+		f, err := interval(fields)
+		if err != nil {
+			return err
+		}
+		f.ID = f.Chrom
+		if len(fields) > 3 {
+			f.ID = fields[3]
+		}
+		if len(fields) > 5 {
+			f.Strand, err = strand(fields[5])
+			if err != nil {
+				return err
+			}
+		}
+		fs = append(fs, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// ReadBEDGraph reads scored intervals in bedGraph format from r. Each line must hold
+// the chrom, chromStart and chromEnd fields followed by one or more score values,
+// allowing multiple score series to be rendered by renderers that render more than
+// one score per feature. The values "NaN" and "." are read as NaN. Blank lines,
+// comment lines and track and browser lines are skipped.
+//
+// The location of each feature is looked up in loc by chrom name. If loc is nil the
+// returned features have nil locations, otherwise every chrom name must be present
+// in loc.
+func ReadBEDGraph(r io.Reader, loc map[string]feat.Feature) ([]rings.Scorer, error) {
+	var fs []rings.Scorer
+	err := readLines(r, func(fields []string) error {
+		if len(fields) < 4 {
+			return fmt.Errorf("too few fields: %d", len(fields))
+		}
+		f, err := interval(fields)
+		if err != nil {
+			return err
+		}
+		f.ID = fmt.Sprintf("%s:%d-%d", f.Chrom, f.From, f.To)
+		f.Loc, err = locationOf(f.Chrom, loc)
+		if err != nil {
+			return err
+		}
+		v := &ValueFeature{Feature: *f, Values: make([]float64, len(fields)-3)}
+		for i, s := range fields[3:] {
+			v.Values[i], err = value(s)
+			if err != nil {
+				return err
+			}
+		}
+		fs = append(fs, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// ReadGFF reads features in GFF format from r. The feature name is taken from the
+// Name or ID attribute of GFF3 attributes if present, and otherwise from the feature
+// field, and the feature description is the source field. GFF coordinates are
+// converted to zero-based half-open coordinates. Blank lines and comment and
+// directive lines are skipped, as is any FASTA section.
+//
+// The location of each feature is looked up in loc by seqname. If loc is nil the
+// returned features have nil locations, otherwise every seqname must be present
+// in loc.
+func ReadGFF(r io.Reader, loc map[string]feat.Feature) ([]feat.Feature, error) {
+	var fs []feat.Feature
+	err := readLines(r, func(fields []string) error {
+		if len(fields) < 7 {
+			return fmt.Errorf("too few fields: %d", len(fields))
+		}
+		start, err := position(fields[3])
+		if err != nil {
+			return err
+		}
+		end, err := position(fields[4])
+		if err != nil {
+			return err
+		}
+		if start < 1 || end < start {
+			return fmt.Errorf("invalid interval: %d-%d", start, end)
+		}
+		f := &Feature{
+			Chrom: fields[0],
+			From:  start - 1,
+			To:    end,
+			ID:    fields[2],
+			Desc:  fields[1],
+		}
+		f.Strand, err = strand(fields[6])
+		if err != nil {
+			return err
+		}
+		if len(fields) > 8 {
+			if name, ok := gffName(fields[8]); ok {
+				f.ID = name
+			}
+		}
+		f.Loc, err = locationOf(f.Chrom, loc)
+		if err != nil {
+			return err
+		}
+		fs = append(fs, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// ReadKaryotype reads chromosomes and their cytogenetic bands from r. The input may be
+// a Circos karyotype file, with "chr" lines describing chromosomes and "band" lines
+// describing bands, or a UCSC cytoBand file with chrom, chromStart, chromEnd, name and
+// gieStain fields. For Circos karyotypes the chromosome's name is its label and bands
+// must follow their parent chromosome's definition. For cytoBand files chromosomes are
+// created in the order they are first seen, extending to the end of their last band.
+// The returned chromosomes have nil locations and may be passed with the bands to
+// rings.NewIdeogram.
+func ReadKaryotype(r io.Reader) (chromosomes []feat.Feature, bands map[feat.Feature][]rings.Band, err error) {
+	ids := make(map[string]*Feature)
+	bands = make(map[feat.Feature][]rings.Band)
+	err = readLines(r, func(fields []string) error {
+		switch {
+		case fields[0] == "chr":
+			// chr - ID LABEL START END COLOR
+			if len(fields) < 6 {
+				return fmt.Errorf("too few fields: %d", len(fields))
+			}
+			f, err := interval([]string{fields[2], fields[4], fields[5]})
+			if err != nil {
+				return err
+			}
+			if _, ok := ids[f.Chrom]; ok {
+				return fmt.Errorf("duplicate chromosome: %q", f.Chrom)
+			}
+			f.ID = fields[3]
+			ids[f.Chrom] = f
+			chromosomes = append(chromosomes, f)
+
+		case fields[0] == "band":
+			// band PARENT NAME LABEL START END COLOR
+			if len(fields) < 7 {
+				return fmt.Errorf("too few fields: %d", len(fields))
+			}
+			chr, ok := ids[fields[1]]
+			if !ok {
+				return fmt.Errorf("unknown chromosome: %q", fields[1])
+			}
+			b, err := interval([]string{fields[1], fields[4], fields[5]})
+			if err != nil {
+				return err
+			}
+			bands[chr] = append(bands[chr], rings.Band{Name: fields[2], Start: b.From, End: b.To, Stain: fields[6]})
+
+		default:
+			// chrom chromStart chromEnd name gieStain
+			if len(fields) < 5 {
+				return fmt.Errorf("too few fields: %d", len(fields))
+			}
+			b, err := interval(fields)
+			if err != nil {
+				return err
+			}
+			chr, ok := ids[b.Chrom]
+			if !ok {
+				chr = &Feature{Chrom: b.Chrom, ID: b.Chrom}
+				ids[b.Chrom] = chr
+				chromosomes = append(chromosomes, chr)
+			}
+			if b.To > chr.To {
+				chr.To = b.To
+			}
+			bands[chr] = append(bands[chr], rings.Band{Name: fields[3], Start: b.From, End: b.To, Stain: fields[4]})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return chromosomes, bands, nil
+}
+
+// readLines calls fn with the tab or space separated fields of each line of r,
+// skipping blank, comment, track and browser lines. Reading stops at a GFF
+// FASTA directive. Errors returned by fn are annotated with the line number.
+func readLines(r io.Reader, fn func(fields []string) error) error {
+	sc := bufio.NewScanner(r)
+	var line int
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "##FASTA" {
+			break
+		}
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		var fields []string
+		if strings.Contains(text, "\t") {
+			fields = strings.Split(text, "\t")
+		} else {
+			fields = strings.Fields(text)
+		}
+		if fields[0] == "track" || fields[0] == "browser" {
+			continue
+		}
+		err := fn(fields)
+		if err != nil {
+			return fmt.Errorf("io: line %d: %v", line, err)
+		}
+	}
+	return sc.Err()
+}
+
+// interval returns a Feature holding the chrom, chromStart and chromEnd fields.
+func interval(fields []string) (*Feature, error) {
+	start, err := position(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	end, err := position(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid interval: %d-%d", start, end)
+	}
+	return &Feature{Chrom: fields[0], From: start, To: end}, nil
+}
+
+func position(s string) (int, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid position: %q", s)
+	}
+	return p, nil
+}
+
+func locationOf(chrom string, loc map[string]feat.Feature) (feat.Feature, error) {
+	if loc == nil {
+		return nil, nil
+	}
+	l, ok := loc[chrom]
+	if !ok {
+		return nil, fmt.Errorf("unknown location: %q", chrom)
+	}
+	return l, nil
+}
+
+func strand(s string) (feat.Orientation, error) {
+	switch s {
+	case "+":
+		return feat.Forward, nil
+	case "-":
+		return feat.Reverse, nil
+	case ".", "?":
+		return feat.NotOriented, nil
+	}
+	return 0, fmt.Errorf("invalid strand: %q", s)
+}
+
+func value(s string) (float64, error) {
+	if s == "." {
+		return math.NaN(), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %q", s)
+	}
+	return v, nil
+}
+
+// gffName returns the value of the Name attribute of a GFF3 attribute field, or
+// of the ID attribute if no Name attribute is present.
+func gffName(attr string) (name string, ok bool) {
+	var id string
+	for _, a := range strings.Split(attr, ";") {
+		kv := strings.SplitN(strings.TrimSpace(a), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v, err := url.PathUnescape(kv[1])
+		if err != nil {
+			v = kv[1]
+		}
+		switch kv[0] {
+		case "Name":
+			return v, true
+		case "ID":
+			id = v
+		}
+	}
+	return id, id != ""
+}
@@ -0,0 +1,234 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/rings"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestReadBED(c *check.C) {
+	fs, err := ReadBED(strings.NewReader(`track name=karyotype
+# comment
+
+chr1	0	1000
+chr2	0	500	second	0	-
+`))
+	c.Assert(err, check.Equals, nil)
+	c.Check(fs, check.DeepEquals, []feat.Feature{
+		&Feature{Chrom: "chr1", From: 0, To: 1000, ID: "chr1"},
+		&Feature{Chrom: "chr2", From: 0, To: 500, ID: "second", Strand: feat.Reverse},
+	})
+
+	for _, t := range []struct {
+		in  string
+		err string
+	}{
+		{in: "chr1\t0\n", err: "io: line 1: too few fields: 2"},
+		{in: "chr1\t0\t10\nchr1\tx\t10\n", err: `io: line 2: invalid position: "x"`},
+		{in: "chr1\t10\t0\n", err: "io: line 1: invalid interval: 10-0"},
+		{in: "chr1\t0\t10\ta\t0\tx\n", err: `io: line 1: invalid strand: "x"`},
+	} {
+		_, err = ReadBED(strings.NewReader(t.in))
+		c.Check(err, check.ErrorMatches, regexp.QuoteMeta(t.err))
+	}
+}
+
+func (s *S) TestReadBEDGraph(c *check.C) {
+	chr := &Feature{Chrom: "chr1", From: 0, To: 1000, ID: "chr1"}
+	loc := map[string]feat.Feature{"chr1": chr}
+	fs, err := ReadBEDGraph(strings.NewReader(`chr1 0 100 1.5
+chr1 100 200 2 .
+`), loc)
+	c.Assert(err, check.Equals, nil)
+	c.Assert(len(fs), check.Equals, 2)
+
+	c.Check(fs[0].(*ValueFeature).Feature, check.DeepEquals, Feature{Chrom: "chr1", From: 0, To: 100, ID: "chr1:0-100", Loc: chr})
+	c.Check(fs[0].Scores(), check.DeepEquals, []float64{1.5})
+	c.Check(fs[1].Location(), check.Equals, feat.Feature(chr))
+	sc := fs[1].Scores()
+	c.Assert(len(sc), check.Equals, 2)
+	c.Check(sc[0], check.Equals, 2.)
+	c.Check(math.IsNaN(sc[1]), check.Equals, true)
+
+	_, err = ReadBEDGraph(strings.NewReader("chr1 0 100 1\nchr2 0 100 1\n"), loc)
+	c.Check(err, check.ErrorMatches, regexp.QuoteMeta(`io: line 2: unknown location: "chr2"`))
+	_, err = ReadBEDGraph(strings.NewReader("chr1 0 100 1 x\n"), loc)
+	c.Check(err, check.ErrorMatches, regexp.QuoteMeta(`io: line 1: invalid value: "x"`))
+	_, err = ReadBEDGraph(strings.NewReader("chr1 0 100\n"), loc)
+	c.Check(err, check.ErrorMatches, regexp.QuoteMeta("io: line 1: too few fields: 3"))
+
+	var _ rings.Scorer = (*ValueFeature)(nil)
+}
+
+func (s *S) TestReadGFF(c *check.C) {
+	chr := &Feature{Chrom: "chr1", From: 0, To: 1000, ID: "chr1"}
+	fs, err := ReadGFF(strings.NewReader(`##gff-version 3
+chr1	src	gene	1	100	.	+	.	ID=g1;Name=gene%3B1
+chr1	src	exon	11	20	.	-	.	Parent=g1
+chr1	src	CDS	21	30	.	.	0	ID=cds1
+##FASTA
+>chr1
+ACGT
+`), map[string]feat.Feature{"chr1": chr})
+	c.Assert(err, check.Equals, nil)
+	c.Check(fs, check.DeepEquals, []feat.Feature{
+		&Feature{Chrom: "chr1", From: 0, To: 100, ID: "gene;1", Desc: "src", Strand: feat.Forward, Loc: chr},
+		&Feature{Chrom: "chr1", From: 10, To: 20, ID: "exon", Desc: "src", Strand: feat.Reverse, Loc: chr},
+		&Feature{Chrom: "chr1", From: 20, To: 30, ID: "cds1", Desc: "src", Loc: chr},
+	})
+
+	_, err = ReadGFF(strings.NewReader("chr1\tsrc\tgene\t0\t100\t.\t+\t.\n"), nil)
+	c.Check(err, check.ErrorMatches, regexp.QuoteMeta("io: line 1: invalid interval: 0-100"))
+}
+
+func (s *S) TestReadKaryotype(c *check.C) {
+	chr, bands, err := ReadKaryotype(strings.NewReader(`chr - hs1 1 0 1000 chr1
+chr - hs2 2 0 500 chr2
+band hs1 p1 p1 0 400 gneg
+band hs1 p11 p11 400 500 acen
+band hs2 q1 q1 0 500 gpos50
+`))
+	c.Assert(err, check.Equals, nil)
+	c.Check(chr, check.DeepEquals, []feat.Feature{
+		&Feature{Chrom: "hs1", From: 0, To: 1000, ID: "1"},
+		&Feature{Chrom: "hs2", From: 0, To: 500, ID: "2"},
+	})
+	c.Check(bands, check.DeepEquals, map[feat.Feature][]rings.Band{
+		chr[0]: {{Name: "p1", Start: 0, End: 400, Stain: "gneg"}, {Name: "p11", Start: 400, End: 500, Stain: "acen"}},
+		chr[1]: {{Name: "q1", Start: 0, End: 500, Stain: "gpos50"}},
+	})
+
+	chr, bands, err = ReadKaryotype(strings.NewReader("chr1\t0\t2300000\tp36.33\tgneg\n" +
+		"chr1\t2300000\t5400000\tp36.32\tgpos25\n" +
+		"chr2\t0\t4400000\tp25.3\tgneg\n"))
+	c.Assert(err, check.Equals, nil)
+	c.Check(chr, check.DeepEquals, []feat.Feature{
+		&Feature{Chrom: "chr1", From: 0, To: 5400000, ID: "chr1"},
+		&Feature{Chrom: "chr2", From: 0, To: 4400000, ID: "chr2"},
+	})
+	c.Check(bands[chr[0]], check.DeepEquals, []rings.Band{
+		{Name: "p36.33", Start: 0, End: 2300000, Stain: "gneg"},
+		{Name: "p36.32", Start: 2300000, End: 5400000, Stain: "gpos25"},
+	})
+
+	_, _, err = ReadKaryotype(strings.NewReader("chr - hs1 1 0 1000 chr1\nband hs3 p1 p1 0 400 gneg\n"))
+	c.Check(err, check.ErrorMatches, regexp.QuoteMeta(`io: line 2: unknown chromosome: "hs3"`))
+}
+
+func (s *S) TestReadLinks(c *check.C) {
+	chr1 := &Feature{Chrom: "chr1", From: 0, To: 1000, ID: "chr1"}
+	chr2 := &Feature{Chrom: "chr2", From: 0, To: 1000, ID: "chr2"}
+	loc := map[string]feat.Feature{"chr1": chr1, "chr2": chr2}
+
+	for _, t := range []struct {
+		in     string
+		format LinkFormat
+		links  []*Link
+		err    string
+	}{
+		{
+			in: `l1 chr1 10 20 color=red,value=2
+l1 chr2 30 40 color=red,value=2
+l2 chr1 50 60
+l2 chr3 70 80
+l3 chr2 90 100
+l3 chr1 110 120 thickness=2
+`,
+			format: CircosLinks,
+			links: []*Link{
+				{
+					ID: "l1",
+					Ends: [2]*Feature{
+						{Chrom: "chr1", From: 10, To: 20, ID: "l1", Loc: chr1},
+						{Chrom: "chr2", From: 30, To: 40, ID: "l1", Loc: chr2},
+					},
+					Value:   2,
+					Color:   "red",
+					Options: map[string]string{"color": "red", "value": "2"},
+				},
+				{
+					ID: "l3",
+					Ends: [2]*Feature{
+						{Chrom: "chr2", From: 90, To: 100, ID: "l3", Loc: chr2},
+						{Chrom: "chr1", From: 110, To: 120, ID: "l3", Loc: chr1},
+					},
+					Value:   math.NaN(),
+					Options: map[string]string{"thickness": "2"},
+				},
+			},
+			err: `io: dropped links to unknown locations: chr3 (1)`,
+		},
+		{
+			in:     "l1 chr1 10 20\nl1 chr3 30 40\n",
+			format: CircosLinks | Strict,
+			err:    `io: line 2: unknown location: "chr3"`,
+		},
+		{
+			in:     "l1 chr1 10 20\nl2 chr1 30 40\nl2 chr2 30 40\n",
+			format: CircosLinks,
+			err:    `io: link "l1" has only one end`,
+		},
+		{
+			in: "chr1\t10\t20\tchr2\t30\t40\tp1\t5\t+\t-\tcolor=blue\n" +
+				"chr1\t10\t20\tchr2\t30\t40\n" +
+				"chr4\t10\t20\tchr3\t30\t40\n",
+			format: BEDPE,
+			links: []*Link{
+				{
+					ID: "p1",
+					Ends: [2]*Feature{
+						{Chrom: "chr1", From: 10, To: 20, ID: "p1", Strand: feat.Forward, Loc: chr1},
+						{Chrom: "chr2", From: 30, To: 40, ID: "p1", Strand: feat.Reverse, Loc: chr2},
+					},
+					Value:   5,
+					Color:   "blue",
+					Options: map[string]string{"color": "blue"},
+				},
+				{
+					Ends: [2]*Feature{
+						{Chrom: "chr1", From: 10, To: 20, Loc: chr1},
+						{Chrom: "chr2", From: 30, To: 40, Loc: chr2},
+					},
+					Value: math.NaN(),
+				},
+			},
+			err: `io: dropped links to unknown locations: chr3 (1), chr4 (1)`,
+		},
+		{
+			in:     "chr1\t10\t20\tchr2\t30\n",
+			format: BEDPE | Strict,
+			err:    `io: line 1: too few fields: 5`,
+		},
+	} {
+		links, err := ReadLinks(strings.NewReader(t.in), loc, t.format)
+		if t.err == "" {
+			c.Check(err, check.Equals, nil)
+		} else {
+			c.Check(err, check.ErrorMatches, regexp.QuoteMeta(t.err))
+		}
+		c.Assert(len(links), check.Equals, len(t.links))
+		for i, l := range links {
+			got, want := l.(*Link), t.links[i]
+			c.Check(math.IsNaN(got.Value), check.Equals, math.IsNaN(want.Value))
+			got.Value, want.Value = 0, 0
+			c.Check(got, check.DeepEquals, want)
+		}
+	}
+}
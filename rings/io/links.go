@@ -0,0 +1,241 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/rings"
+)
+
+// LinkFormat is a flag type used to specify the format of link files read by ReadLinks.
+type LinkFormat uint
+
+const (
+	// CircosLinks specifies the Circos two-line link format, where each link is described
+	// by two lines with a shared link ID:
+	//  id chrom start end [options]
+	CircosLinks LinkFormat = iota
+
+	// BEDPE specifies the BEDPE format:
+	//  chrom1 start1 end1 chrom2 start2 end2 [name [score [strand1 strand2 [options]]]]
+	BEDPE
+
+	// Strict specifies that links to unknown locations are an error. Strict may be
+	// combined with a format using a bitwise OR.
+	Strict LinkFormat = 1 << 8
+)
+
+// Link is a rings.Pair read from a link file.
+type Link struct {
+	// ID is the name of the link.
+	ID string
+
+	// Ends holds the two features joined by the link.
+	Ends [2]*Feature
+
+	// Value is the value of the link. Value is NaN if no value was given.
+	Value float64
+
+	// Color is the color specified for the link, or the empty string if no color
+	// was given. Color is not interpreted.
+	Color string
+
+	// Options holds the key=value options given for the link.
+	Options map[string]string
+}
+
+// Features returns the features joined by the link.
+func (l *Link) Features() [2]feat.Feature { return [2]feat.Feature{l.Ends[0], l.Ends[1]} }
+
+// UnknownLocationError is the error returned by ReadLinks when links in a non-strict
+// read refer to locations that are not present in the location map. The map holds
+// the number of link ends referring to each unknown location name.
+type UnknownLocationError map[string]int
+
+func (e UnknownLocationError) Error() string {
+	names := make([]string, 0, len(e))
+	for n := range e {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for i, n := range names {
+		names[i] = fmt.Sprintf("%s (%d)", n, e[n])
+	}
+	return fmt.Sprintf("io: dropped links to unknown locations: %s", strings.Join(names, ", "))
+}
+
+// ReadLinks reads links in the specified format from r. Link values and colors are
+// taken from the "value" and "color" options of the options field, and for BEDPE
+// input the value is the score field when it is given. Blank lines, comment lines
+// and track and browser lines are skipped.
+//
+// The location of each link end is looked up in loc by chromosome name. If loc is nil
+// the returned links have nil locations. If format includes the Strict flag, a link
+// to a location not present in loc is an error, otherwise such links are dropped and
+// the returned error is an UnknownLocationError counting the references to each
+// unknown location, with the remaining links returned.
+func ReadLinks(r io.Reader, loc map[string]feat.Feature, format LinkFormat) ([]rings.Pair, error) {
+	strict := format&Strict != 0
+	format &^= Strict
+	if format != CircosLinks && format != BEDPE {
+		return nil, errors.New("io: unknown link format")
+	}
+
+	var (
+		links   []rings.Pair
+		unknown = make(UnknownLocationError)
+
+		// pending holds the first line of incomplete Circos links.
+		pending = make(map[string]*Link)
+		order   []*Link
+		dropped = make(map[string]bool)
+	)
+	end := func(fields []string) (*Feature, bool, error) {
+		f, err := interval(fields)
+		if err != nil {
+			return nil, false, err
+		}
+		f.Loc, err = locationOf(f.Chrom, loc)
+		if err != nil {
+			if strict {
+				return nil, false, err
+			}
+			return f, false, nil
+		}
+		return f, true, nil
+	}
+	err := readLines(r, func(fields []string) error {
+		switch format {
+		case CircosLinks:
+			if len(fields) < 4 {
+				return fmt.Errorf("too few fields: %d", len(fields))
+			}
+			id := fields[0]
+			f, ok, err := end(fields[1:4])
+			if err != nil {
+				return err
+			}
+			f.ID = id
+			if !ok {
+				unknown[f.Chrom]++
+				dropped[id] = true
+			}
+			l, seen := pending[id]
+			if !seen {
+				l = &Link{ID: id, Ends: [2]*Feature{f}, Value: math.NaN()}
+				pending[id] = l
+				order = append(order, l)
+			} else {
+				if l.Ends[1] != nil {
+					return fmt.Errorf("link %q has more than two ends", id)
+				}
+				l.Ends[1] = f
+			}
+			if len(fields) > 4 {
+				err = l.parseOptions(fields[4])
+			}
+			return err
+
+		case BEDPE:
+			if len(fields) < 6 {
+				return fmt.Errorf("too few fields: %d", len(fields))
+			}
+			l := &Link{Value: math.NaN()}
+			var drop bool
+			for i := range l.Ends {
+				f, ok, err := end(fields[i*3 : i*3+3])
+				if err != nil {
+					return err
+				}
+				if !ok {
+					unknown[f.Chrom]++
+					drop = true
+				}
+				l.Ends[i] = f
+			}
+			if len(fields) > 6 && fields[6] != "." {
+				l.ID = fields[6]
+				l.Ends[0].ID = l.ID
+				l.Ends[1].ID = l.ID
+			}
+			if len(fields) > 7 && fields[7] != "." {
+				v, err := value(fields[7])
+				if err != nil {
+					return err
+				}
+				l.Value = v
+			}
+			if len(fields) > 9 {
+				for i, s := range fields[8:10] {
+					var err error
+					l.Ends[i].Strand, err = strand(s)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			if len(fields) > 10 {
+				if err := l.parseOptions(fields[10]); err != nil {
+					return err
+				}
+			}
+			if !drop {
+				links = append(links, l)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range order {
+		if l.Ends[1] == nil {
+			return nil, fmt.Errorf("io: link %q has only one end", l.ID)
+		}
+		if !dropped[l.ID] {
+			links = append(links, l)
+		}
+	}
+	if len(unknown) != 0 {
+		return links, unknown
+	}
+	return links, nil
+}
+
+// parseOptions parses a comma separated list of key=value options into the
+// Options of the link, setting the link's Value and Color when those options
+// are present.
+func (l *Link) parseOptions(opts string) error {
+	for _, opt := range strings.Split(opts, ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid option: %q", opt)
+		}
+		if l.Options == nil {
+			l.Options = make(map[string]string)
+		}
+		l.Options[kv[0]] = kv[1]
+		switch kv[0] {
+		case "value":
+			v, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				return fmt.Errorf("invalid value: %q", kv[1])
+			}
+			l.Value = v
+		case "color":
+			l.Color = kv[1]
+		}
+	}
+	return nil
+}
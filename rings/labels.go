@@ -7,6 +7,9 @@ package rings
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
@@ -27,6 +30,20 @@ type Label string
 // Label returns the string used to label a feature.
 func (l Label) Label() string { return string(l) }
 
+// StyledString is a run of text rendered with its own text style.
+type StyledString struct {
+	Text string
+	draw.TextStyle
+}
+
+// RichLabeler is a Labeler that provides its label as a sequence of styled runs.
+// The runs are rendered in order on a single baseline; runs with a zero font size
+// are rendered with the style of the Labels.
+type RichLabeler interface {
+	Labeler
+	RichLabel() []StyledString
+}
+
 type locater interface {
 	Labeler
 	location() feat.Feature
@@ -75,6 +92,61 @@ type Labels struct {
 	// nil, DefaultPlacement is used.
 	Placement TextPlacement
 
+	// ExactAnchor specifies that labels are placed according to the measured
+	// glyph bounds of their text, from the ascent of the first line to the
+	// descent of the last, rather than by the alignment returned by Placement.
+	// The bounds, rotated as returned by Placement, are centered on the radial
+	// line through the label's anchor with their nearest edge at the anchor
+	// radius, so that labels sit at the same distance from the anchor radius
+	// at every angle.
+	ExactAnchor bool
+
+	// UseOrientation specifies that labels of features are rendered according to
+	// the orientations of the labeled features. The orientation of a feature is its
+	// orientation relative to the base; features that are not feat.Orienters and
+	// labels that do not label a feature are treated as forward.
+	UseOrientation bool
+
+	// Prefix and Suffix hold the text added before and after the labels of forward
+	// features, at index 0, and of reverse features, at index 1, when UseOrientation
+	// is true.
+	Prefix, Suffix [2]string
+
+	// ReversePlacement determines the text rotation and alignment of the labels of
+	// reverse features when UseOrientation is true. If ReversePlacement is nil,
+	// Placement is used.
+	ReversePlacement TextPlacement
+
+	// Priority, if not nil, returns the priority of the label of a feature.
+	// When Priority is not nil or either of Max and MaxPerLocation is positive,
+	// labels are considered in order of descending priority, with labels not
+	// labelling a feature considered last, and a label is rendered only if its
+	// bounds do not overlap those of an already accepted label and the maximum
+	// counts have not been reached. If Priority is nil, labels are considered
+	// in the order they are held in Labels.
+	Priority func(feat.Feature) float64
+
+	// Max, if positive, is the maximum number of labels rendered.
+	Max int
+
+	// MaxPerLocation, if positive, is the maximum number of labels of the
+	// features of each location that are rendered.
+	MaxPerLocation int
+
+	// SkipFiltered specifies that labels of features held by locations removed
+	// by the keep filter of a Blocks Base, set by the BlockKeep option, are not
+	// rendered. SkipFiltered is set by NewLabels and NewLabelsWith when base maps
+	// arcs through a Blocks with a keep filter.
+	SkipFiltered bool
+
+	// placed holds the features labelled by the most recent rendering. mu
+	// protects placed so that the Labels may be drawn concurrently.
+	mu     sync.Mutex
+	placed []feat.Feature
+
+	// Layer specifies the drawing layer of the Labels when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
@@ -85,6 +157,13 @@ type Labels struct {
 // not renderable. If base is an XYer, the returned base XY values are used to populate the Labels' X
 // and Y fields.
 func NewLabels(base Arcer, r vg.Length, ls ...Labeler) (*Labels, error) {
+	return NewLabelsWith(base, r, ls)
+}
+
+// NewLabelsWith returns a Labels based on the parameters in the same way as NewLabels and then
+// applies the provided options in order. An error is returned if the labels are not renderable
+// or an option fails.
+func NewLabelsWith(base Arcer, r vg.Length, ls []Labeler, opts ...LabelOption) (*Labels, error) {
 	var b ArcOfer
 	switch base := base.(type) {
 	case ArcOfer:
@@ -98,7 +177,7 @@ func NewLabels(base Arcer, r vg.Length, ls ...Labeler) (*Labels, error) {
 			default:
 				_, err = base.ArcOf(nil, nil)
 			}
-			if err != nil {
+			if err != nil && !isFiltered(base, labelled(l)) {
 				return nil, err
 			}
 		}
@@ -114,87 +193,461 @@ func NewLabels(base Arcer, r vg.Length, ls ...Labeler) (*Labels, error) {
 	if xy, ok := base.(XYer); ok {
 		x, y = xy.XY()
 	}
-	return &Labels{
-		Labels: ls,
-		Base:   b,
-		Radius: r,
-		X:      x,
-		Y:      y,
-	}, nil
+	l := &Labels{
+		Labels:       ls,
+		Base:         b,
+		Radius:       r,
+		SkipFiltered: filtering(b),
+		X:            x,
+		Y:            y,
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
 }
 
 // DrawAt renders the text of a Labels at cen in the specified drawing area,
 // according to the Labels configuration.
 func (r *Labels) DrawAt(ca draw.Canvas, cen vg.Point) {
-	for _, l := range r.Labels {
+	lay, err := r.layout()
+	if err != nil {
+		panic(fmt.Sprint("rings: no arc for feature location:", err))
+	}
+	var placed []feat.Feature
+	for _, p := range lay {
+		pt := cen.Add(p.pt)
+		switch {
+		case r.ExactAnchor && p.runs != nil:
+			fillRunsAnchored(ca, pt, p.angle, p.rot, p.runs)
+		case r.ExactAnchor:
+			fillTextAnchored(ca, p.sty, pt, p.angle, p.rot, r.text(p.label))
+		case p.runs != nil:
+			fillRuns(ca, pt, p.rot, p.xalign, p.yalign, p.runs)
+		default:
+			fillText(ca, p.sty, pt, p.rot, p.xalign, p.yalign, r.text(p.label))
+		}
+		if f := labelled(p.label); f != nil {
+			placed = append(placed, f)
+		}
+	}
+	r.mu.Lock()
+	r.placed = placed
+	r.mu.Unlock()
+}
+
+// Placed returns the features labelled by the most recent call to DrawAt or Plot, in
+// the order they were accepted. Labels that do not label a feature are not included.
+// The returned slice is a copy and may be modified by the caller.
+func (r *Labels) Placed() []feat.Feature {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]feat.Feature(nil), r.placed...)
+}
+
+// labelLayout is the placement of a rendered label relative to the centre of its Labels.
+type labelLayout struct {
+	index int
+	label Labeler
+	sty   draw.TextStyle
+	runs  []StyledString
+
+	pt             vg.Point
+	angle, rot     Angle
+	xalign, yalign float64
+}
+
+// bounds returns the bounds of the placed label relative to the centre of its Labels.
+func (p labelLayout) bounds(r *Labels) vg.Rectangle {
+	var w, h vg.Length
+	switch {
+	case r.ExactAnchor && p.runs != nil:
+		var ascent, descent vg.Length
+		w, ascent, descent = richInkBounds(p.runs)
+		h = ascent + descent
+	case r.ExactAnchor:
+		w, h = inkBounds(p.sty, r.text(p.label))
+	case p.runs != nil:
+		w, h = RichBounds(p.runs)
+	default:
+		w, h = TextBounds(p.sty, r.text(p.label))
+	}
+	pt, xalign, yalign := p.pt, p.xalign, p.yalign
+	if r.ExactAnchor {
+		pt, xalign, yalign = anchoredCenter(p.pt, p.angle, p.rot, w, h), -0.5, -0.5
+	}
+	tb := rotatedBox(w, h, p.rot, xalign, yalign)
+	return vg.Rectangle{Min: pt.Add(tb.Min), Max: pt.Add(tb.Max)}
+}
+
+// layout returns the placements of the labels of the Labels that are rendered. Labels
+// are placed in the order they are held unless they are pruned, in which case they are
+// placed in the order they are accepted. Labels whose arc cannot be found are not placed
+// and the first such error is returned.
+func (r *Labels) layout() ([]labelLayout, error) {
+	var (
+		lay   []labelLayout
+		first error
+	)
+	for i, l := range r.Labels {
+		if r.SkipFiltered && isFiltered(r.Base, labelled(l)) {
+			continue
+		}
 		var sty draw.TextStyle
 		if ts, ok := l.(TextStyler); ok {
 			sty = ts.TextStyle()
 		} else {
 			sty = r.TextStyle
 		}
-		if sty.Color == nil || sty.Font.Size == 0 {
+		runs := r.runs(l, sty)
+		if runs == nil && (sty.Color == nil || sty.Font.Size == 0) {
+			continue
+		}
+		angle, err := r.angleOf(l)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
 			continue
 		}
+		rot, xalign, yalign := r.placement(l, angle)
+		lay = append(lay, labelLayout{
+			index: i,
+			label: l,
+			sty:   sty,
+			runs:  runs,
 
-		var (
-			arc Arc
-			err error
-		)
-		switch l := l.(type) {
-		case locater:
-			arc, err = r.Base.ArcOf(l.location().Location(), l.location())
-		case feat.Feature:
-			arc, err = r.Base.ArcOf(l.Location(), l)
-		default:
-			arc, err = r.Base.ArcOf(nil, nil)
+			pt:     Rectangular(angle, r.Radius+r.offsetOf(l)),
+			angle:  angle,
+			rot:    rot,
+			xalign: xalign,
+			yalign: yalign,
+		})
+	}
+	if !r.prunes() {
+		return lay, first
+	}
+	return r.prune(lay), first
+}
+
+// prunes returns whether the labels of the Labels are pruned by priority and count.
+func (r *Labels) prunes() bool {
+	return r.Priority != nil || r.Max > 0 || r.MaxPerLocation > 0
+}
+
+// prune returns the highest priority subset of lay with non-overlapping bounds, subject
+// to the Max and MaxPerLocation limits of the Labels, in order of acceptance.
+func (r *Labels) prune(lay []labelLayout) []labelLayout {
+	pri := make([]float64, len(lay))
+	for i, p := range lay {
+		f := labelled(p.label)
+		switch {
+		case f == nil:
+			pri[i] = math.Inf(-1)
+		case r.Priority != nil:
+			pri[i] = r.Priority(f)
 		}
-		if err != nil {
-			panic(fmt.Sprint("rings: no arc for feature location:", err))
+	}
+	sort.Stable(byLabelPriority{lay, pri})
+
+	var (
+		accepted []labelLayout
+		boxes    []vg.Rectangle
+		perLoc   = make(map[feat.Feature]int)
+	)
+	for _, p := range lay {
+		if r.Max > 0 && len(accepted) >= r.Max {
+			break
+		}
+		var loc feat.Feature
+		if f := labelled(p.label); f != nil {
+			loc = f.Location()
+		}
+		if r.MaxPerLocation > 0 && perLoc[loc] >= r.MaxPerLocation {
+			continue
+		}
+		b := p.bounds(r)
+		if overlapsAny(b, boxes) {
+			continue
 		}
+		accepted = append(accepted, p)
+		boxes = append(boxes, b)
+		perLoc[loc]++
+	}
+	return accepted
+}
 
-		angle := arc.Theta + arc.Phi/2
-		pt := cen.Add(Rectangular(angle, r.Radius))
-		var (
-			rot            Angle
-			xalign, yalign float64
-		)
-		if r.Placement == nil {
-			rot, xalign, yalign = DefaultPlacement(angle)
-		} else {
-			rot, xalign, yalign = r.Placement(angle)
-		}
-		if rot != 0 {
-			ca.Push()
-			ca.Translate(pt)
-			ca.Rotate(float64(rot))
-			ca.Translate(vg.Point{-pt.X, -pt.Y})
-			ca.FillText(sty, pt, xalign, yalign, l.Label())
-			ca.Pop()
-		} else {
-			ca.FillText(sty, pt, xalign, yalign, l.Label())
+// byLabelPriority sorts label layouts by descending priority.
+type byLabelPriority struct {
+	lay []labelLayout
+	pri []float64
+}
+
+func (l byLabelPriority) Len() int           { return len(l.lay) }
+func (l byLabelPriority) Less(i, j int) bool { return l.pri[i] > l.pri[j] }
+func (l byLabelPriority) Swap(i, j int) {
+	l.lay[i], l.lay[j] = l.lay[j], l.lay[i]
+	l.pri[i], l.pri[j] = l.pri[j], l.pri[i]
+}
+
+// labelled returns the feature labelled by l, or nil if l does not label a feature.
+func labelled(l Labeler) feat.Feature {
+	switch l := l.(type) {
+	case locater:
+		return l.location()
+	case feat.Feature:
+		return l
+	}
+	return nil
+}
+
+// fillText fills txt at pt rotated by rot about pt with the given style and alignment.
+// The lines of a multi-line txt are stacked at the line height of sty and the block
+// of lines is aligned as a whole, with each line aligned horizontally within it.
+func fillText(ca draw.Canvas, sty draw.TextStyle, pt vg.Point, rot Angle, xalign, yalign float64, txt string) {
+	rotateAbout(ca, pt, rot, func() { fillLines(ca, sty, pt, xalign, yalign, txt) })
+}
+
+// fillLines fills the lines of txt at pt with the given style and alignment.
+func fillLines(ca draw.Canvas, sty draw.TextStyle, pt vg.Point, xalign, yalign float64, txt string) {
+	lines := strings.Split(strings.TrimRight(txt, "\n"), "\n")
+	if len(lines) == 1 {
+		ca.FillText(sty, pt, xalign, yalign, lines[0])
+		return
+	}
+	h := lineHeight(sty)
+	bottom := pt.Y + vg.Length(yalign)*vg.Length(len(lines))*h
+	for i, l := range lines {
+		ca.FillText(sty, vg.Point{X: pt.X, Y: bottom + vg.Length(len(lines)-1-i)*h}, xalign, 0, l)
+	}
+}
+
+// fillRuns fills the styled runs at pt rotated by rot about pt with the given
+// alignment. The runs are placed sequentially on a common baseline and aligned as
+// a single block. Runs with a zero font size are not rendered.
+func fillRuns(ca draw.Canvas, pt vg.Point, rot Angle, xalign, yalign float64, runs []StyledString) {
+	w, h := RichBounds(runs)
+	var descent vg.Length
+	for _, s := range runs {
+		if s.Font.Size == 0 {
+			continue
+		}
+		if d := -s.Font.Extents().Descent; d > descent {
+			descent = d
+		}
+	}
+	rotateAbout(ca, pt, rot, func() {
+		fillRunsFrom(ca, vg.Point{X: pt.X + vg.Length(xalign)*w, Y: pt.Y + vg.Length(yalign)*h + descent}, runs)
+	})
+}
+
+// fillRunsFrom fills the styled runs sequentially on the baseline starting at p. Runs
+// with a zero font size are not rendered.
+func fillRunsFrom(ca draw.Canvas, p vg.Point, runs []StyledString) {
+	for _, s := range runs {
+		if s.Font.Size == 0 {
+			continue
+		}
+		if s.Color != nil && s.Text != "" {
+			ca.SetColor(s.Color)
+			ca.FillString(s.Font, p, s.Text)
+		}
+		p.X += s.Font.Width(s.Text)
+	}
+}
+
+// anchoredCenter returns the center of a w by h box rotated by rot that is centered on
+// the ray from pt in the direction dir with its nearest edge, measured along the ray,
+// at pt.
+func anchoredCenter(pt vg.Point, dir, rot Angle, w, h vg.Length) vg.Point {
+	sin, cos := math.Sincos(float64(dir - rot))
+	return RectangularAt(pt, dir, (vg.Length(math.Abs(cos))*w+vg.Length(math.Abs(sin))*h)/2)
+}
+
+// fillTextAnchored fills txt rotated by rot with the given style so that the glyph
+// bounds of txt, from the ascent of its first line to the descent of its last, are
+// centered on the ray from pt in the direction dir with their nearest edge, measured
+// along the ray, at pt. The lines of a multi-line txt are centered within the bounds.
+func fillTextAnchored(ca draw.Canvas, sty draw.TextStyle, pt vg.Point, dir, rot Angle, txt string) {
+	txt = strings.TrimRight(txt, "\n")
+	if txt == "" {
+		return
+	}
+	w, h := inkBounds(sty, txt)
+	c := anchoredCenter(pt, dir, rot, w, h)
+	e := sty.Font.Extents()
+	rotateAbout(ca, c, rot, func() {
+		ca.SetColor(sty.Color)
+		y := c.Y + h/2 - e.Ascent
+		for _, l := range strings.Split(txt, "\n") {
+			ca.FillString(sty.Font, vg.Point{X: c.X - sty.Font.Width(l)/2, Y: y}, l)
+			y -= e.Height
+		}
+	})
+}
+
+// fillRunsAnchored fills the styled runs rotated by rot on a common baseline so that
+// the glyph bounds of the runs are centered on the ray from pt in the direction dir
+// with their nearest edge, measured along the ray, at pt. Runs with a zero font size
+// are not rendered.
+func fillRunsAnchored(ca draw.Canvas, pt vg.Point, dir, rot Angle, runs []StyledString) {
+	w, ascent, descent := richInkBounds(runs)
+	c := anchoredCenter(pt, dir, rot, w, ascent+descent)
+	rotateAbout(ca, c, rot, func() {
+		fillRunsFrom(ca, vg.Point{X: c.X - w/2, Y: c.Y + (descent-ascent)/2}, runs)
+	})
+}
+
+// rotateAbout calls fn with ca rotated by rot about pt.
+func rotateAbout(ca draw.Canvas, pt vg.Point, rot Angle, fn func()) {
+	if rot == 0 {
+		fn()
+		return
+	}
+	ca.Push()
+	ca.Translate(pt)
+	ca.Rotate(float64(rot))
+	ca.Translate(vg.Point{-pt.X, -pt.Y})
+	fn()
+	ca.Pop()
+}
+
+// angleOf returns the angle of the mid point of the arc labeled by l.
+func (r *Labels) angleOf(l Labeler) (Angle, error) {
+	var (
+		arc Arc
+		err error
+	)
+	switch l := l.(type) {
+	case locater:
+		arc, err = r.Base.ArcOf(l.location().Location(), l.location())
+	case feat.Feature:
+		arc, err = r.Base.ArcOf(l.Location(), l)
+	default:
+		arc, err = r.Base.ArcOf(nil, nil)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return arc.Theta + arc.Phi/2, nil
+}
+
+// offsetOf returns the radial displacement of the label l given by the Labels' Base.
+func (r *Labels) offsetOf(l Labeler) vg.Length {
+	switch l := l.(type) {
+	case locater:
+		return offsetOf(r.Base, l.location().Location(), l.location())
+	case feat.Feature:
+		return offsetOf(r.Base, l.Location(), l)
+	}
+	return 0
+}
+
+// orientation returns the orientation of the feature labeled by l.
+func (r *Labels) orientation(l Labeler) feat.Orientation {
+	switch l := l.(type) {
+	case locater:
+		return orientationOf(l.location())
+	case feat.Feature:
+		return orientationOf(l)
+	}
+	return feat.Forward
+}
+
+// text returns the text of the label l.
+func (r *Labels) text(l Labeler) string {
+	if !r.UseOrientation {
+		return l.Label()
+	}
+	i := 0
+	if r.orientation(l) == feat.Reverse {
+		i = 1
+	}
+	return r.Prefix[i] + l.Label() + r.Suffix[i]
+}
+
+// runs returns the styled runs of the label l with runs of zero font size given the
+// style sty, or nil if l is not a RichLabeler.
+func (r *Labels) runs(l Labeler, sty draw.TextStyle) []StyledString {
+	rl, ok := l.(RichLabeler)
+	if !ok {
+		return nil
+	}
+	var runs []StyledString
+	for _, s := range rl.RichLabel() {
+		if s.Font.Size == 0 {
+			s.TextStyle = sty
 		}
+		runs = append(runs, s)
+	}
+	if !r.UseOrientation {
+		return runs
+	}
+	i := 0
+	if r.orientation(l) == feat.Reverse {
+		i = 1
+	}
+	return append(append([]StyledString{{Text: r.Prefix[i], TextStyle: sty}}, runs...), StyledString{Text: r.Suffix[i], TextStyle: sty})
+}
+
+// placement returns the text rotation and alignment for the label l at the given angle.
+func (r *Labels) placement(l Labeler, angle Angle) (rot Angle, xalign, yalign float64) {
+	if r.UseOrientation && r.ReversePlacement != nil && r.orientation(l) == feat.Reverse {
+		return r.ReversePlacement(angle)
 	}
+	if r.Placement == nil {
+		return DefaultPlacement(angle)
+	}
+	return r.Placement(angle)
 }
 
+// DrawLayer returns the drawing layer of the Labels.
+func (r *Labels) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Labels' X and Y values as the drawing coordinates.
 func (r *Labels) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
-// GlyphBoxes returns a liberal glyphbox for the label rendering.
+// GlyphBoxes returns a liberal glyphbox for the label rendering restricted to the
+// base arc, including the measured extents of the label text.
 func (r *Labels) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	box := vg.Rectangle{
+		Min: vg.Point{-r.Radius, -r.Radius},
+		Max: vg.Point{r.Radius, r.Radius},
+	}
+	if r.Base != nil {
+		box = r.Base.Arc().bounds(r.Radius, r.Radius)
+	}
+	lay, _ := r.layout()
+	for _, p := range lay {
+		box = union(box, p.bounds(r))
+	}
 	return []plot.GlyphBox{{
-		X: plt.X.Norm(r.X),
-		Y: plt.Y.Norm(r.Y),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{-r.Radius, -r.Radius},
-			Max: vg.Point{r.Radius, r.Radius},
-		},
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: box,
 	}}
 }
 
+// Extent returns the bounding box of the measured label text relative to the
+// center of the Labels.
+func (r *Labels) Extent() vg.Rectangle {
+	var box vg.Rectangle
+	lay, _ := r.layout()
+	for i, p := range lay {
+		if i == 0 {
+			box = p.bounds(r)
+		} else {
+			box = union(box, p.bounds(r))
+		}
+	}
+	return box
+}
+
 // TextPlacement is used to determine text rotation and alignment by a Labels ring.
 type TextPlacement func(Angle) (rot Angle, xadjust, yadjust float64)
 
@@ -0,0 +1,110 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Recommended drawing layers. Ring types have a zero Layer by default, placing them
+// in the DataLayer. Layers between the recommended values may be used to order
+// renderings within a layer.
+const (
+	BackgroundLayer = -10 // Highlights, grids and other backgrounds.
+	DataLayer       = 0   // Blocks, scores, links and other data renderings.
+	AnnotationLayer = 10  // Markers, scales and other annotations.
+	LabelLayer      = 20  // Labels and text.
+)
+
+// Layerer is a type that can define its drawing layer.
+type Layerer interface {
+	DrawLayer() int
+}
+
+// Layered is a plot.Plotter that renders a collection of plotters in order of their
+// drawing layers rather than the order in which they were added. Layerer plotters
+// are rendered in ascending order of their layers and plotters in the same layer are
+// rendered in the order they are held in Plotters. Plotters that are not Layerers
+// keep their positions in the rendering order.
+type Layered struct {
+	Plotters []plot.Plotter
+}
+
+// NewLayered returns a Layered holding the provided plotters.
+func NewLayered(ps ...plot.Plotter) *Layered {
+	return &Layered{Plotters: ps}
+}
+
+// Add adds the provided plotters to the Layered.
+func (r *Layered) Add(ps ...plot.Plotter) {
+	r.Plotters = append(r.Plotters, ps...)
+}
+
+// Sorted returns the Layered's plotters in rendering order.
+func (r *Layered) Sorted() []plot.Plotter {
+	var (
+		sorted = make([]plot.Plotter, len(r.Plotters))
+		slots  []int
+		layers byLayer
+	)
+	for i, p := range r.Plotters {
+		sorted[i] = p
+		if l, ok := p.(Layerer); ok {
+			slots = append(slots, i)
+			layers = append(layers, l)
+		}
+	}
+	sort.Stable(layers)
+	for i, l := range layers {
+		sorted[slots[i]] = l.(plot.Plotter)
+	}
+	return sorted
+}
+
+type byLayer []Layerer
+
+func (l byLayer) Len() int           { return len(l) }
+func (l byLayer) Less(i, j int) bool { return l[i].DrawLayer() < l[j].DrawLayer() }
+func (l byLayer) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// Plot calls Plot on each of the Layered's plotters in rendering order.
+func (r *Layered) Plot(ca draw.Canvas, plt *plot.Plot) {
+	for _, p := range r.Sorted() {
+		p.Plot(ca, plt)
+	}
+}
+
+// DataRange returns the union of the data ranges of the Layered's plotters that are
+// plot.DataRangers.
+func (r *Layered) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = math.Inf(1), math.Inf(-1)
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+	for _, p := range r.Plotters {
+		dr, ok := p.(plot.DataRanger)
+		if !ok {
+			continue
+		}
+		x0, x1, y0, y1 := dr.DataRange()
+		xmin, xmax = math.Min(xmin, x0), math.Max(xmax, x1)
+		ymin, ymax = math.Min(ymin, y0), math.Max(ymax, y1)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// GlyphBoxes returns the glyph boxes of the Layered's plotters that are
+// plot.GlyphBoxers.
+func (r *Layered) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	var boxes []plot.GlyphBox
+	for _, p := range r.Plotters {
+		if gb, ok := p.(plot.GlyphBoxer); ok {
+			boxes = append(boxes, gb.GlyphBoxes(plt)...)
+		}
+	}
+	return boxes
+}
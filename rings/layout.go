@@ -0,0 +1,177 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// layoutJSON is the JSON encoding of a Layout.
+type layoutJSON struct {
+	Base       ArcGeometry        `json:"base"`
+	Arcs       []namedArcGeometry `json:"arcs"`
+	Collisions []string           `json:"collisions,omitempty"`
+}
+
+// namedArcGeometry is the JSON representation of the Arc of a named feature.
+type namedArcGeometry struct {
+	Name string `json:"name"`
+	ArcGeometry
+}
+
+// SaveLayout writes the JSON encoding of the base arc of the Blocks and the arcs of
+// its features to w. The saved layout may be restored by LoadLayout to render rings
+// of other plots with the same angular layout.
+//
+// The JSON encoding of a layout has the form:
+//
+//	{
+//	 "base": {"theta": number, "phi": number},
+//	 "arcs": [{"name": string, "theta": number, "phi": number}, ...],
+//	 "collisions": [string, ...]
+//	}
+//
+// Features are identified by name. If more than one feature of the Blocks has the
+// same name, only the arc of the first is saved and the name is listed in collisions.
+// The Blocks' Zooms and Offset are not saved.
+func (r *Blocks) SaveLayout(w io.Writer) error {
+	l := layoutJSON{Base: arcGeometry(r.Arc())}
+	seen := make(map[string]bool)
+	for _, f := range r.Set {
+		name := f.Name()
+		if seen[name] {
+			l.Collisions = append(l.Collisions, name)
+			continue
+		}
+		seen[name] = true
+		arc, err := r.ArcOf(f, nil)
+		if err != nil {
+			return err
+		}
+		l.Arcs = append(l.Arcs, namedArcGeometry{Name: name, ArcGeometry: arcGeometry(arc)})
+	}
+	return json.NewEncoder(w).Encode(l)
+}
+
+// Layout is an ArcOfer that maps features to arcs by feature name. A Layout is
+// restored from a layout saved by Blocks.SaveLayout using LoadLayout.
+type Layout struct {
+	// Base represents the complete span of the Layout.
+	Base Arc
+
+	// Arcs provides a lookup for features within the span by name.
+	Arcs map[string]Arc
+
+	// Boundary specifies how features extending beyond the end
+	// of their location are mapped to arcs.
+	Boundary BoundaryPolicy
+
+	// Warnings holds problems found when the layout was saved and when features
+	// were matched against the layout by Match.
+	Warnings []error
+}
+
+// LoadLayout returns the Layout encoded in r by Blocks.SaveLayout. Name collisions
+// found when the layout was saved are reported in the returned Layout's Warnings.
+func LoadLayout(r io.Reader) (*Layout, error) {
+	var l layoutJSON
+	err := json.NewDecoder(r).Decode(&l)
+	if err != nil {
+		return nil, err
+	}
+	lay := &Layout{
+		Base: Arc{Theta: Angle(l.Base.Theta), Phi: Angle(l.Base.Phi)},
+		Arcs: make(map[string]Arc, len(l.Arcs)),
+	}
+	for _, a := range l.Arcs {
+		lay.Arcs[a.Name] = Arc{Theta: Angle(a.Theta), Phi: Angle(a.Phi)}
+	}
+	for _, name := range l.Collisions {
+		lay.Warnings = append(lay.Warnings, fmt.Errorf("rings: layout name collision for %q", name))
+	}
+	return lay, nil
+}
+
+// Match checks the provided features against the Layout, adding a warning to the
+// Layout's Warnings for each feature name in the Layout that is not found in fs
+// and for each name shared by more than one feature in fs. Missing names are
+// reported in sorted order.
+func (l *Layout) Match(fs []feat.Feature) {
+	count := make(map[string]int)
+	for _, f := range fs {
+		name := f.Name()
+		count[name]++
+		if count[name] == 2 {
+			l.Warnings = append(l.Warnings, fmt.Errorf("rings: feature name collision for %q", name))
+		}
+	}
+	var missing []string
+	for name := range l.Arcs {
+		if count[name] == 0 {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		l.Warnings = append(l.Warnings, fmt.Errorf("rings: layout feature %q not found", name))
+	}
+}
+
+// Arc returns the base arc of the Layout.
+func (l *Layout) Arc() Arc { return l.Base }
+
+// ArcOf returns the arc of a feature in the context of the provided location. The
+// behaviour of ArcOf follows that of Arcs.ArcOf, with features found by name.
+func (l *Layout) ArcOf(loc, f feat.Feature) (Arc, error) {
+	var q feat.Feature
+	switch {
+	case loc != nil && f != nil:
+		if !contains(loc, f) {
+			return arcNaN, errors.New("rings: location is not parent of feature")
+		}
+		if f.Start() < loc.Start() || f.Start() > loc.End() {
+			return arcNaN, errors.New("rings: feature out of range")
+		}
+		fe, err := l.Boundary.end(loc, f)
+		if err != nil {
+			return arcNaN, err
+		}
+		if fa, ok := l.containingArcOf(loc); ok {
+			min, max := loc.Start(), loc.End()
+
+			scale := fa.Phi / Angle(max-min)
+			start, end := Angle(f.Start()-min)*scale, Angle(fe-min)*scale
+
+			return Arc{start + fa.Theta, end - start}, nil
+		}
+		return arcNaN, errors.New("rings: location not found")
+	case f != nil:
+		q = f
+	case loc != nil:
+		q = loc
+	default:
+		return l.Base, nil
+	}
+	if fa, ok := l.containingArcOf(q); ok {
+		return fa, nil
+	}
+	return arcNaN, errors.New("rings: location not found")
+}
+
+func (l *Layout) containingArcOf(f feat.Feature) (Arc, bool) {
+	for q := f; q != nil; q = q.Location() {
+		arc, ok := l.Arcs[q.Name()]
+		if ok {
+			return arc, ok
+		}
+	}
+	return arcNaN, false
+}
@@ -5,8 +5,10 @@
 package rings
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"image/color"
 	"math"
 
 	"github.com/gonum/plot"
@@ -27,6 +29,18 @@ type Links struct {
 	// Radii indicates the distance of the ribbon end points from the center of the plot.
 	Radii [2]vg.Length
 
+	// SkipFiltered specifies that links with an end held by a location removed
+	// by the keep filter of a Blocks end, set by the BlockKeep option, are not
+	// rendered. SkipFiltered is set by NewLinks when either end maps arcs through
+	// a Blocks with a keep filter.
+	SkipFiltered bool
+
+	// ToOther specifies that a link with a single end held by a location removed
+	// by the keep filter of a Blocks end is rendered to the middle of the Other
+	// stub of the Blocks when SkipFiltered is true. Links with both ends filtered,
+	// or a filtered end of a Blocks without an Other stub, are not rendered.
+	ToOther bool
+
 	// Bezier describes the Bézier configuration for link rendering.
 	Bezier *Bezier
 
@@ -34,156 +48,593 @@ type Links struct {
 	// is over-ridden if the Pair describing features is a LineStyler.
 	LineStyle draw.LineStyle
 
+	// LineStyleFunc, if not nil, is called once for each Pair that is not a LineStyler
+	// when the Links is drawn and the returned style is used in place of LineStyle.
+	// It allows the width and color of each link to depend on a value associated
+	// with the Pair. Pairs given a style with a nil color or zero width are not drawn.
+	LineStyleFunc func(Pair) draw.LineStyle
+
+	// EndGlyph determines the glyph drawn at each end of each rendered link. Glyphs
+	// are drawn after all the links, so they are rendered on top of the links.
+	EndGlyph draw.GlyphStyle
+
+	// EndGlyphFunc, if not nil, is called for each end of each rendered link and the
+	// returned glyph style is used in place of EndGlyph.
+	EndGlyphFunc func(p Pair, end int) draw.GlyphStyle
+
+	// EndGlyphTolerance, if positive, specifies that link ends at the same radius with
+	// angles within EndGlyphTolerance of an end that has already been given a glyph
+	// are not given a glyph.
+	EndGlyphTolerance Angle
+
+	// Centered specifies that each link end is placed at the middle of the arc of
+	// its feature. Otherwise link ends are placed at the start of their features.
+	Centered bool
+
+	// ClipInner and ClipOuter, if either is positive, define an annulus about the
+	// center of the plot outside of which links are not drawn. A zero ClipOuter
+	// places no limit on the outer extent of links. Links are split at the crossings
+	// of the annulus boundaries so that clipped links end on the boundary circles.
+	// End glyphs are not drawn for link ends outside the annulus.
+	ClipInner, ClipOuter vg.Length
+
+	// Caps, Joins and JoinThreshold specify the shape of the ends and corners of
+	// the links as described by StrokeStyle.
+	Caps          LineCap
+	Joins         LineJoin
+	JoinThreshold Angle
+
+	// Tolerance, if positive, is the tolerance with which the flattened Bézier
+	// curves of the stroked links are simplified by Simplify.
+	Tolerance vg.Length
+
+	// Density, if not nil, specifies that the links are rendered as a raster of
+	// link density, combined with the stroked links according to its Blend.
+	Density *DensityOptions
+
+	// Progress, if not nil, is called with the number of pairs rendered and the
+	// total number of pairs at the progress interval of the context passed to
+	// DrawAtContext, as set by WithProgressInterval, and on completion when the
+	// Links is drawn.
+	Progress func(done, total int)
+
+	// HitTester, if not nil, records the geometry of each rendered link.
+	HitTester *HitTester
+
+	// Layer specifies the drawing layer of the Links when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
 
 // NewLinks returns a Links based on the parameters, first checking that the provided features
-// are able to be rendered. An error is returned if the features are not renderable. The ends of
-// a Links ring cannot be an Arc or a Highlight.
-func NewLinks(fp []Pair, ends [2]ArcOfer, r [2]vg.Length) (*Links, error) {
+// are able to be rendered. The provided options are then applied in order. An error is returned
+// if the features are not renderable or an option fails. The ends of a Links ring cannot be an
+// Arc or a Highlight.
+func NewLinks(fp []Pair, ends [2]ArcOfer, r [2]vg.Length, opts ...LinkOption) (*Links, error) {
 	for _, p := range fp {
 		for i, f := range p.Features() {
 			if f.End() < f.Start() {
 				return nil, errors.New("rings: inverted feature")
 			}
-			if _, err := ends[i].ArcOf(nil, f); err != nil {
+			if _, err := ends[i].ArcOf(nil, f); err != nil && !isFiltered(ends[i], f) {
 				return nil, err
 			}
 		}
 	}
-	return &Links{
-		Set:   fp,
-		Ends:  ends,
-		Radii: r,
-	}, nil
+	l := &Links{
+		Set:          fp,
+		Ends:         ends,
+		Radii:        r,
+		SkipFiltered: filtering(ends[0]) || filtering(ends[1]),
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
 }
 
 // DrawAt renders the feature pairs of a Links at cen in the specified drawing area,
 // according to the Links configuration.
 func (r *Links) DrawAt(ca draw.Canvas, cen vg.Point) {
-	if len(r.Set) == 0 {
-		return
+	r.DrawAtContext(context.Background(), ca, cen)
+}
+
+// DrawAtContext renders the feature pairs of a Links at cen in the specified drawing
+// area, according to the Links configuration. Rendering is stopped and the error of
+// ctx is returned if ctx is cancelled. End glyphs are not drawn if rendering is
+// stopped.
+func (r *Links) DrawAtContext(ctx context.Context, ca draw.Canvas, cen vg.Point) error {
+	prog, err := newProgress(ctx, r.Progress, len(r.Set))
+	if err != nil || len(r.Set) == 0 {
+		return err
 	}
 
 	// Check if we have a Bézier and we want more than one segment in the curve.
 	bez := r.Bezier != nil && r.Bezier.Segments > 1
 
-	var pa vg.Path
-loop:
+	var (
+		pa   vg.Path
+		ends []linkEnd
+
+		// paths and styles hold the rendered links
+		// when the Links is rendered as a density.
+		paths  []vg.Path
+		styles []draw.LineStyle
+	)
 	for _, fp := range r.Set {
-		p := fp.Features()
-		loc := [2]feat.Feature{p[0].Location(), p[1].Location()}
-		var min, max [2]int
-		for j, l := range loc {
-			min[j] = l.Start()
-			max[j] = l.End()
-		}
-
-		var angles [2]Angle
-		for j, f := range p {
-			if f.Start() < min[j] || f.Start() > max[j] {
-				continue loop
-			}
+		if err := prog.step(); err != nil {
+			return err
+		}
+
+		sty := r.styleOf(fp)
+		if sty.Color == nil || sty.Width == 0 {
+			continue
+		}
+
+		angles, ok := r.endAngles(fp)
+		if !ok {
+			continue
+		}
 
-			arc, err := r.Ends[j].ArcOf(f.Location(), f)
-			if err != nil {
-				panic(fmt.Sprint("rings: no arc for feature location:", err))
+		radii := r.endRadii(fp)
+		for i := range angles {
+			if r.clipped() && !r.inClip(Rectangular(angles[i], radii[i])) {
+				continue
 			}
-			angles[j] = Normalize(arc.Theta)
+			ends = append(ends, linkEnd{pair: fp, end: i, angle: angles[i], radius: radii[i]})
 		}
 
 		pa = pa[:0]
-		pa.Move(cen.Add(Rectangular(angles[0], r.Radii[0])))
-		// Bézier from angles[0]@radius[0] to angles[1]@radius[1] through
-		// r.Bezier if it is not nil and we wanted more than 1 segment;
-		// otherwise straight lines.
-		if bez {
-			b := bezier.New(
-				r.Bezier.ControlPoints(angles, r.Radii)...,
-			)
-			for i := 1; i <= r.Bezier.Segments; i++ {
-				pa.Line(cen.Add(b.Point(float64(i) / float64(r.Bezier.Segments))))
+		var starts []int
+		if r.clipped() {
+			pa, starts = r.clippedPath(pa, cen, angles, radii)
+			if len(pa) == 0 {
+				continue
 			}
 		} else {
-			pa.Line(cen.Add(Rectangular(angles[1], r.Radii[1])))
+			pa.Move(RectangularAt(cen, angles[0], radii[0]))
+			// Bézier from angles[0]@radius[0] to angles[1]@radius[1] through
+			// r.Bezier if it is not nil and we wanted more than 1 segment;
+			// otherwise straight lines.
+			if bez {
+				b := bezier.New(
+					endControlPoints(r.Bezier, r.Ends, angles, radii)...,
+				)
+				for i := 1; i <= r.Bezier.Segments; i++ {
+					pa.Line(cen.Add(b.Point(float64(i) / float64(r.Bezier.Segments))))
+				}
+			} else {
+				pa.Line(RectangularAt(cen, angles[1], radii[1]))
+			}
 		}
 
-		var sty draw.LineStyle
-		if ls, ok := fp.(LineStyler); ok {
-			sty = ls.LineStyle()
+		if r.Density != nil {
+			paths = append(paths, append(vg.Path(nil), pa...))
+			styles = append(styles, sty)
 		} else {
-			sty = r.LineStyle
+			r.stroke(ca, sty, pa)
+		}
+		if r.HitTester != nil {
+			if starts == nil {
+				starts = []int{0}
+			}
+			starts = append(starts, len(pa))
+			for i := 1; i < len(starts); i++ {
+				r.HitTester.AddCurve(r, fp, cen, pa[starts[i-1]:starts[i]])
+			}
+		}
+	}
+
+	if r.Density != nil {
+		r.drawDensity(ca, paths, styles)
+	}
+
+	prog.finish()
+
+	r.drawEndGlyphs(ca, cen, ends)
+	return nil
+}
+
+// clipped returns whether the Links has a clipping annulus.
+func (r *Links) clipped() bool { return r.ClipInner > 0 || r.ClipOuter > 0 }
+
+// inClip returns whether p, relative to the center of the plot, is within the
+// clipping annulus of the Links.
+func (r *Links) inClip(p vg.Point) bool {
+	_, d := Polar(p)
+	return d >= r.ClipInner && (r.ClipOuter == 0 || d <= r.ClipOuter)
+}
+
+// clippedPath appends the sections of the link between the given ends that lie
+// within the clipping annulus of the Links to pa, returning the extended path and
+// the index of the start of each section in the path.
+func (r *Links) clippedPath(pa vg.Path, cen vg.Point, angles [2]Angle, radii [2]vg.Length) (vg.Path, []int) {
+	var (
+		b    bezier.Curve
+		segs int
+	)
+	if r.Bezier != nil && r.Bezier.Segments > 1 {
+		b = bezier.New(endControlPoints(r.Bezier, r.Ends, angles, radii)...)
+		segs = r.Bezier.Segments
+	} else {
+		b = bezier.New(Rectangular(angles[0], radii[0]), Rectangular(angles[1], radii[1]))
+		segs = 1
+	}
+
+	n := segs
+	if n < clipSamples {
+		n = clipSamples
+	}
+	var starts []int
+	for _, sec := range r.clip(b, n) {
+		starts = append(starts, len(pa))
+		pa.Move(cen.Add(sec.Point(0)))
+		for i := 1; i <= segs; i++ {
+			pa.Line(cen.Add(sec.Point(float64(i) / float64(segs))))
+		}
+	}
+	return pa, starts
+}
+
+// drawDensity renders the links held in paths with the given styles according to
+// the Links' Density configuration.
+func (r *Links) drawDensity(ca draw.Canvas, paths []vg.Path, styles []draw.LineStyle) {
+	stroke := func() {
+		for i, pa := range paths {
+			r.stroke(ca, styles[i], pa)
+		}
+	}
+	switch r.Density.Blend {
+	case DensityUnder:
+		r.Density.draw(ca, paths)
+		stroke()
+	case DensityOver:
+		stroke()
+		r.Density.draw(ca, paths)
+	default:
+		r.Density.draw(ca, paths)
+	}
+}
+
+// stroke strokes pa with sty according to the Links' cap, join and simplification
+// configuration.
+func (r *Links) stroke(ca draw.Canvas, sty draw.LineStyle, pa vg.Path) {
+	StrokeStyle{LineStyle: sty, Cap: r.Caps, Join: r.Joins, JoinThreshold: r.JoinThreshold}.Stroke(ca, Simplify(pa, r.Tolerance))
+}
+
+// clipSamples is the minimum number of intervals a link is sampled at to find its
+// crossings of the clipping annulus boundaries.
+const clipSamples = 100
+
+// clip returns the sections of b that lie within the clipping annulus of the Links.
+// Boundary crossings are found by sampling b at n intervals and bisecting each
+// interval that crosses a boundary, and b is split exactly at each crossing.
+func (r *Links) clip(b bezier.Curve, n int) []bezier.Curve {
+	var (
+		secs  []bezier.Curve
+		start float64
+		prev  float64
+		in    = r.inClip(b.Point(0))
+	)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		if r.inClip(b.Point(t)) != in {
+			x := r.crossing(b, prev, t, in)
+			if in {
+				secs = append(secs, section(b, start, x))
+			} else {
+				start = x
+			}
+			in = !in
+		}
+		prev = t
+	}
+	if in {
+		secs = append(secs, section(b, start, 1))
+	}
+	return secs
+}
+
+// crossing returns the parameter of the clipping annulus boundary crossing of b
+// between lo and hi, where the point at lo has the clipping state in.
+func (r *Links) crossing(b bezier.Curve, lo, hi float64, in bool) float64 {
+	for i := 0; i < 64 && lo < hi; i++ {
+		mid := (lo + hi) / 2
+		if mid == lo || mid == hi {
+			break
+		}
+		if r.inClip(b.Point(mid)) == in {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if in {
+		return lo
+	}
+	return hi
+}
+
+// section returns the section of b between the parameters start and end.
+func section(b bezier.Curve, start, end float64) bezier.Curve {
+	if end < 1 {
+		b, _ = b.Split(end)
+	}
+	if start > 0 {
+		_, b = b.Split(start / end)
+	}
+	return b
+}
+
+// linkEnd is the position of one end of a rendered link.
+type linkEnd struct {
+	pair   Pair
+	end    int
+	angle  Angle
+	radius vg.Length
+}
+
+// drawEndGlyphs renders the end glyphs of the link ends held in ends.
+func (r *Links) drawEndGlyphs(ca draw.Canvas, cen vg.Point, ends []linkEnd) {
+	if r.EndGlyph.Shape == nil && r.EndGlyphFunc == nil {
+		return
+	}
+	var drawn []linkEnd
+	for _, e := range ends {
+		if r.EndGlyphTolerance > 0 && e.within(drawn, r.EndGlyphTolerance) {
+			continue
+		}
+		sty := r.EndGlyph
+		if r.EndGlyphFunc != nil {
+			sty = r.EndGlyphFunc(e.pair, e.end)
+		}
+		if sty.Color == nil || sty.Shape == nil {
+			continue
+		}
+		ca.DrawGlyph(sty, RectangularAt(cen, e.angle, e.radius))
+		drawn = append(drawn, e)
+	}
+}
+
+// within returns whether any of the ends in ends is at the same radius as e
+// and within tol of the angle of e.
+func (e linkEnd) within(ends []linkEnd, tol Angle) bool {
+	for _, o := range ends {
+		if o.radius != e.radius {
+			continue
 		}
-		if sty.Color != nil && sty.Width != 0 {
-			ca.SetLineStyle(sty)
-			ca.Stroke(pa)
+		d := math.Abs(math.Remainder(float64(e.angle-o.angle), float64(Complete)))
+		if Angle(d) <= tol {
+			return true
 		}
 	}
+	return false
 }
 
+// styleOf returns the line style used to render fp.
+func (r *Links) styleOf(fp Pair) draw.LineStyle {
+	if ls, ok := fp.(LineStyler); ok {
+		return ls.LineStyle()
+	}
+	if r.LineStyleFunc != nil {
+		return r.LineStyleFunc(fp)
+	}
+	return r.LineStyle
+}
+
+// ColorByLocation returns a function suitable for use as a Links or CrossLinks
+// LineStyleFunc that colors each link with the block color of the feature at the
+// specified end of its Pair, as reported by blocks.ColorOf, and with the given alpha
+// in [0, 1]. The remaining fields of the returned style are taken from base, and
+// links whose chosen end has no block color are given the color of base. The Set of
+// blocks is indexed when ColorByLocation is called, so features later added to the
+// Set are not colored by the returned function. ColorByLocation panics if end is
+// not 0 or 1.
+func ColorByLocation(blocks *Blocks, end int, alpha float64, base draw.LineStyle) func(Pair) draw.LineStyle {
+	checkEnd(end)
+	return colorBy(blocks.colorIndex(), end, alpha, base)
+}
+
+// ColorByFeature returns a function suitable for use as a Links or CrossLinks
+// LineStyleFunc that colors each link with the color held by colors for the feature
+// at the specified end of its Pair or the nearest of its ancestor locations, for
+// example as returned by ColorsFor, and with the given alpha in [0, 1]. The remaining
+// fields of the returned style are taken from base, and links whose chosen end has no
+// color are given the color of base. ColorByFeature panics if end is not 0 or 1.
+func ColorByFeature(colors map[feat.Feature]color.Color, end int, alpha float64, base draw.LineStyle) func(Pair) draw.LineStyle {
+	checkEnd(end)
+	return colorBy(func(f feat.Feature) color.Color {
+		for ; f != nil; f = f.Location() {
+			if c, ok := colors[f]; ok {
+				return c
+			}
+		}
+		return nil
+	}, end, alpha, base)
+}
+
+// checkEnd panics if end is not the index of an end of a Pair.
+func checkEnd(end int) {
+	if end != 0 && end != 1 {
+		panic(fmt.Sprintf("rings: invalid link end: %d", end))
+	}
+}
+
+// colorBy returns a LineStyleFunc coloring each link with the color returned by
+// colorOf for the feature at the specified end of its Pair, as described by
+// ColorByLocation.
+func colorBy(colorOf func(feat.Feature) color.Color, end int, alpha float64, base draw.LineStyle) func(Pair) draw.LineStyle {
+	a := uint8(math.Floor(math.Min(math.Max(alpha, 0), 1)*0xff + 0.5))
+	return func(p Pair) draw.LineStyle {
+		sty := base
+		if c := colorOf(p.Features()[end]); c != nil {
+			sty.Color = c
+		}
+		if sty.Color != nil {
+			c := color.NRGBAModel.Convert(sty.Color).(color.NRGBA)
+			c.A = a
+			sty.Color = c
+		}
+		return sty
+	}
+}
+
+// endAngles returns the angles of the end points of a link between the features of fp.
+// The arc of a feature that is not held by the Links' Ends is interpolated within the
+// arc of its location, and the end at an ExternalAnchor is at the angle of the anchor
+// point. If either feature starts outside its location, or the link is not rendered
+// because of a filtered location, ok is returned false. endAngles panics if the
+// arc of a feature cannot be found.
+func (r *Links) endAngles(fp Pair) (angles [2]Angle, ok bool) {
+	angles, ok, err := r.arcAngles(fp)
+	if err != nil {
+		panic(fmt.Sprint("rings: no arc for feature location:", err))
+	}
+	return angles, ok
+}
+
+// arcAngles returns the angles of the end points of a link between the features of fp
+// as described for endAngles, returning a non-nil error if the arc of a feature cannot
+// be found.
+func (r *Links) arcAngles(fp Pair) (angles [2]Angle, ok bool, err error) {
+	other, ok := r.filteredEnd(fp)
+	if !ok {
+		return angles, false, nil
+	}
+	for j, f := range fp.Features() {
+		loc := f.Location()
+		if j == other {
+			f, loc = filteringBlocks(r.Ends[j]).Other, nil
+		} else if loc != nil && (f.Start() < loc.Start() || f.Start() > loc.End()) {
+			return angles, false, nil
+		}
+
+		if a, ok := anchorOf(r.Ends[j]); ok {
+			angles[j], _ = Polar(a.Point)
+			continue
+		}
+		arc, err := r.Ends[j].ArcOf(loc, f)
+		if err != nil {
+			return angles, false, err
+		}
+		if r.Centered {
+			arc.Theta += arc.Phi / 2
+		}
+		angles[j] = Normalize(arc.Theta)
+	}
+	return angles, true, nil
+}
+
+// filteredEnd returns the index of the end of fp that is rendered to the Other stub
+// of its Blocks, or -1 if neither end is, and whether the link is rendered according
+// to the SkipFiltered and ToOther fields.
+func (r *Links) filteredEnd(fp Pair) (end int, ok bool) {
+	if !r.SkipFiltered {
+		return -1, true
+	}
+	end = -1
+	for j, f := range fp.Features() {
+		if !isFiltered(r.Ends[j], f) {
+			continue
+		}
+		if !r.ToOther || end != -1 || filteringBlocks(r.Ends[j]).Other == nil {
+			return -1, false
+		}
+		end = j
+	}
+	return end, true
+}
+
+// endRadii returns the radii of the end points of a link between the features of fp,
+// displaced by the offsets of the features if the Links' Ends are Offsetters. The end
+// at an ExternalAnchor is at the distance of the anchor point from the center.
+func (r *Links) endRadii(fp Pair) [2]vg.Length {
+	radii := r.Radii
+	for j, f := range fp.Features() {
+		if a, ok := anchorOf(r.Ends[j]); ok {
+			_, radii[j] = Polar(a.Point)
+			continue
+		}
+		radii[j] += offsetOf(r.Ends[j], f.Location(), f)
+	}
+	return radii
+}
+
+// DrawLayer returns the drawing layer of the Links.
+func (r *Links) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Links' X and Y values as the drawing coordinates.
 func (r *Links) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
+// PlotContext calls DrawAtContext using the Links' X and Y values as the drawing coordinates.
+func (r *Links) PlotContext(ctx context.Context, ca draw.Canvas, plt *plot.Plot) error {
+	trX, trY := plt.Transforms(&ca)
+	return r.DrawAtContext(ctx, ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
 // GlyphBoxes returns a liberal glyphbox for the links rendering.
 func (r *Links) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 	if len(r.Set) == 0 {
 		return nil
 	}
 
-	rad := float64(r.Radii[0])
-	if float64(r.Radii[1]) > rad {
-		rad = float64(r.Radii[1])
+	rad := r.Radii[0]
+	if r.Radii[1] > rad {
+		rad = r.Radii[1]
+	}
+	for _, e := range r.Ends {
+		if a, ok := anchorOf(e); ok {
+			if _, d := Polar(a.Point); d > rad {
+				rad = d
+			}
+		}
 	}
 
 	// If draw a Bézier we need to see if the radius is increased,
 	// so we mock the drawing, just keeping a record of the furthest
 	// distance from the origin. This may change to be more conservative.
 	if r.Bezier != nil && r.Bezier.Segments > 1 {
-	loop:
 		for _, fp := range r.Set {
-			p := fp.Features()
-			loc := [2]feat.Feature{p[0].Location(), p[1].Location()}
-			var min, max [2]int
-			for j, l := range loc {
-				min[j] = l.Start()
-				max[j] = l.End()
+			angles, ok := r.endAngles(fp)
+			if !ok {
+				continue
 			}
-
-			var angles [2]Angle
-			for j, f := range p {
-				if f.Start() < min[j] || f.End() > max[j] {
-					continue loop
+			radii := r.Radii
+			for j, e := range r.Ends {
+				if a, ok := anchorOf(e); ok {
+					_, radii[j] = Polar(a.Point)
 				}
-
-				arc, err := r.Ends[j].ArcOf(f.Location(), f)
-				if err != nil {
-					panic(fmt.Sprint("rings: no arc for feature location:", err))
-				}
-				angles[j] = Normalize(arc.Theta)
 			}
 
 			b := bezier.New(
-				r.Bezier.ControlPoints(angles, r.Radii)...,
+				endControlPoints(r.Bezier, r.Ends, angles, radii)...,
 			)
 			for k := 0; k <= r.Bezier.Segments; k++ {
 				e := b.Point(float64(k) / float64(r.Bezier.Segments))
-				if d := math.Hypot(float64(e.X), float64(e.Y)); d > rad {
+				if _, d := Polar(e); d > rad {
 					rad = d
 				}
 			}
 		}
 	}
 
+	if r.ClipOuter > 0 && rad > r.ClipOuter {
+		rad = r.ClipOuter
+	}
+
 	return []plot.GlyphBox{{
 		X: plt.X.Norm(r.X),
 		Y: plt.Y.Norm(r.Y),
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{vg.Length(-rad), vg.Length(-rad)},
-			Max: vg.Point{vg.Length(rad), vg.Length(rad)},
+			Min: vg.Point{-rad, -rad},
+			Max: vg.Point{rad, rad},
 		},
 	}}
 }
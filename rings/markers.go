@@ -0,0 +1,139 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Marker is a radial line marking a position within a location.
+type Marker struct {
+	// Location and Pos specify the marked position.
+	Location feat.Feature
+	Pos      int
+
+	// LineStyle determines the line style of the marker.
+	LineStyle draw.LineStyle
+
+	// Inner and Outer define the inner and outer radii of the marker.
+	Inner, Outer vg.Length
+
+	// Label is the text of the marker's label. If Label is empty no label is drawn.
+	Label string
+}
+
+// inRange returns whether the marker's position lies within its location.
+func (m Marker) inRange() bool {
+	return m.Location != nil && m.Location.Start() <= m.Pos && m.Pos <= m.Location.End()
+}
+
+// Markers implements rendering of radial marker lines at positions within the features of
+// an ArcOfer. Marker lines may span several rings to mark a position across their tracks.
+type Markers struct {
+	// Set holds the markers to render.
+	Set []Marker
+
+	// Base defines the targets of the rendered markers. Positions are converted to
+	// angles by the Base's ArcOf method, so markers follow any non-linear mapping of
+	// positions by the Base.
+	Base ArcOfer
+
+	// TextStyle determines the text style of the marker labels.
+	TextStyle draw.TextStyle
+
+	// Placement determines the text rotation and alignment of the marker labels. If
+	// Placement is nil, DefaultPlacement is used.
+	Placement TextPlacement
+
+	// LabelOffset is the radial distance of the marker labels from the outer end of
+	// their markers.
+	LabelOffset vg.Length
+
+	// Layer specifies the drawing layer of the Markers when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewMarkers returns a Markers rendering the provided markers against the base. The
+// markers may be checked with the Markers' Validate method.
+func NewMarkers(ms []Marker, base ArcOfer) *Markers {
+	return &Markers{Set: ms, Base: base}
+}
+
+// DrawAt renders the markers of a Markers at cen in the specified drawing area,
+// according to the Markers configuration. Markers with positions outside their
+// location or without an arc in the Base are not rendered.
+func (r *Markers) DrawAt(ca draw.Canvas, cen vg.Point) {
+	var pa vg.Path
+	for _, m := range r.Set {
+		if !m.inRange() {
+			continue
+		}
+		angle, err := angleAt(r.Base, m.Location, m.Pos)
+		if err != nil {
+			continue
+		}
+
+		if m.LineStyle.Color != nil && m.LineStyle.Width != 0 {
+			pa = pa[:0]
+			pa.Move(RectangularAt(cen, angle, m.Inner))
+			pa.Line(RectangularAt(cen, angle, m.Outer))
+			ca.SetLineStyle(m.LineStyle)
+			ca.Stroke(pa)
+		}
+
+		if m.Label == "" || r.TextStyle.Color == nil || r.TextStyle.Font.Size == 0 {
+			continue
+		}
+		placement := r.Placement
+		if placement == nil {
+			placement = DefaultPlacement
+		}
+		rot, xalign, yalign := placement(angle)
+		fillText(ca, r.TextStyle, RectangularAt(cen, angle, m.Outer+r.LabelOffset), rot, xalign, yalign, m.Label)
+	}
+}
+
+// XY returns the x and y coordinates of the Markers.
+func (r *Markers) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Markers.
+func (r *Markers) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Markers' X and Y values as the drawing coordinates.
+func (r *Markers) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the markers rendering restricted to the
+// base arc. Marker labels are not included.
+func (r *Markers) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if len(r.Set) == 0 {
+		return nil
+	}
+	inner, outer := vg.Length(math.Inf(1)), vg.Length(0)
+	for _, m := range r.Set {
+		if m.Inner < inner {
+			inner = m.Inner
+		}
+		if m.Outer > outer {
+			outer = m.Outer
+		}
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(inner, outer),
+	}}
+}
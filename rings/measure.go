@@ -0,0 +1,148 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"strings"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// lineHeight returns the line spacing of text rendered with sty.
+func lineHeight(sty draw.TextStyle) vg.Length { return sty.Font.Extents().Height }
+
+// TextBounds returns the width and height of s rendered with sty, without requiring
+// a canvas. Each line of a multi-line string is measured separately; the width is
+// the width of the widest line and the height is the number of lines multiplied by
+// the line spacing of the style's font. Trailing newlines are ignored.
+func TextBounds(sty draw.TextStyle, s string) (w, h vg.Length) {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return 0, 0
+	}
+	lines := strings.Split(s, "\n")
+	for _, l := range lines {
+		if lw := sty.Font.Width(l); lw > w {
+			w = lw
+		}
+	}
+	return w, vg.Length(len(lines)) * lineHeight(sty)
+}
+
+// RichBounds returns the width and height of the styled runs rendered sequentially
+// on a single baseline. The height is the largest line height of the runs' styles.
+// Runs with a zero font size are ignored.
+func RichBounds(runs []StyledString) (w, h vg.Length) {
+	for _, s := range runs {
+		if s.Font.Size == 0 {
+			continue
+		}
+		w += s.Font.Width(s.Text)
+		if lh := lineHeight(s.TextStyle); lh > h {
+			h = lh
+		}
+	}
+	return w, h
+}
+
+// inkBounds returns the width and height of the glyph bounds of s rendered with sty.
+// The width is the width of the widest line and the height is the distance from the
+// ascent of the first line to the descent of the last, with lines spaced at the line
+// height of the style's font. Trailing newlines are ignored.
+func inkBounds(sty draw.TextStyle, s string) (w, h vg.Length) {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return 0, 0
+	}
+	lines := strings.Split(s, "\n")
+	for _, l := range lines {
+		if lw := sty.Font.Width(l); lw > w {
+			w = lw
+		}
+	}
+	e := sty.Font.Extents()
+	return w, e.Ascent - e.Descent + vg.Length(len(lines)-1)*e.Height
+}
+
+// richInkBounds returns the width of the styled runs rendered sequentially on a single
+// baseline and the greatest ascent above and descent below the baseline of the runs'
+// fonts. Runs with a zero font size are ignored.
+func richInkBounds(runs []StyledString) (w, ascent, descent vg.Length) {
+	for _, s := range runs {
+		if s.Font.Size == 0 {
+			continue
+		}
+		w += s.Font.Width(s.Text)
+		e := s.Font.Extents()
+		if e.Ascent > ascent {
+			ascent = e.Ascent
+		}
+		if -e.Descent > descent {
+			descent = -e.Descent
+		}
+	}
+	return w, ascent, descent
+}
+
+// RotatedBounds returns the bounding box of s rendered with sty with the lower left
+// corner of the text at the origin and rotated about the origin by rot.
+func RotatedBounds(sty draw.TextStyle, s string, rot Angle) vg.Rectangle {
+	return textBox(sty, s, rot, 0, 0)
+}
+
+// textBox returns the bounding box of s rendered with sty anchored at the origin with
+// the given alignment and rotated about the origin by rot, as rendered by fillText.
+func textBox(sty draw.TextStyle, s string, rot Angle, xalign, yalign float64) vg.Rectangle {
+	w, h := TextBounds(sty, s)
+	return rotatedBox(w, h, rot, xalign, yalign)
+}
+
+// rotatedBox returns the bounding box of a w by h text box anchored at the origin
+// with the given alignment and rotated about the origin by rot.
+func rotatedBox(w, h vg.Length, rot Angle, xalign, yalign float64) vg.Rectangle {
+	sin, cos := math.Sincos(float64(rot))
+	box := vg.Rectangle{
+		Min: vg.Point{X: vg.Length(math.Inf(1)), Y: vg.Length(math.Inf(1))},
+		Max: vg.Point{X: vg.Length(math.Inf(-1)), Y: vg.Length(math.Inf(-1))},
+	}
+	for _, c := range textCorners(w, h, xalign, yalign) {
+		p := vg.Point{
+			X: c.X*vg.Length(cos) - c.Y*vg.Length(sin),
+			Y: c.X*vg.Length(sin) + c.Y*vg.Length(cos),
+		}
+		box.Min.X = vg.Length(math.Min(float64(box.Min.X), float64(p.X)))
+		box.Min.Y = vg.Length(math.Min(float64(box.Min.Y), float64(p.Y)))
+		box.Max.X = vg.Length(math.Max(float64(box.Max.X), float64(p.X)))
+		box.Max.Y = vg.Length(math.Max(float64(box.Max.Y), float64(p.Y)))
+	}
+	return box
+}
+
+// textCorners returns the corners of an unrotated w by h text box anchored at the
+// origin with the given alignment.
+func textCorners(w, h vg.Length, xalign, yalign float64) [4]vg.Point {
+	return [4]vg.Point{
+		{X: vg.Length(xalign) * w, Y: vg.Length(yalign) * h},
+		{X: vg.Length(xalign+1) * w, Y: vg.Length(yalign) * h},
+		{X: vg.Length(xalign) * w, Y: vg.Length(yalign+1) * h},
+		{X: vg.Length(xalign+1) * w, Y: vg.Length(yalign+1) * h},
+	}
+}
+
+// union returns the smallest rectangle containing a and b.
+func union(a, b vg.Rectangle) vg.Rectangle {
+	return vg.Rectangle{
+		Min: vg.Point{
+			X: vg.Length(math.Min(float64(a.Min.X), float64(b.Min.X))),
+			Y: vg.Length(math.Min(float64(a.Min.Y), float64(b.Min.Y))),
+		},
+		Max: vg.Point{
+			X: vg.Length(math.Max(float64(a.Max.X), float64(b.Max.X))),
+			Y: vg.Length(math.Max(float64(a.Max.Y), float64(b.Max.Y))),
+		},
+	}
+}
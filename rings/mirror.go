@@ -0,0 +1,76 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Mirrored is a track of two score series rendered back to back about a shared
+// baseline radius. The scores of Outward are rendered from the baseline towards the
+// outer radius of the track and those of Inward are rendered from the baseline
+// towards the inner radius, on a common score range.
+type Mirrored struct {
+	// Outward renders its scores outwards from the baseline.
+	Outward *Scores
+
+	// Inward is an inverted Scores rendering its scores inwards from
+	// the baseline.
+	Inward *Scores
+
+	// Range is the score range shared by Outward and Inward, which
+	// are included in the Range by NewMirrored.
+	Range *Range
+}
+
+// NewMirrored returns a Mirrored rendering outward and inward score series between the
+// inner and outer radii with the renderers ro and ri. The baseline is placed at the
+// fraction baseline of the distance from inner to outer. The per-side line and fill
+// styles of the track are those of the renderers and the Backgrounds of the Outward
+// and Inward Scores. If ro is a Trace with an Axis, the axis is mirrored to the inner
+// radius so that its values increase in both directions from the baseline. An error
+// is returned if baseline is not within [0, 1] or either series is not renderable.
+func NewMirrored(outward, inward []Scorer, base ArcOfer, inner, outer vg.Length, baseline float64, ro, ri ScoreRenderer) (*Mirrored, error) {
+	if !(0 <= baseline && baseline <= 1) {
+		return nil, fmt.Errorf("rings: mirror baseline %v not in [0, 1]", baseline)
+	}
+	mid := inner + (outer-inner)*vg.Length(baseline)
+
+	rng := NewRange()
+	out, err := NewScores(outward, base, mid, outer, ro, SharedRange(rng))
+	if err != nil {
+		return nil, err
+	}
+	in, err := NewScores(inward, base, inner, mid, ri, SharedRange(rng))
+	if err != nil {
+		return nil, err
+	}
+	in.Invert = true
+
+	if t, ok := ro.(*Trace); ok && t.Axis != nil {
+		t.Axis.MirrorTo = inner
+	}
+	return &Mirrored{Outward: out, Inward: in, Range: rng}, nil
+}
+
+// Plotters returns the plotters rendering the Mirrored, in drawing order.
+func (r *Mirrored) Plotters() []plot.Plotter { return []plot.Plotter{r.Inward, r.Outward} }
+
+// DrawAt renders the Mirrored at cen in the specified drawing area.
+func (r *Mirrored) DrawAt(ca draw.Canvas, cen vg.Point) {
+	r.Inward.DrawAt(ca, cen)
+	r.Outward.DrawAt(ca, cen)
+}
+
+// Plot calls the Plot method of each of the Mirrored's plotters.
+func (r *Mirrored) Plot(ca draw.Canvas, plt *plot.Plot) {
+	for _, p := range r.Plotters() {
+		p.Plot(ca, plt)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// FlipRule specifies when the names of a Blocks ring are flipped so that they read
+// left to right.
+type FlipRule int
+
+const (
+	FlipBottom FlipRule = iota // FlipBottom flips names centred on the bottom half of the circle.
+	FlipNever                  // FlipNever renders all names reading clockwise.
+)
+
+// BlockNames describes the names of features rendered along the outside of the arcs of
+// the blocks of a Blocks ring. Each name is curved with its block and centred on the
+// block's arc, with each glyph placed according to its advance width.
+type BlockNames struct {
+	// TextStyle is the style of the names. If the Color of the TextStyle
+	// is nil, no names are rendered.
+	draw.TextStyle
+
+	// Offset is the radial distance between the outer radius of each
+	// block and its name.
+	Offset vg.Length
+
+	// Flip specifies when names are flipped to read left to right.
+	Flip FlipRule
+
+	// SkipWide specifies that names longer than the arc of their block are not
+	// rendered. If SkipWide is false, such names are rendered as straight
+	// tangential labels centred on the block's arc.
+	SkipWide bool
+}
+
+// height returns the radial extent of the names.
+func (n BlockNames) height() vg.Length {
+	if n.Color == nil {
+		return 0
+	}
+	return n.Offset + lineHeight(n.TextStyle)
+}
+
+// drawAt renders name along the outside of the arc of the block rendered in sec.
+func (n BlockNames) drawAt(ca draw.Canvas, sec Sector, name string) {
+	if n.Color == nil || name == "" {
+		return
+	}
+	w, h := TextBounds(n.TextStyle, name)
+	rad := sec.Outer + n.Offset + h/2
+	mid := sec.Theta + sec.Phi/2
+
+	// Names read clockwise unless they are flipped, in which
+	// case they read anticlockwise and are rotated by a half
+	// turn so that they remain upright.
+	dir, turn := Clockwise, Angle(0)
+	if n.Flip == FlipBottom && Normalize(mid) > Complete/2 {
+		dir, turn = CounterClockwise, Complete/2
+	}
+
+	if w > vg.Length(math.Abs(float64(sec.Phi)))*rad {
+		if n.SkipWide {
+			return
+		}
+		fillText(ca, n.TextStyle, RectangularAt(sec.Center, mid, rad), mid-Complete/4+turn, -0.5, -0.5, name)
+		return
+	}
+
+	theta := mid - dir*Angle(w/rad)/2
+	for _, r := range name {
+		g := string(r)
+		adv := Angle(n.Font.Width(g) / rad)
+		at := theta + dir*adv/2
+		fillText(ca, n.TextStyle, RectangularAt(sec.Center, at, rad), at-Complete/4+turn, -0.5, -0.5, g)
+		theta += dir * adv
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// NestedArcs is an ArcOfer that maps features nested within the features of a parent
+// ring to arcs within the arcs of their parents, so that the rings of a sunburst share
+// a single angular layout. The positions of a nested feature are in the coordinates of
+// its parent, and the nested feature is mapped by the parent ring as if it were held
+// by the location of its parent, or by the parent itself if the parent has no location,
+// so gaps and zooms of the parent ring apply to it. Features that are not nested are
+// mapped by the parent ring.
+type NestedArcs struct {
+	// Parent is the ring holding the parent features.
+	Parent ArcOfer
+
+	// Parents maps each nested feature to its parent.
+	Parents map[feat.Feature]feat.Feature
+
+	// Boundary specifies how nested features extending beyond the end
+	// of their parent are mapped to arcs.
+	Boundary BoundaryPolicy
+}
+
+// Arc returns the arc of the parent ring.
+func (a *NestedArcs) Arc() Arc { return a.Parent.Arc() }
+
+// ArcOf returns the arc of the parameters. A nested feature is mapped within the arc
+// of its parent, whatever its location. If loc is a nested feature, the arc of f is
+// mapped linearly across the arc of loc, so rings may be nested within the features
+// of a ring based on a NestedArcs.
+func (a *NestedArcs) ArcOf(loc, f feat.Feature) (Arc, error) {
+	switch {
+	case loc != nil && f != nil:
+		if _, ok := a.Parents[loc]; ok {
+			la, err := a.ArcOf(nil, loc)
+			if err != nil {
+				return arcNaN, err
+			}
+			return a.within(la, loc, f)
+		}
+		if p, ok := a.Parents[f]; ok {
+			return a.nested(p, f)
+		}
+	case f != nil:
+		if p, ok := a.Parents[f]; ok {
+			return a.nested(p, f)
+		}
+	case loc != nil:
+		if p, ok := a.Parents[loc]; ok {
+			return a.nested(p, loc)
+		}
+	}
+	return a.Parent.ArcOf(loc, f)
+}
+
+// nested returns the arc of f nested within its parent p.
+func (a *NestedArcs) nested(p, f feat.Feature) (Arc, error) {
+	end, err := a.end(p, f)
+	if err != nil {
+		return arcNaN, err
+	}
+	loc := p.Location()
+	if loc == nil {
+		loc = p
+	}
+	return a.Parent.ArcOf(loc, &nestedFeature{Feature: f, end: end, loc: loc})
+}
+
+// within returns the arc of f held by loc given the arc of loc, mapped linearly.
+func (a *NestedArcs) within(la Arc, loc, f feat.Feature) (Arc, error) {
+	end, err := a.end(loc, f)
+	if err != nil {
+		return arcNaN, err
+	}
+	return zoomed(la, loc, f.Start(), end, nil), nil
+}
+
+// end returns the end position of f within p according to the boundary policy.
+func (a *NestedArcs) end(p, f feat.Feature) (int, error) {
+	if f.Start() < p.Start() || f.Start() > p.End() {
+		if a.Boundary == Strict {
+			return 0, &BoundaryError{Feature: f, Location: p}
+		}
+		return 0, errors.New("rings: feature out of range")
+	}
+	return a.Boundary.end(p, f)
+}
+
+// nestedFeature is a nested feature relocated to the location of its parent.
+type nestedFeature struct {
+	feat.Feature
+	end int
+	loc feat.Feature
+}
+
+func (f *nestedFeature) End() int               { return f.end }
+func (f *nestedFeature) Len() int               { return f.end - f.Start() }
+func (f *nestedFeature) Location() feat.Feature { return f.loc }
+
+// NewNestedBlocks returns a Blocks rendering the features of children nested within the
+// arcs of their parent features held by parent, based on a NestedArcs. The positions of
+// each child are in the coordinates of its parent, and children extending beyond the end
+// of their parent are mapped according to the boundary policy of the parent's base. The
+// returned Blocks may itself be the parent of further nested Blocks. The Set of the
+// returned Blocks holds the children in the order of their parents in the Set of parent.
+// The provided options are then applied in order. An error is returned if a parent is
+// not held by parent, a child has more than one parent, or the children are not
+// renderable.
+func NewNestedBlocks(parent *Blocks, children map[feat.Feature][]feat.Feature, inner, outer vg.Length, opts ...BlockOption) (*Blocks, error) {
+	if parent == nil {
+		return nil, errors.New("rings: nil parent blocks")
+	}
+	held := make(map[feat.Feature]bool, len(parent.Set))
+	for _, f := range parent.Set {
+		held[f] = true
+	}
+	for p := range children {
+		if !held[p] {
+			return nil, fmt.Errorf("rings: nested parent %q not held by parent blocks", p.Name())
+		}
+	}
+
+	base := &NestedArcs{
+		Parent:   parent,
+		Parents:  make(map[feat.Feature]feat.Feature),
+		Boundary: boundaryOf(parent),
+	}
+	var fs []feat.Feature
+	for _, p := range parent.Set {
+		for _, c := range children[p] {
+			if q, ok := base.Parents[c]; ok {
+				return nil, fmt.Errorf("rings: nested feature %q has parents %q and %q", c.Name(), q.Name(), p.Name())
+			}
+			base.Parents[c] = p
+			fs = append(fs, c)
+		}
+	}
+	return NewBlocks(fs, base, inner, outer, opts...)
+}
@@ -0,0 +1,410 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// ScoreOption is a configuration option applied to a Scores by NewScores. An
+// option returns an error if its parameters are not valid for the Scores.
+type ScoreOption func(*Scores) error
+
+// BlockOption is a configuration option applied to a Blocks by NewBlocks and
+// NewGappedBlocks. An option returns an error if its parameters are not valid for
+// the Blocks.
+type BlockOption func(*Blocks) error
+
+// LinkOption is a configuration option applied to a Links by NewLinks. An option
+// returns an error if its parameters are not valid for the Links.
+type LinkOption func(*Links) error
+
+// LabelOption is a configuration option applied to a Labels by NewLabelsWith. An
+// option returns an error if its parameters are not valid for the Labels.
+type LabelOption func(*Labels) error
+
+// PeakOption is a configuration option applied to a PeakAnnotations by AnnotatePeaks.
+// An option returns an error if its parameters are not valid for the PeakAnnotations.
+type PeakOption func(*PeakAnnotations) error
+
+// ScoreMinMax returns a ScoreOption that sets the explicit score range of a Scores
+// to the interval from min to max. An error is returned if min is not less than max.
+func ScoreMinMax(min, max float64) ScoreOption {
+	return func(r *Scores) error {
+		if !(min < max) {
+			return fmt.Errorf("rings: score minimum %v not less than maximum %v", min, max)
+		}
+		r.Min, r.Max = min, max
+		return nil
+	}
+}
+
+// SharedRange returns a ScoreOption that renders a Scores according to the shared
+// range rng and includes the Scores in rng. An explicit range set by a preceding
+// option is discarded so that the shared range is used.
+func SharedRange(rng *Range) ScoreOption {
+	return func(r *Scores) error {
+		if rng == nil {
+			return errors.New("rings: nil shared range")
+		}
+		r.Range = rng
+		r.Min, r.Max = 0, 0
+		rng.Include(r)
+		return nil
+	}
+}
+
+// ScoreWindow returns a ScoreOption that aggregates the scores of a Scores into genomic
+// windows of size bases, as described by WindowScores. An error is returned if size is
+// not positive.
+func ScoreWindow(size int) ScoreOption {
+	return func(r *Scores) error {
+		if size <= 0 {
+			return fmt.Errorf("rings: invalid window size %d", size)
+		}
+		r.Window = size
+		return nil
+	}
+}
+
+// ScoreBackground returns a ScoreOption that sets the background color of a Scores.
+func ScoreBackground(c color.Color) ScoreOption {
+	return func(r *Scores) error {
+		r.Background = c
+		return nil
+	}
+}
+
+// ScoreBands returns a ScoreOption that sets the score bands of a Scores. An error
+// is returned if a band's Lo is greater than its Hi.
+func ScoreBands(bands ...ScoreBand) ScoreOption {
+	return func(r *Scores) error {
+		for _, b := range bands {
+			if b.Lo > b.Hi {
+				return fmt.Errorf("rings: score band low limit %v greater than high limit %v", b.Lo, b.Hi)
+			}
+		}
+		r.Bands = bands
+		return nil
+	}
+}
+
+// ScoreTitle returns a ScoreOption that sets the title of a Scores.
+func ScoreTitle(t Title) ScoreOption {
+	return func(r *Scores) error {
+		if err := t.check(); err != nil {
+			return err
+		}
+		r.Title = t
+		return nil
+	}
+}
+
+// BlockFill returns a BlockOption that sets the fill color of a Blocks.
+func BlockFill(c color.Color) BlockOption {
+	return func(r *Blocks) error {
+		r.Color = c
+		return nil
+	}
+}
+
+// BlockColors returns a BlockOption that sets the fill colors of the blocks of the
+// features held by colors, for example as returned by ColorsFor.
+func BlockColors(colors map[feat.Feature]color.Color) BlockOption {
+	return func(r *Blocks) error {
+		r.Colors = colors
+		return nil
+	}
+}
+
+// BlockGradient returns a BlockOption that fills the blocks of a Blocks with the
+// radial gradient g. An error is returned if the number of slices of g is negative.
+func BlockGradient(g GradientFill) BlockOption {
+	return func(r *Blocks) error {
+		if g.Slices < 0 {
+			return errors.New("rings: negative gradient slice count")
+		}
+		r.Gradient = &g
+		return nil
+	}
+}
+
+// BlockKeep returns a BlockOption that removes the features of a Blocks for which keep
+// returns false, so that a plot may be restricted to a subset of its locations. If other
+// is positive and a feature is removed, a stub location of length other is appended to
+// the Set and allocated an arc in place of the removed features; a stub requires that
+// the arcs of the Blocks are allocated by NewGappedBlocks. BlockKeep is the only means
+// of giving a Blocks a keep filter, since the arcs of the kept features are allocated
+// when the Blocks is constructed. An error is returned if keep is nil or other is
+// negative.
+func BlockKeep(keep func(loc feat.Feature) bool, other int) BlockOption {
+	return func(r *Blocks) error {
+		if keep == nil {
+			return errors.New("rings: nil keep function")
+		}
+		if other < 0 {
+			return errors.New("rings: negative other stub length")
+		}
+		r.keep = keep
+		r.otherLength = other
+		return nil
+	}
+}
+
+// BlockLineStyle returns a BlockOption that sets the line style of a Blocks.
+func BlockLineStyle(sty draw.LineStyle) BlockOption {
+	return func(r *Blocks) error {
+		r.LineStyle = sty
+		return nil
+	}
+}
+
+// BlockArrows returns a BlockOption that renders the blocks of oriented features
+// as arrows with heads of at most the given angle. An error is returned if head is
+// negative.
+func BlockArrows(head Angle) BlockOption {
+	return func(r *Blocks) error {
+		if head < 0 {
+			return errors.New("rings: negative arrow head angle")
+		}
+		r.Shape = Arrow
+		r.HeadAngle = head
+		return nil
+	}
+}
+
+// BlockArrowsParsed returns a BlockOption like BlockArrows with the head angle given
+// as a string parsed by ParseAngle, for example "5deg". An error is returned if head
+// is not a valid angle or is negative.
+func BlockArrowsParsed(head string) BlockOption {
+	return func(r *Blocks) error {
+		a, err := ParseAngle(head)
+		if err != nil {
+			return err
+		}
+		return BlockArrows(a)(r)
+	}
+}
+
+// BlockInnerLabel returns a BlockOption that labels each block of a Blocks with
+// the text returned by l.Text. An error is returned if l.Text is nil.
+func BlockInnerLabel(l InnerLabel) BlockOption {
+	return func(r *Blocks) error {
+		if l.Text == nil {
+			return errors.New("rings: nil inner label text function")
+		}
+		r.InnerLabel = l
+		return nil
+	}
+}
+
+// BlockZooms returns a BlockOption that sets the zoomed regions of a Blocks. An
+// error is returned if a zoom is not valid for its location or zooms within a
+// location overlap.
+func BlockZooms(zs ...Zoom) BlockOption {
+	return func(r *Blocks) error {
+		for _, z := range zs {
+			if z.Location == nil {
+				return errors.New("rings: zoom has no location")
+			}
+			if _, err := zoomsOf(zs, z.Location); err != nil {
+				return err
+			}
+		}
+		r.Zooms = zs
+		return nil
+	}
+}
+
+// BlockWeight returns a BlockOption that sets the Weight of a Blocks, allocating the
+// arcs of its features in proportion to their weights. An error is returned if weight
+// is nil or returns a weight that is not positive for a feature of the Set.
+func BlockWeight(weight func(f feat.Feature) float64) BlockOption {
+	return func(r *Blocks) error {
+		if weight == nil {
+			return errors.New("rings: nil block weight function")
+		}
+		r.Weight = weight
+		r.weights.arcs = nil
+		for _, f := range r.roots() {
+			if _, err := r.weightOf(f); err != nil {
+				r.Weight = nil
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// BlockTitle returns a BlockOption that sets the title of a Blocks.
+func BlockTitle(t Title) BlockOption {
+	return func(r *Blocks) error {
+		if err := t.check(); err != nil {
+			return err
+		}
+		r.Title = t
+		return nil
+	}
+}
+
+// LinkLineStyle returns a LinkOption that sets the line style of a Links.
+func LinkLineStyle(sty draw.LineStyle) LinkOption {
+	return func(r *Links) error {
+		r.LineStyle = sty
+		return nil
+	}
+}
+
+// LinkBezier returns a LinkOption that sets the Bézier curve configuration of a
+// Links.
+func LinkBezier(b *Bezier) LinkOption {
+	return func(r *Links) error {
+		r.Bezier = b
+		return nil
+	}
+}
+
+// LinkEndGlyph returns a LinkOption that sets the end glyph style of a Links. An
+// error is returned if tol is negative.
+func LinkEndGlyph(sty draw.GlyphStyle, tol Angle) LinkOption {
+	return func(r *Links) error {
+		if tol < 0 {
+			return errors.New("rings: negative end glyph tolerance")
+		}
+		r.EndGlyph = sty
+		r.EndGlyphTolerance = tol
+		return nil
+	}
+}
+
+// LinkEndGlyphParsed returns a LinkOption like LinkEndGlyph with the tolerance given
+// as a string parsed by ParseAngle. An error is returned if tol is not a valid angle
+// or is negative.
+func LinkEndGlyphParsed(sty draw.GlyphStyle, tol string) LinkOption {
+	return func(r *Links) error {
+		a, err := ParseAngle(tol)
+		if err != nil {
+			return err
+		}
+		return LinkEndGlyph(sty, a)(r)
+	}
+}
+
+// LinkClip returns a LinkOption that sets the clipping annulus of a Links. An error
+// is returned if either radius is negative or inner is greater than a non-zero outer.
+func LinkClip(inner, outer vg.Length) LinkOption {
+	return func(r *Links) error {
+		if inner < 0 || outer < 0 {
+			return errors.New("rings: negative clip radius")
+		}
+		if outer > 0 && inner > outer {
+			return fmt.Errorf("rings: clip inner radius %v greater than outer radius %v", inner, outer)
+		}
+		r.ClipInner, r.ClipOuter = inner, outer
+		return nil
+	}
+}
+
+// LinkClipParsed returns a LinkOption like LinkClip with the radii given as strings
+// parsed by ParseLength, for example "2cm". An error is returned if either radius is
+// not a valid length or the radii are not valid for LinkClip.
+func LinkClipParsed(inner, outer string) LinkOption {
+	return func(r *Links) error {
+		in, err := ParseLength(inner)
+		if err != nil {
+			return err
+		}
+		out, err := ParseLength(outer)
+		if err != nil {
+			return err
+		}
+		return LinkClip(in, out)(r)
+	}
+}
+
+// LabelTextStyle returns a LabelOption that sets the text style of a Labels.
+func LabelTextStyle(sty draw.TextStyle) LabelOption {
+	return func(r *Labels) error {
+		r.TextStyle = sty
+		return nil
+	}
+}
+
+// LabelPriority returns a LabelOption that prunes the labels of a Labels to the highest
+// priority non-overlapping labels, rendering at most max labels in total and at most
+// perLocation labels of the features of each location. A limit of zero is not applied.
+// An error is returned if either limit is negative.
+func LabelPriority(priority func(feat.Feature) float64, max, perLocation int) LabelOption {
+	return func(r *Labels) error {
+		if max < 0 || perLocation < 0 {
+			return errors.New("rings: negative maximum label count")
+		}
+		r.Priority = priority
+		r.Max = max
+		r.MaxPerLocation = perLocation
+		return nil
+	}
+}
+
+// LabelPlacement returns a LabelOption that sets the text placement of a Labels.
+func LabelPlacement(p TextPlacement) LabelOption {
+	return func(r *Labels) error {
+		r.Placement = p
+		return nil
+	}
+}
+
+// PeakCallouts returns a PeakOption that places the callout boxes at the given radius
+// and styles them with box and sty. An error is returned if radius is negative.
+func PeakCallouts(radius vg.Length, box BoxStyle, sty draw.TextStyle) PeakOption {
+	return func(r *PeakAnnotations) error {
+		if radius < 0 {
+			return errors.New("rings: negative callout radius")
+		}
+		r.Callouts.Radius, r.Callouts.Box, r.Callouts.TextStyle = radius, box, sty
+		return nil
+	}
+}
+
+// PeakCalloutsParsed returns a PeakOption like PeakCallouts with the radius given as
+// a string parsed by ParseLength. An error is returned if radius is not a valid length
+// or is negative.
+func PeakCalloutsParsed(radius string, box BoxStyle, sty draw.TextStyle) PeakOption {
+	return func(r *PeakAnnotations) error {
+		l, err := ParseLength(radius)
+		if err != nil {
+			return err
+		}
+		return PeakCallouts(l, box, sty)(r)
+	}
+}
+
+// PeakConnectors returns a PeakOption that strokes the connectors with sty, graded
+// towards fade at the callouts if fade is not nil.
+func PeakConnectors(sty draw.LineStyle, fade color.Color) PeakOption {
+	return func(r *PeakAnnotations) error {
+		r.LineStyle, r.Fade = sty, fade
+		return nil
+	}
+}
+
+// PeakFormat returns a PeakOption that sets the callout text format of the annotated
+// scores. An error is returned if format is nil.
+func PeakFormat(format func(f feat.Feature, idx int, v float64) string) PeakOption {
+	return func(r *PeakAnnotations) error {
+		if format == nil {
+			return errors.New("rings: nil peak format")
+		}
+		r.Format = format
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/plot/vg"
+)
+
+// angleUnits holds the suffixes accepted by ParseAngle and the angles of their units.
+var angleUnits = []struct {
+	suffix string
+	unit   Angle
+}{
+	{suffix: "deg", unit: AngleDegree},
+	{suffix: "rad", unit: 1},
+	{suffix: "turn", unit: Complete},
+}
+
+// ParseAngle parses an angle given as a signed number with an optional unit suffix,
+// for example "90deg", "0.25turn" or "1.5708rad". The accepted suffixes are "deg" for
+// degrees, "rad" for radians and "turn" for fractions of a complete circle. A number
+// with no suffix is in radians, the unit of Angle. Space around the number and between
+// the number and its suffix is ignored. An error is returned if s is not a finite
+// angle.
+func ParseAngle(s string) (Angle, error) {
+	v := strings.TrimSpace(s)
+	var unit Angle = 1
+	for _, u := range angleUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			unit = u.unit
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("rings: invalid angle %q", s)
+	}
+	return Angle(f) * unit, nil
+}
+
+// ParseLength parses a length given as a signed number with an optional unit suffix,
+// for example "5mm", "0.5in" or "12pt", as described by vg.ParseLength: the accepted
+// suffixes are "mm", "cm", "in" and "pt", and a number with no suffix is in points,
+// the unit of vg.Length. Unlike vg.ParseLength, space around the number and between
+// the number and its suffix is ignored. An error is returned if s is not a finite
+// length.
+func ParseLength(s string) (vg.Length, error) {
+	v := strings.TrimSpace(s)
+	for _, suffix := range []string{"mm", "cm", "in", "pt"} {
+		if strings.HasSuffix(v, suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, suffix)) + suffix
+			break
+		}
+	}
+	l, err := vg.ParseLength(v)
+	if err != nil || math.IsNaN(float64(l)) || math.IsInf(float64(l), 0) {
+		return 0, fmt.Errorf("rings: invalid length %q", s)
+	}
+	return l, nil
+}
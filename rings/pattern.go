@@ -0,0 +1,227 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Patterner is a type that can fill the region enclosed by a path with a pattern.
+type Patterner interface {
+	// Fill renders the pattern within the region enclosed by pa.
+	Fill(ca draw.Canvas, pa vg.Path)
+}
+
+// Hatch is a Patterner that fills a region with parallel lines.
+type Hatch struct {
+	// Angle is the angle of the hatching lines.
+	Angle Angle
+
+	// Spacing is the perpendicular distance between hatching lines.
+	Spacing vg.Length
+
+	// LineStyle is the line style of the hatching lines.
+	LineStyle draw.LineStyle
+}
+
+// Fill renders the hatching within the region enclosed by pa. The hatching lines are
+// clipped to the region using the even-odd rule. Nothing is rendered if the Hatch's
+// Spacing is not positive or its LineStyle would not be rendered.
+func (h Hatch) Fill(ca draw.Canvas, pa vg.Path) {
+	if h.Spacing <= 0 || h.LineStyle.Color == nil || h.LineStyle.Width == 0 {
+		return
+	}
+	hatch := hatchLines(flatten(pa), h.Angle, h.Spacing)
+	if len(hatch) == 0 {
+		return
+	}
+	ca.SetLineStyle(h.LineStyle)
+	ca.Stroke(hatch)
+}
+
+// CrossHatch is a Patterner that fills a region with two perpendicular sets of parallel lines.
+type CrossHatch Hatch
+
+// Fill renders the cross-hatching within the region enclosed by pa. The hatching lines
+// are clipped to the region using the even-odd rule.
+func (h CrossHatch) Fill(ca draw.Canvas, pa vg.Path) {
+	Hatch(h).Fill(ca, pa)
+	h.Angle += Complete / 4
+	Hatch(h).Fill(ca, pa)
+}
+
+// Dots is a Patterner that fills a region with a square grid of dots.
+type Dots struct {
+	// Spacing is the distance between adjacent dots.
+	Spacing vg.Length
+
+	// Radius is the radius of each dot.
+	Radius vg.Length
+
+	// Color is the color of the dots.
+	Color color.Color
+}
+
+// Fill renders the dots with centers within the region enclosed by pa, using the
+// even-odd rule. Nothing is rendered if the Dots' Spacing or Radius is not positive
+// or its Color is nil.
+func (d Dots) Fill(ca draw.Canvas, pa vg.Path) {
+	if d.Spacing <= 0 || d.Radius <= 0 || d.Color == nil {
+		return
+	}
+	polys := flatten(pa)
+	min, max, ok := bounds(polys)
+	if !ok {
+		return
+	}
+
+	var dots vg.Path
+	for y := vg.Length(math.Ceil(float64(min.Y/d.Spacing))) * d.Spacing; y <= max.Y; y += d.Spacing {
+		for x := vg.Length(math.Ceil(float64(min.X/d.Spacing))) * d.Spacing; x <= max.X; x += d.Spacing {
+			pt := vg.Point{X: x, Y: y}
+			if !inside(polys, pt) {
+				continue
+			}
+			dots.Move(pt.Add(vg.Point{X: d.Radius}))
+			dots.Arc(pt, d.Radius, 0, 2*math.Pi)
+			dots.Close()
+		}
+	}
+	if len(dots) == 0 {
+		return
+	}
+	ca.SetColor(d.Color)
+	ca.Fill(dots)
+}
+
+// flattenStep is the maximum angle subtended by a line segment approximating an arc.
+const flattenStep = math.Pi / 180
+
+// flatten returns the closed polygons approximating the subpaths of pa.
+func flatten(pa vg.Path) [][]vg.Point {
+	var (
+		polys [][]vg.Point
+		ring  []vg.Point
+	)
+	for _, c := range pa {
+		switch c.Type {
+		case vg.MoveComp:
+			if len(ring) > 2 {
+				polys = append(polys, ring)
+			}
+			ring = []vg.Point{c.Pos}
+		case vg.LineComp:
+			ring = append(ring, c.Pos)
+		case vg.ArcComp:
+			n := int(math.Ceil(math.Abs(c.Angle) / flattenStep))
+			for i := 0; i <= n; i++ {
+				var theta float64
+				if n != 0 {
+					theta = c.Start + c.Angle*float64(i)/float64(n)
+				}
+				ring = append(ring, RectangularAt(c.Pos, Angle(theta), c.Radius))
+			}
+		case vg.CloseComp:
+			if len(ring) > 2 {
+				polys = append(polys, ring)
+			}
+			if len(ring) != 0 {
+				ring = []vg.Point{ring[0]}
+			}
+		}
+	}
+	if len(ring) > 2 {
+		polys = append(polys, ring)
+	}
+	return polys
+}
+
+// bounds returns the bounding box of the points in polys.
+func bounds(polys [][]vg.Point) (min, max vg.Point, ok bool) {
+	min = vg.Point{X: vg.Length(math.Inf(1)), Y: vg.Length(math.Inf(1))}
+	max = vg.Point{X: vg.Length(math.Inf(-1)), Y: vg.Length(math.Inf(-1))}
+	for _, r := range polys {
+		for _, p := range r {
+			min.X, min.Y = vg.Length(math.Min(float64(min.X), float64(p.X))), vg.Length(math.Min(float64(min.Y), float64(p.Y)))
+			max.X, max.Y = vg.Length(math.Max(float64(max.X), float64(p.X))), vg.Length(math.Max(float64(max.Y), float64(p.Y)))
+			ok = true
+		}
+	}
+	return min, max, ok
+}
+
+// crossings returns the sorted x coordinates at which the horizontal line through y
+// crosses the edges of the closed polygons in polys.
+func crossings(polys [][]vg.Point, y vg.Length) []vg.Length {
+	var xs []vg.Length
+	for _, r := range polys {
+		for i, a := range r {
+			b := r[(i+1)%len(r)]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+	}
+	sort.Sort(lengths(xs))
+	return xs
+}
+
+type lengths []vg.Length
+
+func (l lengths) Len() int           { return len(l) }
+func (l lengths) Less(i, j int) bool { return l[i] < l[j] }
+func (l lengths) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// inside returns whether pt is within the closed polygons in polys by the even-odd rule.
+func inside(polys [][]vg.Point, pt vg.Point) bool {
+	var in bool
+	for _, x := range crossings(polys, pt.Y) {
+		if x > pt.X {
+			break
+		}
+		in = !in
+	}
+	return in
+}
+
+// hatchLines returns a path holding the segments of lines at the given angle and
+// spacing that lie within the closed polygons in polys by the even-odd rule.
+func hatchLines(polys [][]vg.Point, angle Angle, spacing vg.Length) vg.Path {
+	// Rotate the polygons so that the hatching lines are horizontal.
+	sin, cos := math.Sincos(float64(angle))
+	rotate := func(p vg.Point, sin, cos float64) vg.Point {
+		return vg.Point{
+			X: p.X*vg.Length(cos) - p.Y*vg.Length(sin),
+			Y: p.X*vg.Length(sin) + p.Y*vg.Length(cos),
+		}
+	}
+	rot := make([][]vg.Point, len(polys))
+	for i, r := range polys {
+		rot[i] = make([]vg.Point, len(r))
+		for j, p := range r {
+			rot[i][j] = rotate(p, -sin, cos)
+		}
+	}
+	min, max, ok := bounds(rot)
+	if !ok {
+		return nil
+	}
+
+	var pa vg.Path
+	for y := vg.Length(math.Ceil(float64(min.Y/spacing))) * spacing; y <= max.Y; y += spacing {
+		xs := crossings(rot, y)
+		for i := 0; i+1 < len(xs); i += 2 {
+			pa.Move(rotate(vg.Point{X: xs[i], Y: y}, sin, cos))
+			pa.Line(rotate(vg.Point{X: xs[i+1], Y: y}, sin, cos))
+		}
+	}
+	return pa
+}
@@ -0,0 +1,184 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// maxPeakNudges is the number of times a peak label that overlaps a previously
+// placed label is moved outwards before it is discarded.
+const maxPeakNudges = 8
+
+// PeakLabels describes labels of the values of the local maxima of the traces of a
+// Trace. The peaks of each trace are found separately within each location. NaN scores
+// are ignored, so a peak may be separated from its neighbours by NaN scores, and a run
+// of equal scores forming a plateau peak is labelled once at the centre of the run.
+type PeakLabels struct {
+	// Window is the number of non-NaN scores either side of a score that it
+	// must exceed to be a peak. A run of equal scores is counted as a single
+	// score. If Window is less than one, a window of one is used.
+	Window int
+
+	// Count, if greater than zero, is the maximum number of peaks of each trace
+	// within each location that are labelled. The highest peaks are labelled.
+	Count int
+
+	// Filter, if not nil, returns whether a peak of value v is labelled, so that
+	// labels may be restricted to peaks above a threshold. Filter is applied
+	// before Count.
+	Filter func(v float64) bool
+
+	// Format returns the label text of a peak of value v. If Format is nil, the
+	// shortest representation of v is used.
+	Format func(v float64) string
+
+	// TextStyle is the style of the labels. If the Color of the TextStyle is nil,
+	// no labels are rendered.
+	draw.TextStyle
+
+	// Placement determines the text rotation given the angle of a peak. The
+	// alignment returned by Placement is ignored. If Placement is nil,
+	// DefaultPlacement is used.
+	Placement TextPlacement
+
+	// Gap is the radial distance between a peak and its label. Labels that
+	// overlap the label of a higher peak are moved outwards by the radial extent
+	// of the label, and are not rendered if they still overlap after a number
+	// of moves.
+	Gap vg.Length
+}
+
+// peak is a local maximum of a trace.
+type peak struct {
+	angle  Angle
+	value  float64
+	offset vg.Length
+}
+
+// peaks returns the labelled peaks of the jth trace of the sorted values, given the
+// rendered scores of each value and the radial displacement of each Scorer given by
+// offset.
+func (p *PeakLabels) peaks(values arcScores, scores [][]float64, j int, offset func(Scorer) vg.Length) []peak {
+	var all []peak
+	for start := 0; start < len(values); {
+		end := start + 1
+		for end < len(values) && values[end].Location() == values[start].Location() {
+			end++
+		}
+		all = append(all, p.locationPeaks(values[start:end], scores[start:end], j, offset)...)
+		start = end
+	}
+	return all
+}
+
+// locationPeaks returns the labelled peaks of the jth trace of values held by a
+// single location.
+func (p *PeakLabels) locationPeaks(values arcScores, scores [][]float64, j int, offset func(Scorer) vg.Length) []peak {
+	// Collapse runs of equal non-NaN scores into single points
+	// at the angular centre of the run.
+	var pts []peak
+	var first, last Arc
+	for i, v := range values {
+		if j >= len(scores[i]) || math.IsNaN(scores[i][j]) {
+			continue
+		}
+		s := scores[i][j]
+		arc := v.Arc
+		if arc.Phi < 0 {
+			arc = Arc{arc.Theta + arc.Phi, -arc.Phi}
+		}
+		if n := len(pts); n != 0 && pts[n-1].value == s {
+			last = arc
+			pts[n-1].angle = (first.Theta + last.Theta + last.Phi) / 2
+			continue
+		}
+		first, last = arc, arc
+		pts = append(pts, peak{angle: arc.Theta + arc.Phi/2, value: s, offset: scorerOffset(offset, v.Scorer)})
+	}
+
+	w := p.Window
+	if w < 1 {
+		w = 1
+	}
+	var found []peak
+	for k, pt := range pts {
+		isPeak := true
+		for o := k - w; o <= k+w && isPeak; o++ {
+			if o != k && 0 <= o && o < len(pts) && pts[o].value >= pt.value {
+				isPeak = false
+			}
+		}
+		if isPeak && (p.Filter == nil || p.Filter(pt.value)) {
+			found = append(found, pt)
+		}
+	}
+	sort.Stable(byPeakValue(found))
+	if p.Count > 0 && len(found) > p.Count {
+		found = found[:p.Count]
+	}
+	return found
+}
+
+// byPeakValue sorts peaks by descending value.
+type byPeakValue []peak
+
+func (p byPeakValue) Len() int           { return len(p) }
+func (p byPeakValue) Less(i, j int) bool { return p[i].value > p[j].value }
+func (p byPeakValue) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// text returns the label text of a peak of value v.
+func (p *PeakLabels) text(v float64) string {
+	if p.Format == nil {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return p.Format(v)
+}
+
+// drawAt renders the labels of the peaks in order, scaled radially about cen by s
+// displaced by the offset of each peak.
+// Labels are placed in order of descending peak value, so labels of lower peaks are
+// moved to avoid those of higher peaks.
+func (p *PeakLabels) drawAt(ca draw.Canvas, cen vg.Point, s radialScale, peaks []peak) {
+	if p.Color == nil {
+		return
+	}
+	sort.Stable(byPeakValue(peaks))
+	placement := p.Placement
+	if placement == nil {
+		placement = DefaultPlacement
+	}
+	var placed []vg.Rectangle
+	for _, pk := range peaks {
+		txt := p.text(pk.value)
+		rot, _, _ := placement(pk.angle)
+		w, h := TextBounds(p.TextStyle, txt)
+
+		// The radial extent of the rotated label determines
+		// the distance of its centre beyond the peak and the
+		// size of each outward move.
+		sin, cos := math.Sincos(float64(rot - pk.angle))
+		ext := vg.Length(math.Abs(float64(w)*cos) + math.Abs(float64(h)*sin))
+
+		rad, _ := s.shifted(pk.offset).radius(pk.value)
+		rad += p.Gap + ext/2
+		box := rotatedBox(w, h, rot, -0.5, -0.5)
+		for n := 0; n <= maxPeakNudges; n++ {
+			pt := RectangularAt(cen, pk.angle, rad)
+			b := vg.Rectangle{Min: pt.Add(box.Min), Max: pt.Add(box.Max)}
+			if !overlapsAny(b, placed) {
+				placed = append(placed, b)
+				fillText(ca, p.TextStyle, pt, rot, -0.5, -0.5, txt)
+				break
+			}
+			rad += ext
+		}
+	}
+}
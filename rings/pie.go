@@ -0,0 +1,193 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// pieLength is the length of the location holding the sectors of a Pie. Each sector
+// is allocated a share of the location proportional to its value.
+const pieLength = 1 << 30
+
+// Pie is a pie or donut chart of a set of values. Each value is rendered as a block of
+// a Blocks ring, with an arc proportional to its share of the total of the values,
+// starting at the top of the circle and proceeding clockwise.
+type Pie struct {
+	// Blocks renders the sectors of the chart. The Set of the Blocks holds
+	// a feature for each value, so further rings may use the Blocks as their
+	// base to render data against the sectors.
+	//
+	// The InnerLabel of the Blocks labels each sector with its percentage of
+	// the total. The labels are rendered when the TextStyle of the InnerLabel
+	// is given a Color and Font.
+	Blocks *Blocks
+
+	// Labels, if not nil, labels each sector with its label outside the
+	// chart. The labels are rendered when the TextStyle of the Labels is
+	// given a Color and Font.
+	Labels *Labels
+}
+
+// NewPie returns a Pie rendering values between the inner and outer radii. If labels is
+// not nil, it holds the label of each value and the Pie has Labels. If colors is not nil,
+// it holds the fill color of each value; otherwise the sectors are filled with the Color
+// of the Pie's Blocks. An error is returned if values is empty or holds a value that is
+// not positive and finite, if labels or colors are not nil and do not hold an element for
+// each value, or if the radii are not valid.
+func NewPie(values []float64, labels []string, colors []color.Color, inner, outer vg.Length) (*Pie, error) {
+	if len(values) == 0 {
+		return nil, errors.New("rings: no pie values")
+	}
+	if labels != nil && len(labels) != len(values) {
+		return nil, fmt.Errorf("rings: %d pie labels for %d values", len(labels), len(values))
+	}
+	if colors != nil && len(colors) != len(values) {
+		return nil, fmt.Errorf("rings: %d pie colors for %d values", len(colors), len(values))
+	}
+	var total float64
+	for i, v := range values {
+		if !(v > 0) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("rings: pie value %d not positive: %v", i, v)
+		}
+		total += v
+	}
+	if math.IsInf(total, 0) {
+		return nil, errors.New("rings: pie total is infinite")
+	}
+
+	loc := &pieLocation{}
+	fs := make([]feat.Feature, len(values))
+	var sum float64
+	for i, v := range values {
+		s := &pieSector{
+			loc:      loc,
+			start:    int(sum / total * pieLength),
+			fraction: v / total,
+		}
+		sum += v
+		s.end = int(sum / total * pieLength)
+		if i == len(values)-1 {
+			s.end = pieLength
+		}
+		if labels != nil {
+			s.name = labels[i]
+		} else {
+			s.name = fmt.Sprint(i)
+		}
+		if colors != nil {
+			fs[i] = coloredSector{s, colors[i]}
+		} else {
+			fs[i] = s
+		}
+	}
+
+	arc := Arc{Complete / 4, Clockwise * Complete}
+	b, err := NewBlocks(fs, Arcs{Base: arc, Arcs: map[feat.Feature]Arc{loc: arc}}, inner, outer)
+	if err != nil {
+		return nil, err
+	}
+	b.InnerLabel = InnerLabel{Text: pieSectorPercent, Placement: Horizontal}
+
+	p := &Pie{Blocks: b}
+	if labels != nil {
+		p.Labels, err = NewLabels(b, outer, NameLabels(fs)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Plotters returns the plotters rendering the Pie, in drawing order.
+func (p *Pie) Plotters() []plot.Plotter {
+	ps := []plot.Plotter{p.Blocks}
+	if p.Labels != nil {
+		ps = append(ps, p.Labels)
+	}
+	return ps
+}
+
+// DrawAt renders the Pie at cen in the specified drawing area.
+func (p *Pie) DrawAt(ca draw.Canvas, cen vg.Point) {
+	p.Blocks.DrawAt(ca, cen)
+	if p.Labels != nil {
+		p.Labels.DrawAt(ca, cen)
+	}
+}
+
+// Plot calls the Plot method of each of the Pie's plotters.
+func (p *Pie) Plot(ca draw.Canvas, plt *plot.Plot) {
+	for _, r := range p.Plotters() {
+		r.Plot(ca, plt)
+	}
+}
+
+// GlyphBoxes returns the glyph boxes of the Pie's plotters.
+func (p *Pie) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	var boxes []plot.GlyphBox
+	for _, r := range p.Plotters() {
+		if gb, ok := r.(plot.GlyphBoxer); ok {
+			boxes = append(boxes, gb.GlyphBoxes(plt)...)
+		}
+	}
+	return boxes
+}
+
+// pieSectorPercent returns the percentage label of the pie sector f.
+func pieSectorPercent(f feat.Feature) string {
+	var s *pieSector
+	switch f := f.(type) {
+	case *pieSector:
+		s = f
+	case coloredSector:
+		s = f.pieSector
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", 100*s.fraction)
+}
+
+// pieLocation is the location holding the sectors of a Pie.
+type pieLocation struct{}
+
+func (l *pieLocation) Start() int             { return 0 }
+func (l *pieLocation) End() int               { return pieLength }
+func (l *pieLocation) Len() int               { return pieLength }
+func (l *pieLocation) Name() string           { return "pie" }
+func (l *pieLocation) Description() string    { return "pie" }
+func (l *pieLocation) Location() feat.Feature { return nil }
+
+// pieSector is the feature of a value of a Pie.
+type pieSector struct {
+	loc        *pieLocation
+	start, end int
+	name       string
+	fraction   float64
+}
+
+func (s *pieSector) Start() int             { return s.start }
+func (s *pieSector) End() int               { return s.end }
+func (s *pieSector) Len() int               { return s.end - s.start }
+func (s *pieSector) Name() string           { return s.name }
+func (s *pieSector) Description() string    { return "pie sector" }
+func (s *pieSector) Location() feat.Feature { return s.loc }
+
+// coloredSector is a pie sector with a fill color.
+type coloredSector struct {
+	*pieSector
+	color color.Color
+}
+
+func (s coloredSector) FillColor() color.Color { return s.color }
@@ -0,0 +1,166 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// AxisPlacement determines the angular location of an Axis from the base of the ring
+// the axis is drawn on.
+type AxisPlacement func(base ArcOfer) (Angle, error)
+
+// PlaceAtAngle returns an AxisPlacement that places an axis at the specified angle.
+func PlaceAtAngle(a Angle) AxisPlacement {
+	return func(ArcOfer) (Angle, error) { return a, nil }
+}
+
+// PlaceAtGapAfter returns an AxisPlacement that places an axis at the center of the
+// gap following the arc of f in the direction of the base arc. If f is followed by
+// overlapping features, the gap following the last of them is used. If the gap is
+// empty, the axis is placed at the boundary between f and the following feature.
+// The base must be a *Blocks or an Arcs.
+func PlaceAtGapAfter(f feat.Feature) AxisPlacement {
+	return func(base ArcOfer) (Angle, error) {
+		g, err := gapsOf(base)
+		if err != nil {
+			return 0, err
+		}
+		arc, err := base.ArcOf(nil, f)
+		if err != nil {
+			return 0, err
+		}
+		end := g.span(arc).hi
+		for i, s := range g.spans {
+			if s.lo <= end && end <= s.hi {
+				return g.center(i), nil
+			}
+		}
+		return 0, errors.New("rings: feature not found in axis base")
+	}
+}
+
+// PlaceAtLargestGap is an AxisPlacement that places an axis at the center of the
+// largest gap between the features of the base. If there are no gaps, the axis is
+// placed at the end of the first feature in the direction of the base arc. The base
+// must be a *Blocks or an Arcs.
+func PlaceAtLargestGap(base ArcOfer) (Angle, error) {
+	g, err := gapsOf(base)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		max  Angle = -1
+		best int
+	)
+	for i := range g.spans {
+		if w := g.width(i); w > max {
+			max, best = w, i
+		}
+	}
+	return g.center(best), nil
+}
+
+// gaps holds the arcs covered by the features of a base as sorted, non-overlapping
+// spans of angles measured from the start of the base arc in its direction.
+type gaps struct {
+	base  Arc
+	dir   Angle
+	spans []gapSpan
+}
+
+// overlapTolerance is the angular overlap of adjacent spans below which the
+// spans are considered to abut.
+const overlapTolerance = 1e-9
+
+// gapSpan is an interval of angles measured from the start of a base arc.
+type gapSpan struct {
+	lo, hi Angle
+}
+
+type bySpanStart []gapSpan
+
+func (s bySpanStart) Len() int           { return len(s) }
+func (s bySpanStart) Less(i, j int) bool { return s[i].lo < s[j].lo }
+func (s bySpanStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// gapsOf returns the gaps between the features of base.
+func gapsOf(base ArcOfer) (gaps, error) {
+	var fs []feat.Feature
+	switch b := base.(type) {
+	case *Blocks:
+		fs = b.Set
+	case Arcs:
+		for f := range b.Arcs {
+			fs = append(fs, f)
+		}
+	case *Arcs:
+		for f := range b.Arcs {
+			fs = append(fs, f)
+		}
+	}
+	if len(fs) == 0 {
+		return gaps{}, errors.New("rings: no features in axis base")
+	}
+
+	g := gaps{base: base.Arc(), dir: 1}
+	if g.base.Phi < 0 {
+		g.dir = -1
+	}
+	var spans []gapSpan
+	for _, f := range fs {
+		arc, err := base.ArcOf(nil, f)
+		if err != nil {
+			return gaps{}, err
+		}
+		spans = append(spans, g.span(arc))
+	}
+	// Overlapping spans are merged, but abutting spans are
+	// kept so that the empty gaps between them are retained.
+	sort.Sort(bySpanStart(spans))
+	for _, s := range spans {
+		if n := len(g.spans); n != 0 && s.lo < g.spans[n-1].hi-overlapTolerance {
+			g.spans[n-1].hi = Angle(math.Max(float64(g.spans[n-1].hi), float64(s.hi)))
+			continue
+		}
+		g.spans = append(g.spans, s)
+	}
+	// A span wrapping past the end of the circle may reach into the first span.
+	if n := len(g.spans); n > 1 && g.spans[n-1].hi-Complete > g.spans[0].lo+overlapTolerance {
+		g.spans[0].lo = g.spans[n-1].lo - Complete
+		g.spans[0].hi = Angle(math.Max(float64(g.spans[0].hi), float64(g.spans[n-1].hi-Complete)))
+		g.spans = g.spans[:n-1]
+	}
+	return g, nil
+}
+
+// span returns the span of arc relative to the base arc.
+func (g gaps) span(arc Arc) gapSpan {
+	start := arc.Theta
+	if arc.Phi*g.dir < 0 {
+		start += arc.Phi
+	}
+	lo := Normalize((start - g.base.Theta) * g.dir)
+	return gapSpan{lo: lo, hi: lo + Angle(math.Abs(float64(arc.Phi)))}
+}
+
+// width returns the angular width of the gap following the ith span. The gap
+// following the last span wraps around the circle to the first span.
+func (g gaps) width(i int) Angle {
+	next := g.spans[0].lo + Complete
+	if i+1 < len(g.spans) {
+		next = g.spans[i+1].lo
+	}
+	return Angle(math.Max(0, float64(next-g.spans[i].hi)))
+}
+
+// center returns the angle of the center of the gap following the ith span.
+func (g gaps) center(i int) Angle {
+	return Normalize(g.base.Theta + g.dir*(g.spans[i].hi+g.width(i)/2))
+}
@@ -0,0 +1,75 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"context"
+)
+
+// DefaultProgressInterval is the number of features rendered between checks for
+// cancellation and calls to Progress functions by the DrawAtContext methods of the
+// rings package when no interval is specified by WithProgressInterval.
+const DefaultProgressInterval = 1000
+
+// progressIntervalKey is the context key for the progress interval.
+type progressIntervalKey struct{}
+
+// WithProgressInterval returns a copy of ctx specifying that the DrawAtContext methods
+// of the rings package called with the returned context check for cancellation and
+// call Progress functions every n features. If n is not positive, the interval is
+// DefaultProgressInterval.
+func WithProgressInterval(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		n = DefaultProgressInterval
+	}
+	return context.WithValue(ctx, progressIntervalKey{}, n)
+}
+
+// progressInterval returns the progress interval specified by ctx, or
+// DefaultProgressInterval if ctx specifies no valid interval.
+func progressInterval(ctx context.Context) int {
+	if n, ok := ctx.Value(progressIntervalKey{}).(int); ok && n > 0 {
+		return n
+	}
+	return DefaultProgressInterval
+}
+
+// progress tracks the rendering of features for cancellation and progress reporting.
+type progress struct {
+	ctx      context.Context
+	fn       func(done, total int)
+	interval int
+	done     int
+	n        int
+}
+
+// newProgress returns a progress reporting to fn for the rendering of n features and
+// cancelled by ctx. The returned error is the error of ctx if it is already cancelled.
+func newProgress(ctx context.Context, fn func(done, total int), n int) (*progress, error) {
+	return &progress{ctx: ctx, fn: fn, interval: progressInterval(ctx), n: n}, ctx.Err()
+}
+
+// step records the start of rendering of a feature. Every progress interval features,
+// the progress function is called with the number of features already rendered and
+// the error of the context is returned.
+func (p *progress) step() error {
+	if p.done != 0 && p.done%p.interval == 0 {
+		if p.fn != nil {
+			p.fn(p.done, p.n)
+		}
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	p.done++
+	return nil
+}
+
+// finish records the completion of rendering, calling the progress function.
+func (p *progress) finish() {
+	if p.fn != nil {
+		p.fn(p.n, p.n)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sync"
+)
+
+// Range is a score range shared by a collection of Scores and Axes so that they are
+// rendered on a common radial scale. The shared range is determined from the scores
+// of the Scores included in the Range each time the range is used, so it follows
+// changes to their scores and does not depend on the order in which the Scores are
+// drawn. A Scores rendered according to a Range contributes its own scores to the
+// range it is rendered with whether or not it has been included. Scores are included
+// by the SharedRange option, or by Include.
+type Range struct {
+	// Min and Max, if not both zero, explicitly specify the shared range, and
+	// scores included in the Range are ignored.
+	Min, Max float64
+
+	// IncludeZero specifies that the shared range is extended to include zero.
+	IncludeZero bool
+
+	// Symmetric specifies that the shared range is extended to be symmetric
+	// about zero.
+	Symmetric bool
+
+	// Padding is the fraction of the width of the shared range added to each end
+	// of the range after IncludeZero and Symmetric have been applied.
+	Padding float64
+
+	// scores holds the included Scores. mu protects scores so
+	// that plots sharing the Range may be drawn concurrently.
+	mu     sync.Mutex
+	scores []*Scores
+}
+
+// NewRange returns a new empty Range.
+func NewRange() *Range { return &Range{} }
+
+// Include includes the provided Scores in the Range. The finite scores of each
+// included Scores, or the data ranges of its Scorers if its Renderer is a DataRanger,
+// contribute to the shared range. Scores with an explicit range do not contribute.
+// Including a Scores more than once has no further effect.
+func (r *Range) Include(ss ...*Scores) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range ss {
+		if !r.includes(s) {
+			r.scores = append(r.scores, s)
+		}
+	}
+}
+
+// includes returns whether s is included in the Range. It must be called with mu held.
+func (r *Range) includes(s *Scores) bool {
+	for _, sc := range r.scores {
+		if sc == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset removes the included Scores from the Range.
+func (r *Range) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scores = nil
+}
+
+// Values returns the shared range. The range is given by Min and Max, or by the
+// range of the scores of the included Scores if Min and Max are both zero, and is
+// then adjusted according to the IncludeZero, Symmetric and Padding fields. If Min
+// and Max are both zero and no included Scores has a score, the range before
+// adjustment is [0, 0]. A range of scores that are all equal is widened as described
+// by Scores.ScoreRange.
+func (r *Range) Values() (min, max float64) {
+	return r.values(nil)
+}
+
+// values returns the shared range as described by Values, with the scores of s
+// contributing to the range if s is not nil.
+func (r *Range) values(s *Scores) (min, max float64) {
+	min, max = r.Min, r.Max
+	var ok bool
+	if min == 0 && max == 0 {
+		r.mu.Lock()
+		ss := r.scores
+		if s != nil && !r.includes(s) {
+			ss = append(ss[:len(ss):len(ss)], s)
+		}
+		r.mu.Unlock()
+
+		min, max = math.Inf(1), math.Inf(-1)
+		for _, sc := range ss {
+			if sc.hasRange() {
+				continue
+			}
+			smin, smax, sok := scoreRange(sc.scorers(), sc.Renderer)
+			if sok {
+				min, max, ok = math.Min(min, smin), math.Max(max, smax), true
+			}
+		}
+		if !ok {
+			min, max = 0, 0
+		}
+	}
+	return adjustRange(min, max, r.IncludeZero, r.Symmetric, r.Padding, ok)
+}
@@ -0,0 +1,241 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"container/heap"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+)
+
+// heatSamples holds the offsets within a pixel of the supersamples used to rasterize
+// a Heat, in pixel units along each axis.
+var heatSamples = [...]float64{0.25, 0.75}
+
+// heatCell is the recorded rendering of the scores of a Scorer by a rasterized Heat.
+type heatCell struct {
+	// index is the order in which the cell was rendered.
+	index int
+
+	// start and length describe the arc of the cell, with start
+	// in [0, Complete) and length not negative.
+	start, length Angle
+
+	// offset is the radial displacement of the cell.
+	offset vg.Length
+
+	// cols holds the premultiplied color of each score from the inner
+	// radius to the outer radius. Scores that are not rendered are
+	// transparent.
+	cols []color.RGBA64
+}
+
+type byCellStart []heatCell
+
+func (c byCellStart) Len() int           { return len(c) }
+func (c byCellStart) Less(i, j int) bool { return c[i].start < c[j].start }
+func (c byCellStart) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// record records the colors of scores across arc, displaced radially by off, for
+// rasterization when the Heat is closed, with ps being the number of palette steps
+// per unit value.
+func (h *Heat) record(arc Arc, scores []float64, ps float64, off vg.Length) {
+	start, length := arc.Theta, arc.Phi
+	if length < 0 {
+		start, length = start+length, -length
+	}
+	start = Angle(math.Mod(float64(start), float64(Complete)))
+	if start < 0 {
+		start += Complete
+	}
+	c := heatCell{index: len(h.cells), start: start, length: length, offset: off, cols: make([]color.RGBA64, len(scores))}
+	for i, v := range scores {
+		if col := h.colorOf(v, h.Min, h.Max, ps); col != nil {
+			r, g, b, a := col.RGBA()
+			c.cols[i] = color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+		}
+	}
+	h.cells = append(h.cells, c)
+}
+
+// heatSpan is an angular span over which a single recorded cell of a rasterized Heat
+// is visible.
+type heatSpan struct {
+	start, end Angle
+	offset     vg.Length
+	cols       []color.RGBA64
+}
+
+// heatSpans returns the non-overlapping spans, sorted by start angle, over which each
+// of the cells is visible. Where cells overlap, the last rendered cell is visible.
+func heatSpans(cells []heatCell) []heatSpan {
+	var bounds []float64
+	for _, c := range cells {
+		if c.length == 0 || len(c.cols) == 0 {
+			continue
+		}
+		bounds = append(bounds, float64(c.start), float64(c.start+c.length))
+	}
+	sort.Float64s(bounds)
+	sort.Stable(byCellStart(cells))
+
+	var (
+		spans  []heatSpan
+		active cellHeap
+		next   int
+	)
+	for k, b := range bounds {
+		if k+1 == len(bounds) || bounds[k+1] == b {
+			continue
+		}
+		for ; next < len(cells) && float64(cells[next].start) <= b; next++ {
+			if c := cells[next]; c.length != 0 && len(c.cols) != 0 {
+				heap.Push(&active, c)
+			}
+		}
+		for len(active) != 0 && float64(active[0].start+active[0].length) <= b {
+			heap.Pop(&active)
+		}
+		if len(active) == 0 {
+			continue
+		}
+		top := active[0]
+		if n := len(spans); n != 0 && spans[n-1].end == Angle(b) && &spans[n-1].cols[0] == &top.cols[0] {
+			spans[n-1].end = Angle(bounds[k+1])
+			continue
+		}
+		spans = append(spans, heatSpan{start: Angle(b), end: Angle(bounds[k+1]), offset: top.offset, cols: top.cols})
+	}
+	return spans
+}
+
+// cellHeap is a max-heap of cells ordered by the order in which they were rendered.
+type cellHeap []heatCell
+
+func (h cellHeap) Len() int            { return len(h) }
+func (h cellHeap) Less(i, j int) bool  { return h[i].index > h[j].index }
+func (h cellHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cellHeap) Push(x interface{}) { *h = append(*h, x.(heatCell)) }
+func (h *cellHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// drawRaster draws the recorded cells of the Heat as a raster image at the Heat's
+// Rasterize resolution and clears the recorded cells. Each pixel is the mean of a
+// grid of samples, each taking the color of the cell bin found by mapping the sample
+// back to its angle and radius about the Heat's Center. The Inner radius of the Heat
+// may be greater than its Outer radius, as it is when the Heat renders an inverted
+// Scores.
+func (h *Heat) drawRaster() {
+	cells := h.cells
+	h.cells = nil
+	inner, outer := h.Inner, h.Outer
+	if inner > outer {
+		inner, outer = outer, inner
+	}
+	if len(cells) == 0 || inner == outer {
+		return
+	}
+
+	var box vg.Rectangle
+	for i, c := range cells {
+		b := Arc{Theta: c.start, Phi: c.length}.bounds(inner+c.offset, outer+c.offset)
+		if i == 0 {
+			box = b
+		} else {
+			box = union(box, b)
+		}
+	}
+	spans := heatSpans(cells)
+
+	scale := h.Rasterize / 72 // Pixels per point.
+	w := int(math.Ceil(float64(box.Max.X-box.Min.X) * scale))
+	ht := int(math.Ceil(float64(box.Max.Y-box.Min.Y) * scale))
+	if w <= 0 || ht <= 0 {
+		return
+	}
+	min := h.Center.Add(box.Min)
+	rect := vg.Rectangle{
+		Min: min,
+		Max: vg.Point{X: min.X + vg.Length(float64(w)/scale), Y: min.Y + vg.Length(float64(ht)/scale)},
+	}
+
+	const n = uint32(len(heatSamples) * len(heatSamples))
+	img := image.NewRGBA(image.Rect(0, 0, w, ht))
+	for py := 0; py < ht; py++ {
+		for px := 0; px < w; px++ {
+			var r, g, b, a uint32
+			for _, oy := range heatSamples {
+				for _, ox := range heatSamples {
+					p := vg.Point{
+						X: rect.Min.X + vg.Length((float64(px)+ox)/scale),
+						Y: rect.Max.Y - vg.Length((float64(py)+oy)/scale),
+					}
+					c := h.colorAt(spans, p)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					b += uint32(c.B)
+					a += uint32(c.A)
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			img.SetRGBA(px, py, color.RGBA{
+				R: uint8((r / n) >> 8),
+				G: uint8((g / n) >> 8),
+				B: uint8((b / n) >> 8),
+				A: uint8((a / n) >> 8),
+			})
+		}
+	}
+	h.DrawArea.DrawImage(rect, img)
+}
+
+// colorAt returns the color of the cell bin holding p, or transparent if no cell holds
+// p. The bins of each cell run from the Inner radius of the Heat to its Outer radius,
+// displaced by the offset of the cell.
+func (h *Heat) colorAt(spans []heatSpan, p vg.Point) color.RGBA64 {
+	theta, r := Polar(p.Sub(h.Center))
+	span, ok := findSpan(spans, theta)
+	if !ok {
+		// Cells crossing zero hold angles past Complete.
+		span, ok = findSpan(spans, theta+Complete)
+		if !ok {
+			return color.RGBA64{}
+		}
+	}
+	r -= span.offset
+	inner, outer := h.Inner, h.Outer
+	if inner > outer {
+		inner, outer = outer, inner
+	}
+	if r < inner || r >= outer {
+		return color.RGBA64{}
+	}
+	cols := span.cols
+	bin := int(float64(r-h.Inner) / float64(h.Outer-h.Inner) * float64(len(cols)))
+	if bin >= len(cols) {
+		bin = len(cols) - 1
+	}
+	return cols[bin]
+}
+
+// findSpan returns the span of the spans sorted by start angle that holds theta, and
+// whether such a span was found.
+func findSpan(spans []heatSpan, theta Angle) (heatSpan, bool) {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].start > theta }) - 1
+	if i < 0 || theta >= spans[i].end {
+		return heatSpan{}, false
+	}
+	return spans[i], true
+}
@@ -0,0 +1,253 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// resizer is a type whose radial geometry can be scaled. Components that may be shared
+// between rings, such as a Bezier or an Axis, are scaled once and recorded in done.
+type resizer interface {
+	resize(f float64, done map[interface{}]bool)
+}
+
+// Resize scales the radial geometry of each of the provided plotters by the factor f,
+// so that a plot built for one canvas size may be rendered at another. The radii of
+// the rings are scaled along with the lengths that depend on them, including radial
+// offsets, tick lengths, label offsets and title gaps, while angles are unchanged.
+// Line widths, font sizes and text layout are not scaled. Plotters of a Layered and
+// the Plotters of a Clip and a Hashed are resized, and components shared between the
+// plotters, such as a Bezier or an Axis, are scaled once. The Key of a resized Hashed
+// must then be changed, otherwise a Retained replays the rendering of the track at its
+// previous size. Plotters that are not rings of this package are ignored. An error is
+// returned if f is not positive and finite.
+func Resize(f float64, ps ...plot.Plotter) error {
+	if !(f > 0) || math.IsInf(f, 0) {
+		return fmt.Errorf("rings: resize factor %v not positive and finite", f)
+	}
+	done := make(map[interface{}]bool)
+	for _, p := range ps {
+		if r, ok := p.(resizer); ok {
+			r.resize(f, done)
+		}
+	}
+	return nil
+}
+
+// Resize scales the radial geometry of the Layered's plotters by the factor f, as
+// described by the Resize function.
+func (r *Layered) Resize(f float64) error { return Resize(f, r.Plotters...) }
+
+func (r *Layered) resize(f float64, done map[interface{}]bool) {
+	for _, p := range r.Plotters {
+		if p, ok := p.(resizer); ok {
+			p.resize(f, done)
+		}
+	}
+}
+
+// scale returns l scaled by f.
+func scale(l vg.Length, f float64) vg.Length { return l * vg.Length(f) }
+
+// scaled returns a copy of ls with each length scaled by f.
+func scaled(ls []vg.Length, f float64) []vg.Length {
+	if ls == nil {
+		return nil
+	}
+	s := make([]vg.Length, len(ls))
+	for i, l := range ls {
+		s[i] = scale(l, f)
+	}
+	return s
+}
+
+func (r *Annulus) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+}
+
+func (r *Blocks) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	if off := r.Offset; off != nil {
+		r.Offset = func(q feat.Feature) vg.Length { return scale(off(q), f) }
+	}
+	r.Names.Offset = scale(r.Names.Offset, f)
+	r.Title.Gap = scale(r.Title.Gap, f)
+}
+
+func (r *Brush) resize(f float64, _ map[interface{}]bool) {
+	radii := make([]RadialInterval, len(r.Radii))
+	for i, ri := range r.Radii {
+		radii[i] = RadialInterval{Inner: scale(ri.Inner, f), Outer: scale(ri.Outer, f)}
+	}
+	r.Radii = radii
+}
+
+func (r *Callouts) resize(f float64, _ map[interface{}]bool) {
+	r.Anchor, r.Radius = scale(r.Anchor, f), scale(r.Radius, f)
+	r.Push = scale(r.Push, f)
+}
+
+func (r *Center) resize(f float64, _ map[interface{}]bool) {
+	r.Radius, r.ImageRadius = scale(r.Radius, f), scale(r.ImageRadius, f)
+}
+
+func (r *Clip) resize(f float64, done map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	if p, ok := r.Plotter.(resizer); ok {
+		p.resize(f, done)
+	}
+}
+
+func (r *Connectors) resize(f float64, _ map[interface{}]bool) {
+	for i := range r.Radii {
+		r.Radii[i], r.Stubs[i] = scale(r.Radii[i], f), scale(r.Stubs[i], f)
+	}
+}
+
+func (r *CrossLinks) resize(f float64, done map[interface{}]bool) {
+	for i := range r.Radii {
+		r.Radii[i] = scale(r.Radii[i], f)
+	}
+	r.Bezier.resize(f, done)
+}
+
+func (r *Diff) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+}
+
+func (r *PolarGrid) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	r.Radii = scaled(r.Radii, f)
+}
+
+func (r *Hashed) resize(f float64, done map[interface{}]bool) {
+	if p, ok := r.Plotter.(resizer); ok {
+		p.resize(f, done)
+	}
+}
+
+func (r *Highlight) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	if off := r.Offset; off != nil {
+		r.Offset = func(q feat.Feature) vg.Length { return scale(off(q), f) }
+	}
+}
+
+func (r *Labels) resize(f float64, _ map[interface{}]bool) {
+	r.Radius = scale(r.Radius, f)
+}
+
+func (r *Links) resize(f float64, done map[interface{}]bool) {
+	for i := range r.Radii {
+		r.Radii[i] = scale(r.Radii[i], f)
+	}
+	r.ClipInner, r.ClipOuter = scale(r.ClipInner, f), scale(r.ClipOuter, f)
+	r.Bezier.resize(f, done)
+}
+
+func (r *Markers) resize(f float64, _ map[interface{}]bool) {
+	set := make([]Marker, len(r.Set))
+	for i, m := range r.Set {
+		m.Inner, m.Outer = scale(m.Inner, f), scale(m.Outer, f)
+		set[i] = m
+	}
+	r.Set = set
+	r.LabelOffset = scale(r.LabelOffset, f)
+}
+
+func (r *Mirrored) resize(f float64, done map[interface{}]bool) {
+	r.Inward.resize(f, done)
+	r.Outward.resize(f, done)
+}
+
+func (p *Pie) resize(f float64, done map[interface{}]bool) {
+	p.Blocks.resize(f, done)
+	if p.Labels != nil {
+		p.Labels.resize(f, done)
+	}
+}
+
+// resize scales the callout configuration of the PeakAnnotations. The annotated
+// Scores is not resized, since it is rendered separately.
+func (r *PeakAnnotations) resize(f float64, done map[interface{}]bool) {
+	r.Callouts.resize(f, done)
+}
+
+func (r *Ribbons) resize(f float64, done map[interface{}]bool) {
+	for i := range r.Radii {
+		r.Radii[i] = scale(r.Radii[i], f)
+	}
+	r.ClipInner, r.ClipOuter = scale(r.ClipInner, f), scale(r.ClipOuter, f)
+	r.Bezier.resize(f, done)
+}
+
+func (r *Sail) resize(f float64, done map[interface{}]bool) {
+	r.Radius = scale(r.Radius, f)
+	r.Bezier.resize(f, done)
+}
+
+func (r *Scale) resize(f float64, _ map[interface{}]bool) {
+	r.Radius = scale(r.Radius, f)
+	r.Tick.resize(f)
+	r.Grid.Inner, r.Grid.Outer = scale(r.Grid.Inner, f), scale(r.Grid.Outer, f)
+}
+
+func (r *Scores) resize(f float64, done map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	r.Title.Gap = scale(r.Title.Gap, f)
+	if t, ok := r.Renderer.(*Trace); ok {
+		t.Axis.resize(f, done)
+		if t.PeakLabels != nil && !done[t.PeakLabels] {
+			done[t.PeakLabels] = true
+			t.PeakLabels.Gap = scale(t.PeakLabels.Gap, f)
+		}
+	}
+}
+
+func (r *Spokes) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+}
+
+func (r *Texts) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+}
+
+func (r *Tiles) resize(f float64, _ map[interface{}]bool) {
+	r.Inner, r.Outer = scale(r.Inner, f), scale(r.Outer, f)
+	r.Thickness, r.MinWidth = scale(r.Thickness, f), scale(r.MinWidth, f)
+	r.Title.Gap = scale(r.Title.Gap, f)
+}
+
+// resize scales the control point radius of the Bezier if it has not been scaled.
+func (b *Bezier) resize(f float64, done map[interface{}]bool) {
+	if b == nil || done[b] {
+		return
+	}
+	done[b] = true
+	b.Radius.Length = scale(b.Radius.Length, f)
+}
+
+// resize scales the radial lengths of the Axis if it has not been scaled.
+func (a *Axis) resize(f float64, done map[interface{}]bool) {
+	if a == nil || done[a] {
+		return
+	}
+	done[a] = true
+	a.Tick.resize(f)
+	a.BreakLength = scale(a.BreakLength, f)
+	a.MirrorTo = scale(a.MirrorTo, f)
+}
+
+// resize scales the tick mark length and label offset of the TickConfig.
+func (t *TickConfig) resize(f float64) {
+	t.Length, t.LabelOffset = scale(t.Length, f), scale(t.LabelOffset, f)
+}
@@ -0,0 +1,240 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	imgdraw "image/draw"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/recorder"
+)
+
+// Hashable is a track whose rendering is determined by the inputs summarised by its
+// hash. A Retained replays the recorded rendering of a Hashable track while its hash
+// is unchanged.
+type Hashable interface {
+	// Hash returns a hash of the inputs that determine the rendering of the
+	// track. Renderings of the track with equal hashes must be identical.
+	Hash() uint64
+}
+
+// Hashed is a Hashable plot.Plotter that renders a track whose hash is given by Key, so
+// that tracks that are not themselves Hashable, such as the tracks of this package, may
+// be retained by a Retained. Key summarises the inputs of the track and must be changed
+// whenever they change, for example by incrementing it as a version number, including
+// when the track is scaled by Resize. A Hashed must be held by the Retained as a pointer
+// so that its rendering is identified.
+type Hashed struct {
+	// Plotter is the hashed track.
+	Plotter plot.Plotter
+
+	// Key is the hash of the inputs of the track.
+	Key uint64
+}
+
+// Hash returns the Key of the Hashed.
+func (r *Hashed) Hash() uint64 { return r.Key }
+
+// DrawAt renders the track at cen in the specified drawing area. If the Plotter cannot
+// be drawn about a center point, nothing is rendered.
+func (r *Hashed) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if p, ok := r.Plotter.(drawAter); ok {
+		p.DrawAt(ca, cen)
+	}
+}
+
+// Plot calls Plot on the Plotter.
+func (r *Hashed) Plot(ca draw.Canvas, plt *plot.Plot) { r.Plotter.Plot(ca, plt) }
+
+// XY returns the x and y coordinates of the Plotter, or zero if the Plotter is not an XYer.
+func (r *Hashed) XY() (x, y float64) {
+	if xy, ok := r.Plotter.(XYer); ok {
+		return xy.XY()
+	}
+	return 0, 0
+}
+
+// DrawLayer returns the drawing layer of the Plotter, or the DataLayer if the Plotter is
+// not a Layerer.
+func (r *Hashed) DrawLayer() int {
+	if l, ok := r.Plotter.(Layerer); ok {
+		return l.DrawLayer()
+	}
+	return DataLayer
+}
+
+// Extent returns the extent of the Plotter as calculated by Bounds.
+func (r *Hashed) Extent() vg.Rectangle { return Bounds(r.Plotter) }
+
+// GlyphBoxes returns the glyph boxes of the Plotter, or nil if the Plotter is not a
+// plot.GlyphBoxer.
+func (r *Hashed) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if g, ok := r.Plotter.(plot.GlyphBoxer); ok {
+		return g.GlyphBoxes(plt)
+	}
+	return nil
+}
+
+// Retained is a figure of tracks drawn about a common center that retains the rendering
+// of each track between calls to Render, so that a figure in which one track changes at
+// a time, as in an interactive loop, is redrawn without re-rendering the unchanged
+// tracks. Each Hashable track is rendered into its own recorded command buffer, which
+// is replayed by later calls to Render until the track's hash changes or the track is
+// marked dirty. Tracks that are not Hashable, for example those whose rendering depends
+// on closures, are rendered on each call to Render unless they are wrapped by a Hashed.
+type Retained struct {
+	// Tracks holds the tracks of the figure, which are drawn in the rendering
+	// order of a Layered holding the tracks. Plotters of a Layered are retained
+	// individually. Tracks that cannot be drawn about a center point are ignored.
+	// Tracks are identified by their values, so the retained renderings of tracks
+	// that are not comparable, such as tracks held as struct values rather than
+	// pointers, are not reused.
+	Tracks []plot.Plotter
+
+	// Center is the center of the tracks relative to the minimum point of the
+	// canvas, as returned by Fit.
+	Center vg.Point
+
+	// buffers holds the retained renderings of the tracks.
+	buffers map[plot.Plotter]*trackBuffer
+}
+
+// trackBuffer is the retained rendering of a Hashable track.
+type trackBuffer struct {
+	rec recorder.Canvas
+
+	// hash, area and cen are the hash of the track and the
+	// drawing area and center of the recorded rendering.
+	hash uint64
+	area vg.Rectangle
+	cen  vg.Point
+
+	// dirty indicates that the rendering must not be reused.
+	dirty bool
+}
+
+// NewRetained returns a Retained drawing the tracks centered at cen.
+func NewRetained(cen vg.Point, tracks ...plot.Plotter) *Retained {
+	return &Retained{Tracks: tracks, Center: cen}
+}
+
+// Render draws the figure on ca, replaying the retained renderings of Hashable tracks
+// whose hash, and the drawing area of ca, are unchanged since they were last rendered
+// and rendering the remaining tracks. The renderings of tracks no longer held by the
+// Retained are discarded. An error is returned if a retained rendering cannot be
+// replayed.
+func (r *Retained) Render(ca draw.Canvas) error {
+	used := make(map[plot.Plotter]bool)
+	err := r.render(ca, ca.Min.Add(r.Center), r.Tracks, used)
+	for p := range r.buffers {
+		if !used[p] {
+			delete(r.buffers, p)
+		}
+	}
+	return err
+}
+
+func (r *Retained) render(ca draw.Canvas, cen vg.Point, tracks []plot.Plotter, used map[plot.Plotter]bool) error {
+	for _, p := range NewLayered(tracks...).Sorted() {
+		if l, ok := p.(*Layered); ok {
+			if err := r.render(ca, cen, l.Plotters, used); err != nil {
+				return err
+			}
+			continue
+		}
+		d, ok := p.(drawAter)
+		if !ok {
+			continue
+		}
+		h, ok := p.(Hashable)
+		if !ok || !isComparable(p) {
+			d.DrawAt(ca, cen)
+			continue
+		}
+
+		used[p] = true
+		if r.buffers == nil {
+			r.buffers = make(map[plot.Plotter]*trackBuffer)
+		}
+		buf, ok := r.buffers[p]
+		if !ok {
+			buf = &trackBuffer{dirty: true}
+			r.buffers[p] = buf
+		}
+		hash := h.Hash()
+		if buf.dirty || buf.hash != hash || buf.area != ca.Rectangle || buf.cen != cen {
+			buf.rec.Reset()
+			d.DrawAt(draw.Canvas{Canvas: recording(&buf.rec, ca.Canvas), Rectangle: ca.Rectangle}, cen)
+			buf.hash, buf.area, buf.cen, buf.dirty = hash, ca.Rectangle, cen, false
+		}
+		if err := buf.rec.ReplayOn(ca.Canvas); err != nil {
+			buf.dirty = true
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkDirty marks the retained renderings of the provided tracks as stale, so that the
+// tracks are rendered by the next call to Render whether or not their hashes have
+// changed. If no track is provided, the renderings of all tracks are marked. MarkDirty
+// is an escape hatch for changes to the inputs of a track that are not reflected by its
+// hash.
+func (r *Retained) MarkDirty(tracks ...plot.Plotter) {
+	if len(tracks) == 0 {
+		for _, buf := range r.buffers {
+			buf.dirty = true
+		}
+		return
+	}
+	var mark func([]plot.Plotter)
+	mark = func(ps []plot.Plotter) {
+		for _, p := range ps {
+			if l, ok := p.(*Layered); ok {
+				mark(l.Plotters)
+				continue
+			}
+			if !isComparable(p) {
+				continue
+			}
+			if buf, ok := r.buffers[p]; ok {
+				buf.dirty = true
+			}
+		}
+	}
+	mark(tracks)
+}
+
+// isComparable returns whether p may be used as a map key.
+func isComparable(p interface{}) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	_ = p == p
+	return true
+}
+
+// recording returns the canvas recording to rec on behalf of dst. If dst is a raster
+// canvas the recording reports its resolution, so that tracks snapping strokes to the
+// pixel grid render to the recording as they would to dst.
+func recording(rec *recorder.Canvas, dst vg.Canvas) vg.Canvas {
+	if rc, ok := dst.(rasterCanvas); ok {
+		return rasterRecording{Canvas: rec, dst: rc}
+	}
+	return rec
+}
+
+// rasterRecording is a recorder canvas standing in for a raster canvas.
+type rasterRecording struct {
+	*recorder.Canvas
+	dst rasterCanvas
+}
+
+func (c rasterRecording) DPI() float64         { return c.dst.DPI() }
+func (c rasterRecording) Image() imgdraw.Image { return c.dst.Image() }
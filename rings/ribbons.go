@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"image/color"
-	"math"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
@@ -29,6 +28,12 @@ type Ribbons struct {
 	// Radii indicates the distance of the ribbon end points from the center of the plot.
 	Radii [2]vg.Length
 
+	// SkipFiltered specifies that ribbons with an end held by a location removed
+	// by the keep filter of a Blocks end, set by the BlockKeep option, are not
+	// rendered. SkipFiltered is set by NewRibbons when either end maps arcs
+	// through a Blocks with a keep filter.
+	SkipFiltered bool
+
 	// Twist indicates how feature orientation should be rendered.
 	//
 	// None indicates no explicit twist; ribbons are draw so that the start positions
@@ -64,11 +69,43 @@ type Ribbons struct {
 	// behaviour is over-ridden if the feature describing the block is a FillColorer.
 	Color color.Color
 
+	// Pattern determines the fill pattern of each ribbon. If Pattern is not nil each
+	// ribbon is rendered filled with the pattern, over any fill color. This behaviour
+	// is over-ridden if the Pair is a FillPatterner.
+	Pattern Patterner
+
+	// FillRule determines how the fill color and pattern fill a ribbon whose outline
+	// overlaps itself, as happens when the arcs of its ends overlap. If FillRule is
+	// DefaultRule the rule of the canvas is used.
+	FillRule FillRule
+
 	// LineStyle determines the line style of each ribbon. LineStyle behaviour is over-ridden
 	// for end point arcs if the feature describing an end point is a LineStyler and for
 	// Bézier curves if the Pair is a LineStyler.
 	LineStyle draw.LineStyle
 
+	// ArcLineStyle and CurveLineStyle, if not nil, specify the line styles of the
+	// end point arcs and of the Bézier curves of each ribbon in place of LineStyle.
+	// When either is not nil the edges are stroked separately after the ribbon is
+	// filled, with a nil edge style falling back to LineStyle. The styles are
+	// over-ridden for end point arcs if the feature describing an end point is a
+	// LineStyler and for Bézier curves if the Pair is a LineStyler.
+	ArcLineStyle, CurveLineStyle *draw.LineStyle
+
+	// Tolerance, if positive, is the tolerance with which the flattened Bézier
+	// curves of the filled and stroked ribbon outlines are simplified by Simplify.
+	Tolerance vg.Length
+
+	// ClipInner and ClipOuter, if either is positive, define an annulus about the
+	// center of the plot outside of which ribbons are not drawn. A zero ClipOuter
+	// places no limit on the outer extent of ribbons. Ribbon fills are intersected
+	// with the annulus and ribbon edges are cut where they cross its boundaries,
+	// as described for Clip.
+	ClipInner, ClipOuter vg.Length
+
+	// Layer specifies the drawing layer of the Ribbons when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
@@ -82,18 +119,33 @@ func NewRibbons(fp []Pair, ends [2]ArcOfer, r [2]vg.Length) (*Ribbons, error) {
 			if f.End() < f.Start() {
 				return nil, errors.New("rings: inverted feature")
 			}
-			if _, err := ends[i].ArcOf(nil, f); err != nil {
+			if _, err := ends[i].ArcOf(nil, f); err != nil && !isFiltered(ends[i], f) {
 				return nil, err
 			}
 		}
 	}
 	return &Ribbons{
-		Set:   fp,
-		Ends:  ends,
-		Radii: r,
+		Set:          fp,
+		Ends:         ends,
+		Radii:        r,
+		SkipFiltered: filtering(ends[0]) || filtering(ends[1]),
 	}, nil
 }
 
+// filtered returns whether fp is not rendered because an end is held by a filtered
+// location and SkipFiltered is true.
+func (r *Ribbons) filtered(fp Pair) bool {
+	if !r.SkipFiltered {
+		return false
+	}
+	for j, f := range fp.Features() {
+		if isFiltered(r.Ends[j], f) {
+			return true
+		}
+	}
+	return false
+}
+
 // twist returns alters the ribbon twist depending on the relative orientation
 // of the provided features and the Twist flags of the receiver.
 func (r *Ribbons) twist(angles *[4]Angle, fp Pair) {
@@ -165,12 +217,20 @@ func (r *Ribbons) DrawAt(ca draw.Canvas, cen vg.Point) {
 		return
 	}
 
+	if r.ClipInner > 0 || r.ClipOuter > 0 {
+		ca = r.clipCanvas(ca, cen)
+	}
+
 	// Check if we have a Bézier and we want more than one segment in the curve.
 	bez := r.Bezier != nil && r.Bezier.Segments > 1
 
+	radii := r.endRadii()
 	var pa vg.Path
 loop:
 	for _, fp := range r.Set {
+		if r.filtered(fp) {
+			continue
+		}
 		p := fp.Features()
 		var min, max [2]int
 		for j, loc := range [2]feat.Feature{p[0].Location(), p[1].Location()} {
@@ -197,14 +257,14 @@ loop:
 		r.twist(&angles, fp)
 
 		pa = pa[:0]
-		pa.Move(cen.Add(Rectangular(angles[0], r.Radii[0])))
+		pa.Move(RectangularAt(cen, angles[0], radii[0]))
 		var arcs [2]int
-		for j, rad := range r.Radii {
+		for j, rad := range radii {
 			// Arc from angles[j*2] to angles[j*2+1] with radius rad around cen.
 			arcs[j] = len(pa) // Remember where the arcs are.
 			start := angles[j*2]
 			end := angles[j*2+1]
-			pa.Arc(cen, rad, float64(start), float64(end-start))
+			r.endEdge(&pa, cen, j, start, end, rad)
 
 			// Bézier from angles[j*2+1]@radius[j] to angles[(j*2+2)%4]@radius[1-j]
 			// through r.Bezier if it is not nil and we wanted more than 1 segment;
@@ -212,16 +272,18 @@ loop:
 			next := angles[(j*2+2)%4]
 			if bez {
 				b := bezier.New(
-					r.Bezier.ControlPoints(
+					endControlPoints(
+						r.Bezier,
+						[2]ArcOfer{r.Ends[j], r.Ends[1-j]},
 						[2]Angle{end, next},
-						[2]vg.Length{rad, r.Radii[1-j]},
+						[2]vg.Length{rad, radii[1-j]},
 					)...,
 				)
 				for i := 1; i <= r.Bezier.Segments; i++ {
 					pa.Line(cen.Add(b.Point(float64(i) / float64(r.Bezier.Segments))))
 				}
 			} else {
-				pa.Line(cen.Add(Rectangular(next, r.Radii[1-j])))
+				pa.Line(RectangularAt(cen, next, radii[1-j]))
 			}
 		}
 
@@ -231,20 +293,34 @@ loop:
 		} else {
 			col = r.Color
 		}
-		if col != nil {
-			ca.SetColor(col)
-			ca.Fill(pa)
+		var pat Patterner
+		if p, ok := fp.(FillPatterner); ok {
+			pat = p.FillPattern()
+		} else {
+			pat = r.Pattern
+		}
+		if col != nil || pat != nil {
+			fill := r.FillRule.outline(Simplify(pa, r.Tolerance))
+			if col != nil {
+				ca.SetColor(col)
+				ca.Fill(fill)
+			}
+			if pat != nil {
+				pat.Fill(ca, fill)
+			}
 		}
 
-		if ls, ok := fp.(LineStyler); ok || (r.LineStyle.Color != nil && r.LineStyle.Width != 0) {
+		if r.ArcLineStyle != nil || r.CurveLineStyle != nil {
+			r.strokeEdges(ca, cen, fp, pa, arcs, angles, radii)
+		} else if ls, ok := fp.(LineStyler); ok || (r.LineStyle.Color != nil && r.LineStyle.Width != 0) {
 			// Change Arc vg.PathComps to Move vg.PathComps where necessary.
-			for j, rad := range r.Radii {
+			for j, rad := range radii {
 				if _, ok := p[j].(LineStyler); ok {
 					// The feature wants to define its own line style, so don't draw arc.
 					end := angles[j*2+1]
 					pa[arcs[j]] = vg.PathComp{
 						Type: vg.MoveComp,
-						Pos:  cen.Add(Rectangular(end, rad)),
+						Pos:  RectangularAt(cen, end, rad),
 					}
 				}
 			}
@@ -257,19 +333,19 @@ loop:
 			}
 			if sty.Color != nil && sty.Width != 0 {
 				ca.SetLineStyle(sty)
-				ca.Stroke(pa)
+				ca.Stroke(Simplify(pa, r.Tolerance))
 			}
 		}
 
 		// Draw feature ends according to the feature's linestyle if it has one.
-		for j, rad := range r.Radii {
+		for j, rad := range radii {
 			if f, ok := p[j].(LineStyler); ok {
 				pa = pa[:0]
 				//Arc from angles[j*2] to angles[j*2+1] with radius rad around cen.
 				start := angles[j*2]
 				end := angles[j*2+1]
-				pa.Move(cen.Add(Rectangular(start, rad)))
-				pa.Arc(cen, rad, float64(start), float64(end-start))
+				pa.Move(RectangularAt(cen, start, rad))
+				r.endEdge(&pa, cen, j, start, end, rad)
 				ca.SetLineStyle(f.LineStyle())
 				ca.Stroke(pa)
 			}
@@ -277,6 +353,93 @@ loop:
 	}
 }
 
+// clipCanvas returns a canvas clipping rendering on ca to the clipping annulus of the
+// Ribbons about cen. A zero ClipOuter is taken to be the distance from cen to the
+// furthest corner of ca.
+func (r *Ribbons) clipCanvas(ca draw.Canvas, cen vg.Point) draw.Canvas {
+	outer := r.ClipOuter
+	if outer == 0 {
+		for _, p := range []vg.Point{ca.Min, ca.Max, {X: ca.Min.X, Y: ca.Max.Y}, {X: ca.Max.X, Y: ca.Min.Y}} {
+			if d := distance(cen, p); d > outer {
+				outer = d
+			}
+		}
+	}
+	c := Clip{Inner: r.ClipInner, Outer: outer, FillRule: r.FillRule}
+	return c.canvas(ca, cen)
+}
+
+// endRadii returns the radii of the ends of the ribbons. The end at an ExternalAnchor
+// is at the radius of the ends of the anchor's ribbon end.
+func (r *Ribbons) endRadii() [2]vg.Length {
+	radii := r.Radii
+	for j, e := range r.Ends {
+		if a, ok := anchorOf(e); ok {
+			radii[j] = a.radius()
+		}
+	}
+	return radii
+}
+
+// endEdge appends the edge of the jth end of a ribbon from the angle start to end at
+// radius rad to pa: an arc about cen, or a straight line if the end is an ExternalAnchor.
+func (r *Ribbons) endEdge(pa *vg.Path, cen vg.Point, j int, start, end Angle, rad vg.Length) {
+	if _, ok := anchorOf(r.Ends[j]); ok {
+		pa.Line(RectangularAt(cen, end, rad))
+		return
+	}
+	pa.Arc(cen, rad, float64(start), float64(end-start))
+}
+
+// strokeEdges strokes the end point arcs and Bézier curves of the outline pa of the
+// ribbon of fp separately according to the ArcLineStyle and CurveLineStyle of the
+// Ribbons. The end point arcs of LineStyler features are not stroked. The arcs of
+// the outline begin at the indices in arcs and sweep the angles of the ribbon ends
+// at radii.
+func (r *Ribbons) strokeEdges(ca draw.Canvas, cen vg.Point, fp Pair, pa vg.Path, arcs [2]int, angles [4]Angle, radii [2]vg.Length) {
+	arcSty, curveSty := r.LineStyle, r.LineStyle
+	if r.ArcLineStyle != nil {
+		arcSty = *r.ArcLineStyle
+	}
+	if r.CurveLineStyle != nil {
+		curveSty = *r.CurveLineStyle
+	}
+	if ls, ok := fp.(LineStyler); ok {
+		curveSty = ls.LineStyle()
+		if r.ArcLineStyle == nil {
+			arcSty = curveSty
+		}
+	}
+
+	p := fp.Features()
+	var edge vg.Path
+	for j, rad := range radii {
+		start, end := angles[j*2], angles[j*2+1]
+		if _, ok := p[j].(LineStyler); !ok && arcSty.Color != nil && arcSty.Width != 0 {
+			edge = edge[:0]
+			edge.Move(RectangularAt(cen, start, rad))
+			edge = append(edge, pa[arcs[j]])
+			ca.SetLineStyle(arcSty)
+			ca.Stroke(edge)
+		}
+
+		if curveSty.Color != nil && curveSty.Width != 0 {
+			last := len(pa)
+			if j == 0 {
+				last = arcs[1]
+			}
+			edge = edge[:0]
+			edge.Move(RectangularAt(cen, end, rad))
+			edge = append(edge, pa[arcs[j]+1:last]...)
+			ca.SetLineStyle(curveSty)
+			ca.Stroke(Simplify(edge, r.Tolerance))
+		}
+	}
+}
+
+// DrawLayer returns the drawing layer of the Ribbons.
+func (r *Ribbons) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Ribbons' X and Y values as the drawing coordinates.
 func (r *Ribbons) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
@@ -289,9 +452,10 @@ func (r *Ribbons) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 		return nil
 	}
 
-	rad := float64(r.Radii[0])
-	if float64(r.Radii[1]) > rad {
-		rad = float64(r.Radii[1])
+	radii := r.endRadii()
+	rad := radii[0]
+	if radii[1] > rad {
+		rad = radii[1]
 	}
 
 	// If draw a Bézier we need to see if the radius is increased,
@@ -300,6 +464,9 @@ func (r *Ribbons) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 	if r.Bezier != nil && r.Bezier.Segments > 1 {
 	loop:
 		for _, fp := range r.Set {
+			if r.filtered(fp) {
+				continue
+			}
 			p := fp.Features()
 			var min, max [2]int
 			for j, loc := range [2]feat.Feature{p[0].Location(), p[1].Location()} {
@@ -324,18 +491,20 @@ func (r *Ribbons) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 			}
 			r.twist(&angles, fp)
 
-			for j := range r.Radii {
+			for j := range radii {
 				end := angles[j*2+1]
 				next := angles[(j*2+2)%4]
 				b := bezier.New(
-					r.Bezier.ControlPoints(
+					endControlPoints(
+						r.Bezier,
+						[2]ArcOfer{r.Ends[j], r.Ends[1-j]},
 						[2]Angle{end, next},
-						[2]vg.Length{r.Radii[j], r.Radii[1-j]},
+						[2]vg.Length{radii[j], radii[1-j]},
 					)...,
 				)
 				for k := 0; k <= r.Bezier.Segments; k++ {
 					e := b.Point(float64(k) / float64(r.Bezier.Segments))
-					if d := math.Hypot(float64(e.X), float64(e.Y)); d > rad {
+					if _, d := Polar(e); d > rad {
 						rad = d
 					}
 				}
@@ -343,12 +512,16 @@ func (r *Ribbons) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 		}
 	}
 
+	if r.ClipOuter > 0 && rad > r.ClipOuter {
+		rad = r.ClipOuter
+	}
+
 	return []plot.GlyphBox{{
 		X: plt.X.Norm(r.X),
 		Y: plt.Y.Norm(r.Y),
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{vg.Length(-rad), vg.Length(-rad)},
-			Max: vg.Point{vg.Length(rad), vg.Length(rad)},
+			Min: vg.Point{-rad, -rad},
+			Max: vg.Point{rad, rad},
 		},
 	}}
 }
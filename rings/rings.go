@@ -7,11 +7,21 @@
 //
 // The rings package borrows significantly from the ideas of Circos and shares some implementation
 // details in order to run as a work-a-like. Circos is available from http://circos.ca/.
+//
+// The plotters of the package may be drawn concurrently, for example to render the same
+// plot.Plot to canvases of several sizes. Values shared between plotters that are updated
+// during drawing, HitTester, Range, TitleStack and the Rand of a Bezier, serialise their
+// use. Plotters and the values they hold must not be modified while they are being drawn.
+// State recorded by drawing, such as the geometry held by a HitTester or the features
+// reported by Labels.Placed, is recorded by each of the concurrent drawings. Curves
+// perturbed by a Bezier are reproducible under concurrent drawing only when the Bezier
+// has a Seed and no Rand.
 package rings
 
 import (
 	"image/color"
 
+	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
 
 	"github.com/biogo/biogo/feat"
@@ -58,6 +68,19 @@ type FillColorer interface {
 	FillColor() color.Color
 }
 
+// FillPatterner is a type that can define its fill pattern. For the purposes of the rings
+// package a FillPatterner that returns a nil Patterner is not rendered with a pattern.
+type FillPatterner interface {
+	FillPattern() Patterner
+}
+
+// SectorPather is a type that can define the outline used to render it within a sector.
+// For the purposes of the rings package a SectorPather is rendered by appending the path
+// returned by SectorPath to pa in place of the outline of the complete sector.
+type SectorPather interface {
+	SectorPath(pa *vg.Path, s Sector)
+}
+
 // XYer is a type that returns its x and y coordinates.
 type XYer interface {
 	XY() (x, y float64)
@@ -5,12 +5,19 @@
 package rings_test
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"image"
 	"image/color"
+	imgdraw "image/draw"
 	"math"
 	"math/rand"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gonum/plot"
@@ -18,8 +25,12 @@ import (
 	"github.com/gonum/plot/plotter"
 	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
+	"github.com/gonum/plot/vg/vgimg"
+	"github.com/gonum/plot/vg/vgsvg"
 
 	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/bezier"
+	ramp "github.com/biogo/graphics/palette"
 	"github.com/biogo/graphics/rings"
 
 	"gopkg.in/check.v1"
@@ -76,6 +87,14 @@ func (p fp) LineStyle() draw.LineStyle {
 	return p.sty
 }
 
+// vp is a Pair with an associated value that is not a LineStyler.
+type vp struct {
+	feats [2]feat.Feature
+	value float64
+}
+
+func (p vp) Features() [2]feat.Feature { return p.feats }
+
 func randomFeatures(n, min, max int, single bool, sty draw.LineStyle) []feat.Feature {
 	data := make([]feat.Feature, n)
 	for i := range data {
@@ -4368,71 +4387,71 @@ func (s *S) TestScoresAxis(c *check.C) {
 				setLineDash{dashes: []vg.Length(nil), offsets: 0},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 112.55576396611607, Y: 154.6113994575564}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 112.66133393899389, Y: 156.60861125925058}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 112.45019399323826, Y: 152.6141876558622}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				push{},
-				translate{x: 112.76690391187171, y: 158.60582306094477},
+				translate{x: 112.34462402036044, y: 150.616975854168},
 				rotate{angle: 1.517986797501079},
-				translate{x: -112.76690391187171, y: -158.60582306094477},
+				translate{x: -112.34462402036044, y: -150.616975854168},
 				setColor{col: color.Gray16{Y: 0x0}},
-				fillString{font: "Helvetica", size: 5, x: 111.37652305249671, y: 156.25596954531977, str: "0"},
+				fillString{font: "Helvetica", size: 5, x: 110.95424316098544, y: 148.267122338543, str: "0"},
 				pop{},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 100.9053617895666, Y: 155.22722429934367}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 101.01093176244441, Y: 157.22443610103787}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 100.79979181668878, Y: 153.23001249764945}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				push{},
-				translate{x: 101.11650173532223, y: 159.22164790273206},
+				translate{x: 100.69422184381096, y: 151.23280069595526},
 				rotate{angle: 1.517986797501079},
-				translate{x: -101.11650173532223, y: -159.22164790273206},
+				translate{x: -100.69422184381096, y: -151.23280069595526},
 				setColor{col: color.Gray16{Y: 0x0}},
-				fillString{font: "Helvetica", size: 5, x: 99.72612087594723, y: 156.87179438710706, str: "3"},
+				fillString{font: "Helvetica", size: 5, x: 99.30384098443596, y: 148.88294718033026, str: "3"},
 				pop{},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 89.25495961301712, Y: 155.84304914113093}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 89.36052958589494, Y: 157.84026094282513}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 89.1493896401393, Y: 153.84583733943674}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				push{},
-				translate{x: 89.46609955877277, y: 159.83747274451935},
+				translate{x: 89.04381966726149, y: 151.84862553774255},
 				rotate{angle: 1.517986797501079},
-				translate{x: -89.46609955877277, y: -159.83747274451935},
+				translate{x: -89.04381966726149, y: -151.84862553774255},
 				setColor{col: color.Gray16{Y: 0x0}},
-				fillString{font: "Helvetica", size: 5, x: 88.07571869939777, y: 157.48761922889435, str: "6"},
+				fillString{font: "Helvetica", size: 5, x: 87.65343880788649, y: 149.49877202211755, str: "6"},
 				pop{},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 77.60455743646764, Y: 156.45887398291822}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 77.71012740934546, Y: 158.4560857846124}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 77.49898746358983, Y: 154.46166218122403}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				push{},
-				translate{x: 77.81569738222328, y: 160.4532975863066},
+				translate{x: 77.39341749071201, y: 152.46445037952984},
 				rotate{angle: 1.517986797501079},
-				translate{x: -77.81569738222328, y: -160.4532975863066},
+				translate{x: -77.39341749071201, y: -152.46445037952984},
 				setColor{col: color.Gray16{Y: 0x0}},
-				fillString{font: "Helvetica", size: 5, x: 76.42531652284828, y: 158.1034440706816, str: "9"},
+				fillString{font: "Helvetica", size: 5, x: 76.00303663133701, y: 150.11459686390484, str: "9"},
 				pop{},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 108.67229657393293, Y: 154.8166744048188}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 108.72508156037182, Y: 155.8152803056659}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 108.619511587494, Y: 153.81806850397172}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 104.78882918174975, Y: 155.02194935208124}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 104.84161416818867, Y: 156.02055525292835}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 104.73604419531085, Y: 154.02334345123413}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 97.02189439738343, Y: 155.43249924660608}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 97.07467938382234, Y: 156.4311051474532}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 96.96910941094453, Y: 154.43389334575897}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 93.13842700520027, Y: 155.63777419386852}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 93.1912119916392, Y: 156.6363800947156}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 93.08564201876138, Y: 154.6391682930214}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 85.37149222083396, Y: 156.04832408839337}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 85.42427720727287, Y: 157.04692998924045}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 85.31870723439505, Y: 155.04971818754626}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				stroke{path: vg.Path{
 					{Type: vg.MoveComp, Pos: vg.Point{X: 81.4880248286508, Y: 156.25359903565578}, Radius: 0, Start: 0, Angle: 0},
-					{Type: vg.LineComp, Pos: vg.Point{X: 81.5408098150897, Y: 157.2522049365029}, Radius: 0, Start: 0, Angle: 0},
+					{Type: vg.LineComp, Pos: vg.Point{X: 81.43523984221189, Y: 155.2549931348087}, Radius: 0, Start: 0, Angle: 0},
 				}},
 				push{},
 				translate{x: 95.08016070129186, y: 155.5351367202373},
@@ -4536,3 +4555,7869 @@ func (s *S) TestScoresAxis(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestHitTester(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "a"},
+		&fs{start: 0, end: 100, name: "b"},
+	}
+	arcs := rings.NewGappedArcs(rings.Arc{0, rings.Complete}, chr, 0)
+	b, err := rings.NewBlocks(chr, arcs, 80, 100)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(
+		makeScorers(chr[0].(*fs), 2, 1, func(i, _ int) float64 { return float64(i) }),
+		arcs, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}},
+	)
+	c.Assert(err, check.Equals, nil)
+	lp := &fp{
+		feats: [2]*fs{
+			{start: 50, end: 50, location: chr[0], style: plotter.DefaultLineStyle},
+			{start: 50, end: 50, location: chr[1], style: plotter.DefaultLineStyle},
+		},
+		sty: plotter.DefaultLineStyle,
+	}
+	ls, err := rings.NewLinks([]rings.Pair{lp}, [2]rings.ArcOfer{arcs, arcs}, [2]vg.Length{50, 50})
+	c.Assert(err, check.Equals, nil)
+	ls.LineStyle = plotter.DefaultLineStyle
+
+	h := &rings.HitTester{Tolerance: 1}
+	b.HitTester = h
+	sc.HitTester = h
+	ls.HitTester = h
+
+	cen := vg.Point{X: 150, Y: 150}
+	ca := draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300)
+	for _, r := range []interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}{b, sc, ls} {
+		r.DrawAt(ca, cen)
+	}
+
+	for i, t := range []struct {
+		p    vg.Point
+		want []rings.HitResult
+	}{
+		{
+			p:    cen.Add(rings.Rectangular(math.Pi/4, 90)),
+			want: []rings.HitResult{{Track: b, Feature: chr[0], Pos: 0.25}},
+		},
+		{
+			p:    cen.Add(rings.Rectangular(3*math.Pi/2, 100)),
+			want: []rings.HitResult{{Track: b, Feature: chr[1], Pos: 0.5}},
+		},
+		{
+			p:    cen.Add(rings.Rectangular(math.Pi/4, 55)),
+			want: []rings.HitResult{{Track: sc, Feature: sc.Set[0], Pos: 0.5}},
+		},
+		{
+			p: cen.Add(vg.Point{X: 0.5, Y: 45}),
+			want: []rings.HitResult{
+				{Track: sc, Feature: sc.Set[0], Pos: math.Atan2(45, 0.5) / (math.Pi / 2)},
+				{Track: ls, Pair: lp, Pos: 0.05},
+			},
+		},
+		{
+			p:    cen.Add(vg.Point{X: 0.5, Y: 20}),
+			want: []rings.HitResult{{Track: ls, Pair: lp, Pos: 0.3}},
+		},
+		{
+			p:    cen.Add(vg.Point{X: 2, Y: 20}),
+			want: nil,
+		},
+		{
+			p:    cen.Add(rings.Rectangular(math.Pi/4, 70)),
+			want: nil,
+		},
+	} {
+		got := h.Hit(t.p)
+		c.Assert(len(got), check.Equals, len(t.want), check.Commentf("Test %d", i))
+		for j := range got {
+			c.Check(got[j].Track, check.Equals, t.want[j].Track, check.Commentf("Test %d hit %d", i, j))
+			c.Check(got[j].Feature, check.Equals, t.want[j].Feature, check.Commentf("Test %d hit %d", i, j))
+			c.Check(got[j].Pair, check.Equals, t.want[j].Pair, check.Commentf("Test %d hit %d", i, j))
+			c.Check(math.Abs(got[j].Pos-t.want[j].Pos) < 1e-9, check.Equals, true, check.Commentf("Test %d hit %d: %v", i, j, got[j].Pos))
+		}
+	}
+
+	h.Reset()
+	c.Check(h.Hit(cen.Add(rings.Rectangular(math.Pi/4, 90))), check.IsNil)
+}
+
+func (s *S) TestDescribe(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "a"},
+		&fs{start: 0, end: 100, name: "b"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.RGBA{R: 0xff, A: 0xff}
+
+	g, err := b.Describe()
+	c.Assert(err, check.Equals, nil)
+	var buf bytes.Buffer
+	c.Assert(g.EncodeTo(&buf), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `{"type":"blocks","blocks":[`+
+		`{"name":"a","arc":{"theta":0,"phi":3.1415926535897936},"inner":80,"outer":100,"fill":"#ff0000ff"},`+
+		`{"name":"b","arc":{"theta":3.141592653589793,"phi":3.1415926535897936},"inner":80,"outer":100,"fill":"#ff0000ff"}]}`+"\n")
+
+	sc, err := rings.NewScores(
+		makeScorers(chr[0].(*fs), 2, 2, func(i, j int) float64 {
+			if i == 1 && j == 1 {
+				return math.NaN()
+			}
+			return float64(i + j)
+		}),
+		b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}},
+	)
+	c.Assert(err, check.Equals, nil)
+	g, err = sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	buf.Reset()
+	c.Assert(g.EncodeTo(&buf), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `{"type":"scores","scores":[`+
+		`{"name":"a#0","arc":{"theta":0,"phi":1.5707963267948968},"radii":[40,60]},`+
+		`{"name":"a#1","arc":{"theta":1.5707963267948968,"phi":1.5707963267948968},"radii":[60,null]}]}`+"\n")
+
+	lp := &fp{
+		feats: [2]*fs{
+			{start: 25, end: 25, name: "x", location: chr[0], style: plotter.DefaultLineStyle},
+			{start: 50, end: 50, name: "y", location: chr[1], style: plotter.DefaultLineStyle},
+		},
+		sty: plotter.DefaultLineStyle,
+	}
+	ls, err := rings.NewLinks([]rings.Pair{lp}, [2]rings.ArcOfer{b, b}, [2]vg.Length{40, 40})
+	c.Assert(err, check.Equals, nil)
+	ls.Bezier = &rings.Bezier{
+		Segments: 10,
+		Radius:   rings.LengthDist{Length: 20, Min: floatPtr(0.5), Max: floatPtr(1.5)},
+		Crest:    &rings.FactorDist{Factor: 2, Min: floatPtr(0.7), Max: floatPtr(1.4)},
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+	g, err = ls.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(len(g.Links), check.Equals, 1)
+	l := g.Links[0]
+	c.Check(l.Names, check.Equals, [2]string{"x", "y"})
+	c.Check(l.Radii, check.Equals, [2]float64{40, 40})
+	c.Check(len(l.Control), check.Equals, 5)
+
+	// Rendering with the same random state must produce the described curve.
+	ls.Bezier.Rand = rand.New(rand.NewSource(1))
+	tc := &canvas{dpi: defaultDPI}
+	cen := vg.Point{X: 150, Y: 150}
+	ls.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var path vg.Path
+	for _, a := range tc.actions {
+		if s, ok := a.(stroke); ok {
+			path = s.path
+		}
+	}
+	c.Assert(len(path), check.Equals, ls.Bezier.Segments+1)
+	cp := make([]vg.Point, len(l.Control))
+	for i, p := range l.Control {
+		cp[i] = vg.Point{X: vg.Length(p.X), Y: vg.Length(p.Y)}
+	}
+	curve := bezier.New(cp...)
+	for i, comp := range path[1:] {
+		want := cen.Add(curve.Point(float64(i+1) / float64(ls.Bezier.Segments)))
+		c.Check(math.Hypot(float64(comp.Pos.X-want.X), float64(comp.Pos.Y-want.Y)) < 1e-9, check.Equals, true,
+			check.Commentf("segment %d: got %v want %v", i, comp.Pos, want))
+	}
+
+	// An end with a location not held by the Blocks is reported as an error.
+	lp.feats[1].location = &fs{start: 0, end: 100, name: "z"}
+	g, err = ls.Describe()
+	c.Check(g, check.IsNil)
+	c.Check(err, check.ErrorMatches, "rings: no arc for feature location: .*")
+}
+
+func (s *S) TestIdeogram(c *check.C) {
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 50, name: "chr2"},
+	}
+	bands := map[feat.Feature][]rings.Band{
+		chr[0]: {
+			{Name: "q21", Start: 60, End: 100, Stain: "gpos50"},
+			{Name: "p11.1", Start: 30, End: 45, Stain: "acen"},
+			{Name: "p21", Start: 0, End: 30, Stain: "gneg"},
+			{Name: "q11.1", Start: 45, End: 60, Stain: "acen"},
+		},
+	}
+	b, err := rings.NewIdeogram(chr, bands, nil, rings.Arc{0, rings.Complete / 2}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.RGBA{B: 0xff, A: 0xff}
+	p.Add(b)
+
+	p.HideAxes()
+
+	tc := &canvas{dpi: defaultDPI}
+	p.Draw(draw.NewCanvas(tc, 300, 300))
+
+	base.append(
+		setColor{col: color.Gray{Y: 0xff}},
+		fill{path: vg.Path{
+			{Type: vg.MoveComp, Pos: vg.Point{X: 232.5, Y: 152.5}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 80, Start: 0, Angle: 0.6283185307179586},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 100, Start: 0.6283185307179586, Angle: -0.6283185307179586},
+			{Type: vg.CloseComp, Pos: vg.Point{X: 0, Y: 0}, Radius: 0, Start: 0, Angle: 0},
+		}},
+		setColor{col: color.RGBA{R: 0xff, G: 0x0, B: 0x0, A: 0xff}},
+		fill{path: vg.Path{
+			{Type: vg.MoveComp, Pos: vg.Point{X: 217.22135954999578, Y: 199.52282018339787}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.LineComp, Pos: vg.Point{X: 205.40067270632258, Y: 225.31152949374524}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.LineComp, Pos: vg.Point{X: 233.40169943749473, Y: 211.27852522924732}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.CloseComp, Pos: vg.Point{X: 0, Y: 0}, Radius: 0, Start: 0, Angle: 0},
+		}},
+		setColor{col: color.RGBA{R: 0xff, G: 0x0, B: 0x0, A: 0xff}},
+		fill{path: vg.Path{
+			{Type: vg.MoveComp, Pos: vg.Point{X: 177.22135954999578, Y: 228.5845213036123}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.LineComp, Pos: vg.Point{X: 205.40067270632258, Y: 225.31152949374524}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.LineComp, Pos: vg.Point{X: 183.40169943749476, Y: 247.60565162951536}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.CloseComp, Pos: vg.Point{X: 0, Y: 0}, Radius: 0, Start: 0, Angle: 0},
+		}},
+		setColor{col: color.Gray{Y: 0x7f}},
+		fill{path: vg.Path{
+			{Type: vg.MoveComp, Pos: vg.Point{X: 177.22135954999578, Y: 228.5845213036123}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 80, Start: 1.2566370614359172, Angle: 0.8377580409572785},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 100, Start: 2.0943951023931957, Angle: -0.8377580409572785},
+			{Type: vg.CloseComp, Pos: vg.Point{X: 0, Y: 0}, Radius: 0, Start: 0, Angle: 0},
+		}},
+		setColor{col: color.RGBA{R: 0x0, G: 0x0, B: 0xff, A: 0xff}},
+		fill{path: vg.Path{
+			{Type: vg.MoveComp, Pos: vg.Point{X: 112.49999999999993, Y: 221.78203230275506}, Radius: 0, Start: 0, Angle: 0},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 80, Start: 2.0943951023931966, Angle: 1.0471975511965979},
+			{Type: vg.ArcComp, Pos: vg.Point{X: 152.5, Y: 152.5}, Radius: 100, Start: 3.1415926535897944, Angle: -1.0471975511965979},
+			{Type: vg.CloseComp, Pos: vg.Point{X: 0, Y: 0}, Radius: 0, Start: 0, Angle: 0},
+		}},
+	)
+	c.Check(tc.actions, check.DeepEquals, base.actions)
+	if ok := reflect.DeepEqual(tc.actions, base.actions); *pics && !ok || *allPics {
+		c.Assert(p.Save(vg.Length(300), vg.Length(300), fmt.Sprintf("ideogram-%s.svg", failure(!ok))), check.Equals, nil)
+	}
+
+	bands[chr[1]] = []rings.Band{{Name: "p1", Start: 0, End: 60, Stain: "gneg"}}
+	_, err = rings.NewIdeogram(chr, bands, nil, rings.Arc{0, rings.Complete / 2}, 80, 100, 0)
+	c.Check(err, check.ErrorMatches, "rings: band p1 out of range of chr2")
+	bands = map[feat.Feature][]rings.Band{chr[1]: {{Name: "p1", Start: 0, End: 50, Stain: "gpos"}}}
+	_, err = rings.NewIdeogram(chr, bands, map[string]color.Color{"gneg": color.White}, rings.Arc{0, rings.Complete / 2}, 80, 100, 0)
+	c.Check(err, check.ErrorMatches, `rings: no color for stain "gpos"`)
+}
+
+func (s *S) TestTexts(c *check.C) {
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+
+	chr := &fs{start: 0, end: 1000, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete * rings.CounterClockwise}, 70, 80, 0)
+	c.Assert(err, check.Equals, nil)
+
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	ts, err := rings.NewTexts([]rings.Texter{
+		rings.Annotation{Loc: chr, Pos: 100, Label: "AAAA"},
+		rings.Annotation{Loc: chr, Pos: 102, Label: "BBBB"},
+		rings.Annotation{Loc: chr, Pos: 104, Label: "CCCC"},
+		rings.Annotation{Loc: chr, Pos: 500, Label: "DDDD"},
+	}, b, 85, 115)
+	c.Assert(err, check.Equals, nil)
+	ts.TextStyle = draw.TextStyle{Color: color.Gray16{0}, Font: font}
+	ts.Placement = rings.Horizontal
+
+	lanes, err := ts.Lanes()
+	c.Check(err, check.Equals, nil)
+	c.Check(lanes, check.DeepEquals, []int{0, 1, 2, 0})
+
+	ts.Priority = func(a, b rings.Texter) bool { return a.Text() == "CCCC" && b.Text() != "CCCC" }
+	lanes, err = ts.Lanes()
+	c.Check(err, check.Equals, nil)
+	c.Check(lanes, check.DeepEquals, []int{1, 2, 0, 0})
+
+	ts.Set = append(ts.Set, rings.Annotation{Loc: chr, Pos: 101, Label: "EEEE"})
+	_, err = ts.Lanes()
+	c.Check(err, check.ErrorMatches, `rings: no lane available for text "EEEE"`)
+	ts.Drop = true
+	lanes, err = ts.Lanes()
+	c.Check(err, check.Equals, nil)
+	c.Check(lanes, check.DeepEquals, []int{1, 2, 0, 0, -1})
+
+	_, err = rings.NewTexts([]rings.Texter{rings.Annotation{Loc: chr, Pos: 1001, Label: "out"}}, b, 85, 115)
+	c.Check(err, check.ErrorMatches, "rings: text position out of range")
+
+	p.Add(ts)
+	p.HideAxes()
+
+	tc := &canvas{dpi: defaultDPI}
+	p.Draw(draw.NewCanvas(tc, 300, 300))
+
+	base.append(
+		setColor{col: color.Gray16{Y: 0x0}},
+		fillString{font: "Helvetica", size: 10, x: 229.10009242152736, y: 208.18460847464596, str: "AAAA"},
+		setColor{col: color.Gray16{Y: 0x0}},
+		fillString{font: "Helvetica", size: 10, x: 238.55759349502338, y: 216.89656341071205, str: "BBBB"},
+		setColor{col: color.Gray16{Y: 0x0}},
+		fillString{font: "Helvetica", size: 10, x: 217.0137131780362, y: 202.45316736330187, str: "CCCC"},
+		setColor{col: color.Gray16{Y: 0x0}},
+		fillString{font: "Helvetica", size: 10, x: 38.61328125, y: 147.80029296875, str: "DDDD"},
+	)
+	c.Check(tc.actions, check.DeepEquals, base.actions)
+	if ok := reflect.DeepEqual(tc.actions, base.actions); *pics && !ok || *allPics {
+		c.Assert(p.Save(vg.Length(300), vg.Length(300), fmt.Sprintf("texts-%s.svg", failure(!ok))), check.Equals, nil)
+	}
+}
+
+func (s *S) TestTiles(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete * rings.CounterClockwise}, 60, 70, 0)
+	c.Assert(err, check.Equals, nil)
+
+	t, err := rings.NewTiles([]feat.Feature{
+		&fs{start: 0, end: 100, location: chr},
+		&fs{start: 50, end: 150, location: chr},
+		&fs{start: 120, end: 200, location: chr},
+		&fs{start: 60, end: 80, location: chr},
+		&fs{start: 300, end: 300, location: chr},
+	}, b, 80, 90)
+	c.Assert(err, check.Equals, nil)
+	t.Color = color.Gray{0x7f}
+
+	lanes, n, err := t.Lanes()
+	c.Check(err, check.Equals, nil)
+	c.Check(lanes, check.DeepEquals, []int{0, 1, 0, 2, 0})
+	c.Check(n, check.Equals, 3)
+
+	// radii returns the inner and outer radii and the arc angle of each rendered tile.
+	radii := func() [][3]float64 {
+		tc := &canvas{dpi: defaultDPI}
+		t.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var r [][3]float64
+		for _, a := range tc.actions {
+			if f, ok := a.(fill); ok {
+				r = append(r, [3]float64{float64(f.path[1].Radius), float64(f.path[2].Radius), f.path[1].Angle})
+			}
+		}
+		return r
+	}
+	tick := 1 / 90.
+	for _, test := range []struct {
+		thick    vg.Length
+		max      int
+		dir      rings.TileDirection
+		overflow rings.Overflow
+		want     [][3]float64
+	}{
+		{
+			thick: 5, dir: rings.TileOutward, overflow: rings.OverflowDrop,
+			want: [][3]float64{{80, 85, 0.2 * math.Pi}, {85, 90, 0.2 * math.Pi}, {80, 85, 0.16 * math.Pi}, {80, 85, tick}},
+		},
+		{
+			thick: 5, dir: rings.TileInward, overflow: rings.OverflowDrop,
+			want: [][3]float64{{85, 90, 0.2 * math.Pi}, {80, 85, 0.2 * math.Pi}, {85, 90, 0.16 * math.Pi}, {85, 90, tick}},
+		},
+		{
+			max: 2, dir: rings.TileOutward, overflow: rings.OverflowSqueeze,
+			want: [][3]float64{{80, 80 + 10/3., 0.2 * math.Pi}, {80 + 10/3., 80 + 20/3., 0.2 * math.Pi}, {80, 80 + 10/3., 0.16 * math.Pi}, {80 + 20/3., 90, 0.04 * math.Pi}, {80, 80 + 10/3., tick}},
+		},
+		{
+			thick: 5, dir: rings.TileInward, overflow: rings.OverflowGrow,
+			want: [][3]float64{{85, 90, 0.2 * math.Pi}, {80, 85, 0.2 * math.Pi}, {85, 90, 0.16 * math.Pi}, {75, 80, 0.04 * math.Pi}, {85, 90, tick}},
+		},
+		{
+			// Grown lanes are stacked inward whatever the direction.
+			thick: 5, dir: rings.TileOutward, overflow: rings.OverflowGrow,
+			want: [][3]float64{{80, 85, 0.2 * math.Pi}, {85, 90, 0.2 * math.Pi}, {80, 85, 0.16 * math.Pi}, {75, 80, 0.04 * math.Pi}, {80, 85, tick}},
+		},
+	} {
+		t.Thickness, t.MaxLanes, t.Direction, t.Overflow = test.thick, test.max, test.dir, test.overflow
+		got := radii()
+		c.Assert(len(got), check.Equals, len(test.want))
+		for i := range got {
+			for j := range got[i] {
+				c.Check(math.Abs(got[i][j]-test.want[i][j]) < 1e-9, check.Equals, true,
+					check.Commentf("test %+v tile %d: got %v want %v", test, i, got[i], test.want[i]))
+			}
+		}
+	}
+}
+
+func (s *S) TestConnectors(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 1000, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete * rings.CounterClockwise}, 60, 70, 0)
+	c.Assert(err, check.Equals, nil)
+
+	_, err = rings.NewConnectors([]rings.Connector{
+		rings.Connection{Loc: [2]feat.Feature{chr[0], chr[1]}, Pos: [2]int{250, 1001}},
+	}, [2]rings.ArcOfer{b, b}, [2]vg.Length{100, 80})
+	c.Check(err, check.ErrorMatches, "rings: connector position out of range")
+
+	cs, err := rings.NewConnectors([]rings.Connector{
+		rings.Connection{Loc: [2]feat.Feature{chr[0], chr[1]}, Pos: [2]int{250, 500}},
+	}, [2]rings.ArcOfer{b, b}, [2]vg.Length{100, 80})
+	c.Assert(err, check.Equals, nil)
+	cs.Stubs = [2]vg.Length{5, 10}
+	cs.LineStyle = plotter.DefaultLineStyle
+
+	cen := vg.Point{X: 150, Y: 150}
+	want := []vg.Point{
+		cen.Add(rings.Rectangular(math.Pi/4, 100)),
+		cen.Add(rings.Rectangular(math.Pi/4, 95)),
+		cen.Add(rings.Rectangular(3*math.Pi/2, 90)),
+		cen.Add(rings.Rectangular(3*math.Pi/2, 80)),
+	}
+	for _, segments := range []int{0, 10} {
+		cs.Segments = segments
+		tc := &canvas{dpi: defaultDPI}
+		cs.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var path vg.Path
+		for _, a := range tc.actions {
+			if s, ok := a.(stroke); ok {
+				path = s.path
+			}
+		}
+		if segments == 0 {
+			c.Assert(len(path), check.Equals, 4)
+		} else {
+			c.Assert(len(path), check.Equals, segments+1)
+			want = []vg.Point{want[0], want[3]}
+			path = vg.Path{path[0], path[len(path)-1]}
+		}
+		for i, comp := range path {
+			c.Check(math.Hypot(float64(comp.Pos.X-want[i].X), float64(comp.Pos.Y-want[i].Y)) < 1e-9, check.Equals, true,
+				check.Commentf("segments %d point %d: got %v want %v", segments, i, comp.Pos, want[i]))
+		}
+	}
+}
+
+func (s *S) TestOffset(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Offset = func(f feat.Feature) vg.Length {
+		if f == chr[0] {
+			return 10
+		}
+		return 0
+	}
+
+	sub := &fs{start: 10, end: 20, location: chr[0]}
+	c.Check(b.OffsetOf(chr[0], sub), check.Equals, vg.Length(10))
+	c.Check(b.OffsetOf(nil, chr[1]), check.Equals, vg.Length(0))
+
+	g, err := b.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Blocks[0].Inner, check.Equals, 90.)
+	c.Check(g.Blocks[0].Outer, check.Equals, 110.)
+	c.Check(g.Blocks[1].Inner, check.Equals, 80.)
+	c.Check(g.Blocks[1].Outer, check.Equals, 100.)
+
+	lb, err := rings.NewLabels(b, 120, rings.NameLabels(chr)...)
+	c.Assert(err, check.Equals, nil)
+	lb.TextStyle = draw.TextStyle{Color: color.Gray16{0}, Font: vg.Font{Size: 10}}
+	g, err = lb.Describe()
+	c.Assert(err, check.Equals, nil)
+	for i, want := range []vg.Length{130, 120} {
+		_, r := rings.Polar(vg.Point{X: vg.Length(g.Labels[i].Anchor.X), Y: vg.Length(g.Labels[i].Anchor.Y)})
+		c.Check(math.Abs(float64(r-want)) < 1e-9, check.Equals, true, check.Commentf("label %d radius %v", i, r))
+	}
+
+	sc, err := rings.NewScores([]rings.Scorer{
+		&fs{start: 10, end: 20, location: chr[0], scores: []float64{0}},
+		&fs{start: 10, end: 20, location: chr[1], scores: []float64{1}},
+	}, b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	g, err = sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(*g.Scores[0].Radii[0], check.Equals, 50.)
+	c.Check(*g.Scores[1].Radii[0], check.Equals, 60.)
+
+	// Trace rendering is configured once, displacing each Scorer by its offset,
+	// and the axis is drawn once at the radii of the Scores.
+	rec := &configCounter{ScoreRenderer: &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}}}
+	sc.Renderer = rec
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	c.Check(rec.n, check.Equals, 1)
+	var radii []vg.Length
+	for _, a := range tc.actions {
+		if s, ok := a.(stroke); ok {
+			radii = append(radii, s.path[1].Radius)
+		}
+	}
+	c.Check(radii, check.DeepEquals, []vg.Length{50, 60})
+
+	// Highlight wedges are displaced by the offset of their first feature.
+	h := &rings.Highlight{
+		Color:       color.Gray16{0},
+		LocFeatures: []feat.Feature{&fs{start: 10, end: 20, location: chr[0]}, &fs{start: 10, end: 20, location: chr[1]}},
+		LocBase:     b,
+		AllowSplit:  true,
+		Inner:       20,
+		Outer:       30,
+		Offset:      func(f feat.Feature) vg.Length { return b.OffsetOf(f.Location(), f) },
+	}
+	tc = &canvas{dpi: defaultDPI}
+	h.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	radii = radii[:0]
+	for _, a := range tc.actions {
+		if f, ok := a.(fill); ok {
+			radii = append(radii, f.path[1].Radius)
+		}
+	}
+	c.Check(radii, check.DeepEquals, []vg.Length{30, 20})
+}
+
+// configCounter is a ScoreRenderer that counts the calls to its Configure method.
+type configCounter struct {
+	rings.ScoreRenderer
+	n int
+}
+
+func (r *configCounter) Configure(ctx rings.ScoreContext) {
+	r.n++
+	r.ScoreRenderer.Configure(ctx)
+}
+
+func (s *S) TestZoom(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Zooms = []rings.Zoom{{Location: chr[0], Start: 40, End: 60, Scale: 3}}
+
+	near := func(a, b rings.Angle) bool { return math.Abs(float64(a-b)) < 1e-12 }
+	for _, t := range []struct {
+		f          feat.Feature
+		theta, phi rings.Angle
+	}{
+		{f: &fs{start: 0, end: 40, location: chr[0]}, theta: 0, phi: math.Pi * 40 / 140},
+		{f: &fs{start: 40, end: 60, location: chr[0]}, theta: math.Pi * 40 / 140, phi: math.Pi * 60 / 140},
+		{f: &fs{start: 50, end: 100, location: chr[0]}, theta: math.Pi * 70 / 140, phi: math.Pi * 70 / 140},
+		{f: &fs{start: 50, end: 100, location: chr[1]}, theta: math.Pi * 3 / 2, phi: math.Pi / 2},
+	} {
+		arc, err := b.ArcOf(t.f.Location(), t.f)
+		c.Assert(err, check.Equals, nil)
+		c.Check(near(arc.Theta, t.theta) && near(arc.Phi, t.phi), check.Equals, true,
+			check.Commentf("feature %d-%d of %s: got %v", t.f.Start(), t.f.End(), t.f.Location().Name(), arc))
+	}
+
+	// The complete location arc is unchanged.
+	arc, err := b.ArcOf(nil, chr[0])
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(arc.Theta, 0) && near(arc.Phi, math.Pi), check.Equals, true)
+
+	// Rings based on the Blocks follow the zoomed mapping.
+	sc, err := rings.NewScores([]rings.Scorer{
+		&fs{start: 50, end: 60, location: chr[0], scores: []float64{0}},
+	}, b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	g, err := sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(rings.Angle(g.Scores[0].Arc.Theta), math.Pi*70/140), check.Equals, true)
+	c.Check(near(rings.Angle(g.Scores[0].Arc.Phi), math.Pi*30/140), check.Equals, true)
+
+	pos, err := b.ArcOf(chr[0], &fs{start: 60, end: 60, location: chr[0]})
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(pos.Theta, math.Pi*100/140) && pos.Phi == 0, check.Equals, true)
+
+	for _, z := range []rings.Zoom{
+		{Location: chr[0], Start: 50, End: 40, Scale: 2},
+		{Location: chr[0], Start: 50, End: 110, Scale: 2},
+		{Location: chr[0], Start: 10, End: 20, Scale: 0},
+		{Location: chr[0], Start: 50, End: 70, Scale: 2},
+	} {
+		b.Zooms = []rings.Zoom{{Location: chr[0], Start: 40, End: 60, Scale: 3}, z}
+		_, err = b.ArcOf(chr[0], &fs{start: 10, end: 20, location: chr[0]})
+		c.Check(err, check.NotNil)
+	}
+}
+
+func (s *S) TestScoresBreaks(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	scores := makeScorers(chr, 4, 1, func(i, _ int) float64 { return []float64{5, 50, 95, 100}[i] })
+
+	tr := &rings.Trace{
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+		Join:       true,
+		Min:        0,
+		Max:        100,
+		Breaks:     []rings.Break{{Lo: 30, Hi: 90}, {Lo: 10, Hi: 40}, {Lo: 120, Hi: 130}},
+		Axis: &rings.Axis{
+			LineStyle: plotter.DefaultLineStyle,
+			Tick: rings.TickConfig{
+				Marker: plot.ConstantTicks{
+					{Value: 0, Label: "0"}, {Value: 50, Label: "50"}, {Value: 100, Label: "100"},
+				},
+				LineStyle: plotter.DefaultLineStyle,
+				Length:    3,
+			},
+		},
+	}
+	sc, err := rings.NewScores(scores, b, 40, 60, tr)
+	c.Assert(err, check.Equals, nil)
+
+	// The breaks are merged into a single break from 10 to 90,
+	// leaving 20 units of score over 20 points of radius.
+	g, err := sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	var radii []interface{}
+	for _, s := range g.Scores {
+		if s.Radii[0] == nil {
+			radii = append(radii, nil)
+		} else {
+			radii = append(radii, *s.Radii[0])
+		}
+	}
+	c.Check(radii, check.DeepEquals, []interface{}{45., nil, 55., 60.})
+
+	// Rendered paths are described by the radius of each path component.
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var paths [][]float64
+	for _, a := range tc.actions {
+		s, ok := a.(stroke)
+		if !ok {
+			continue
+		}
+		var radii []float64
+		for _, p := range s.path {
+			r := p.Radius
+			if p.Type != vg.ArcComp {
+				_, r = rings.Polar(p.Pos.Sub(vg.Point{150, 150}))
+			}
+			radii = append(radii, math.Floor(float64(r)*100+0.5)/100)
+		}
+		paths = append(paths, radii)
+	}
+	c.Check(paths, check.DeepEquals, [][]float64{
+		// Axis line interrupted at the break, with the break slashes.
+		{40, 49.5},
+		{48.45, 50.57},
+		{49.45, 51.57},
+		{50.5, 60},
+
+		// Ticks at 0 and 100; the tick at 50 is within the break.
+		{40, 40.11},
+		{60, 60.07},
+
+		// Traces. The score of 50 within the break is not drawn, but is
+		// joined to its neighbours at the break radius.
+		{45, 45},
+		{45, 50},
+		{50, 55, 55},
+		{55, 60, 60},
+	})
+
+	// The other radial renderers share the scale of the Trace.
+	breaks := tr.Breaks
+	for _, f := range scores {
+		f.(*fs).style = plotter.DefaultLineStyle
+	}
+	for _, r := range []rings.ScoreRenderer{
+		&rings.Segments{LineStyle: plotter.DefaultLineStyle, Breaks: breaks},
+		&rings.Interval{Values: func(s rings.Scorer) []float64 { v := s.Scores()[0]; return []float64{v, v, v} },
+			Mark: plotter.DefaultLineStyle, Breaks: breaks},
+		&rings.ErrorBand{Upper: func(s rings.Scorer) float64 { return s.Scores()[0] }, Lower: func(s rings.Scorer) float64 { return s.Scores()[0] },
+			UpperStyle: plotter.DefaultLineStyle, Breaks: breaks},
+	} {
+		sc, err := rings.NewScores(scores, b, 40, 60, r)
+		c.Assert(err, check.Equals, nil)
+		sc.Min, sc.Max = 0, 100
+		for i, want := range []vg.Length{45, 50, 55, 60} {
+			rad, _ := sc.ScoreRadius(scores[i], scores[i].Scores()[0])
+			c.Check(math.Abs(float64(rad-want)) < 1e-9, check.Equals, true, check.Commentf("%T score %d: got %v want %v", r, i, rad, want))
+		}
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var got []float64
+		for _, a := range tc.actions {
+			if s, ok := a.(stroke); ok {
+				for _, p := range s.path {
+					if p.Type == vg.ArcComp {
+						got = append(got, math.Floor(float64(p.Radius)*100+0.5)/100)
+					}
+				}
+			}
+		}
+		c.Check(got, check.DeepEquals, []float64{45, 50, 55, 60}, check.Commentf("%T", r))
+	}
+}
+
+func (s *S) TestLinksLineStyleFunc(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	var pairs []rings.Pair
+	for _, v := range []float64{0, 1, 3} {
+		pairs = append(pairs, vp{
+			feats: [2]feat.Feature{
+				&fs{start: 10, end: 20, location: chr[0]},
+				&fs{start: 60, end: 70, location: chr[1]},
+			},
+			value: v,
+		})
+	}
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	l.LineStyle = plotter.DefaultLineStyle
+
+	var calls int
+	l.LineStyleFunc = func(p rings.Pair) draw.LineStyle {
+		calls++
+		v := p.(vp).value
+		return draw.LineStyle{
+			Color: color.NRGBA{A: uint8(v * 80)},
+			Width: vg.Length(v),
+		}
+	}
+
+	tc := &canvas{dpi: defaultDPI}
+	l.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	c.Check(calls, check.Equals, len(pairs))
+
+	var widths []vg.Length
+	var strokes int
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case setWidth:
+			widths = append(widths, a.w)
+		case stroke:
+			strokes++
+		}
+	}
+	// The zero value pair has zero width and is not drawn.
+	c.Check(widths, check.DeepEquals, []vg.Length{1, 3})
+	c.Check(strokes, check.Equals, 2)
+
+	g, err := l.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(len(g.Links), check.Equals, 2)
+	c.Check(g.Links[0].Stroke, check.Equals, "#00000050")
+	c.Check(g.Links[1].Stroke, check.Equals, "#000000f0")
+}
+
+func (s *S) TestLinksEndGlyphs(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	var pairs []rings.Pair
+	for _, v := range []float64{1, 1, 0} {
+		pairs = append(pairs, vp{
+			feats: [2]feat.Feature{
+				&fs{start: 10, end: 20, location: chr[0]},
+				&fs{start: 60, end: 70, location: chr[1]},
+			},
+			value: v,
+		})
+	}
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	l.LineStyleFunc = func(p rings.Pair) draw.LineStyle {
+		return draw.LineStyle{Color: color.Black, Width: vg.Length(p.(vp).value)}
+	}
+	l.EndGlyph = draw.GlyphStyle{Color: color.Black, Radius: 2, Shape: draw.CircleGlyph{}}
+
+	// glyphs returns the centres of the rendered end glyphs after
+	// checking that they are rendered after all the links.
+	glyphs := func() []vg.Point {
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var (
+			pts     []vg.Point
+			stroked bool
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case fill:
+				for _, p := range a.path {
+					if p.Type == vg.ArcComp {
+						pts = append(pts, p.Pos)
+					}
+				}
+			case stroke:
+				stroked = true
+				c.Check(pts, check.HasLen, 0)
+			}
+		}
+		c.Check(stroked, check.Equals, true)
+		return pts
+	}
+
+	// The pair with a zero width style is not rendered and so has no glyphs.
+	c.Check(glyphs(), check.HasLen, 4)
+
+	l.EndGlyphTolerance = 0.01
+	pts := glyphs()
+	c.Assert(pts, check.HasLen, 2)
+	for i, want := range []rings.Angle{math.Pi / 10, math.Pi * 8 / 5} {
+		theta, r := rings.Polar(pts[i].Sub(vg.Point{150, 150}))
+		c.Check(math.Abs(float64(theta-want)) < 1e-9, check.Equals, true, check.Commentf("glyph %d angle %v", i, theta))
+		c.Check(math.Abs(float64(r-70)) < 1e-9, check.Equals, true, check.Commentf("glyph %d radius %v", i, r))
+	}
+
+	l.EndGlyphTolerance = 0
+	l.EndGlyphFunc = func(p rings.Pair, end int) draw.GlyphStyle {
+		if end == 1 {
+			return draw.GlyphStyle{}
+		}
+		return l.EndGlyph
+	}
+	c.Check(glyphs(), check.HasLen, 2)
+}
+
+func (s *S) TestPatterns(c *check.C) {
+	cen := vg.Point{150, 150}
+	h := rings.NewHighlight(nil, rings.Arc{0, rings.Complete / 4}, 20, 40)
+
+	// within checks that p lies within the highlight sector, allowing
+	// for the approximation of arcs by line segments.
+	within := func(p vg.Point) bool {
+		theta, r := rings.Polar(p.Sub(cen))
+		const tol = 1e-3
+		return 20-tol <= r && r <= 40+tol && (theta <= rings.Complete/4+tol || theta >= rings.Complete-tol)
+	}
+
+	for _, t := range []struct {
+		pattern rings.Patterner
+		strokes int
+		fills   int
+	}{
+		{pattern: rings.Hatch{Angle: rings.Complete / 8, Spacing: 5, LineStyle: plotter.DefaultLineStyle}, strokes: 1},
+		{pattern: rings.CrossHatch{Angle: rings.Complete / 8, Spacing: 5, LineStyle: plotter.DefaultLineStyle}, strokes: 2},
+		{pattern: rings.Dots{Spacing: 5, Radius: 1, Color: color.Black}, fills: 1},
+		{pattern: rings.Hatch{Spacing: 0, LineStyle: plotter.DefaultLineStyle}},
+	} {
+		h.Pattern = t.pattern
+		tc := &canvas{dpi: defaultDPI}
+		h.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var strokes, fills int
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				strokes++
+				c.Check(len(a.path)%2, check.Equals, 0)
+				for _, p := range a.path {
+					c.Check(p.Type == vg.MoveComp || p.Type == vg.LineComp, check.Equals, true)
+					c.Check(within(p.Pos), check.Equals, true, check.Commentf("%T point %v", t.pattern, p.Pos))
+				}
+			case fill:
+				fills++
+				for _, p := range a.path {
+					if p.Type == vg.ArcComp {
+						c.Check(within(p.Pos), check.Equals, true, check.Commentf("%T point %v", t.pattern, p.Pos))
+					}
+				}
+				c.Check(len(a.path) > 3, check.Equals, true)
+			}
+		}
+		c.Check(strokes, check.Equals, t.strokes, check.Commentf("%T", t.pattern))
+		c.Check(fills, check.Equals, t.fills, check.Commentf("%T", t.pattern))
+	}
+}
+
+func (s *S) TestTraceSmooth(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	values := [][]float64{
+		{0, 0, 30, 0, 0},
+		{10, 10, math.NaN(), 10, 10},
+	}
+
+	// radii returns the radii of the rendered trace arcs.
+	radii := func(smooth *rings.Smooth) []float64 {
+		var scores []rings.Scorer
+		for i, f := range chr {
+			scores = append(scores, makeScorers(f.(*fs), 5, 1, func(k, _ int) float64 { return values[i][k] })...)
+		}
+		sc, err := rings.NewScores(scores, b, 40, 60, &rings.Trace{
+			LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+			Smooth:     smooth,
+		})
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var r []float64
+		for _, a := range tc.actions {
+			if s, ok := a.(stroke); ok {
+				for _, p := range s.path {
+					if p.Type == vg.ArcComp {
+						r = append(r, math.Floor(float64(p.Radius)*1e6+0.5)/1e6)
+					}
+				}
+			}
+		}
+		return r
+	}
+
+	// Without smoothing the range is 0 to 30.
+	c.Check(radii(nil), check.DeepEquals, []float64{
+		40, 40, 60, 40, 40,
+		46.666667, 46.666667, 46.666667, 46.666667,
+	})
+
+	// The smoothed range is 0 to 10, smoothing does not extend across
+	// locations and the NaN score is not interpolated.
+	c.Check(radii(&rings.Smooth{Method: rings.MovingMean, Bins: 3}), check.DeepEquals, []float64{
+		40, 60, 60, 60, 40,
+		60, 60, 60, 60,
+	})
+	c.Check(radii(&rings.Smooth{Method: rings.MovingMean, Window: rings.Complete / 4}), check.DeepEquals, []float64{
+		40, 60, 60, 60, 40,
+		60, 60, 60, 60,
+	})
+	c.Check(radii(&rings.Smooth{Method: rings.MovingMean, Bins: 3, CrossLocations: true}), check.DeepEquals, []float64{
+		40, 60, 60, 60, 46.666667,
+		53.333333, 60, 60, 60,
+	})
+
+	c.Check(radii(&rings.Smooth{Method: rings.MovingMedian, Bins: 3}), check.DeepEquals, []float64{
+		40, 40, 40, 40, 40,
+		60, 60, 60, 60,
+	})
+
+	// LOESS with a window of three scores is exact for scores linear in angle.
+	values[0] = []float64{0, 1, 2, 3, 4}
+	c.Check(radii(&rings.Smooth{Method: rings.LOESS, Bins: 3}), check.DeepEquals, []float64{
+		40, 42, 44, 46, 48,
+		60, 60, 60, 60,
+	})
+
+	// Angular windows wrap around the circle, so the first and last scores of a
+	// complete circle are smoothed with each other.
+	chr = chr[:1]
+	b, err = rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	values = [][]float64{{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	scores := makeScorers(chr[0].(*fs), 10, 1, func(k, _ int) float64 { return values[0][k] })
+	for _, method := range []rings.SmoothMethod{rings.MovingMean, rings.MovingMedian} {
+		sc, err := rings.NewScores(scores, b, 40, 60, &rings.Trace{
+			LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+			Smooth:     &rings.Smooth{Method: method, Window: rings.Complete / 4},
+		})
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var got []float64
+		for _, a := range tc.actions {
+			if s, ok := a.(stroke); ok {
+				for _, p := range s.path {
+					if p.Type == vg.ArcComp {
+						got = append(got, math.Floor(float64(p.Radius)*1e6+0.5)/1e6)
+					}
+				}
+			}
+		}
+		// The smoothed scores are 10/3, 1, 2, ..., 8, 17/3 for the mean and
+		// 1, 1, 2, ..., 8, 8 for the median, with a range of 1 to 8.
+		want := []float64{40 + 20*(10./3-1)/7, 40, 40 + 20./7, 40 + 40./7, 40 + 60./7, 40 + 80./7, 40 + 100./7, 40 + 120./7, 60, 40 + 20*(17./3-1)/7}
+		if method == rings.MovingMedian {
+			want[0], want[9] = 40, 60
+		}
+		for i := range want {
+			want[i] = math.Floor(want[i]*1e6+0.5) / 1e6
+		}
+		c.Check(got, check.DeepEquals, want, check.Commentf("method %d", method))
+	}
+}
+
+func (s *S) TestScoresBands(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	var scores []rings.Scorer
+	for _, f := range chr {
+		scores = append(scores, makeScorers(f.(*fs), 5, 1, func(k, _ int) float64 { return float64(k) })...)
+	}
+	sc, err := rings.NewScores(scores, b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	sc.Bands = []rings.ScoreBand{
+		{Lo: 1.5, Hi: 2.5, Color: red},
+		{Lo: 3, Hi: 10, Color: blue},
+		{Lo: 5, Hi: 6, Color: blue},
+		{Lo: 1, Hi: 2},
+	}
+
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+
+	type band struct {
+		col          color.Color
+		inner, outer vg.Length
+		theta, phi   float64
+	}
+	var (
+		bands   []band
+		col     color.Color
+		strokes int
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case setColor:
+			col = a.col
+		case fill:
+			c.Check(strokes, check.Equals, 0, check.Commentf("band rendered over scores"))
+			bnd := band{col: col, inner: vg.Length(math.Inf(1))}
+			for _, p := range a.path {
+				if p.Type != vg.ArcComp {
+					continue
+				}
+				if p.Radius < bnd.inner {
+					bnd.inner = p.Radius
+					bnd.theta = math.Floor(p.Start*1e9+0.5) / 1e9
+					bnd.phi = math.Floor(p.Angle*1e9+0.5) / 1e9
+				}
+				if p.Radius > bnd.outer {
+					bnd.outer = p.Radius
+				}
+			}
+			bands = append(bands, bnd)
+		case stroke:
+			strokes++
+		}
+	}
+	pi := math.Floor(math.Pi*1e9+0.5) / 1e9
+	c.Check(bands, check.DeepEquals, []band{
+		{col: red, inner: 47.5, outer: 52.5, theta: 0, phi: pi},
+		{col: red, inner: 47.5, outer: 52.5, theta: pi, phi: pi},
+		{col: blue, inner: 55, outer: 60, theta: 0, phi: pi},
+		{col: blue, inner: 55, outer: 60, theta: pi, phi: pi},
+	})
+	c.Check(strokes, check.Equals, 10)
+}
+
+func (s *S) TestTraceSegmenter(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	values := []float64{2, 4, math.NaN(), 1, 0, 3, 2}
+	scores := makeScorers(chr, len(values), 1, func(k, _ int) float64 { return values[k] })
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	grey := color.Gray{0x80}
+	sc, err := rings.NewScores(scores, b, 40, 60, &rings.Trace{
+		Join: true,
+		Min:  0,
+		Max:  4,
+		Segmenter: func(v float64) draw.LineStyle {
+			switch {
+			case v > 2.5:
+				return draw.LineStyle{Color: red, Width: 1}
+			case v < 1.5:
+				return draw.LineStyle{Color: blue, Width: 1}
+			}
+			return draw.LineStyle{Color: grey, Width: 1}
+		},
+	})
+	c.Assert(err, check.Equals, nil)
+
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+
+	// Each stroke is described by its color and the radii of its components.
+	type part struct {
+		col   color.Color
+		radii []float64
+	}
+	var (
+		parts []part
+		col   color.Color
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case setColor:
+			col = a.col
+		case stroke:
+			p := part{col: col}
+			for _, pc := range a.path {
+				r := pc.Radius
+				if pc.Type != vg.ArcComp {
+					_, r = rings.Polar(pc.Pos.Sub(vg.Point{150, 150}))
+				}
+				p.radii = append(p.radii, math.Floor(float64(r)*1e6+0.5)/1e6)
+			}
+			parts = append(parts, p)
+		}
+	}
+	c.Check(parts, check.DeepEquals, []part{
+		{col: grey, radii: []float64{50, 50}},
+
+		// The join from 2 to 4 changes color at 2.5.
+		{col: grey, radii: []float64{50, 52.5}},
+		{col: red, radii: []float64{52.5, 60, 60}},
+
+		// The NaN score is not rendered or joined.
+		{col: blue, radii: []float64{45, 45}},
+		{col: blue, radii: []float64{45, 40, 40}},
+
+		// The join from 0 to 3 crosses two thresholds.
+		{col: blue, radii: []float64{40, 47.5}},
+		{col: grey, radii: []float64{47.5, 52.5}},
+		{col: red, radii: []float64{52.5, 55, 55}},
+
+		{col: red, radii: []float64{55, 52.5}},
+		{col: grey, radii: []float64{52.5, 50, 50}},
+	})
+}
+
+func (s *S) TestAnnulus(c *check.C) {
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(k, _ int) float64 { return float64(k) }),
+		b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+
+	disc := color.Gray{0xe0}
+	background := color.Gray{0xf0}
+	highlight := color.NRGBA{R: 0xf3, G: 0xf3, B: 0x15, A: 0xff}
+	sc.Background = background
+
+	a := rings.NewAnnulus(disc, 0, 30)
+	a.LineStyle = plotter.DefaultLineStyle
+	p.Add(a, sc, rings.NewHighlight(highlight, rings.Arc{0, rings.Complete / 4}, 50, 70))
+	p.HideAxes()
+
+	tc := &canvas{dpi: defaultDPI}
+	p.Draw(draw.NewCanvas(tc, 300, 300))
+
+	// The plotters render in the order they were added, with the Scores
+	// background rendered before its trace and the highlight added after
+	// the Scores rendered over it.
+	var (
+		order []string
+		col   color.Color
+	)
+	for _, a := range tc.actions[len(base.base):] {
+		switch a := a.(type) {
+		case setColor:
+			col = a.col
+		case fill:
+			order = append(order, fmt.Sprintf("fill %v %d", col, len(a.path)))
+		case stroke:
+			order = append(order, fmt.Sprintf("stroke %v", col))
+		}
+	}
+	c.Check(order, check.DeepEquals, []string{
+		fmt.Sprintf("fill %v 5", disc),
+		fmt.Sprintf("stroke %v", plotter.DefaultLineStyle.Color),
+		fmt.Sprintf("fill %v 5", background),
+		fmt.Sprintf("stroke %v", plotter.DefaultLineStyle.Color),
+		fmt.Sprintf("stroke %v", plotter.DefaultLineStyle.Color),
+		fmt.Sprintf("stroke %v", plotter.DefaultLineStyle.Color),
+		fmt.Sprintf("stroke %v", plotter.DefaultLineStyle.Color),
+		fmt.Sprintf("fill %v 4", highlight),
+	})
+
+	// The complete annulus is rendered as two disjoint circles.
+	for _, a := range tc.actions[len(base.base):] {
+		if f, ok := a.(fill); ok {
+			c.Check(f.path[2].Type, check.Equals, vg.MoveComp)
+			break
+		}
+	}
+}
+
+func (s *S) TestFan(c *check.C) {
+	fan := rings.Arc{0, rings.Complete / 2}
+
+	_, err := rings.NewGappedBlocks(nil, rings.Arc{0, 3 * rings.Complete / 2}, 80, 100, 0)
+	c.Check(err, check.ErrorMatches, "rings: base arc exceeds a complete circle")
+
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 80, name: "chr2"},
+		&fs{start: 0, end: 60, name: "chr3"},
+	}
+	b, err := rings.NewGappedBlocks(chrs, fan, 110, 120, 0.01)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.Gray{0x7f}
+
+	var scorers []rings.Scorer
+	for _, chr := range chrs {
+		scorers = append(scorers, makeScorers(chr.(*fs), 10, 1, func(i, _ int) float64 { return float64(i % 3) })...)
+	}
+	sc, err := rings.NewScores(scorers, b, 70, 100,
+		&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Axis: &rings.Axis{
+			LineStyle: plotter.DefaultLineStyle,
+			Grid:      plotter.DefaultLineStyle,
+			Tick:      rings.TickConfig{Marker: plot.ConstantTicks{{Value: 0, Label: "0"}, {Value: 1, Label: "1"}, {Value: 2, Label: "2"}}},
+		}})
+	c.Assert(err, check.Equals, nil)
+	sc.Background = color.Gray{0xf0}
+
+	t, err := rings.NewTiles([]feat.Feature{
+		&fs{start: 0, end: 50, location: chrs[0]},
+		&fs{start: 20, end: 70, location: chrs[0]},
+		&fs{start: 10, end: 60, location: chrs[2]},
+	}, b, 40, 60)
+	c.Assert(err, check.Equals, nil)
+	t.Color = color.Gray{0x3f}
+
+	sp, err := rings.NewSpokes([]feat.Feature{
+		&fs{start: 0, end: 1, location: chrs[0], style: plotter.DefaultLineStyle},
+		&fs{start: 40, end: 41, location: chrs[1], style: plotter.DefaultLineStyle},
+		&fs{start: 60, end: 60, location: chrs[2], style: plotter.DefaultLineStyle},
+	}, b, 60, 70)
+	c.Assert(err, check.Equals, nil)
+	sp.LineStyle = plotter.DefaultLineStyle
+
+	font, err := vg.MakeFont("Helvetica", 5)
+	c.Assert(err, check.Equals, nil)
+	lb, err := rings.NewLabels(b, 125, rings.NameLabels(chrs)...)
+	c.Assert(err, check.Equals, nil)
+	lb.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	// The glyph boxes of the tracks are restricted to the upper half of the plot.
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	for _, g := range []struct {
+		p     plot.GlyphBoxer
+		inner vg.Length
+		outer vg.Length
+	}{
+		{p: b, inner: 110, outer: 120},
+		{p: sc, inner: 70, outer: 100},
+		{p: t, inner: 40, outer: 60},
+		{p: sp, inner: 60, outer: 70},
+	} {
+		box := g.p.GlyphBoxes(p)[0].Rectangle
+		c.Check(near(float64(box.Min.X), float64(-g.outer)), check.Equals, true)
+		c.Check(near(float64(box.Min.Y), 0), check.Equals, true)
+		c.Check(near(float64(box.Max.X), float64(g.outer)), check.Equals, true)
+		c.Check(near(float64(box.Max.Y), float64(g.outer)), check.Equals, true)
+	}
+
+	// All rendered geometry lies within the fan.
+	cen := vg.Point{150, 150}
+	tc := &canvas{dpi: defaultDPI}
+	ca := draw.NewCanvas(tc, 300, 300)
+	for _, r := range []interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}{b, sc, t, sp, lb} {
+		r.DrawAt(ca, cen)
+	}
+	var n, grid, spokes, labels int
+	const tol = 1e-6
+	for _, a := range tc.actions {
+		var pa vg.Path
+		switch a := a.(type) {
+		case fill:
+			pa = a.path
+		case stroke:
+			pa = a.path
+			if len(pa) == 2 && pa[1].Type == vg.ArcComp {
+				grid++
+			}
+			if len(pa) == 2 && pa[1].Type == vg.LineComp {
+				r0 := pa[0].Pos.Sub(cen)
+				r1 := pa[1].Pos.Sub(cen)
+				if near(math.Hypot(float64(r0.X), float64(r0.Y)), 60) && near(math.Hypot(float64(r1.X), float64(r1.Y)), 70) {
+					spokes++
+				}
+			}
+		case fillString:
+			// Labels are anchored within the fan.
+			c.Check(a.y >= cen.Y-tol, check.Equals, true, check.Commentf("label %q below fan", a.str))
+			labels++
+			continue
+		default:
+			continue
+		}
+		for _, comp := range pa {
+			switch comp.Type {
+			case vg.MoveComp, vg.LineComp:
+				c.Check(comp.Pos.Y >= cen.Y-tol, check.Equals, true, check.Commentf("point %v below fan", comp.Pos))
+			case vg.ArcComp:
+				c.Check(comp.Pos, check.Equals, cen)
+				lo, hi := comp.Start, comp.Start+comp.Angle
+				if hi < lo {
+					lo, hi = hi, lo
+				}
+				c.Check(lo >= -tol && hi <= math.Pi+tol, check.Equals, true, check.Commentf("arc [%v, %v] outside fan", lo, hi))
+			}
+			n++
+		}
+	}
+	c.Check(n > 0, check.Equals, true)
+	c.Check(grid > 0, check.Equals, true)
+	c.Check(spokes, check.Equals, 3)
+	c.Check(labels, check.Equals, 3)
+
+	// The labels' glyph box is restricted to the upper half of the plot
+	// beyond the label radius.
+	box := lb.GlyphBoxes(p)[0].Rectangle
+	c.Check(box.Min.Y >= -tol, check.Equals, true, check.Commentf("label box %v", box))
+	c.Check(box.Max.Y >= 125, check.Equals, true, check.Commentf("label box %v", box))
+}
+
+func (s *S) TestGroupPairs(c *check.C) {
+	chr1 := &fs{start: 0, end: 100, name: "chr1"}
+	chr2 := &fs{start: 0, end: 100, name: "chr2"}
+	ga := &fs{name: "pathwayA"}
+	gb := &fs{name: "pathwayB"}
+
+	a1 := &fs{start: 10, end: 20, location: chr1}
+	a2 := &fs{start: 30, end: 45, location: chr1}
+	a3 := &fs{start: 60, end: 70, location: chr2}
+	b1 := &fs{start: 50, end: 55, location: chr2}
+	b2 := &fs{start: 80, end: 90, location: chr2}
+	u := &fs{start: 70, end: 75, location: chr1}
+	groupOf := func(f feat.Feature) feat.Feature {
+		switch f {
+		case a1, a2, a3:
+			return ga
+		case b1, b2:
+			return gb
+		}
+		return nil
+	}
+
+	pairs := []rings.Pair{
+		fp{feats: [2]*fs{a1, b1}},
+		fp{feats: [2]*fs{a2, b2}},
+		fp{feats: [2]*fs{u, b1}},
+		fp{feats: [2]*fs{a1, b2}},
+		fp{feats: [2]*fs{a3, b1}},
+	}
+	grouped := rings.GroupPairs(pairs, groupOf)
+
+	type groupEnd struct {
+		name     string
+		loc      feat.Feature
+		from, to int
+	}
+	var (
+		ends   [][2]groupEnd
+		counts []int
+	)
+	for _, p := range grouped {
+		gp := p.(*rings.GroupPair)
+		var e [2]groupEnd
+		for i, f := range gp.Features() {
+			e[i] = groupEnd{name: f.Name(), loc: f.Location(), from: f.Start(), to: f.End()}
+		}
+		ends = append(ends, e)
+		counts = append(counts, gp.Count())
+	}
+	c.Check(ends, check.DeepEquals, [][2]groupEnd{
+		{{"pathwayA", chr1, 10, 45}, {"pathwayB", chr2, 50, 90}},
+		{{"", chr1, 70, 75}, {"pathwayB", chr2, 50, 90}},
+		{{"pathwayA", chr2, 60, 70}, {"pathwayB", chr2, 50, 90}},
+	})
+	c.Check(counts, check.DeepEquals, []int{3, 1, 1})
+
+	// Group features are shared between the pairs they end.
+	c.Check(grouped[0].Features()[1], check.Equals, grouped[1].Features()[1])
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	// The grouped pairs are rendered as ribbons spanning the group extents.
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr1, chr2}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	r, err := rings.NewRibbons(grouped, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	r.Color = color.Gray{0x7f}
+
+	tc := &canvas{dpi: defaultDPI}
+	r.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var got [][2]float64
+	for _, a := range tc.actions {
+		if f, ok := a.(fill); ok {
+			got = append(got, [2]float64{f.path[1].Start, f.path[1].Angle})
+		}
+	}
+	c.Assert(got, check.HasLen, 3)
+	for i, p := range grouped {
+		f := p.Features()[0]
+		arc, err := b.ArcOf(f.Location(), f)
+		c.Assert(err, check.Equals, nil)
+		c.Check(near(got[i][0], float64(rings.Normalize(arc.Theta))), check.Equals, true)
+		c.Check(near(got[i][1], float64(arc.Phi)), check.Equals, true)
+	}
+}
+
+func (s *S) TestValidate(c *check.C) {
+	chr1 := &fs{start: 0, end: 100, name: "chr1"}
+	chr2 := &fs{start: 0, end: 100, name: "chr2"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr1, chr2}, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(makeScorers(chr1, 4, 2, func(i, j int) float64 { return float64(i + j) }),
+		b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle, plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	pairs := []rings.Pair{fp{feats: [2]*fs{{start: 10, end: 20, location: chr1, name: "a"}, {start: 30, end: 40, location: chr2, name: "b"}}}}
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	rb, err := rings.NewRibbons(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	lb, err := rings.NewLabels(b, 110, rings.NameLabels(b.Set)...)
+	c.Assert(err, check.Equals, nil)
+
+	c.Check(rings.ValidateAll(b, sc, l, rb, lb, rings.NewAnnulus(nil, 0, 10)), check.Equals, nil)
+
+	unknown := &fs{start: 0, end: 10, name: "chrU"}
+	b.Inner = 120
+	b.Set = append(b.Set, &fs{start: 20, end: 10, name: "inverted"})
+	sc.Min, sc.Max = 5, 5
+	sc.Renderer.(*rings.Trace).LineStyles = sc.Renderer.(*rings.Trace).LineStyles[:1]
+	sc.Renderer.(*rings.Trace).Axis = &rings.Axis{Grid: plotter.DefaultGridLineStyle}
+	l.Set = append(l.Set, fp{feats: [2]*fs{{start: 0, end: 5, location: chr1, name: "c"}, {start: 0, end: 5, location: unknown, name: "d"}}})
+	rb.Twist = rings.Flat | rings.Twisted
+	rb.Set = append(rb.Set, fp{feats: [2]*fs{{start: 0, end: 5, name: "e"}, {start: 0, end: 5, location: chr2, name: "f"}}})
+	lb.Labels = append(lb.Labels, rings.NameLabels([]feat.Feature{unknown})...)
+
+	var got []string
+	for _, v := range []rings.Validator{b, sc, l, rb, lb} {
+		err := v.Validate()
+		c.Assert(err, check.FitsTypeOf, rings.ValidationError(nil))
+		for _, e := range err.(rings.ValidationError) {
+			got = append(got, e.Error())
+		}
+	}
+	c.Check(got, check.DeepEquals, []string{
+		"rings: inner radius 120 greater than outer radius 100",
+		`rings: inverted feature "inverted": end 10 less than start 20`,
+		`rings: no arc for feature "inverted": rings: location not found`,
+		"rings: score minimum 5 not less than maximum 5",
+		`rings: scorer "chr1#0" has 2 scores but trace has 1 line styles`,
+		`rings: scorer "chr1#1" has 2 scores but trace has 1 line styles`,
+		`rings: scorer "chr1#2" has 2 scores but trace has 1 line styles`,
+		`rings: scorer "chr1#3" has 2 scores but trace has 1 line styles`,
+		"rings: nil axis tick marker",
+		`rings: no arc for feature "d": rings: location not found`,
+		"rings: cannot specify flat and twisted",
+		`rings: feature "e" has no location`,
+		`rings: no arc for feature "e": rings: location not found`,
+		`rings: no arc for feature "chrU": rings: location not found`,
+	})
+
+	err = rings.ValidateAll(b, sc, l, rb, lb)
+	c.Check(err, check.FitsTypeOf, rings.ValidationError(nil))
+	c.Check(err.(rings.ValidationError), check.HasLen, len(got))
+	c.Check(err.Error(), check.Equals, strings.Join(got, "; "))
+}
+
+func (s *S) TestOrientation(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	fwd := &fs{start: 100, end: 200, location: chr, name: "fwd", orient: feat.Forward, style: plotter.DefaultLineStyle}
+	rev := &fs{start: 300, end: 400, location: chr, name: "rev", orient: feat.Reverse, style: plotter.DefaultLineStyle}
+	thin := &fs{start: 500, end: 501, location: chr, name: "thin", orient: feat.Reverse, style: plotter.DefaultLineStyle}
+	none := &fs{start: 600, end: 700, location: chr, name: "none", style: plotter.DefaultLineStyle}
+	set := []feat.Feature{fwd, rev, thin, none}
+
+	blk, err := rings.NewBlocks(set, b, 60, 70)
+	c.Assert(err, check.Equals, nil)
+
+	// chevrons returns the direction of each rendered chevron and whether the
+	// chevron lies within the arc of its feature.
+	cen := vg.Point{150, 150}
+	chevrons := func() (dirs []float64, within []bool) {
+		tc := &canvas{dpi: defaultDPI}
+		blk.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			st, ok := a.(stroke)
+			if !ok || len(st.path) != 3 || st.path[1].Type != vg.LineComp {
+				continue
+			}
+			f := set[len(dirs)]
+			arc, err := b.ArcOf(f.Location(), f)
+			c.Assert(err, check.Equals, nil)
+			in := true
+			for _, p := range st.path {
+				theta, r := rings.Polar(p.Pos.Sub(cen))
+				d := math.Remainder(float64(theta-arc.Theta), float64(rings.Complete))
+				in = in && d > 0 && d < float64(arc.Phi) && r > 60 && r < 70
+			}
+			back, _ := rings.Polar(st.path[0].Pos.Sub(cen))
+			tip, _ := rings.Polar(st.path[1].Pos.Sub(cen))
+			dirs = append(dirs, math.Copysign(1, math.Remainder(float64(tip-back), float64(rings.Complete))))
+			within = append(within, in)
+		}
+		return dirs, within
+	}
+	dirs, _ := chevrons()
+	c.Check(dirs, check.HasLen, 0)
+
+	blk.UseOrientation = true
+	dirs, within := chevrons()
+	c.Check(dirs, check.DeepEquals, []float64{1, -1, -1, 1})
+	c.Check(within, check.DeepEquals, []bool{true, true, true, true})
+
+	// Reverse oriented locations reverse the orientation of their features.
+	chr.orient = feat.Reverse
+	dirs, _ = chevrons()
+	c.Check(dirs, check.DeepEquals, []float64{-1, 1, 1, -1})
+	chr.orient = feat.NotOriented
+
+	// Tiles of each orientation are stacked away from the midpoint of the ring.
+	t, err := rings.NewTiles([]feat.Feature{
+		&fs{start: 0, end: 100, location: chr, orient: feat.Forward},
+		&fs{start: 50, end: 150, location: chr},
+		&fs{start: 0, end: 100, location: chr, orient: feat.Reverse},
+		&fs{start: 20, end: 80, location: chr, orient: feat.Reverse},
+	}, b, 40, 60)
+	c.Assert(err, check.Equals, nil)
+	t.Color = color.Gray{0x7f}
+	t.UseOrientation = true
+	lanes, n, err := t.Lanes()
+	c.Check(err, check.Equals, nil)
+	c.Check(lanes, check.DeepEquals, []int{0, 1, 0, 1})
+	c.Check(n, check.Equals, 2)
+
+	tc := &canvas{dpi: defaultDPI}
+	t.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var radii [][2]float64
+	for _, a := range tc.actions {
+		if f, ok := a.(fill); ok {
+			radii = append(radii, [2]float64{float64(f.path[1].Radius), float64(f.path[2].Radius)})
+		}
+	}
+	c.Check(radii, check.DeepEquals, [][2]float64{{50, 55}, {55, 60}, {45, 50}, {40, 45}})
+
+	// Labels are decorated and placed according to orientation.
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	lb, err := rings.NewLabels(b, 110, rings.NameLabels([]feat.Feature{fwd, rev, none})...)
+	c.Assert(err, check.Equals, nil)
+	lb.TextStyle = draw.TextStyle{Color: color.Gray16{0}, Font: font}
+	lb.Suffix = [2]string{" (+)", " (-)"}
+	lb.ReversePlacement = rings.Horizontal
+
+	type label struct {
+		text string
+		rot  float64
+	}
+	labels := func() []label {
+		g, err := lb.Describe()
+		c.Assert(err, check.Equals, nil)
+		var l []label
+		for _, lg := range g.Labels {
+			l = append(l, label{text: lg.Text, rot: lg.Rotation})
+		}
+		return l
+	}
+	plain := labels()
+	c.Check(plain[0].text, check.Equals, "fwd")
+	c.Check(plain[1].text, check.Equals, "rev")
+
+	lb.UseOrientation = true
+	oriented := labels()
+	c.Check(oriented, check.DeepEquals, []label{
+		{text: "fwd (+)", rot: plain[0].rot},
+		{text: "rev (-)", rot: 0},
+		{text: "none (+)", rot: plain[2].rot},
+	})
+}
+
+func (s *S) TestScoresRange(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	values := []float64{1, math.NaN(), 3, 2}
+	t := &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}}
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return values[i] }), b, 40, 60, t)
+	c.Assert(err, check.Equals, nil)
+	sc.Set = append(sc.Set, &fs{start: 0, end: 10, location: chr, scores: []float64{math.Inf(1)}})
+
+	// round rounds away floating point error.
+	round := func(min, max float64) [2]float64 {
+		return [2]float64{math.Floor(min*1e6+0.5) / 1e6, math.Floor(max*1e6+0.5) / 1e6}
+	}
+	for _, test := range []struct {
+		min, max    float64
+		includeZero bool
+		symmetric   bool
+		padding     float64
+		want        [2]float64
+	}{
+		{min: 0, max: 10, want: [2]float64{0, 10}},
+		{min: 2, max: 4, includeZero: true, want: [2]float64{0, 4}},
+		{want: [2]float64{1, 3}},
+		{includeZero: true, want: [2]float64{0, 3}},
+		{symmetric: true, want: [2]float64{-3, 3}},
+		{padding: 0.25, want: [2]float64{0.5, 3.5}},
+		{symmetric: true, padding: 0.1, want: [2]float64{-3.6, 3.6}},
+	} {
+		sc.Min, sc.Max = test.min, test.max
+		sc.IncludeZero, sc.Symmetric, sc.Padding = test.includeZero, test.symmetric, test.padding
+		c.Check(round(sc.ScoreRange()), check.Equals, test.want)
+
+		// The resolved range is passed to the renderer and used by its axis.
+		t.Min, t.Max = 0, 0
+		t.Axis = &rings.Axis{Tick: rings.TickConfig{Marker: plot.ConstantTicks{{Value: 0, Label: "0"}}}}
+		sc.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{150, 150})
+		c.Check(round(t.Min, t.Max), check.Equals, test.want)
+	}
+
+	// A Set without finite scores has an empty automatic range.
+	sc.Set = []rings.Scorer{&fs{start: 0, end: 10, location: chr, scores: []float64{math.NaN()}}}
+	sc.Min, sc.Max = 0, 0
+	sc.IncludeZero, sc.Symmetric, sc.Padding = false, false, 0
+	min, max := sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 0})
+}
+
+func (s *S) TestSharedRange(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	var (
+		traces [3]*rings.Trace
+		scores [3]*rings.Scores
+	)
+	for i, values := range [][]float64{{0, 1, 2, 3}, {-2, -1, 0, 1}, {100, 100, 100, 100}} {
+		values := values
+		traces[i] = &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}}
+		scores[i], err = rings.NewScores(makeScorers(chr, 4, 1, func(j, _ int) float64 { return values[j] }),
+			b, 40+vg.Length(i)*10, 45+vg.Length(i)*10, traces[i])
+		c.Assert(err, check.Equals, nil)
+	}
+	// The first two scores are auto-ranged and the last has an explicit range.
+	scores[0].Min, scores[0].Max = 0, 0
+	scores[1].Min, scores[1].Max = 0, 0
+	scores[2].Min, scores[2].Max = 0, 10
+
+	r := rings.NewRange()
+	for _, sc := range scores {
+		sc.Range = r
+	}
+
+	// A Scores not included in the Range contributes only its own scores.
+	min, max := scores[0].ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 3})
+	min, max = r.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 0})
+
+	// The shared range of the included scores is used however they are drawn.
+	r.Include(scores[:]...)
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	p.Add(scores[0], scores[1], scores[2])
+	p.HideAxes()
+	p.Draw(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300))
+	for i, want := range [][2]float64{{-2, 3}, {-2, 3}, {0, 10}} {
+		c.Check([2]float64{traces[i].Min, traces[i].Max}, check.Equals, want)
+	}
+
+	// The shared range follows changes to the included scores.
+	scores[1].Set[0].Scores()[0] = -4
+	min, max = scores[0].ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-4, 3})
+	scores[1].Set[0].Scores()[0] = -2
+
+	// The shared range applies its own adjustments, and explicit values win.
+	r.Symmetric = true
+	min, max = r.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-3, 3})
+	scores[0].Symmetric = false
+	min, max = scores[0].ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-3, 3})
+	r.Min, r.Max = -5, 5
+	min, max = scores[1].ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-5, 5})
+	r.Min, r.Max, r.Symmetric = 0, 0, false
+
+	// Scores drawn directly include their scores explicitly.
+	direct := rings.NewRange()
+	min, max = direct.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 0})
+	direct.Include(scores[:]...)
+	min, max = direct.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-2, 3})
+	direct.Reset()
+	direct.Include(scores[0])
+	min, max = direct.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 3})
+
+	// An axis with a shared range is drawn according to the explicit range of
+	// its Scores, and otherwise according to the shared range.
+	traces[2].Axis = &rings.Axis{
+		Range: r,
+		Tick: rings.TickConfig{
+			LineStyle: plotter.DefaultLineStyle,
+			Length:    2,
+			Marker:    plot.ConstantTicks{{Value: 0, Label: "0"}},
+		},
+	}
+	cen := vg.Point{150, 150}
+	ticks := func(sc *rings.Scores) []float64 {
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var ticks []float64
+		for _, a := range tc.actions {
+			if st, ok := a.(stroke); ok && len(st.path) == 2 && st.path[1].Type == vg.LineComp {
+				ticks = append(ticks, float64(st.path[0].Pos.X-cen.X))
+			}
+		}
+		return ticks
+	}
+	// Zero is at the inner radius for the explicit range of [0, 10], and two
+	// fifths of the way through the shared range of [-2, 3] for an axis with a
+	// Trace taking its range from the shared range.
+	c.Check(ticks(scores[2]), check.DeepEquals, []float64{60})
+	traces[0].Axis = traces[2].Axis
+	c.Check(ticks(scores[0]), check.DeepEquals, []float64{42})
+}
+
+func (s *S) TestTickFormat(c *check.C) {
+	for _, test := range []struct {
+		fn   func(float64) string
+		v    float64
+		want string
+	}{
+		{fn: rings.CommaFormat, v: 0, want: "0"},
+		{fn: rings.CommaFormat, v: 999, want: "999"},
+		{fn: rings.CommaFormat, v: 1000, want: "1,000"},
+		{fn: rings.CommaFormat, v: 12345678, want: "12,345,678"},
+		{fn: rings.CommaFormat, v: -123456.5, want: "-123,456.5"},
+
+		{fn: rings.SIFormat("Hz"), v: 0, want: "0 Hz"},
+		{fn: rings.SIFormat("Hz"), v: 12345, want: "12.3 kHz"},
+		{fn: rings.SIFormat("Hz"), v: 0.0025, want: "2.5 mHz"},
+		{fn: rings.SIFormat("Hz"), v: 999999, want: "1 MHz"},
+		{fn: rings.SIFormat(""), v: -1500, want: "-1.5 k"},
+		{fn: rings.SIFormat(""), v: 42, want: "42"},
+
+		{fn: rings.GenomicFormat, v: 0, want: "0 b"},
+		{fn: rings.GenomicFormat, v: 0.5, want: "0.5 b"},
+		{fn: rings.GenomicFormat, v: 500, want: "500 b"},
+		{fn: rings.GenomicFormat, v: 1000, want: "1 kb"},
+		{fn: rings.GenomicFormat, v: 12345678, want: "12.3 Mb"},
+		{fn: rings.GenomicFormat, v: 3.1e9, want: "3.1 Gb"},
+	} {
+		c.Check(test.fn(test.v), check.Equals, test.want, check.Commentf("value %v", test.v))
+	}
+
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	tick := rings.TickConfig{
+		Label:     draw.TextStyle{Color: color.Gray16{0}, Font: font},
+		LineStyle: plotter.DefaultLineStyle,
+		Length:    2,
+		Marker: plot.ConstantTicks{
+			{Value: 0, Label: "zero"},
+			{Value: 5e6},
+			{Value: 1e7, Label: "ten million"},
+		},
+	}
+
+	// labels returns the tick label text rendered by DrawAt.
+	labels := func(r interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) []string {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var l []string
+		for _, a := range tc.actions {
+			if t, ok := a.(fillString); ok {
+				l = append(l, t.str)
+			}
+		}
+		return l
+	}
+
+	chr := &fs{start: 0, end: 1e7, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) * 1e7 / 3 }),
+		b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Axis: &rings.Axis{Tick: tick}})
+	c.Assert(err, check.Equals, nil)
+	scale, err := rings.NewScale([]feat.Feature{chr}, b, 100)
+	c.Assert(err, check.Equals, nil)
+	scale.Tick = tick
+	scale.LineStyle = plotter.DefaultLineStyle
+
+	// Minor ticks are not labeled and labels are taken from the Ticker
+	// unless a Format is provided.
+	c.Check(labels(sc), check.DeepEquals, []string{"zero", "ten million"})
+	c.Check(labels(scale), check.DeepEquals, []string{"zero", "ten million"})
+
+	sc.Renderer.(*rings.Trace).Axis.Tick.Format = rings.GenomicFormat
+	scale.Tick.Format = rings.CommaFormat
+	c.Check(labels(sc), check.DeepEquals, []string{"0 b", "10 Mb"})
+	c.Check(labels(scale), check.DeepEquals, []string{"0", "10,000,000"})
+}
+
+func (s *S) TestTickSide(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	unaligned := func(Angle rings.Angle) (rings.Angle, float64, float64) { return 0, 0, 0 }
+	tick := rings.TickConfig{
+		Label:     draw.TextStyle{Color: color.Gray16{0}, Font: font},
+		LineStyle: plotter.DefaultLineStyle,
+		Placement: unaligned,
+		Length:    2,
+		Marker:    plot.ConstantTicks{{Value: 0, Label: "0"}},
+	}
+
+	// ticks returns the displacement of the end of each tick mark and of each
+	// label anchor from the start of the first tick mark.
+	cen := vg.Point{150, 150}
+	// near returns whether the points are the same, allowing for the small vertical
+	// adjustment of text by the canvas.
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 0.25 }
+	ticks := func(r interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) (marks, labels []vg.Point) {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var start vg.Point
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				if len(a.path) == 2 && a.path[1].Type == vg.LineComp {
+					start = a.path[0].Pos
+					marks = append(marks, a.path[1].Pos.Sub(start))
+				}
+			case fillString:
+				labels = append(labels, vg.Point{a.x, a.y}.Sub(start))
+			}
+		}
+		return marks, labels
+	}
+
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	ax := &rings.Axis{Tick: tick}
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) }),
+		b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Axis: ax})
+	c.Assert(err, check.Equals, nil)
+
+	// The radial axis at angle zero has ticks perpendicular to it.
+	for _, test := range []struct {
+		side   rings.TickSide
+		offset vg.Length
+		mark   vg.Point
+		label  vg.Point
+	}{
+		{side: rings.TickOutside, mark: vg.Point{0, 2}, label: vg.Point{0, 4}},
+		{side: rings.TickInside, mark: vg.Point{0, -2}, label: vg.Point{0, -4}},
+		{side: rings.TickOutside, offset: 3, mark: vg.Point{0, 2}, label: vg.Point{0, 3}},
+		{side: rings.TickInside, offset: 3, mark: vg.Point{0, -2}, label: vg.Point{0, -3}},
+	} {
+		ax.Tick.Side, ax.Tick.LabelOffset = test.side, test.offset
+		marks, labels := ticks(sc)
+		c.Check(marks, check.DeepEquals, []vg.Point{test.mark})
+		c.Assert(labels, check.HasLen, 1)
+		c.Check(near(labels[0], test.label), check.Equals, true, check.Commentf("got label at %v want %v", labels[0], test.label))
+	}
+
+	// The circular scale at angle zero has radial ticks.
+	scale, err := rings.NewScale([]feat.Feature{chr}, b, 100)
+	c.Assert(err, check.Equals, nil)
+	scale.Tick = tick
+	h := font.Extents().Height
+	for _, test := range []struct {
+		side   rings.TickSide
+		offset vg.Length
+		mark   vg.Point
+		label  vg.Point
+	}{
+		{side: rings.TickOutside, mark: vg.Point{2, 0}, label: vg.Point{2 + h, 0}},
+		{side: rings.TickInside, mark: vg.Point{-2, 0}, label: vg.Point{-2 - h, 0}},
+		{side: rings.TickInside, offset: 1, mark: vg.Point{-2, 0}, label: vg.Point{-1, 0}},
+	} {
+		scale.Tick.Side, scale.Tick.LabelOffset = test.side, test.offset
+		marks, labels := ticks(scale)
+		c.Check(marks, check.DeepEquals, []vg.Point{test.mark})
+		c.Assert(labels, check.HasLen, 1)
+		c.Check(near(labels[0], test.label), check.Equals, true, check.Commentf("got label at %v want %v", labels[0], test.label))
+	}
+}
+
+func (s *S) TestCenter(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	sty := draw.TextStyle{Color: color.Gray16{0}, Font: font}
+	text := "the quick brown fox jumps over the lazy dog\nsupercalifragilisticexpialidocious"
+
+	r := rings.NewCenter(text, sty)
+	c.Check(r.Lines(), check.DeepEquals, []string{
+		"the quick brown fox jumps over the lazy dog",
+		"supercalifragilisticexpialidocious",
+	})
+
+	// Rectangle wrapping keeps every line within the width unless it is a single word.
+	r.Width = 60
+	lines := r.Lines()
+	c.Check(len(lines) > 2, check.Equals, true)
+	c.Check(strings.Join(lines, " "), check.Equals, strings.Join(strings.Fields(text), " "))
+	for _, l := range lines {
+		if strings.Contains(l, " ") {
+			c.Check(font.Width(l) <= r.Width, check.Equals, true, check.Commentf("line %q", l))
+		}
+	}
+	c.Check(lines[len(lines)-1], check.Equals, "supercalifragilisticexpialidocious")
+
+	// Circle wrapping keeps every line within the chord of the circle at the line's
+	// edge furthest from the center.
+	r.Width = 0
+	r.Radius = 60
+	r.Text = "the quick brown fox jumps over the lazy dog and the quick brown fox jumps over the lazy dog again"
+	lines = r.Lines()
+	c.Check(len(lines) > 1, check.Equals, true)
+	c.Check(strings.Join(lines, " "), check.Equals, r.Text)
+	h := font.Extents().Height
+	for i, l := range lines {
+		mid := (vg.Length(len(lines)-1)/2 - vg.Length(i)) * h
+		y := math.Max(math.Abs(float64(mid+h/2)), math.Abs(float64(mid-h/2)))
+		chord := 2 * math.Sqrt(float64(r.Radius*r.Radius)-y*y)
+		c.Check(float64(font.Width(l)) <= chord, check.Equals, true, check.Commentf("line %q", l))
+	}
+
+	// Lines are centered on the rendering location and the image is scaled to the
+	// image radius, centered on the same location.
+	r = rings.NewCenter("one\ntwo\nthree", sty)
+	r.LineSpacing = 2
+	r.Image = image.NewRGBA(image.Rect(0, 0, 30, 40))
+	r.ImageRadius = 25
+	cen := vg.Point{150, 150}
+	tc := &canvas{dpi: defaultDPI}
+	r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+
+	var (
+		rects []vg.Rectangle
+		texts []string
+		ys    []vg.Length
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case drawImage:
+			rects = append(rects, a.rect)
+		case fillString:
+			texts = append(texts, a.str)
+			c.Check(math.Abs(float64(a.x+font.Width(a.str)/2-cen.X)) < 1e-9, check.Equals, true)
+			ys = append(ys, a.y)
+		}
+	}
+	c.Check(rects, check.DeepEquals, []vg.Rectangle{{Min: vg.Point{135, 130}, Max: vg.Point{165, 170}}})
+	c.Check(texts, check.DeepEquals, []string{"one", "two", "three"})
+	c.Assert(len(ys), check.Equals, 3)
+	c.Check(math.Abs(float64(ys[0]-ys[1]-(h+2))) < 1e-9, check.Equals, true)
+	c.Check(math.Abs(float64(ys[1]-ys[2]-(h+2))) < 1e-9, check.Equals, true)
+	// The middle line is vertically centered on the rendering location.
+	c.Check(math.Abs(float64(ys[1]-(cen.Y-font.Extents().Ascent*3/2+font.Size))) < 1e-9, check.Equals, true)
+}
+
+func (s *S) TestLayout(c *check.C) {
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 50, name: "chr2"},
+		&fs{start: 0, end: 80, name: "chr3"},
+	}
+	b, err := rings.NewGappedBlocks(chrs, rings.Arc{rings.Complete / 4, rings.Clockwise * rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+
+	var buf bytes.Buffer
+	c.Assert(b.SaveLayout(&buf), check.Equals, nil)
+	l, err := rings.LoadLayout(&buf)
+	c.Assert(err, check.Equals, nil)
+	c.Check(l.Warnings, check.HasLen, 0)
+	c.Check(l.Arc(), check.Equals, b.Arc())
+
+	// Features of a different program with the same names are given the same arcs.
+	detail := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 80, name: "chr3"},
+	}
+	for i, f := range detail {
+		want, err := b.ArcOf(chrs[2*i], nil)
+		c.Assert(err, check.Equals, nil)
+		got, err := l.ArcOf(f, nil)
+		c.Check(err, check.Equals, nil)
+		c.Check(got, check.Equals, want)
+	}
+	sub := &fs{start: 20, end: 40, location: detail[0]}
+	want, err := b.ArcOf(chrs[0], &fs{start: 20, end: 40, location: chrs[0]})
+	c.Assert(err, check.Equals, nil)
+	got, err := l.ArcOf(detail[0], sub)
+	c.Check(err, check.Equals, nil)
+	c.Check(got, check.Equals, want)
+	got, err = l.ArcOf(nil, sub)
+	c.Check(err, check.Equals, nil)
+	c.Check(got, check.Equals, l.Arcs["chr1"])
+	_, err = l.ArcOf(&fs{name: "chrX"}, nil)
+	c.Check(err, check.ErrorMatches, "rings: location not found")
+
+	// The restored layout can be used as the base of new tracks.
+	nb, err := rings.NewBlocks(detail, l, 80, 100)
+	c.Assert(err, check.Equals, nil)
+	c.Check(nb.Base, check.Equals, rings.ArcOfer(l))
+
+	l.Match(detail)
+	c.Check(l.Warnings, check.HasLen, 1)
+	c.Check(l.Warnings[0], check.ErrorMatches, `rings: layout feature "chr2" not found`)
+
+	// Name collisions are reported.
+	b.Set = append(b.Set, &fs{start: 0, end: 10, name: "chr1"})
+	b.Base = rings.NewGappedArcs(b.Arc(), b.Set, 0.01)
+	buf.Reset()
+	c.Assert(b.SaveLayout(&buf), check.Equals, nil)
+	l, err = rings.LoadLayout(&buf)
+	c.Assert(err, check.Equals, nil)
+	c.Check(l.Warnings, check.HasLen, 1)
+	c.Check(l.Warnings[0], check.ErrorMatches, `rings: layout name collision for "chr1"`)
+	l.Match(append(detail, &fs{start: 0, end: 5, name: "chr3"}))
+	c.Check(l.Warnings, check.HasLen, 3)
+	c.Check(l.Warnings[1], check.ErrorMatches, `rings: feature name collision for "chr3"`)
+	c.Check(l.Warnings[2], check.ErrorMatches, `rings: layout feature "chr2" not found`)
+}
+
+func (s *S) TestPolarGrid(c *check.C) {
+	cen := vg.Point{150, 150}
+	sty := plotter.DefaultLineStyle
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	strokes := func(r *rings.PolarGrid) []vg.Path {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var paths []vg.Path
+		for _, a := range tc.actions {
+			if a, ok := a.(stroke); ok {
+				paths = append(paths, a.path)
+			}
+		}
+		return paths
+	}
+
+	// A complete grid renders full circles as arcs and spokes evenly spaced around the circle.
+	g := rings.NewPolarGrid(sty, 20, 80, 4, 4)
+	paths := strokes(g)
+	c.Assert(paths, check.HasLen, 2)
+	c.Assert(paths[0], check.HasLen, 8)
+	for i, rad := range []vg.Length{20, 40, 60, 80} {
+		c.Check(paths[0][2*i], check.Equals, vg.PathComp{Type: vg.MoveComp, Pos: vg.Point{cen.X + rad, cen.Y}})
+		c.Check(paths[0][2*i+1], check.Equals, vg.PathComp{Type: vg.ArcComp, Pos: cen, Radius: rad, Start: 0, Angle: 2 * math.Pi})
+	}
+	c.Assert(paths[1], check.HasLen, 8)
+	for i := 0; i < 4; i++ {
+		theta := rings.Angle(i) * rings.Complete / 4
+		c.Check(near(paths[1][2*i].Pos, cen.Add(rings.Rectangular(theta, 20))), check.Equals, true)
+		c.Check(near(paths[1][2*i+1].Pos, cen.Add(rings.Rectangular(theta, 80))), check.Equals, true)
+	}
+
+	// A grid restricted to an arc renders partial circles and spokes at both ends of the arc.
+	g.Arc = rings.Arc{0, rings.Complete / 4}
+	g.Radii = []vg.Length{50}
+	g.Spokes = 3
+	paths = strokes(g)
+	c.Assert(paths, check.HasLen, 2)
+	c.Check(paths[0], check.DeepEquals, vg.Path{
+		{Type: vg.MoveComp, Pos: vg.Point{200, 150}},
+		{Type: vg.ArcComp, Pos: cen, Radius: 50, Start: 0, Angle: math.Pi / 2},
+	})
+	c.Assert(paths[1], check.HasLen, 6)
+	for i := 0; i < 3; i++ {
+		theta := rings.Angle(i) * rings.Complete / 8
+		c.Check(near(paths[1][2*i+1].Pos, cen.Add(rings.Rectangular(theta, 80))), check.Equals, true)
+	}
+
+	// Explicit angles outside the arc are not rendered and zero styles render nothing.
+	g.Angles = []rings.Angle{rings.Complete / 8, rings.Complete / 2}
+	g.CircleStyle = draw.LineStyle{}
+	paths = strokes(g)
+	c.Assert(paths, check.HasLen, 1)
+	c.Check(paths[0], check.HasLen, 2)
+	c.Check(near(paths[0][1].Pos, cen.Add(rings.Rectangular(rings.Complete/8, 80))), check.Equals, true)
+}
+
+func (s *S) TestWinding(c *check.C) {
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	cen := vg.Point{150, 150}
+	mirror := func(p vg.Point) vg.Point { return vg.Point{p.X, 2*cen.Y - p.Y} }
+	font, err := vg.MakeFont("Helvetica", 5)
+	c.Assert(err, check.Equals, nil)
+
+	// A ring drawn with a clockwise base is the mirror image of
+	// the same ring drawn with a counter clockwise base.
+	render := func(w rings.Angle) []interface{} {
+		loc := &fs{start: 0, end: 1000, name: "chr"}
+		arc := rings.Arc{0, w * rings.Complete / 2}
+		base := rings.Arcs{Base: arc, Arcs: map[feat.Feature]rings.Arc{loc: arc}}
+		r, err := rings.NewScores(
+			makeScorers(loc, 10, 1, func(i, _ int) float64 { return float64(i * i) }),
+			base, 40, 75,
+			&rings.Trace{
+				LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+				Axis: &rings.Axis{
+					Angle:     w * rings.Complete / 8,
+					LineStyle: plotter.DefaultLineStyle,
+					Tick: rings.TickConfig{
+						Marker:    plot.DefaultTicks{},
+						LineStyle: plotter.DefaultLineStyle,
+						Length:    2,
+						Label:     draw.TextStyle{Color: color.Gray16{0}, Font: font},
+					},
+				},
+			},
+		)
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		return tc.actions
+	}
+	// The paths of a clockwise ring are traversed in a different
+	// order, so the rendered geometry is compared as sets of lines
+	// and arcs, each described by its end points and mid point.
+	type segment struct {
+		ends [2]vg.Point
+		mid  vg.Point
+	}
+	points := func(actions []interface{}) (segs []segment, labels []vg.Point) {
+		for _, a := range actions {
+			switch a := a.(type) {
+			case stroke:
+				var cur vg.Point
+				for _, pc := range a.path {
+					switch pc.Type {
+					case vg.MoveComp:
+						cur = pc.Pos
+					case vg.LineComp:
+						segs = append(segs, segment{ends: [2]vg.Point{cur, pc.Pos}, mid: vg.Point{(cur.X + pc.Pos.X) / 2, (cur.Y + pc.Pos.Y) / 2}})
+						cur = pc.Pos
+					case vg.ArcComp:
+						end := rings.RectangularAt(pc.Pos, rings.Angle(pc.Start+pc.Angle), pc.Radius)
+						mid := rings.RectangularAt(pc.Pos, rings.Angle(pc.Start+pc.Angle/2), pc.Radius)
+						segs = append(segs, segment{ends: [2]vg.Point{rings.RectangularAt(pc.Pos, rings.Angle(pc.Start), pc.Radius), end}, mid: mid})
+						cur = end
+					}
+				}
+			case translate:
+				// Labels are rotated about their anchor by
+				// translating to the anchor and back.
+				if a.x > 0 {
+					labels = append(labels, vg.Point{a.x, a.y})
+				}
+			}
+		}
+		return segs, labels
+	}
+	contains := func(pts []vg.Point, p vg.Point) bool {
+		for _, q := range pts {
+			if near(p, q) {
+				return true
+			}
+		}
+		return false
+	}
+	containsSegment := func(segs []segment, s segment) bool {
+		for _, t := range segs {
+			if near(s.mid, t.mid) && (near(s.ends[0], t.ends[0]) && near(s.ends[1], t.ends[1]) || near(s.ends[0], t.ends[1]) && near(s.ends[1], t.ends[0])) {
+				return true
+			}
+		}
+		return false
+	}
+	ccwSegs, ccwLabels := points(render(rings.CounterClockwise))
+	cwSegs, cwLabels := points(render(rings.Clockwise))
+	c.Assert(cwSegs, check.HasLen, len(ccwSegs))
+	c.Assert(cwLabels, check.HasLen, len(ccwLabels))
+	c.Check(len(ccwLabels) > 0, check.Equals, true)
+	for _, sg := range ccwSegs {
+		m := segment{ends: [2]vg.Point{mirror(sg.ends[0]), mirror(sg.ends[1])}, mid: mirror(sg.mid)}
+		c.Check(containsSegment(cwSegs, m), check.Equals, true, check.Commentf("segment %v", sg))
+	}
+	for _, p := range ccwLabels {
+		c.Check(contains(cwLabels, mirror(p)), check.Equals, true, check.Commentf("label point %v", p))
+	}
+
+	// The bisector of the ends of a Bezier link does not depend
+	// on the normalisation of the end angles.
+	b := &rings.Bezier{Segments: 4, Radius: rings.LengthDist{Length: 10}}
+	for _, t := range []struct {
+		ends [2]rings.Angle
+		want vg.Point
+	}{
+		{ends: [2]rings.Angle{0.1, -0.1}, want: vg.Point{10, 0}},
+		{ends: [2]rings.Angle{0.1, rings.Complete - 0.1}, want: vg.Point{10, 0}},
+		{ends: [2]rings.Angle{-rings.Complete + 0.1, -0.1}, want: vg.Point{10, 0}},
+		{ends: [2]rings.Angle{3, -3}, want: vg.Point{-10, 0}},
+	} {
+		got := b.ControlPoints(t.ends, [2]vg.Length{40, 40})
+		c.Assert(got, check.HasLen, 3)
+		c.Check(near(got[1], t.want), check.Equals, true, check.Commentf("ends %v", t.ends))
+	}
+}
+
+func (s *S) TestCrossLinks(c *check.C) {
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	cens := [2]vg.Point{{60, 150}, {240, 150}}
+
+	// The middle control point lies on the segment between the centers, displaced from
+	// its midpoint toward the projection of the midpoint of the end points.
+	b := &rings.Bezier{Segments: 4, Radius: rings.LengthDist{Length: 30}}
+	p := [2]vg.Point{{100, 170}, {200, 170}}
+	c.Check(b.ControlPointsAt(p, cens), check.DeepEquals, []vg.Point{p[0], {180, 150}, p[1]})
+	p = [2]vg.Point{{100, 150}, {260, 190}}
+	pts := b.ControlPointsAt(p, cens)
+	c.Assert(pts, check.HasLen, 3)
+	c.Check(near(pts[1], vg.Point{180, 150}), check.Equals, true)
+	p = [2]vg.Point{{40, 150}, {200, 150}}
+	c.Check(near(b.ControlPointsAt(p, cens)[1], vg.Point{120, 150}), check.Equals, true)
+
+	// The middle control point is restricted to the segment.
+	b.Radius.Length = 200
+	c.Check(near(b.ControlPointsAt(p, cens)[1], vg.Point{60, 150}), check.Equals, true)
+
+	// Crest control points lie on the rays from each center through its end point.
+	b.Radius.Length = 0
+	b.Crest = &rings.FactorDist{Factor: 0.5}
+	p = [2]vg.Point{{100, 150}, {200, 150}}
+	pts = b.ControlPointsAt(p, cens)
+	c.Assert(pts, check.HasLen, 5)
+	c.Check(near(pts[1], vg.Point{80, 150}), check.Equals, true)
+	c.Check(near(pts[2], vg.Point{150, 150}), check.Equals, true)
+	c.Check(near(pts[3], vg.Point{220, 150}), check.Equals, true)
+
+	// Coincident centers give the control points of ControlPoints.
+	b = &rings.Bezier{Segments: 4, Radius: rings.LengthDist{Length: 10}}
+	got := b.ControlPointsAt([2]vg.Point{{140, 150}, {150, 140}}, [2]vg.Point{{100, 150}, {100, 150}})
+	want := b.ControlPoints([2]rings.Angle{0, rings.Angle(math.Atan2(-10, 50)) + rings.Complete}, [2]vg.Length{40, vg.Length(math.Hypot(50, 10))})
+	c.Assert(got, check.HasLen, len(want))
+	for i := range want {
+		c.Check(near(got[i], want[i].Add(vg.Point{100, 150})), check.Equals, true)
+	}
+
+	// Links are rendered between the end points in each plot's coordinates.
+	chrA := &fs{start: 0, end: 100, name: "chrA"}
+	chrB := &fs{start: 0, end: 100, name: "chrB"}
+	endA, err := rings.NewGappedBlocks([]feat.Feature{chrA}, rings.Arc{0, rings.Complete}, 40, 50, 0)
+	c.Assert(err, check.Equals, nil)
+	endB, err := rings.NewGappedBlocks([]feat.Feature{chrB}, rings.Arc{rings.Complete / 2, rings.Complete}, 40, 50, 0)
+	c.Assert(err, check.Equals, nil)
+	sty := plotter.DefaultLineStyle
+	pair := fp{
+		feats: [2]*fs{{start: 0, end: 1, location: chrA, style: sty}, {start: 0, end: 1, location: chrB, style: sty}},
+		sty:   sty,
+	}
+	l, err := rings.NewCrossLinks([]rings.Pair{pair}, [2]rings.ArcOfer{endA, endB}, [2]vg.Length{40, 40})
+	c.Assert(err, check.Equals, nil)
+	c.Check(rings.ValidateAll(l), check.Equals, nil)
+	l.Bezier = &rings.Bezier{Segments: 2}
+
+	tc := &canvas{dpi: defaultDPI}
+	l.DrawAt(draw.NewCanvas(tc, 300, 300), cens)
+	var paths []vg.Path
+	for _, a := range tc.actions {
+		if a, ok := a.(stroke); ok {
+			paths = append(paths, a.path)
+		}
+	}
+	c.Assert(paths, check.HasLen, 1)
+	c.Assert(paths[0], check.HasLen, 3)
+	c.Check(near(paths[0][0].Pos, vg.Point{100, 150}), check.Equals, true)
+	c.Check(near(paths[0][1].Pos, vg.Point{150, 150}), check.Equals, true)
+	c.Check(near(paths[0][2].Pos, vg.Point{200, 150}), check.Equals, true)
+}
+
+func (s *S) TestDrawAtContext(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	var tiled []feat.Feature
+	var pairs []rings.Pair
+	for i := 0; i < 10; i++ {
+		f := &fs{start: i * 10, end: i*10 + 5, location: chr, style: plotter.DefaultLineStyle}
+		tiled = append(tiled, f)
+		pairs = append(pairs, fp{feats: [2]*fs{f, f}, sty: plotter.DefaultLineStyle})
+	}
+	tiles, err := rings.NewTiles(tiled, b, 60, 80)
+	c.Assert(err, check.Equals, nil)
+	links, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{60, 60})
+	c.Assert(err, check.Equals, nil)
+	scores, err := rings.NewScores(makeScorers(chr, 10, 1, func(i, _ int) float64 { return float64(i) }),
+		b, 40, 60, &rings.Heat{Palette: []color.Color{color.Black}})
+	c.Assert(err, check.Equals, nil)
+
+	type drawer interface {
+		DrawAtContext(context.Context, draw.Canvas, vg.Point) error
+	}
+	for _, r := range []struct {
+		drawer
+		progress *func(done, total int)
+	}{
+		{tiles, &tiles.Progress},
+		{links, &links.Progress},
+		{scores, &scores.Progress},
+	} {
+		// Uncancelled rendering reports progress and completion.
+		var reports [][2]int
+		*r.progress = func(done, total int) { reports = append(reports, [2]int{done, total}) }
+		tc := &canvas{dpi: defaultDPI}
+		c.Check(r.DrawAtContext(rings.WithProgressInterval(context.Background(), 3), draw.NewCanvas(tc, 300, 300), vg.Point{150, 150}), check.Equals, nil)
+		c.Check(reports, check.DeepEquals, [][2]int{{3, 10}, {6, 10}, {9, 10}, {10, 10}})
+
+		// Intervals that are not positive are replaced by the default interval.
+		for _, n := range []int{0, -1} {
+			reports = nil
+			tc = &canvas{dpi: defaultDPI}
+			c.Check(r.DrawAtContext(rings.WithProgressInterval(context.Background(), n), draw.NewCanvas(tc, 300, 300), vg.Point{150, 150}), check.Equals, nil)
+			c.Check(reports, check.DeepEquals, [][2]int{{10, 10}})
+		}
+
+		// Rendering stops at the next check after cancellation.
+		ctx, cancel := context.WithCancel(rings.WithProgressInterval(context.Background(), 3))
+		reports = nil
+		*r.progress = func(done, total int) {
+			reports = append(reports, [2]int{done, total})
+			if done >= 6 {
+				cancel()
+			}
+		}
+		tc = &canvas{dpi: defaultDPI}
+		c.Check(r.DrawAtContext(ctx, draw.NewCanvas(tc, 300, 300), vg.Point{150, 150}), check.Equals, context.Canceled)
+		c.Check(reports, check.DeepEquals, [][2]int{{3, 10}, {6, 10}})
+
+		// A cancelled context renders nothing.
+		tc = &canvas{dpi: defaultDPI}
+		c.Check(r.DrawAtContext(ctx, draw.NewCanvas(tc, 300, 300), vg.Point{150, 150}), check.Equals, context.Canceled)
+		c.Check(tc.actions, check.HasLen, 0)
+		*r.progress = nil
+	}
+}
+
+func (s *S) TestArcDashes(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+	type dash struct{ start, sweep float64 }
+	render := func(r interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) (dashes []dash, lines int, lineDashes []vg.Length) {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case setLineDash:
+				lineDashes = a.dashes
+			case stroke:
+				for _, p := range a.path {
+					switch p.Type {
+					case vg.ArcComp:
+						dashes = append(dashes, dash{p.Start, p.Angle})
+					case vg.LineComp:
+						lines++
+					}
+				}
+			}
+		}
+		return dashes, lines, lineDashes
+	}
+	match := func(got, want []dash) {
+		c.Assert(got, check.HasLen, len(want))
+		for i := range want {
+			c.Check(near(got[i].start, want[i].start) && near(got[i].sweep, want[i].sweep), check.Equals, true,
+				check.Commentf("dash %d: got %v want %v", i, got[i], want[i]))
+		}
+	}
+
+	sty := plotter.DefaultLineStyle
+	sty.Dashes = []vg.Length{2, 2}
+	pattern := rings.ArcDashes{Angles: []rings.Angle{math.Pi / 4, math.Pi / 4}}
+
+	// A complete disc border is dashed around the circle from the pattern offset.
+	a := &rings.Annulus{Outer: 50, LineStyle: sty, ArcDashes: pattern}
+	dashes, lines, lineDashes := render(a)
+	match(dashes, []dash{{0, math.Pi / 4}, {math.Pi / 2, math.Pi / 4}, {math.Pi, math.Pi / 4}, {3 * math.Pi / 2, math.Pi / 4}})
+	c.Check(lines, check.Equals, 0)
+	c.Check(lineDashes, check.HasLen, 0)
+
+	// Dashes are anchored at the pattern offset, so concentric arcs are aligned, and
+	// clockwise arcs are dashed in the same positions as counter-clockwise arcs.
+	h := &rings.Highlight{Base: rings.Arc{math.Pi / 8, -math.Pi / 2}, Inner: 40, Outer: 60, LineStyle: sty, ArcDashes: pattern}
+	dashes, lines, lineDashes = render(h)
+	arc := []dash{{-3 * math.Pi / 8, math.Pi / 8}, {0, math.Pi / 8}}
+	match(dashes, append(arc, arc...))
+	c.Check(lines, check.Equals, 2)
+	c.Check(lineDashes, check.HasLen, 0)
+
+	pattern.Offset = math.Pi / 8
+	h.ArcDashes = pattern
+	dashes, _, _ = render(h)
+	arc = []dash{{-3 * math.Pi / 8, math.Pi / 4}}
+	match(dashes, append(arc, arc...))
+
+	// Unset angular dashes fall back to the line style dashes.
+	h.ArcDashes = rings.ArcDashes{}
+	dashes, lines, lineDashes = render(h)
+	match(dashes, []dash{{math.Pi / 8, -math.Pi / 2}, {-3 * math.Pi / 8, math.Pi / 2}})
+	c.Check(lineDashes, check.DeepEquals, sty.Dashes)
+}
+
+func (s *S) TestInterval(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	vals := [][]float64{{1, 2, 4}, {0, 3, 10}}
+	set := makeScorers(chr, 2, 3, func(i, j int) float64 { return vals[i][j] })
+
+	// The range is determined by the lo and hi values, not the mid values, and
+	// mid values outside the range are clamped.
+	iv := &rings.Interval{
+		Values: func(s rings.Scorer) []float64 {
+			v := s.Scores()
+			return []float64{v[0], v[1] * 100, v[2]}
+		},
+		Whisker: plotter.DefaultLineStyle,
+		Mark:    plotter.DefaultLineStyle,
+	}
+	sc, err := rings.NewScores(set, b, 40, 60, iv)
+	c.Assert(err, check.Equals, nil)
+	min, max := sc.ScoreRange()
+	c.Check(min, check.Equals, 0.)
+	c.Check(max, check.Equals, 10.)
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	render := func() (strokes []vg.Path, fills []vg.Path) {
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				strokes = append(strokes, a.path)
+			case fill:
+				fills = append(fills, a.path)
+			}
+		}
+		return strokes, fills
+	}
+	strokes, _ := render()
+	c.Assert(strokes, check.HasLen, 4)
+	c.Check(near(strokes[0][0].Pos, cen.Add(rings.Rectangular(math.Pi/2, 42))), check.Equals, true)
+	c.Check(near(strokes[0][1].Pos, cen.Add(rings.Rectangular(math.Pi/2, 48))), check.Equals, true)
+	c.Check(strokes[1][1].Type, check.Equals, vg.ArcComp)
+	c.Check(strokes[1][1].Radius, check.Equals, vg.Length(60))
+	c.Check(float64(strokes[1][1].Start), check.Equals, 0.)
+	c.Check(math.Abs(strokes[1][1].Angle-math.Pi) < 1e-12, check.Equals, true)
+	c.Check(near(strokes[2][0].Pos, cen.Add(rings.Rectangular(3*math.Pi/2, 40))), check.Equals, true)
+	c.Check(near(strokes[2][1].Pos, cen.Add(rings.Rectangular(3*math.Pi/2, 60))), check.Equals, true)
+
+	// Five values are rendered with a box of the configured angular width.
+	vals = [][]float64{{0, 2, 5, 6, 10}, {0, 1, 2, 3, 4}}
+	sc.Set = makeScorers(chr, 2, 5, func(i, j int) float64 { return vals[i][j] })
+	iv.Values = nil
+	iv.Min, iv.Max = 0, 0
+	iv.Width = math.Pi / 4
+	iv.Fill = color.Gray{0x80}
+	strokes, fills := render()
+	c.Assert(fills, check.HasLen, 2)
+	c.Check(fills[0][1].Type, check.Equals, vg.ArcComp)
+	c.Check(fills[0][1].Radius, check.Equals, vg.Length(44))
+	c.Check(math.Abs(fills[0][1].Start-3*math.Pi/8) < 1e-12, check.Equals, true)
+	c.Check(math.Abs(fills[0][1].Angle-math.Pi/4) < 1e-12, check.Equals, true)
+	c.Check(fills[0][2].Radius, check.Equals, vg.Length(52))
+	c.Assert(strokes, check.HasLen, 6)
+	c.Check(strokes[2][1].Radius, check.Equals, vg.Length(50))
+
+	// Scorers without three or five values are reported by Validate and not rendered.
+	sc.Set = makeScorers(chr, 2, 4, func(i, j int) float64 { return float64(j) })
+	c.Check(sc.Validate(), check.ErrorMatches, `rings: scorer "chr#0" has 4 scores but interval requires 3 or 5; .*`)
+	strokes, fills = render()
+	c.Check(strokes, check.HasLen, 0)
+	c.Check(fills, check.HasLen, 0)
+}
+
+func (s *S) TestErrorBand(c *check.C) {
+	cen := vg.Point{150, 150}
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	vals := [][]float64{{2, 4}, {7, 5}, {math.NaN(), 1}, {0, 10}}
+	set := makeScorers(chr, 4, 2, func(i, j int) float64 { return vals[i][j] })
+
+	band := &rings.ErrorBand{
+		Upper:      func(s rings.Scorer) float64 { return s.Scores()[0] },
+		Lower:      func(s rings.Scorer) float64 { return s.Scores()[1] },
+		Fill:       color.Gray{0x80},
+		UpperStyle: plotter.DefaultLineStyle,
+		Join:       true,
+	}
+	sc, err := rings.NewScores(set, b, 40, 60, band)
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.Equals, nil)
+	min, max := sc.ScoreRange()
+	c.Check(min, check.Equals, 0.)
+	c.Check(max, check.Equals, 10.)
+
+	type arc struct {
+		rad          vg.Length
+		start, angle float64
+	}
+	render := func() (fills, strokes [][]arc) {
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		arcs := func(pa vg.Path) []arc {
+			var a []arc
+			for _, p := range pa {
+				if p.Type == vg.ArcComp {
+					a = append(a, arc{p.Radius, math.Floor(p.Start/(math.Pi/2)*1e9+0.5) / 1e9, math.Floor(p.Angle/(math.Pi/2)*1e9+0.5) / 1e9})
+				}
+			}
+			return a
+		}
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case fill:
+				fills = append(fills, arcs(a.path))
+			case stroke:
+				strokes = append(strokes, arcs(a.path))
+			}
+		}
+		return fills, strokes
+	}
+
+	// The band is broken at the NaN value and the fill follows the greater and lesser
+	// values of each feature where the profiles cross. Angles are in quarter turns.
+	fills, strokes := render()
+	c.Check(fills, check.DeepEquals, [][]arc{
+		{{48, 0, 1}, {54, 1, 1}, {50, 2, -1}, {44, 1, -1}},
+		{{60, 3, 1}, {40, 4, -1}},
+	})
+	c.Check(strokes, check.DeepEquals, [][]arc{
+		{{44, 0, 1}, {54, 1, 1}},
+		{{40, 3, 1}},
+	})
+
+	// Without Join each feature is a separate band.
+	band.Join = false
+	band.Min, band.Max = 0, 0
+	fills, _ = render()
+	c.Check(fills, check.HasLen, 3)
+
+	band.Lower = nil
+	c.Check(sc.Validate(), check.ErrorMatches, "rings: nil band value function")
+}
+
+func (s *S) TestMarkers(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chrs, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	sty := plotter.DefaultLineStyle
+	m := rings.NewMarkers([]rings.Marker{
+		{Location: chrs[0], Pos: 50, LineStyle: sty, Inner: 20, Outer: 110, Label: "bp"},
+		{Location: chrs[1], Pos: 25, LineStyle: sty, Inner: 30, Outer: 90},
+	}, b)
+	m.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	m.Placement = func(rings.Angle) (rings.Angle, float64, float64) { return 0, 0, 0 }
+	m.LabelOffset = 5
+	c.Check(m.Validate(), check.Equals, nil)
+
+	render := func() (strokes []vg.Path, labels []fillString) {
+		tc := &canvas{dpi: defaultDPI}
+		m.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				strokes = append(strokes, a.path)
+			case fillString:
+				labels = append(labels, a)
+			}
+		}
+		return strokes, labels
+	}
+	strokes, labels := render()
+	c.Assert(strokes, check.HasLen, 2)
+	c.Check(near(strokes[0][0].Pos, rings.RectangularAt(cen, math.Pi/2, 20)), check.Equals, true)
+	c.Check(near(strokes[0][1].Pos, rings.RectangularAt(cen, math.Pi/2, 110)), check.Equals, true)
+	c.Check(near(strokes[1][0].Pos, rings.RectangularAt(cen, 5*math.Pi/4, 30)), check.Equals, true)
+	c.Check(near(strokes[1][1].Pos, rings.RectangularAt(cen, 5*math.Pi/4, 90)), check.Equals, true)
+	c.Assert(labels, check.HasLen, 1)
+	c.Check(labels[0].str, check.Equals, "bp")
+	pt := rings.RectangularAt(cen, math.Pi/2, 115)
+	c.Check(math.Abs(float64(labels[0].x-pt.X)) < 1e-9, check.Equals, true)
+
+	// Markers follow the zoomed mapping of positions by a Blocks base.
+	b.Zooms = []rings.Zoom{{Location: chrs[0], Start: 40, End: 60, Scale: 3}}
+	strokes, _ = render()
+	zoomed, err := b.ArcOf(chrs[0], &fs{start: 50, end: 50, location: chrs[0]})
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(strokes[0][1].Pos, rings.RectangularAt(cen, zoomed.Theta, 110)), check.Equals, true)
+
+	// Out of range positions are reported by Validate and not drawn.
+	m.Set[1].Pos = 101
+	m.Set = append(m.Set, rings.Marker{Pos: 1, Inner: 2, Outer: 1})
+	c.Check(m.Validate(), check.ErrorMatches, `rings: marker 1 position 101 out of range of location "chr2"; `+
+		`rings: inner radius 2 greater than outer radius 1; rings: marker 2 has no location`)
+	strokes, _ = render()
+	c.Check(strokes, check.HasLen, 1)
+}
+
+func (s *S) TestArrowBlocks(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+
+	// area returns the signed area of the outline pa, approximating arcs by their
+	// end and mid points.
+	area := func(pa vg.Path) float64 {
+		var pts []vg.Point
+		for _, p := range pa {
+			switch p.Type {
+			case vg.MoveComp, vg.LineComp:
+				pts = append(pts, p.Pos)
+			case vg.ArcComp:
+				for _, f := range []float64{0, 0.5, 1} {
+					pts = append(pts, rings.RectangularAt(p.Pos, rings.Angle(p.Start+f*p.Angle), p.Radius))
+				}
+			}
+		}
+		var a float64
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			a += float64(p.X*q.Y - q.X*p.Y)
+		}
+		return a / 2
+	}
+	types := func(pa vg.Path) []int {
+		t := make([]int, len(pa))
+		for i, p := range pa {
+			t[i] = p.Type
+		}
+		return t
+	}
+
+	chr := &fs{start: 0, end: 1000, name: "chr"}
+	set := []feat.Feature{
+		&fs{start: 0, end: 500, location: chr, orient: feat.Forward},
+		&fs{start: 500, end: 1000, location: chr, orient: feat.Reverse},
+		&fs{start: 0, end: 500, location: chr},
+		&fs{start: 500, end: 510, location: chr, orient: feat.Forward},
+	}
+	for _, phi := range []rings.Angle{math.Pi, -math.Pi} {
+		base := rings.NewGappedArcs(rings.Arc{0, phi}, []feat.Feature{chr}, 0)
+		b, err := rings.NewBlocks(set, base, 60, 70)
+		c.Assert(err, check.Equals, nil)
+		b.Color = color.Gray{0x7f}
+		b.Shape = rings.Arrow
+		b.HeadAngle = 0.2
+
+		tc := &canvas{dpi: defaultDPI}
+		b.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var fills []vg.Path
+		for _, a := range tc.actions {
+			if f, ok := a.(fill); ok {
+				fills = append(fills, f.path)
+			}
+		}
+		c.Assert(fills, check.HasLen, 4)
+
+		var rect vg.Path
+		rings.Sector{Center: cen, Inner: 60, Outer: 70, Arc: rings.Arc{0, phi / 2}}.Path(&rect, false)
+		sense := math.Signbit(area(rect))
+
+		// Forward features point to the end of their arc.
+		c.Check(types(fills[0]), check.DeepEquals, []int{vg.MoveComp, vg.ArcComp, vg.LineComp, vg.LineComp, vg.ArcComp, vg.CloseComp})
+		c.Check(near(fills[0][2].Pos, rings.RectangularAt(cen, phi/2, 65)), check.Equals, true)
+		c.Check(near(fills[0][3].Pos, rings.RectangularAt(cen, phi/2-rings.Angle(math.Copysign(0.2, float64(phi))), 70)), check.Equals, true)
+		c.Check(math.Signbit(area(fills[0])), check.Equals, sense)
+
+		// Reverse features point to the start of their arc.
+		c.Check(types(fills[1]), check.DeepEquals, []int{vg.MoveComp, vg.LineComp, vg.ArcComp, vg.LineComp, vg.ArcComp, vg.LineComp, vg.CloseComp})
+		c.Check(near(fills[1][0].Pos, rings.RectangularAt(cen, phi/2, 65)), check.Equals, true)
+		c.Check(math.Signbit(area(fills[1])), check.Equals, sense)
+
+		// Features without an orientation are rendered as rectangles.
+		c.Check(fills[2], check.DeepEquals, rect)
+
+		// Features shorter than the head are rendered as a triangle.
+		c.Check(types(fills[3]), check.DeepEquals, []int{vg.MoveComp, vg.LineComp, vg.LineComp, vg.CloseComp})
+		c.Check(near(fills[3][1].Pos, rings.RectangularAt(cen, phi*0.51, 65)), check.Equals, true)
+		c.Check(math.Signbit(area(fills[3])), check.Equals, sense)
+	}
+}
+
+func (s *S) TestTitles(c *check.C) {
+	cen := vg.Point{150, 150}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	h := font.Extents().Height
+
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	base := rings.NewGappedArcs(rings.Arc{0, rings.Complete}, []feat.Feature{chr}, 0)
+	blk, err := rings.NewBlocks([]feat.Feature{chr}, base, 80, 90)
+	c.Assert(err, check.Equals, nil)
+	t, err := rings.NewTiles([]feat.Feature{&fs{start: 0, end: 10, location: chr}}, base, 82, 88)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(makeScorers(chr, 1, 1, func(_, _ int) float64 { return 1 }), base, 40, 60,
+		&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}},
+	)
+	c.Assert(err, check.Equals, nil)
+
+	title := func(text string, side rings.TickSide, stack *rings.TitleStack) rings.Title {
+		return rings.Title{
+			Text:      text,
+			TextStyle: draw.TextStyle{Color: color.Black, Font: font},
+			Angle:     0,
+			Side:      side,
+			Stack:     stack,
+		}
+	}
+	// radius returns the radius of the anchor of the title text rendered by r.
+	radius := func(r interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) []vg.Length {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var rads []vg.Length
+		for _, a := range tc.actions {
+			if tr, ok := a.(translate); ok && tr.x > 0 {
+				_, rad := rings.Polar(vg.Point{tr.x, tr.y}.Sub(cen))
+				rads = append(rads, rad)
+			}
+		}
+		return rads
+	}
+	near := func(a, b vg.Length) bool { return math.Abs(float64(a-b)) < 1e-9 }
+
+	// Unstacked titles are placed beyond the Outer or within the Inner radius.
+	blk.Title = title("blocks", rings.TickOutside, nil)
+	rads := radius(blk)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 90+h/2), check.Equals, true)
+	sc.Title = title("scores", rings.TickInside, nil)
+	rads = radius(sc)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 40-h/2-h), check.Equals, true)
+
+	// Stacked titles at the same angle do not overlap.
+	stack := rings.NewTitleStack()
+	blk.Title = title("blocks", rings.TickOutside, stack)
+	t.Title = title("tiles", rings.TickOutside, stack)
+	stack.Include(t, blk)
+	rads = radius(t)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 88+h/2), check.Equals, true)
+	rads = radius(blk)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 88+h/2+h+h/2), check.Equals, true)
+
+	// Titles at other angles are not stacked.
+	t.Title.Angle = math.Pi / 2
+	rads = radius(blk)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 90+h/2), check.Equals, true)
+
+	// Titles inside their ring are stacked inwards.
+	sc.Title = title("scores", rings.TickInside, stack)
+	blk.Title = title("blocks", rings.TickInside, stack)
+	stack.Reset()
+	rs := []rings.Titler{sc, blk}
+	stack.Include(rs...)
+	rads = radius(sc)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 40-h/2-h), check.Equals, true)
+	rads = radius(blk)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 80-h/2-h), check.Equals, true)
+	blk.Inner = 45
+	stack.Include(blk)
+	rads = radius(sc)
+	c.Assert(rads, check.HasLen, 1)
+	c.Check(near(rads[0], 45-h/2-h-h/2-h), check.Equals, true)
+}
+
+func (s *S) TestOptions(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	base := rings.NewGappedArcs(rings.Arc{0, rings.Complete}, []feat.Feature{chr}, 0)
+	sty := plotter.DefaultLineStyle
+	fill := color.Gray{0x7f}
+
+	b, err := rings.NewBlocks([]feat.Feature{chr}, base, 80, 90,
+		rings.BlockFill(fill),
+		rings.BlockLineStyle(sty),
+		rings.BlockArrows(0.1),
+		rings.BlockZooms(rings.Zoom{Location: chr, Start: 10, End: 20, Scale: 2}),
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(b.Color, check.Equals, color.Color(fill))
+	c.Check(b.LineStyle, check.DeepEquals, sty)
+	c.Check(b.Shape, check.Equals, rings.Arrow)
+	c.Check(b.HeadAngle, check.Equals, rings.Angle(0.1))
+	c.Check(b.Zooms, check.HasLen, 1)
+	_, err = rings.NewBlocks([]feat.Feature{chr}, base, 80, 90, rings.BlockZooms(
+		rings.Zoom{Location: chr, Start: 10, End: 20, Scale: 2},
+		rings.Zoom{Location: chr, Start: 15, End: 25, Scale: 2},
+	))
+	c.Check(err, check.ErrorMatches, "rings: overlapping zooms")
+	_, err = rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 90, 0, rings.BlockTitle(rings.Title{Side: 2}))
+	c.Check(err, check.ErrorMatches, "rings: unknown title side 2")
+
+	scorers := makeScorers(chr, 2, 1, func(i, _ int) float64 { return float64(i) })
+	tr := &rings.Trace{LineStyles: []draw.LineStyle{sty}}
+	rng := rings.NewRange()
+	sc, err := rings.NewScores(scorers, b, 40, 60, tr,
+		rings.ScoreMinMax(-1, 1),
+		rings.SharedRange(rng),
+		rings.ScoreBackground(fill),
+		rings.ScoreBands(rings.ScoreBand{Lo: 0, Hi: 1, Color: fill}),
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Range, check.Equals, rng)
+	c.Check(sc.Min == 0 && sc.Max == 0, check.Equals, true)
+	c.Check(sc.Background, check.Equals, color.Color(fill))
+	c.Check(sc.Bands, check.HasLen, 1)
+	for _, t := range []struct {
+		inner, outer vg.Length
+		renderer     rings.ScoreRenderer
+		opts         []rings.ScoreOption
+		err          string
+	}{
+		{inner: 60, outer: 40, renderer: tr, err: "rings: inner radius greater than outer radius"},
+		{inner: 40, outer: 60, err: "rings: nil score renderer"},
+		{inner: 40, outer: 60, renderer: tr, opts: []rings.ScoreOption{rings.ScoreMinMax(1, 1)}, err: "rings: score minimum 1 not less than maximum 1"},
+		{inner: 40, outer: 60, renderer: tr, opts: []rings.ScoreOption{rings.SharedRange(nil)}, err: "rings: nil shared range"},
+		{inner: 40, outer: 60, renderer: tr, opts: []rings.ScoreOption{rings.ScoreBands(rings.ScoreBand{Lo: 1, Hi: 0})}, err: "rings: score band low limit 1 greater than high limit 0"},
+	} {
+		_, err = rings.NewScores(scorers, b, t.inner, t.outer, t.renderer, t.opts...)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+
+	l, err := rings.NewLinks(nil, [2]rings.ArcOfer{b, b}, [2]vg.Length{40, 40},
+		rings.LinkLineStyle(sty),
+		rings.LinkBezier(&rings.Bezier{Segments: 5}),
+		rings.LinkEndGlyph(draw.GlyphStyle{Color: color.Black, Radius: 2}, 0.01),
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(l.LineStyle, check.DeepEquals, sty)
+	c.Check(l.Bezier.Segments, check.Equals, 5)
+	c.Check(l.EndGlyphTolerance, check.Equals, rings.Angle(0.01))
+	_, err = rings.NewLinks(nil, [2]rings.ArcOfer{b, b}, [2]vg.Length{40, 40}, rings.LinkEndGlyph(draw.GlyphStyle{}, -1))
+	c.Check(err, check.ErrorMatches, "rings: negative end glyph tolerance")
+
+	ts := draw.TextStyle{Color: color.Black}
+	lb, err := rings.NewLabelsWith(b, 100, rings.NameLabels(b.Set), rings.LabelTextStyle(ts), rings.LabelPlacement(rings.Radial))
+	c.Assert(err, check.Equals, nil)
+	c.Check(lb.Labels, check.HasLen, 1)
+	c.Check(lb.TextStyle, check.DeepEquals, ts)
+	c.Check(lb.Placement, check.NotNil)
+}
+
+func (s *S) TestTextBounds(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	sty := draw.TextStyle{Color: color.Black, Font: font}
+	lh := font.Extents().Height
+	near := func(a, b vg.Length) bool { return math.Abs(float64(a-b)) < 1e-9 }
+
+	w, h := rings.TextBounds(sty, "")
+	c.Check(w, check.Equals, vg.Length(0))
+	c.Check(h, check.Equals, vg.Length(0))
+	w, h = rings.TextBounds(sty, "chr1")
+	c.Check(w, check.Equals, font.Width("chr1"))
+	c.Check(h, check.Equals, lh)
+
+	// Multi-line strings are measured per line.
+	w, h = rings.TextBounds(sty, "chr1\nchromosome 22\n")
+	c.Check(w, check.Equals, font.Width("chromosome 22"))
+	c.Check(h, check.Equals, 2*lh)
+
+	w, h = rings.TextBounds(sty, "chr1")
+	box := rings.RotatedBounds(sty, "chr1", 0)
+	c.Check(box, check.DeepEquals, vg.Rectangle{Max: vg.Point{w, h}})
+	box = rings.RotatedBounds(sty, "chr1", math.Pi/2)
+	c.Check(near(box.Min.X, -h) && near(box.Max.X, 0), check.Equals, true)
+	c.Check(near(box.Min.Y, 0) && near(box.Max.Y, w), check.Equals, true)
+
+	// Labels glyph boxes include their measured text.
+	chr := &fs{start: 0, end: 100, name: "chromosome 1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 90, 0)
+	c.Assert(err, check.Equals, nil)
+	lb, err := rings.NewLabelsWith(b, 100, rings.NameLabels(b.Set),
+		rings.LabelTextStyle(sty),
+		rings.LabelPlacement(rings.Radial),
+	)
+	c.Assert(err, check.Equals, nil)
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	gb := lb.GlyphBoxes(p)[0].Rectangle
+	w, _ = rings.TextBounds(sty, chr.Name())
+	// The label is centred on the radial line at the middle of the arc at angle π.
+	c.Check(near(gb.Min.X, -100-w/2), check.Equals, true)
+	c.Check(near(gb.Max.X, 100), check.Equals, true)
+}
+
+func (s *S) TestDeterministicRendering(c *check.C) {
+	cen := vg.Point{150, 150}
+
+	var chrs []feat.Feature
+	for i := 0; i < 12; i++ {
+		chrs = append(chrs, &fs{start: 0, end: 100, name: fmt.Sprintf("chr%d", i+1)})
+	}
+	b, err := rings.NewGappedBlocks(chrs, rings.Arc{0, rings.Complete}, 110, 120, 0.01)
+	c.Assert(err, check.Equals, nil)
+
+	// Scorers are given in an order different from their locations.
+	var scorers []rings.Scorer
+	for _, i := range []int{7, 2, 11, 0, 5, 9, 1, 4, 10, 3, 8, 6} {
+		scorers = append(scorers, makeScorers(chrs[i].(*fs), 2, 1, func(j, _ int) float64 { return float64(j) })...)
+	}
+	grid := plotter.DefaultGridLineStyle
+	grid.Color = color.NRGBA{R: 0xff, A: 0x40}
+	sc, err := rings.NewScores(scorers, b, 60, 100, &rings.Trace{
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+		Axis: &rings.Axis{
+			Grid: grid,
+			Tick: rings.TickConfig{Marker: plot.ConstantTicks{{Value: 0.5}}},
+		},
+	}, rings.ScoreMinMax(0, 1), rings.ScoreBands(rings.ScoreBand{Lo: 0, Hi: 0.5, Color: color.NRGBA{B: 0xff, A: 0x40}}))
+	c.Assert(err, check.Equals, nil)
+
+	render := func() []interface{} {
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		return tc.actions
+	}
+	want := render()
+	for i := 0; i < 20; i++ {
+		c.Assert(render(), check.DeepEquals, want)
+	}
+
+	// Grid arcs are stroked in the order the locations of the scorers are first seen
+	// by the axis, which is the angular order of the scorers sorted by the Trace.
+	var thetas []rings.Angle
+	for _, a := range want {
+		st, ok := a.(stroke)
+		if !ok || len(st.path) != 2 || st.path[1].Type != vg.ArcComp || st.path[1].Radius != 80 {
+			continue
+		}
+		thetas = append(thetas, rings.Angle(st.path[1].Start))
+	}
+	c.Assert(thetas, check.HasLen, len(chrs))
+	for i, chr := range chrs {
+		arc, err := b.ArcOf(chr, nil)
+		c.Assert(err, check.Equals, nil)
+		c.Check(thetas[i], check.Equals, arc.Theta)
+	}
+
+	// Rendered images are byte identical.
+	p := goldenPlot(c, b, sc)
+	var first []byte
+	for i := 0; i < 5; i++ {
+		var buf bytes.Buffer
+		_, err := vgimg.PngCanvas{Canvas: renderCanvas(p)}.WriteTo(&buf)
+		c.Assert(err, check.Equals, nil)
+		if first == nil {
+			first = buf.Bytes()
+			continue
+		}
+		c.Check(bytes.Equal(buf.Bytes(), first), check.Equals, true)
+	}
+}
+
+func (s *S) TestHighlightFeatures(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b rings.Angle) bool {
+		return math.Abs(math.Remainder(float64(a-b), float64(rings.Complete))) < 1e-9
+	}
+
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+		&fs{start: 0, end: 100, name: "chr3"},
+		&fs{start: 0, end: 100, name: "chr4"},
+	}
+	gap := 0.01
+	for _, dir := range []rings.Angle{rings.CounterClockwise, rings.Clockwise} {
+		b, err := rings.NewGappedBlocks(chrs, rings.Arc{0, dir * rings.Complete}, 80, 90, gap)
+		c.Assert(err, check.Equals, nil)
+		arcOf := func(f feat.Feature) rings.Arc {
+			arc, err := b.ArcOf(f.Location(), f)
+			c.Assert(err, check.Equals, nil)
+			return arc
+		}
+
+		h := rings.NewHighlight(color.Gray{0x7f}, rings.Arc{}, 70, 100)
+		h.LocBase = b
+
+		// Adjacent features are joined into a single wedge.
+		genes := []feat.Feature{
+			&fs{start: 40, end: 60, location: chrs[1]},
+			&fs{start: 10, end: 40, location: chrs[1]},
+		}
+		h.LocFeatures = genes
+		arcs, err := h.Arcs()
+		c.Assert(err, check.Equals, nil)
+		c.Assert(arcs, check.HasLen, 1)
+		a, z := arcOf(genes[1]), arcOf(genes[0])
+		c.Check(near(arcs[0].Theta, a.Theta), check.Equals, true)
+		c.Check(near(arcs[0].Phi, z.Theta+z.Phi-a.Theta), check.Equals, true)
+
+		// Separated chromosomes form separate wedges unless the gap is joined.
+		h.LocFeatures = []feat.Feature{chrs[3], chrs[2]}
+		_, err = h.Arcs()
+		c.Check(err, check.ErrorMatches, "rings: highlight features form 2 separate wedges")
+		c.Check(h.Validate(), check.ErrorMatches, "rings: highlight features form 2 separate wedges")
+		c.Check(func() { h.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), cen) }, check.PanicMatches, "rings: highlight features form 2 separate wedges")
+
+		h.AllowSplit = true
+		arcs, err = h.Arcs()
+		c.Assert(err, check.Equals, nil)
+		c.Assert(arcs, check.HasLen, 2)
+		c.Check(near(arcs[0].Theta, arcOf(chrs[2]).Theta), check.Equals, true)
+		c.Check(near(arcs[1].Theta, arcOf(chrs[3]).Theta), check.Equals, true)
+		tc := &canvas{dpi: defaultDPI}
+		h.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var fills int
+		for _, a := range tc.actions {
+			if _, ok := a.(fill); ok {
+				fills++
+			}
+		}
+		c.Check(fills, check.Equals, 2)
+
+		h.AllowSplit = false
+		h.JoinGap = rings.Angle(gap * 2 * math.Pi)
+		arcs, err = h.Arcs()
+		c.Assert(err, check.Equals, nil)
+		c.Assert(arcs, check.HasLen, 1)
+		a, z = arcOf(chrs[2]), arcOf(chrs[3])
+		c.Check(near(arcs[0].Theta, a.Theta), check.Equals, true)
+		c.Check(near(arcs[0].Phi, z.Theta+z.Phi-a.Theta), check.Equals, true)
+		c.Check(near(h.Arc().Theta, arcs[0].Theta) && near(h.Arc().Phi, arcs[0].Phi), check.Equals, true)
+
+		// An explicit arc is used in preference to features.
+		h.Base = rings.Arc{1, 0.5}
+		arcs, err = h.Arcs()
+		c.Assert(err, check.Equals, nil)
+		c.Check(arcs, check.DeepEquals, []rings.Arc{{1, 0.5}})
+	}
+}
+
+func (s *S) TestLinksClip(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	cen := vg.Point{150, 150}
+	// subpaths returns the subpaths of the rendered links as distances of their
+	// points from cen.
+	subpaths := func(l *rings.Links) [][]vg.Length {
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var d [][]vg.Length
+		for _, a := range tc.actions {
+			if a, ok := a.(stroke); ok {
+				for _, p := range a.path {
+					if p.Type == vg.MoveComp {
+						d = append(d, nil)
+					}
+					_, r := rings.Polar(p.Pos.Sub(cen))
+					d[len(d)-1] = append(d[len(d)-1], r)
+				}
+			}
+		}
+		return d
+	}
+	near := func(a, b vg.Length) bool { return math.Abs(float64(a-b)) < 1e-6 }
+
+	// Links between close features bulge past their end radius.
+	bulge := []rings.Pair{fp{
+		feats: [2]*fs{
+			{start: 10, end: 20, location: chr[0], style: plotter.DefaultLineStyle},
+			{start: 30, end: 40, location: chr[0], style: plotter.DefaultLineStyle},
+		},
+		sty: plotter.DefaultLineStyle,
+	}}
+	l, err := rings.NewLinks(bulge, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70},
+		rings.LinkBezier(&rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 120}}),
+	)
+	c.Assert(err, check.Equals, nil)
+	d := subpaths(l)
+	c.Assert(d, check.HasLen, 1)
+	var max vg.Length
+	for _, r := range d[0] {
+		if r > max {
+			max = r
+		}
+	}
+	c.Check(max > 80, check.Equals, true)
+
+	c.Check(rings.LinkClip(0, 80)(l), check.Equals, nil)
+	c.Check(l.Validate(), check.Equals, nil)
+	d = subpaths(l)
+	c.Assert(d, check.HasLen, 2)
+	for i, sub := range d {
+		c.Check(sub, check.HasLen, 21)
+		for _, r := range sub {
+			c.Check(r <= 80+1e-6, check.Equals, true, check.Commentf("subpath %d radius %v", i, r))
+		}
+	}
+	// Each clipped section runs from a link end to the boundary.
+	c.Check(near(d[0][0], 70), check.Equals, true)
+	c.Check(near(d[0][20], 80), check.Equals, true)
+	c.Check(near(d[1][0], 80), check.Equals, true)
+	c.Check(near(d[1][20], 70), check.Equals, true)
+
+	// Straight links crossing the center are split by the inner boundary.
+	across := []rings.Pair{fp{
+		feats: [2]*fs{
+			{start: 10, end: 20, location: chr[0], style: plotter.DefaultLineStyle},
+			{start: 10, end: 20, location: chr[1], style: plotter.DefaultLineStyle},
+		},
+		sty: plotter.DefaultLineStyle,
+	}}
+	l, err = rings.NewLinks(across, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkClip(30, 0))
+	c.Assert(err, check.Equals, nil)
+	d = subpaths(l)
+	c.Assert(d, check.HasLen, 2)
+	c.Check(near(d[0][0], 70), check.Equals, true)
+	c.Check(near(d[0][1], 30), check.Equals, true)
+	c.Check(near(d[1][0], 30), check.Equals, true)
+	c.Check(near(d[1][1], 70), check.Equals, true)
+
+	// Links entirely outside the annulus are not drawn.
+	l.ClipInner, l.ClipOuter = 75, 90
+	c.Check(subpaths(l), check.HasLen, 0)
+
+	l.ClipInner, l.ClipOuter = 90, 80
+	c.Check(l.Validate(), check.ErrorMatches, `(?s).*clip inner radius 90 greater than clip outer radius 80.*`)
+	_, err = rings.NewLinks(across, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkClip(-1, 0))
+	c.Check(err, check.ErrorMatches, "rings: negative clip radius")
+}
+
+type richLabel []rings.StyledString
+
+func (l richLabel) Label() string {
+	var s string
+	for _, r := range l {
+		s += r.Text
+	}
+	return s
+}
+func (l richLabel) RichLabel() []rings.StyledString { return l }
+
+func (s *S) TestMultiLineLabels(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	sty := draw.TextStyle{Color: color.Black, Font: font}
+	ext := font.Extents()
+	cen := vg.Point{150, 150}
+	arc := rings.Arc{0, rings.Complete / 2}
+
+	// strings returns the strings filled by rendering l.
+	strings := func(l rings.Labeler, place rings.TextPlacement) []fillString {
+		lb, err := rings.NewLabelsWith(arc, 50, []rings.Labeler{l}, rings.LabelTextStyle(sty), rings.LabelPlacement(place))
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		lb.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var fs []fillString
+		for _, a := range tc.actions {
+			if a, ok := a.(fillString); ok {
+				fs = append(fs, a)
+			}
+		}
+		return fs
+	}
+	near := func(a, b vg.Length) bool { return math.Abs(float64(a-b)) < 1e-9 }
+	pt := vg.Point{150, 200}
+
+	// A right aligned, vertically centered two line label.
+	fs := strings(rings.Label("a\nbbb\n"), func(rings.Angle) (rings.Angle, float64, float64) { return 0, -1, -0.5 })
+	c.Assert(fs, check.HasLen, 2)
+	for i, want := range []struct {
+		str string
+		y   vg.Length
+	}{
+		{str: "a", y: pt.Y},
+		{str: "bbb", y: pt.Y - ext.Height},
+	} {
+		c.Check(fs[i].str, check.Equals, want.str)
+		c.Check(near(fs[i].x, pt.X-font.Width(want.str)), check.Equals, true, check.Commentf("line %d x %v", i, fs[i].x))
+		c.Check(near(fs[i].y, want.y-ext.Ascent+font.Size), check.Equals, true, check.Commentf("line %d y %v", i, fs[i].y))
+	}
+
+	// Rich labels are rendered as sequential runs on a single baseline.
+	italic, err := vg.MakeFont("Helvetica-Oblique", 12)
+	c.Assert(err, check.Equals, nil)
+	rl := richLabel{
+		{Text: "BRCA1", TextStyle: draw.TextStyle{Color: color.Black, Font: italic}},
+		{Text: " exon 2"},
+	}
+	fs = strings(rl, func(rings.Angle) (rings.Angle, float64, float64) { return 0, 0, 0 })
+	c.Assert(fs, check.HasLen, 2)
+	c.Check(fs[0].str, check.Equals, "BRCA1")
+	c.Check(fs[0].font, check.Equals, "Helvetica-Oblique")
+	c.Check(fs[1].str, check.Equals, " exon 2")
+	c.Check(fs[1].font, check.Equals, "Helvetica")
+	c.Check(near(fs[0].x, pt.X), check.Equals, true)
+	c.Check(near(fs[1].x, pt.X+italic.Width("BRCA1")), check.Equals, true)
+	c.Check(fs[0].y, check.Equals, fs[1].y)
+	c.Check(fs[0].y > pt.Y, check.Equals, true)
+
+	w, h := rings.RichBounds(rl)
+	c.Check(near(w, italic.Width("BRCA1")), check.Equals, true, check.Commentf("runs without a font are not measured"))
+	c.Check(near(h, italic.Extents().Height), check.Equals, true)
+}
+
+// windRose is a ScoreRenderer implemented outside the rings package. It renders the
+// first score of each Scorer as a wedge from the inner radius that is half the width
+// of the Scorer's arc.
+type windRose struct {
+	ctx   rings.ScoreContext
+	fill  color.Color
+	count int
+}
+
+var _ rings.ScoreRenderer = (*windRose)(nil)
+
+func (w *windRose) Configure(ctx rings.ScoreContext) { w.ctx = ctx }
+func (w *windRose) Render(arc rings.Arc, scorer rings.Scorer) {
+	rad, ok := w.ctx.Radius(scorer.Scores()[0])
+	if !ok {
+		return
+	}
+	var pa vg.Path
+	rings.Sector{
+		Center: w.ctx.Center,
+		Inner:  w.ctx.Inner,
+		Outer:  rad,
+		Arc:    rings.Arc{Theta: arc.Theta + arc.Phi/4, Phi: arc.Phi / 2},
+	}.Path(&pa, false)
+	w.ctx.Canvas.SetColor(w.fill)
+	w.ctx.Canvas.Fill(pa)
+	w.count++
+}
+func (w *windRose) Close() {}
+
+func (s *S) TestExternalScoreRenderer(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	scorers := makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) })
+	// A score outside the explicit range is not rendered.
+	scorers[3].(*fs).scores[0] = 10
+
+	wr := &windRose{fill: color.Black}
+	sc, err := rings.NewScores(scorers, b, 20, 70, wr, rings.ScoreMinMax(0, 4))
+	c.Assert(err, check.Equals, nil)
+
+	cen := vg.Point{150, 150}
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	c.Check(wr.count, check.Equals, 3)
+	c.Check(wr.ctx.Center, check.Equals, cen)
+	c.Check(wr.ctx.Inner, check.Equals, vg.Length(20))
+	c.Check(wr.ctx.Outer, check.Equals, vg.Length(70))
+	c.Check(wr.ctx.Min, check.Equals, 0.)
+	c.Check(wr.ctx.Max, check.Equals, 4.)
+	c.Check(wr.ctx.Base, check.Equals, rings.ArcOfer(b))
+
+	var fills int
+	for _, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills++
+		}
+	}
+	c.Check(fills, check.Equals, 3)
+
+	for _, t := range []struct {
+		v   float64
+		rad vg.Length
+		ok  bool
+	}{
+		{v: 0, rad: 20, ok: true},
+		{v: 2, rad: 45, ok: true},
+		{v: 4, rad: 70, ok: true},
+		{v: 10, rad: 70, ok: false},
+		{v: -1, rad: 20, ok: false},
+	} {
+		rad, ok := wr.ctx.Radius(t.v)
+		c.Check(rad, check.Equals, t.rad, check.Commentf("value %v", t.v))
+		c.Check(ok, check.Equals, t.ok, check.Commentf("value %v", t.v))
+	}
+}
+
+func (s *S) TestHeatRamp(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	f := ramp.Greys()
+	h := &rings.Heat{Ramp: f, Overflow: color.NRGBA{R: 0xff, A: 0xff}}
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 60, h, rings.ScoreMinMax(0, 2))
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var cols []color.Color
+	for _, a := range tc.actions {
+		if a, ok := a.(setColor); ok {
+			cols = append(cols, a.col)
+		}
+	}
+	c.Check(cols, check.DeepEquals, []color.Color{f(0), f(0.5), f(1), h.Overflow})
+	c.Check(ramp.Discretize(f, 3), check.DeepEquals, []color.Color{f(0), f(0.5), f(1)})
+}
+
+func (s *S) TestLinksDensity(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	link := func(s0, s1 int) fp {
+		return fp{
+			feats: [2]*fs{
+				{start: s0, end: s0 + 10, location: chr[0], style: plotter.DefaultLineStyle},
+				{start: s1, end: s1 + 10, location: chr[1], style: plotter.DefaultLineStyle},
+			},
+			sty: plotter.DefaultLineStyle,
+		}
+	}
+	pairs := []rings.Pair{link(10, 10), link(10, 10), link(10, 10), link(30, 70)}
+	f := ramp.Greys()
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	l.Density = &rings.DensityOptions{DPI: 144, Radius: 1, Ramp: f}
+
+	cen := vg.Point{150, 150}
+	// at returns the point the fraction t along the link between s0 and s1.
+	at := func(s0, s1 int, t float64) vg.Point {
+		p := link(s0, s1)
+		var ends [2]vg.Point
+		for i, f := range p.Features() {
+			arc, err := b.ArcOf(f.Location(), f)
+			c.Assert(err, check.Equals, nil)
+			ends[i] = rings.RectangularAt(cen, rings.Normalize(arc.Theta), 70)
+		}
+		return ends[0].Add(ends[1].Sub(ends[0]).Scale(vg.Length(t)))
+	}
+	// render returns the kinds of the rendering actions and the density image.
+	render := func() ([]string, drawImage) {
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var (
+			kinds []string
+			img   drawImage
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				kinds = append(kinds, "stroke")
+			case drawImage:
+				kinds = append(kinds, "image")
+				img = a
+			}
+		}
+		return kinds, img
+	}
+	// pixel returns the color of the density image at p.
+	pixel := func(img drawImage, p vg.Point) color.NRGBA {
+		scale := 144.0 / 72
+		x := int(float64(p.X-img.rect.Min.X) * scale)
+		y := int(float64(img.rect.Max.Y-p.Y) * scale)
+		return color.NRGBAModel.Convert(img.img.At(x, y)).(color.NRGBA)
+	}
+	nrgba := func(c color.Color) color.NRGBA { return color.NRGBAModel.Convert(c).(color.NRGBA) }
+
+	kinds, img := render()
+	c.Check(kinds, check.DeepEquals, []string{"image"})
+	c.Assert(img.img, check.NotNil)
+	// The image covers the links.
+	for _, p := range []vg.Point{at(10, 10, 0), at(10, 10, 1), at(30, 70, 0), at(30, 70, 1)} {
+		c.Check(img.rect.Min.X < p.X && p.X < img.rect.Max.X, check.Equals, true)
+		c.Check(img.rect.Min.Y < p.Y && p.Y < img.rect.Max.Y, check.Equals, true)
+	}
+	// Overplotted links are denser than a single link. The densest pixels
+	// are where the single link crosses the overplotted links.
+	c.Check(pixel(img, at(10, 10, 0.25)), check.Equals, nrgba(f(3./4)))
+	c.Check(pixel(img, at(10, 10, 0.75)), check.Equals, nrgba(f(3./4)))
+	c.Check(pixel(img, at(30, 70, 0.25)), check.Equals, nrgba(f(1./4)))
+	// Pixels no link passes through are transparent.
+	c.Check(pixel(img, img.rect.Min.Add(vg.Point{1, 1})).A, check.Equals, uint8(0))
+
+	l.Density.Blend = rings.DensityUnder
+	kinds, _ = render()
+	c.Check(kinds, check.DeepEquals, []string{"image", "stroke", "stroke", "stroke", "stroke"})
+	l.Density.Blend = rings.DensityOver
+	kinds, _ = render()
+	c.Check(kinds, check.DeepEquals, []string{"stroke", "stroke", "stroke", "stroke", "image"})
+
+	c.Check(l.Validate(), check.Equals, nil)
+	l.Density.Radius = -1
+	c.Check(l.Validate(), check.ErrorMatches, `(?s).*negative density stamp radius -1.*`)
+}
+
+func (s *S) TestStrokeStyle(c *check.C) {
+	sty := plotter.DefaultLineStyle
+	sty.Width = 4
+
+	var corner vg.Path
+	corner.Move(vg.Point{0, 0})
+	corner.Line(vg.Point{10, 0})
+	corner.Line(vg.Point{10, 10})
+
+	// arcs returns the centres and angles of the round caps and joins in pa.
+	type arc struct {
+		cen   vg.Point
+		angle float64
+	}
+	arcs := func(pa vg.Path) []arc {
+		var a []arc
+		for _, p := range pa {
+			if p.Type == vg.ArcComp {
+				c.Check(p.Radius, check.Equals, sty.Width/2)
+				a = append(a, arc{cen: p.Pos, angle: p.Angle})
+			}
+		}
+		return a
+	}
+	// render returns the stroked and filled paths of pa stroked with ss.
+	render := func(ss rings.StrokeStyle, pa vg.Path) (strokes, fills []vg.Path) {
+		tc := &canvas{dpi: defaultDPI}
+		ss.Stroke(draw.NewCanvas(tc, 300, 300), pa)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				strokes = append(strokes, a.path)
+			case fill:
+				fills = append(fills, a.path)
+			}
+		}
+		return strokes, fills
+	}
+
+	// Lines without round caps or joins are stroked by the canvas.
+	strokes, fills := render(rings.StrokeStyle{LineStyle: sty}, corner)
+	c.Check(strokes, check.DeepEquals, []vg.Path{corner})
+	c.Check(fills, check.HasLen, 0)
+
+	for i, t := range []struct {
+		sty      rings.StrokeStyle
+		path     vg.Path
+		wantArcs []arc
+	}{
+		{
+			// The outer side of the corner is rounded.
+			sty:      rings.StrokeStyle{LineStyle: sty, Join: rings.RoundJoin},
+			path:     corner,
+			wantArcs: []arc{{cen: vg.Point{10, 0}, angle: -math.Pi / 2}},
+		},
+		{
+			// The corner does not turn by more than the threshold.
+			sty:  rings.StrokeStyle{LineStyle: sty, Join: rings.RoundJoin, JoinThreshold: math.Pi / 2},
+			path: corner,
+		},
+		{
+			sty:      rings.StrokeStyle{LineStyle: sty, Cap: rings.RoundCap},
+			path:     corner,
+			wantArcs: []arc{{cen: vg.Point{10, 10}, angle: -math.Pi}, {cen: vg.Point{0, 0}, angle: -math.Pi}},
+		},
+		{
+			// A radial line joined to an arc turns only where they meet.
+			sty: rings.StrokeStyle{LineStyle: sty, Join: rings.RoundJoin, Cap: rings.RoundCap},
+			path: vg.Path{
+				{Type: vg.MoveComp, Pos: vg.Point{5, 0}},
+				{Type: vg.LineComp, Pos: vg.Point{10, 0}},
+				{Type: vg.ArcComp, Pos: vg.Point{0, 0}, Radius: 10, Start: 0, Angle: math.Pi / 2},
+			},
+			wantArcs: []arc{
+				{cen: rings.Rectangular(math.Pi/2, 10), angle: -math.Pi},
+				{cen: vg.Point{10, 0}, angle: -math.Pi / 2},
+				{cen: vg.Point{5, 0}, angle: -math.Pi},
+			},
+		},
+	} {
+		strokes, fills := render(t.sty, t.path)
+		c.Check(strokes, check.HasLen, 0, check.Commentf("Test %d", i))
+		// The line is filled once as a single outline so that translucent
+		// lines are not composited twice at their caps and joins.
+		c.Assert(fills, check.HasLen, 1, check.Commentf("Test %d", i))
+		got := arcs(fills[0])
+		c.Assert(got, check.HasLen, len(t.wantArcs), check.Commentf("Test %d", i))
+		for j, a := range got {
+			want := t.wantArcs[j]
+			c.Check(math.Hypot(float64(a.cen.X-want.cen.X), float64(a.cen.Y-want.cen.Y)) < 1e-9, check.Equals, true,
+				check.Commentf("Test %d arc %d centre %v", i, j, a.cen))
+			// Arcs are approximated by chords of at most a degree, so a join
+			// onto an arc turns to the direction of the first chord.
+			c.Check(math.Abs(a.angle-want.angle) <= math.Pi/180, check.Equals, true, check.Commentf("Test %d arc %d angle %v", i, j, a.angle))
+		}
+	}
+
+	// A mitred corner is outlined by the offsets of its segments.
+	_, fills = render(rings.StrokeStyle{LineStyle: sty, Join: rings.RoundJoin, JoinThreshold: math.Pi / 2}, corner)
+	c.Check(fills, check.DeepEquals, []vg.Path{{
+		{Type: vg.MoveComp, Pos: vg.Point{0, 2}},
+		{Type: vg.LineComp, Pos: vg.Point{8, 2}},
+		{Type: vg.LineComp, Pos: vg.Point{8, 10}},
+		{Type: vg.LineComp, Pos: vg.Point{12, 10}},
+		{Type: vg.LineComp, Pos: vg.Point{12, -2}},
+		{Type: vg.LineComp, Pos: vg.Point{0, -2}},
+		{Type: vg.CloseComp},
+	}})
+
+	// Dashed lines are outlined dash by dash.
+	dashed := sty
+	dashed.Dashes = []vg.Length{4, 2}
+	_, fills = render(rings.StrokeStyle{LineStyle: dashed, Cap: rings.RoundCap}, corner)
+	c.Check(fills, check.HasLen, 4)
+
+	// Lines with no color are not rendered.
+	strokes, fills = render(rings.StrokeStyle{Cap: rings.RoundCap}, corner)
+	c.Check(strokes, check.HasLen, 0)
+	c.Check(fills, check.HasLen, 0)
+
+	// Joined traces are given round joins.
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	tr := &rings.Trace{LineStyles: []draw.LineStyle{sty}, Join: true, Joins: rings.RoundJoin}
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i % 2) }), b, 40, 60, tr)
+	c.Assert(err, check.Equals, nil)
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var n int
+	for _, a := range tc.actions {
+		if a, ok := a.(fill); ok {
+			n += len(arcs(a.path))
+		}
+	}
+	// Each of the three joins turns from its radial line onto the arc of
+	// the following feature.
+	c.Check(n, check.Equals, 3)
+}
+
+func (s *S) TestFacet(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	samples := []string{"low", "high", "mid"}
+	rng := rings.NewRange()
+	renderers := make(map[string]*windRose)
+	f := &rings.Facet{
+		Samples: samples,
+		Plotters: func(sample string) ([]plot.Plotter, error) {
+			b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+			if err != nil {
+				return nil, err
+			}
+			scale := map[string]float64{"low": 1, "mid": 5, "high": 10}[sample]
+			wr := &windRose{fill: color.Black}
+			renderers[sample] = wr
+			sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) * scale }), b, 20, 70, wr, rings.SharedRange(rng))
+			if err != nil {
+				return nil, err
+			}
+			return []plot.Plotter{b, sc}, nil
+		},
+		Cols:       2,
+		Padding:    5,
+		TitleStyle: draw.TextStyle{Color: color.Black, Font: font},
+	}
+
+	tc := &canvas{dpi: defaultDPI}
+	c.Assert(f.Draw(draw.NewCanvas(tc, 600, 600)), check.Equals, nil)
+
+	// Every facet is rendered on the shared range of all the facets.
+	for _, s := range samples {
+		wr := renderers[s]
+		c.Check(wr.ctx.Min, check.Equals, 0., check.Commentf("sample %s", s))
+		c.Check(wr.ctx.Max, check.Equals, 30., check.Commentf("sample %s", s))
+	}
+
+	// Facets are placed in row-major order in a grid with two columns.
+	low, high, mid := renderers["low"].ctx.Center, renderers["high"].ctx.Center, renderers["mid"].ctx.Center
+	c.Check(low.X < 300 && low.Y > 300, check.Equals, true, check.Commentf("low at %v", low))
+	c.Check(high.X > 300 && high.Y > 300, check.Equals, true, check.Commentf("high at %v", high))
+	c.Check(mid.X < 300 && mid.Y < 300, check.Equals, true, check.Commentf("mid at %v", mid))
+
+	var titles []string
+	for _, a := range tc.actions {
+		if a, ok := a.(fillString); ok {
+			titles = append(titles, a.str)
+		}
+	}
+	c.Check(titles, check.DeepEquals, samples)
+
+	f.Rows, f.Cols = 1, 2
+	c.Check(f.Draw(draw.NewCanvas(tc, 600, 600)), check.ErrorMatches, `rings: 3 samples do not fit in a 1x2 facet grid`)
+}
+
+// plain is a plot.Plotter that is not a rings.Layerer.
+type plain struct{ name string }
+
+func (plain) Plot(draw.Canvas, *plot.Plot) {}
+
+func (s *S) TestLayered(c *check.C) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	green := color.NRGBA{G: 0xff, A: 0xff}
+	blue := color.NRGBA{B: 0xff, A: 0xff}
+
+	label := rings.NewAnnulus(green, 110, 120)
+	label.Layer = rings.LabelLayer
+	data := rings.NewAnnulus(blue, 80, 100)
+	first := plain{"first"}
+	back := rings.NewHighlight(red, rings.Arc{0, rings.Complete}, 0, 120)
+	back.Layer = rings.BackgroundLayer
+	data2 := rings.NewAnnulus(blue, 60, 70)
+	last := plain{"last"}
+
+	c.Check(data.DrawLayer(), check.Equals, rings.DataLayer)
+
+	l := rings.NewLayered(label, data, first, back)
+	l.Add(data2, last)
+	c.Check(l.Sorted(), check.DeepEquals, []plot.Plotter{back, data, first, data2, label, last})
+
+	// Rendering order follows the layers.
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	p.Add(l)
+	p.HideAxes()
+	tc := &canvas{dpi: defaultDPI}
+	p.Draw(draw.NewCanvas(tc, 300, 300))
+	var colors []color.Color
+	for _, a := range tc.actions {
+		if a, ok := a.(setColor); ok && (a.col == red || a.col == green || a.col == blue) {
+			colors = append(colors, a.col)
+		}
+	}
+	c.Check(colors, check.DeepEquals, []color.Color{red, blue, blue, green})
+}
+
+func (s *S) TestCrisp(c *check.C) {
+	black := func(img imgdraw.Image) (n int) {
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if color.NRGBAModel.Convert(img.At(x, y)) == (color.NRGBA{A: 0xff}) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	// The center and radii lie between pixels.
+	cen := vg.Point{X: 50.3, Y: 50.4}
+	for _, dpi := range []float64{72, 144} {
+		// Hairlines one pixel wide.
+		sty := draw.LineStyle{Color: color.Black, Width: vg.Length(72 / dpi)}
+		var counts [2]int
+		for i, crisp := range []bool{false, true} {
+			g := rings.NewPolarGrid(sty, 10.3, 40.3, 1, 4)
+			g.Crisp = crisp
+			cv := vgimg.NewWith(vgimg.UseWH(100, 100), vgimg.UseDPI(int(dpi)))
+			g.DrawAt(draw.New(cv), cen)
+			counts[i] = black(cv.Image())
+		}
+		// Snapped spokes fill whole pixels along their length, apart from
+		// their butt ends which end at pixel centers.
+		c.Check(counts[0] < 10, check.Equals, true, check.Commentf("dpi %v unsnapped opaque pixels %d", dpi, counts[0]))
+		c.Check(counts[1] >= 4*(30*int(dpi/72)-1), check.Equals, true, check.Commentf("dpi %v snapped opaque pixels %d", dpi, counts[1]))
+	}
+
+	// Vector canvases are not snapped.
+	sty := draw.LineStyle{Color: color.Black, Width: 1}
+	var actions [2][]interface{}
+	for i, crisp := range []bool{false, true} {
+		g := rings.NewPolarGrid(sty, 10.3, 40.3, 1, 4)
+		g.Crisp = crisp
+		tc := &canvas{dpi: defaultDPI}
+		g.DrawAt(draw.NewCanvas(tc, 100, 100), cen)
+		actions[i] = tc.actions
+	}
+	c.Check(actions[1], check.DeepEquals, actions[0])
+}
+
+// roundedBorder returns n points along each side and each corner of the border
+// of the box b with corners of radius r.
+func roundedBorder(b vg.Rectangle, r vg.Length, n int) []vg.Point {
+	in := vg.Rectangle{
+		Min: vg.Point{X: b.Min.X + r, Y: b.Min.Y + r},
+		Max: vg.Point{X: b.Max.X - r, Y: b.Max.Y - r},
+	}
+	corners := []vg.Point{in.Min, {X: in.Max.X, Y: in.Min.Y}, in.Max, {X: in.Min.X, Y: in.Max.Y}}
+	var pts []vg.Point
+	for i, p := range corners {
+		q := corners[(i+1)%4]
+		// The side from corner p to corner q lies outward of the inset box.
+		out := rings.Rectangular(rings.Angle(float64(i)*math.Pi/2-math.Pi/2), r)
+		for k := 0; k <= n; k++ {
+			f := vg.Length(k) / vg.Length(n)
+			pts = append(pts,
+				vg.Point{X: p.X + (q.X-p.X)*f, Y: p.Y + (q.Y-p.Y)*f}.Add(out),
+				rings.RectangularAt(q, rings.Angle(float64(i)*math.Pi/2-math.Pi/2+float64(f)*math.Pi/2), r),
+			)
+		}
+	}
+	return pts
+}
+
+func (s *S) TestCallouts(c *check.C) {
+	cen := vg.Point{200, 200}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	feats := []*fs{
+		{start: 10, end: 12, location: chr, name: "a"},
+		{start: 12, end: 14, location: chr, name: "b"},
+		{start: 60, end: 62, location: chr, name: "c"},
+	}
+	co := rings.NewCallouts([]rings.Callout{
+		{Feature: feats[0], Text: "gene a"},
+		{Feature: feats[1], Text: "gene b\nsecond line"},
+		{Feature: feats[2], Text: "gene c"},
+	}, b, 100, 110)
+	co.Box = rings.BoxStyle{
+		Color:     color.White,
+		LineStyle: plotter.DefaultLineStyle,
+		Radius:    3,
+		Padding:   2,
+	}
+	co.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	co.LeaderStyle = plotter.DefaultLineStyle
+	co.Spread = math.Pi / 4
+	co.Push = 40
+	c.Check(co.Validate(), check.Equals, nil)
+
+	boxes, err := co.Boxes()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(boxes, check.HasLen, 3)
+
+	// Boxes do not overlap and lie outside the callout radius.
+	for i, bi := range boxes {
+		for _, bj := range boxes[i+1:] {
+			overlap := bi.Min.X < bj.Max.X && bj.Min.X < bi.Max.X && bi.Min.Y < bj.Max.Y && bj.Min.Y < bi.Max.Y
+			c.Check(overlap, check.Equals, false, check.Commentf("boxes %v and %v overlap", bi, bj))
+		}
+		nearest := vg.Point{
+			X: vg.Length(math.Max(float64(bi.Min.X), math.Min(0, float64(bi.Max.X)))),
+			Y: vg.Length(math.Max(float64(bi.Min.Y), math.Min(0, float64(bi.Max.Y)))),
+		}
+		d := math.Hypot(float64(nearest.X), float64(nearest.Y))
+		c.Check(d >= 110-1e-9, check.Equals, true, check.Commentf("box %d at distance %v", i, d))
+	}
+
+	// An isolated callout is not moved: its center is on the ray through its
+	// feature and its nearest point is at the callout radius.
+	arc, err := b.ArcOf(chr, feats[2])
+	c.Assert(err, check.Equals, nil)
+	mid := arc.Theta + arc.Phi/2
+	box := boxes[2]
+	center := vg.Point{X: (box.Min.X + box.Max.X) / 2, Y: (box.Min.Y + box.Max.Y) / 2}
+	theta, _ := rings.Polar(center)
+	c.Check(math.Abs(math.Remainder(float64(theta-mid), 2*math.Pi)) < 1e-9, check.Equals, true)
+	nearest := vg.Point{
+		X: vg.Length(math.Max(float64(box.Min.X), math.Min(0, float64(box.Max.X)))),
+		Y: vg.Length(math.Max(float64(box.Min.Y), math.Min(0, float64(box.Max.Y)))),
+	}
+	c.Check(math.Abs(math.Hypot(float64(nearest.X), float64(nearest.Y))-110) < 1e-9, check.Equals, true)
+
+	// Leader lines run from the features to the nearest point of the box borders.
+	tc := &canvas{dpi: defaultDPI}
+	co.DrawAt(draw.NewCanvas(tc, 400, 400), cen)
+	var (
+		leaders []vg.Path
+		texts   []string
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case stroke:
+			if len(a.path) == 2 {
+				leaders = append(leaders, a.path)
+			}
+		case fillString:
+			texts = append(texts, a.str)
+		}
+	}
+	c.Check(texts, check.DeepEquals, []string{"gene a", "gene b", "second line", "gene c"})
+	c.Assert(leaders, check.HasLen, 3)
+	for i, l := range leaders {
+		f := feats[i]
+		arc, err := b.ArcOf(chr, f)
+		c.Assert(err, check.Equals, nil)
+		from := rings.RectangularAt(cen, arc.Theta+arc.Phi/2, 100)
+		c.Check(math.Hypot(float64(l[0].Pos.X-from.X), float64(l[0].Pos.Y-from.Y)) < 1e-9, check.Equals, true)
+
+		// The leader end is on the box border and no point of the border is
+		// nearer to the feature.
+		box := vg.Rectangle{Min: cen.Add(boxes[i].Min), Max: cen.Add(boxes[i].Max)}
+		end := l[1].Pos
+		d := math.Hypot(float64(end.X-from.X), float64(end.Y-from.Y))
+		for _, p := range roundedBorder(box, 3, 100) {
+			c.Check(math.Hypot(float64(p.X-from.X), float64(p.Y-from.Y)) >= d-1e-9, check.Equals, true,
+				check.Commentf("leader %d: border point %v nearer than %v", i, p, end))
+		}
+		c.Check(end.X >= box.Min.X-1e-9 && end.X <= box.Max.X+1e-9 && end.Y >= box.Min.Y-1e-9 && end.Y <= box.Max.Y+1e-9, check.Equals, true)
+	}
+
+	co.Radius = 90
+	co.Push = -1
+	c.Check(co.Validate(), check.ErrorMatches, `rings: callout radius 90 less than anchor radius 100; rings: negative callout push -1`)
+}
+
+// colorFeature is a feature with a fill color.
+type colorFeature struct {
+	*fs
+	fill color.Color
+}
+
+func (f colorFeature) FillColor() color.Color { return f.fill }
+
+func (s *S) TestFillRule(c *check.C) {
+	const size = 240
+	cen := vg.Point{size / 2, size / 2}
+	translucent := color.NRGBA{R: 0xff, A: 0x80}
+	over := func(dst color.NRGBA, src color.NRGBA) color.NRGBA {
+		a := float64(src.A) / 0xff
+		blend := func(d, s uint8) uint8 { return uint8(math.Floor(float64(s)*a + float64(d)*(1-a) + 0.5)) }
+		return color.NRGBA{R: blend(dst.R, src.R), G: blend(dst.G, src.G), B: blend(dst.B, src.B), A: 0xff}
+	}
+	white := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	near := func(a, b color.NRGBA) bool {
+		d := func(x, y uint8) bool { return math.Abs(float64(x)-float64(y)) <= 3 }
+		return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+	}
+
+	chr := &fs{start: 0, end: 360, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 100, 110, 0)
+	c.Assert(err, check.Equals, nil)
+
+	// The ends of the ribbon overlap between 45° and 90°, so its outline encloses
+	// the circular segment between those angles twice.
+	ribbon := func(rule rings.FillRule) *rings.Ribbons {
+		r, err := rings.NewRibbons([]rings.Pair{vp{feats: [2]feat.Feature{
+			&fs{start: 0, end: 90, location: chr},
+			&fs{start: 45, end: 135, location: chr},
+		}}}, [2]rings.ArcOfer{b, b}, [2]vg.Length{100, 100})
+		c.Assert(err, check.Equals, nil)
+		r.Twist = rings.Flat
+		r.Color = translucent
+		r.FillRule = rule
+		return r
+	}
+	var (
+		twice = rings.RectangularAt(cen, 3*math.Pi/8, 98)
+		once  = rings.RectangularAt(cen, 3*math.Pi/8, 85)
+		none  = rings.RectangularAt(cen, 3*math.Pi/8, 20)
+	)
+	render := func(ps ...interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) func(vg.Point) color.NRGBA {
+		cv := vgimg.NewWith(vgimg.UseWH(size, size), vgimg.UseDPI(72))
+		for _, p := range ps {
+			p.DrawAt(draw.New(cv), cen)
+		}
+		img := cv.Image()
+		return func(p vg.Point) color.NRGBA {
+			return color.NRGBAModel.Convert(img.At(int(p.X), size-1-int(p.Y))).(color.NRGBA)
+		}
+	}
+
+	single := over(white, translucent)
+	for _, t := range []struct {
+		rule  rings.FillRule
+		twice color.NRGBA
+	}{
+		{rule: rings.NonZeroRule, twice: single},
+		{rule: rings.EvenOddRule, twice: white},
+	} {
+		at := render(ribbon(t.rule))
+		c.Check(near(at(twice), t.twice), check.Equals, true, check.Commentf("rule %d: doubly enclosed %v", t.rule, at(twice)))
+		c.Check(near(at(once), single), check.Equals, true, check.Commentf("rule %d: singly enclosed %v", t.rule, at(once)))
+		c.Check(near(at(none), white), check.Equals, true, check.Commentf("rule %d: outside %v", t.rule, at(none)))
+	}
+
+	// A translucent highlight over a translucent ribbon composites each exactly once.
+	blue := color.NRGBA{B: 0xff, A: 0x80}
+	h := rings.NewHighlight(blue, rings.Arc{0, rings.Complete / 2}, 90, 110)
+	at := render(ribbon(rings.NonZeroRule), h)
+	c.Check(near(at(twice), over(single, blue)), check.Equals, true, check.Commentf("stacked %v", at(twice)))
+	c.Check(near(at(rings.RectangularAt(cen, 7*math.Pi/8, 105)), over(white, blue)), check.Equals, true)
+
+	// Blocks features with a nil fill color are not filled.
+	blocks, err := rings.NewBlocks([]feat.Feature{colorFeature{fs: &fs{start: 10, end: 20, location: chr}}}, b, 80, 90)
+	c.Assert(err, check.Equals, nil)
+	blocks.Color = translucent
+	tc := &canvas{dpi: defaultDPI}
+	blocks.DrawAt(draw.NewCanvas(tc, size, size), cen)
+	for _, a := range tc.actions {
+		_, ok := a.(fill)
+		c.Check(ok, check.Equals, false)
+	}
+}
+
+func (s *S) TestAxisPlacement(c *check.C) {
+	chrs := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+		&fs{start: 0, end: 200, name: "chr3"},
+	}
+	round := func(a rings.Angle) float64 { return math.Floor(float64(a)*1e9+0.5) / 1e9 }
+
+	// Blocks on half of the circle leave the largest gap opposite them.
+	half, err := rings.NewGappedBlocks(chrs, rings.Arc{0, rings.Complete / 2}, 80, 100, 0.1)
+	c.Assert(err, check.Equals, nil)
+	a, err := rings.PlaceAtLargestGap(half)
+	c.Check(err, check.Equals, nil)
+	c.Check(round(a), check.Equals, round(3*math.Pi/2))
+	g := rings.Angle(0.1 * math.Pi)
+	scale := rings.Angle(0.7 * math.Pi / 400)
+	for i, want := range []rings.Angle{g + 100*scale, 2*g + 200*scale} {
+		a, err = rings.PlaceAtGapAfter(chrs[i])(half)
+		c.Check(err, check.Equals, nil)
+		c.Check(round(a), check.Equals, round(want), check.Commentf("gap after %s", chrs[i].Name()))
+	}
+
+	// Placement follows the direction of the base arc.
+	rev, err := rings.NewGappedBlocks(chrs, rings.Arc{0, -rings.Complete / 2}, 80, 100, 0.1)
+	c.Assert(err, check.Equals, nil)
+	a, err = rings.PlaceAtGapAfter(chrs[0])(rev)
+	c.Check(err, check.Equals, nil)
+	c.Check(round(a), check.Equals, round(rings.Normalize(-(g + 100*scale))))
+
+	// Without gaps the axis is placed at the boundary between features.
+	flush, err := rings.NewGappedBlocks(chrs, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	for _, p := range []rings.AxisPlacement{rings.PlaceAtGapAfter(chrs[0]), rings.PlaceAtLargestGap} {
+		a, err = p(flush)
+		c.Check(err, check.Equals, nil)
+		c.Check(round(a), check.Equals, round(math.Pi/2))
+	}
+	a, err = rings.PlaceAtGapAfter(chrs[2])(flush)
+	c.Check(err, check.Equals, nil)
+	c.Check(round(a), check.Equals, 0.)
+
+	a, err = rings.PlaceAtAngle(1)(flush)
+	c.Check(err, check.Equals, nil)
+	c.Check(a, check.Equals, rings.Angle(1))
+
+	_, err = rings.PlaceAtGapAfter(&fs{start: 0, end: 10, name: "chrX"})(flush)
+	c.Check(err, check.NotNil)
+	_, err = rings.PlaceAtLargestGap(rings.Arcs{Base: rings.Arc{0, rings.Complete}})
+	c.Check(err, check.NotNil)
+
+	// An automatically placed axis renders as an axis at the resolved angle.
+	scores := makeScorers(chrs[0].(*fs), 4, 1, func(i, _ int) float64 { return float64(i) })
+	render := func(axis *rings.Axis) []interface{} {
+		axis.LineStyle = plotter.DefaultLineStyle
+		axis.Tick = rings.TickConfig{
+			Marker:    plot.ConstantTicks{{Value: 0, Label: "0"}, {Value: 3, Label: "3"}},
+			LineStyle: plotter.DefaultLineStyle,
+			Length:    3,
+		}
+		sc, err := rings.NewScores(scores, half, 40, 60, &rings.Trace{
+			LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+			Axis:       axis,
+		})
+		c.Assert(err, check.Equals, nil)
+		c.Check(sc.Validate(), check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		return tc.actions
+	}
+	want, err := rings.PlaceAtGapAfter(chrs[1])(half)
+	c.Assert(err, check.Equals, nil)
+	c.Check(render(&rings.Axis{Auto: rings.PlaceAtGapAfter(chrs[1])}), check.DeepEquals, render(&rings.Axis{Angle: want}))
+
+	sc, err := rings.NewScores(scores, half, 40, 60, &rings.Trace{
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+		Axis:       &rings.Axis{Auto: rings.PlaceAtGapAfter(&fs{start: 0, end: 10, name: "chrX"})},
+	})
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.NotNil)
+
+	// Drawing an axis that cannot be placed panics with the placement error.
+	_, err = rings.PlaceAtGapAfter(&fs{start: 0, end: 10, name: "chrX"})(half)
+	c.Assert(err, check.NotNil)
+	c.Check(func() { sc.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{150, 150}) },
+		check.Panics, "rings: failed to place axis: "+err.Error())
+}
+
+func (s *S) TestColorByLocation(c *check.C) {
+	red := colorFeature{fs: &fs{start: 0, end: 100, name: "chr1"}, fill: color.NRGBA{R: 0xff, A: 0xff}}
+	plain := &fs{start: 0, end: 100, name: "chr2"}
+	unfilled := colorFeature{fs: &fs{start: 0, end: 100, name: "chr3"}}
+	b, err := rings.NewGappedBlocks([]feat.Feature{red, plain, unfilled}, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	stray := &fs{start: 0, end: 100, name: "chrX"}
+
+	base := draw.LineStyle{Color: color.Gray{0x80}, Width: 2}
+	sty := rings.ColorByLocation(b, 1, 0.5, base)
+	for _, t := range []struct {
+		loc  feat.Feature
+		want color.Color
+	}{
+		{loc: red, want: color.NRGBA{R: 0xff, A: 0x80}},
+		{loc: plain, want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}},
+		{loc: unfilled, want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}},
+		{loc: stray, want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}},
+	} {
+		p := vp{feats: [2]feat.Feature{&fs{start: 1, end: 2, location: plain}, &fs{start: 1, end: 2, location: t.loc}}}
+		c.Check(sty(p), check.DeepEquals, draw.LineStyle{Color: t.want, Width: 2}, check.Commentf("location %s", t.loc.Name()))
+	}
+
+	b.Color = color.NRGBA{B: 0xff, A: 0xff}
+	p := vp{feats: [2]feat.Feature{&fs{start: 1, end: 2, location: plain}, &fs{start: 1, end: 2, location: red}}}
+	c.Check(rings.ColorByLocation(b, 0, 1, base)(p).Color, check.DeepEquals, color.NRGBA{B: 0xff, A: 0xff})
+	c.Check(rings.ColorByLocation(b, 1, 1, draw.LineStyle{Width: 1})(vp{feats: [2]feat.Feature{plain, stray}}).Color, check.Equals, nil)
+
+	// Locations that are not held by the Blocks are matched by name.
+	alias := &fs{start: 0, end: 100, name: "chr1"}
+	p = vp{feats: [2]feat.Feature{&fs{start: 1, end: 2, location: plain}, &fs{start: 1, end: 2, location: alias}}}
+	c.Check(rings.ColorByLocation(b, 1, 1, base)(p).Color, check.DeepEquals, color.NRGBA{R: 0xff, A: 0xff})
+	c.Check(b.ColorOf(alias), check.Equals, red.fill)
+
+	// Names shared by several blocks are not matched.
+	twin := colorFeature{fs: &fs{start: 0, end: 100, name: "chr1"}, fill: color.NRGBA{G: 0xff, A: 0xff}}
+	b, err = rings.NewGappedBlocks([]feat.Feature{red, twin}, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	c.Check(b.ColorOf(red), check.Equals, red.fill)
+	c.Check(b.ColorOf(twin), check.Equals, twin.fill)
+	c.Check(b.ColorOf(alias), check.Equals, nil)
+
+	c.Check(func() { rings.ColorByLocation(b, 2, 1, base) }, check.PanicMatches, "rings: invalid link end: 2")
+	c.Check(func() { rings.ColorByFeature(nil, -1, 1, base) }, check.PanicMatches, "rings: invalid link end: -1")
+}
+
+func (s *S) TestAnnularWedge(c *check.C) {
+	cen := vg.Point{50, 50}
+	for _, phi := range []rings.Angle{math.Pi / 3, -math.Pi / 3, rings.Complete, -rings.Complete} {
+		var pa vg.Path
+		rings.AnnularWedge(&pa, cen, 10, 20, rings.Arc{Theta: math.Pi / 4, Phi: phi})
+		c.Check(pa, check.DeepEquals, vg.Path{
+			{Type: vg.MoveComp, Pos: rings.RectangularAt(cen, math.Pi/4, 10)},
+			{Type: vg.ArcComp, Pos: cen, Radius: 10, Start: math.Pi / 4, Angle: float64(phi)},
+			{Type: vg.ArcComp, Pos: cen, Radius: 20, Start: float64(math.Pi/4 + phi), Angle: float64(-phi)},
+			{Type: vg.CloseComp},
+		}, check.Commentf("phi=%v", phi))
+	}
+
+	// Heat cells are rendered as exact annular wedges.
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0.1)
+	c.Assert(err, check.Equals, nil)
+	sc, err := rings.NewScores(makeScorers(chr, 4, 2, func(i, j int) float64 { return float64(i + j) }), b, 40, 60,
+		&rings.Heat{Palette: []color.Color{color.Black, color.White}})
+	c.Assert(err, check.Equals, nil)
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var n int
+	for _, a := range tc.actions {
+		f, ok := a.(fill)
+		if !ok {
+			continue
+		}
+		n++
+		var arcs int
+		for _, p := range f.path {
+			if p.Type == vg.ArcComp {
+				arcs++
+			}
+		}
+		c.Check(arcs, check.Equals, 2)
+		c.Check(f.path[len(f.path)-1].Type, check.Equals, vg.CloseComp)
+	}
+	c.Check(n, check.Equals, 8)
+}
+
+func (s *S) TestLinksEndPositions(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	cen := vg.Point{150, 150}
+	pairs := []rings.Pair{
+		// Ends at the first and final bases of their chromosomes.
+		vp{feats: [2]feat.Feature{&fs{start: 0, end: 1, location: chr[0]}, &fs{start: 99, end: 100, location: chr[1]}}},
+		// Ends spanning whole chromosomes held by the base.
+		vp{feats: [2]feat.Feature{chr[0], chr[1]}},
+	}
+	for _, t := range []struct {
+		centered bool
+		want     [][2]rings.Angle
+	}{
+		{
+			centered: false,
+			want:     [][2]rings.Angle{{0, math.Pi * 1.99}, {0, math.Pi}},
+		},
+		{
+			centered: true,
+			want:     [][2]rings.Angle{{math.Pi * 0.005, math.Pi * 1.995}, {math.Pi / 2, math.Pi * 1.5}},
+		},
+	} {
+		l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+		c.Assert(err, check.Equals, nil)
+		l.LineStyle = plotter.DefaultLineStyle
+		l.Bezier = &rings.Bezier{Segments: 10, Radius: rings.LengthDist{Length: 20}}
+		l.Centered = t.centered
+		c.Check(l.Validate(), check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+
+		var got [][2]rings.Angle
+		for _, a := range tc.actions {
+			s, ok := a.(stroke)
+			if !ok {
+				continue
+			}
+			var ends [2]rings.Angle
+			for i, p := range []vg.Point{s.path[0].Pos, s.path[len(s.path)-1].Pos} {
+				theta, r := rings.Polar(p.Sub(cen))
+				c.Check(math.Floor(float64(r)*1e6+0.5)/1e6, check.Equals, 70.)
+				ends[i] = rings.Angle(math.Floor(float64(rings.Normalize(theta))*1e9+0.5) / 1e9)
+			}
+			got = append(got, ends)
+		}
+		var want [][2]rings.Angle
+		for _, w := range t.want {
+			for i := range w {
+				w[i] = rings.Angle(math.Floor(float64(w[i])*1e9+0.5) / 1e9)
+			}
+			want = append(want, w)
+		}
+		c.Check(got, check.DeepEquals, want, check.Commentf("centered=%t", t.centered))
+		p, err := plot.New()
+		c.Assert(err, check.Equals, nil)
+		c.Check(l.GlyphBoxes(p), check.HasLen, 1)
+	}
+}
+
+func (s *S) TestZeroLengthFeatures(c *check.C) {
+	const mb = 1000000
+	chr := &fs{start: 0, end: 250 * mb, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 100, 110, 0)
+	c.Assert(err, check.Equals, nil)
+	snvs := []feat.Feature{
+		&fs{start: 10 * mb, end: 10 * mb, name: "ins", location: chr},
+		&fs{start: 125 * mb, end: 125*mb + 1, name: "snv", location: chr},
+		&fs{start: 250 * mb, end: 250 * mb, name: "end", location: chr},
+	}
+	at := func(pos int) rings.Angle { return rings.Angle(float64(pos) / (250 * mb) * float64(rings.Complete)) }
+	round := func(a float64) float64 { return math.Floor(a*1e9+0.5) / 1e9 }
+	cen := vg.Point{150, 150}
+
+	// sweeps returns the centre and sweep of the inner arc of each filled path.
+	sweeps := func(p interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) (mids, phis []float64) {
+		tc := &canvas{dpi: defaultDPI}
+		p.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			f, ok := a.(fill)
+			if !ok {
+				continue
+			}
+			for _, p := range f.path {
+				if p.Type == vg.ArcComp {
+					mids = append(mids, round(float64(rings.Normalize(rings.Angle(p.Start+p.Angle/2)))))
+					phis = append(phis, round(p.Angle))
+					break
+				}
+			}
+		}
+		return mids, phis
+	}
+	var mids []float64
+	for _, f := range snvs {
+		mids = append(mids, round(float64(rings.Normalize(at(f.Start())+at(f.Len())/2))))
+	}
+
+	blocks, err := rings.NewBlocks(snvs, b, 80, 90)
+	c.Assert(err, check.Equals, nil)
+	blocks.Color = color.Black
+	for _, t := range []struct {
+		min  rings.Angle
+		want []float64
+	}{
+		{min: 0, want: []float64{round(float64(rings.MinFeatureAngle)), round(float64(rings.MinFeatureAngle)), round(float64(rings.MinFeatureAngle))}},
+		{min: 0.01, want: []float64{0.01, 0.01, 0.01}},
+		{min: -1, want: []float64{0, round(float64(at(1))), 0}},
+	} {
+		blocks.MinAngle = t.min
+		gotMids, phis := sweeps(blocks)
+		c.Check(gotMids, check.DeepEquals, mids, check.Commentf("blocks min angle %v", t.min))
+		c.Check(phis, check.DeepEquals, t.want, check.Commentf("blocks min angle %v", t.min))
+	}
+
+	tiles, err := rings.NewTiles(snvs, b, 60, 70)
+	c.Assert(err, check.Equals, nil)
+	tiles.Color = color.Black
+	tiles.MinWidth = 0
+	gotMids, phis := sweeps(tiles)
+	c.Check(gotMids, check.DeepEquals, mids)
+	for _, phi := range phis {
+		c.Check(phi, check.Equals, round(float64(rings.MinFeatureAngle)))
+	}
+
+	// Labels and link ends are placed at the point of the feature.
+	l, err := rings.NewLabels(b, 120, rings.NameLabels(snvs)...)
+	c.Assert(err, check.Equals, nil)
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	l.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	g, err := l.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(g.Labels, check.HasLen, len(snvs))
+	for i, lg := range g.Labels {
+		c.Check(round(float64(rings.Normalize(rings.Angle(lg.Angle)))), check.Equals, mids[i])
+	}
+	links, err := rings.NewLinks([]rings.Pair{vp{feats: [2]feat.Feature{snvs[0], snvs[1]}}}, [2]rings.ArcOfer{b, b}, [2]vg.Length{50, 50})
+	c.Assert(err, check.Equals, nil)
+	links.LineStyle = plotter.DefaultLineStyle
+	tc := &canvas{dpi: defaultDPI}
+	links.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var ends []float64
+	for _, a := range tc.actions {
+		if s, ok := a.(stroke); ok {
+			for _, p := range []vg.Point{s.path[0].Pos, s.path[len(s.path)-1].Pos} {
+				theta, _ := rings.Polar(p.Sub(cen))
+				ends = append(ends, math.Floor(float64(rings.Normalize(theta))*1e6+0.5)/1e6)
+			}
+		}
+	}
+	c.Check(ends, check.DeepEquals, []float64{
+		math.Floor(float64(at(10*mb))*1e6+0.5) / 1e6,
+		math.Floor(float64(at(125*mb))*1e6+0.5) / 1e6,
+	})
+
+	// Highlights refuse zero length features.
+	h := rings.NewHighlight(color.Black, rings.Arc{}, 80, 90)
+	h.LocBase = b
+	h.LocFeatures = snvs[1:2]
+	c.Check(h.Validate(), check.Equals, nil)
+	h.LocFeatures = snvs[:2]
+	c.Check(h.Validate(), check.ErrorMatches, `.*zero length highlight feature "ins".*`)
+}
+
+func (s *S) TestConcurrentPlot(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 600, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 110, 120, 0.02)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.NRGBA{R: 0x80, G: 0x80, B: 0xff, A: 0xff}
+	b.HitTester = &rings.HitTester{}
+	font, err := vg.MakeFont("Helvetica", 6)
+	c.Assert(err, check.Equals, nil)
+	stack := rings.NewTitleStack()
+	b.Title = rings.Title{Text: "genome", TextStyle: draw.TextStyle{Color: color.Black, Font: font}, Side: rings.TickOutside, Stack: stack}
+
+	r := rings.NewRange()
+	var ps []plot.Plotter
+	for i, f := range chr {
+		i := i
+		tr, err := rings.NewScores(makeScorers(f.(*fs), 20, 1, func(j, _ int) float64 { return math.Sin(float64(i+j) / 3) }), b, 80, 100,
+			&rings.Trace{
+				LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+				Join:       true,
+				Axis: &rings.Axis{
+					LineStyle: plotter.DefaultLineStyle,
+					Tick:      rings.TickConfig{Marker: plot.DefaultTicks{}, LineStyle: plotter.DefaultLineStyle, Length: 2},
+				},
+			})
+		c.Assert(err, check.Equals, nil)
+		tr.Range = r
+		tr.Title = rings.Title{Text: f.Name(), TextStyle: draw.TextStyle{Color: color.Black, Font: font}, Side: rings.TickOutside, Stack: stack}
+		heat, err := rings.NewScores(makeScorers(f.(*fs), 20, 3, func(j, k int) float64 { return float64(j * k) }), b, 50, 70,
+			&rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()})
+		c.Assert(err, check.Equals, nil)
+		ps = append(ps, tr, heat)
+	}
+	var pairs []rings.Pair
+	for i := 0; i < 20; i++ {
+		pairs = append(pairs, vp{feats: [2]feat.Feature{
+			&fs{start: i * 50, end: i*50 + 10, location: chr[0]},
+			&fs{start: i * 30, end: i*30 + 10, location: chr[1]},
+		}})
+	}
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{45, 45})
+	c.Assert(err, check.Equals, nil)
+	l.LineStyle = plotter.DefaultLineStyle
+	// Perturbed curves are reproducible when drawn concurrently since
+	// each is seeded by the Bezier's Seed and its end points.
+	l.Bezier = &rings.Bezier{
+		Segments: 20,
+		Radius:   rings.LengthDist{Length: 20, Min: floatPtr(0.5), Max: floatPtr(1.5)},
+		Crest:    &rings.FactorDist{Factor: 2, Min: floatPtr(0.7), Max: floatPtr(1.4)},
+		Seed:     1,
+	}
+	lb, err := rings.NewLabels(b, 125, rings.NameLabels(chr)...)
+	c.Assert(err, check.Equals, nil)
+	lb.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	ps = append(ps, b, l, lb)
+
+	p, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	p.Add(ps...)
+	p.HideAxes()
+
+	render := func() []byte {
+		cv := vgimg.New(300, 300)
+		p.Draw(draw.New(cv))
+		return cv.Image().(*image.RGBA).Pix
+	}
+	want := render()
+
+	const n = 8
+	got := make(chan []byte, n)
+	for i := 0; i < n; i++ {
+		go func() { got <- render() }()
+	}
+	for i := 0; i < n; i++ {
+		c.Check(bytes.Equal(<-got, want), check.Equals, true)
+	}
+	c.Check(lb.Placed(), check.DeepEquals, chr)
+
+	// Curves drawn with a nil Rand depend only on the Seed and their ends.
+	a, rad := [2]rings.Angle{0, 2}, [2]vg.Length{45, 45}
+	c.Check(l.Bezier.ControlPoints(a, rad), check.DeepEquals, l.Bezier.ControlPoints(a, rad))
+	other := &rings.Bezier{Radius: l.Bezier.Radius, Crest: l.Bezier.Crest, Seed: 2}
+	c.Check(other.ControlPoints(a, rad), check.Not(check.DeepEquals), l.Bezier.ControlPoints(a, rad))
+}
+
+func (s *S) TestScoresPerFeatureRange(c *check.C) {
+	chr := []*fs{
+		{start: 0, end: 100, name: "chr1"},
+		{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr[0], chr[1]}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	var set []rings.Scorer
+	for i, scale := range []float64{10, 1000} {
+		scale := scale
+		set = append(set, makeScorers(chr[i], 4, 1, func(j, _ int) float64 { return float64(j+1) * scale / 4 })...)
+	}
+	t := &rings.Trace{
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+		Axis: &rings.Axis{
+			LineStyle: plotter.DefaultLineStyle,
+			Tick: rings.TickConfig{
+				Marker:    plot.ConstantTicks{{Value: 5, Label: "5"}},
+				LineStyle: plotter.DefaultLineStyle,
+				Length:    2,
+			},
+		},
+	}
+	sc, err := rings.NewScores(set, b, 40, 60, t)
+	c.Assert(err, check.Equals, nil)
+	sc.PerFeatureRange = true
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	for i, want := range [][2]float64{{2.5, 10}, {250, 1000}} {
+		min, max := sc.LocationRange(chr[i])
+		c.Check([2]float64{min, max}, check.Equals, want)
+	}
+
+	// Each location is scaled to its own range.
+	g, err := sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	var radii []float64
+	for _, s := range g.Scores {
+		radii = append(radii, math.Floor(*s.Radii[0]*1e6+0.5)/1e6)
+	}
+	c.Check(radii, check.DeepEquals, []float64{40, 46.666667, 53.333333, 60, 40, 46.666667, 53.333333, 60})
+
+	// An axis line is drawn at the start of each location, with a tick for 5
+	// only within the range of the first location.
+	cen := vg.Point{150, 150}
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var axes []float64
+	for _, a := range tc.actions {
+		st, ok := a.(stroke)
+		if !ok || len(st.path) != 2 {
+			continue
+		}
+		theta, r0 := rings.Polar(st.path[0].Pos.Sub(cen))
+		_, r1 := rings.Polar(st.path[1].Pos.Sub(cen))
+		if r0 == 40 && r1 == 60 {
+			axes = append(axes, math.Floor(float64(rings.Normalize(theta))*1e6+0.5)/1e6)
+		}
+	}
+	c.Check(axes, check.DeepEquals, []float64{0, math.Floor(math.Pi*1e6+0.5) / 1e6})
+	c.Check(t.Axis.Angle, check.Equals, rings.Angle(0))
+
+	// A shared axis range cannot be used for all the locations.
+	t.Axis.Range = rings.NewRange()
+	c.Check(sc.Validate(), check.ErrorMatches, `.*per feature range with shared axis range.*`)
+
+	// Each location of a Heat is colored according to its own range, and
+	// the range is resolved again on each drawing.
+	h := &rings.Heat{Palette: []color.Color{color.Gray{0x00}, color.Gray{0x80}, color.Gray{0xff}}}
+	sc, err = rings.NewScores(set, b, 40, 60, h)
+	c.Assert(err, check.Equals, nil)
+	sc.PerFeatureRange = true
+	fills := func() []color.Color {
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var cols []color.Color
+		for i, a := range tc.actions {
+			if _, ok := a.(fill); ok {
+				cols = append(cols, tc.actions[i-1].(setColor).col)
+			}
+		}
+		return cols
+	}
+	ramp := []color.Color{color.Gray{0x00}, color.Gray{0x80}, color.Gray{0x80}, color.Gray{0xff}}
+	for i := 0; i < 2; i++ {
+		c.Check(fills(), check.DeepEquals, append(ramp[:4:4], ramp...), check.Commentf("drawing %d", i))
+	}
+
+	sc.PerFeatureRange = false
+	sc.Set = set[4:]
+	min, max := sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{250, 1000})
+	c.Check(fills(), check.DeepEquals, ramp)
+}
+
+// bareFeature is a feature that does not define its own style.
+type bareFeature struct{ feat.Feature }
+
+func (s *S) TestRibbonsEdgeStyles(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0.1)
+	c.Assert(err, check.Equals, nil)
+	r, err := rings.NewRibbons([]rings.Pair{vp{feats: [2]feat.Feature{
+		bareFeature{&fs{start: 10, end: 30, location: chr[0]}},
+		bareFeature{&fs{start: 60, end: 90, location: chr[1]}},
+	}}}, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	r.Color = color.NRGBA{R: 0xff, A: 0x80}
+	r.Bezier = &rings.Bezier{Segments: 10, Radius: rings.LengthDist{Length: 20}}
+	r.LineStyle = draw.LineStyle{Color: color.Black, Width: 1}
+
+	// edges returns the number of path components of each type in each
+	// filled and stroked path, and the colors of the strokes.
+	edges := func() (fills, strokes [][3]int, cols []color.Color) {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		count := func(pa vg.Path) (n [3]int) {
+			for _, p := range pa {
+				switch p.Type {
+				case vg.MoveComp:
+					n[0]++
+				case vg.LineComp:
+					n[1]++
+				case vg.ArcComp:
+					n[2]++
+				}
+			}
+			return n
+		}
+		var col color.Color
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case setColor:
+				col = a.col
+			case fill:
+				fills = append(fills, count(a.path))
+			case stroke:
+				strokes = append(strokes, count(a.path))
+				cols = append(cols, col)
+			}
+		}
+		return fills, strokes, cols
+	}
+
+	// Without edge styles the outline is stroked as a single path.
+	fills, strokes, cols := edges()
+	c.Check(fills, check.DeepEquals, [][3]int{{1, 20, 2}})
+	c.Check(strokes, check.DeepEquals, [][3]int{{1, 20, 2}})
+	c.Check(cols, check.DeepEquals, []color.Color{color.Black})
+
+	// Solid rim edges without curve strokes.
+	r.ArcLineStyle = &draw.LineStyle{Color: color.Gray{0x40}, Width: 2}
+	r.CurveLineStyle = &draw.LineStyle{}
+	fills, strokes, cols = edges()
+	c.Check(fills, check.DeepEquals, [][3]int{{1, 20, 2}})
+	c.Check(strokes, check.DeepEquals, [][3]int{{1, 0, 1}, {1, 0, 1}})
+	c.Check(cols, check.DeepEquals, []color.Color{color.Gray{0x40}, color.Gray{0x40}})
+
+	// An unset edge style falls back to LineStyle.
+	r.ArcLineStyle = nil
+	r.CurveLineStyle = &draw.LineStyle{Color: color.White, Width: 1}
+	fills, strokes, cols = edges()
+	c.Check(fills, check.DeepEquals, [][3]int{{1, 20, 2}})
+	c.Check(strokes, check.DeepEquals, [][3]int{{1, 0, 1}, {1, 10, 0}, {1, 0, 1}, {1, 10, 0}})
+	c.Check(cols, check.DeepEquals, []color.Color{color.Black, color.White, color.Black, color.White})
+}
+
+func (s *S) TestAxisTicks(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	scores := makeScorers(chr, 4, 1, func(i, _ int) float64 { return []float64{5, 50, 95, 100}[i] })
+
+	ax := &rings.Axis{
+		Grid: plotter.DefaultLineStyle,
+		Tick: rings.TickConfig{
+			Marker: plot.ConstantTicks{
+				{Value: 0, Label: "0"}, {Value: 25}, {Value: 50, Label: "50"},
+				{Value: 75}, {Value: 100, Label: "100"}, {Value: 150, Label: "150"},
+			},
+			Format: func(v float64) string { return fmt.Sprintf("%.0f%%", v) },
+		},
+	}
+	breaks := []rings.Break{{Lo: 40, Hi: 60}}
+
+	c.Check(ax.Ticks(0, 100, 40, 60, breaks...), check.DeepEquals, []rings.ResolvedTick{
+		{Value: 0, Label: "0%", Radius: 40},
+		{Value: 25, Radius: 46.25, Minor: true},
+		{Value: 75, Radius: 53.75, Minor: true},
+		{Value: 100, Label: "100%", Radius: 60},
+	})
+
+	// A shared range takes precedence over the specified range.
+	ax.Range = &rings.Range{Min: 0, Max: 200}
+	c.Check(ax.Ticks(0, 100, 40, 60), check.DeepEquals, []rings.ResolvedTick{
+		{Value: 0, Label: "0%", Radius: 40},
+		{Value: 25, Radius: 42.5, Minor: true},
+		{Value: 50, Label: "50%", Radius: 45},
+		{Value: 75, Radius: 47.5, Minor: true},
+		{Value: 100, Label: "100%", Radius: 50},
+		{Value: 150, Label: "150%", Radius: 55},
+	})
+	ax.Range = nil
+
+	// The grid lines of the drawn axis are at the radii of the resolved ticks.
+	tr := &rings.Trace{LineStyles: []draw.LineStyle{{}}, Min: 0, Max: 100, Breaks: breaks, Axis: ax}
+	sc, err := rings.NewScores(scores, b, 40, 60, tr)
+	c.Assert(err, check.Equals, nil)
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var got []vg.Length
+	for _, a := range tc.actions {
+		if s, ok := a.(stroke); ok && len(s.path) != 0 && s.path[len(s.path)-1].Type == vg.ArcComp {
+			got = append(got, s.path[len(s.path)-1].Radius)
+		}
+	}
+	var want []vg.Length
+	for _, t := range ax.Ticks(tr.Min, tr.Max, sc.Inner, sc.Outer, tr.Breaks...) {
+		want = append(want, t.Radius)
+	}
+	c.Check(got, check.DeepEquals, want)
+}
+
+func (s *S) TestBoundaryPolicy(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	base := rings.NewGappedArcs(rings.Arc{0, rings.Complete}, chr, 0)
+	over := &fs{start: 90, end: 110, name: "over", location: chr[0]}
+	within := &fs{start: 10, end: 20, name: "within", location: chr[0]}
+
+	near := func(a, b rings.Angle) bool { return math.Abs(float64(a-b)) < 1e-12 }
+	for _, t := range []struct {
+		policy rings.BoundaryPolicy
+		end    rings.Angle
+		zoomed rings.Angle
+		err    string
+	}{
+		{policy: rings.Clamp, end: rings.Complete / 2, zoomed: rings.Complete / 2},
+		{policy: rings.Wrap, end: rings.Complete * 0.55, zoomed: rings.Complete * 0.525},
+		{policy: rings.Strict, err: `rings: feature "over" extends beyond end of location "chr1"`},
+	} {
+		base.Boundary = t.policy
+		arc, err := base.ArcOf(chr[0], over)
+		if t.err != "" {
+			c.Check(err, check.ErrorMatches, t.err)
+			_, ok := err.(*rings.BoundaryError)
+			c.Check(ok, check.Equals, true)
+		} else {
+			c.Check(err, check.Equals, nil)
+			c.Check(near(arc.Theta+arc.Phi, t.end), check.Equals, true, check.Commentf("policy %d end %v", t.policy, arc.Theta+arc.Phi))
+		}
+
+		// Features within their location are unaffected by the policy.
+		arc, err = base.ArcOf(chr[0], within)
+		c.Check(err, check.Equals, nil)
+		c.Check(near(arc.Theta+arc.Phi, rings.Complete*0.1), check.Equals, true)
+
+		// Zoomed Blocks follow the policy of their base. Positions beyond
+		// the end of the location are not magnified by the zoom.
+		b, err := rings.NewBlocks(chr, base, 80, 100)
+		c.Assert(err, check.Equals, nil)
+		b.Zooms = []rings.Zoom{{Location: chr[0], Start: 0, End: 50, Scale: 3}}
+		arc, err = b.ArcOf(chr[0], over)
+		if t.err != "" {
+			c.Check(err, check.ErrorMatches, t.err)
+		} else {
+			c.Check(err, check.Equals, nil)
+			c.Check(near(arc.Theta+arc.Phi, t.zoomed), check.Equals, true, check.Commentf("policy %d end %v", t.policy, arc.Theta+arc.Phi))
+		}
+	}
+
+	// Validation is strict whatever the policy used for drawing.
+	base.Boundary = rings.Clamp
+	scores := []rings.Scorer{
+		&fs{start: 10, end: 20, name: "a", location: chr[0]},
+		&fs{start: 90, end: 101, name: "b", location: chr[0]},
+		&fs{start: 95, end: 120, name: "c", location: chr[1]},
+	}
+	for i, sc := range scores {
+		sc.(*fs).scores = []float64{float64(i)}
+	}
+	sc, err := rings.NewScores(scores, base, 40, 60, &rings.Heat{Palette: []color.Color{color.Black}})
+	c.Assert(err, check.Equals, nil)
+	err = sc.Validate()
+	c.Check(err, check.ErrorMatches, `rings: feature "b" extends beyond end of location "chr1"; `+
+		`rings: feature "c" extends beyond end of location "chr2"`)
+	verr, ok := err.(rings.ValidationError)
+	c.Assert(ok, check.Equals, true)
+	c.Check(verr.Violations(), check.DeepEquals, []feat.Feature{scores[1], scores[2]})
+}
+
+func (s *S) TestPad(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	var scores []rings.Scorer
+	for _, loc := range chr {
+		scores = append(scores, makeScorers(loc.(*fs), 4, 1, func(i, _ int) float64 { return float64(i) })...)
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+
+	// A negative pad is treated as zero.
+	for _, pad := range []rings.Angle{0, rings.Complete / 20, -1} {
+		sc, err := rings.NewScores(scores, b, 40, 60, &rings.Heat{Palette: []color.Color{color.Black}})
+		c.Assert(err, check.Equals, nil)
+		sc.Pad = pad
+		c.Check(sc.Validate(), check.Equals, nil)
+
+		// Each location's scorers are spread over its arc less the padding
+		// at each end.
+		g, err := sc.Describe()
+		c.Assert(err, check.Equals, nil)
+		c.Assert(g.Scores, check.HasLen, 8)
+		if pad < 0 {
+			pad = 0
+		}
+		width := (math.Pi - 2*float64(pad)) / 4
+		for i, s := range g.Scores {
+			theta := float64(i/4)*math.Pi + float64(pad) + float64(i%4)*width
+			c.Check(near(s.Arc.Theta, theta), check.Equals, true, check.Commentf("pad %v scorer %d theta %v", pad, i, s.Arc.Theta))
+			c.Check(near(s.Arc.Phi, width), check.Equals, true, check.Commentf("pad %v scorer %d phi %v", pad, i, s.Arc.Phi))
+		}
+
+		// No scorer is dropped.
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var fills int
+		for _, a := range tc.actions {
+			if _, ok := a.(fill); ok {
+				fills++
+			}
+		}
+		c.Check(fills, check.Equals, 8)
+	}
+
+	// Tiles are padded in the same way. A pad larger than half of the
+	// location's arc places all its features at the middle of the arc.
+	for _, t := range []struct {
+		pad  rings.Angle
+		want []float64
+	}{
+		{pad: -1, want: []float64{0, 0.5 * math.Pi, math.Pi}},
+		{pad: rings.Complete / 20, want: []float64{0.1 * math.Pi, 0.5 * math.Pi, 1.1 * math.Pi}},
+		{pad: rings.Complete, want: []float64{0.5 * math.Pi, 0.5 * math.Pi, 1.5 * math.Pi}},
+	} {
+		tl, err := rings.NewTiles([]feat.Feature{
+			&fs{start: 0, end: 10, name: "a", location: chr[0]},
+			&fs{start: 50, end: 60, name: "b", location: chr[0]},
+			&fs{start: 0, end: 10, name: "c", location: chr[1]},
+		}, b, 40, 60)
+		c.Assert(err, check.Equals, nil)
+		tl.Color = color.Black
+		tl.MinAngle = -1
+		tl.MinWidth = 0
+		tl.Pad = t.pad
+		tc := &canvas{dpi: defaultDPI}
+		tl.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var got []float64
+		for _, a := range tc.actions {
+			if f, ok := a.(fill); ok {
+				theta, _ := rings.Polar(f.path[0].Pos.Sub(vg.Point{150, 150}))
+				got = append(got, float64(rings.Normalize(theta)))
+			}
+		}
+		c.Assert(got, check.HasLen, len(t.want))
+		for i := range got {
+			c.Check(math.Abs(got[i]-t.want[i]) < 1e-9, check.Equals, true, check.Commentf("pad %v tile %d theta %v", t.pad, i, got[i]))
+		}
+	}
+}
+
+func (s *S) TestAxisRangeFrom(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	ax := &rings.Axis{
+		Grid: plotter.DefaultLineStyle,
+		Tick: rings.TickConfig{Marker: plot.ConstantTicks{{Value: 0, Label: "0"}, {Value: 10, Label: "10"}, {Value: 20, Label: "20"}}},
+	}
+	tr := &rings.Trace{LineStyles: []draw.LineStyle{{}}, Axis: ax}
+	a, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) * 10 / 3 }), b, 40, 60, tr)
+	c.Assert(err, check.Equals, nil)
+	a.Min, a.Max = 0, 0
+	other, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return 5 + float64(i)*5 }), b, 60, 80, &rings.Heat{Palette: []color.Color{color.Black}})
+	c.Assert(err, check.Equals, nil)
+	other.Min, other.Max = 0, 0
+
+	// The axis is ticked according to the union of the ranges of the Scores,
+	// determined from their data when it is drawn, and the Scores are rendered
+	// according to the same range.
+	ax.RangeFrom(a, other)
+	c.Check(ax.Validate(), check.Equals, nil)
+	c.Check(a.Range, check.Equals, ax.Range)
+	c.Check(other.Range, check.Equals, ax.Range)
+	want := []rings.ResolvedTick{
+		{Value: 0, Label: "0", Radius: 40},
+		{Value: 10, Label: "10", Radius: 50},
+		{Value: 20, Label: "20", Radius: 60},
+	}
+	c.Check(ax.Ticks(0, 1, 40, 60), check.DeepEquals, want)
+	for _, sc := range []*rings.Scores{a, other} {
+		min, max := sc.ScoreRange()
+		c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 20})
+	}
+
+	tc := &canvas{dpi: defaultDPI}
+	tr.LineStyles = []draw.LineStyle{plotter.DefaultLineStyle}
+	a.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var radii []vg.Length
+	for _, act := range tc.actions {
+		if s, ok := act.(stroke); ok && len(s.path) != 0 && s.path[len(s.path)-1].Type == vg.ArcComp {
+			radii = append(radii, s.path[len(s.path)-1].Radius)
+		}
+	}
+	// The grid is drawn at the ticks of the axis, followed by the trace of
+	// scores from 0 to 10 across the inner half of the axis.
+	c.Check(radii, check.DeepEquals, []vg.Length{40, 50, 60, 40, 40 + 10.0/3, 40 + 20.0/3, 50})
+
+	// Sources given an explicit range are reported.
+	other.Min, other.Max = 0, 10
+	other.Title.Text = "depth"
+	c.Check(ax.Validate(), check.ErrorMatches, `rings: axis range source "depth" not using axis range`)
+	other.Min, other.Max = 0, 0
+	c.Check(ax.Validate(), check.Equals, nil)
+
+	rng := ax.Range
+	ax.RangeFrom()
+	c.Check(ax.Range, check.IsNil)
+	c.Check(a.Range, check.Equals, rng)
+	c.Check(ax.Ticks(0, 1, 40, 60), check.DeepEquals, []rings.ResolvedTick{{Value: 0, Label: "0", Radius: 40}})
+}
+
+func (s *S) TestBlocksInnerLabel(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 900, name: "chromosome1"},
+		&fs{start: 0, end: 90, name: "c2"},
+		&fs{start: 0, end: 10, name: "c3"},
+	}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	for _, t := range []struct {
+		min  vg.Length
+		want []string
+		size []vg.Length
+	}{
+		{min: 0, want: []string{"chromosome1", "c2"}, size: []vg.Length{10, 10}},
+		{min: 4, want: []string{"chromosome1", "c2", "c3"}, size: []vg.Length{10, 10, 5.3}},
+		{min: 6, want: []string{"chromosome1", "c2"}, size: []vg.Length{10, 10}},
+	} {
+		b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0,
+			rings.BlockInnerLabel(rings.InnerLabel{
+				Text:      feat.Feature.Name,
+				TextStyle: draw.TextStyle{Color: color.Black, Font: font},
+				MinSize:   t.min,
+			}),
+		)
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		b.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var (
+			got  []string
+			size []vg.Length
+		)
+		for _, a := range tc.actions {
+			if f, ok := a.(fillString); ok {
+				got = append(got, f.str)
+				size = append(size, vg.Length(math.Floor(float64(f.size)*10)/10))
+			}
+		}
+		c.Check(got, check.DeepEquals, t.want)
+		c.Check(size, check.DeepEquals, t.size)
+	}
+
+	_, err = rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0, rings.BlockInnerLabel(rings.InnerLabel{}))
+	c.Check(err, check.ErrorMatches, "rings: nil inner label text function")
+}
+
+func (s *S) TestAnchors(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	anchors := rings.NewAnchors()
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Anchors = anchors
+
+	tr := &rings.Trace{
+		LineStyles: []draw.LineStyle{{}},
+		Min:        0,
+		Max:        20,
+		Axis: &rings.Axis{
+			Angle:   rings.Complete / 4,
+			Anchors: anchors,
+			Tick: rings.TickConfig{Marker: plot.ConstantTicks{
+				{Value: 0, Label: "0"}, {Value: 5}, {Value: 10, Label: "10"}, {Value: 40, Label: "40"},
+			}},
+		},
+	}
+	sc, err := rings.NewScores(makeScorers(chr[0].(*fs), 4, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 60, tr)
+	c.Assert(err, check.Equals, nil)
+
+	// Anchors cannot be resolved before their provider is drawn.
+	_, err = anchors.Resolve("blocks/chr1/mid")
+	c.Check(err, check.ErrorMatches, `rings: anchor "blocks/chr1/mid" not registered: .*`)
+
+	cen := vg.Point{150, 150}
+	tc := &canvas{dpi: defaultDPI}
+	b.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+
+	c.Check(anchors.Names(), check.DeepEquals, []string{
+		"axis/tick/0", "axis/tick/10",
+		"blocks/chr1/end", "blocks/chr1/mid", "blocks/chr1/start",
+		"blocks/chr2/end", "blocks/chr2/mid", "blocks/chr2/start",
+		"blocks/inner", "blocks/outer",
+	})
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for _, t := range []struct {
+		name   string
+		angle  rings.Angle
+		radius vg.Length
+	}{
+		{name: "blocks/chr1/start", angle: 0, radius: 90},
+		{name: "blocks/chr1/mid", angle: rings.Complete / 4, radius: 90},
+		{name: "blocks/chr2/end", angle: rings.Complete, radius: 90},
+		{name: "blocks/outer", angle: 0, radius: 100},
+		{name: "axis/tick/10", angle: rings.Complete / 4, radius: 50},
+	} {
+		a, err := anchors.Resolve(t.name)
+		c.Assert(err, check.Equals, nil)
+		c.Check(a.Center, check.Equals, cen)
+		c.Check(near(float64(a.Angle), float64(t.angle)), check.Equals, true, check.Commentf("%s angle %v", t.name, a.Angle))
+		c.Check(a.Radius, check.Equals, t.radius, check.Commentf("%s", t.name))
+	}
+	a, err := anchors.Resolve("axis/tick/10")
+	c.Assert(err, check.Equals, nil)
+	pt := a.Point()
+	c.Check(near(float64(pt.X), 150) && near(float64(pt.Y), 200), check.Equals, true, check.Commentf("point %v", pt))
+
+	// Anchors of features no longer drawn are removed when the ring is redrawn.
+	b.Set = b.Set[:1]
+	tr.Axis.Tick.Marker = plot.ConstantTicks{{Value: 10, Label: "10"}}
+	b.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	c.Check(anchors.Names(), check.DeepEquals, []string{
+		"axis/tick/10",
+		"blocks/chr1/end", "blocks/chr1/mid", "blocks/chr1/start",
+		"blocks/inner", "blocks/outer",
+	})
+
+	anchors.Register("custom", func() rings.Anchor { return rings.Anchor{Radius: 1} })
+	a, err = anchors.Resolve("custom")
+	c.Check(err, check.Equals, nil)
+	c.Check(a.Radius, check.Equals, vg.Length(1))
+
+	anchors.Reset()
+	c.Check(anchors.Names(), check.HasLen, 0)
+}
+
+func (s *S) TestBlockNames(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 50, name: "top"},
+		&fs{start: 0, end: 49, name: "bottom"},
+		&fs{start: 0, end: 1, name: "tiny"},
+	}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	type glyph struct {
+		str   string
+		angle float64
+		rot   float64
+	}
+	for _, skip := range []bool{false, true} {
+		b, err := rings.NewGappedBlocks(chr, rings.Arc{0.1, rings.Complete}, 80, 100, 0)
+		c.Assert(err, check.Equals, nil)
+		b.Names = rings.BlockNames{TextStyle: draw.TextStyle{Color: color.Black, Font: font}, Offset: 5, SkipWide: skip}
+
+		cen := vg.Point{150, 150}
+		tc := &canvas{dpi: defaultDPI}
+		b.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+
+		// Each glyph is rendered after a translation to its anchor
+		// and a rotation about it.
+		var (
+			glyphs []glyph
+			at     vg.Point
+			rot    float64
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case push:
+				at = vg.Point{}
+			case translate:
+				if at == (vg.Point{}) {
+					at = vg.Point{a.x, a.y}
+				}
+			case rotate:
+				rot = a.angle
+			case fillString:
+				theta, rad := rings.Polar(at.Sub(cen))
+				c.Check(math.Abs(float64(rad)-(105+float64(font.Extents().Height)/2)) < 1e-9, check.Equals, true)
+				glyphs = append(glyphs, glyph{
+					str:   a.str,
+					angle: float64(rings.Normalize(theta)),
+					rot:   float64(rings.Normalize(rings.Angle(rot))),
+				})
+			}
+		}
+
+		var names []string
+		for _, g := range glyphs {
+			names = append(names, g.str)
+		}
+		want := []string{"t", "o", "p", "b", "o", "t", "t", "o", "m"}
+		if !skip {
+			want = append(want, "tiny")
+		}
+		c.Assert(names, check.DeepEquals, want)
+
+		// Names on the top half read clockwise and names on the bottom half
+		// anticlockwise, each centred on its block and tangential to it.
+		for i, g := range glyphs {
+			var (
+				mid  float64
+				turn float64
+			)
+			switch {
+			case i < 3:
+				mid = 0.1 + math.Pi/2
+				if i > 0 {
+					c.Check(g.angle < glyphs[i-1].angle, check.Equals, true)
+				}
+			default:
+				mid = 0.1 + math.Pi + 0.49*math.Pi
+				turn = math.Pi
+				if i > 3 && i < 9 {
+					c.Check(g.angle > glyphs[i-1].angle, check.Equals, true)
+				}
+			}
+			if i == 9 {
+				// The straight label of the tiny block is on the top half.
+				mid, turn = 0.1+1.99*math.Pi-2*math.Pi, 0
+			}
+			c.Check(math.Abs(g.angle-mid) < 0.5, check.Equals, true, check.Commentf("glyph %d %q angle %v", i, g.str, g.angle))
+			want := math.Mod(g.angle-math.Pi/2+turn+2*math.Pi, 2*math.Pi)
+			c.Check(math.Abs(g.rot-want) < 1e-9, check.Equals, true, check.Commentf("glyph %d %q rotation %v", i, g.str, g.rot))
+		}
+		first, last := glyphs[0].angle, glyphs[2].angle
+		c.Check(math.Abs((first+last)/2-(0.1+math.Pi/2)) < 0.02, check.Equals, true)
+
+		// The names are included in the glyph box of the ring.
+		p, err := plot.New()
+		c.Assert(err, check.Equals, nil)
+		box := b.GlyphBoxes(p)[0].Rectangle
+		c.Check(box.Max.Y > 105, check.Equals, true)
+	}
+}
+
+func (s *S) TestTracePeakLabels(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	nan := math.NaN()
+	vals := [][]float64{
+		{1, 5, 2, nan, 7, 7, 3, 4, 1, 9},
+		{8, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+	var scores []rings.Scorer
+	for k, loc := range chr {
+		scores = append(scores, makeScorers(loc.(*fs), 10, 1, func(i, _ int) float64 { return vals[k][i] })...)
+	}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	type label struct {
+		str   string
+		angle float64
+		rad   float64
+	}
+	cen := vg.Point{150, 150}
+	render := func(p *rings.PeakLabels) []label {
+		// Radial placement ensures each label is rotated, so its
+		// anchor is recorded by the translation about it.
+		if p.Placement == nil {
+			p.Placement = rings.Radial
+		}
+		tr := &rings.Trace{LineStyles: []draw.LineStyle{{}}, Min: 0, Max: 10, PeakLabels: p}
+		sc, err := rings.NewScores(scores, b, 40, 60, tr)
+		c.Assert(err, check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var (
+			labels []label
+			at     vg.Point
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case push:
+				at = vg.Point{}
+			case translate:
+				if at == (vg.Point{}) {
+					at = vg.Point{a.x, a.y}
+				}
+			case fillString:
+				theta, rad := rings.Polar(at.Sub(cen))
+				labels = append(labels, label{
+					str:   a.str,
+					angle: math.Floor(float64(rings.Normalize(theta))/math.Pi*1000+0.5) / 1000,
+					rad:   float64(rad),
+				})
+			}
+		}
+		return labels
+	}
+	style := draw.TextStyle{Color: color.Black, Font: font}
+	strs := func(ls []label) []string {
+		var s []string
+		for _, l := range ls {
+			s = append(s, l.str)
+		}
+		return s
+	}
+
+	// Peaks are found within each location, ignoring the NaN score, and the
+	// plateau of 7 is labelled once at its centre.
+	ls := render(&rings.PeakLabels{TextStyle: style})
+	c.Check(strs(ls), check.DeepEquals, []string{"9", "8", "7", "5", "4"})
+	c.Check(ls[2].angle, check.Equals, 0.5)
+
+	// Labels are limited in number and value.
+	ls = render(&rings.PeakLabels{TextStyle: style, Count: 2})
+	c.Check(strs(ls), check.DeepEquals, []string{"9", "8", "7"})
+	ls = render(&rings.PeakLabels{TextStyle: style, Filter: func(v float64) bool { return v > 4.5 }})
+	c.Check(strs(ls), check.DeepEquals, []string{"9", "8", "7", "5"})
+	ls = render(&rings.PeakLabels{TextStyle: style, Window: 2})
+	c.Check(strs(ls), check.DeepEquals, []string{"9", "8", "7"})
+
+	// The label of a lower peak overlapping that of a higher peak is
+	// moved outwards.
+	ls = render(&rings.PeakLabels{TextStyle: style, Count: 1, Placement: rings.Tangential,
+		Format: func(v float64) string { return fmt.Sprintf("value %.0f", v) }})
+	c.Assert(strs(ls), check.DeepEquals, []string{"value 9", "value 8"})
+	c.Check(ls[1].rad > ls[0].rad, check.Equals, true, check.Commentf("radii %v %v", ls[0].rad, ls[1].rad))
+	ls = render(&rings.PeakLabels{TextStyle: style, Count: 1, Placement: rings.Tangential})
+	c.Assert(strs(ls), check.DeepEquals, []string{"9", "8"})
+	c.Check(ls[1].rad < ls[0].rad, check.Equals, true, check.Commentf("radii %v %v", ls[0].rad, ls[1].rad))
+}
+
+func (s *S) TestScoreTrack(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	vals := func(i, j int) float64 { return float64((i*7+j*3)%11) - 2 }
+	set := makeScorers(chr, 20, 2, vals)
+	track := &rings.ScoreTrack{Loc: chr}
+	for i, f := range set {
+		track.Starts = append(track.Starts, int32(f.Start()))
+		track.Ends = append(track.Ends, int32(f.End()))
+		track.Values = append(track.Values, []float64{vals(i, 0), vals(i, 1)})
+	}
+	c.Check(track.Len(), check.Equals, 20)
+	loc, start, end, scores := track.At(3)
+	c.Check(loc, check.Equals, feat.Feature(chr))
+	c.Check([]int{start, end}, check.DeepEquals, []int{150, 200})
+	c.Check(scores, check.DeepEquals, []float64{vals(3, 0), vals(3, 1)})
+
+	// A ScoreTrack is rendered as the equivalent Set of Scorers by each renderer.
+	sty := plotter.DefaultLineStyle
+	for _, renderer := range []func() rings.ScoreRenderer{
+		func() rings.ScoreRenderer {
+			return &rings.Trace{LineStyles: []draw.LineStyle{sty, sty}, Join: true}
+		},
+		func() rings.ScoreRenderer {
+			return &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()}
+		},
+	} {
+		want, err := rings.NewScores(set, b, 40, 75, renderer())
+		c.Assert(err, check.Equals, nil)
+		got, err := rings.NewScoresFrom(track, b, 40, 75, renderer())
+		c.Assert(err, check.Equals, nil)
+		c.Check(got.Validate(), check.Equals, nil)
+		gotMin, gotMax := got.ScoreRange()
+		wantMin, wantMax := want.ScoreRange()
+		c.Check([]float64{gotMin, gotMax}, check.DeepEquals, []float64{wantMin, wantMax})
+
+		render := func(r *rings.Scores) []interface{} {
+			tc := &canvas{dpi: defaultDPI}
+			r.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+			return tc.actions
+		}
+		c.Check(render(got), check.DeepEquals, render(want), check.Commentf("renderer %T", want.Renderer))
+	}
+
+	// Intervals of a Source are described by their location and coordinates.
+	r, err := rings.NewScoresFrom(track, b, 40, 75, &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()})
+	c.Assert(err, check.Equals, nil)
+	g, err := r.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(g.Scores, check.HasLen, 20)
+	c.Check(g.Scores[1].Name, check.Equals, "chr1:50-100")
+
+	// Columns of differing lengths are rejected.
+	track.Ends = track.Ends[:10]
+	_, err = rings.NewScoresFrom(track, b, 40, 75, &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()})
+	c.Check(err, check.ErrorMatches, "rings: score track column lengths differ")
+	c.Check(r.Validate(), check.ErrorMatches, "(?s).*score track column lengths differ.*")
+	_, err = rings.NewScoresFrom(nil, b, 40, 75, &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()})
+	c.Check(err, check.ErrorMatches, "rings: nil score source")
+}
+
+// scoreBins is the number of bins held by the score representations compared by
+// BenchmarkScorers and BenchmarkScoreTrack.
+const scoreBins = 1e6
+
+func BenchmarkScorers(b *testing.B) {
+	chr := &fs{start: 0, end: 100 * scoreBins, name: "chr1"}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		set := make([]rings.Scorer, scoreBins)
+		for i := range set {
+			set[i] = &fs{start: 100 * i, end: 100 * (i + 1), location: chr, scores: []float64{float64(i)}}
+		}
+	}
+}
+
+func BenchmarkScoreTrack(b *testing.B) {
+	chr := &fs{start: 0, end: 100 * scoreBins, name: "chr1"}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		t := &rings.ScoreTrack{
+			Loc:    chr,
+			Starts: make([]int32, scoreBins),
+			Ends:   make([]int32, scoreBins),
+			Values: make([][]float64, scoreBins),
+		}
+		values := make([]float64, scoreBins)
+		for i := range t.Starts {
+			t.Starts[i], t.Ends[i] = int32(100*i), int32(100*(i+1))
+			values[i] = float64(i)
+			t.Values[i] = values[i : i+1 : i+1]
+		}
+	}
+}
+
+func BenchmarkScoresDrawAt(b *testing.B) {
+	chr := &fs{start: 0, end: 100 * scoreBins, name: "chr1"}
+	t := &rings.ScoreTrack{
+		Loc:    chr,
+		Starts: make([]int32, scoreBins),
+		Ends:   make([]int32, scoreBins),
+		Values: make([][]float64, scoreBins),
+	}
+	values := make([]float64, scoreBins)
+	for i := range t.Starts {
+		t.Starts[i], t.Ends[i] = int32(100*i), int32(100*(i+1))
+		values[i] = float64(i)
+		t.Values[i] = values[i : i+1 : i+1]
+	}
+	base, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sc, err := rings.NewScoresFrom(t, base, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sc.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{150, 150})
+	}
+}
+
+func (s *S) TestPie(c *check.C) {
+	near := func(a, b rings.Angle) bool { return math.Abs(float64(a-b)) < 1e-6 }
+	colors := []color.Color{color.Gray{0x20}, color.Gray{0x80}, color.Gray{0xe0}}
+	p, err := rings.NewPie([]float64{1, 2, 1}, []string{"a", "b", "c"}, colors, 40, 80)
+	c.Assert(err, check.Equals, nil)
+	c.Assert(p.Blocks.Set, check.HasLen, 3)
+	c.Assert(p.Labels, check.NotNil)
+	c.Check(p.Plotters(), check.HasLen, 2)
+
+	// Sectors proceed clockwise from the top of the circle in proportion to their values.
+	theta := rings.Complete / 4
+	for i, share := range []float64{0.25, 0.5, 0.25} {
+		f := p.Blocks.Set[i]
+		arc, err := p.Blocks.ArcOf(f.Location(), f)
+		c.Assert(err, check.Equals, nil)
+		c.Check(near(arc.Theta, theta), check.Equals, true, check.Commentf("sector %d theta %v", i, arc.Theta))
+		c.Check(near(arc.Phi, rings.Angle(-share)*rings.Complete), check.Equals, true, check.Commentf("sector %d phi %v", i, arc.Phi))
+		c.Check(p.Blocks.ColorOf(f), check.Equals, colors[i])
+		theta += arc.Phi
+	}
+
+	// Percentage and outer labels are rendered when given a style.
+	font, err := vg.MakeFont("Helvetica", 5)
+	c.Assert(err, check.Equals, nil)
+	strs := func() []string {
+		tc := &canvas{dpi: defaultDPI}
+		p.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var strs []string
+		for _, a := range tc.actions {
+			if a, ok := a.(fillString); ok {
+				strs = append(strs, a.str)
+			}
+		}
+		return strs
+	}
+	c.Check(strs(), check.HasLen, 0)
+	p.Blocks.InnerLabel.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	p.Labels.TextStyle = draw.TextStyle{Color: color.Black, Font: font}
+	c.Check(strs(), check.DeepEquals, []string{"25%", "50%", "25%", "a", "b", "c"})
+
+	// The glyph boxes of the Pie are those of its Blocks and Labels.
+	plt, err := plot.New()
+	c.Assert(err, check.Equals, nil)
+	want := append(p.Blocks.GlyphBoxes(plt), p.Labels.GlyphBoxes(plt)...)
+	got := p.GlyphBoxes(plt)
+	c.Assert(got, check.HasLen, 2)
+	for i := range got {
+		c.Check(got[i].Rectangle, check.Equals, want[i].Rectangle)
+	}
+
+	// Further rings may be based on the sectors.
+	sc, err := rings.NewScores(
+		makeScorers(&fs{start: p.Blocks.Set[1].Start(), end: p.Blocks.Set[1].End(), location: p.Blocks.Set[1]}, 4, 1, func(i, _ int) float64 { return float64(i) }),
+		p.Blocks, 20, 35, &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()},
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	// Without labels or colors the sectors are unlabelled and take the color of the Blocks.
+	p, err = rings.NewPie([]float64{3, 1}, nil, nil, 40, 80)
+	c.Assert(err, check.Equals, nil)
+	c.Check(p.Labels, check.IsNil)
+	c.Check(p.Plotters(), check.HasLen, 1)
+	p.Blocks.Color = color.Gray{0x40}
+	c.Check(p.Blocks.ColorOf(p.Blocks.Set[0]), check.Equals, color.Color(color.Gray{0x40}))
+
+	for _, t := range []struct {
+		values []float64
+		labels []string
+		colors []color.Color
+		err    string
+	}{
+		{values: nil, err: "rings: no pie values"},
+		{values: []float64{1, 0}, err: "rings: pie value 1 not positive: 0"},
+		{values: []float64{-1, 2}, err: "rings: pie value 0 not positive: -1"},
+		{values: []float64{1, math.NaN()}, err: "rings: pie value 1 not positive: NaN"},
+		{values: []float64{1, math.Inf(1)}, err: "rings: pie value 1 not positive: \\+Inf"},
+		{values: []float64{1, 2}, labels: []string{"a"}, err: "rings: 1 pie labels for 2 values"},
+		{values: []float64{1, 2}, colors: colors, err: "rings: 3 pie colors for 2 values"},
+	} {
+		_, err := rings.NewPie(t.values, t.labels, t.colors, 40, 80)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
+
+func (s *S) TestNestedBlocks(c *check.C) {
+	near := func(a, b rings.Arc) bool {
+		return math.Abs(float64(a.Theta-b.Theta)) < 1e-9 && math.Abs(float64(a.Phi-b.Phi)) < 1e-9
+	}
+	chr := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 500, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 90, 0.1)
+	c.Assert(err, check.Equals, nil)
+	b.Zooms = []rings.Zoom{{Location: chr[0], Start: 300, End: 500, Scale: 2}}
+
+	// Arms are held by their chromosomes, so are mapped by the parent
+	// Blocks with its gaps and zooms.
+	arms := []feat.Feature{
+		&fs{start: 0, end: 400, name: "1p", location: chr[0]},
+		&fs{start: 400, end: 1000, name: "1q", location: chr[0]},
+		&fs{start: 0, end: 200, name: "2p", location: chr[1]},
+		&fs{start: 200, end: 500, name: "2q", location: chr[1]},
+	}
+	a, err := rings.NewNestedBlocks(b, map[feat.Feature][]feat.Feature{
+		chr[1]: arms[2:],
+		chr[0]: arms[:2],
+	}, 92, 100)
+	c.Assert(err, check.Equals, nil)
+	c.Check(a.Set, check.DeepEquals, arms)
+	for _, f := range arms {
+		got, err := a.ArcOf(f.Location(), f)
+		c.Assert(err, check.Equals, nil)
+		want, err := b.ArcOf(f.Location(), f)
+		c.Assert(err, check.Equals, nil)
+		c.Check(near(got, want), check.Equals, true, check.Commentf("arm %s: got %v want %v", f.Name(), got, want))
+	}
+
+	// Bands without locations are mapped linearly across the arcs of their arms.
+	bands := []feat.Feature{
+		&fs{start: 0, end: 100, name: "2p1"},
+		&fs{start: 100, end: 200, name: "2p2"},
+	}
+	n, err := rings.NewNestedBlocks(a, map[feat.Feature][]feat.Feature{arms[2]: bands}, 102, 110)
+	c.Assert(err, check.Equals, nil)
+	pa, err := a.ArcOf(nil, arms[2])
+	c.Assert(err, check.Equals, nil)
+	for i, f := range bands {
+		got, err := n.ArcOf(nil, f)
+		c.Assert(err, check.Equals, nil)
+		want := rings.Arc{pa.Theta + rings.Angle(i)*pa.Phi/2, pa.Phi / 2}
+		c.Check(near(got, want), check.Equals, true, check.Commentf("band %s: got %v want %v", f.Name(), got, want))
+	}
+
+	// Children extending beyond their parent are clamped to the parent's arc.
+	over := &fs{start: 100, end: 300, name: "2p3"}
+	n, err = rings.NewNestedBlocks(a, map[feat.Feature][]feat.Feature{arms[2]: {over}}, 102, 110)
+	c.Assert(err, check.Equals, nil)
+	got, err := n.ArcOf(nil, over)
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(got, rings.Arc{pa.Theta + pa.Phi/2, pa.Phi / 2}), check.Equals, true, check.Commentf("got %v", got))
+
+	// or rejected if the boundary policy is Strict.
+	strict := rings.Arcs{Base: rings.Arc{0, rings.Complete}, Arcs: map[feat.Feature]rings.Arc{
+		chr[1]: {0, rings.Complete},
+	}, Boundary: rings.Strict}
+	sb, err := rings.NewBlocks(chr[1:], strict, 80, 90)
+	c.Assert(err, check.Equals, nil)
+	_, err = rings.NewNestedBlocks(sb, map[feat.Feature][]feat.Feature{
+		chr[1]: {&fs{start: 400, end: 600, name: "2q", location: chr[1]}},
+	}, 92, 100)
+	c.Check(err, check.ErrorMatches, `rings: feature "2q" extends beyond end of location "chr2"`)
+
+	// Parents must be held by the parent Blocks and children may have only one parent.
+	_, err = rings.NewNestedBlocks(a, map[feat.Feature][]feat.Feature{chr[0]: bands}, 102, 110)
+	c.Check(err, check.ErrorMatches, `rings: nested parent "chr1" not held by parent blocks`)
+	_, err = rings.NewNestedBlocks(a, map[feat.Feature][]feat.Feature{arms[2]: bands, arms[3]: bands[:1]}, 102, 110)
+	c.Check(err, check.ErrorMatches, `rings: nested feature "2p1" has parents "2p" and "2q"`)
+
+	// Nested Blocks render their children with their own styles.
+	tc := &canvas{dpi: defaultDPI}
+	a.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var strokes int
+	for _, act := range tc.actions {
+		if _, ok := act.(stroke); ok {
+			strokes++
+		}
+	}
+	c.Check(strokes, check.Equals, 0)
+	for _, f := range arms {
+		f.(*fs).style = plotter.DefaultLineStyle
+	}
+	tc = &canvas{dpi: defaultDPI}
+	a.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	for _, act := range tc.actions {
+		if _, ok := act.(stroke); ok {
+			strokes++
+		}
+	}
+	c.Check(strokes, check.Equals, len(arms))
+}
+
+func (s *S) TestBrush(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 500, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 90, 0.1)
+	c.Assert(err, check.Equals, nil)
+	b.Zooms = []rings.Zoom{{Location: chr[0], Start: 300, End: 500, Scale: 2}}
+	sc, err := rings.NewScores(makeScorers(chr[0].(*fs), 4, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 75, &rings.Heat{Palette: palette.Radial(10, palette.Cyan, palette.Magenta, 1).Colors()})
+	c.Assert(err, check.Equals, nil)
+
+	// The radii of rings are collected for brushing all tracks.
+	radii := rings.RingRadii(b, sc, rings.NewHighlight(color.Black, rings.Arc{0, 1}, 10, 20))
+	c.Check(radii, check.DeepEquals, []rings.RadialInterval{{Inner: 80, Outer: 90}, {Inner: 40, Outer: 75}})
+
+	// The brushed interval follows the zoomed mapping of the base.
+	br, err := rings.NewBrush(b, chr[0], 250, 550, color.NRGBA{R: 0xff, A: 0x40}, append(radii, rings.RadialInterval{Inner: 70, Outer: 82}, rings.RadialInterval{Inner: 100, Outer: 105})...)
+	c.Assert(err, check.Equals, nil)
+	c.Check(br.Validate(), check.Equals, nil)
+	want, err := b.ArcOf(chr[0], &fs{start: 250, end: 550, location: chr[0]})
+	c.Assert(err, check.Equals, nil)
+	c.Check(br.Arc(), check.Equals, want)
+
+	// Overlapping intervals are filled once as their union, and a single border
+	// is drawn around the extent of the intervals.
+	br.LineStyle = plotter.DefaultLineStyle
+	tc := &canvas{dpi: defaultDPI}
+	br.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var fills []vg.Path
+	var strokes []vg.Path
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case fill:
+			fills = append(fills, a.path)
+		case stroke:
+			strokes = append(strokes, a.path)
+		}
+	}
+	radius := func(pa vg.Path) (min, max vg.Length) {
+		min, max = vg.Length(math.Inf(1)), 0
+		for _, pc := range pa {
+			if pc.Type == vg.ArcComp {
+				min = vg.Length(math.Min(float64(min), float64(pc.Radius)))
+				max = vg.Length(math.Max(float64(max), float64(pc.Radius)))
+			}
+		}
+		return min, max
+	}
+	c.Assert(fills, check.HasLen, 2)
+	for i, w := range []rings.RadialInterval{{Inner: 40, Outer: 90}, {Inner: 100, Outer: 105}} {
+		min, max := radius(fills[i])
+		c.Check(rings.RadialInterval{Inner: min, Outer: max}, check.Equals, w)
+	}
+	c.Assert(strokes, check.HasLen, 1)
+	min, max := radius(strokes[0])
+	c.Check(rings.RadialInterval{Inner: min, Outer: max}, check.Equals, rings.RadialInterval{Inner: 40, Outer: 105})
+
+	for _, t := range []struct {
+		loc        feat.Feature
+		start, end int
+		err        string
+	}{
+		{loc: chr[0], start: 20, end: 10, err: "rings: inverted brush"},
+		{loc: chr[0], start: 20, end: 20, err: "rings: zero length brush"},
+		{loc: nil, start: 0, end: 10, err: "rings: nil brush location"},
+		{loc: &fs{start: 0, end: 10, name: "chr3"}, start: 0, end: 10, err: "rings: location not found"},
+	} {
+		_, err := rings.NewBrush(b, t.loc, t.start, t.end, color.Black)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+	br.End = br.Start
+	c.Check(br.Validate(), check.ErrorMatches, "(?s).*zero length brush.*")
+	c.Check(math.IsNaN(float64(br.Arc().Theta)), check.Equals, true)
+}
+
+func (s *S) TestMirrored(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 90, 0)
+	c.Assert(err, check.Equals, nil)
+	a := makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) })
+	d := makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(2 * i) })
+
+	sty := plotter.DefaultLineStyle
+	axis := &rings.Axis{
+		LineStyle: sty,
+		Tick:      rings.TickConfig{Marker: plot.DefaultTicks{}, LineStyle: sty, Length: 2},
+	}
+	m, err := rings.NewMirrored(a, d, b, 20, 60, 0.75,
+		&rings.Trace{LineStyles: []draw.LineStyle{sty}, Axis: axis},
+		&rings.Trace{LineStyles: []draw.LineStyle{sty}},
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(m.Inward.Invert, check.Equals, true)
+	c.Check(m.Plotters(), check.HasLen, 2)
+
+	// Both series share a range and are scaled away from the baseline at 50.
+	min, max := m.Outward.ScoreRange()
+	c.Check([]float64{min, max}, check.DeepEquals, []float64{0, 6})
+	min, max = m.Inward.ScoreRange()
+	c.Check([]float64{min, max}, check.DeepEquals, []float64{0, 6})
+	radii := func(r *rings.Scores) []float64 {
+		g, err := r.Describe()
+		c.Assert(err, check.Equals, nil)
+		var rad []float64
+		for _, s := range g.Scores {
+			rad = append(rad, *s.Radii[0])
+		}
+		return rad
+	}
+	c.Check(radii(m.Outward), check.DeepEquals, []float64{50, 50 + 10.0/6, 50 + 20.0/6, 55})
+	c.Check(radii(m.Inward), check.DeepEquals, []float64{50, 40, 30, 20})
+
+	// The axis of the outward trace is mirrored to the inner radius of the track.
+	c.Check(axis.MirrorTo, check.Equals, vg.Length(20))
+	ticks := axis.Ticks(0, 6, 50, 60)
+	var out, in []float64
+	for _, t := range ticks {
+		if t.Minor {
+			continue
+		}
+		if t.Mirrored {
+			in = append(in, float64(t.Radius))
+		} else {
+			out = append(out, float64(t.Radius))
+		}
+	}
+	c.Check(out, check.DeepEquals, []float64{50, 50 + 10.0/3, 50 + 20.0/3, 60})
+	c.Assert(in, check.HasLen, 3)
+	for i, want := range []float64{40, 30, 20} {
+		c.Check(math.Abs(in[i]-want) < 1e-9, check.Equals, true, check.Commentf("mirrored tick %d radius %v", i, in[i]))
+	}
+
+	tc := &canvas{dpi: defaultDPI}
+	m.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var mirrorLine bool
+	for _, act := range tc.actions {
+		if st, ok := act.(stroke); ok && len(st.path) == 2 && st.path[0].Pos == (vg.Point{170, 150}) && st.path[1].Pos == (vg.Point{200, 150}) {
+			mirrorLine = true
+		}
+	}
+	c.Check(mirrorLine, check.Equals, true)
+
+	_, err = rings.NewMirrored(a, d, b, 20, 60, 1.5, &rings.Trace{}, &rings.Trace{})
+	c.Check(err, check.ErrorMatches, `rings: mirror baseline 1.5 not in \[0, 1\]`)
+	axis.MirrorTo = -1
+	c.Check(axis.Validate(), check.ErrorMatches, "(?s).*negative axis mirror radius -1.*")
+}
+
+func (s *S) TestGradientFill(c *check.C) {
+	black, white := color.Gray{0}, color.Gray{0xff}
+	g := rings.GradientFill{Inner: black, Outer: white}
+	gray := func(col color.Color) uint8 { return color.GrayModel.Convert(col).(color.Gray).Y }
+	c.Check(gray(g.At(0)), check.Equals, uint8(0))
+	c.Check(gray(g.At(0.5)), check.Equals, uint8(0x80))
+	c.Check(gray(g.At(1)), check.Equals, uint8(0xff))
+	g.Ease = func(t float64) float64 { return t * t }
+	c.Check(gray(g.At(0.5)), check.Equals, uint8(0x40))
+
+	// Blocks are filled by overlapping slices from the inner to the outer radius.
+	chr := &fs{start: 0, end: 100, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete / 4}, 40, 60, 0,
+		rings.BlockGradient(rings.GradientFill{Inner: black, Outer: white, Slices: 4}))
+	c.Assert(err, check.Equals, nil)
+	c.Check(b.Validate(), check.Equals, nil)
+	tc := &canvas{dpi: defaultDPI}
+	b.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var (
+		shades       []uint8
+		inner, outer []vg.Length
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case setColor:
+			shades = append(shades, gray(a.col))
+		case fill:
+			inner = append(inner, a.path[0].Pos.X-150)
+			outer = append(outer, a.path[2].Radius)
+		}
+	}
+	c.Check(shades, check.DeepEquals, []uint8{0x20, 0x60, 0x9f, 0xdf})
+	c.Check(inner, check.DeepEquals, []vg.Length{40, 45, 50, 55})
+	c.Check(outer, check.DeepEquals, []vg.Length{45.5, 50.5, 55.5, 60})
+
+	// Blocks of FillColorers keep their own color.
+	red := color.RGBA{R: 0xff, A: 0xff}
+	b.Set = []feat.Feature{colorFeature{&fs{start: 10, end: 20, name: "f", location: chr}, red}}
+	tc = &canvas{dpi: defaultDPI}
+	b.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var fills int
+	for _, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills++
+		}
+	}
+	c.Check(fills, check.Equals, 1)
+
+	_, err = rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 40, 60, 0, rings.BlockGradient(rings.GradientFill{Slices: -1}))
+	c.Check(err, check.ErrorMatches, "rings: negative gradient slice count")
+
+	// Highlights use DefaultGradientSlices when Slices is zero.
+	h := rings.NewHighlight(nil, rings.Arc{0, rings.Complete / 4}, 40, 60)
+	h.Gradient = &rings.GradientFill{Inner: black, Outer: color.Transparent}
+	tc = &canvas{dpi: defaultDPI}
+	h.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	fills = 0
+	for _, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills++
+		}
+	}
+	c.Check(fills, check.Equals, rings.DefaultGradientSlices)
+	h.Gradient.Slices = -2
+	c.Check(h.Validate(), check.ErrorMatches, "(?s).*negative gradient slice count -2.*")
+
+	// Smoothed heat cells blend towards their neighbours.
+	base, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	heat := &rings.Heat{Palette: []color.Color{black, white}, Smooth: 2}
+	sc, err := rings.NewScores(makeScorers(chr, 2, 2, func(i, j int) float64 { return float64(j + i) }), base, 40, 60, heat, rings.ScoreMinMax(0, 1))
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.Equals, nil)
+	tc = &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	shades = shades[:0]
+	for _, a := range tc.actions {
+		if a, ok := a.(setColor); ok {
+			shades = append(shades, gray(a.col))
+		}
+	}
+	c.Check(shades[:4], check.DeepEquals, []uint8{0, 0x40, 0xc0, 0xff})
+	var fills0 int
+	for _, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills0++
+		}
+	}
+
+	// Inverted smoothed heat cells blend inwards from the outer radius.
+	sc.Invert = true
+	tc = &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	shades = shades[:0]
+	fills = 0
+	min, max := vg.Length(math.Inf(1)), vg.Length(math.Inf(-1))
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case setColor:
+			shades = append(shades, gray(a.col))
+		case fill:
+			fills++
+			for _, p := range a.path {
+				if p.Type == vg.ArcComp {
+					min, max = vg.Length(math.Min(float64(min), float64(p.Radius))), vg.Length(math.Max(float64(max), float64(p.Radius)))
+				}
+			}
+		}
+	}
+	c.Check(fills, check.Equals, fills0)
+	c.Check(shades[:4], check.DeepEquals, []uint8{0, 0x40, 0xc0, 0xff})
+	c.Check(min >= 40 && max <= 60, check.Equals, true, check.Commentf("radii [%v, %v]", min, max))
+	sc.Invert = false
+	heat.Smooth = -1
+	c.Check(sc.Validate(), check.ErrorMatches, "(?s).*negative heat smoothing slice count -1.*")
+}
+
+func (s *S) TestLabelPriority(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	genes := []feat.Feature{
+		&fs{start: 10, end: 12, name: "a", location: chr[0]},
+		&fs{start: 11, end: 13, name: "b", location: chr[0]},
+		&fs{start: 60, end: 62, name: "c", location: chr[0]},
+		&fs{start: 20, end: 22, name: "d", location: chr[1]},
+		&fs{start: 70, end: 72, name: "e", location: chr[1]},
+	}
+	priority := map[string]float64{"a": 1, "b": 5, "c": 3, "d": 4, "e": 2}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+
+	names := func(fs []feat.Feature) []string {
+		var n []string
+		for _, f := range fs {
+			n = append(n, f.Name())
+		}
+		return n
+	}
+	for _, t := range []struct {
+		max, perLocation int
+		want             []string
+	}{
+		// Label a overlaps the higher priority label b.
+		{want: []string{"b", "d", "c", "e"}},
+		{max: 3, want: []string{"b", "d", "c"}},
+		{perLocation: 1, want: []string{"b", "d"}},
+	} {
+		l, err := rings.NewLabelsWith(b, 110, rings.NameLabels(genes),
+			rings.LabelTextStyle(draw.TextStyle{Color: color.Black, Font: font}),
+			rings.LabelPriority(func(f feat.Feature) float64 { return priority[f.Name()] }, t.max, t.perLocation),
+		)
+		c.Assert(err, check.Equals, nil)
+		c.Check(l.Validate(), check.Equals, nil)
+
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var drawn []string
+		for _, a := range tc.actions {
+			if a, ok := a.(fillString); ok {
+				drawn = append(drawn, a.str)
+			}
+		}
+		c.Check(drawn, check.DeepEquals, t.want)
+		c.Check(names(l.Placed()), check.DeepEquals, t.want)
+
+		g, err := l.Describe()
+		c.Assert(err, check.Equals, nil)
+		c.Check(g.Labels, check.HasLen, len(t.want))
+	}
+
+	// Without pruning every label is drawn in order.
+	l, err := rings.NewLabelsWith(b, 110, rings.NameLabels(genes), rings.LabelTextStyle(draw.TextStyle{Color: color.Black, Font: font}))
+	c.Assert(err, check.Equals, nil)
+	l.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{150, 150})
+	c.Check(names(l.Placed()), check.DeepEquals, []string{"a", "b", "c", "d", "e"})
+
+	// Modifying the returned slice does not alter the placed labels.
+	placed := l.Placed()
+	placed[0] = nil
+	c.Check(names(l.Placed()), check.DeepEquals, []string{"a", "b", "c", "d", "e"})
+
+	_, err = rings.NewLabelsWith(b, 110, rings.NameLabels(genes), rings.LabelPriority(nil, -1, 0))
+	c.Check(err, check.ErrorMatches, "rings: negative maximum label count")
+	l.MaxPerLocation = -1
+	c.Check(l.Validate(), check.ErrorMatches, "rings: negative maximum label count per location -1")
+}
+
+func (s *S) TestFilteredBlocks(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+		&fs{start: 0, end: 100, name: "chr3"},
+	}
+	keep := func(loc feat.Feature) bool { return loc.Name() != "chr2" }
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0, rings.BlockKeep(keep, 20))
+	c.Assert(err, check.Equals, nil)
+	c.Check(b.Validate(), check.Equals, nil)
+
+	// The kept locations and the stub share the arc.
+	var names []string
+	for _, f := range b.Set {
+		names = append(names, f.Name())
+	}
+	c.Check(names, check.DeepEquals, []string{"chr1", "chr3", "other"})
+	arc, err := b.ArcOf(nil, chr[2])
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(float64(arc.Theta), float64(rings.Complete*100/220)), check.Equals, true)
+	arc, err = b.ArcOf(nil, b.Other)
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(float64(arc.Phi), float64(rings.Complete*20/220)), check.Equals, true)
+	gene := &fs{start: 10, end: 20, name: "gene", location: chr[1]}
+	_, err = b.ArcOf(chr[1], gene)
+	c.Check(err, check.FitsTypeOf, &rings.FilteredError{})
+	c.Check(err, check.ErrorMatches, `rings: location "chr2" filtered`)
+	c.Check(b.Filtered(gene), check.Equals, true)
+
+	// Scores held by the filtered location are skipped.
+	sc, err := rings.NewScores(append(append(
+		makeScorers(chr[0].(*fs), 2, 1, func(i, _ int) float64 { return float64(i) }),
+		makeScorers(chr[1].(*fs), 2, 1, func(i, _ int) float64 { return 100 })...),
+		makeScorers(chr[2].(*fs), 2, 1, func(i, _ int) float64 { return float64(i + 2) })...),
+		b, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}})
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.SkipFiltered, check.Equals, true)
+	c.Check(sc.Validate(), check.Equals, nil)
+	min, max := sc.ScoreRange()
+	c.Check([]float64{min, max}, check.DeepEquals, []float64{0, 3})
+	g, err := sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Scores, check.HasLen, 4)
+	sc.SkipFiltered = false
+	c.Check(sc.Validate(), check.ErrorMatches, `(?s).*location "chr2" filtered.*`)
+
+	// Labels of the filtered location are skipped.
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	l, err := rings.NewLabelsWith(b, 110, rings.NameLabels(chr), rings.LabelTextStyle(draw.TextStyle{Color: color.Black, Font: font}))
+	c.Assert(err, check.Equals, nil)
+	c.Check(l.Validate(), check.Equals, nil)
+	l.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{150, 150})
+	names = names[:0]
+	for _, f := range l.Placed() {
+		names = append(names, f.Name())
+	}
+	c.Check(names, check.DeepEquals, []string{"chr1", "chr3"})
+
+	// Links with a filtered end are skipped or drawn to the stub.
+	link := func(l0, l1 int) fp {
+		return fp{
+			feats: [2]*fs{
+				{start: 10, end: 20, name: "a", location: chr[l0], style: plotter.DefaultLineStyle},
+				{start: 10, end: 20, name: "b", location: chr[l1], style: plotter.DefaultLineStyle},
+			},
+			sty: plotter.DefaultLineStyle,
+		}
+	}
+	lk, err := rings.NewLinks([]rings.Pair{link(0, 2), link(0, 1), link(1, 1)}, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	c.Check(lk.SkipFiltered, check.Equals, true)
+	c.Check(lk.Validate(), check.Equals, nil)
+	g, err = lk.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Links, check.HasLen, 1)
+	lk.ToOther = true
+	g, err = lk.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(g.Links, check.HasLen, 2)
+	arc, err = b.ArcOf(nil, b.Other)
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(g.Links[1].Angles[1], float64(rings.Normalize(arc.Theta))), check.Equals, true)
+
+	// Ribbons with a filtered end are skipped.
+	ribbon := func(l0, l1 int) rings.Pair {
+		return vp{feats: [2]feat.Feature{
+			&fs{start: 10, end: 20, location: chr[l0]},
+			&fs{start: 10, end: 20, location: chr[l1]},
+		}}
+	}
+	rb, err := rings.NewRibbons([]rings.Pair{ribbon(0, 2), ribbon(0, 1), ribbon(1, 1)}, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	c.Check(rb.SkipFiltered, check.Equals, true)
+	c.Check(rb.Validate(), check.Equals, nil)
+	rb.Color = color.Black
+	tc := &canvas{dpi: defaultDPI}
+	rb.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var fills int
+	for _, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills++
+		}
+	}
+	c.Check(fills, check.Equals, 1)
+	rb.SkipFiltered = false
+	c.Check(rb.Validate(), check.ErrorMatches, `(?s).*location "chr2" filtered.*`)
+
+	// Filtering applies through ArcOfers that map arcs through the Blocks.
+	lens := rings.Lens{ArcOfer: b, Optics: func(_, _ feat.Feature, _, arc rings.Arc) (rings.Arc, error) { return arc, nil }}
+	sc, err = rings.NewScores(sc.Set, lens, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}})
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.SkipFiltered, check.Equals, true)
+	c.Check(sc.Validate(), check.Equals, nil)
+	lk, err = rings.NewLinks([]rings.Pair{link(0, 2), link(1, 0)}, [2]rings.ArcOfer{lens, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	lk.ToOther = true
+	g, err = lk.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Links, check.HasLen, 2)
+
+	// A stub requires the arcs to be allocated by NewGappedBlocks.
+	_, err = rings.NewBlocks(chr, rings.NewGappedArcs(rings.Arc{0, rings.Complete}, chr, 0), 80, 100, rings.BlockKeep(keep, 20))
+	c.Check(err, check.ErrorMatches, "rings: other stub requires allocated arcs")
+	_, err = rings.NewBlocks(chr, rings.NewGappedArcs(rings.Arc{0, rings.Complete}, chr, 0), 80, 100, rings.BlockKeep(nil, 0))
+	c.Check(err, check.ErrorMatches, "rings: nil keep function")
+}
+
+func (s *S) TestResize(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	b.Offset = func(feat.Feature) vg.Length { return 1 }
+	b.Title.Gap = 3
+	before, err := b.Describe()
+	c.Assert(err, check.Equals, nil)
+
+	axis := &rings.Axis{Tick: rings.TickConfig{Length: 2, LabelOffset: 4}}
+	sc, err := rings.NewScores(makeScorers(chr[0].(*fs), 2, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 60,
+		&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Axis: axis})
+	c.Assert(err, check.Equals, nil)
+
+	bez := &rings.Bezier{Segments: 5, Radius: rings.LengthDist{Length: 10}}
+	var links []*rings.Links
+	for i := 0; i < 2; i++ {
+		l, err := rings.NewLinks(nil, [2]rings.ArcOfer{b, b}, [2]vg.Length{35, 35})
+		c.Assert(err, check.Equals, nil)
+		l.Bezier = bez
+		links = append(links, l)
+	}
+	h := rings.NewHighlight(color.Black, rings.Arc{0, rings.Complete / 4}, 20, 30)
+	h.Offset = func(feat.Feature) vg.Length { return 5 }
+	lb, err := rings.NewLabels(b, 110, rings.NameLabels(chr)...)
+	c.Assert(err, check.Equals, nil)
+
+	lay := rings.NewLayered(b, sc, links[0], links[1], h, lb)
+	c.Assert(lay.Resize(2), check.Equals, nil)
+
+	c.Check([]vg.Length{b.Inner, b.Outer, b.Offset(chr[0]), b.Title.Gap}, check.DeepEquals, []vg.Length{160, 200, 2, 6})
+	c.Check([]vg.Length{sc.Inner, sc.Outer}, check.DeepEquals, []vg.Length{80, 120})
+	c.Check([]vg.Length{axis.Tick.Length, axis.Tick.LabelOffset}, check.DeepEquals, []vg.Length{4, 8})
+	c.Check(links[0].Radii, check.Equals, [2]vg.Length{70, 70})
+	c.Check(links[1].Radii, check.Equals, [2]vg.Length{70, 70})
+	// The shared Bezier is scaled once.
+	c.Check(bez.Radius.Length, check.Equals, vg.Length(20))
+	c.Check([]vg.Length{h.Inner, h.Outer, h.Offset(nil)}, check.DeepEquals, []vg.Length{40, 60, 10})
+	c.Check(lb.Radius, check.Equals, vg.Length(220))
+
+	// The track of a Hashed is resized.
+	c.Assert(rings.Resize(0.5, &rings.Hashed{Plotter: h, Key: 1}), check.Equals, nil)
+	c.Check([]vg.Length{h.Inner, h.Outer, h.Offset(nil)}, check.DeepEquals, []vg.Length{20, 30, 5})
+
+	// Angles are unchanged.
+	after, err := b.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(after.Blocks, check.HasLen, len(before.Blocks))
+	for i := range after.Blocks {
+		c.Check(after.Blocks[i].Arc, check.Equals, before.Blocks[i].Arc)
+	}
+
+	c.Check(rings.Resize(0, b), check.ErrorMatches, "rings: resize factor 0 not positive and finite")
+	c.Check(rings.Resize(math.Inf(1), b), check.ErrorMatches, `rings: resize factor \+Inf not positive and finite`)
+}
+
+func (s *S) TestHeatRaster(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 1000, name: "chr1"},
+		&fs{start: 0, end: 1000, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{rings.Complete / 4, rings.Clockwise * rings.Complete}, 110, 120, 0.02)
+	c.Assert(err, check.Equals, nil)
+	var scorers []rings.Scorer
+	for i, loc := range chr {
+		scorers = append(scorers, makeScorers(loc.(*fs), 50, 4, func(k, j int) float64 {
+			return math.Mod(float64(k*7+j*3+i*5), 11)
+		})...)
+	}
+	heat := func(dpi float64) *rings.Scores {
+		sc, err := rings.NewScores(scorers, b, 40, 100, &rings.Heat{Ramp: ramp.Greys(), Rasterize: dpi}, rings.ScoreMinMax(0, 10))
+		c.Assert(err, check.Equals, nil)
+		return sc
+	}
+	c.Check(heat(144).Validate(), check.Equals, nil)
+
+	// The rasterized cells are drawn as a single image.
+	tc := &canvas{dpi: defaultDPI}
+	heat(144).DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var images, fills int
+	for _, a := range tc.actions {
+		switch a.(type) {
+		case drawImage:
+			images++
+		case fill:
+			fills++
+		}
+	}
+	c.Check(images, check.Equals, 1)
+	c.Check(fills, check.Equals, 0)
+
+	// The raster matches the vector rendering away from cell boundaries.
+	vector := render(goldenPlot(c, heat(0)))
+	raster := render(goldenPlot(c, heat(144)))
+	var painted int
+	for i := 3; i < len(vector.Pix); i += 4 {
+		if vector.Pix[i] != 0 {
+			painted++
+		}
+	}
+	c.Assert(painted > 0, check.Equals, true)
+	diff := diffPixels(raster, vector)
+	c.Check(float64(diff)/float64(painted) < 0.02, check.Equals, true, check.Commentf("%d of %d pixels differ", diff, painted))
+
+	// Inverted heats are rasterized from the outer radius inwards.
+	inverted := func(dpi float64) *rings.Scores {
+		sc := heat(dpi)
+		sc.Invert = true
+		return sc
+	}
+	vector = render(goldenPlot(c, inverted(0)))
+	raster = render(goldenPlot(c, inverted(144)))
+	c.Check(diffPixels(vector, render(goldenPlot(c, heat(0)))) > painted/10, check.Equals, true)
+	diff = diffPixels(raster, vector)
+	c.Check(float64(diff)/float64(painted) < 0.02, check.Equals, true, check.Commentf("%d of %d pixels differ", diff, painted))
+
+	sc := heat(-1)
+	c.Check(sc.Validate(), check.ErrorMatches, "(?s).*negative heat raster resolution -1.*")
+}
+
+func (s *S) TestBounds(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0.01)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.Black
+	genes := []feat.Feature{
+		&fs{start: 0, end: 2, name: "longest gene name", location: chr[0]},
+		&fs{start: 50, end: 52, name: "west", location: chr[0]},
+	}
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	l, err := rings.NewLabelsWith(b, 105, rings.NameLabels(genes),
+		rings.LabelTextStyle(draw.TextStyle{Color: color.Black, Font: font}),
+	)
+	c.Assert(err, check.Equals, nil)
+	l.Placement = rings.Horizontal
+
+	c.Check(rings.Bounds(), check.Equals, vg.Rectangle{})
+	box := rings.Bounds(b)
+	c.Check(box, check.Equals, vg.Rectangle{Min: vg.Point{-100, -100}, Max: vg.Point{100, 100}})
+	box = rings.Bounds(rings.NewLayered(b, l))
+	c.Check(box, check.DeepEquals, rings.Bounds(b, l))
+	w, _ := rings.TextBounds(draw.TextStyle{Font: font}, "longest gene name")
+	c.Check(box.Max.X >= 100+w, check.Equals, true, check.Commentf("%v", box))
+
+	// The fitted rendering is tightly cropped within the margin.
+	const margin = 5
+	cw, ch, cen := rings.Fit(margin, b, l)
+	c.Check(cw >= box.Max.X-box.Min.X+2*margin, check.Equals, true)
+	c.Check(ch >= box.Max.Y-box.Min.Y+2*margin, check.Equals, true)
+	cv := vgimg.NewWith(vgimg.UseWH(cw, ch), vgimg.UseDPI(72))
+	rings.DrawFitted(draw.New(cv), cen, rings.NewLayered(l, b))
+	img := cv.Image()
+	r := img.Bounds()
+	painted := image.Rectangle{Min: r.Max, Max: r.Min}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r&g&b != 0xffff {
+				painted = painted.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	c.Assert(painted.Empty(), check.Equals, false)
+	// Text is measured by line height, so vertical gaps may include
+	// the space above and below glyphs.
+	slack := int(font.Extents().Height)
+	for _, t := range []struct{ gap, slack int }{
+		{gap: painted.Min.X - r.Min.X, slack: 2},
+		{gap: r.Max.X - painted.Max.X, slack: 2},
+		{gap: painted.Min.Y - r.Min.Y, slack: slack},
+		{gap: r.Max.Y - painted.Max.Y, slack: slack},
+	} {
+		c.Check(margin-1 <= t.gap && t.gap <= margin+t.slack, check.Equals, true, check.Commentf("painted %v in %v", painted, r))
+	}
+}
+
+func (s *S) TestAnnotatePeaks(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 110, 120, 0)
+	c.Assert(err, check.Equals, nil)
+	vals := []float64{1, 3, 9, 2, 1, 7, 1, 2, 1, 1}
+	scorers := makeScorers(chr, len(vals), 1, func(i, _ int) float64 { return vals[i] })
+	sc, err := rings.NewScores(scorers, b, 50, 100, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+
+	_, err = rings.AnnotatePeaks(sc, nil)
+	c.Check(err, check.ErrorMatches, "rings: nil peak selector")
+	font, err := vg.MakeFont("Helvetica", 8)
+	c.Assert(err, check.Equals, nil)
+	pa, err := rings.AnnotatePeaks(sc, func(_ feat.Feature, _ int, v float64) bool { return v > 5 },
+		rings.PeakCallouts(140, rings.BoxStyle{Padding: 2}, draw.TextStyle{Color: color.Black, Font: font}),
+		rings.PeakConnectors(draw.LineStyle{Color: color.Black, Width: 1}, color.White),
+	)
+	c.Assert(err, check.Equals, nil)
+	c.Check(pa.Validate(), check.Equals, nil)
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	anns, err := pa.Annotations()
+	c.Assert(err, check.Equals, nil)
+	c.Assert(anns, check.HasLen, 2)
+	for i, want := range []struct {
+		bin int
+		rad vg.Length
+	}{
+		// The scores span [1, 9].
+		{bin: 2, rad: 100},
+		{bin: 5, rad: 87.5},
+	} {
+		a := anns[i]
+		c.Check(a.Scorer, check.Equals, scorers[want.bin])
+		c.Check(a.Value, check.Equals, vals[want.bin])
+		c.Check(near(float64(a.Radius), float64(want.rad)), check.Equals, true, check.Commentf("got radius %v want %v", a.Radius, want.rad))
+		rad, ok := sc.ScoreRadius(scorers[want.bin], a.Value)
+		c.Check(ok, check.Equals, true)
+		c.Check(rad, check.Equals, a.Radius)
+		c.Check(near(float64(a.Angle), (float64(want.bin)+0.5)/float64(len(vals))*float64(rings.Complete)), check.Equals, true)
+	}
+
+	// Changing the score range after construction moves the annotations.
+	sc.Min, sc.Max = 0, 18
+	anns, err = pa.Annotations()
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(float64(anns[0].Radius), 75), check.Equals, true, check.Commentf("got radius %v", anns[0].Radius))
+
+	tc := &canvas{dpi: defaultDPI}
+	pa.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var (
+		strokes int
+		texts   []string
+		starts  []vg.Point
+	)
+	for _, a := range tc.actions {
+		switch a := a.(type) {
+		case stroke:
+			strokes++
+			if strokes%rings.DefaultAnnotationSegments == 1 {
+				starts = append(starts, a.path[0].Pos)
+			}
+		case fillString:
+			texts = append(texts, a.str)
+		}
+	}
+	// Each connector is stroked segment by segment to grade its color.
+	c.Check(strokes, check.Equals, 2*rings.DefaultAnnotationSegments)
+	c.Check(texts, check.DeepEquals, []string{"9", "7"})
+	c.Assert(starts, check.HasLen, 2)
+	want := rings.RectangularAt(vg.Point{150, 150}, anns[0].Angle, anns[0].Radius)
+	c.Check(near(float64(starts[0].X), float64(want.X)) && near(float64(starts[0].Y), float64(want.Y)), check.Equals, true, check.Commentf("got %v want %v", starts[0], want))
+}
+
+func (s *S) TestAxisFrame(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 110, 120, 0)
+	c.Assert(err, check.Equals, nil)
+	vals := []float64{1, 3, 9, 2}
+	scorers := makeScorers(chr, len(vals), 1, func(i, _ int) float64 { return vals[i] })
+	font, err := vg.MakeFont("Helvetica", 8)
+	c.Assert(err, check.Equals, nil)
+	frame := draw.LineStyle{Color: color.RGBA{R: 0xff, A: 0xff}, Width: 1}
+
+	for _, t := range []struct {
+		marker plot.Ticker
+		labels []string
+	}{
+		// The frame is drawn without a tick Marker.
+		{labels: []string{"1", "9"}},
+		// The frame label at the minimum gives way to the tick label.
+		{marker: plot.ConstantTicks{{Value: 1, Label: "one"}, {Value: 5, Label: "five"}}, labels: []string{"one", "five", "9"}},
+	} {
+		axis := &rings.Axis{
+			Frame:       frame,
+			FrameLabels: true,
+			Tick: rings.TickConfig{
+				Label:  draw.TextStyle{Color: color.Black, Font: font},
+				Length: 3,
+				Marker: t.marker,
+			},
+		}
+		if t.marker != nil {
+			axis.Tick.LineStyle = plotter.DefaultLineStyle
+		}
+		c.Check(axis.Validate(), check.Equals, nil)
+		sc, err := rings.NewScores(scorers, b, 50, 100, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Axis: axis})
+		c.Assert(err, check.Equals, nil)
+
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+		var (
+			caps   []vg.Path
+			col    color.Color
+			labels []string
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case setColor:
+				col = a.col
+			case stroke:
+				if col == frame.Color {
+					caps = append(caps, a.path)
+				}
+			case fillString:
+				labels = append(labels, a.str)
+			}
+		}
+		c.Check(labels, check.DeepEquals, t.labels)
+		c.Assert(caps, check.HasLen, 2)
+		for i, rad := range []vg.Length{50, 100} {
+			// The axis is at angle zero, so caps are vertical.
+			c.Check(caps[i][0].Pos, check.Equals, vg.Point{150 + rad, 150})
+			c.Check(math.Abs(float64(caps[i][1].Pos.Y-150)), check.Equals, 3.0)
+		}
+	}
+}
+
+func (s *S) TestTraceInterpolation(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 110, 120, 0)
+	c.Assert(err, check.Equals, nil)
+	vals := []float64{0, 10, 0, 10, 10}
+	scorers := makeScorers(chr, len(vals), 1, func(i, _ int) float64 { return vals[i] })
+	cen := vg.Point{150, 150}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	bin := float64(rings.Complete) / float64(len(vals))
+
+	render := func(t *rings.Trace) []vg.Path {
+		sc, err := rings.NewScores(scorers, b, 50, 100, t)
+		c.Assert(err, check.Equals, nil)
+		c.Check(sc.Validate(), check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var paths []vg.Path
+		for _, a := range tc.actions {
+			if s, ok := a.(stroke); ok {
+				paths = append(paths, s.path)
+			}
+		}
+		return paths
+	}
+	polar := func(p vg.Point) (float64, float64) {
+		theta, r := rings.Polar(p.Sub(cen))
+		return float64(rings.Normalize(theta)), float64(r)
+	}
+
+	for _, interp := range []rings.Interpolation{rings.LinearInterpolation, rings.SmoothInterpolation} {
+		paths := render(&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Join: true, Interpolation: interp})
+		c.Assert(paths, check.HasLen, 1)
+		pa := paths[0]
+		c.Check(pa[0].Type, check.Equals, vg.MoveComp)
+		var mids int
+		for k, e := range pa {
+			c.Check(e.Type == vg.ArcComp, check.Equals, false)
+			theta, r := polar(e.Pos)
+			if k == len(pa)-1 {
+				theta = float64(rings.Complete)
+			}
+			// The curve never leaves the radii of the score range.
+			c.Check(50-1e-6 <= r && r <= 100+1e-6, check.Equals, true, check.Commentf("interpolation %d radius %v", interp, r))
+			// The curve passes through the scores at the midpoints of their arcs.
+			if i := theta/bin - 0.5; near(i, math.Floor(i+0.5)) && i >= 0 {
+				mids++
+				c.Check(near(r, 50+5*vals[int(i+0.5)]), check.Equals, true, check.Commentf("interpolation %d radius %v at %d", interp, r, int(i+0.5)))
+			}
+			// Linear interpolation varies the radius linearly with angle.
+			if interp == rings.LinearInterpolation && bin/2 < theta && theta < float64(rings.Complete)-bin/2 {
+				i := int(theta/bin - 0.5)
+				f := theta/bin - 0.5 - float64(i)
+				want := 50 + 5*(vals[i]+(vals[i+1]-vals[i])*f)
+				c.Check(near(r, want), check.Equals, true, check.Commentf("radius %v want %v at %v", r, want, theta))
+			}
+		}
+		c.Check(mids, check.Equals, len(vals))
+		theta, r := polar(pa[0].Pos)
+		c.Check(near(theta, 0) && near(r, 50), check.Equals, true)
+	}
+
+	// The curve is broken where its style changes.
+	thick := plotter.DefaultLineStyle
+	thick.Width *= 2
+	paths := render(&rings.Trace{
+		Join:          true,
+		Interpolation: rings.SmoothInterpolation,
+		Segmenter: func(v float64) draw.LineStyle {
+			if v > 5 {
+				return thick
+			}
+			return plotter.DefaultLineStyle
+		},
+	})
+	c.Check(paths, check.HasLen, 4)
+
+	// Scores that are not joined are rendered as arcs.
+	paths = render(&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Interpolation: rings.LinearInterpolation})
+	c.Assert(paths, check.HasLen, len(vals))
+	for _, pa := range paths {
+		c.Check(pa[len(pa)-1].Type, check.Equals, vg.ArcComp)
+	}
+
+	sc, err := rings.NewScores(scorers, b, 50, 100, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}, Interpolation: 3})
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.ErrorMatches, "(?s).*unknown trace interpolation 3.*")
+}
+
+func (s *S) TestBezierRadiusFunc(c *check.C) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	b := &rings.Bezier{Segments: 4, Radius: rings.LengthDist{Length: 10}, RadiusFunc: rings.ProportionalRadius(0.1, 0.9)}
+	for _, t := range []struct {
+		ends [2]rings.Angle
+		want float64
+	}{
+		// Nearby ends keep the curve near the rim, whatever the
+		// normalisation of the end angles.
+		{ends: [2]rings.Angle{0.1, -0.1}, want: 40 * (0.9 - 0.8*0.2/math.Pi)},
+		{ends: [2]rings.Angle{0.1, rings.Complete - 0.1}, want: 40 * (0.9 - 0.8*0.2/math.Pi)},
+		{ends: [2]rings.Angle{0, rings.Complete / 4}, want: 40 * 0.5},
+		// Opposite ends pass close to the center.
+		{ends: [2]rings.Angle{0, rings.Complete / 2}, want: 40 * 0.1},
+	} {
+		got := b.ControlPoints(t.ends, [2]vg.Length{30, 50})
+		c.Assert(got, check.HasLen, 3)
+		_, r := rings.Polar(got[1])
+		c.Check(near(float64(r), t.want), check.Equals, true, check.Commentf("ends %v radius %v want %v", t.ends, r, t.want))
+	}
+
+	var seps []rings.Angle
+	b.RadiusFunc = func(sep rings.Angle, rad [2]vg.Length) vg.Length {
+		seps = append(seps, sep)
+		c.Check(rad, check.Equals, [2]vg.Length{30, 50})
+		return 0
+	}
+	b.ControlPoints([2]rings.Angle{-rings.Complete + 0.1, -0.1}, [2]vg.Length{30, 50})
+	c.Assert(seps, check.HasLen, 1)
+	c.Check(near(float64(seps[0]), 0.2), check.Equals, true)
+}
+
+func (s *S) TestSegments(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 110, 120, 0)
+	c.Assert(err, check.Equals, nil)
+	sty := draw.LineStyle{Color: color.Black, Width: 1}
+	var scorers []rings.Scorer
+	for _, seg := range []struct {
+		start, end int
+		value      float64
+	}{
+		{0, 250, 2}, {250, 500, 2}, {500, 800, 5}, {800, 1000, 1},
+	} {
+		scorers = append(scorers, &fs{start: seg.start, end: seg.end, location: chr, style: sty, scores: []float64{seg.value}})
+	}
+	cen := vg.Point{150, 150}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	for _, merge := range []bool{false, true} {
+		sg := &rings.Segments{
+			Connector: plotter.DefaultLineStyle,
+			Fill:      color.Gray{0x80},
+			Baseline:  1,
+			Merge:     merge,
+		}
+		sc, err := rings.NewScores(scorers, b, 50, 100, sg)
+		c.Assert(err, check.Equals, nil)
+		c.Check(sc.Validate(), check.Equals, nil)
+		tc := &canvas{dpi: defaultDPI}
+		sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+
+		var (
+			fills      int
+			arcs       []vg.PathComp
+			connectors int
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case fill:
+				fills++
+			case stroke:
+				if last := a.path[len(a.path)-1]; last.Type == vg.ArcComp {
+					arcs = append(arcs, last)
+				} else {
+					connectors++
+				}
+			}
+		}
+		n := 4
+		if merge {
+			n = 3
+		}
+		c.Check(fills, check.Equals, n)
+		c.Check(connectors, check.Equals, n-1)
+		c.Assert(arcs, check.HasLen, n)
+
+		// The score range [1, 5] is taken from the segment values.
+		c.Check(near(float64(arcs[0].Radius), 62.5), check.Equals, true)
+		if merge {
+			c.Check(near(arcs[0].Start, 0) && near(arcs[0].Angle, float64(rings.Complete)/2), check.Equals, true, check.Commentf("%+v", arcs[0]))
+		} else {
+			c.Check(near(arcs[0].Angle, float64(rings.Complete)/4), check.Equals, true)
+		}
+		c.Check(near(float64(arcs[n-1].Radius), 50), check.Equals, true)
+	}
+
+	sc, err := rings.NewScores(append(scorers, &fs{start: 0, end: 10, location: chr, name: "empty"}), b, 50, 100, &rings.Segments{})
+	c.Assert(err, check.Equals, nil)
+	c.Check(sc.Validate(), check.ErrorMatches, `(?s).*scorer "empty" has no scores but segments require one.*`)
+}
+
+func (s *S) TestBezierOutward(c *check.C) {
+	const eps = 1e-9
+	ends := [2]rings.Angle{0, rings.Complete / 4}
+	rad := [2]vg.Length{100, 100}
+
+	// extent returns the minimum and maximum radius of the curve.
+	extent := func(b *rings.Bezier) (min, max float64) {
+		curve := bezier.New(b.ControlPoints(ends, rad)...)
+		min, max = math.Inf(1), math.Inf(-1)
+		for i := 0; i <= 100; i++ {
+			_, r := rings.Polar(curve.Point(float64(i) / 100))
+			min, max = math.Min(min, float64(r)), math.Max(max, float64(r))
+		}
+		return min, max
+	}
+
+	for _, crest := range []*rings.FactorDist{nil, {Factor: 0.5}, {Factor: 1}} {
+		// An inward curve stays within the radius of its ends.
+		b := &rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 40}, Crest: crest}
+		min, max := extent(b)
+		c.Check(max <= 100+eps, check.Equals, true, check.Commentf("crest %v: inward max radius %v", crest, max))
+		c.Check(min < 100, check.Equals, true, check.Commentf("crest %v: inward min radius %v", crest, min))
+
+		// The outward curve is the reflection of the inward curve about
+		// the radius of its ends, and stays outside that radius.
+		b.Outward = true
+		min, max = extent(b)
+		c.Check(min >= 100-eps, check.Equals, true, check.Commentf("crest %v: outward min radius %v", crest, min))
+		c.Check(max > 100, check.Equals, true, check.Commentf("crest %v: outward max radius %v", crest, max))
+		c.Check(max <= 160+eps, check.Equals, true, check.Commentf("crest %v: outward max radius %v", crest, max))
+
+		// A Radius beyond the radius of the ends also bows outward.
+		b = &rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 160}, Crest: crest}
+		min, max = extent(b)
+		c.Check(min >= 100-eps, check.Equals, true, check.Commentf("crest %v: outer radius min radius %v", crest, min))
+		c.Check(max > 100, check.Equals, true, check.Commentf("crest %v: outer radius max radius %v", crest, max))
+	}
+
+	// Crest control points of outward curves lie outside their ends
+	// on the rays through the ends.
+	b := &rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 40}, Crest: &rings.FactorDist{Factor: 0.5}, Outward: true}
+	pts := b.ControlPoints(ends, rad)
+	c.Assert(pts, check.HasLen, 5)
+	for i, p := range []vg.Point{pts[1], pts[3]} {
+		a, r := rings.Polar(p)
+		c.Check(math.Abs(float64(r)-130) < eps, check.Equals, true, check.Commentf("crest %d radius %v", i, r))
+		c.Check(math.Abs(math.Remainder(float64(a-ends[i]), 2*math.Pi)) < eps, check.Equals, true, check.Commentf("crest %d angle %v", i, a))
+	}
+
+	// Large crest factors do not carry the crest control
+	// points of inward curves through the center.
+	b = &rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 40}, Crest: &rings.FactorDist{Factor: 3}}
+	pts = b.ControlPoints(ends, rad)
+	for i, p := range []vg.Point{pts[1], pts[3]} {
+		_, r := rings.Polar(p)
+		c.Check(float64(r) < eps, check.Equals, true, check.Commentf("crest %d radius %v", i, r))
+	}
+}
+
+func (s *S) TestClipped(c *check.C) {
+	chr := &fs{start: 0, end: 1000, name: "chr1"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 110, 120, 0)
+	c.Assert(err, check.Equals, nil)
+	var scorers []rings.Scorer
+	for i := 0; i < 20; i++ {
+		scorers = append(scorers, &fs{start: i * 50, end: (i + 1) * 50, location: chr, scores: []float64{float64(i % 5)}})
+	}
+	const width = 2
+	cen := vg.Point{150, 150}
+
+	// The trace overshoots the clipping annulus, between 60 and 90, on both sides.
+	trace := func() *rings.Scores {
+		sc, err := rings.NewScores(scorers, b, 50, 100, &rings.Trace{
+			LineStyles: []draw.LineStyle{{Color: color.Black, Width: width}},
+			Join:       true,
+		}, rings.ScoreMinMax(1, 3))
+		c.Assert(err, check.Equals, nil)
+		return sc
+	}
+
+	// ink returns the minimum and maximum radius of the pixels painted by p.
+	ink := func(p plot.Plotter) (min, max float64) {
+		cv := vgimg.NewWith(vgimg.UseWH(300, 300), vgimg.UseDPI(72))
+		p.(interface {
+			DrawAt(draw.Canvas, vg.Point)
+		}).DrawAt(draw.New(cv), cen)
+		img := cv.Image()
+		min, max = math.Inf(1), math.Inf(-1)
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				if r, g, b, _ := img.At(x, y).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+					continue
+				}
+				r := math.Hypot(float64(x)+0.5-150, 300-(float64(y)+0.5)-150)
+				min, max = math.Min(min, r), math.Max(max, r)
+			}
+		}
+		return min, max
+	}
+
+	min, max := ink(trace())
+	c.Assert(min < 60-width && max > 90+width, check.Equals, true, check.Commentf("unclipped ink radii [%v, %v]", min, max))
+
+	cl := rings.Clipped(trace(), 60, 90, nil)
+	c.Check(cl.(rings.Validator).Validate(), check.Equals, nil)
+	min, max = ink(cl)
+	c.Check(min >= 60-width/2-1 && max <= 90+width/2+1, check.Equals, true, check.Commentf("clipped trace ink radii [%v, %v]", min, max))
+
+	// Fills are intersected with the region.
+	h := rings.NewHighlight(color.Gray{0x80}, rings.Arc{0, rings.Complete / 4}, 20, 130)
+	min, max = ink(rings.Clipped(h, 50, 100, nil))
+	c.Check(min >= 49 && max <= 101, check.Equals, true, check.Commentf("clipped highlight ink radii [%v, %v]", min, max))
+	c.Check(max > 99, check.Equals, true, check.Commentf("clipped highlight ink radii [%v, %v]", min, max))
+
+	// Images are masked to the region.
+	heat, err := rings.NewScores(scorers, b, 50, 100, &rings.Heat{Palette: palette.Heat(10, 1).Colors(), Rasterize: 72}, rings.ScoreMinMax(0, 4))
+	c.Assert(err, check.Equals, nil)
+	min, max = ink(rings.Clipped(heat, 60, 90, nil))
+	c.Check(min >= 59 && max <= 91, check.Equals, true, check.Commentf("clipped heat ink radii [%v, %v]", min, max))
+
+	// Sectors restrict the region.
+	tc := &canvas{dpi: defaultDPI}
+	rings.Clipped(trace(), 60, 90, &rings.Arc{0, rings.Complete / 4}).(*rings.Clip).DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var strokes int
+	for _, a := range tc.actions {
+		st, ok := a.(stroke)
+		if !ok {
+			continue
+		}
+		strokes++
+		for _, pc := range st.path {
+			if pc.Type != vg.MoveComp && pc.Type != vg.LineComp {
+				continue
+			}
+			theta, r := rings.Polar(pc.Pos.Sub(cen))
+			c.Check(r >= 60-1e-6 && r <= 90+1e-6, check.Equals, true, check.Commentf("point radius %v", r))
+			c.Check(theta >= -1e-6 && theta <= rings.Complete/4+1e-6, check.Equals, true, check.Commentf("point angle %v", theta))
+		}
+	}
+	c.Check(strokes > 0, check.Equals, true)
+
+	// Text is drawn only if it lies within the region.
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	for _, t := range []struct {
+		inner vg.Length
+		want  int
+	}{{inner: 0, want: 1}, {inner: 50, want: 0}} {
+		tc := &canvas{dpi: defaultDPI}
+		rings.Clipped(rings.NewCenter("center", draw.TextStyle{Color: color.Black, Font: font}), t.inner, 100, nil).(*rings.Clip).DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var texts int
+		for _, a := range tc.actions {
+			if _, ok := a.(fillString); ok {
+				texts++
+			}
+		}
+		c.Check(texts, check.Equals, t.want, check.Commentf("inner radius %v", t.inner))
+	}
+
+	// Transformed text is tested in the coordinates it is drawn in. The
+	// labels are drawn tangentially, centered on their radius.
+	var marks []feat.Feature
+	for i := 0; i < 8; i++ {
+		marks = append(marks, &fs{start: i * 125, end: i*125 + 1, name: fmt.Sprintf("mark%d", i), location: chr})
+	}
+	lb, err := rings.NewLabelsWith(b, 125, rings.NameLabels(marks), rings.LabelTextStyle(draw.TextStyle{Color: color.Black, Font: font}))
+	c.Assert(err, check.Equals, nil)
+	for _, t := range []struct {
+		inner, outer vg.Length
+		want         int
+	}{{inner: 110, outer: 140, want: len(marks)}, {inner: 126, outer: 200, want: 0}} {
+		tc := &canvas{dpi: defaultDPI}
+		rings.Clipped(lb, t.inner, t.outer, nil).(*rings.Clip).DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var texts int
+		for _, a := range tc.actions {
+			if _, ok := a.(fillString); ok {
+				texts++
+			}
+		}
+		c.Check(texts, check.Equals, t.want, check.Commentf("radii [%v, %v]", t.inner, t.outer))
+	}
+
+	// Paths written to SVG lie within the region.
+	sv := vgsvg.New(300, 300)
+	cl.(*rings.Clip).DrawAt(draw.New(sv), cen)
+	var buf bytes.Buffer
+	_, err = sv.WriteTo(&buf)
+	c.Assert(err, check.Equals, nil)
+	scale := 72 / float64(vgsvg.DPI)
+	pts := regexp.MustCompile(`[ML](-?[0-9.e+-]+),(-?[0-9.e+-]+)`).FindAllStringSubmatch(buf.String(), -1)
+	c.Assert(len(pts) > 0, check.Equals, true)
+	for _, m := range pts {
+		x, err := strconv.ParseFloat(m[1], 64)
+		c.Assert(err, check.Equals, nil)
+		y, err := strconv.ParseFloat(m[2], 64)
+		c.Assert(err, check.Equals, nil)
+		r := math.Hypot(x*scale-150, y*scale-150)
+		// Coordinates are written to SVG to limited precision.
+		c.Check(r >= 60-1e-2 && r <= 90+1e-2, check.Equals, true, check.Commentf("svg point radius %v", r))
+	}
+
+	c.Check(rings.Clipped(trace(), 100, 50, nil).(rings.Validator).Validate(), check.ErrorMatches, "rings: inner radius 100 greater than outer radius 50")
+}
+
+func (s *S) TestDiff(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	other := &fs{start: 0, end: 100, name: "other"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr, other}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	// The bins of B are held in a different order to those of A.
+	a := &rings.ScoreTrack{
+		Loc:    chr,
+		Starts: []int32{0, 25, 50},
+		Ends:   []int32{25, 50, 75},
+		Values: [][]float64{{0}, {4}, {1}},
+	}
+	bt := &rings.ScoreTrack{
+		Loc:    chr,
+		Starts: []int32{50, 0, 25},
+		Ends:   []int32{75, 25, 50},
+		Values: [][]float64{{2}, {2}, {2}},
+	}
+	d, err := rings.NewDiff(a, bt, b, 40, 60)
+	c.Assert(err, check.Equals, nil)
+	c.Check(d.Validate(), check.Equals, nil)
+	min, max := d.ScoreRange()
+	c.Check(min, check.Equals, 0.)
+	c.Check(max, check.Equals, 4.)
+
+	aFill, bFill := color.Gray{0x40}, color.Gray{0xc0}
+	d.AFill, d.BFill = aFill, bFill
+	d.AStyle = plotter.DefaultLineStyle
+
+	type region struct {
+		col  color.Color
+		path vg.Path
+	}
+	render := func() (fills []region, strokes []vg.Path) {
+		tc := &canvas{dpi: defaultDPI}
+		d.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		var col color.Color
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case setColor:
+				col = a.col
+			case fill:
+				fills = append(fills, region{col, a.path})
+			case stroke:
+				strokes = append(strokes, a.path)
+			}
+		}
+		return fills, strokes
+	}
+
+	// The profiles have radii of 40+5v, so A rises from 40 to 60 between
+	// the midpoints of the first and second bins and falls to 45 between
+	// the midpoints of the second and third, crossing the constant B
+	// profile at 50 at the end of the first bin and two thirds of the way
+	// from the midpoint of the second bin to that of the third.
+	arc, err := b.ArcOf(chr, &fs{start: 0, end: 25, location: chr})
+	c.Assert(err, check.Equals, nil)
+	first := rings.RectangularAt(cen, arc.Theta+arc.Phi, 50)
+	second := rings.RectangularAt(cen, arc.Theta+arc.Phi*(3.0/2+2.0/3), 50)
+
+	fills, strokes := render()
+	c.Assert(fills, check.HasLen, 3)
+	for i, want := range []struct {
+		col        color.Color
+		start, end vg.Point
+	}{
+		{col: bFill, start: rings.RectangularAt(cen, arc.Theta, 40), end: first},
+		{col: aFill, start: first, end: second},
+		{col: bFill, start: second, end: rings.RectangularAt(cen, arc.Theta+3*arc.Phi, 45)},
+	} {
+		f := fills[i]
+		c.Check(f.col, check.Equals, want.col, check.Commentf("fill %d", i))
+		c.Check(near(f.path[0].Pos, want.start), check.Equals, true, check.Commentf("fill %d", i))
+
+		var found bool
+		for _, p := range f.path {
+			found = found || near(p.Pos, want.end)
+		}
+		c.Check(found, check.Equals, true, check.Commentf("fill %d", i))
+	}
+	c.Assert(strokes, check.HasLen, 1)
+	c.Check(near(strokes[0][0].Pos, rings.RectangularAt(cen, arc.Theta, 40)), check.Equals, true)
+	c.Check(near(strokes[0][len(strokes[0])-1].Pos, rings.RectangularAt(cen, arc.Theta+3*arc.Phi, 45)), check.Equals, true)
+
+	// A NaN score breaks the profiles and a nil fill color is not filled.
+	a.Values[1] = []float64{math.NaN()}
+	d.BFill = nil
+	d.Min, d.Max = 0, 4
+	fills, strokes = render()
+	c.Check(fills, check.HasLen, 0)
+	c.Check(strokes, check.HasLen, 2)
+
+	// Mismatched bins are reported at the first location at which they differ.
+	bt.Loc = other
+	c.Check(d.Validate(), check.ErrorMatches, `rings: diff bins of location "chr" differ between series`)
+	bt.Loc = chr
+	bt.Ends[0] = 70
+	c.Check(d.Validate(), check.ErrorMatches, `rings: diff bins of location "chr" differ between series`)
+	_, err = rings.NewDiff(a, bt, b, 40, 60)
+	c.Check(err, check.ErrorMatches, `rings: diff bins of location "chr" differ between series`)
+	bt.Ends[0] = 75
+
+	d.B = nil
+	c.Check(d.Validate(), check.ErrorMatches, "rings: nil diff series B")
+}
+
+func (s *S) TestParseAngle(c *check.C) {
+	near := func(a, b rings.Angle) bool { return math.Abs(float64(a-b)) < 1e-12 }
+	c.Check(rings.AngleHalf, check.Equals, rings.Angle(math.Pi))
+	c.Check(rings.AngleQuarter, check.Equals, rings.Complete/4)
+	c.Check(rings.AngleEighth, check.Equals, rings.Complete/8)
+	c.Check(near(360*rings.AngleDegree, rings.Complete), check.Equals, true)
+
+	for _, test := range []struct {
+		in   string
+		want rings.Angle
+	}{
+		{in: "90deg", want: rings.AngleQuarter},
+		{in: "-45 deg", want: -rings.AngleEighth},
+		{in: "0.25turn", want: rings.AngleQuarter},
+		{in: " 1turn ", want: rings.Complete},
+		{in: "3.141592653589793rad", want: rings.AngleHalf},
+		{in: "1.5", want: 1.5},
+		{in: "-2e-1", want: -0.2},
+		{in: "0", want: 0},
+	} {
+		got, err := rings.ParseAngle(test.in)
+		c.Check(err, check.Equals, nil, check.Commentf("%q", test.in))
+		c.Check(near(got, test.want), check.Equals, true, check.Commentf("%q: got %v want %v", test.in, got, test.want))
+	}
+
+	// Angles formatted in each unit parse back to the same angle.
+	for _, a := range []rings.Angle{0, 1, -rings.AngleQuarter, rings.Complete / 3, 7 * rings.Complete / 5} {
+		for _, u := range []struct {
+			suffix string
+			unit   rings.Angle
+		}{
+			{suffix: "deg", unit: rings.AngleDegree},
+			{suffix: "rad", unit: 1},
+			{suffix: "turn", unit: rings.Complete},
+			{suffix: "", unit: 1},
+		} {
+			str := strconv.FormatFloat(float64(a/u.unit), 'g', -1, 64) + u.suffix
+			got, err := rings.ParseAngle(str)
+			c.Check(err, check.Equals, nil, check.Commentf("%q", str))
+			c.Check(near(got, a), check.Equals, true, check.Commentf("%q: got %v want %v", str, got, a))
+		}
+	}
+
+	for _, bad := range []string{"", "deg", "90 degrees", "quarter", "NaN", "Infturn", "1e400rad"} {
+		_, err := rings.ParseAngle(bad)
+		c.Check(err, check.ErrorMatches, `rings: invalid angle ".*"`, check.Commentf("%q", bad))
+	}
+}
+
+func (s *S) TestParseLength(c *check.C) {
+	for _, test := range []struct {
+		in   string
+		want vg.Length
+	}{
+		{in: "12", want: 12},
+		{in: "12pt", want: 12},
+		{in: "1in", want: vg.Inch},
+		{in: " 2.54 cm ", want: vg.Inch},
+		{in: "-5mm", want: -5 * vg.Millimeter},
+	} {
+		got, err := rings.ParseLength(test.in)
+		c.Check(err, check.Equals, nil, check.Commentf("%q", test.in))
+		c.Check(math.Abs(float64(got-test.want)) < 1e-12, check.Equals, true, check.Commentf("%q: got %v want %v", test.in, got, test.want))
+	}
+
+	// Lengths formatted in each unit parse back to the same length.
+	for _, l := range []vg.Length{0, 1, 72, -36.5, 100.0 / 3} {
+		for _, u := range []struct {
+			suffix string
+			unit   vg.Length
+		}{
+			{suffix: "mm", unit: vg.Millimeter},
+			{suffix: "cm", unit: vg.Centimeter},
+			{suffix: "in", unit: vg.Inch},
+			{suffix: "pt", unit: 1},
+			{suffix: "", unit: 1},
+		} {
+			str := strconv.FormatFloat(float64(l/u.unit), 'g', -1, 64) + u.suffix
+			got, err := rings.ParseLength(str)
+			c.Check(err, check.Equals, nil, check.Commentf("%q", str))
+			c.Check(math.Abs(float64(got-l)) < 1e-12, check.Equals, true, check.Commentf("%q: got %v want %v", str, got, l))
+		}
+	}
+
+	for _, bad := range []string{"", "cm", "ten", "NaN", "Infmm", "12px"} {
+		_, err := rings.ParseLength(bad)
+		c.Check(err, check.ErrorMatches, `rings: invalid length ".*"`, check.Commentf("%q", bad))
+	}
+}
+
+func (s *S) TestParsedOptions(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0, rings.BlockArrowsParsed("5deg"))
+	c.Assert(err, check.Equals, nil)
+	c.Check(b.Shape, check.Equals, rings.Arrow)
+	c.Check(math.Abs(float64(b.HeadAngle-5*rings.AngleDegree)) < 1e-12, check.Equals, true)
+	_, err = rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0, rings.BlockArrowsParsed("five"))
+	c.Check(err, check.ErrorMatches, `rings: invalid angle "five"`)
+	_, err = rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0, rings.BlockArrowsParsed("-0.01turn"))
+	c.Check(err, check.ErrorMatches, "rings: negative arrow head angle")
+
+	pairs := []rings.Pair{&fp{feats: [2]*fs{
+		{start: 10, end: 20, location: chr},
+		{start: 60, end: 70, location: chr},
+	}}}
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkClipParsed("1cm", "1in"), rings.LinkEndGlyphParsed(draw.GlyphStyle{}, "1deg"))
+	c.Assert(err, check.Equals, nil)
+	c.Check(l.ClipInner, check.Equals, vg.Centimeter)
+	c.Check(l.ClipOuter, check.Equals, vg.Inch)
+	c.Check(math.Abs(float64(l.EndGlyphTolerance-rings.AngleDegree)) < 1e-12, check.Equals, true)
+	_, err = rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkClipParsed("1in", "1cm"))
+	c.Check(err, check.ErrorMatches, "rings: clip inner radius .* greater than outer radius .*")
+	_, err = rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkClipParsed("1in", "wide"))
+	c.Check(err, check.ErrorMatches, `rings: invalid length "wide"`)
+	_, err = rings.NewLinks(pairs, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70}, rings.LinkEndGlyphParsed(draw.GlyphStyle{}, "1 degree"))
+	c.Check(err, check.ErrorMatches, `rings: invalid angle "1 degree"`)
+
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 60,
+		&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	peak := func(_ feat.Feature, _ int, v float64) bool { return v > 2 }
+	pa, err := rings.AnnotatePeaks(sc, peak, rings.PeakCalloutsParsed("1in", rings.BoxStyle{}, draw.TextStyle{}))
+	c.Assert(err, check.Equals, nil)
+	c.Check(pa.Callouts.Radius, check.Equals, vg.Inch)
+	_, err = rings.AnnotatePeaks(sc, peak, rings.PeakCalloutsParsed("-1in", rings.BoxStyle{}, draw.TextStyle{}))
+	c.Check(err, check.ErrorMatches, "rings: negative callout radius")
+}
+
+func (s *S) TestExternalAnchor(c *check.C) {
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool { return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) < 1e-9 }
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	f := &fs{start: 25, end: 35, location: chr}
+	arc, err := b.ArcOf(chr, f)
+	c.Assert(err, check.Equals, nil)
+	g := &fs{start: 0, end: 1, location: chr}
+	pairs := []rings.Pair{vp{feats: [2]feat.Feature{f, g}}}
+
+	// The middle control point of a curve to an external point lies on the
+	// ray from the center through the midpoint of the straight line between
+	// the ends.
+	bez := &rings.Bezier{Segments: 10, Radius: rings.LengthDist{Length: 120}}
+	pts := bez.ControlPointsTo(rings.AngleQuarter, 80, vg.Point{150, 0})
+	c.Assert(pts, check.HasLen, 3)
+	c.Check(near(pts[0], vg.Point{0, 80}), check.Equals, true)
+	mid, _ := rings.Polar(vg.Point{75, 40})
+	c.Check(near(pts[1], rings.Rectangular(mid, 120)), check.Equals, true)
+	c.Check(pts[2], check.Equals, vg.Point{150, 0})
+
+	anchor := &rings.ExternalAnchor{Point: vg.Point{150, 0}, Width: 20}
+	render := func(p interface {
+		DrawAt(draw.Canvas, vg.Point)
+	}) (strokes, fills []vg.Path) {
+		tc := &canvas{dpi: defaultDPI}
+		p.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case stroke:
+				strokes = append(strokes, a.path)
+			case fill:
+				fills = append(fills, a.path)
+			}
+		}
+		return strokes, fills
+	}
+
+	// Links end at the anchor point, whichever end the anchor is.
+	l, err := rings.NewLinks(pairs, [2]rings.ArcOfer{b, anchor}, [2]vg.Length{80, 80})
+	c.Assert(err, check.Equals, nil)
+	l.Bezier = bez
+	l.LineStyle = plotter.DefaultLineStyle
+	c.Check(l.Validate(), check.Equals, nil)
+	strokes, _ := render(l)
+	c.Assert(strokes, check.HasLen, 1)
+	c.Check(near(strokes[0][0].Pos, rings.RectangularAt(cen, arc.Theta, 80)), check.Equals, true)
+	c.Check(near(strokes[0][len(strokes[0])-1].Pos, cen.Add(anchor.Point)), check.Equals, true)
+
+	l.Ends = [2]rings.ArcOfer{anchor, b}
+	l.Set = []rings.Pair{vp{feats: [2]feat.Feature{g, f}}}
+	strokes, _ = render(l)
+	c.Assert(strokes, check.HasLen, 1)
+	c.Check(near(strokes[0][0].Pos, cen.Add(anchor.Point)), check.Equals, true)
+	c.Check(near(strokes[0][len(strokes[0])-1].Pos, rings.RectangularAt(cen, arc.Theta, 80)), check.Equals, true)
+
+	// Ribbons end on a straight line of the anchor's width perpendicular to
+	// the direction of the anchor.
+	r, err := rings.NewRibbons(pairs, [2]rings.ArcOfer{b, anchor}, [2]vg.Length{80, 80})
+	c.Assert(err, check.Equals, nil)
+	r.Color = color.Gray{0x80}
+	c.Check(r.Validate(), check.Equals, nil)
+	_, fills := render(r)
+	c.Assert(fills, check.HasLen, 1)
+	var hi, lo bool
+	for _, p := range fills[0] {
+		hi = hi || near(p.Pos, cen.Add(vg.Point{150, 10}))
+		lo = lo || near(p.Pos, cen.Add(vg.Point{150, -10}))
+	}
+	c.Check(hi && lo, check.Equals, true)
+
+	r.Bezier = bez
+	_, fills = render(r)
+	c.Assert(fills, check.HasLen, 1)
+	hi, lo = false, false
+	for _, p := range fills[0] {
+		hi = hi || near(p.Pos, cen.Add(vg.Point{150, 10}))
+		lo = lo || near(p.Pos, cen.Add(vg.Point{150, -10}))
+	}
+	c.Check(hi && lo, check.Equals, true)
+
+	// Anchors must lie outside the radius of the other end.
+	anchor.Point = vg.Point{50, 0}
+	c.Check(l.Validate(), check.ErrorMatches, "rings: external anchor at distance 50 not outside end radius 80")
+	anchor.Point, anchor.Width = vg.Point{150, 0}, -1
+	c.Check(r.Validate(), check.ErrorMatches, "rings: negative external anchor width -1")
+	anchor.Width = 20
+	r.Ends[0] = &rings.ExternalAnchor{Point: vg.Point{0, 150}}
+	c.Check(r.Validate(), check.ErrorMatches, "rings: both ends are external anchors")
+}
+
+// retainedTrack is a track counting its renderings. A hashedTrack is a Hashable retainedTrack.
+type retainedTrack struct {
+	rad   vg.Length
+	layer int
+	drawn int
+}
+
+func (t *retainedTrack) DrawAt(ca draw.Canvas, cen vg.Point) {
+	t.drawn++
+	var pa vg.Path
+	pa.Move(cen.Add(vg.Point{X: t.rad}))
+	pa.Arc(cen, t.rad, 0, 2*math.Pi)
+	ca.SetColor(color.Gray{uint8(t.rad)})
+	ca.Fill(pa)
+}
+func (t *retainedTrack) Plot(draw.Canvas, *plot.Plot) {}
+func (t *retainedTrack) DrawLayer() int               { return t.layer }
+
+type hashedTrack struct{ retainedTrack }
+
+func (t *hashedTrack) Hash() uint64 { return math.Float64bits(float64(t.rad)) }
+
+func (s *S) TestRetained(c *check.C) {
+	a := &hashedTrack{retainedTrack{rad: 10, layer: 1}}
+	b := &hashedTrack{retainedTrack{rad: 20}}
+	u := &retainedTrack{rad: 30}
+	r := rings.NewRetained(vg.Point{50, 50}, a, b, rings.NewLayered(u))
+
+	render := func(w vg.Length) []interface{} {
+		tc := &canvas{dpi: defaultDPI}
+		c.Assert(r.Render(draw.NewCanvas(tc, w, 100)), check.Equals, nil)
+		return tc.actions
+	}
+	drawn := func() [3]int { return [3]int{a.drawn, b.drawn, u.drawn} }
+
+	// The first rendering draws every track, in layer order, and later
+	// renderings replay the hashed tracks identically.
+	first := render(100)
+	c.Check(drawn(), check.Equals, [3]int{1, 1, 1})
+	c.Assert(first, check.HasLen, 6)
+	c.Check(first[0], check.DeepEquals, setColor{color.Gray{20}})
+	c.Check(first[2], check.DeepEquals, setColor{color.Gray{10}})
+	c.Check(first[4], check.DeepEquals, setColor{color.Gray{30}})
+	c.Check(render(100), check.DeepEquals, first)
+	c.Check(drawn(), check.Equals, [3]int{1, 1, 2})
+
+	// Only the track whose hash changed is rendered again.
+	a.rad = 15
+	actions := render(100)
+	c.Check(drawn(), check.Equals, [3]int{2, 1, 3})
+	c.Check(actions[:2], check.DeepEquals, first[:2])
+	c.Check(actions[2], check.DeepEquals, setColor{color.Gray{15}})
+	c.Check(actions[4:], check.DeepEquals, first[4:])
+
+	// Marked tracks are rendered whatever their hash.
+	r.MarkDirty(b)
+	render(100)
+	c.Check(drawn(), check.Equals, [3]int{2, 2, 4})
+	r.MarkDirty()
+	render(100)
+	c.Check(drawn(), check.Equals, [3]int{3, 3, 5})
+
+	// A change of drawing area invalidates the retained renderings.
+	render(200)
+	c.Check(drawn(), check.Equals, [3]int{4, 4, 6})
+	render(200)
+	c.Check(drawn(), check.Equals, [3]int{4, 4, 7})
+}
+
+func (s *S) TestSharedGuides(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.AngleHalf}, 100, 110, 0)
+	c.Assert(err, check.Equals, nil)
+
+	var tracks [3]*rings.Scores
+	for i, rng := range [][2]float64{{-1, 1}, {-1, 1}, {0, 2}} {
+		tracks[i], err = rings.NewScores(makeScorers(chr, 4, 1, func(j, _ int) float64 { return float64(j) }),
+			b, 40+vg.Length(i)*20, 50+vg.Length(i)*20, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+		c.Assert(err, check.Equals, nil)
+		tracks[i].Min, tracks[i].Max = rng[0], rng[1]
+	}
+	tracks[1].Invert = true
+
+	_, err = rings.NewSharedGuides(nil, nil, plotter.DefaultLineStyle)
+	c.Check(err, check.ErrorMatches, "rings: no guide tracks")
+	_, err = rings.NewSharedGuides(nil, nil, plotter.DefaultLineStyle, tracks[0], nil)
+	c.Check(err, check.ErrorMatches, "rings: nil guide track")
+
+	g, err := rings.NewSharedGuides(nil, []float64{-0.5, 0, 2}, plotter.DefaultLineStyle, tracks[:]...)
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Validate(), check.Equals, nil)
+
+	// Values are placed on the scale of each track, and values outside the
+	// range of a track are skipped for that track only.
+	for _, t := range []struct {
+		track int
+		v     float64
+		rad   vg.Length
+		ok    bool
+	}{
+		{track: 0, v: -0.5, rad: 42.5, ok: true},
+		{track: 1, v: -0.5, rad: 67.5, ok: true},
+		{track: 2, v: -0.5, ok: false},
+		{track: 0, v: 0, rad: 45, ok: true},
+		{track: 1, v: 0, rad: 65, ok: true},
+		{track: 2, v: 0, rad: 80, ok: true},
+		{track: 0, v: 2, ok: false},
+		{track: 2, v: 2, rad: 90, ok: true},
+	} {
+		rad, ok := g.Radius(tracks[t.track], t.v)
+		c.Check(ok, check.Equals, t.ok, check.Commentf("track %d value %v", t.track, t.v))
+		if ok {
+			c.Check(rad, check.Equals, t.rad, check.Commentf("track %d value %v", t.track, t.v))
+		}
+	}
+
+	cen := vg.Point{150, 150}
+	near := func(a, b vg.Point) bool {
+		return math.Abs(float64(a.X-b.X)) < 1e-6 && math.Abs(float64(a.Y-b.Y)) < 1e-6
+	}
+	tc := &canvas{dpi: defaultDPI}
+	g.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	var (
+		arcs       []vg.Length
+		connectors [][2]vg.Point
+	)
+	for _, a := range tc.actions {
+		st, ok := a.(stroke)
+		if !ok {
+			continue
+		}
+		c.Assert(st.path, check.HasLen, 2)
+		switch st.path[1].Type {
+		case vg.ArcComp:
+			c.Check(st.path[1].Angle, check.Equals, float64(rings.AngleHalf))
+			arcs = append(arcs, st.path[1].Radius)
+		case vg.LineComp:
+			connectors = append(connectors, [2]vg.Point{st.path[0].Pos, st.path[1].Pos})
+		default:
+			c.Errorf("unexpected path component type %d", st.path[1].Type)
+		}
+	}
+	c.Check(arcs, check.DeepEquals, []vg.Length{42.5, 67.5, 45, 65, 80, 90})
+
+	// Connectors join the guides of adjacent tracks at both ends of the arc.
+	want := [][2]vg.Point{
+		{rings.RectangularAt(cen, 0, 42.5), rings.RectangularAt(cen, 0, 67.5)},
+		{rings.RectangularAt(cen, rings.AngleHalf, 42.5), rings.RectangularAt(cen, rings.AngleHalf, 67.5)},
+		{rings.RectangularAt(cen, 0, 45), rings.RectangularAt(cen, 0, 65)},
+		{rings.RectangularAt(cen, rings.AngleHalf, 45), rings.RectangularAt(cen, rings.AngleHalf, 65)},
+		{rings.RectangularAt(cen, 0, 65), rings.RectangularAt(cen, 0, 80)},
+		{rings.RectangularAt(cen, rings.AngleHalf, 65), rings.RectangularAt(cen, rings.AngleHalf, 80)},
+	}
+	c.Assert(connectors, check.HasLen, len(want))
+	for i, w := range want {
+		c.Check(near(connectors[i][0], w[0]) && near(connectors[i][1], w[1]), check.Equals, true,
+			check.Commentf("connector %d: got %v want %v", i, connectors[i], w))
+	}
+
+	// Tracks must be rendered according to a shared range, and guides
+	// are placed according to it.
+	rng := rings.NewRange()
+	rng.Min, rng.Max = 0, 4
+	_, err = rings.NewSharedGuides(rng, nil, plotter.DefaultLineStyle, tracks[:]...)
+	c.Check(err, check.ErrorMatches, "rings: guide track not using shared range")
+	g.Range = rng
+	c.Check(g.Validate(), check.ErrorMatches, "(?s).*guide track 0 not using shared range.*guide track 2 not using shared range.*")
+	for _, t := range tracks {
+		t.Min, t.Max = 0, 0
+		t.Range = rng
+	}
+	g, err = rings.NewSharedGuides(rng, nil, plotter.DefaultLineStyle, tracks[:]...)
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Validate(), check.Equals, nil)
+	rad, ok := g.Radius(tracks[0], 2)
+	c.Check(ok, check.Equals, true)
+	c.Check(rad, check.Equals, vg.Length(45))
+	min, max := tracks[0].ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 4})
+
+	tracks[2].PerFeatureRange = true
+	g.Range.Max = 0
+	c.Check(g.Validate(), check.ErrorMatches, "(?s).*guide track 2 has per feature ranges.*shared range minimum 0 not less than maximum 0.*")
+}
+
+func (s *S) TestExactAnchor(c *check.C) {
+	font, err := vg.MakeFont("Helvetica", 10)
+	c.Assert(err, check.Equals, nil)
+	sty := draw.TextStyle{Color: color.Black, Font: font}
+	ext := font.Extents()
+
+	// glyphs returns the corners of the glyph bounds of each rendered string.
+	glyphs := func(tc *canvas) [][4]vg.Point {
+		var (
+			boxes [][4]vg.Point
+			pivot vg.Point
+			rot   float64
+			moved bool
+		)
+		for _, a := range tc.actions {
+			switch a := a.(type) {
+			case push:
+				rot, moved = 0, false
+			case translate:
+				if !moved {
+					pivot, moved = vg.Point{a.x, a.y}, true
+				}
+			case rotate:
+				rot = a.angle
+			case pop:
+				rot = 0
+			case fillString:
+				sin, cos := math.Sincos(rot)
+				w := font.Width(a.str)
+				var box [4]vg.Point
+				for i, p := range []vg.Point{
+					{a.x, a.y + ext.Descent}, {a.x + w, a.y + ext.Descent},
+					{a.x + w, a.y + ext.Ascent}, {a.x, a.y + ext.Ascent},
+				} {
+					d := p.Sub(pivot)
+					box[i] = pivot.Add(vg.Point{
+						X: d.X*vg.Length(cos) - d.Y*vg.Length(sin),
+						Y: d.X*vg.Length(sin) + d.Y*vg.Length(cos),
+					})
+				}
+				boxes = append(boxes, box)
+			}
+		}
+		return boxes
+	}
+
+	arcs := rings.Arcs{Base: rings.Arc{0, rings.Complete}, Arcs: make(map[feat.Feature]rings.Arc)}
+	var feats []feat.Feature
+	for i := 0; i < 8; i++ {
+		f := &fs{start: 0, end: 1, name: "gypsy"}
+		arcs.Arcs[f] = rings.Arc{Theta: rings.Angle(i)*rings.AngleEighth - rings.AngleDegree, Phi: 2 * rings.AngleDegree}
+		feats = append(feats, f)
+	}
+	const radius = 80
+	cen := vg.Point{150, 150}
+	for _, place := range []rings.TextPlacement{rings.Tangential, rings.Radial, rings.Horizontal} {
+		l, err := rings.NewLabels(arcs, radius, rings.NameLabels(feats)...)
+		c.Assert(err, check.Equals, nil)
+		l.TextStyle = sty
+		l.Placement = place
+		l.ExactAnchor = true
+
+		tc := &canvas{dpi: defaultDPI}
+		l.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		boxes := glyphs(tc)
+		c.Assert(boxes, check.HasLen, 8)
+
+		// The gap between the glyph bounds of each label, at the eight
+		// compass points, and the anchor radius is the same.
+		for i, box := range boxes {
+			// The nearest point of the bounds to the center lies on
+			// one of its edges.
+			min := math.Inf(1)
+			for j, p := range box {
+				p = p.Sub(cen)
+				e := box[(j+1)%4].Sub(cen).Sub(p)
+				t := -float64(p.X*e.X+p.Y*e.Y) / float64(e.X*e.X+e.Y*e.Y)
+				n := p.Add(e.Scale(vg.Length(math.Max(0, math.Min(1, t)))))
+				min = math.Min(min, math.Hypot(float64(n.X), float64(n.Y)))
+			}
+			c.Check(math.Abs(min-radius) < 0.5, check.Equals, true, check.Commentf("label %d gap %v", i, min-radius))
+		}
+
+		// The measured label bounds agree with the rendering.
+		b := l.Extent()
+		for i, box := range boxes {
+			for _, p := range box {
+				p = p.Sub(cen)
+				c.Check(p.X > b.Min.X-1e-6 && p.X < b.Max.X+1e-6 && p.Y > b.Min.Y-1e-6 && p.Y < b.Max.Y+1e-6,
+					check.Equals, true, check.Commentf("label %d corner %v outside %v", i, p, b))
+			}
+		}
+	}
+
+	// Tick labels of an axis are placed with their nearest edge at the label
+	// anchor, beside the ticks, and the axis label on the other side of the
+	// axis line.
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	t := &rings.Trace{
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+		Axis: &rings.Axis{
+			Angle: rings.AngleQuarter,
+			Label: rings.AxisLabel{Text: "gy", TextStyle: sty, Placement: rings.Horizontal, ExactAnchor: true},
+			Tick: rings.TickConfig{
+				Label:       sty,
+				LineStyle:   plotter.DefaultLineStyle,
+				Placement:   rings.Tangential,
+				Length:      2,
+				Marker:      plot.ConstantTicks{{Value: 0, Label: "gy"}, {Value: 1, Label: "gy"}},
+				ExactAnchor: true,
+			},
+		},
+	}
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(j, _ int) float64 { return float64(j) }), b, 40, 60, t)
+	c.Assert(err, check.Equals, nil)
+	sc.Min, sc.Max = 0, 1
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+	boxes := glyphs(tc)
+	c.Assert(boxes, check.HasLen, 3)
+	for i, box := range boxes {
+		near, far := math.Inf(1), math.Inf(-1)
+		for _, p := range box {
+			// The axis line is vertical and ticks extend in the
+			// winding direction of the base, towards negative x.
+			d := float64(p.X - cen.X)
+			near = math.Min(near, math.Abs(d))
+			far = math.Max(far, math.Abs(d))
+			if i < 2 {
+				c.Check(d < 1e-6, check.Equals, true, check.Commentf("tick label %d on wrong side", i))
+			} else {
+				c.Check(d > -1e-6, check.Equals, true, check.Commentf("axis label on wrong side"))
+			}
+		}
+		want := 4.0
+		if i == 2 {
+			want = 0
+		}
+		c.Check(math.Abs(near-want) < 1e-6, check.Equals, true, check.Commentf("label %d gap %v", i, near))
+	}
+}
+
+func (s *S) TestBlockWeight(c *check.C) {
+	a := &fs{start: 0, end: 100, name: "a"}
+	b := &fs{start: 0, end: 300, name: "b"}
+	r := &fs{start: 0, end: 100, name: "r", orient: feat.Reverse}
+	weights := map[feat.Feature]float64{a: 3, b: 1, r: 2}
+	weight := func(f feat.Feature) float64 { return weights[f] }
+
+	near := func(x, y rings.Angle) bool { return math.Abs(float64(x-y)) < 1e-9 }
+	const gap = 0.05
+	blk, err := rings.NewGappedBlocks([]feat.Feature{a, b, r}, rings.Arc{0, rings.Complete}, 80, 100, gap, rings.BlockWeight(weight))
+	c.Assert(err, check.Equals, nil)
+	c.Check(blk.Validate(), check.Equals, nil)
+
+	// Arcs are allocated by weight rather than length, keeping the gaps
+	// between them and their total angle.
+	g := gap * rings.Complete
+	span := rings.Complete - 3*g
+	theta := g / 2
+	for _, f := range []feat.Feature{a, b, r} {
+		arc, err := blk.ArcOf(f, nil)
+		c.Assert(err, check.Equals, nil)
+		phi := span * rings.Angle(weights[f]/6)
+		if f == r {
+			c.Check(near(arc.Theta, theta+phi) && near(arc.Phi, -phi), check.Equals, true, check.Commentf("%s: %+v", f.Name(), arc))
+		} else {
+			c.Check(near(arc.Theta, theta) && near(arc.Phi, phi), check.Equals, true, check.Commentf("%s: %+v", f.Name(), arc))
+		}
+		theta += phi + g
+	}
+
+	// Positions within a feature are mapped by length to its weighted arc.
+	whole, err := blk.ArcOf(b, nil)
+	c.Assert(err, check.Equals, nil)
+	part := &fs{start: 150, end: 300, location: b, name: "part"}
+	arc, err := blk.ArcOf(b, part)
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(arc.Theta, whole.Theta+whole.Phi/2) && near(arc.Phi, whole.Phi/2), check.Equals, true, check.Commentf("%+v", arc))
+	whole, err = blk.ArcOf(r, nil)
+	c.Assert(err, check.Equals, nil)
+	part = &fs{start: 0, end: 25, location: r, name: "part"}
+	arc, err = blk.ArcOf(r, part)
+	c.Assert(err, check.Equals, nil)
+	c.Check(near(arc.Theta, whole.Theta) && near(arc.Phi, whole.Phi/4), check.Equals, true, check.Commentf("%+v", arc))
+
+	// Ribbons based on the Blocks end within the weighted arcs.
+	pairs := []rings.Pair{vp{feats: [2]feat.Feature{
+		&fs{start: 0, end: 50, location: a, name: "x"},
+		&fs{start: 150, end: 300, location: b, name: "y"},
+	}}}
+	rb, err := rings.NewRibbons(pairs, [2]rings.ArcOfer{blk, blk}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	rb.Color = color.Gray{0x7f}
+	tc := &canvas{dpi: defaultDPI}
+	rb.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var got []vg.PathComp
+	for _, act := range tc.actions {
+		if f, ok := act.(fill); ok {
+			for _, pc := range f.path {
+				if pc.Type == vg.ArcComp && pc.Radius == 70 {
+					got = append(got, pc)
+				}
+			}
+		}
+	}
+	c.Assert(got, check.HasLen, 2)
+	for i, f := range pairs[0].Features() {
+		arc, err := blk.ArcOf(f.Location(), f)
+		c.Assert(err, check.Equals, nil)
+		if i == 1 {
+			// The second end is traced from its far end.
+			arc = rings.Arc{Theta: arc.Theta + arc.Phi, Phi: -arc.Phi}
+		}
+		c.Check(near(rings.Angle(got[i].Start), rings.Normalize(arc.Theta)), check.Equals, true, check.Commentf("end %d", i))
+		c.Check(near(rings.Angle(got[i].Angle), arc.Phi), check.Equals, true, check.Commentf("end %d", i))
+	}
+	c.Check(near(rings.Angle(-got[1].Angle), span/12), check.Equals, true)
+
+	// Weighted arcs are retained until the Set is changed, and invalid
+	// weights are reported.
+	before, err := blk.ArcOf(a, nil)
+	c.Assert(err, check.Equals, nil)
+	weights[b] = 0
+	c.Check(blk.Validate(), check.ErrorMatches, `(?s).*invalid weight 0 of feature "b".*`)
+	arc, err = blk.ArcOf(a, nil)
+	c.Check(err, check.Equals, nil)
+	c.Check(arc, check.Equals, before)
+	blk.Set = append([]feat.Feature(nil), blk.Set...)
+	_, err = blk.ArcOf(a, nil)
+	c.Check(err, check.ErrorMatches, `rings: invalid weight 0 of feature "b"`)
+	_, err = rings.NewGappedBlocks([]feat.Feature{a, b, r}, rings.Arc{0, rings.Complete}, 80, 100, gap, rings.BlockWeight(weight))
+	c.Check(err, check.ErrorMatches, `rings: invalid weight 0 of feature "b"`)
+	_, err = rings.NewGappedBlocks([]feat.Feature{a, b, r}, rings.Arc{0, rings.Complete}, 80, 100, gap, rings.BlockWeight(nil))
+	c.Check(err, check.ErrorMatches, "rings: nil block weight function")
+}
+
+func (s *S) TestColorsFor(c *check.C) {
+	p := []color.Color{color.Gray{10}, color.Gray{20}, color.Gray{30}, color.Gray{40}}
+
+	// Names hash to fixed slots of the palette.
+	for _, t := range []struct {
+		name string
+		want color.Color
+	}{
+		{name: "chr1", want: p[3]},
+		{name: "chr2", want: p[2]},
+		{name: "chr3", want: p[1]},
+		{name: "chr4", want: p[0]},
+		{name: "chr5", want: p[3]},
+		{name: "chrX", want: p[0]},
+	} {
+		c.Check(rings.ColorOf(t.name, p), check.Equals, t.want, check.Commentf("%s", t.name))
+	}
+	c.Check(rings.ColorOf("chr1", nil), check.Equals, nil)
+
+	chr1 := &fs{start: 0, end: 100, name: "chr1"}
+	chr2 := &fs{start: 0, end: 100, name: "chr2"}
+	chr4 := &fs{start: 0, end: 100, name: "chr4"}
+	chr5 := &fs{start: 0, end: 100, name: "chr5"}
+	chrX := &fs{start: 0, end: 100, name: "chrX"}
+
+	// Colliding features are given the next free color, independently of the
+	// order of the features.
+	want := map[feat.Feature]color.Color{chr1: p[3], chr2: p[2], chr5: p[1], chrX: p[0]}
+	for _, set := range [][]feat.Feature{
+		{chr1, chr5, chrX, chr2},
+		{chr2, chrX, chr5, chr1, chr5, nil},
+	} {
+		c.Check(rings.ColorsFor(set, p), check.DeepEquals, want)
+	}
+
+	// Features beyond the size of the palette are given their hash colors.
+	c.Check(rings.ColorsFor([]feat.Feature{chrX, chr5, chr4, chr2, chr1}, p), check.DeepEquals,
+		map[feat.Feature]color.Color{chr1: p[3], chr2: p[2], chr4: p[0], chr5: p[1], chrX: p[0]})
+	c.Check(rings.ColorsFor([]feat.Feature{chr1}, nil), check.HasLen, 0)
+
+	// Blocks and links consume the assigned colors.
+	set := []feat.Feature{chr1, chr2, chr5}
+	colors := rings.ColorsFor(set, p)
+	b, err := rings.NewGappedBlocks(set, rings.Arc{0, rings.Complete}, 80, 100, 0.01, rings.BlockColors(colors), rings.BlockFill(color.White))
+	c.Assert(err, check.Equals, nil)
+	tc := &canvas{dpi: defaultDPI}
+	b.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var fills []color.Color
+	for i, a := range tc.actions {
+		if _, ok := a.(fill); ok {
+			fills = append(fills, tc.actions[i-1].(setColor).col)
+		}
+	}
+	c.Check(fills, check.DeepEquals, []color.Color{p[3], p[2], p[0]})
+
+	pair := vp{feats: [2]feat.Feature{
+		&fs{start: 10, end: 20, location: chr1, name: "a"},
+		&fs{start: 10, end: 20, location: chr5, name: "b"},
+	}}
+	for end, want := range []color.Color{p[3], p[0]} {
+		w := color.NRGBAModel.Convert(want).(color.NRGBA)
+		w.A = 0x80
+		c.Check(rings.ColorByLocation(b, end, 0.5, plotter.DefaultLineStyle)(pair).Color, check.Equals, w)
+		c.Check(rings.ColorByFeature(colors, end, 0.5, plotter.DefaultLineStyle)(pair).Color, check.Equals, w)
+	}
+	other := vp{feats: [2]feat.Feature{&fs{start: 10, end: 20, name: "c"}, nil}}
+	c.Check(rings.ColorByFeature(colors, 0, 1, plotter.DefaultLineStyle)(other).Color, check.Equals,
+		color.NRGBAModel.Convert(plotter.DefaultLineStyle.Color))
+}
+
+func (s *S) TestSimplify(c *check.C) {
+	var pa vg.Path
+	pa.Move(vg.Point{0, 0})
+	// Nearly collinear points are removed.
+	pa.Line(vg.Point{10, 0.01})
+	pa.Line(vg.Point{20, -0.01})
+	pa.Line(vg.Point{30, 0})
+	// A corner is retained.
+	pa.Line(vg.Point{30, 10})
+	pa.Arc(vg.Point{30, 20}, 10, -math.Pi/2, math.Pi)
+	// The run after the arc begins at the end of the arc.
+	pa.Line(vg.Point{20, 30.01})
+	pa.Line(vg.Point{10, 30})
+	pa.Close()
+
+	orig := append(vg.Path(nil), pa...)
+	got := rings.Simplify(pa, 0.1)
+	c.Check(pa, check.DeepEquals, orig, check.Commentf("input path altered"))
+
+	var want vg.Path
+	want.Move(vg.Point{0, 0})
+	want.Line(vg.Point{30, 0})
+	want.Line(vg.Point{30, 10})
+	want.Arc(vg.Point{30, 20}, 10, -math.Pi/2, math.Pi)
+	want.Line(vg.Point{10, 30})
+	want.Close()
+	c.Check(got, check.DeepEquals, want)
+
+	c.Check(rings.Simplify(pa, 0.001), check.DeepEquals, orig)
+	c.Check(rings.Simplify(pa, 0), check.DeepEquals, orig)
+	if got := rings.Simplify(pa, 0); len(got) != 0 {
+		c.Check(&got[0] == &pa[0], check.Equals, true, check.Commentf("path copied with zero tolerance"))
+	}
+
+	// Coordinates are rounded by a RoundingCanvas, except at arcs.
+	pa = pa[:0]
+	pa.Move(vg.Point{1.2345, 6.789})
+	pa.Line(vg.Point{1.23, 6.81})
+	pa.Line(vg.Point{2.04, 6.8})
+	pa.Arc(vg.Point{5.56, 5.64}, 2.345, 0, math.Pi)
+	pa.Line(vg.Point{9.87, 5.64})
+	for _, test := range []struct {
+		rc   rings.RoundingCanvas
+		want vg.Path
+	}{
+		{
+			rc: rings.RoundingCanvas{Precision: 1},
+			want: vg.Path{
+				{Type: vg.MoveComp, Pos: vg.Point{1.2, 6.8}},
+				{Type: vg.LineComp, Pos: vg.Point{2.04, 6.8}},
+				{Type: vg.ArcComp, Pos: vg.Point{5.56, 5.64}, Radius: 2.345, Start: 0, Angle: math.Pi},
+				{Type: vg.LineComp, Pos: vg.Point{9.9, 5.6}},
+			},
+		},
+		{
+			// Whole dots of a 36 DPI canvas are 2pt.
+			rc: rings.RoundingCanvas{DPI: 36},
+			want: vg.Path{
+				{Type: vg.MoveComp, Pos: vg.Point{2, 6}},
+				{Type: vg.LineComp, Pos: vg.Point{2.04, 6.8}},
+				{Type: vg.ArcComp, Pos: vg.Point{5.56, 5.64}, Radius: 2.345, Start: 0, Angle: math.Pi},
+				{Type: vg.LineComp, Pos: vg.Point{10, 6}},
+			},
+		},
+	} {
+		tc := &canvas{dpi: defaultDPI}
+		test.rc.Canvas = tc
+		test.rc.Fill(pa)
+		test.rc.Stroke(pa)
+		c.Assert(tc.actions, check.HasLen, 2)
+		c.Check(tc.actions[0].(fill).path, check.DeepEquals, test.want)
+		c.Check(tc.actions[1].(stroke).path, check.DeepEquals, test.want)
+	}
+}
+
+func (s *S) TestWindowScores(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 250, name: "chr1"},
+		&fs{start: 0, end: 130, name: "chr2"},
+	}
+	nan := math.NaN()
+	a := []rings.Scorer{
+		&fs{start: 0, end: 50, location: chr[0], scores: []float64{1}},
+		&fs{start: 50, end: 150, location: chr[0], scores: []float64{3}},
+		&fs{start: 150, end: 250, location: chr[0], scores: []float64{nan}},
+		&fs{start: 0, end: 130, location: chr[1], scores: []float64{4}},
+	}
+	// The same scores binned differently.
+	var b []rings.Scorer
+	for _, f := range a {
+		for from := f.Start(); from < f.End(); from += 25 {
+			to := from + 25
+			if to > f.End() {
+				to = f.End()
+			}
+			b = append(b, &fs{start: from, end: to, location: f.Location(), scores: f.Scores()})
+		}
+	}
+
+	_, err := rings.WindowScores(a, 0)
+	c.Check(err, check.ErrorMatches, "rings: invalid window size 0")
+
+	type window struct {
+		name  string
+		score string
+	}
+	want := []window{
+		{name: "chr1:0-100", score: "2"},
+		{name: "chr1:100-200", score: "3"},
+		{name: "chr1:200-250", score: "NaN"},
+		{name: "chr2:0-100", score: "4"},
+		{name: "chr2:100-130", score: "4"},
+	}
+	for _, set := range [][]rings.Scorer{a, b} {
+		ws, err := rings.WindowScores(set, 100)
+		c.Assert(err, check.Equals, nil)
+		var got []window
+		for _, w := range ws {
+			got = append(got, window{name: w.Name(), score: fmt.Sprint(w.Scores()[0])})
+		}
+		c.Check(got, check.DeepEquals, want)
+	}
+
+	// Windows are rendered at the arcs of their coordinates, following zooms,
+	// and the partial final window of a location is not stretched. Windows
+	// within a zoomed region are divided by the scale of the zoom.
+	base, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	base.Zooms = []rings.Zoom{{Location: chr[1], Start: 0, End: 100, Scale: 2}}
+	want = []window{
+		{name: "chr1:0-100", score: "2"},
+		{name: "chr1:100-200", score: "3"},
+		{name: "chr1:200-250", score: "NaN"},
+		{name: "chr2:0-50", score: "4"},
+		{name: "chr2:50-100", score: "4"},
+		{name: "chr2:100-130", score: "4"},
+	}
+	var arcs [2][]rings.ArcGeometry
+	for i, set := range [][]rings.Scorer{a, b} {
+		sc, err := rings.NewScores(set, base, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}}, rings.ScoreWindow(100))
+		c.Assert(err, check.Equals, nil)
+		// The range is that of the windowed scores.
+		min, max := sc.ScoreRange()
+		c.Check([]float64{min, max}, check.DeepEquals, []float64{2, 4})
+		g, err := sc.Describe()
+		c.Assert(err, check.Equals, nil)
+		c.Assert(g.Scores, check.HasLen, len(want))
+		for j, w := range g.Scores {
+			c.Check(w.Name, check.Equals, want[j].name)
+			arcs[i] = append(arcs[i], w.Arc)
+		}
+	}
+	c.Check(arcs[0], check.DeepEquals, arcs[1])
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+	for j, want := range []struct{ start, end int }{{0, 100}, {100, 200}, {200, 250}, {0, 50}, {50, 100}, {100, 130}} {
+		loc := chr[0]
+		if j > 2 {
+			loc = chr[1]
+		}
+		arc, err := base.ArcOf(loc, &fs{start: want.start, end: want.end, location: loc})
+		c.Assert(err, check.Equals, nil)
+		got := arcs[0][j]
+		c.Check(near(got.Theta, float64(arc.Theta)) && near(got.Phi, float64(arc.Phi)), check.Equals, true,
+			check.Commentf("window %d: got %v want %v", j, got, arc))
+	}
+	c.Check(near(arcs[0][2].Phi, arcs[0][0].Phi/2), check.Equals, true)
+
+	// The windows follow changes to the zooms of the Base.
+	sc, err := rings.NewScores(a, base, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}}, rings.ScoreWindow(100))
+	c.Assert(err, check.Equals, nil)
+	g, err := sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Scores, check.HasLen, 6)
+	base.Zooms = nil
+	g, err = sc.Describe()
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Scores, check.HasLen, 5)
+
+	_, err = rings.NewScores(a, base, 40, 60, &rings.Heat{Palette: []color.Color{color.Black}}, rings.ScoreWindow(-1))
+	c.Check(err, check.ErrorMatches, "rings: invalid window size -1")
+}
+
+func (s *S) TestConstantScoreRange(c *check.C) {
+	chr1 := &fs{start: 0, end: 100, name: "chr1"}
+	chr2 := &fs{start: 0, end: 100, name: "chr2"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr1, chr2}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	// The scores of chr2 are all equal.
+	set := append(
+		makeScorers(chr1, 4, 1, func(i, _ int) float64 { return float64(i) }),
+		makeScorers(chr2, 4, 1, func(_, _ int) float64 { return 3 })...,
+	)
+	sc, err := rings.NewScores(set, b, 40, 60, &rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+	sc.PerFeatureRange = true
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	for _, test := range []struct {
+		includeZero, symmetric bool
+		want                   [2]float64
+		rad                    vg.Length
+	}{
+		{want: [2]float64{2, 4}, rad: 50},
+		{includeZero: true, want: [2]float64{0, 3}, rad: 60},
+		{symmetric: true, want: [2]float64{-3, 3}, rad: 60},
+	} {
+		sc.IncludeZero, sc.Symmetric = test.includeZero, test.symmetric
+		min, max := sc.LocationRange(chr2)
+		c.Check([2]float64{min, max}, check.Equals, test.want)
+		rad, ok := sc.ScoreRadius(set[len(set)-1], 3)
+		c.Check(ok, check.Equals, true)
+		c.Check(rad, check.Equals, test.rad)
+	}
+
+	// A constant Set has a widened automatic range and is drawn.
+	sc.Set, sc.PerFeatureRange, sc.IncludeZero, sc.Symmetric = set[4:], false, false, false
+	sc.Min, sc.Max = 0, 0
+	min, max := sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{2, 4})
+	tc := &canvas{dpi: defaultDPI}
+	sc.DrawAt(draw.NewCanvas(tc, 300, 300), vg.Point{150, 150})
+	var strokes int
+	for _, a := range tc.actions {
+		if st, ok := a.(stroke); ok {
+			strokes++
+			for _, p := range st.path {
+				c.Check(math.IsNaN(float64(p.Pos.X)) || math.IsNaN(float64(p.Radius)), check.Equals, false)
+			}
+		}
+	}
+	c.Check(strokes, check.Not(check.Equals), 0)
+
+	// A shared Range of equal scores is widened.
+	r := rings.NewRange()
+	r.Include(sc)
+	min, max = r.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{2, 4})
+}
+
+func (s *S) TestScoresAutoRange(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	sc, err := rings.NewScores(makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) }), b, 40, 60,
+		&rings.Trace{LineStyles: []draw.LineStyle{plotter.DefaultLineStyle}})
+	c.Assert(err, check.Equals, nil)
+
+	// The range of a constructed Scores is unset and follows the Set.
+	c.Check(math.IsNaN(sc.Min) && math.IsNaN(sc.Max), check.Equals, true)
+	min, max := sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 3})
+	sc.Set = append(sc.Set, &fs{start: 0, end: 10, location: chr, scores: []float64{7}})
+	min, max = sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 7})
+	c.Check(sc.Validate(), check.Equals, nil)
+
+	// A shared range assigned directly is used and includes the scores.
+	sc.Range = rings.NewRange()
+	sc.Range.Include(sc)
+	sc.Range.Padding = 0.5
+	min, max = sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-3.5, 10.5})
+
+	// An explicit range is used in place of the shared range.
+	sc.Min, sc.Max = -1, 1
+	min, max = sc.ScoreRange()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{-1, 1})
+	other := rings.NewRange()
+	other.Include(sc)
+	min, max = other.Values()
+	c.Check([2]float64{min, max}, check.Equals, [2]float64{0, 0})
+}
+
+func (s *S) TestScoresWindowCache(c *check.C) {
+	chr := &fs{start: 0, end: 200, name: "chr"}
+	arcs := rings.NewGappedArcs(rings.Arc{0, rings.Complete}, []feat.Feature{chr}, 0)
+	set := makeScorers(chr, 4, 1, func(i, _ int) float64 { return float64(i) })
+
+	// The window option does not discard a range set by a preceding option.
+	sc, err := rings.NewScores(set, arcs, 40, 60, &rings.Heat{Palette: []color.Color{color.Black, color.White}},
+		rings.ScoreMinMax(-1, 5), rings.ScoreWindow(100))
+	c.Assert(err, check.Equals, nil)
+	c.Check([2]float64{sc.Min, sc.Max}, check.Equals, [2]float64{-1, 5})
+
+	// The windows are retained between renderings.
+	h := &rings.HitTester{}
+	sc.HitTester = h
+	hit := func() feat.Feature {
+		h.Reset()
+		sc.DrawAt(draw.NewCanvas(&canvas{dpi: defaultDPI}, 300, 300), vg.Point{})
+		got := h.Hit(rings.Rectangular(math.Pi/4, 50))
+		c.Assert(got, check.HasLen, 1)
+		return got[0].Feature
+	}
+	w := hit()
+	c.Check(w.Name(), check.Equals, "chr:0-100")
+	c.Check(hit(), check.Equals, w)
+
+	// The windows are determined again when the Set or Window is changed.
+	sc.Set = append(sc.Set[:len(sc.Set):len(sc.Set)], &fs{start: 0, end: 100, location: chr, scores: []float64{10}})
+	n := hit()
+	c.Check(n, check.Not(check.Equals), w)
+	c.Check(n.(rings.Scorer).Scores()[0], check.Equals, (0.5*100+10*100)/200)
+	sc.Window = 50
+	c.Check(hit().Name(), check.Equals, "chr:0-50")
+}
+
+func (s *S) TestHashed(c *check.C) {
+	chr := &fs{start: 0, end: 100, name: "chr"}
+	b, err := rings.NewGappedBlocks([]feat.Feature{chr}, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+	b.Color = color.Black
+	b.Layer = 2
+	h := &rings.Hashed{Plotter: b, Key: 1}
+	c.Check(h.DrawLayer(), check.Equals, 2)
+	c.Check(h.Extent(), check.Equals, rings.Bounds(b))
+
+	r := rings.NewRetained(vg.Point{150, 150}, h)
+	render := func() []interface{} {
+		tc := &canvas{dpi: defaultDPI}
+		c.Assert(r.Render(draw.NewCanvas(tc, 300, 300)), check.Equals, nil)
+		return tc.actions
+	}
+
+	// The retained rendering of the wrapped track is replayed while
+	// the key is unchanged, and the track is rendered again when the
+	// key changes.
+	first := render()
+	c.Assert(first, check.Not(check.HasLen), 0)
+	b.Color = color.White
+	c.Check(render(), check.DeepEquals, first)
+	h.Key++
+	c.Check(render(), check.Not(check.DeepEquals), first)
+}
+
+func (s *S) TestRibbonsClip(c *check.C) {
+	chr := []feat.Feature{
+		&fs{start: 0, end: 100, name: "chr1"},
+		&fs{start: 0, end: 100, name: "chr2"},
+	}
+	b, err := rings.NewGappedBlocks(chr, rings.Arc{0, rings.Complete}, 80, 100, 0)
+	c.Assert(err, check.Equals, nil)
+
+	cen := vg.Point{150, 150}
+	// radii returns the range of the distances from cen of the points of the
+	// filled and stroked paths of the rendered ribbons.
+	radii := func(r *rings.Ribbons) (fills int, min, max vg.Length) {
+		tc := &canvas{dpi: defaultDPI}
+		r.DrawAt(draw.NewCanvas(tc, 300, 300), cen)
+		min = vg.Length(math.Inf(1))
+		for _, a := range tc.actions {
+			var pa vg.Path
+			switch a := a.(type) {
+			case fill:
+				fills++
+				pa = a.path
+			case stroke:
+				pa = a.path
+			default:
+				continue
+			}
+			for _, p := range pa {
+				if p.Type == vg.CloseComp {
+					continue
+				}
+				_, d := rings.Polar(p.Pos.Sub(cen))
+				if p.Type == vg.ArcComp {
+					d = p.Radius
+				}
+				if d < min {
+					min = d
+				}
+				if d > max {
+					max = d
+				}
+			}
+		}
+		return fills, min, max
+	}
+
+	// Ribbons between opposite features pass close to the center.
+	across := []rings.Pair{fp{
+		feats: [2]*fs{
+			{start: 10, end: 20, location: chr[0], style: plotter.DefaultLineStyle},
+			{start: 10, end: 20, location: chr[1], style: plotter.DefaultLineStyle},
+		},
+		sty: plotter.DefaultLineStyle,
+	}}
+	r, err := rings.NewRibbons(across, [2]rings.ArcOfer{b, b}, [2]vg.Length{70, 70})
+	c.Assert(err, check.Equals, nil)
+	r.Color = color.Gray16{0x8000}
+	r.Bezier = &rings.Bezier{Segments: 20, Radius: rings.LengthDist{Length: 10}}
+	fills, min, max := radii(r)
+	c.Check(fills, check.Equals, 1)
+	c.Check(min < 30, check.Equals, true, check.Commentf("min radius %v", min))
+	c.Check(math.Abs(float64(max-70)) < 1e-6, check.Equals, true, check.Commentf("max radius %v", max))
+
+	// The fill and outline are cut at the inner boundary.
+	r.ClipInner = 30
+	c.Check(r.Validate(), check.Equals, nil)
+	fills, min, max = radii(r)
+	c.Check(fills, check.Equals, 1)
+	c.Check(min >= 30-1e-6, check.Equals, true, check.Commentf("min radius %v", min))
+	c.Check(math.Abs(float64(max-70)) < 1e-6, check.Equals, true, check.Commentf("max radius %v", max))
+
+	// Ribbons entirely outside the annulus are not drawn.
+	r.ClipInner, r.ClipOuter = 75, 90
+	fills, _, _ = radii(r)
+	c.Check(fills, check.Equals, 0)
+
+	r.ClipInner, r.ClipOuter = 90, 80
+	c.Check(r.Validate(), check.ErrorMatches, `(?s).*clip inner radius 90 greater than clip outer radius 80.*`)
+	r.ClipInner, r.ClipOuter = -1, 0
+	c.Check(r.Validate(), check.ErrorMatches, `(?s).*negative clip inner radius -1.*`)
+}
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"image/color"
-	"math"
 	"sort"
 
 	"github.com/gonum/plot"
@@ -65,10 +64,21 @@ type Sail struct {
 	// rendered filled with the specified color, otherwise no fill is performed.
 	Color color.Color
 
+	// Pattern determines the fill pattern of each sail. If Pattern is not nil each
+	// sail is rendered filled with the pattern, over any fill color.
+	Pattern Patterner
+
+	// FillRule determines how the fill color and pattern fill a sail whose outline
+	// overlaps itself. If FillRule is DefaultRule the rule of the canvas is used.
+	FillRule FillRule
+
 	// LineStyle determines the line style of each sail. LineStyle behaviour is over-ridden
 	// for end point arcs if the feature describing an end point is a LineStyler.
 	LineStyle draw.LineStyle
 
+	// Layer specifies the drawing layer of the Sail when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
@@ -205,7 +215,7 @@ func (r *Sail) DrawAt(ca draw.Canvas, cen vg.Point) {
 	r.twist(af)
 
 	var pa vg.Path
-	pa.Move(cen.Add(Rectangular(af[0].angles[0], r.Radius)))
+	pa.Move(RectangularAt(cen, af[0].angles[0], r.Radius))
 	arcs := make([]int, len(af))
 	for i, f := range af {
 		// Arc from f.angles[0] to f.angles[1] with radius r.Radius around cen.
@@ -229,13 +239,19 @@ func (r *Sail) DrawAt(ca draw.Canvas, cen vg.Point) {
 				pa.Line(cen.Add(b.Point(float64(i) / float64(r.Bezier.Segments))))
 			}
 		} else {
-			pa.Line(cen.Add(Rectangular(next, r.Radius)))
+			pa.Line(RectangularAt(cen, next, r.Radius))
 		}
 	}
 
-	if r.Color != nil {
-		ca.SetColor(r.Color)
-		ca.Fill(pa)
+	if r.Color != nil || r.Pattern != nil {
+		fill := r.FillRule.outline(pa)
+		if r.Color != nil {
+			ca.SetColor(r.Color)
+			ca.Fill(fill)
+		}
+		if r.Pattern != nil {
+			r.Pattern.Fill(ca, fill)
+		}
 	}
 
 	if r.LineStyle.Color != nil && r.LineStyle.Width != 0 {
@@ -246,7 +262,7 @@ func (r *Sail) DrawAt(ca draw.Canvas, cen vg.Point) {
 				end := f.angles[1]
 				pa[arcs[i]] = vg.PathComp{
 					Type: vg.MoveComp,
-					Pos:  cen.Add(Rectangular(end, r.Radius)),
+					Pos:  RectangularAt(cen, end, r.Radius),
 				}
 			}
 		}
@@ -264,7 +280,7 @@ func (r *Sail) DrawAt(ca draw.Canvas, cen vg.Point) {
 			//Arc from f.angles[0] to f.angles[1] with radius r.Radius around cen.
 			start := f.angles[0]
 			end := f.angles[1]
-			pa.Move(cen.Add(Rectangular(start, r.Radius)))
+			pa.Move(RectangularAt(cen, start, r.Radius))
 			pa.Arc(cen, r.Radius, float64(start), float64(end-start))
 			ca.SetLineStyle(ls.LineStyle())
 			ca.Stroke(pa)
@@ -272,6 +288,9 @@ func (r *Sail) DrawAt(ca draw.Canvas, cen vg.Point) {
 	}
 }
 
+// DrawLayer returns the drawing layer of the Sail.
+func (r *Sail) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Sail's X and Y values as the drawing coordinates.
 func (r *Sail) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
@@ -284,7 +303,7 @@ func (r *Sail) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 		return nil
 	}
 
-	rad := float64(r.Radius)
+	rad := r.Radius
 
 	// If draw a Bézier we need to see if the radius is increased,
 	// so we mock the drawing, just keeping a record of the furthest
@@ -329,7 +348,7 @@ func (r *Sail) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 			)
 			for k := 0; k <= r.Bezier.Segments; k++ {
 				e := b.Point(float64(k) / float64(r.Bezier.Segments))
-				if d := math.Hypot(float64(e.X), float64(e.Y)); d > rad {
+				if _, d := Polar(e); d > rad {
 					rad = d
 				}
 			}
@@ -340,8 +359,8 @@ func (r *Sail) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 		X: plt.X.Norm(r.X),
 		Y: plt.Y.Norm(r.Y),
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{vg.Length(-rad), vg.Length(-rad)},
-			Max: vg.Point{vg.Length(rad), vg.Length(rad)},
+			Min: vg.Point{-rad, -rad},
+			Max: vg.Point{rad, rad},
 		},
 	}}
 }
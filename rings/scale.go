@@ -36,6 +36,9 @@ type Scale struct {
 	// Grid describes the scales grid configuration.
 	Grid ScaleGrid
 
+	// Layer specifies the drawing layer of the Scale when rendered by a Layered.
+	Layer int
+
 	X, Y float64
 }
 
@@ -112,8 +115,8 @@ func (r *Scale) DrawAt(ca draw.Canvas, cen vg.Point) {
 
 				angle := Angle(iv-min)*scale + arc.Theta
 
-				pa.Move(cen.Add(Rectangular(angle, r.Grid.Inner)))
-				pa.Line(cen.Add(Rectangular(angle, r.Grid.Outer)))
+				pa.Move(RectangularAt(cen, angle, r.Grid.Inner))
+				pa.Line(RectangularAt(cen, angle, r.Grid.Outer))
 
 				ca.Stroke(pa)
 			}
@@ -123,7 +126,7 @@ func (r *Scale) DrawAt(ca draw.Canvas, cen vg.Point) {
 			start := arc.Theta
 			end := Angle(f.End()-min)*scale + arc.Theta
 			pa = pa[:0]
-			pa.Move(cen.Add(Rectangular(start, r.Radius)))
+			pa.Move(RectangularAt(cen, start, r.Radius))
 			pa.Arc(cen, r.Radius, float64(start), float64(end-start))
 
 			ca.SetLineStyle(r.LineStyle)
@@ -147,8 +150,8 @@ func (r *Scale) DrawAt(ca draw.Canvas, cen vg.Point) {
 				} else {
 					length = r.Tick.Length
 				}
-				pa.Move(cen.Add(Rectangular(angle, r.Radius)))
-				pa.Line(cen.Add(Rectangular(angle, r.Radius+length)))
+				pa.Move(RectangularAt(cen, angle, r.Radius))
+				pa.Line(RectangularAt(cen, angle, r.Radius+r.Tick.direction()*length))
 
 				ca.Stroke(pa)
 			}
@@ -162,31 +165,16 @@ func (r *Scale) DrawAt(ca draw.Canvas, cen vg.Point) {
 				}
 
 				angle := Angle(iv-min)*scale + arc.Theta
-				pt := cen.Add(Rectangular(angle, r.Radius+r.Tick.Length+r.Tick.Label.Font.Extents().Height))
-				var (
-					rot            Angle
-					xalign, yalign float64
-				)
-				if r.Tick.Placement == nil {
-					rot, xalign, yalign = DefaultPlacement(angle)
-				} else {
-					rot, xalign, yalign = r.Tick.Placement(angle)
-				}
-				if rot != 0 {
-					ca.Push()
-					ca.Translate(pt)
-					ca.Rotate(float64(rot))
-					ca.Translate(vg.Point{-pt.X, -pt.Y})
-					ca.FillText(r.Tick.Label, pt, xalign, yalign, mark.Label)
-					ca.Pop()
-				} else {
-					ca.FillText(r.Tick.Label, pt, xalign, yalign, mark.Label)
-				}
+				off := r.Tick.labelOffset(r.Tick.Length + r.Tick.Label.Font.Extents().Height)
+				r.Tick.fillLabel(ca, RectangularAt(cen, angle, r.Radius+off), sideOf(angle, off), angle, r.Tick.text(mark))
 			}
 		}
 	}
 }
 
+// DrawLayer returns the drawing layer of the Scale.
+func (r *Scale) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Scale's X and Y values as the drawing coordinates.
 func (r *Scale) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
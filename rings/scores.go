@@ -5,17 +5,20 @@
 package rings
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"image/color"
 	"math"
 	"sort"
+	"sync"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/vg"
 	"github.com/gonum/plot/vg/draw"
 
 	"github.com/biogo/biogo/feat"
+	"github.com/biogo/graphics/palette"
 )
 
 // Scorer describes features that can provided scored values.
@@ -26,10 +29,16 @@ type Scorer interface {
 
 // ScoreRenderer is a type that produces a graphical representation of a score series
 // for a Scores ring.
+//
+// When a Scores is drawn, its Renderer is configured with a ScoreContext for each
+// group of Scorers sharing radii, each Scorer of the group is passed to Render with
+// the arc it occupies in the Scores' Base, and the Renderer is then closed. The
+// ScoreContext holds everything the renderers of this package use to render scores,
+// so renderers may be implemented outside the package.
 type ScoreRenderer interface {
-	// Configure sets up the ScoreRenderer for set-wide values.
-	// The min and max parameters may be ignored by an implementation.
-	Configure(ca draw.Canvas, cen vg.Point, base ArcOfer, inner, outer vg.Length, min, max float64)
+	// Configure sets up the ScoreRenderer for set-wide values. The
+	// score range of the context may be ignored by an implementation.
+	Configure(ScoreContext)
 
 	// Render renders scores across the specified arc. Rendering may be
 	// performed lazily.
@@ -40,34 +49,238 @@ type ScoreRenderer interface {
 	Close()
 }
 
+// ScoreContext holds the set-wide values used by a ScoreRenderer to render the
+// scores of a Scores ring.
+type ScoreContext struct {
+	// Canvas is the drawing area the scores are rendered to.
+	Canvas draw.Canvas
+
+	// Center is the center of the Scores ring.
+	Center vg.Point
+
+	// Base is the ArcOfer that defines the arcs of the rendered Scorers.
+	Base ArcOfer
+
+	// Inner and Outer are the inner and outer radii of the rendered scores,
+	// excluding any displacement of the Scorers by the Base. Scores are scaled
+	// from Inner at Min to Outer at Max, so Inner is greater than Outer when
+	// the Scores is inverted.
+	Inner, Outer vg.Length
+
+	// Offset, if not nil, returns the radial displacement of the rendering
+	// of a Scorer by the Base. Renderers add the displacement to Inner and
+	// Outer when rendering the Scorer.
+	Offset func(Scorer) vg.Length
+
+	// Min and Max are the score range of the Scores.
+	Min, Max float64
+
+	// Explicit specifies that Min and Max are the explicit range of the
+	// Scores rather than a range determined from scores or a shared Range.
+	Explicit bool
+}
+
+// Radius returns the radius of v scaled linearly from the score range of the context
+// to its radii, and whether v is within the score range. Values outside the range,
+// which are not rendered by the renderers of this package, are clamped to the range.
+func (c ScoreContext) Radius(v float64) (rad vg.Length, ok bool) {
+	return newRadialScale(c.Min, c.Max, c.Inner, c.Outer, nil).radius(v)
+}
+
+// OffsetOf returns the radial displacement of the rendering of f given by the
+// context's Offset function, or zero if Offset is nil.
+func (c ScoreContext) OffsetOf(f Scorer) vg.Length {
+	return scorerOffset(c.Offset, f)
+}
+
+// scorerOffset returns the displacement of f given by offset, or zero if offset
+// is nil.
+func scorerOffset(offset func(Scorer) vg.Length, f Scorer) vg.Length {
+	if offset == nil {
+		return 0
+	}
+	return offset(f)
+}
+
+// DataRanger is a ScoreRenderer that determines the range of the values it renders
+// for a Scorer. A Scores determining its range from its data uses the DataRange of
+// its Renderer in place of the range of the Scorers' scores if the Renderer is a
+// DataRanger.
+type DataRanger interface {
+	// DataRange returns the range of values rendered for the Scorer and
+	// whether any value is rendered.
+	DataRange(Scorer) (min, max float64, ok bool)
+}
+
+// RendererCopier is a ScoreRenderer that can return an unconfigured copy of itself.
+// A Scores renders with a copy of a Renderer that is a RendererCopier, so drawing does
+// not alter the Renderer and the Scores may be drawn concurrently. The ScoreRenderers
+// of this package are RendererCopiers. The score range resolved by a copied Heat or
+// Trace is recorded in the Min and Max fields of the Renderer, as it would be had the
+// Renderer been drawn itself.
+type RendererCopier interface {
+	ScoreRenderer
+	CopyRenderer() ScoreRenderer
+}
+
 // Scores implements rendering of feat.Features as radial blocks.
 type Scores struct {
 	// Set holds a collection of features to render. Scores does not
 	// make any check for Scorer overlap in Set.
 	Set []Scorer
 
+	// Source, if not nil, holds scored intervals that are rendered with the
+	// Scorers of the Set. A Source holds many intervals more compactly than
+	// a Set of Scorers.
+	Source ScoreSource
+
 	// Base defines the targets of the rendered scores.
 	Base ArcOfer
 
+	// SkipFiltered specifies that Scorers held by locations removed by the
+	// keep filter of a Blocks Base, set by the BlockKeep option, are not rendered
+	// and do not contribute to the score range. SkipFiltered is set by NewScores
+	// when Base maps arcs through a Blocks with a keep filter.
+	SkipFiltered bool
+
 	// Renderer is the rendering implementation used to represent the
 	// feature sets score data.
 	Renderer ScoreRenderer
 
-	// Min and Max hold the score range.
+	// Min and Max hold an explicit score range. The range is unset if either is NaN,
+	// as it is for a Scores returned by NewScores or NewScoresFrom, or if both are
+	// zero. The range of a Scores without an explicit range is determined from the
+	// scores of the Set when the Scores is rendered, so it follows changes to the
+	// Set. NaN scores, which renderers do not render, and infinite scores are ignored
+	// when determining the range; if no score remains the range is [0, 0]. A range
+	// determined from scores that are all equal is widened as described by ScoreRange.
 	Min, Max float64
 
+	// IncludeZero specifies that the score range is extended to include zero.
+	IncludeZero bool
+
+	// Symmetric specifies that the score range is extended to be symmetric about
+	// zero, as is appropriate for log-ratio scores.
+	Symmetric bool
+
+	// Padding is the fraction of the width of the score range added to each end of
+	// the range after IncludeZero and Symmetric have been applied.
+	Padding float64
+
+	// PerFeatureRange specifies that the scores of each location are scaled
+	// independently, according to the range of the scores within the location,
+	// adjusted by IncludeZero, Symmetric and Padding, and Min, Max and Range are
+	// ignored. Scores in different locations are then not comparable. A Trace Axis
+	// is drawn for each location at the start of the location's arc and is ticked
+	// according to the location's range.
+	PerFeatureRange bool
+
+	// Range, if not nil, is a score range shared with other Scores and Axes. If the
+	// Scores has no explicit range, it is rendered according to the shared range,
+	// to which its scores contribute, and IncludeZero, Symmetric and Padding are
+	// ignored in favour of those of the Range. The scores of other Scores only
+	// contribute to the shared range if they are included in the Range. Scores
+	// with an explicit range do not use or contribute to the shared range, and
+	// an Axis drawn by their Renderer is ticked according to the explicit range.
+	Range *Range
+
 	// Inner and Outer define the inner and outer radii of the blocks.
 	Inner, Outer vg.Length
 
+	// Invert specifies that scores are scaled from Outer at the minimum of
+	// the score range to Inner at the maximum, so that larger scores are
+	// rendered closer to the center. The Renderer of an inverted Scores is
+	// configured with an Inner radius greater than its Outer radius.
+	Invert bool
+
+	// Pad is the angle removed from each end of the arc of each location before
+	// the arcs of the location's Scorers are determined. The Scorers are mapped
+	// linearly into the remaining arc, so scores of adjacent locations do not meet
+	// at the boundary between the locations. Bands are not padded. A negative Pad
+	// is treated as zero.
+	Pad Angle
+
+	// Background, if not nil, is the fill color of the annulus between Inner and Outer
+	// across the arc of the Base. The background is rendered before the bands and scores.
+	Background color.Color
+
+	// Bands holds score ranges that are shaded across the arc of each location of
+	// the scores. Bands are rendered under the scores, and are scaled linearly between
+	// Inner and Outer according to the score range returned by ScoreRange, with the parts
+	// of bands outside that range not rendered.
+	Bands []ScoreBand
+
+	// Progress, if not nil, is called with the number of Scorers rendered and the
+	// total number of Scorers at the progress interval of the context passed to
+	// DrawAtContext, as set by WithProgressInterval, and on completion when the
+	// Scores is drawn.
+	Progress func(done, total int)
+
+	// HitTester, if not nil, records the geometry of each rendered Scorer.
+	HitTester *HitTester
+
+	// Window, if positive, is the size in bases of the genomic windows into
+	// which the scores of the Set and Source are aggregated by WindowScores
+	// before they are rendered. The arcs of the windows are given by the Base,
+	// so windows follow any zooming of the Base and the windows of tracks of
+	// different Scores with the same Window are aligned. Within the zoomed
+	// regions of a Blocks Base, the windows are anchored at the start of the
+	// region and the window size is divided by the scale of the zoom, so that
+	// zoomed regions are rendered at a finer resolution. The windows are
+	// retained until the Set is reassigned or changes length, or the Source,
+	// Window or Zooms of the Base is changed, so the Scorers of the Set and
+	// their scores must not be altered in place while the Scores is in use.
+	Window int
+
+	// Title is the title of the ring.
+	Title Title
+
+	// Layer specifies the drawing layer of the Scores when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
+
+	// cache holds the Scorers of the Set and Source.
+	cache scorerCache
 }
 
 // NewScores returns a Scores based on the parameters, first checking that the provided features
-// are able to be rendered. An error is returned if the features are not renderable.
-func NewScores(fs []Scorer, base ArcOfer, inner, outer vg.Length, renderer ScoreRenderer) (*Scores, error) {
+// are able to be rendered. The provided options are then applied in order. An error is returned
+// if the features are not renderable, the radii or renderer are not valid, or an option fails.
+func NewScores(fs []Scorer, base ArcOfer, inner, outer vg.Length, renderer ScoreRenderer, opts ...ScoreOption) (*Scores, error) {
+	return newScores(&Scores{Set: fs}, base, inner, outer, renderer, opts)
+}
+
+// NewScoresFrom returns a Scores rendering the intervals of src, first checking that the
+// intervals are able to be rendered. The provided options are then applied in order. An
+// error is returned if the intervals are not renderable, the radii or renderer are not
+// valid, or an option fails.
+func NewScoresFrom(src ScoreSource, base ArcOfer, inner, outer vg.Length, renderer ScoreRenderer, opts ...ScoreOption) (*Scores, error) {
+	if src == nil {
+		return nil, errors.New("rings: nil score source")
+	}
+	if t, ok := src.(*ScoreTrack); ok {
+		if err := t.check(); err != nil {
+			return nil, err
+		}
+	}
+	return newScores(&Scores{Source: src}, base, inner, outer, renderer, opts)
+}
+
+// newScores completes r with the parameters after checking that its Scorers are able
+// to be rendered, and then applies opts in order.
+func newScores(r *Scores, base ArcOfer, inner, outer vg.Length, renderer ScoreRenderer, opts []ScoreOption) (*Scores, error) {
+	if inner > outer {
+		return nil, errors.New("rings: inner radius greater than outer radius")
+	}
+	if renderer == nil {
+		return nil, errors.New("rings: nil score renderer")
+	}
+	r.Base = base
+	r.SkipFiltered = filtering(base)
 	min, max := math.Inf(1), math.Inf(-1)
-	for _, f := range fs {
+	for _, f := range r.scorers() {
 		if f.End() < f.Start() {
 			return nil, errors.New("rings: inverted feature")
 		}
@@ -90,58 +303,388 @@ func NewScores(fs []Scorer, base ArcOfer, inner, outer vg.Length, renderer Score
 	if math.IsInf(max-min, 0) {
 		return nil, errors.New("rings: score range is infinite")
 	}
-	return &Scores{
-		Set:      fs,
-		Base:     base,
-		Renderer: renderer,
-		Inner:    inner,
-		Outer:    outer,
-		Min:      min,
-		Max:      max,
-	}, nil
+	r.Renderer = renderer
+	r.Inner, r.Outer = inner, outer
+	r.Min, r.Max = math.NaN(), math.NaN()
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
 }
 
 // DrawAt renders the feature of a Scores at cen in the specified drawing area,
 // according to the Scores configuration.
 func (r *Scores) DrawAt(ca draw.Canvas, cen vg.Point) {
-	if len(r.Set) == 0 {
-		return
+	r.DrawAtContext(context.Background(), ca, cen)
+}
+
+// DrawAtContext renders the features of a Scores at cen in the specified drawing area,
+// according to the Scores configuration. Rendering is stopped and the error of ctx is
+// returned if ctx is cancelled.
+func (r *Scores) DrawAtContext(ctx context.Context, ca draw.Canvas, cen vg.Point) error {
+	fs := r.scorers()
+	prog, err := newProgress(ctx, r.Progress, len(fs))
+	if err != nil || len(fs) == 0 {
+		return err
+	}
+
+	// Scorers displaced by an Offsetter Base are rendered by a single
+	// configuration of the renderer, which displaces each Scorer by the
+	// offset given by the context. The background is drawn at each of
+	// the offsets.
+	offset := func(f Scorer) vg.Length { return offsetOf(r.Base, f.Location(), f) }
+	if r.Background != nil {
+		seen := make(map[vg.Length]bool)
+		for _, f := range fs {
+			off := offset(f)
+			if seen[off] {
+				continue
+			}
+			seen[off] = true
+			var pa vg.Path
+			annulusPath(&pa, cen, r.Inner+off, r.Outer+off, r.Base.Arc())
+			ca.SetColor(r.Background)
+			ca.Fill(pa)
+		}
+	}
+
+	min, max := r.ScoreRange()
+	sets := [][]Scorer{fs}
+	if r.PerFeatureRange {
+		sets = byLocation(fs)
 	}
+	lo, hi := r.scaled(r.Inner, r.Outer)
+	for _, set := range sets {
+		if r.PerFeatureRange {
+			min, max = r.LocationRange(set[0].Location())
+		}
+		r.drawBands(ca, cen, set, lo, hi, min, max)
+		renderer := r.renderer(set[0].Location())
+		renderer.Configure(ScoreContext{
+			Canvas: ca,
+			Center: cen,
+			Base:   r.Base,
+			Inner:  lo,
+			Outer:  hi,
+			Offset: offset,
+			Min:    min,
+			Max:    max,
 
-	r.Renderer.Configure(ca, cen, r.Base, r.Inner, r.Outer, r.Min, r.Max)
-	for _, f := range r.Set {
-		loc := f.Location()
-		min := loc.Start()
-		max := loc.End()
+			Explicit: r.hasRange() && !r.PerFeatureRange,
+		})
+		for _, f := range set {
+			if err := prog.step(); err != nil {
+				renderer.Close()
+				r.recordRange(renderer)
+				return err
+			}
 
-		if f.Start() < min || f.End() > max {
-			continue
+			loc := f.Location()
+			if f.Start() < loc.Start() || f.End() > loc.End() {
+				continue
+			}
+
+			arc, err := r.arcOf(loc, f)
+			if err != nil {
+				panic(fmt.Sprint("rings: no arc for feature location:", err))
+			}
+			if r.HitTester != nil {
+				off := offset(f)
+				r.HitTester.AddSector(r, f, Sector{Center: cen, Inner: r.Inner + off, Outer: r.Outer + off, Arc: arc})
+			}
+			renderer.Render(arc, f)
+		}
+		renderer.Close()
+		r.recordRange(renderer)
+	}
+	r.Title.drawAt(ca, cen, r.Inner, r.Outer)
+	prog.finish()
+	return nil
+}
+
+// scaled returns the radii to which the minimum and maximum of the score range are
+// scaled given the inner and outer radii of the rendered scores.
+func (r *Scores) scaled(inner, outer vg.Length) (min, max vg.Length) {
+	if r.Invert {
+		return outer, inner
+	}
+	return inner, outer
+}
+
+// ScoreRadius returns the radius at which the score v of the Scorer f is rendered by
+// a Trace, ErrorBand, Interval or Segments, given the current score range of the
+// Scores, the Breaks of the Renderer and any displacement of f by the Base, and
+// whether v is within the score range. Values outside the range are clamped to the
+// range. The radius is resolved from the state of the Scores when ScoreRadius is
+// called, so it follows changes to the Set and score range.
+func (r *Scores) ScoreRadius(f Scorer, v float64) (rad vg.Length, ok bool) {
+	min, max := r.LocationRange(f.Location())
+	return r.scoreRadius(f, v, min, max)
+}
+
+// scoreRadius returns the radius of the score v of f given the score range.
+func (r *Scores) scoreRadius(f Scorer, v, min, max float64) (rad vg.Length, ok bool) {
+	off := offsetOf(r.Base, f.Location(), f)
+	lo, hi := r.scaled(r.Inner+off, r.Outer+off)
+	return newRadialScale(min, max, lo, hi, breaksOf(r.Renderer)).radius(v)
+}
+
+// arcOf returns the arc of the Scorer f held by loc, inset by the Scores' Pad.
+func (r *Scores) arcOf(loc feat.Feature, f Scorer) (Arc, error) {
+	arc, err := r.Base.ArcOf(loc, f)
+	if err != nil {
+		return arc, err
+	}
+	return padded(r.Base, loc, arc, r.Pad)
+}
+
+// ScoreRange returns the score range used to render the Scores. If the Scores has no
+// explicit range and has a shared Range, the range is the shared range. Otherwise the
+// range is given by Min and Max, or determined from the scores of the Set if the range
+// is unset, and is then adjusted according to the IncludeZero, Symmetric and Padding
+// fields. A range determined from scores that are all equal, which leaves no span across
+// which to scale the scores, is widened to [0, v] if IncludeZero is set, and otherwise
+// by one at each end, before it is padded. The returned range is passed to the Renderer's
+// Configure method, so an Axis drawn by a Renderer that adopts the range is ticked
+// according to it. If PerFeatureRange is true, the range of each location is given by
+// LocationRange.
+func (r *Scores) ScoreRange() (min, max float64) {
+	if !r.hasRange() && r.Range != nil {
+		return r.Range.values(r)
+	}
+	min, max = r.Min, r.Max
+	var ok bool
+	if !r.hasRange() {
+		min, max, ok = scoreRange(r.scorers(), r.Renderer)
+		if !ok {
+			min, max = 0, 0
+		}
+	}
+	return adjustRange(min, max, r.IncludeZero, r.Symmetric, r.Padding, ok)
+}
+
+// hasRange returns whether Min and Max hold an explicit score range.
+func (r *Scores) hasRange() bool {
+	return !math.IsNaN(r.Min) && !math.IsNaN(r.Max) && (r.Min != 0 || r.Max != 0)
+}
+
+// LocationRange returns the score range used to render the Scorers located in loc.
+// If PerFeatureRange is false, the range is the range returned by ScoreRange.
+// Otherwise the range is determined from the scores of the Scorers in loc and then
+// adjusted according to the IncludeZero, Symmetric and Padding fields, and widened if
+// the scores of loc are all equal, as described by ScoreRange.
+func (r *Scores) LocationRange(loc feat.Feature) (min, max float64) {
+	if !r.PerFeatureRange {
+		return r.ScoreRange()
+	}
+	var set []Scorer
+	for _, f := range r.scorers() {
+		if f.Location() == loc {
+			set = append(set, f)
 		}
+	}
+	min, max, ok := scoreRange(set, r.Renderer)
+	if !ok {
+		min, max = 0, 0
+	}
+	return adjustRange(min, max, r.IncludeZero, r.Symmetric, r.Padding, ok)
+}
 
-		arc, err := r.Base.ArcOf(loc, f)
+// byLocation returns the Scorers of fs grouped by location, in order of the
+// first appearance of each location.
+func byLocation(fs []Scorer) [][]Scorer {
+	var (
+		sets  [][]Scorer
+		index = make(map[feat.Feature]int)
+	)
+	for _, f := range fs {
+		i, ok := index[f.Location()]
+		if !ok {
+			i = len(sets)
+			index[f.Location()] = i
+			sets = append(sets, nil)
+		}
+		sets[i] = append(sets[i], f)
+	}
+	return sets
+}
+
+// renderer returns the ScoreRenderer used to render the Scorers located in loc.
+// Renderers that are RendererCopiers are copied so that rendering does not alter
+// the Scores' Renderer. When each location has its own range, the Axis of a Trace
+// is placed at the start of the arc of loc.
+func (r *Scores) renderer(loc feat.Feature) ScoreRenderer {
+	rc, ok := r.Renderer.(RendererCopier)
+	if !ok {
+		return r.Renderer
+	}
+	rangeMu.Lock()
+	renderer := rc.CopyRenderer()
+	rangeMu.Unlock()
+	if t, ok := renderer.(*Trace); ok && t.Axis != nil && r.PerFeatureRange {
+		arc, err := r.Base.ArcOf(nil, loc)
 		if err != nil {
 			panic(fmt.Sprint("rings: no arc for feature location:", err))
 		}
-		r.Renderer.Render(arc, f)
+		axis := *t.Axis
+		axis.Angle, axis.Auto = arc.Theta, nil
+		t.Axis = &axis
 	}
-	r.Renderer.Close()
+	return renderer
 }
 
+// rangeMu protects the Min and Max fields of the Heat and Trace Renderers of Scores
+// while they are copied and while the ranges resolved by their copies are recorded.
+var rangeMu sync.Mutex
+
+// recordRange records the score range resolved by renderer, a copy of the Scores'
+// Renderer, in the Renderer if it is a Heat or a Trace.
+func (r *Scores) recordRange(renderer ScoreRenderer) {
+	rangeMu.Lock()
+	defer rangeMu.Unlock()
+	switch orig := r.Renderer.(type) {
+	case *Heat:
+		if h, ok := renderer.(*Heat); ok && h != orig {
+			orig.Min, orig.Max, orig.autoRange = h.Min, h.Max, h.autoRange
+		}
+	case *Trace:
+		if t, ok := renderer.(*Trace); ok && t != orig {
+			orig.Min, orig.Max, orig.autoRange = t.Min, t.Max, t.autoRange
+		}
+	}
+}
+
+// scoreRange returns the range of the finite scores in fs and whether any finite
+// score was found. If rr is a DataRanger, the range is the range of the data ranges
+// of fs.
+func scoreRange(fs []Scorer, rr ScoreRenderer) (min, max float64, ok bool) {
+	min, max = math.Inf(1), math.Inf(-1)
+	if dr, isRanger := rr.(DataRanger); isRanger {
+		for _, f := range fs {
+			if lo, hi, ok := dr.DataRange(f); ok {
+				min = math.Min(min, lo)
+				max = math.Max(max, hi)
+			}
+		}
+		return min, max, min <= max
+	}
+	for _, f := range fs {
+		for _, v := range f.Scores() {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+	return min, max, min <= max
+}
+
+// adjustRange returns the range [min, max] extended to include zero if includeZero is
+// true, to be symmetric about zero if symmetric is true, and then by the fraction
+// padding of its width at each end. If widen is true and the range is degenerate, as
+// is the range of scores that are all equal, it is widened before it is padded so that
+// scores can be scaled within it: to [0, 1] if the range is [0, 0] and includeZero is
+// true without symmetric, and otherwise by one at each end.
+func adjustRange(min, max float64, includeZero, symmetric bool, padding float64, widen bool) (float64, float64) {
+	if includeZero {
+		min = math.Min(min, 0)
+		max = math.Max(max, 0)
+	}
+	if symmetric {
+		m := math.Max(math.Abs(min), math.Abs(max))
+		min, max = -m, m
+	}
+	if widen && min == max {
+		if includeZero && !symmetric {
+			max++
+		} else {
+			min, max = min-1, max+1
+		}
+	}
+	pad := (max - min) * padding
+	return min - pad, max + pad
+}
+
+// ScoreBand is a range of score values shaded by a Scores ring.
+type ScoreBand struct {
+	// Lo and Hi are the limits of the range of the band.
+	Lo, Hi float64
+
+	// Color is the fill color of the band.
+	Color color.Color
+}
+
+// drawBands renders the Scores' Bands across the arcs of the locations of fs between
+// the inner and outer radii scaled according to the score range [min, max], displaced
+// by the offset of each location given by the Base.
+func (r *Scores) drawBands(ca draw.Canvas, cen vg.Point, fs []Scorer, inner, outer vg.Length, min, max float64) {
+	if len(r.Bands) == 0 {
+		return
+	}
+	var (
+		locs []feat.Feature
+		seen = make(map[feat.Feature]bool)
+	)
+	for _, f := range fs {
+		if loc := f.Location(); !seen[loc] {
+			seen[loc] = true
+			locs = append(locs, loc)
+		}
+	}
+
+	scale := newRadialScale(min, max, inner, outer, nil)
+	var pa vg.Path
+	for _, b := range r.Bands {
+		if b.Color == nil || b.Hi <= min || max <= b.Lo || b.Hi <= b.Lo {
+			continue
+		}
+		lo, _ := scale.radius(b.Lo)
+		hi, _ := scale.radius(b.Hi)
+		for _, loc := range locs {
+			arc, err := r.Base.ArcOf(loc, nil)
+			if err != nil {
+				panic(fmt.Sprint("rings: no arc for feature location:", err))
+			}
+			off := offsetOf(r.Base, loc, nil)
+			pa = pa[:0]
+			AnnularWedge(&pa, cen, lo+off, hi+off, arc)
+			ca.SetColor(b.Color)
+			ca.Fill(pa)
+		}
+	}
+}
+
+// RingTitle returns the title of the Scores and the radii it is placed relative to.
+func (r *Scores) RingTitle() (t *Title, inner, outer vg.Length) { return &r.Title, r.Inner, r.Outer }
+
+// DrawLayer returns the drawing layer of the Scores.
+func (r *Scores) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Scores' X and Y values as the drawing coordinates.
 func (r *Scores) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
-// GlyphBoxes returns a liberal glyphbox for the score rendering.
+// PlotContext calls DrawAtContext using the Scores' X and Y values as the drawing coordinates.
+func (r *Scores) PlotContext(ctx context.Context, ca draw.Canvas, plt *plot.Plot) error {
+	trX, trY := plt.Transforms(&ca)
+	return r.DrawAtContext(ctx, ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the score rendering restricted
+// to the base arc. If its Title has a Stack, the title is registered with it.
 func (r *Scores) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if r.Title.Stack != nil {
+		r.Title.Stack.Include(r)
+	}
 	return []plot.GlyphBox{{
-		X: plt.X.Norm(r.X),
-		Y: plt.Y.Norm(r.Y),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{-r.Outer, -r.Outer},
-			Max: vg.Point{r.Outer, r.Outer},
-		},
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(r.Inner, r.Outer),
 	}}
 }
 
@@ -165,67 +708,161 @@ type Heat struct {
 	Underflow color.Color
 	Overflow  color.Color
 
+	// Ramp, if not nil, is used in place of Palette to color scores within the
+	// range of the Heat. Ramp is called with the position of the score within the
+	// range, from 0 at Min to 1 at Max.
+	Ramp palette.ColorFunc
+
+	// Smooth, if positive, specifies that each cell is rendered as a radial
+	// gradient of Smooth slices, blending the color of the cell towards the
+	// colors of its radially adjacent cells so that the colors meet midway
+	// at the boundary between the cells. Cells are not blended towards
+	// adjacent cells that are not rendered.
+	Smooth int
+
+	// Rasterize, if positive, is the resolution in dots per inch at which the
+	// cells are rendered as a single raster image drawn when the Heat is closed,
+	// in place of a vector wedge for each cell. Rasterizing keeps the output of
+	// a Heat with very many cells small. Cell boundaries are antialiased by
+	// supersampling, and pixels outside the cells are transparent. Smooth is
+	// ignored when the cells are rasterized.
+	Rasterize float64
+
+	// cells holds the cells rendered since the Heat was last
+	// closed when the Heat is rasterized.
+	cells []heatCell
+
 	DrawArea draw.Canvas
 
 	Center       vg.Point
 	Inner, Outer vg.Length
 
 	Min, Max float64
+
+	// autoRange indicates that Min and Max were taken from the
+	// Configure parameters.
+	autoRange bool
+
+	// offset is the displacement of each Scorer given
+	// by the configuring context.
+	offset func(Scorer) vg.Length
 }
 
-// Configure is called by Scores' DrawAt method. The min and max parameters are ignored if
-// the Heat's Min and Max fields are both non-zero.
-func (h *Heat) Configure(ca draw.Canvas, cen vg.Point, _ ArcOfer, inner, outer vg.Length, min, max float64) {
-	h.DrawArea = ca
-	h.Center = cen
-	h.Inner = inner
-	h.Outer = outer
-	if h.Max == 0 && h.Min == 0 {
-		h.Min = min
-		h.Max = max
+// Configure is called by Scores' DrawAt method. The score range of c is ignored if
+// the Heat's Min and Max fields are not both zero, unless they were taken from the
+// score range of an earlier Configure call.
+func (h *Heat) Configure(c ScoreContext) {
+	h.DrawArea = c.Canvas
+	h.Center = c.Center
+	h.Inner = c.Inner
+	h.Outer = c.Outer
+	h.offset = c.Offset
+	if h.Max == 0 && h.Min == 0 || h.autoRange {
+		h.Min = c.Min
+		h.Max = c.Max
+		h.autoRange = true
 	}
 }
 
+// CopyRenderer returns an unconfigured copy of the Heat.
+func (h *Heat) CopyRenderer() ScoreRenderer {
+	c := *h
+	c.DrawArea = draw.Canvas{}
+	c.cells = nil
+	return &c
+}
+
 // Render renders the values in scores across the specified arc from inner to outer.
 // Rendering is performed eagerly.
 func (h *Heat) Render(arc Arc, scorer Scorer) {
 	scores := scorer.Scores()
 
 	ps := float64(len(h.Palette)-1) / (h.Max - h.Min)
+	off := scorerOffset(h.offset, scorer)
+
+	if h.Rasterize > 0 {
+		h.record(arc, scores, ps, off)
+		return
+	}
 
 	// Define block progression inner to outer.
 	d := (h.Outer - h.Inner) / vg.Length(len(scores))
-	rad := h.Inner
+	rad := h.Inner + off
+
+	if h.Smooth > 0 {
+		h.renderSmooth(arc, scores, ps, rad, d)
+		return
+	}
 
 	var pa vg.Path
 	for _, v := range scores {
 		pa = pa[:0]
 
-		pa.Move(h.Center.Add(Rectangular(arc.Theta, rad)))
-		pa.Arc(h.Center, rad, float64(arc.Theta), float64(arc.Phi))
+		AnnularWedge(&pa, h.Center, rad, rad+d, arc)
 		rad += d
-		pa.Arc(h.Center, rad, float64(arc.Theta+arc.Phi), float64(-arc.Phi))
-		pa.Close()
-
-		var c color.Color
-		switch {
-		case math.IsNaN(v), math.IsInf(v, 0):
-		case v < h.Min:
-			c = h.Underflow
-		case v > h.Max:
-			c = h.Overflow
-		default:
-			c = h.Palette[int((v-h.Min)*ps+0.5)]
-		}
-		if c != nil {
+
+		if c := h.colorOf(v, h.Min, h.Max, ps); c != nil {
 			h.DrawArea.SetColor(c)
 			h.DrawArea.Fill(pa)
 		}
 	}
 }
 
-// Close is a no-op.
-func (h *Heat) Close() {}
+// renderSmooth renders the cells of scores, each of radial depth d starting from rad,
+// as gradients blending towards the colors of adjacent cells. The inner and outer halves
+// of each cell are rendered as separate gradients meeting at the color of the cell.
+func (h *Heat) renderSmooth(arc Arc, scores []float64, ps float64, rad, d vg.Length) {
+	cols := make([]color.Color, len(scores))
+	for i, v := range scores {
+		cols[i] = h.colorOf(v, h.Min, h.Max, ps)
+	}
+	half := (h.Smooth + 1) / 2
+	for i, c := range cols {
+		if c == nil {
+			rad += d
+			continue
+		}
+		in, out := c, c
+		if i > 0 && cols[i-1] != nil {
+			in = lerpColor(cols[i-1], c, 0.5)
+		}
+		if i < len(cols)-1 && cols[i+1] != nil {
+			out = lerpColor(c, cols[i+1], 0.5)
+		}
+		open := i < len(cols)-1 && cols[i+1] != nil
+		mid := rad + d/2
+		g := GradientFill{Inner: in, Outer: c, Slices: half}
+		g.fill(h.DrawArea, Sector{Center: h.Center, Inner: rad, Outer: mid, Arc: arc}, true)
+		g = GradientFill{Inner: c, Outer: out, Slices: half}
+		g.fill(h.DrawArea, Sector{Center: h.Center, Inner: mid, Outer: rad + d, Arc: arc}, open)
+		rad += d
+	}
+}
+
+// colorOf returns the color used to represent v in the range [min, max], with
+// ps being the number of palette steps per unit value.
+func (h *Heat) colorOf(v, min, max, ps float64) color.Color {
+	switch {
+	case math.IsNaN(v), math.IsInf(v, 0):
+		return nil
+	case v < min:
+		return h.Underflow
+	case v > max:
+		return h.Overflow
+	case h.Ramp != nil:
+		return h.Ramp((v - min) / (max - min))
+	default:
+		return h.Palette[int((v-min)*ps+0.5)]
+	}
+}
+
+// Close draws the raster of the rendered cells if the Heat is rasterized, and is
+// otherwise a no-op.
+func (h *Heat) Close() {
+	if h.Rasterize > 0 {
+		h.drawRaster()
+	}
+}
 
 // Trace is a ScoreRenderer that represents feature scores as a trace line.
 type Trace struct {
@@ -236,6 +873,11 @@ type Trace struct {
 	// It is overridden by the returned value of JoinTrace if the Scorer is a TraceJoiner.
 	Join bool
 
+	// Interpolation specifies the rendering of the traces between the scores
+	// of joined features. The zero value, StepInterpolation, renders each
+	// score as an arc across its feature.
+	Interpolation Interpolation
+
 	Base ArcOfer
 
 	DrawArea draw.Canvas
@@ -245,25 +887,76 @@ type Trace struct {
 
 	Min, Max float64
 
+	// Breaks specifies intervals of score values that are excluded from the radial
+	// scale of the trace and its axis. Traces joining scores across a break are
+	// drawn through the break radius, and scores within a break are not drawn.
+	Breaks []Break
+
 	// Axis represents a radial axis configuration
 	Axis *Axis
 
+	// Segmenter, if not nil, is called with each score value to determine the line
+	// style used to render the trace at that value, in place of LineStyles. Radial
+	// lines joining adjacent scores are split where the returned style changes, at the
+	// radius of the score value at which the change occurs.
+	Segmenter func(v float64) draw.LineStyle
+
+	// Caps, Joins and JoinThreshold specify the shape of the ends and corners of
+	// the trace lines as described by StrokeStyle.
+	Caps          LineCap
+	Joins         LineJoin
+	JoinThreshold Angle
+
+	// Smooth, if not nil, specifies smoothing of the scores before they are rendered.
+	// If the Trace's range is taken from the Configure min and max parameters, the
+	// range of the smoothed scores is used in place of the parameters. Smoothing is
+	// not reflected by DescribeScores.
+	Smooth *Smooth
+
+	// PeakLabels, if not nil, describes labels of the values of the local
+	// maxima of the traces. The labels are rendered after the traces.
+	PeakLabels *PeakLabels
+
 	values arcScores
+
+	// autoRange indicates that Min and Max were taken from the
+	// Configure parameters.
+	autoRange bool
+
+	// explicit indicates that Min and Max are an explicit range,
+	// so the shared Range of the Axis is not used.
+	explicit bool
+
+	// offset is the displacement of each Scorer given
+	// by the configuring context.
+	offset func(Scorer) vg.Length
 }
 
-// Configure is called by Scores' DrawAt method. The min and max parameters are ignored if
-// the Trace's Min and Max fields are both non-zero.
-func (t *Trace) Configure(ca draw.Canvas, cen vg.Point, base ArcOfer, inner, outer vg.Length, min, max float64) {
+// Configure is called by Scores' DrawAt method. The score range of c is ignored if
+// the Trace's Min and Max fields are not both zero, unless they were taken from the
+// score range of an earlier Configure call.
+func (t *Trace) Configure(c ScoreContext) {
 	t.values = t.values[:0]
-	t.DrawArea = ca
-	t.Center = cen
-	t.Base = base
-	t.Inner = inner
-	t.Outer = outer
-	if t.Max == 0 && t.Min == 0 {
-		t.Min = min
-		t.Max = max
+	t.DrawArea = c.Canvas
+	t.Center = c.Center
+	t.Base = c.Base
+	t.Inner = c.Inner
+	t.Outer = c.Outer
+	t.offset = c.Offset
+	if t.Max == 0 && t.Min == 0 || t.autoRange {
+		t.Min = c.Min
+		t.Max = c.Max
+		t.autoRange = true
 	}
+	t.explicit = !t.autoRange || c.Explicit
+}
+
+// CopyRenderer returns an unconfigured copy of the Trace.
+func (t *Trace) CopyRenderer() ScoreRenderer {
+	c := *t
+	c.DrawArea = draw.Canvas{}
+	c.values = nil
+	return &c
 }
 
 // TraceJoiner is a type that can specify whether the traces for its scores should
@@ -280,26 +973,87 @@ func (t *Trace) Render(arc Arc, scorer Scorer) {
 
 // Close renders the added scores and axis.
 func (t *Trace) Close() {
+	sort.Sort(t.values)
+
+	scores := make([][]float64, len(t.values))
+	if t.Smooth != nil {
+		scores = t.Smooth.smooth(t.values)
+		if t.autoRange {
+			min, max := math.Inf(1), math.Inf(-1)
+			for _, s := range scores {
+				for _, v := range s {
+					if !math.IsNaN(v) {
+						min = math.Min(min, v)
+						max = math.Max(max, v)
+					}
+				}
+			}
+			if !math.IsInf(max-min, 0) {
+				t.Min, t.Max = min, max
+			}
+		}
+	} else {
+		for i, v := range t.values {
+			scores[i] = v.Scores()
+		}
+	}
+
 	if t.Axis != nil {
 		set := make([]Scorer, len(t.values))
 		for i, s := range t.values {
 			set[i] = s.Scorer
 		}
-		t.Axis.drawAt(t.DrawArea, t.Center, set, t.Base, t.Inner, t.Outer, t.Min, t.Max)
+		axis := t.Axis
+		if t.explicit && axis.Range != nil {
+			a := *axis
+			a.Range = nil
+			axis = &a
+		}
+		axis.drawAt(t.DrawArea, t.Center, set, t.Base, t.scale(t.Inner, t.Outer, t.Min, t.Max))
 	}
 
-	sort.Sort(t.values)
+	scale := t.scale(t.Inner, t.Outer, t.Min, t.Max)
+	if t.Interpolation == StepInterpolation {
+		t.drawSteps(scores, scale)
+	} else {
+		t.drawCurves(scores, scale)
+	}
 
-	rs := float64(t.Outer-t.Inner) / (t.Max - t.Min)
+	if t.PeakLabels != nil {
+		var n int
+		for _, s := range scores {
+			if len(s) > n {
+				n = len(s)
+			}
+		}
+		var peaks []peak
+		for j := 0; j < n; j++ {
+			peaks = append(peaks, t.PeakLabels.peaks(t.values, scores, j, t.offset)...)
+		}
+		t.PeakLabels.drawAt(t.DrawArea, t.Center, scale, peaks)
+	}
+}
 
-	var pa vg.Path
+// drawSteps renders the traces of scores as arcs at the radii of the scores, joined
+// by radial lines where the Trace joins adjacent features that share a displacement.
+func (t *Trace) drawSteps(scores [][]float64, base radialScale) {
+	var pa, seg vg.Path
 	for i, arc := range t.values {
-		for j, as := range arc.Scores() {
+		off := scorerOffset(t.offset, arc.Scorer)
+		scale := base.shifted(off)
+		for j, as := range scores[i] {
 			if math.IsNaN(as) {
 				continue
 			}
 			pa = pa[:0]
 
+			var sty draw.LineStyle
+			if t.Segmenter != nil {
+				sty = t.Segmenter(as)
+			} else {
+				sty = t.LineStyles[j]
+			}
+
 			if arc.Phi < 0 {
 				arc.Theta, arc.Phi = arc.Theta+arc.Phi, -arc.Phi
 			}
@@ -310,34 +1064,103 @@ func (t *Trace) Close() {
 			} else {
 				join = t.Join
 			}
-			if join && i != 0 && adjacent(t.values[i-1].Scorer, arc.Scorer) {
-				prev := t.values[i-1].Scores()[j]
-				if !math.IsNaN(prev) && ((t.Min <= as && as <= t.Max) || (t.Min <= prev && prev <= t.Max)) {
+			if join && i != 0 && adjacent(t.values[i-1].Scorer, arc.Scorer) && scorerOffset(t.offset, t.values[i-1].Scorer) == off {
+				prev := scores[i-1][j]
+				from, okPrev := scale.radius(prev)
+				to, ok := scale.radius(as)
+				if !math.IsNaN(prev) && (ok || okPrev) {
 					joined = true
 
-					prev = math.Min(math.Max(prev, t.Min), t.Max)
-					as := math.Min(math.Max(as, t.Min), t.Max)
+					if t.Segmenter != nil {
+						// Render the parts of the join with a style differing from
+						// the style of the score separately.
+						vals := t.segmentValues(prev, as)
+						for k, v := range vals[:len(vals)-2] {
+							r0, _ := scale.radius(v)
+							r1, _ := scale.radius(vals[k+1])
+							sty := t.Segmenter(v + (vals[k+1]-v)/2)
+							if r0 == r1 || sty.Color == nil || sty.Width == 0 {
+								continue
+							}
+							seg = seg[:0]
+							seg.Move(RectangularAt(t.Center, arc.Theta, r0))
+							seg.Line(RectangularAt(t.Center, arc.Theta, r1))
+							t.stroke(sty, seg)
+						}
+						from, _ = scale.radius(vals[len(vals)-2])
+					}
 
-					pa.Move(t.Center.Add(Rectangular(arc.Theta, vg.Length((prev-t.Min)*rs)+t.Inner)))
-					pa.Line(t.Center.Add(Rectangular(arc.Theta, vg.Length((as-t.Min)*rs)+t.Inner)))
+					pa.Move(RectangularAt(t.Center, arc.Theta, from))
+					pa.Line(RectangularAt(t.Center, arc.Theta, to))
 				}
 			}
 
-			if t.Min <= as && as <= t.Max {
-				rad := vg.Length((as-t.Min)*rs) + t.Inner
+			if rad, ok := scale.radius(as); ok {
 				if !joined {
-					pa.Move(t.Center.Add(Rectangular(arc.Theta, rad)))
+					pa.Move(RectangularAt(t.Center, arc.Theta, rad))
 				}
 				pa.Arc(t.Center, rad, float64(arc.Theta), float64(arc.Phi))
 			}
 
-			sty := t.LineStyles[j]
-			if sty.Color != nil && sty.Width != 0 {
-				t.DrawArea.SetLineStyle(sty)
-				t.DrawArea.Stroke(pa)
-			}
+			t.stroke(sty, pa)
+		}
+	}
+}
+
+// stroke strokes pa with sty according to the Trace's cap and join configuration.
+func (t *Trace) stroke(sty draw.LineStyle, pa vg.Path) {
+	StrokeStyle{LineStyle: sty, Cap: t.Caps, Join: t.Joins, JoinThreshold: t.JoinThreshold}.Stroke(t.DrawArea, pa)
+}
+
+// segmentValues returns the values from a to b, inclusive, separated at the values at
+// which the style returned by the Trace's Segmenter changes.
+func (t *Trace) segmentValues(a, b float64) []float64 {
+	vals := t.styleChanges([]float64{a}, a, b, t.Segmenter(a), t.Segmenter(b), 64)
+	return append(vals, b)
+}
+
+// styleChanges appends to dst the values between a and b at which the style returned
+// by the Trace's Segmenter changes, given the styles sa and sb at a and b. The values
+// are found by bisection to the given depth.
+func (t *Trace) styleChanges(dst []float64, a, b float64, sa, sb draw.LineStyle, depth int) []float64 {
+	if sameLineStyle(sa, sb) {
+		return dst
+	}
+	m := a + (b-a)/2
+	if depth == 0 || m == a || m == b {
+		return append(dst, m)
+	}
+	sm := t.Segmenter(m)
+	dst = t.styleChanges(dst, a, m, sa, sm, depth-1)
+	return t.styleChanges(dst, m, b, sm, sb, depth-1)
+}
+
+// sameLineStyle returns whether a and b describe the same line style.
+func sameLineStyle(a, b draw.LineStyle) bool {
+	if a.Width != b.Width || a.DashOffs != b.DashOffs || len(a.Dashes) != len(b.Dashes) {
+		return false
+	}
+	for i, d := range a.Dashes {
+		if d != b.Dashes[i] {
+			return false
 		}
 	}
+	return sameColor(a.Color, b.Color)
+}
+
+// sameColor returns whether a and b are the same color.
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+// scale returns the radial scale of the trace given the specified radii and range.
+func (t *Trace) scale(inner, outer vg.Length, min, max float64) radialScale {
+	return newRadialScale(min, max, inner, outer, t.Breaks)
 }
 
 func adjacent(a, b feat.Feature) bool {
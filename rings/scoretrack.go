@@ -0,0 +1,200 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// ScoreSource is a collection of scored intervals that a Scores renders without each
+// interval being held as a separate Scorer. When a Scores with a Source is drawn, its
+// Renderer is passed a lightweight view of each interval that calls At when it is
+// queried, so the scores of an interval are not copied.
+type ScoreSource interface {
+	// Len returns the number of intervals in the collection.
+	Len() int
+
+	// At returns the location, start and end coordinates and scores
+	// of the ith interval. The returned scores must not be modified.
+	At(i int) (loc feat.Feature, start, end int, scores []float64)
+}
+
+// ScoreTrack is a columnar ScoreSource of intervals held by a single location. The
+// ith interval spans [Starts[i], Ends[i]) of Loc and has the scores Values[i].
+type ScoreTrack struct {
+	Loc          feat.Feature
+	Starts, Ends []int32
+	Values       [][]float64
+}
+
+// Len returns the number of intervals in the track.
+func (t *ScoreTrack) Len() int { return len(t.Starts) }
+
+// At returns the location, start and end coordinates and scores of the ith interval
+// of the track.
+func (t *ScoreTrack) At(i int) (loc feat.Feature, start, end int, scores []float64) {
+	return t.Loc, int(t.Starts[i]), int(t.Ends[i]), t.Values[i]
+}
+
+// check returns an error if the columns of the track differ in length.
+func (t *ScoreTrack) check() error {
+	if len(t.Ends) != len(t.Starts) || len(t.Values) != len(t.Starts) {
+		return errors.New("rings: score track column lengths differ")
+	}
+	return nil
+}
+
+// sourceScorer is the Scorer view of an interval of a ScoreSource.
+type sourceScorer struct {
+	src ScoreSource
+	i   int
+}
+
+func (s *sourceScorer) Start() int {
+	_, start, _, _ := s.src.At(s.i)
+	return start
+}
+func (s *sourceScorer) End() int {
+	_, _, end, _ := s.src.At(s.i)
+	return end
+}
+func (s *sourceScorer) Len() int { return s.End() - s.Start() }
+func (s *sourceScorer) Name() string {
+	loc, start, end, _ := s.src.At(s.i)
+	if loc == nil {
+		return fmt.Sprintf("%d-%d", start, end)
+	}
+	return fmt.Sprintf("%s:%d-%d", loc.Name(), start, end)
+}
+func (s *sourceScorer) Description() string { return "score" }
+func (s *sourceScorer) Location() feat.Feature {
+	loc, _, _, _ := s.src.At(s.i)
+	return loc
+}
+func (s *sourceScorer) Scores() []float64 {
+	_, _, _, scores := s.src.At(s.i)
+	return scores
+}
+
+// scorerCache holds the Scorers of a Scores, which are retained while the Set, Source
+// and Window of the Scores are unchanged.
+type scorerCache struct {
+	mu sync.Mutex
+
+	set    *Scorer
+	len    int
+	src    ScoreSource
+	n      int
+	window int
+
+	// zoom and zooms are the address of the first
+	// zoom and the number of zooms of the Base.
+	zoom  *Zoom
+	zooms int
+
+	fs []Scorer
+}
+
+// valid returns whether the cache holds the Scorers of r.
+func (c *scorerCache) valid(r *Scores) bool {
+	if c.fs == nil || c.window != r.Window || c.len != len(r.Set) || c.set != first(r.Set) {
+		return false
+	}
+	if r.Window > 0 {
+		zs := baseZooms(r.Base)
+		if c.zooms != len(zs) || c.zoom != firstZoom(zs) {
+			return false
+		}
+	}
+	if r.Source == nil || c.src == nil {
+		return r.Source == c.src
+	}
+	return isComparable(r.Source) && c.src == r.Source && c.n == r.Source.Len()
+}
+
+// first returns the address of the first element of fs, or nil if fs is empty.
+func first(fs []Scorer) *Scorer {
+	if len(fs) == 0 {
+		return nil
+	}
+	return &fs[0]
+}
+
+// firstZoom returns the address of the first element of zs, or nil if zs is empty.
+func firstZoom(zs []Zoom) *Zoom {
+	if len(zs) == 0 {
+		return nil
+	}
+	return &zs[0]
+}
+
+// scorers returns the Scorers of the Set followed by views of the intervals of the
+// Source. The views are allocated together so that a Source of many intervals is not
+// expanded into many heap objects. If Window is positive, the returned Scorers are
+// the windows of the Scorers, scaled within the zoomed regions of the Base. The
+// Scorers are retained until the Set, Source, Window or zooms of the Base are
+// changed. If SkipFiltered is true, Scorers held by locations filtered
+// from the Base are omitted.
+func (r *Scores) scorers() []Scorer {
+	r.cache.mu.Lock()
+	if !r.cache.valid(r) {
+		fs := r.unwindowed()
+		zs := baseZooms(r.Base)
+		if r.Window > 0 {
+			fs = windowScores(fs, r.Window, zs)
+		}
+		if fs == nil {
+			fs = []Scorer{}
+		}
+		c := &r.cache
+		c.set, c.len = first(r.Set), len(r.Set)
+		c.src, c.n = r.Source, 0
+		if r.Source != nil {
+			c.n = r.Source.Len()
+		}
+		c.window = r.Window
+		c.zoom, c.zooms = firstZoom(zs), len(zs)
+		c.fs = fs
+	}
+	fs := r.cache.fs
+	r.cache.mu.Unlock()
+	return r.filter(fs)
+}
+
+// unwindowed returns the Scorers of the Set followed by views of the intervals of
+// the Source.
+func (r *Scores) unwindowed() []Scorer {
+	fs := r.Set
+	if r.Source != nil {
+		n := r.Source.Len()
+		views := make([]sourceScorer, n)
+		fs = make([]Scorer, len(r.Set), len(r.Set)+n)
+		copy(fs, r.Set)
+		for i := range views {
+			views[i] = sourceScorer{src: r.Source, i: i}
+			fs = append(fs, &views[i])
+		}
+	}
+	return fs
+}
+
+// filter returns fs without the Scorers held by locations filtered from the Base
+// if SkipFiltered is true.
+func (r *Scores) filter(fs []Scorer) []Scorer {
+	if !r.SkipFiltered || !filtering(r.Base) {
+		return fs
+	}
+	kept := make([]Scorer, 0, len(fs))
+	for _, f := range fs {
+		if !isFiltered(r.Base, f) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,198 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Segments is a ScoreRenderer that represents piecewise-constant scores, such as the
+// segments of a copy number segmentation, as arcs spanning the arc of each Scorer at
+// the radius of the Scorer's first score. Other scores of a Scorer are ignored, and
+// Scorers with no scores or a NaN first score are not rendered. Values outside the
+// range of the Segments are clamped to the range.
+type Segments struct {
+	// LineStyle determines the line style of the segment arcs. LineStyle
+	// behaviour is over-ridden if the Scorer is a LineStyler.
+	LineStyle draw.LineStyle
+
+	// Connector, if its Color and Width are set, is the line style of radial
+	// lines joining adjacent segments at their shared boundary.
+	Connector draw.LineStyle
+
+	// Fill, if not nil, is the color of the annular wedges filled between
+	// the radius of each segment and the radius of Baseline. Fill behaviour
+	// is over-ridden if the Scorer is a FillColorer.
+	Fill     color.Color
+	Baseline float64
+
+	// Merge specifies that adjacent segments with equal scores and styles
+	// are rendered as a single arc.
+	Merge bool
+
+	DrawArea draw.Canvas
+
+	Center       vg.Point
+	Inner, Outer vg.Length
+
+	Min, Max float64
+
+	// Breaks specifies intervals of score values that are excluded from the radial
+	// scale of the segments. Segments and baselines with values within a break are
+	// placed at the radius of the break.
+	Breaks []Break
+
+	values arcScores
+
+	// offset is the displacement of each Scorer given
+	// by the configuring context.
+	offset func(Scorer) vg.Length
+}
+
+// Configure is called by Scores' DrawAt method. The score range of c is ignored if
+// the Segments' Min and Max fields are not both zero.
+func (sg *Segments) Configure(c ScoreContext) {
+	sg.values = sg.values[:0]
+	sg.DrawArea = c.Canvas
+	sg.Center = c.Center
+	sg.Inner = c.Inner
+	sg.Outer = c.Outer
+	sg.offset = c.Offset
+	if sg.Max == 0 && sg.Min == 0 {
+		sg.Min = c.Min
+		sg.Max = c.Max
+	}
+}
+
+// DataRange returns the range of the first score of scorer.
+func (sg *Segments) DataRange(scorer Scorer) (min, max float64, ok bool) {
+	v, ok := segmentValue(scorer)
+	if !ok || math.IsInf(v, 0) {
+		return 0, 0, false
+	}
+	return v, v, true
+}
+
+// segmentValue returns the first score of scorer and whether it is renderable.
+func segmentValue(scorer Scorer) (float64, bool) {
+	s := scorer.Scores()
+	if len(s) == 0 || math.IsNaN(s[0]) {
+		return 0, false
+	}
+	return s[0], true
+}
+
+// radius returns the radius of v, clamped to the range of the Segments and skipping
+// its Breaks, displaced by off.
+func (sg *Segments) radius(v float64, off vg.Length) vg.Length {
+	rad, _ := newRadialScale(sg.Min, sg.Max, sg.Inner+off, sg.Outer+off, sg.Breaks).radius(v)
+	return rad
+}
+
+// CopyRenderer returns an unconfigured copy of the Segments.
+func (sg *Segments) CopyRenderer() ScoreRenderer {
+	c := *sg
+	c.DrawArea = draw.Canvas{}
+	c.values = nil
+	return &c
+}
+
+// Render adds the segment at the specified arc for lazy rendering.
+func (sg *Segments) Render(arc Arc, scorer Scorer) {
+	if _, ok := segmentValue(scorer); !ok {
+		return
+	}
+	if arc.Phi < 0 {
+		arc.Theta, arc.Phi = arc.Theta+arc.Phi, -arc.Phi
+	}
+	sg.values = append(sg.values, arcScore{arc, scorer})
+}
+
+// segment is a rendered run of merged segments.
+type segment struct {
+	arc   Arc
+	value float64
+	sty   draw.LineStyle
+	fill  color.Color
+
+	// offset is the radial displacement of the segment.
+	offset vg.Length
+
+	// joined indicates that the segment is adjacent to the
+	// preceding segment.
+	joined bool
+}
+
+// Close renders the added segments. Fills are rendered first, followed by the
+// connectors and then the segment arcs.
+func (sg *Segments) Close() {
+	sort.Sort(sg.values)
+
+	var segs []segment
+	for i, v := range sg.values {
+		val, _ := segmentValue(v.Scorer)
+		s := segment{arc: v.Arc, value: val, sty: sg.LineStyle, fill: sg.Fill, offset: scorerOffset(sg.offset, v.Scorer)}
+		if ls, ok := v.Scorer.(LineStyler); ok {
+			s.sty = ls.LineStyle()
+		}
+		if fc, ok := v.Scorer.(FillColorer); ok {
+			s.fill = fc.FillColor()
+		}
+		s.joined = i != 0 && adjacent(sg.values[i-1].Scorer, v.Scorer) && len(segs) != 0 && segs[len(segs)-1].offset == s.offset
+		if n := len(segs); sg.Merge && s.joined && mergeable(segs[n-1], s) {
+			last := &segs[n-1]
+			last.arc.Phi = s.arc.Theta + s.arc.Phi - last.arc.Theta
+			continue
+		}
+		segs = append(segs, s)
+	}
+
+	var pa vg.Path
+	for _, s := range segs {
+		if s.fill == nil {
+			continue
+		}
+		pa = pa[:0]
+		AnnularWedge(&pa, sg.Center, sg.radius(sg.Baseline, s.offset), sg.radius(s.value, s.offset), s.arc)
+		sg.DrawArea.SetColor(s.fill)
+		sg.DrawArea.Fill(pa)
+	}
+
+	if sg.Connector.Color != nil && sg.Connector.Width != 0 {
+		sg.DrawArea.SetLineStyle(sg.Connector)
+		for i, s := range segs {
+			if !s.joined {
+				continue
+			}
+			pa = pa[:0]
+			pa.Move(RectangularAt(sg.Center, s.arc.Theta, sg.radius(segs[i-1].value, s.offset)))
+			pa.Line(RectangularAt(sg.Center, s.arc.Theta, sg.radius(s.value, s.offset)))
+			sg.DrawArea.Stroke(pa)
+		}
+	}
+
+	for _, s := range segs {
+		if s.sty.Color == nil || s.sty.Width == 0 {
+			continue
+		}
+		rad := sg.radius(s.value, s.offset)
+		pa = pa[:0]
+		pa.Move(RectangularAt(sg.Center, s.arc.Theta, rad))
+		pa.Arc(sg.Center, rad, float64(s.arc.Theta), float64(s.arc.Phi))
+		sg.DrawArea.SetLineStyle(s.sty)
+		sg.DrawArea.Stroke(pa)
+	}
+}
+
+// mergeable returns whether the adjacent segments a and b may be rendered as a
+// single segment.
+func mergeable(a, b segment) bool {
+	return a.value == b.value && sameLineStyle(a.sty, b.sty) && sameColor(a.fill, b.fill)
+}
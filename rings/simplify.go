@@ -0,0 +1,167 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"image"
+	"math"
+
+	"github.com/gonum/plot/vg"
+)
+
+// Simplify returns a copy of pa with each run of consecutive line segments simplified
+// by the Ramer–Douglas–Peucker algorithm, so that no point removed from a run lies
+// further than tol from the simplified run. Move, arc and close components, and the
+// end points of each run, are retained unaltered. If tol is not positive, pa is
+// returned unaltered.
+func Simplify(pa vg.Path, tol vg.Length) vg.Path {
+	if tol <= 0 {
+		return pa
+	}
+
+	s := make(vg.Path, 0, len(pa))
+	var (
+		start, cur vg.Point
+		run        []vg.Point
+	)
+	for i := 0; i < len(pa); {
+		c := pa[i]
+		if c.Type != vg.LineComp {
+			s = append(s, c)
+			switch c.Type {
+			case vg.MoveComp:
+				start, cur = c.Pos, c.Pos
+			case vg.ArcComp:
+				cur = RectangularAt(c.Pos, Angle(c.Start+c.Angle), c.Radius)
+			case vg.CloseComp:
+				cur = start
+			}
+			i++
+			continue
+		}
+
+		// The run begins at the current point and
+		// holds the points of the line segments.
+		run = append(run[:0], cur)
+		for ; i < len(pa) && pa[i].Type == vg.LineComp; i++ {
+			run = append(run, pa[i].Pos)
+		}
+		for j, keep := range reduce(run, tol) {
+			if j != 0 && keep {
+				s.Line(run[j])
+			}
+		}
+		cur = run[len(run)-1]
+	}
+	return s
+}
+
+// reduce returns which of the points of the polyline pts are retained by the
+// Ramer–Douglas–Peucker simplification of pts with the tolerance tol.
+func reduce(pts []vg.Point, tol vg.Length) []bool {
+	keep := make([]bool, len(pts))
+	keep[0], keep[len(pts)-1] = true, true
+	spans := [][2]int{{0, len(pts) - 1}}
+	for len(spans) != 0 {
+		first, last := spans[len(spans)-1][0], spans[len(spans)-1][1]
+		spans = spans[:len(spans)-1]
+
+		var (
+			max vg.Length
+			idx int
+		)
+		for i := first + 1; i < last; i++ {
+			if _, d := nearest(pts[i], pts[first], pts[last]); d > max {
+				max, idx = d, i
+			}
+		}
+		if max > tol {
+			keep[idx] = true
+			spans = append(spans, [2]int{first, idx}, [2]int{idx, last})
+		}
+	}
+	return keep
+}
+
+// RoundingCanvas is a vg.Canvas that rounds the coordinates of the paths, text and
+// images drawn on it to Precision decimal places of the output units of the wrapped
+// Canvas before drawing them, reducing the size of vector output such as SVG. Line
+// segments that become degenerate when rounded are dropped. Arcs, and the points at
+// which arcs begin, are not rounded, since the wrapped Canvas renders arcs from their
+// centers and radii.
+//
+// Coordinates are rounded in the user space of the wrapped Canvas, before its current
+// transform is applied, since vector formats such as SVG record them in that space.
+// Under a Translate transform that is not itself a multiple of the precision, or under
+// a Rotate transform, rounded coordinates do not lie on the output grid, and under a
+// Scale transform the rounding error is scaled with the coordinates. The Precision
+// should be chosen for the scale at which paths are drawn.
+type RoundingCanvas struct {
+	vg.Canvas
+
+	// Precision is the number of decimal places of
+	// the output units retained by the coordinates.
+	Precision int
+
+	// DPI is the resolution of the output units of
+	// the wrapped Canvas, for example vgsvg.DPI. If
+	// DPI is zero, the output units are points.
+	DPI float64
+}
+
+// round returns l rounded to the precision of the canvas.
+func (c RoundingCanvas) round(l vg.Length) vg.Length {
+	scale := math.Pow(10, float64(c.Precision))
+	if c.DPI != 0 {
+		scale *= c.DPI / float64(vg.Inch)
+	}
+	return vg.Length(math.Floor(float64(l)*scale+0.5) / scale)
+}
+
+func (c RoundingCanvas) roundPoint(p vg.Point) vg.Point {
+	return vg.Point{X: c.round(p.X), Y: c.round(p.Y)}
+}
+
+// path returns a copy of pa with rounded coordinates.
+func (c RoundingCanvas) path(pa vg.Path) vg.Path {
+	r := make(vg.Path, 0, len(pa))
+	for i, p := range pa {
+		if p.Type != vg.MoveComp && p.Type != vg.LineComp {
+			r = append(r, p)
+			continue
+		}
+		if i+1 < len(pa) && pa[i+1].Type == vg.ArcComp {
+			r = append(r, p)
+			continue
+		}
+		p.Pos = c.roundPoint(p.Pos)
+		if p.Type == vg.LineComp && len(r) != 0 {
+			if q := r[len(r)-1]; (q.Type == vg.MoveComp || q.Type == vg.LineComp) && q.Pos == p.Pos {
+				continue
+			}
+		}
+		r = append(r, p)
+	}
+	return r
+}
+
+// Translate applies a translational transform rounded to the precision of the canvas.
+func (c RoundingCanvas) Translate(pt vg.Point) { c.Canvas.Translate(c.roundPoint(pt)) }
+
+// Stroke strokes pa with rounded coordinates.
+func (c RoundingCanvas) Stroke(pa vg.Path) { c.Canvas.Stroke(c.path(pa)) }
+
+// Fill fills pa with rounded coordinates.
+func (c RoundingCanvas) Fill(pa vg.Path) { c.Canvas.Fill(c.path(pa)) }
+
+// FillString fills text at the rounded location pt.
+func (c RoundingCanvas) FillString(f vg.Font, pt vg.Point, text string) {
+	c.Canvas.FillString(f, c.roundPoint(pt), text)
+}
+
+// DrawImage draws img scaled to the rounded rectangle rect.
+func (c RoundingCanvas) DrawImage(rect vg.Rectangle, img image.Image) {
+	c.Canvas.DrawImage(vg.Rectangle{Min: c.roundPoint(rect.Min), Max: c.roundPoint(rect.Max)}, img)
+}
@@ -0,0 +1,231 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+	"sort"
+)
+
+// SmoothMethod specifies the method used to smooth scores.
+type SmoothMethod int
+
+const (
+	MovingMean   SmoothMethod = iota // MovingMean smooths scores with the mean of the scores in the window.
+	MovingMedian                     // MovingMedian smooths scores with the median of the scores in the window.
+	LOESS                            // LOESS smooths scores with a tricube weighted local linear regression.
+)
+
+// Smooth describes the smoothing of scores before rendering. Smoothing is performed
+// independently for each score index in angular space, so windows specified as an
+// angle have a consistent width across locations of different lengths. NaN scores are
+// not smoothed and smoothing windows do not extend across them.
+type Smooth struct {
+	// Method is the smoothing method.
+	Method SmoothMethod
+
+	// Window is the angular width of the smoothing window centered on each score.
+	// If Window is zero, Bins is used to define the window.
+	Window Angle
+
+	// Bins is the number of scores in the smoothing window centered on each score
+	// when Window is zero.
+	Bins int
+
+	// CrossLocations specifies that smoothing windows may include the scores of
+	// features in other locations.
+	CrossLocations bool
+}
+
+// smooth returns the smoothed scores of the angle-sorted values.
+func (s *Smooth) smooth(values arcScores) [][]float64 {
+	scores := make([][]float64, len(values))
+	var n int
+	for i, v := range values {
+		scores[i] = append([]float64(nil), v.Scores()...)
+		if len(scores[i]) > n {
+			n = len(scores[i])
+		}
+	}
+	if s.Window <= 0 && s.Bins <= 1 {
+		return scores
+	}
+
+	for j := 0; j < n; j++ {
+		valid := func(i int) bool { return j < len(scores[i]) && !math.IsNaN(scores[i][j]) }
+		for start := 0; start < len(values); {
+			if !valid(start) {
+				start++
+				continue
+			}
+			end := start + 1
+			for end < len(values) && valid(end) &&
+				(s.CrossLocations || values[end].Location() == values[start].Location()) {
+				end++
+			}
+			s.smoothRun(values[start:end], scores[start:end], j)
+			start = end
+		}
+	}
+	return scores
+}
+
+// smoothRun smooths the scores at index j of a run of consecutive values that may
+// be smoothed together.
+func (s *Smooth) smoothRun(values arcScores, scores [][]float64, j int) {
+	mid := func(i int) Angle { return values[i].Theta + values[i].Phi/2 }
+	smoothed := make([]float64, len(values))
+	if s.Window > 0 {
+		s.smoothWindows(mid, scores, j, smoothed)
+	} else {
+		var x, y []float64
+		for k := range values {
+			lo, hi := k-s.Bins/2, k+(s.Bins+1)/2
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > len(values) {
+				hi = len(values)
+			}
+			x, y = x[:0], y[:0]
+			for i := lo; i < hi; i++ {
+				x = append(x, math.Remainder(float64(mid(i)-mid(k)), float64(Complete)))
+				y = append(y, scores[i][j])
+			}
+			smoothed[k] = s.Method.estimate(x, y, 0)
+		}
+	}
+	for k, v := range smoothed {
+		scores[k][j] = v
+	}
+}
+
+// smoothWindows places in smoothed the scores at index j of a run of values smoothed
+// over the angular Window of the Smooth, given the middle angle of each value. Taken
+// in order around the circle, the scores within the window of each score follow on
+// from those within the window of the preceding score, so each window is found by
+// advancing its ends from the previous window, and moving means are calculated from
+// a running sum.
+func (s *Smooth) smoothWindows(mid func(int) Angle, scores [][]float64, j int, smoothed []float64) {
+	n := len(smoothed)
+	ord := angleOrder{idx: make([]int, n), angles: make([]float64, n)}
+	for i := range ord.idx {
+		ord.idx[i] = i
+		a := math.Mod(float64(mid(i)), float64(Complete))
+		if a < 0 {
+			a += float64(Complete)
+		}
+		ord.angles[i] = a
+	}
+	sort.Stable(ord)
+
+	// The window of the pth score in order holds the scores from lo up to hi,
+	// with indices taken modulo n so that windows may wrap around the circle.
+	at := func(q int) int { return ord.idx[((q%n)+n)%n] }
+	dist := func(q, p int) float64 {
+		return math.Remainder(float64(mid(at(q))-mid(at(p))), float64(Complete))
+	}
+	in := func(q, p int) bool { return math.Abs(dist(q, p)) <= float64(s.Window/2) }
+
+	var (
+		lo, hi int
+		sum    float64
+		x, y   []float64
+	)
+	for p := 0; p < n; p++ {
+		switch {
+		case p == 0 || hi <= p:
+			lo, hi, sum = p, p+1, scores[at(p)][j]
+			if p == 0 {
+				for hi-lo < n && in(lo-1, p) {
+					lo--
+					sum += scores[at(lo)][j]
+				}
+			}
+		default:
+			for lo < p && !in(lo, p) {
+				sum -= scores[at(lo)][j]
+				lo++
+			}
+		}
+		for hi-lo < n && in(hi, p) {
+			sum += scores[at(hi)][j]
+			hi++
+		}
+
+		if s.Method == MovingMean {
+			smoothed[at(p)] = sum / float64(hi-lo)
+			continue
+		}
+		x, y = x[:0], y[:0]
+		for q := lo; q < hi; q++ {
+			x = append(x, dist(q, p))
+			y = append(y, scores[at(q)][j])
+		}
+		smoothed[at(p)] = s.Method.estimate(x, y, s.Window/2)
+	}
+}
+
+// angleOrder sorts the indices in idx by the corresponding angles.
+type angleOrder struct {
+	idx    []int
+	angles []float64
+}
+
+func (o angleOrder) Len() int           { return len(o.idx) }
+func (o angleOrder) Less(i, j int) bool { return o.angles[o.idx[i]] < o.angles[o.idx[j]] }
+func (o angleOrder) Swap(i, j int)      { o.idx[i], o.idx[j] = o.idx[j], o.idx[i] }
+
+// estimate returns the smoothed value at zero distance from the scores in y at the
+// distances in x. The half parameter is the half width of the smoothing window used
+// to weight LOESS scores, or zero if the window is defined by the scores in x.
+func (m SmoothMethod) estimate(x, y []float64, half Angle) float64 {
+	switch m {
+	case MovingMean:
+		var sum float64
+		for _, v := range y {
+			sum += v
+		}
+		return sum / float64(len(y))
+	case MovingMedian:
+		v := append([]float64(nil), y...)
+		sort.Float64s(v)
+		if len(v)%2 == 1 {
+			return v[len(v)/2]
+		}
+		return (v[len(v)/2-1] + v[len(v)/2]) / 2
+	case LOESS:
+		dmax := float64(half)
+		if dmax <= 0 {
+			for _, d := range x {
+				dmax = math.Max(dmax, math.Abs(d))
+			}
+		}
+		// Widen the window slightly so the furthest scores have non-zero weight.
+		dmax *= 1 + 1e-9
+
+		var sw, swx, swy, swxx, swxy float64
+		for i, d := range x {
+			w := 1.
+			if dmax > 0 {
+				u := math.Abs(d) / dmax
+				w = math.Pow(1-u*u*u, 3)
+			}
+			sw += w
+			swx += w * d
+			swy += w * y[i]
+			swxx += w * d * d
+			swxy += w * d * y[i]
+		}
+		den := sw*swxx - swx*swx
+		if den <= 0 || math.Abs(den) < 1e-12*sw*swxx {
+			return swy / sw
+		}
+		// The intercept of the weighted linear fit is the estimate at zero distance.
+		return (swy*swxx - swx*swxy) / den
+	default:
+		panic("rings: unknown smoothing method")
+	}
+}
@@ -30,6 +30,14 @@ type Spokes struct {
 	// Inner and Outer define the inner and outer radii of the spokes.
 	Inner, Outer vg.Length
 
+	// Crisp specifies that the spokes are snapped to the pixel grid when rendered
+	// on a raster canvas so that thin lines render sharply. Crisp has no effect on
+	// vector canvases.
+	Crisp bool
+
+	// Layer specifies the drawing layer of the Spokes when rendered by a Layered.
+	Layer int
+
 	// X and Y specify rendering location when Plot is called.
 	X, Y float64
 }
@@ -87,15 +95,21 @@ func (r *Spokes) DrawAt(ca draw.Canvas, cen vg.Point) {
 			panic(fmt.Sprintf("rings: no arc for feature location: %v\n%v", err, f))
 		}
 
-		pa.Move(cen.Add(Rectangular(arc.Theta, r.Inner)))
-		pa.Line(cen.Add(Rectangular(arc.Theta, r.Outer)))
-
 		var sty draw.LineStyle
 		if ls, ok := f.(LineStyler); ok {
 			sty = ls.LineStyle()
 		} else {
 			sty = r.LineStyle
 		}
+
+		var s snapper
+		if r.Crisp {
+			s = crisp(ca, sty.Width)
+		}
+		c := s.point(cen)
+		off := offsetOf(r.Base, loc, f)
+		pa.Move(s.clean(RectangularAt(c, arc.Theta, s.length(r.Inner+off))))
+		pa.Line(s.clean(RectangularAt(c, arc.Theta, s.length(r.Outer+off))))
 		if sty.Color != nil && sty.Width != 0 {
 			ca.SetLineStyle(r.LineStyle)
 			ca.Stroke(pa)
@@ -113,20 +127,25 @@ func (r *Spokes) Arc() Arc { return r.Base.Arc() }
 // the Spokes, an error is returned.
 func (r *Spokes) ArcOf(loc, f feat.Feature) (Arc, error) { return r.Base.ArcOf(loc, f) }
 
+// OffsetOf returns the offset given by the Spokes' Base if it is an Offsetter,
+// otherwise zero.
+func (r *Spokes) OffsetOf(loc, f feat.Feature) vg.Length { return offsetOf(r.Base, loc, f) }
+
+// DrawLayer returns the drawing layer of the Spokes.
+func (r *Spokes) DrawLayer() int { return r.Layer }
+
 // Plot calls DrawAt using the Spokes' X and Y values as the drawing coordinates.
 func (r *Spokes) Plot(ca draw.Canvas, plt *plot.Plot) {
 	trX, trY := plt.Transforms(&ca)
 	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
 }
 
-// GlyphBoxes returns a liberal glyphbox for the blocks rendering.
+// GlyphBoxes returns a liberal glyphbox for the spokes rendering restricted
+// to the base arc.
 func (r *Spokes) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
 	return []plot.GlyphBox{{
-		X: plt.X.Norm(r.X),
-		Y: plt.Y.Norm(r.Y),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{-r.Outer, -r.Outer},
-			Max: vg.Point{r.Outer, r.Outer},
-		},
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(r.Inner, r.Outer),
 	}}
 }
@@ -0,0 +1,347 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"math"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// LineCap specifies the shape of the ends of stroked lines.
+type LineCap int
+
+const (
+	// DefaultCap leaves the ends of lines to the canvas, usually rendering
+	// butt ends.
+	DefaultCap LineCap = iota
+	// RoundCap renders round line ends.
+	RoundCap
+)
+
+// LineJoin specifies the shape of the corners of stroked lines.
+type LineJoin int
+
+const (
+	// DefaultJoin leaves the corners of lines to the canvas, usually rendering
+	// miter joins.
+	DefaultJoin LineJoin = iota
+	// RoundJoin renders round line corners.
+	RoundJoin
+)
+
+// StrokeStyle is a line style with cap and join settings. Canvases do not provide
+// control over line caps and joins, so lines with round caps or joins are rendered as
+// filled outlines: each sub-path is approximated by line segments, and its outline,
+// offset by half the line width to each side of the sub-path and including the round
+// ends and corners, is filled with the line's color. Each sub-path is filled once, so
+// translucent lines are composited once where their segments, caps and joins meet.
+// Corners that turn by no more than JoinThreshold, and all corners when Join is
+// DefaultJoin, are mitred. Dashed lines are divided into dashes before they are
+// outlined, with the dash pattern restarting at each sub-path.
+type StrokeStyle struct {
+	draw.LineStyle
+
+	// Cap and Join specify the shape of the ends and corners of stroked lines.
+	Cap  LineCap
+	Join LineJoin
+
+	// JoinThreshold is the change of direction above which a corner is given a
+	// round join.
+	JoinThreshold Angle
+}
+
+// Stroke strokes pa on ca according to the style. Nothing is rendered if the style
+// has a nil color or a zero width.
+func (sty StrokeStyle) Stroke(ca draw.Canvas, pa vg.Path) {
+	if sty.Color == nil || sty.Width == 0 {
+		return
+	}
+	if sty.Cap == DefaultCap && sty.Join == DefaultJoin {
+		ca.SetLineStyle(sty.LineStyle)
+		ca.Stroke(pa)
+		return
+	}
+
+	lines := strokeLines(pa)
+	if len(sty.Dashes) != 0 {
+		lines = dashLines(lines, sty.Dashes, sty.DashOffs)
+	}
+	ca.SetColor(sty.Color)
+	var out vg.Path
+	for _, l := range lines {
+		out = out[:0]
+		sty.outline(&out, l)
+		if len(out) != 0 {
+			ca.Fill(out)
+		}
+	}
+}
+
+// mitreLimit is the largest ratio of the length of a mitred corner to half the line
+// width. Sharper corners are bevelled.
+const mitreLimit = 10
+
+// coincident is the distance below which points of a path are treated as the
+// same point when finding its corners.
+const coincident = 1e-6
+
+// strokeLine is a line segment approximation of a sub-path of a stroked path.
+type strokeLine struct {
+	// pts holds the vertices of the line. The vertices of a
+	// closed line do not repeat its first vertex.
+	pts []vg.Point
+
+	// smooth indicates the vertices that lie within an arc of
+	// the path, which are not corners of the path.
+	smooth []bool
+
+	closed bool
+}
+
+// add appends p to the vertices of l unless it coincides with the last vertex.
+func (l *strokeLine) add(p vg.Point, smooth bool) {
+	if n := len(l.pts); n != 0 && distance(l.pts[n-1], p) < coincident {
+		l.smooth[n-1] = l.smooth[n-1] && smooth
+		return
+	}
+	l.pts = append(l.pts, p)
+	l.smooth = append(l.smooth, smooth)
+}
+
+// strokeLines returns the line segment approximations of the sub-paths of pa. Sub-paths
+// with no segments are omitted.
+func strokeLines(pa vg.Path) []strokeLine {
+	var (
+		lines []strokeLine
+		cur   strokeLine
+		last  vg.Point // Current point of the path.
+	)
+	end := func() {
+		if len(cur.pts) != 0 {
+			lines = append(lines, cur)
+		}
+		cur = strokeLine{}
+	}
+	for _, c := range pa {
+		switch c.Type {
+		case vg.MoveComp:
+			end()
+			last = c.Pos
+		case vg.LineComp:
+			if len(cur.pts) == 0 {
+				cur.add(last, false)
+			}
+			cur.add(c.Pos, false)
+			last = c.Pos
+		case vg.ArcComp:
+			if len(cur.pts) == 0 {
+				cur.add(last, false)
+			}
+			// The canvas joins the current point to the start of the arc.
+			n := int(math.Ceil(math.Abs(c.Angle) / flattenStep))
+			for i := 0; i <= n; i++ {
+				theta := c.Start
+				if n != 0 {
+					theta += c.Angle * float64(i) / float64(n)
+				}
+				cur.add(RectangularAt(c.Pos, Angle(theta), c.Radius), i != 0 && i != n)
+			}
+			last = cur.pts[len(cur.pts)-1]
+		case vg.CloseComp:
+			if len(cur.pts) == 0 {
+				continue
+			}
+			start := cur.pts[0]
+			if n := len(cur.pts); n > 1 && distance(cur.pts[n-1], start) < coincident {
+				cur.pts, cur.smooth = cur.pts[:n-1], cur.smooth[:n-1]
+			}
+			cur.closed = len(cur.pts) > 1
+			end()
+			last = start
+		}
+	}
+	end()
+	return lines
+}
+
+// dashLines returns the dashes of lines given the dash pattern and offset. The pattern
+// restarts at each line.
+func dashLines(lines []strokeLine, dashes []vg.Length, offs vg.Length) []strokeLine {
+	var total vg.Length
+	for _, d := range dashes {
+		if d < 0 {
+			return lines
+		}
+		total += d
+	}
+	if total <= 0 {
+		return lines
+	}
+
+	var out []strokeLine
+	for _, l := range lines {
+		pts, smooth := l.pts, l.smooth
+		if l.closed {
+			pts = append(pts[:len(pts):len(pts)], pts[0])
+			smooth = append(smooth[:len(smooth):len(smooth)], false)
+		}
+
+		k, rem, on := 0, dashes[0], true
+		o := vg.Length(math.Mod(float64(offs), float64(total)))
+		if o < 0 {
+			o += total
+		}
+		for o > 0 {
+			if o < rem {
+				rem -= o
+				break
+			}
+			o -= rem
+			k = (k + 1) % len(dashes)
+			rem, on = dashes[k], !on
+		}
+
+		var cur strokeLine
+		if on {
+			cur.add(pts[0], false)
+		}
+		for i := 1; i < len(pts); i++ {
+			a, b := pts[i-1], pts[i]
+			seg := distance(a, b)
+			var pos vg.Length
+			for seg-pos > rem {
+				pos += rem
+				f := pos / seg
+				q := vg.Point{X: a.X + (b.X-a.X)*f, Y: a.Y + (b.Y-a.Y)*f}
+				cur.add(q, false)
+				if on {
+					out = append(out, cur)
+					cur = strokeLine{}
+				}
+				k = (k + 1) % len(dashes)
+				rem, on = dashes[k], !on
+			}
+			rem -= seg - pos
+			if on {
+				cur.add(b, smooth[i])
+			}
+		}
+		if on && len(cur.pts) != 0 {
+			out = append(out, cur)
+		}
+	}
+	return out
+}
+
+// outline appends the closed outline of the stroke of l to pa. A line of a single
+// vertex is outlined as a disc if the style has round caps.
+func (sty StrokeStyle) outline(pa *vg.Path, l strokeLine) {
+	h := sty.Width / 2
+	if len(l.pts) == 1 {
+		if sty.Cap == RoundCap {
+			p := l.pts[0]
+			pa.Move(vg.Point{X: p.X + h, Y: p.Y})
+			pa.Arc(p, h, 0, 2*math.Pi)
+			pa.Close()
+		}
+		return
+	}
+
+	rev := strokeLine{pts: make([]vg.Point, len(l.pts)), smooth: make([]bool, len(l.smooth)), closed: l.closed}
+	for i, p := range l.pts {
+		rev.pts[len(l.pts)-1-i] = p
+		rev.smooth[len(l.pts)-1-i] = l.smooth[i]
+	}
+	if l.closed {
+		// The outline of a closed line is the pair of rings
+		// offset to each side of the line.
+		sty.side(pa, l, h, true)
+		pa.Close()
+		sty.side(pa, rev, h, true)
+		pa.Close()
+		return
+	}
+	sty.side(pa, l, h, true)
+	sty.cap(pa, l.pts[len(l.pts)-2], l.pts[len(l.pts)-1], h)
+	sty.side(pa, rev, h, false)
+	sty.cap(pa, l.pts[1], l.pts[0], h)
+	pa.Close()
+}
+
+// side appends the offset of l by h to its left to pa, starting a new sub-path if
+// move is true.
+func (sty StrokeStyle) side(pa *vg.Path, l strokeLine, h vg.Length, move bool) {
+	to := func(p vg.Point) {
+		if move {
+			pa.Move(p)
+			move = false
+			return
+		}
+		pa.Line(p)
+	}
+	pts, n := l.pts, len(l.pts)
+	if l.closed {
+		for i, p := range pts {
+			sty.join(pa, to, p, unit(pts[(i+n-1)%n], p), unit(p, pts[(i+1)%n]), l.smooth[i], h)
+		}
+		return
+	}
+	to(displaced(pts[0], leftNormal(unit(pts[0], pts[1])), h))
+	for i := 1; i < n-1; i++ {
+		sty.join(pa, to, pts[i], unit(pts[i-1], pts[i]), unit(pts[i], pts[i+1]), l.smooth[i], h)
+	}
+	to(displaced(pts[n-1], leftNormal(unit(pts[n-2], pts[n-1])), h))
+}
+
+// join appends the corner of the left offset by h of the lines meeting at p with the
+// directions u0 and u1 to pa, using to to add points. Corners turning to the right
+// that are not smooth are rounded if the style has round joins and the corner turns by
+// more than JoinThreshold. Other corners are mitred, or bevelled if they are too sharp
+// to mitre.
+func (sty StrokeStyle) join(pa *vg.Path, to func(vg.Point), p, u0, u1 vg.Point, smooth bool, h vg.Length) {
+	n0, n1 := leftNormal(u0), leftNormal(u1)
+	cross := float64(u0.X*u1.Y - u0.Y*u1.X)
+	dot := float64(u0.X*u1.X + u0.Y*u1.Y)
+	turn := math.Atan2(cross, dot)
+	if turn < 0 && !smooth && sty.Join == RoundJoin && -turn > float64(sty.JoinThreshold) {
+		to(displaced(p, n0, h))
+		pa.Arc(p, h, math.Atan2(float64(n0.Y), float64(n0.X)), turn)
+		return
+	}
+	if 1+dot < 2/(mitreLimit*mitreLimit) {
+		to(displaced(p, n0, h))
+		to(displaced(p, n1, h))
+		return
+	}
+	m := vg.Point{X: (n0.X + n1.X) / vg.Length(1+dot), Y: (n0.Y + n1.Y) / vg.Length(1+dot)}
+	to(displaced(p, m, h))
+}
+
+// cap appends the end of the outline of a line ending with the segment from a to b to
+// pa. The outline is at the left offset of the end at b and continues from the right
+// offset.
+func (sty StrokeStyle) cap(pa *vg.Path, a, b vg.Point, h vg.Length) {
+	if sty.Cap != RoundCap {
+		return
+	}
+	n := leftNormal(unit(a, b))
+	pa.Arc(b, h, math.Atan2(float64(n.Y), float64(n.X)), -math.Pi)
+}
+
+// unit returns the unit vector from a to b.
+func unit(a, b vg.Point) vg.Point {
+	d := distance(a, b)
+	return vg.Point{X: (b.X - a.X) / d, Y: (b.Y - a.Y) / d}
+}
+
+// leftNormal returns u rotated a quarter turn anticlockwise.
+func leftNormal(u vg.Point) vg.Point { return vg.Point{X: -u.Y, Y: u.X} }
+
+// displaced returns p displaced by h along v.
+func displaced(p, v vg.Point, h vg.Length) vg.Point {
+	return vg.Point{X: p.X + v.X*h, Y: p.Y + v.Y*h}
+}
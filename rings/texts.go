@@ -0,0 +1,307 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Texter is a type that can be rendered as text anchored at a position within a feature.
+type Texter interface {
+	// Position returns the location of the text anchor and the position of the
+	// anchor within the location.
+	Position() (loc feat.Feature, pos int)
+
+	// Text returns the text to render.
+	Text() string
+}
+
+// Annotation is a Texter holding a string anchored at a position within a feature.
+type Annotation struct {
+	Loc   feat.Feature
+	Pos   int
+	Label string
+}
+
+// Position returns the Loc and Pos of the Annotation.
+func (a Annotation) Position() (loc feat.Feature, pos int) { return a.Loc, a.Pos }
+
+// Text returns the Label of the Annotation.
+func (a Annotation) Text() string { return a.Label }
+
+// Texts implements rendering of text annotations anchored at positions within features.
+// Annotations that would overlap are stacked into radial lanes.
+type Texts struct {
+	// Set holds the collection of text annotations to render.
+	Set []Texter
+
+	// Base describes the ring holding the features the annotations are anchored to.
+	Base ArcOfer
+
+	// TextStyle determines the text style of each annotation. TextStyle behaviour
+	// is over-ridden if the Texter is a TextStyler. The height of the TextStyle's
+	// font determines the spacing of lanes.
+	TextStyle draw.TextStyle
+
+	// Inner and Outer define the radii of the innermost and outermost lanes.
+	// Lanes are placed at intervals of the TextStyle's font height from Inner to Outer.
+	Inner, Outer vg.Length
+
+	// Placement determines the text rotation and alignment. If Placement is
+	// nil, DefaultPlacement is used. Lane spacing is most appropriate for
+	// placements that do not extend text radially.
+	Placement TextPlacement
+
+	// Priority determines the order in which annotations are assigned to lanes.
+	// Annotations for which Priority returns true when compared with another
+	// annotation are assigned first, and so are placed in lower lanes. If Priority
+	// is nil, annotations are assigned in the order they are held in Set.
+	Priority func(a, b Texter) bool
+
+	// Drop specifies that annotations that cannot be placed in any lane are not
+	// rendered. If Drop is false, exhausting the available lanes is an error.
+	Drop bool
+
+	// Layer specifies the drawing layer of the Texts when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewTexts returns a Texts based on the parameters, first checking that the provided text
+// annotations are able to be rendered. An error is returned if the annotations are not
+// renderable. If base is an XYer, the returned base XY values are used to populate the
+// Texts' X and Y fields.
+func NewTexts(ts []Texter, base ArcOfer, inner, outer vg.Length) (*Texts, error) {
+	if inner > outer {
+		return nil, errors.New("rings: inner radius greater than outer radius")
+	}
+	for _, t := range ts {
+		loc, pos := t.Position()
+		if loc != nil && (pos < loc.Start() || pos > loc.End()) {
+			return nil, errors.New("rings: text position out of range")
+		}
+		if _, err := base.ArcOf(loc, nil); err != nil {
+			return nil, err
+		}
+	}
+	var x, y float64
+	if xy, ok := base.(XYer); ok {
+		x, y = xy.XY()
+	}
+	return &Texts{
+		Set:   ts,
+		Base:  base,
+		Inner: inner,
+		Outer: outer,
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+// locus is a zero length feat.Feature at a position within a location.
+type locus struct {
+	loc feat.Feature
+	pos int
+}
+
+func (l locus) Start() int             { return l.pos }
+func (l locus) End() int               { return l.pos }
+func (l locus) Len() int               { return 0 }
+func (l locus) Name() string           { return "" }
+func (l locus) Description() string    { return "" }
+func (l locus) Location() feat.Feature { return l.loc }
+
+// angleAt returns the angle of the position pos within loc in base.
+func angleAt(base ArcOfer, loc feat.Feature, pos int) (Angle, error) {
+	arc, err := base.ArcOf(loc, locus{loc: loc, pos: pos})
+	if err != nil {
+		return 0, err
+	}
+	return arc.Theta, nil
+}
+
+// Lanes returns the lane assigned to each of the annotations in the Set, with lane
+// 0 at the Inner radius. Annotations that are dropped are assigned lane -1. If the
+// Texts' Drop field is false, an error is returned if an annotation cannot be placed.
+func (r *Texts) Lanes() ([]int, error) {
+	h := lineHeight(r.TextStyle)
+	n := 1
+	if h > 0 {
+		n = int((r.Outer-r.Inner)/h) + 1
+	}
+
+	order := make([]int, len(r.Set))
+	for i := range order {
+		order[i] = i
+	}
+	if r.Priority != nil {
+		sort.Stable(byPriority{order: order, set: r.Set, less: r.Priority})
+	}
+
+	lanes := make([]int, len(r.Set))
+	placed := make([][]span, n)
+	for _, i := range order {
+		t := r.Set[i]
+		angle, err := r.angleOf(t)
+		if err != nil {
+			return nil, err
+		}
+		sty := r.styleOf(t)
+		off := r.offsetOf(t)
+		lanes[i] = -1
+		for l := range placed {
+			s := r.spanOf(angle, r.Inner+off+vg.Length(l)*h, sty, t.Text())
+			if !s.overlapsAny(placed[l]) {
+				placed[l] = append(placed[l], s)
+				lanes[i] = l
+				break
+			}
+		}
+		if lanes[i] < 0 && !r.Drop {
+			return nil, fmt.Errorf("rings: no lane available for text %q", t.Text())
+		}
+	}
+	return lanes, nil
+}
+
+type byPriority struct {
+	order []int
+	set   []Texter
+	less  func(a, b Texter) bool
+}
+
+func (p byPriority) Len() int           { return len(p.order) }
+func (p byPriority) Less(i, j int) bool { return p.less(p.set[p.order[i]], p.set[p.order[j]]) }
+func (p byPriority) Swap(i, j int)      { p.order[i], p.order[j] = p.order[j], p.order[i] }
+
+// span is the angular extent of rendered text, described by its mid angle and half width.
+type span struct {
+	mid, half Angle
+}
+
+func (s span) overlapsAny(spans []span) bool {
+	for _, o := range spans {
+		d := math.Abs(math.Remainder(float64(s.mid-o.mid), float64(Complete)))
+		if Angle(d) < s.half+o.half {
+			return true
+		}
+	}
+	return false
+}
+
+// spanOf returns the angular extent of txt rendered at the given angle and radius.
+func (r *Texts) spanOf(angle Angle, rad vg.Length, sty draw.TextStyle, txt string) span {
+	rot, xalign, yalign := r.placement(angle)
+	w, h := TextBounds(sty, txt)
+	pt := Rectangular(angle, rad)
+	sin, cos := math.Sincos(float64(rot))
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range textCorners(w, h, xalign, yalign) {
+		p := pt.Add(vg.Point{
+			X: c.X*vg.Length(cos) - c.Y*vg.Length(sin),
+			Y: c.X*vg.Length(sin) + c.Y*vg.Length(cos),
+		})
+		theta, _ := Polar(p)
+		d := math.Remainder(float64(theta-angle), float64(Complete))
+		lo = math.Min(lo, d)
+		hi = math.Max(hi, d)
+	}
+	return span{mid: angle + Angle(lo+hi)/2, half: Angle(hi-lo) / 2}
+}
+
+// DrawAt renders the text of a Texts at cen in the specified drawing area,
+// according to the Texts configuration.
+func (r *Texts) DrawAt(ca draw.Canvas, cen vg.Point) {
+	if len(r.Set) == 0 {
+		return
+	}
+
+	lanes, err := r.Lanes()
+	if err != nil {
+		panic(err)
+	}
+	h := lineHeight(r.TextStyle)
+	for i, t := range r.Set {
+		if lanes[i] < 0 {
+			continue
+		}
+		sty := r.styleOf(t)
+		if sty.Color == nil || sty.Font.Size == 0 {
+			continue
+		}
+
+		angle, err := r.angleOf(t)
+		if err != nil {
+			panic(fmt.Sprint("rings: no arc for feature location:", err))
+		}
+		pt := RectangularAt(cen, angle, r.Inner+r.offsetOf(t)+vg.Length(lanes[i])*h)
+		rot, xalign, yalign := r.placement(angle)
+		fillText(ca, sty, pt, rot, xalign, yalign, t.Text())
+	}
+}
+
+// angleOf returns the angle of the anchor of t.
+func (r *Texts) angleOf(t Texter) (Angle, error) {
+	loc, pos := t.Position()
+	return angleAt(r.Base, loc, pos)
+}
+
+// offsetOf returns the radial displacement of the anchor of t given by the Texts' Base.
+func (r *Texts) offsetOf(t Texter) vg.Length {
+	loc, pos := t.Position()
+	return offsetOf(r.Base, loc, locus{loc: loc, pos: pos})
+}
+
+// styleOf returns the text style used to render t.
+func (r *Texts) styleOf(t Texter) draw.TextStyle {
+	if ts, ok := t.(TextStyler); ok {
+		return ts.TextStyle()
+	}
+	return r.TextStyle
+}
+
+// placement returns the text rotation and alignment for text at the given angle.
+func (r *Texts) placement(angle Angle) (rot Angle, xalign, yalign float64) {
+	if r.Placement == nil {
+		return DefaultPlacement(angle)
+	}
+	return r.Placement(angle)
+}
+
+// XY returns the x and y coordinates of the Texts.
+func (r *Texts) XY() (x, y float64) { return r.X, r.Y }
+
+// DrawLayer returns the drawing layer of the Texts.
+func (r *Texts) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Texts' X and Y values as the drawing coordinates.
+func (r *Texts) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the texts rendering.
+func (r *Texts) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	return []plot.GlyphBox{{
+		X: plt.X.Norm(r.X),
+		Y: plt.Y.Norm(r.Y),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{-r.Outer, -r.Outer},
+			Max: vg.Point{r.Outer, r.Outer},
+		},
+	}}
+}
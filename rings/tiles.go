@@ -0,0 +1,410 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// TileDirection specifies the direction in which the lanes of a Tiles ring are stacked.
+type TileDirection int
+
+const (
+	TileOutward TileDirection = iota // TileOutward stacks lanes from the Inner radius towards the Outer radius.
+	TileInward                       // TileInward stacks lanes from the Outer radius towards the Inner radius.
+)
+
+// Overflow specifies the behaviour of a Tiles ring when its features require more lanes
+// than are available.
+type Overflow int
+
+const (
+	OverflowDrop    Overflow = iota // OverflowDrop does not render features in lanes beyond those available.
+	OverflowSqueeze                 // OverflowSqueeze reduces the lane thickness so that all lanes are rendered.
+	OverflowGrow                    // OverflowGrow renders additional lanes inward of the Inner radius.
+)
+
+// Tiles implements rendering of feat.Features as arc segments stacked into lanes so that
+// overlapping features are not drawn over each other.
+type Tiles struct {
+	// Set holds a collection of features to render.
+	Set []feat.Feature
+
+	// Base defines the targets of the rendered tiles.
+	Base ArcOfer
+
+	// Color determines the fill color of each tile. If Color is not nil each tile is rendered
+	// filled with the specified color, otherwise no fill is performed. This behaviour is
+	// over-ridden if the feature describing the tile is a FillColorer.
+	Color color.Color
+
+	// LineStyle determines the line style of each tile. LineStyle behaviour
+	// is over-ridden if the feature describing a tile is a LineStyler.
+	LineStyle draw.LineStyle
+
+	// Inner and Outer define the inner and outer radii of the tiles.
+	Inner, Outer vg.Length
+
+	// Thickness is the radial thickness of each lane. If Thickness is zero the
+	// lane thickness is determined by dividing the space between Inner and Outer
+	// by MaxLanes, or by the number of lanes required if MaxLanes is also zero.
+	Thickness vg.Length
+
+	// MaxLanes limits the number of available lanes if it is greater than zero.
+	MaxLanes int
+
+	// Direction specifies the direction in which lanes are stacked.
+	Direction TileDirection
+
+	// UseOrientation specifies that lanes are assigned separately for forward and
+	// reverse features. When UseOrientation is true, the space between Inner and Outer
+	// is split at its midpoint, with the lanes of forward features stacked outward from
+	// the midpoint and those of reverse features stacked inward from it, and Direction
+	// is ignored. The orientation of a feature is its orientation relative to the base;
+	// features that are not feat.Orienters are treated as forward.
+	UseOrientation bool
+
+	// Overflow specifies the handling of features placed in lanes beyond those
+	// available. Lanes grown by OverflowGrow are stacked inward from the Inner
+	// radius whatever the Direction, except that when UseOrientation is true the
+	// grown lanes of forward features are stacked outward from the Outer radius
+	// so that they do not overlap the lanes of reverse features. Lanes that would
+	// extend past the centre are never rendered.
+	Overflow Overflow
+
+	// MinWidth is the minimum arc length of a rendered tile, measured at the Outer
+	// radius. Features shorter than MinWidth, including zero length features, are
+	// rendered as ticks of MinWidth centred on the feature.
+	MinWidth vg.Length
+
+	// MinAngle is the minimum angular width of a rendered tile. Features with
+	// narrower arcs are rendered as ticks of MinAngle centred on the feature. If
+	// MinAngle is zero, MinFeatureAngle is used, and if it is negative no minimum
+	// angle is applied.
+	MinAngle Angle
+
+	// Pad is the angle removed from each end of the arc of each location before
+	// the arcs of the location's features are determined. The features are mapped
+	// linearly into the remaining arc, so tiles of adjacent locations do not meet
+	// at the boundary between the locations. A negative Pad is treated as zero.
+	Pad Angle
+
+	// Progress, if not nil, is called with the number of tiles rendered and the
+	// total number of tiles at the progress interval of the context passed to
+	// DrawAtContext, as set by WithProgressInterval, and on completion when the
+	// Tiles is drawn.
+	Progress func(done, total int)
+
+	// HitTester, if not nil, records the geometry of each rendered tile.
+	HitTester *HitTester
+
+	// Title is the title of the ring.
+	Title Title
+
+	// Layer specifies the drawing layer of the Tiles when rendered by a Layered.
+	Layer int
+
+	// X and Y specify rendering location when Plot is called.
+	X, Y float64
+}
+
+// NewTiles returns a Tiles based on the parameters, first checking that the provided features
+// are able to be rendered. An error is returned if the features are not renderable. The returned
+// Tiles has a MinWidth of 1.
+func NewTiles(fs []feat.Feature, base ArcOfer, inner, outer vg.Length) (*Tiles, error) {
+	if inner > outer {
+		return nil, errors.New("rings: inner radius greater than outer radius")
+	}
+	for _, f := range fs {
+		if f.End() < f.Start() {
+			return nil, errors.New("rings: inverted feature")
+		}
+		if _, err := base.ArcOf(f.Location(), f); err != nil {
+			return nil, err
+		}
+	}
+	return &Tiles{
+		Set:      fs,
+		Base:     base,
+		Inner:    inner,
+		Outer:    outer,
+		MinWidth: 1,
+	}, nil
+}
+
+// Lanes returns the lane assigned to each feature in the Set, with lane 0 being the
+// first lane in the stacking direction, and the number of lanes required. Features
+// are assigned to the lowest lane in which their arc, widened to MinWidth, does not
+// overlap the arc of another feature. If UseOrientation is true, forward and reverse
+// features are assigned lanes independently and the number of lanes required is the
+// greater of the numbers required for each orientation.
+func (r *Tiles) Lanes() (lanes []int, n int, err error) {
+	arcs := make([]Arc, len(r.Set))
+	var order [2][]int
+	for i, f := range r.Set {
+		arcs[i], err = r.arcOf(f)
+		if err != nil {
+			return nil, 0, err
+		}
+		s := 0
+		if r.UseOrientation && orientationOf(f) == feat.Reverse {
+			s = 1
+		}
+		order[s] = append(order[s], i)
+	}
+
+	lanes = make([]int, len(r.Set))
+	for _, o := range order {
+		if m := assignLanes(lanes, o, arcs); m > n {
+			n = m
+		}
+	}
+	return lanes, n, nil
+}
+
+// assignLanes assigns lanes to the features indexed by order with the given arcs,
+// storing the lanes in the corresponding elements of lanes, and returns the number
+// of lanes used.
+func assignLanes(lanes, order []int, arcs []Arc) int {
+	sort.Stable(byArcStart{order: order, arcs: arcs})
+
+	// ends holds the end angle of the last tile placed in each lane.
+	var ends []Angle
+	for _, i := range order {
+		a := arcs[i]
+		l := 0
+		for ; l < len(ends); l++ {
+			if ends[l] <= a.Theta {
+				break
+			}
+		}
+		if l == len(ends) {
+			ends = append(ends, 0)
+		}
+		ends[l] = a.Theta + a.Phi
+		lanes[i] = l
+	}
+	return len(ends)
+}
+
+// arcOf returns the arc of f, inset by Pad and widened to MinWidth and MinAngle, with
+// a non-negative Phi.
+func (r *Tiles) arcOf(f feat.Feature) (Arc, error) {
+	arc, err := r.Base.ArcOf(f.Location(), f)
+	if err != nil {
+		return arc, err
+	}
+	arc, err = padded(r.Base, f.Location(), arc, r.Pad)
+	if err != nil {
+		return arc, err
+	}
+	if arc.Phi < 0 {
+		arc = Arc{arc.Theta + arc.Phi, -arc.Phi}
+	}
+	min := minFeatureAngle(r.MinAngle)
+	if r.Outer > 0 {
+		min = Angle(math.Max(float64(min), float64(r.MinWidth/r.Outer)))
+	}
+	return widen(arc, min), nil
+}
+
+type byArcStart struct {
+	order []int
+	arcs  []Arc
+}
+
+func (a byArcStart) Len() int { return len(a.order) }
+func (a byArcStart) Less(i, j int) bool {
+	return a.arcs[a.order[i]].Theta < a.arcs[a.order[j]].Theta
+}
+func (a byArcStart) Swap(i, j int) { a.order[i], a.order[j] = a.order[j], a.order[i] }
+
+// laneLayout returns the thickness of lanes, the number of lanes to render given n
+// required lanes and the number of those lanes that are stacked in the Direction of
+// the Tiles, the remainder being grown by OverflowGrow. If UseOrientation is true, the
+// lanes are laid out in half of the space between Inner and Outer.
+func (r *Tiles) laneLayout(n int) (thick vg.Length, avail, stacked int) {
+	space := r.Outer - r.Inner
+	if r.UseOrientation {
+		space /= 2
+	}
+	switch {
+	case r.Thickness > 0:
+		thick = r.Thickness
+		avail = int(math.Floor(float64(space / thick)))
+		if r.MaxLanes > 0 && r.MaxLanes < avail {
+			avail = r.MaxLanes
+		}
+	case r.MaxLanes > 0:
+		avail = r.MaxLanes
+		thick = space / vg.Length(avail)
+	default:
+		avail = n
+		if n > 0 {
+			thick = space / vg.Length(n)
+		}
+	}
+	stacked = avail
+	if n > avail {
+		switch r.Overflow {
+		case OverflowSqueeze:
+			thick *= vg.Length(avail) / vg.Length(n)
+			avail, stacked = n, n
+		case OverflowGrow:
+			avail = n
+		}
+	}
+	return thick, avail, stacked
+}
+
+// DrawAt renders the features of a Tiles at cen in the specified drawing area,
+// according to the Tiles configuration.
+func (r *Tiles) DrawAt(ca draw.Canvas, cen vg.Point) {
+	r.DrawAtContext(context.Background(), ca, cen)
+}
+
+// DrawAtContext renders the features of a Tiles at cen in the specified drawing area,
+// according to the Tiles configuration. Rendering is stopped and the error of ctx is
+// returned if ctx is cancelled.
+func (r *Tiles) DrawAtContext(ctx context.Context, ca draw.Canvas, cen vg.Point) error {
+	prog, err := newProgress(ctx, r.Progress, len(r.Set))
+	if err != nil || len(r.Set) == 0 {
+		return err
+	}
+
+	lanes, n, err := r.Lanes()
+	if err != nil {
+		panic(fmt.Sprintf("rings: no arc for feature location: %v", err))
+	}
+	thick, avail, stacked := r.laneLayout(n)
+
+	var pa vg.Path
+	for i, f := range r.Set {
+		if err := prog.step(); err != nil {
+			return err
+		}
+
+		l := lanes[i]
+		if l >= avail {
+			continue
+		}
+		sec := Sector{Center: cen}
+		inner, outer, dir := r.Inner, r.Outer, r.Direction
+		if r.UseOrientation {
+			mid := (r.Inner + r.Outer) / 2
+			if orientationOf(f) == feat.Reverse {
+				outer, dir = mid, TileInward
+			} else {
+				inner, dir = mid, TileOutward
+			}
+		}
+		switch {
+		case dir == TileOutward && l >= stacked && !r.UseOrientation:
+			// Grown lanes are stacked inward from
+			// the inner radius.
+			sec.Outer = inner - vg.Length(l-stacked)*thick
+			sec.Inner = sec.Outer - thick
+		case dir == TileOutward:
+			sec.Inner = inner + vg.Length(l)*thick
+			sec.Outer = sec.Inner + thick
+		case dir == TileInward:
+			sec.Outer = outer - vg.Length(l)*thick
+			sec.Inner = sec.Outer - thick
+		default:
+			panic("rings: unknown tile direction")
+		}
+		if sec.Inner < 0 {
+			continue
+		}
+		off := offsetOf(r.Base, f.Location(), f)
+		sec.Inner += off
+		sec.Outer += off
+		sec.Arc, _ = r.arcOf(f)
+
+		pa = pa[:0]
+		sec.Path(&pa, false)
+		if r.HitTester != nil {
+			r.HitTester.AddSector(r, f, sec)
+		}
+
+		if c, ok := f.(FillColorer); ok {
+			ca.SetColor(c.FillColor())
+			ca.Fill(pa)
+		} else if r.Color != nil {
+			ca.SetColor(r.Color)
+			ca.Fill(pa)
+		}
+
+		var sty draw.LineStyle
+		if ls, ok := f.(LineStyler); ok {
+			sty = ls.LineStyle()
+		} else {
+			sty = r.LineStyle
+		}
+		if sty.Color != nil && sty.Width != 0 {
+			ca.SetLineStyle(sty)
+			ca.Stroke(pa)
+		}
+	}
+	r.Title.drawAt(ca, cen, r.Inner, r.Outer)
+	prog.finish()
+	return nil
+}
+
+// XY returns the x and y coordinates of the Tiles.
+func (r *Tiles) XY() (x, y float64) { return r.X, r.Y }
+
+// RingTitle returns the title of the Tiles and the radii it is placed relative to.
+func (r *Tiles) RingTitle() (t *Title, inner, outer vg.Length) { return &r.Title, r.Inner, r.Outer }
+
+// Arc returns the base arc of the Tiles.
+func (r *Tiles) Arc() Arc { return r.Base.Arc() }
+
+// ArcOf returns the Arc location of the parameter. If the location is not found in
+// the Tiles, an error is returned.
+func (r *Tiles) ArcOf(loc, f feat.Feature) (Arc, error) { return r.Base.ArcOf(loc, f) }
+
+// OffsetOf returns the offset given by the Tiles' Base if it is an Offsetter,
+// otherwise zero.
+func (r *Tiles) OffsetOf(loc, f feat.Feature) vg.Length { return offsetOf(r.Base, loc, f) }
+
+// DrawLayer returns the drawing layer of the Tiles.
+func (r *Tiles) DrawLayer() int { return r.Layer }
+
+// Plot calls DrawAt using the Tiles' X and Y values as the drawing coordinates.
+func (r *Tiles) Plot(ca draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&ca)
+	r.DrawAt(ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// PlotContext calls DrawAtContext using the Tiles' X and Y values as the drawing coordinates.
+func (r *Tiles) PlotContext(ctx context.Context, ca draw.Canvas, plt *plot.Plot) error {
+	trX, trY := plt.Transforms(&ca)
+	return r.DrawAtContext(ctx, ca, vg.Point{trX(r.X), trY(r.Y)})
+}
+
+// GlyphBoxes returns a liberal glyphbox for the tiles rendering restricted
+// to the base arc. If the Title has a Stack, the title is registered with it.
+func (r *Tiles) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if r.Title.Stack != nil {
+		r.Title.Stack.Include(r)
+	}
+	return []plot.GlyphBox{{
+		X:         plt.X.Norm(r.X),
+		Y:         plt.Y.Norm(r.Y),
+		Rectangle: r.Base.Arc().bounds(r.Inner, r.Outer),
+	}}
+}
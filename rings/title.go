@@ -0,0 +1,223 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// Title is the title of a ring. A title is rendered tangentially at its Angle, either
+// beyond the outer radius or within the inner radius of the ring.
+type Title struct {
+	// Text is the title string. If Text is empty no title is rendered.
+	Text string
+
+	// TextStyle is the style of the title text.
+	draw.TextStyle
+
+	// Angle specifies the angular location of the title.
+	Angle Angle
+
+	// Side specifies whether the title is placed beyond the outer radius of the
+	// ring, TickOutside, or within its inner radius, TickInside.
+	Side TickSide
+
+	// Gap is the radial distance between the title and the ring, and between
+	// titles stacked by a TitleStack. If Gap is zero, half the line spacing of
+	// the title font is used.
+	Gap vg.Length
+
+	// Stack, if not nil, is a registry of titles shared with other rings so that
+	// their titles are stacked without overlap.
+	Stack *TitleStack
+}
+
+// Titler is a ring with a Title. Blocks, Scores and Tiles are Titlers.
+type Titler interface {
+	// RingTitle returns the title of the ring and the inner and outer
+	// radii of the ring.
+	RingTitle() (t *Title, inner, outer vg.Length)
+}
+
+// height returns the radial extent of the title text.
+func (t *Title) height() vg.Length {
+	_, h := TextBounds(t.TextStyle, t.Text)
+	return h
+}
+
+// gap returns the radial gap between the title and its neighbours.
+func (t *Title) gap() vg.Length {
+	if t.Gap != 0 {
+		return t.Gap
+	}
+	return lineHeight(t.TextStyle) / 2
+}
+
+// span returns the radial interval of the title of a ring with the given inner and
+// outer radii when it is not stacked.
+func (t *Title) span(inner, outer vg.Length) (lo, hi vg.Length) {
+	h := t.height()
+	switch t.Side {
+	case TickOutside:
+		lo = outer + t.gap()
+		return lo, lo + h
+	case TickInside:
+		hi = inner - t.gap()
+		return hi - h, hi
+	default:
+		panic("rings: unknown title side")
+	}
+}
+
+// check returns an error if the title cannot be rendered.
+func (t Title) check() error {
+	if t.Side != TickOutside && t.Side != TickInside {
+		return fmt.Errorf("rings: unknown title side %d", t.Side)
+	}
+	if math.IsNaN(float64(t.Angle)) || math.IsInf(float64(t.Angle), 0) {
+		return errors.New("rings: title angle is not finite")
+	}
+	return nil
+}
+
+// drawAt renders the title of a ring with the given inner and outer radii at cen in
+// the specified drawing area.
+func (t *Title) drawAt(ca draw.Canvas, cen vg.Point, inner, outer vg.Length) {
+	if t.Text == "" || t.Color == nil || t.Font.Size == 0 {
+		return
+	}
+	var lo vg.Length
+	if t.Stack != nil {
+		var ok bool
+		lo, ok = t.Stack.radius(t)
+		if !ok {
+			lo, _ = t.span(inner, outer)
+		}
+	} else {
+		lo, _ = t.span(inner, outer)
+	}
+	fillText(ca, t.TextStyle, RectangularAt(cen, t.Angle, lo), t.Angle-math.Pi/2, -0.5, 0, t.Text)
+}
+
+// TitleStack is a registry of ring titles shared by a collection of rings so that titles
+// placed at the same angle and on the same side of their rings are stacked without
+// overlap. Titles are stacked away from their rings in order of the radii of their
+// rings. Rings using a TitleStack register their titles when their GlyphBoxes method is
+// called, which a plot.Plot does for all its plotters before drawing any of them. When
+// rings are drawn directly with DrawAt, Include must be called with all the participating
+// rings first.
+type TitleStack struct {
+	// mu protects entries so that plots sharing the
+	// TitleStack may be drawn concurrently.
+	mu      sync.Mutex
+	entries []titleEntry
+}
+
+// titleEntry is a title registered with a TitleStack with the radii of its ring.
+type titleEntry struct {
+	title        *Title
+	inner, outer vg.Length
+}
+
+// NewTitleStack returns a new empty TitleStack.
+func NewTitleStack() *TitleStack { return &TitleStack{} }
+
+// Include registers the titles of the provided rings. Titles that are already registered
+// are updated with the current radii of their rings.
+func (s *TitleStack) Include(rs ...Titler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range rs {
+		s.include(r.RingTitle())
+	}
+}
+
+// include registers t with the given radii. include must be called with s.mu held.
+func (s *TitleStack) include(t *Title, inner, outer vg.Length) {
+	for i, e := range s.entries {
+		if e.title == t {
+			s.entries[i].inner, s.entries[i].outer = inner, outer
+			return
+		}
+	}
+	s.entries = append(s.entries, titleEntry{title: t, inner: inner, outer: outer})
+}
+
+// Reset discards the registered titles.
+func (s *TitleStack) Reset() {
+	s.mu.Lock()
+	s.entries = s.entries[:0]
+	s.mu.Unlock()
+}
+
+// radius returns the inner radius of the registered title t after stacking, and whether
+// t is registered.
+func (s *TitleStack) radius(t *Title) (vg.Length, bool) {
+	var group []titleEntry
+	found := false
+	s.mu.Lock()
+	for _, e := range s.entries {
+		if e.title.Text == "" || e.title.Side != t.Side || Normalize(e.title.Angle) != Normalize(t.Angle) {
+			continue
+		}
+		group = append(group, e)
+		found = found || e.title == t
+	}
+	s.mu.Unlock()
+	if !found {
+		return 0, false
+	}
+
+	switch t.Side {
+	case TickOutside:
+		sort.Stable(titlesByOuter(group))
+		var end vg.Length
+		for i, e := range group {
+			lo, hi := e.title.span(e.inner, e.outer)
+			if i != 0 && lo < end+e.title.gap() {
+				lo, hi = end+e.title.gap(), end+e.title.gap()+hi-lo
+			}
+			if e.title == t {
+				return lo, true
+			}
+			end = hi
+		}
+	case TickInside:
+		sort.Stable(sort.Reverse(titlesByInner(group)))
+		var start vg.Length
+		for i, e := range group {
+			lo, hi := e.title.span(e.inner, e.outer)
+			if i != 0 && hi > start-e.title.gap() {
+				lo, hi = start-e.title.gap()-(hi-lo), start-e.title.gap()
+			}
+			if e.title == t {
+				return lo, true
+			}
+			start = lo
+		}
+	default:
+		panic("rings: unknown title side")
+	}
+	panic("rings: title lost from stack")
+}
+
+type titlesByOuter []titleEntry
+
+func (e titlesByOuter) Len() int           { return len(e) }
+func (e titlesByOuter) Less(i, j int) bool { return e[i].outer < e[j].outer }
+func (e titlesByOuter) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+type titlesByInner []titleEntry
+
+func (e titlesByInner) Len() int           { return len(e) }
+func (e titlesByInner) Less(i, j int) bool { return e[i].inner < e[j].inner }
+func (e titlesByInner) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
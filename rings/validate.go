@@ -0,0 +1,624 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Validator is a type that can check its configuration and data before rendering.
+type Validator interface {
+	// Validate returns a non-nil error if the receiver is not renderable. The
+	// returned error should list every problem found.
+	Validate() error
+}
+
+// ValidationError is the error returned by the Validate methods of the rings package.
+// It holds every problem found during validation.
+type ValidationError []error
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Violations returns the features reported by the ValidationError as not lying
+// within their location, in the order they were reported.
+func (e ValidationError) Violations() []feat.Feature {
+	var fs []feat.Feature
+	seen := make(map[feat.Feature]bool)
+	for _, err := range e {
+		if err, ok := err.(*BoundaryError); ok && !seen[err.Feature] {
+			seen[err.Feature] = true
+			fs = append(fs, err.Feature)
+		}
+	}
+	return fs
+}
+
+// ValidateAll validates each of the provided plotters that is a Validator and returns
+// a ValidationError listing every problem found, or nil if no problem was found. Since
+// a plot.Plot does not expose the plotters that have been added to it, ValidateAll
+// should be called with the plotters before they are added to a plot.
+func ValidateAll(ps ...plot.Plotter) error {
+	var p problems
+	for _, v := range ps {
+		if v, ok := v.(Validator); ok {
+			p.add(v.Validate())
+		}
+	}
+	return p.err()
+}
+
+// problems is a collection of validation problems.
+type problems []error
+
+// add adds err to the problems, flattening ValidationErrors. Nil errors are ignored.
+func (p *problems) add(err error) {
+	switch err := err.(type) {
+	case nil:
+	case ValidationError:
+		*p = append(*p, err...)
+	default:
+		*p = append(*p, err)
+	}
+}
+
+// addf adds a problem described by the format and arguments.
+func (p *problems) addf(format string, args ...interface{}) {
+	*p = append(*p, fmt.Errorf("rings: "+format, args...))
+}
+
+// err returns the problems as a ValidationError, or nil if there are no problems.
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return ValidationError(p)
+}
+
+// radii checks that inner and outer are a valid pair of radii.
+func (p *problems) radii(inner, outer vg.Length) {
+	if inner < 0 {
+		p.addf("negative inner radius %v", inner)
+	}
+	if inner > outer {
+		p.addf("inner radius %v greater than outer radius %v", inner, outer)
+	}
+}
+
+// clip checks that inner and outer are a valid pair of clipping annulus radii, where a
+// zero outer radius places no outer limit.
+func (p *problems) clip(inner, outer vg.Length) {
+	if inner < 0 {
+		p.addf("negative clip inner radius %v", inner)
+	}
+	if outer < 0 {
+		p.addf("negative clip outer radius %v", outer)
+	}
+	if outer > 0 && inner > outer {
+		p.addf("clip inner radius %v greater than clip outer radius %v", inner, outer)
+	}
+}
+
+// gradient checks that g, if not nil, has a valid number of slices.
+func (p *problems) gradient(g *GradientFill) {
+	if g != nil && g.Slices < 0 {
+		p.addf("negative gradient slice count %d", g.Slices)
+	}
+}
+
+// feature checks that f is not inverted and lies within its location. Features
+// extending beyond the end of their location are reported whatever the boundary
+// policy used when they are drawn.
+func (p *problems) feature(f feat.Feature) {
+	if f == nil {
+		p.addf("nil feature")
+		return
+	}
+	if f.End() < f.Start() {
+		p.addf("inverted feature %q: end %d less than start %d", f.Name(), f.End(), f.Start())
+	}
+	if loc := f.Location(); loc != nil && (f.Start() < loc.Start() || f.Start() > loc.End() || f.End() > loc.End()) {
+		*p = append(*p, &BoundaryError{Feature: f, Location: loc})
+	}
+}
+
+// arcOf checks that base can provide an arc for loc and f.
+func (p *problems) arcOf(base ArcOfer, loc, f feat.Feature) {
+	if _, err := base.ArcOf(loc, f); err != nil {
+		q := f
+		if q == nil {
+			q = loc
+		}
+		var name string
+		if q != nil {
+			name = q.Name()
+		}
+		p.addf("no arc for feature %q: %v", name, err)
+	}
+}
+
+// pairs checks the feature pairs fp against the provided ends.
+func (p *problems) pairs(fp []Pair, ends [2]ArcOfer, needLocation bool) {
+	for i, e := range ends {
+		if e == nil {
+			p.addf("nil end %d", i)
+		}
+	}
+	for _, pair := range fp {
+		for i, f := range pair.Features() {
+			p.feature(f)
+			if f == nil {
+				continue
+			}
+			if needLocation && f.Location() == nil {
+				p.addf("feature %q has no location", f.Name())
+			}
+			if ends[i] != nil {
+				p.arcOf(ends[i], nil, f)
+			}
+		}
+	}
+}
+
+// anchors checks that at most one of ends is an ExternalAnchor, and that an anchor
+// has a non-negative width and lies outside the radius of the other end given by radii.
+func (p *problems) anchors(ends [2]ArcOfer, radii [2]vg.Length) {
+	var n int
+	for j, e := range ends {
+		a, ok := anchorOf(e)
+		if !ok {
+			continue
+		}
+		n++
+		if a.Width < 0 {
+			p.addf("negative external anchor width %v", a.Width)
+		}
+		if _, d := Polar(a.Point); d <= radii[1-j] {
+			p.addf("external anchor at distance %v not outside end radius %v", d, radii[1-j])
+		}
+	}
+	if n == 2 {
+		p.addf("both ends are external anchors")
+	}
+}
+
+// Validate checks the configuration and features of the Blocks, returning a
+// ValidationError listing every problem found.
+func (r *Blocks) Validate() error {
+	var p problems
+	p.radii(r.Inner, r.Outer)
+	if r.Base == nil {
+		p.addf("nil blocks base")
+		return p.err()
+	}
+	if math.Abs(float64(r.Base.Arc().Phi)) > float64(Complete) {
+		p.addf("base arc exceeds a complete circle")
+	}
+	p.gradient(r.Gradient)
+	valid := true
+	for _, f := range r.Set {
+		p.feature(f)
+		if f != nil {
+			p.arcOf(r.Base, f, nil)
+		} else {
+			valid = false
+		}
+	}
+	if r.Weight != nil && valid {
+		for _, f := range r.roots() {
+			if w, err := r.weightOf(f); err != nil {
+				p.addf("invalid weight %v of feature %q", w, f.Name())
+			}
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration and features of the Scores and its Renderer,
+// returning a ValidationError listing every problem found. Scorers holding more
+// scores than a Trace Renderer has LineStyles are reported unless the Trace has
+// a Segmenter.
+func (r *Scores) Validate() error {
+	var p problems
+	p.radii(r.Inner, r.Outer)
+	if r.hasRange() {
+		if min, max := r.ScoreRange(); !(min < max) {
+			p.addf("score minimum %v not less than maximum %v", min, max)
+		}
+	}
+	if t, ok := r.Renderer.(*Trace); ok && r.PerFeatureRange && t.Axis != nil && t.Axis.Range != nil {
+		p.addf("per feature range with shared axis range")
+	}
+	if r.Base == nil {
+		p.addf("nil scores base")
+	}
+	var fs []Scorer
+	if t, ok := r.Source.(*ScoreTrack); ok && t.check() != nil {
+		p.addf("score track column lengths differ")
+		fs = r.Set
+	} else {
+		fs = r.scorers()
+	}
+	for _, f := range fs {
+		p.feature(f)
+		if f == nil {
+			continue
+		}
+		if r.Base != nil {
+			p.arcOf(r.Base, nil, f)
+		}
+		if t, ok := r.Renderer.(*Trace); ok && t.Segmenter == nil && len(f.Scores()) > len(t.LineStyles) {
+			p.addf("scorer %q has %d scores but trace has %d line styles", f.Name(), len(f.Scores()), len(t.LineStyles))
+		}
+	}
+	switch rr := r.Renderer.(type) {
+	case nil:
+		p.addf("nil score renderer")
+	case *Heat:
+		if len(rr.Palette) == 0 && rr.Ramp == nil {
+			p.addf("empty heat palette")
+		}
+		if rr.Smooth < 0 {
+			p.addf("negative heat smoothing slice count %d", rr.Smooth)
+		}
+		if rr.Rasterize < 0 {
+			p.addf("negative heat raster resolution %v", rr.Rasterize)
+		}
+	case *Trace:
+		if rr.Interpolation < StepInterpolation || SmoothInterpolation < rr.Interpolation {
+			p.addf("unknown trace interpolation %d", rr.Interpolation)
+		}
+		if rr.Axis != nil {
+			p.add(rr.Axis.Validate())
+			if rr.Axis.Auto != nil && r.Base != nil {
+				if _, err := rr.Axis.Auto(r.Base); err != nil {
+					p.add(err)
+				}
+			}
+		}
+	case *ErrorBand:
+		if rr.Upper == nil || rr.Lower == nil {
+			p.addf("nil band value function")
+		}
+	case *Segments:
+		for _, f := range fs {
+			if f != nil && len(f.Scores()) == 0 {
+				p.addf("scorer %q has no scores but segments require one", f.Name())
+			}
+		}
+	case *Interval:
+		if rr.Values == nil {
+			for _, f := range fs {
+				if f == nil {
+					continue
+				}
+				if n := len(f.Scores()); n != 3 && n != 5 {
+					p.addf("scorer %q has %d scores but interval requires 3 or 5", f.Name(), n)
+				}
+			}
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration and score series of the Diff, returning a
+// ValidationError listing every problem found. Series whose bins differ are
+// reported at the first location at which they differ.
+func (r *Diff) Validate() error {
+	var p problems
+	p.radii(r.Inner, r.Outer)
+	if (r.Min != 0 || r.Max != 0) && !(r.Min < r.Max) {
+		p.addf("score minimum %v not less than maximum %v", r.Min, r.Max)
+	}
+	if r.Base == nil {
+		p.addf("nil diff base")
+	}
+	aligned := true
+	for i, src := range []ScoreSource{r.A, r.B} {
+		name := [...]string{"A", "B"}[i]
+		if src == nil {
+			p.addf("nil diff series %s", name)
+			aligned = false
+			continue
+		}
+		if t, ok := src.(*ScoreTrack); ok && t.check() != nil {
+			p.addf("score track column lengths differ in diff series %s", name)
+			aligned = false
+			continue
+		}
+		for j := 0; j < src.Len(); j++ {
+			f := &sourceScorer{src: src, i: j}
+			p.feature(f)
+			if r.Base != nil {
+				p.arcOf(r.Base, nil, f)
+			}
+		}
+	}
+	if aligned {
+		_, err := r.bins()
+		p.add(err)
+	}
+	return p.err()
+}
+
+// Validate checks the configuration and feature pairs of the Links, returning a
+// ValidationError listing every problem found.
+func (r *Links) Validate() error {
+	var p problems
+	p.clip(r.ClipInner, r.ClipOuter)
+	if d := r.Density; d != nil {
+		if d.DPI < 0 {
+			p.addf("negative density resolution %v", d.DPI)
+		}
+		if d.Radius < 0 {
+			p.addf("negative density stamp radius %v", d.Radius)
+		}
+	}
+	fp := r.Set
+	if r.SkipFiltered {
+		// Pairs with a filtered end are not rendered, or are
+		// rendered to the Other stub of the filtered end.
+		fp = nil
+		for _, pair := range r.Set {
+			if end, ok := r.filteredEnd(pair); ok && end == -1 {
+				fp = append(fp, pair)
+			}
+		}
+	}
+	p.pairs(fp, r.Ends, false)
+	p.anchors(r.Ends, r.Radii)
+	return p.err()
+}
+
+// Validate checks the configuration and feature pairs of the CrossLinks, returning a
+// ValidationError listing every problem found.
+func (r *CrossLinks) Validate() error {
+	var p problems
+	p.pairs(r.Set, r.Ends, false)
+	return p.err()
+}
+
+// Validate checks the configuration and feature pairs of the Ribbons, returning a
+// ValidationError listing every problem found.
+func (r *Ribbons) Validate() error {
+	var p problems
+	if r.Twist&(Flat|Twisted) == Flat|Twisted {
+		p.addf("cannot specify flat and twisted")
+	}
+	p.clip(r.ClipInner, r.ClipOuter)
+	fp := r.Set
+	if r.SkipFiltered {
+		fp = nil
+		for _, pair := range r.Set {
+			if !r.filtered(pair) {
+				fp = append(fp, pair)
+			}
+		}
+	}
+	p.pairs(fp, r.Ends, true)
+	p.anchors(r.Ends, r.Radii)
+	return p.err()
+}
+
+// Validate checks the configuration and labels of the Labels, returning a
+// ValidationError listing every problem found.
+func (r *Labels) Validate() error {
+	var p problems
+	if r.Radius < 0 {
+		p.addf("negative label radius %v", r.Radius)
+	}
+	if r.Max < 0 {
+		p.addf("negative maximum label count %d", r.Max)
+	}
+	if r.MaxPerLocation < 0 {
+		p.addf("negative maximum label count per location %d", r.MaxPerLocation)
+	}
+	if r.Base == nil {
+		p.addf("nil labels base")
+		return p.err()
+	}
+	for _, l := range r.Labels {
+		if r.SkipFiltered && isFiltered(r.Base, labelled(l)) {
+			continue
+		}
+		switch l := l.(type) {
+		case locater:
+			p.arcOf(r.Base, l.location(), nil)
+		case feat.Feature:
+			p.arcOf(r.Base, l, nil)
+		default:
+			if _, err := r.Base.ArcOf(nil, nil); err != nil {
+				p.addf("no arc for label %q: %v", l.Label(), err)
+			}
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the Clip and its Plotter, returning a
+// ValidationError listing every problem found.
+func (r *Clip) Validate() error {
+	var p problems
+	if r.Plotter == nil {
+		p.addf("nil clipped plotter")
+	} else if v, ok := r.Plotter.(Validator); ok {
+		p.add(v.Validate())
+	}
+	p.radii(r.Inner, r.Outer)
+	if r.FillRule < DefaultRule || EvenOddRule < r.FillRule {
+		p.addf("unknown clip fill rule %d", r.FillRule)
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the Highlight, returning a ValidationError
+// listing every problem found. A Highlight specified by LocFeatures is checked for
+// features without arcs in the LocBase and for split wedges when AllowSplit is false.
+func (r *Highlight) Validate() error {
+	var p problems
+	p.radii(r.Inner, r.Outer)
+	p.gradient(r.Gradient)
+	if _, err := r.Arcs(); err != nil {
+		p.add(err)
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the Brush, returning a ValidationError listing
+// every problem found. The brushed interval is checked for an arc in the Base.
+func (r *Brush) Validate() error {
+	var p problems
+	for _, ri := range r.Radii {
+		p.radii(ri.Inner, ri.Outer)
+	}
+	if _, err := r.arc(); err != nil {
+		p.add(err)
+	}
+	return p.err()
+}
+
+// Validate checks the configuration and markers of the Markers, returning a
+// ValidationError listing every problem found. Markers with positions outside their
+// location are reported.
+func (r *Markers) Validate() error {
+	var p problems
+	if r.Base == nil {
+		p.addf("nil markers base")
+	}
+	for i, m := range r.Set {
+		p.radii(m.Inner, m.Outer)
+		switch {
+		case m.Location == nil:
+			p.addf("marker %d has no location", i)
+		case !m.inRange():
+			p.addf("marker %d position %d out of range of location %q", i, m.Pos, m.Location.Name())
+		case r.Base != nil:
+			p.arcOf(r.Base, m.Location, locus{loc: m.Location, pos: m.Pos})
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the Axis, returning a ValidationError listing
+// every problem found. An Axis that renders grid lines or ticks must have a tick Marker,
+// and the Scores provided to RangeFrom must be rendered according to the Range of the
+// Axis without an explicit or per feature range.
+func (r *Axis) Validate() error {
+	var p problems
+	if r.Tick.Marker == nil {
+		grid := r.Grid.Color != nil && r.Grid.Width != 0
+		ticks := r.Tick.LineStyle.Color != nil && r.Tick.LineStyle.Width != 0 && r.Tick.Length != 0
+		if grid || ticks {
+			p.addf("nil axis tick marker")
+		}
+	}
+	if r.MirrorTo < 0 {
+		p.addf("negative axis mirror radius %v", r.MirrorTo)
+	}
+	for i, sc := range r.from {
+		if !sc.usesRange(r.Range) {
+			p.addf("axis range source %s not using axis range", scoresName(i, sc))
+		}
+	}
+	return p.err()
+}
+
+// scoresName returns the title of s, or its index i if s has no title.
+func scoresName(i int, s *Scores) string {
+	if s.Title.Text != "" {
+		return fmt.Sprintf("%q", s.Title.Text)
+	}
+	return fmt.Sprintf("scores %d", i)
+}
+
+// Validate checks the configuration and callouts of the Callouts, returning a
+// ValidationError listing every problem found.
+func (r *Callouts) Validate() error {
+	var p problems
+	if r.Base == nil {
+		p.addf("nil callouts base")
+	}
+	if r.Radius < r.Anchor {
+		p.addf("callout radius %v less than anchor radius %v", r.Radius, r.Anchor)
+	}
+	if r.Push < 0 {
+		p.addf("negative callout push %v", r.Push)
+	}
+	if r.Spread < 0 {
+		p.addf("negative callout spread %v", r.Spread)
+	}
+	for _, c := range r.Set {
+		p.feature(c.Feature)
+		if c.Feature != nil && r.Base != nil {
+			p.arcOf(r.Base, c.Feature.Location(), c.Feature)
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the PeakAnnotations, returning a
+// ValidationError listing every problem found.
+func (r *PeakAnnotations) Validate() error {
+	var p problems
+	if r.Scores == nil {
+		p.addf("nil annotated scores")
+	} else if r.Callouts.Radius < r.Scores.Outer {
+		p.addf("callout radius %v less than scores outer radius %v", r.Callouts.Radius, r.Scores.Outer)
+	}
+	if r.Select == nil {
+		p.addf("nil peak selector")
+	}
+	if r.Callouts.Push < 0 {
+		p.addf("negative callout push %v", r.Callouts.Push)
+	}
+	if r.Callouts.Spread < 0 {
+		p.addf("negative callout spread %v", r.Callouts.Spread)
+	}
+	if r.Scores != nil && r.Select != nil {
+		if _, err := r.Annotations(); err != nil {
+			p.add(err)
+		}
+	}
+	return p.err()
+}
+
+// Validate checks the configuration of the SharedGuides, returning a ValidationError
+// listing every problem found.
+func (r *SharedGuides) Validate() error {
+	var p problems
+	if len(r.Tracks) == 0 {
+		p.addf("no guide tracks")
+	}
+	for i, t := range r.Tracks {
+		switch {
+		case t == nil:
+			p.addf("nil guide track %d", i)
+		case t.Base == nil:
+			p.addf("nil base of guide track %d", i)
+		case t.PerFeatureRange:
+			p.addf("guide track %d has per feature ranges", i)
+		case r.Range != nil && !t.usesRange(r.Range):
+			p.addf("guide track %d not using shared range", i)
+		}
+	}
+	if r.Range != nil {
+		if min, max := r.Range.Values(); !(min < max) {
+			p.addf("shared range minimum %v not less than maximum %v", min, max)
+		}
+	}
+	return p.err()
+}
@@ -125,8 +125,9 @@ func (c *canvas) FillString(font vg.Font, pt vg.Point, str string) {
 type drawImage struct {
 	rect vg.Rectangle
 	image.Rectangle
+	img image.Image
 }
 
 func (c *canvas) DrawImage(rect vg.Rectangle, img image.Image) {
-	c.actions = append(c.actions, drawImage{rect, img.Bounds()})
+	c.actions = append(c.actions, drawImage{rect, img.Bounds(), img})
 }
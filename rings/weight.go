@@ -0,0 +1,209 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// weightedArc is the arc of a feature allocated by the Base of a Blocks and the arc
+// allocated to it in proportion to its weight.
+type weightedArc struct {
+	base, weighted Arc
+}
+
+// mapArc returns arc, an arc within the base arc of the feature, mapped linearly to
+// the weighted arc of the feature.
+func (w weightedArc) mapArc(arc Arc) Arc {
+	if w.base.Phi == 0 {
+		return Arc{Theta: w.weighted.Theta, Phi: 0}
+	}
+	scale := w.weighted.Phi / w.base.Phi
+	return Arc{Theta: w.weighted.Theta + (arc.Theta-w.base.Theta)*scale, Phi: arc.Phi * scale}
+}
+
+// roots returns the features of the Set that are not held by another feature of the
+// Set.
+func (r *Blocks) roots() []feat.Feature {
+	in := make(map[feat.Feature]bool, len(r.Set))
+	for _, f := range r.Set {
+		in[f] = true
+	}
+	var roots []feat.Feature
+	for _, f := range r.Set {
+		root := true
+		for loc := f.Location(); loc != nil; loc = loc.Location() {
+			if in[loc] {
+				root = false
+				break
+			}
+		}
+		if root {
+			roots = append(roots, f)
+		}
+	}
+	return roots
+}
+
+// weightOf returns the Weight of f and an error if the weight is not a positive
+// finite value.
+func (r *Blocks) weightOf(f feat.Feature) (float64, error) {
+	w := r.Weight(f)
+	if !(w > 0) || math.IsInf(w, 1) {
+		return w, fmt.Errorf("rings: invalid weight %v of feature %q", w, f.Name())
+	}
+	return w, nil
+}
+
+// weightedArcs returns the arcs of the root features of the Set allocated in proportion
+// to their weights. The weighted arcs are placed in the order of the features' arcs
+// along the base arc, and the total angle of the arcs and the gaps between them are
+// those given by the Base. An error is returned if the arc of a root feature cannot be
+// found or its weight is invalid.
+func (r *Blocks) weightedArcs() (map[feat.Feature]weightedArc, error) {
+	base := r.Base.Arc()
+	dir := CounterClockwise
+	if base.Phi < 0 {
+		dir = Clockwise
+	}
+
+	// The position of each feature is the angle from the start of the base
+	// arc to the start of the feature's arc in the direction of the base arc.
+	const tol = 1e-9
+	var (
+		alloc []weightAllocation
+		total Angle
+		sum   float64
+	)
+	for _, f := range r.roots() {
+		arc, err := r.Base.ArcOf(nil, f)
+		if err != nil {
+			return nil, err
+		}
+		w, err := r.weightOf(f)
+		if err != nil {
+			return nil, err
+		}
+		start := arc.Theta
+		if arc.Phi*dir < 0 {
+			start += arc.Phi
+		}
+		pos := math.Mod(float64(dir*(start-base.Theta)), float64(Complete))
+		if pos < 0 {
+			pos += float64(Complete)
+		}
+		if pos > float64(Complete)-tol {
+			pos = 0
+		}
+		alloc = append(alloc, weightAllocation{f: f, arc: arc, weight: w, pos: pos})
+		total += Angle(math.Abs(float64(arc.Phi)))
+		sum += w
+	}
+	sort.Stable(byAllocationPosition(alloc))
+
+	arcs := make(map[feat.Feature]weightedArc, len(alloc))
+	var end, pos Angle
+	for _, a := range alloc {
+		pos += Angle(a.pos) - end
+		end = Angle(a.pos) + Angle(math.Abs(float64(a.arc.Phi)))
+		phi := total * Angle(a.weight/sum)
+		w := Arc{Theta: Normalize(base.Theta + dir*pos), Phi: dir * phi}
+		if a.arc.Phi*dir < 0 {
+			w = Arc{Theta: Normalize(base.Theta + dir*(pos+phi)), Phi: -dir * phi}
+		}
+		arcs[a.f] = weightedArc{base: a.arc, weighted: w}
+		pos += phi
+	}
+	return arcs, nil
+}
+
+// weightAllocation is the arc and weight of a root feature of a Blocks and the
+// position of the arc along the base arc.
+type weightAllocation struct {
+	f      feat.Feature
+	arc    Arc
+	weight float64
+	pos    float64
+}
+
+type byAllocationPosition []weightAllocation
+
+func (a byAllocationPosition) Len() int           { return len(a) }
+func (a byAllocationPosition) Less(i, j int) bool { return a[i].pos < a[j].pos }
+func (a byAllocationPosition) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// weightCache holds the weighted arcs of a Blocks, which are retained while the Set
+// and Base of the Blocks are unchanged.
+type weightCache struct {
+	mu sync.Mutex
+
+	set  *feat.Feature
+	len  int
+	base ArcOfer
+
+	arcs map[feat.Feature]weightedArc
+}
+
+// cachedWeightedArcs returns the weighted arcs of the Blocks, determining them only if
+// the Set or Base has changed since they were last determined.
+func (r *Blocks) cachedWeightedArcs() (map[feat.Feature]weightedArc, error) {
+	c := &r.weights
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var set *feat.Feature
+	if len(r.Set) != 0 {
+		set = &r.Set[0]
+	}
+	if c.arcs != nil && c.set == set && c.len == len(r.Set) && sameArcOfer(c.base, r.Base) {
+		return c.arcs, nil
+	}
+	arcs, err := r.weightedArcs()
+	if err != nil {
+		return nil, err
+	}
+	c.set, c.len, c.base, c.arcs = set, len(r.Set), r.Base, arcs
+	return arcs, nil
+}
+
+// sameArcOfer returns whether a and b are known to be the same ArcOfer. Arcs values
+// are the same if they share their arc lookup.
+func sameArcOfer(a, b ArcOfer) bool {
+	if a, ok := a.(Arcs); ok {
+		b, ok := b.(Arcs)
+		return ok && a.Base == b.Base && a.Boundary == b.Boundary &&
+			reflect.ValueOf(a.Arcs).Pointer() == reflect.ValueOf(b.Arcs).Pointer()
+	}
+	return isComparable(a) && a == b
+}
+
+// baseArcOf returns the arc of f in the context of loc given by the Base. If the
+// Blocks has a Weight, the arc is mapped to the weighted arc of the root feature of
+// the Set holding it.
+func (r *Blocks) baseArcOf(loc, f feat.Feature) (Arc, error) {
+	arc, err := r.Base.ArcOf(loc, f)
+	if err != nil || r.Weight == nil {
+		return arc, err
+	}
+	arcs, err := r.cachedWeightedArcs()
+	if err != nil {
+		return arcNaN, err
+	}
+	q := f
+	if q == nil {
+		q = loc
+	}
+	for ; q != nil; q = q.Location() {
+		if w, ok := arcs[q]; ok {
+			return w.mapArc(arc), nil
+		}
+	}
+	return arc, nil
+}
@@ -0,0 +1,183 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// WindowScores returns the scores of fs aggregated into genomic windows of size bases.
+// Windows are anchored at position 0 of each location, so the windows of tracks binned
+// separately with the same size are aligned whatever the lengths of the locations, and
+// windows are truncated at the start and end of their location rather than stretched,
+// so a partial final window is rendered at its true angular width. The score of a
+// window at each index is the mean of the scores at that index of the Scorers
+// overlapping the window, weighted by the length of their overlap with the window. NaN
+// scores are ignored, and a window with no score at an index holds NaN. Windows that
+// no Scorer overlaps are omitted. The returned Scorers are grouped by location, in
+// order of the first appearance of each location in fs, and sorted by position. An
+// error is returned if size is not positive.
+func WindowScores(fs []Scorer, size int) ([]Scorer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("rings: invalid window size %d", size)
+	}
+	return windowScores(fs, size, nil), nil
+}
+
+// windowScores returns the scores of fs aggregated into windows of size bases as
+// described by WindowScores. Within the regions of zooms, the windows are anchored
+// at the start of the region and their size is divided by the scale of the zoom, so
+// that the windows are magnified with the positions they hold.
+func windowScores(fs []Scorer, size int, zooms []Zoom) []Scorer {
+	var ws []Scorer
+	for _, set := range byLocation(fs) {
+		loc := set[0].Location()
+		regions := windowRegions(loc, size, zooms)
+		windows := make(map[int]*window)
+		for _, f := range set {
+			scores := f.Scores()
+			for _, rg := range regions {
+				start, end := clamp(f.Start(), rg.start, rg.end), clamp(f.End(), rg.start, rg.end)
+				if start >= end {
+					continue
+				}
+				for k := floorDiv(start-rg.anchor, rg.size); rg.anchor+k*rg.size < end; k++ {
+					wstart := clamp(rg.anchor+k*rg.size, rg.start, rg.end)
+					w, ok := windows[wstart]
+					if !ok {
+						w = &window{loc: loc, start: wstart, end: clamp(rg.anchor+(k+1)*rg.size, rg.start, rg.end)}
+						windows[wstart] = w
+					}
+					w.add(scores, float64(clamp(end, w.start, w.end)-clamp(start, w.start, w.end)))
+				}
+			}
+		}
+
+		keys := make([]int, 0, len(windows))
+		for k := range windows {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			windows[k].finish()
+			ws = append(ws, windows[k])
+		}
+	}
+	return ws
+}
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// windowRegion is an interval of positions holding windows of size bases anchored
+// at anchor.
+type windowRegion struct {
+	start, end   int
+	anchor, size int
+}
+
+// windowRegions returns the regions of loc holding windows of size bases given the
+// zooms of the location's base. A nil loc is a single unbounded region.
+func windowRegions(loc feat.Feature, size int, zooms []Zoom) []windowRegion {
+	if loc == nil {
+		return []windowRegion{{start: minInt, end: maxInt, size: size}}
+	}
+	zs, err := zoomsOf(zooms, loc)
+	if err != nil {
+		zs = nil
+	}
+	var regions []windowRegion
+	pos := loc.Start()
+	for _, z := range zs {
+		if pos < z.Start {
+			regions = append(regions, windowRegion{start: pos, end: z.Start, size: size})
+		}
+		zsize := int(math.Max(1, math.Floor(float64(size)/z.Scale+0.5)))
+		regions = append(regions, windowRegion{start: z.Start, end: z.End, anchor: z.Start, size: zsize})
+		pos = z.End
+	}
+	if pos < loc.End() || len(regions) == 0 {
+		regions = append(regions, windowRegion{start: pos, end: loc.End(), size: size})
+	}
+	return regions
+}
+
+// window is a Scorer holding the aggregated scores of a genomic window.
+type window struct {
+	loc        feat.Feature
+	start, end int
+
+	// scores holds the weighted sums of the scores overlapping the
+	// window, and weights the total overlap of the summed scores,
+	// until the window is finished.
+	scores  []float64
+	weights []float64
+}
+
+// add adds scores to the window with the weight w.
+func (s *window) add(scores []float64, w float64) {
+	for len(s.scores) < len(scores) {
+		s.scores = append(s.scores, 0)
+		s.weights = append(s.weights, 0)
+	}
+	for i, v := range scores {
+		if math.IsNaN(v) {
+			continue
+		}
+		s.scores[i] += v * w
+		s.weights[i] += w
+	}
+}
+
+// finish replaces the weighted sums of the window with the weighted means.
+func (s *window) finish() {
+	for i, w := range s.weights {
+		if w == 0 {
+			s.scores[i] = math.NaN()
+			continue
+		}
+		s.scores[i] /= w
+	}
+	s.weights = nil
+}
+
+func (s *window) Start() int { return s.start }
+func (s *window) End() int   { return s.end }
+func (s *window) Len() int   { return s.end - s.start }
+func (s *window) Name() string {
+	if s.loc == nil {
+		return fmt.Sprintf("%d-%d", s.start, s.end)
+	}
+	return fmt.Sprintf("%s:%d-%d", s.loc.Name(), s.start, s.end)
+}
+func (s *window) Description() string    { return "window" }
+func (s *window) Location() feat.Feature { return s.loc }
+func (s *window) Scores() []float64      { return s.scores }
+
+// clamp returns v clamped to [lo, hi].
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// floorDiv returns a divided by b rounded towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
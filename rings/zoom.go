@@ -0,0 +1,99 @@
+// Copyright ©2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rings
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/biogo/biogo/feat"
+)
+
+// Zoom specifies a region of a location that is allocated a scaled share of the
+// location's arc. Positions within the region are spread over Scale times the angle
+// they would otherwise occupy and the remainder of the location is compressed so
+// that the total arc of the location is unchanged.
+type Zoom struct {
+	// Location is the feature holding the zoomed region.
+	Location feat.Feature
+
+	// Start and End are the positions of the zoomed region within Location.
+	Start, End int
+
+	// Scale is the magnification of the zoomed region. Scale must be positive.
+	Scale float64
+}
+
+// baseZooms returns the zooms of base if it is a Blocks.
+func baseZooms(base ArcOfer) []Zoom {
+	if b, ok := base.(*Blocks); ok {
+		return b.Zooms
+	}
+	return nil
+}
+
+// zoomsOf returns the zooms in zs applying to loc sorted by start position.
+func zoomsOf(zs []Zoom, loc feat.Feature) ([]Zoom, error) {
+	var lz []Zoom
+	for _, z := range zs {
+		if z.Location != loc {
+			continue
+		}
+		if z.End < z.Start {
+			return nil, errors.New("rings: inverted zoom")
+		}
+		if z.Start < loc.Start() || z.End > loc.End() {
+			return nil, errors.New("rings: zoom out of range")
+		}
+		if z.Scale <= 0 {
+			return nil, errors.New("rings: non-positive zoom scale")
+		}
+		lz = append(lz, z)
+	}
+	sort.Sort(byZoomStart(lz))
+	for i := 1; i < len(lz); i++ {
+		if lz[i].Start < lz[i-1].End {
+			return nil, errors.New("rings: overlapping zooms")
+		}
+	}
+	return lz, nil
+}
+
+type byZoomStart []Zoom
+
+func (z byZoomStart) Len() int           { return len(z) }
+func (z byZoomStart) Less(i, j int) bool { return z[i].Start < z[j].Start }
+func (z byZoomStart) Swap(i, j int)      { z[i], z[j] = z[j], z[i] }
+
+// zoomed returns the arc of the interval from start to end within loc, given the arc
+// of loc and the zooms applying to loc. The mapping from position to angle is piecewise
+// linear, with each position weighted by the scale of the zoom containing it.
+func zoomed(la Arc, loc feat.Feature, start, end int, zs []Zoom) Arc {
+	min, max := loc.Start(), loc.End()
+
+	// weight returns the scaled length of the location from its start to pos.
+	weight := func(pos int) float64 {
+		w := float64(pos - min)
+		for _, z := range zs {
+			if pos <= z.Start {
+				break
+			}
+			end := z.End
+			if pos < end {
+				end = pos
+			}
+			w += (z.Scale - 1) * float64(end-z.Start)
+		}
+		return w
+	}
+
+	total := weight(max)
+	if total == 0 {
+		return Arc{la.Theta, 0}
+	}
+	theta := la.Phi * Angle(weight(start)/total)
+	phi := la.Phi*Angle(weight(end)/total) - theta
+	return Arc{theta + la.Theta, phi}
+}